@@ -0,0 +1,400 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/reqlog"
+)
+
+// defaultAntiEntropyInterval is how often Server.runAntiEntropyLoop starts a
+// new push-pull round with every trusted indexer when
+// Server.AntiEntropyInterval is unset. It's deliberately much coarser than
+// boardRequestTimeout: this loop exists to catch boards that announce
+// forwarding missed (a node offline when the announce went out, a dropped
+// packet), not to replace it as the primary propagation path.
+const defaultAntiEntropyInterval = 5 * time.Minute
+
+// antiEntropyJitterFraction spreads each tick by up to this fraction of the
+// interval in either direction, so a fleet of nodes configured with the same
+// interval doesn't settle into syncing every peer in lockstep.
+const antiEntropyJitterFraction = 0.2
+
+// antiEntropyInitialBackoff and antiEntropyMaxBackoff bound the
+// exponential backoff antiEntropyPeerSet applies to a peer after a failed
+// round: doubling from the initial value, capped at the max, so one
+// unreachable trusted indexer doesn't eat a full round's timeout budget on
+// every tick.
+const (
+	antiEntropyInitialBackoff = 5 * time.Second
+	antiEntropyMaxBackoff     = 10 * time.Minute
+)
+
+// antiEntropyPeerState tracks one peer's backoff: consecutive round
+// failures push retryAfter further out, same idea as peerHealthSet but
+// tracking a growing delay rather than a fixed cooldown, since a digest
+// round is a much cheaper, more tolerant operation than a request-path
+// indexer fetch.
+type antiEntropyPeerState struct {
+	backoff    time.Duration
+	retryAfter time.Time
+}
+
+// antiEntropyPeerSet is a mutex-protected, per-baseURL backoff tracker
+// shared across anti-entropy rounds (lazily initialized once in
+// Server.initNetworkDeps, like peerHealthSet).
+type antiEntropyPeerSet struct {
+	mu sync.Mutex
+	m  map[string]*antiEntropyPeerState
+}
+
+func newAntiEntropyPeerSet() *antiEntropyPeerSet {
+	return &antiEntropyPeerSet{m: make(map[string]*antiEntropyPeerState)}
+}
+
+func (s *antiEntropyPeerSet) Allowed(baseURL string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.m[baseURL]
+	if !ok {
+		return true
+	}
+	return !time.Now().Before(st.retryAfter)
+}
+
+func (s *antiEntropyPeerSet) RecordSuccess(baseURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, baseURL)
+}
+
+func (s *antiEntropyPeerSet) RecordFailure(baseURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.m[baseURL]
+	if !ok {
+		st = &antiEntropyPeerState{backoff: antiEntropyInitialBackoff}
+		s.m[baseURL] = st
+	} else {
+		st.backoff *= 2
+		if st.backoff > antiEntropyMaxBackoff {
+			st.backoff = antiEntropyMaxBackoff
+		}
+	}
+	st.retryAfter = time.Now().Add(st.backoff)
+}
+
+func (s *Server) antiEntropyIntervalOrDefault() time.Duration {
+	if s.AntiEntropyInterval > 0 {
+		return s.AntiEntropyInterval
+	}
+	return defaultAntiEntropyInterval
+}
+
+func (s *Server) antiEntropyConcurrencyOrDefault() int {
+	if s.AntiEntropyConcurrency > 0 {
+		return s.AntiEntropyConcurrency
+	}
+	return defaultMaxConcurrentFetches
+}
+
+func jitteredInterval(d time.Duration) time.Duration {
+	spread := float64(d) * antiEntropyJitterFraction
+	offset := (rand.Float64()*2 - 1) * spread
+	return d + time.Duration(offset)
+}
+
+// runAntiEntropyLoop ticks forever (until ctx is cancelled) running one
+// push-pull round with every trusted indexer per tick. It's started once,
+// from initNetworkDeps, for the life of the process — there's no Stop here
+// because nothing in this codebase currently tears a Server down before
+// process exit (cmd/bbs-node's shutdown coordinator closes listeners and
+// flex-ipfs, not the Server itself).
+func (s *Server) runAntiEntropyLoop(ctx context.Context) {
+	for {
+		select {
+		case <-time.After(jitteredInterval(s.antiEntropyIntervalOrDefault())):
+		case <-ctx.Done():
+			return
+		}
+		s.runAntiEntropyRound(ctx)
+	}
+}
+
+// runAntiEntropyRound builds a digest of every board this node tracks and
+// POSTs it to each trusted indexer, applying whatever divergent
+// BoardMetaCIDs come back. One peer's failure doesn't stop the round for
+// the others; peers in backoff (antiEntropyPeerSet) are skipped outright.
+func (s *Server) runAntiEntropyRound(ctx context.Context) {
+	if s.TrustedIndexers == nil || s.Boards == nil {
+		return
+	}
+	// A tick doesn't originate from an incoming HTTP request, so it mints
+	// its own request ID here — every log line and outbound request this
+	// round makes shares it, the same way one handler's logs and forwards
+	// correlate under the ID WithRequestID assigned it.
+	ctx = ensureRequestID(ctx)
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "antiEntropy"))
+
+	if err := s.TrustedIndexers.Load(); err != nil {
+		logger.Info("trusted indexers load error", reqlog.F("error", err.Error()))
+		return
+	}
+	peers := s.TrustedIndexers.List()
+	if len(peers) == 0 {
+		return
+	}
+
+	digest := s.buildBoardDigest(ctx)
+	if len(digest) == 0 {
+		return
+	}
+
+	pool := newFetchPool(s.antiEntropyConcurrencyOrDefault())
+	pool.forEach(len(peers), func(i int) {
+		baseURL := peers[i]
+		if !s.antiEntropyPeers.Allowed(baseURL) {
+			return
+		}
+
+		var reached bool
+		if s.peerSupportsFeature(ctx, baseURL, featureDigestSync) {
+			reached = s.antiEntropyRoundWithPeer(ctx, baseURL, digest)
+		} else {
+			reached = s.antiEntropyRoundWithPeerPerBoard(ctx, baseURL, digest)
+		}
+		if reached {
+			s.antiEntropyPeers.RecordSuccess(baseURL)
+		} else {
+			s.antiEntropyPeers.RecordFailure(baseURL)
+		}
+	})
+}
+
+// buildBoardDigest returns one BoardDigestEntry per board this node
+// currently tracks, each carrying the board's current BoardMetaCID and
+// LogHeadCID so a peer can tell at a glance whether it has anything newer.
+func (s *Server) buildBoardDigest(ctx context.Context) []BoardDigestEntry {
+	if err := s.Boards.Load(); err != nil {
+		reqlog.FromContext(ctx).With(reqlog.F("route", "antiEntropy")).Info("boards load error", reqlog.F("error", err.Error()))
+		return nil
+	}
+	refs := s.Boards.List()
+	digest := make([]BoardDigestEntry, 0, len(refs))
+	for _, ref := range refs {
+		_, bm, ok := s.loadBoardByID(ctx, ref.BoardID)
+		if !ok {
+			continue
+		}
+		digest = append(digest, BoardDigestEntry{
+			BoardID:      ref.BoardID,
+			BoardMetaCID: ref.BoardMetaCID,
+			LogHeadCID:   strOrEmpty(bm.LogHeadCID),
+		})
+	}
+	return digest
+}
+
+// antiEntropyRoundWithPeer POSTs digest to baseURL's /api/v1/sync/digest and
+// applies every divergent entry the peer reports, the same
+// shouldAcceptBoardMetaUpdate accept-or-reject path announceBoard and
+// syncBoardFromTrustedIndexersBestEffort use. It reports whether the round
+// reached and got a valid response from the peer at all (for
+// antiEntropyPeerSet bookkeeping), independent of whether any entry ended
+// up accepted.
+func (s *Server) antiEntropyRoundWithPeer(ctx context.Context, baseURL string, digest []BoardDigestEntry) bool {
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "antiEntropy"), reqlog.F("base_url", baseURL))
+	endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/sync/digest"
+	reqBody, err := json.Marshal(SyncDigestRequest{Boards: digest})
+	if err != nil {
+		return false
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(rctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setOutboundRequestID(req, ctx)
+	s.signOutboundAnnounceRequestIfConfigured(req, reqBody)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256<<10))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+			// The capability probe said digest-sync was supported (or was
+			// never run), but the endpoint itself disagrees — trust the
+			// stronger signal and re-probe before relying on it again.
+			s.invalidatePeerCapabilities(baseURL)
+		}
+		logger.Info("digest round non-2xx response", reqlog.F("status", resp.StatusCode))
+		return false
+	}
+
+	var out SyncDigestResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		logger.Info("digest round decode error", reqlog.F("error", err.Error()))
+		return false
+	}
+
+	for _, entry := range out.Boards {
+		s.applyAntiEntropyEntry(ctx, baseURL, entry)
+	}
+	return true
+}
+
+// antiEntropyRoundWithPeerPerBoard is antiEntropyRoundWithPeer's fallback
+// for a peer whose capabilities probe didn't advertise featureDigestSync
+// (an older node, or one that simply hasn't been probed successfully yet):
+// it GETs each of digest's boards individually from baseURL instead of
+// POSTing the whole digest at once, then funnels whatever BoardMetaCID
+// comes back through the same applyAntiEntropyEntry accept path. It costs
+// one request per board rather than one request per peer, but it's the
+// same per-board GET every pre-digest-sync deployment already serves.
+func (s *Server) antiEntropyRoundWithPeerPerBoard(ctx context.Context, baseURL string, digest []BoardDigestEntry) bool {
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "antiEntropy"), reqlog.F("base_url", baseURL))
+	reached := false
+	for _, entry := range digest {
+		boardID := strings.TrimSpace(entry.BoardID)
+		if boardID == "" {
+			continue
+		}
+		endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/boards/" + url.PathEscape(boardID)
+
+		rctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		req, err := http.NewRequestWithContext(rctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			cancel()
+			continue
+		}
+		setOutboundRequestID(req, ctx)
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			cancel()
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256<<10))
+		_ = resp.Body.Close()
+		cancel()
+		if resp.StatusCode == http.StatusNotFound {
+			reached = true
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			continue
+		}
+		reached = true
+
+		var item BoardItem
+		if err := json.Unmarshal(body, &item); err != nil {
+			logger.Info("per-board decode error", reqlog.F("board_id", boardID), reqlog.F("error", err.Error()))
+			continue
+		}
+		s.applyAntiEntropyEntry(ctx, baseURL, BoardDigestEntry{
+			BoardID:      boardID,
+			BoardMetaCID: strings.TrimSpace(item.BoardMetaCID),
+			LogHeadCID:   strOrEmpty(item.Board.LogHeadCID),
+		})
+	}
+	return reached
+}
+
+// applyAntiEntropyEntry fetches entry's BoardMetaCID (content-addressed, so
+// it's fetched straight through storage the same way announceBoard fetches
+// an announced CID, with no dependence on which peer reported it), verifies
+// it, and applies it if shouldAcceptBoardMetaUpdate agrees. Any failure
+// along the way is logged and swallowed — one peer's stale or unreachable
+// entry shouldn't interrupt the rest of the round.
+func (s *Server) applyAntiEntropyEntry(ctx context.Context, baseURL string, entry BoardDigestEntry) {
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "antiEntropy"), reqlog.F("base_url", baseURL), reqlog.F("board_id", entry.BoardID))
+	boardID := strings.TrimSpace(entry.BoardID)
+	incomingCID := strings.TrimSpace(entry.BoardMetaCID)
+	if boardID == "" || incomingCID == "" {
+		return
+	}
+	if s.seenBoardMetaCIDs != nil && s.seenBoardMetaCIDs.Seen(incomingCID) {
+		return
+	}
+
+	currentCID, currentBM, ok := s.loadBoardByID(ctx, boardID)
+	if !ok || incomingCID == currentCID {
+		return
+	}
+
+	incomingBM, err := s.Storage.LoadBoardMeta(ctx, incomingCID)
+	if err != nil {
+		logger.Info("load boardMeta failed", reqlog.F("cid", incomingCID), reqlog.F("error", err.Error()))
+		return
+	}
+	if !bbslog.VerifyBoardMeta(incomingBM) || strings.TrimSpace(incomingBM.BoardID) != boardID {
+		return
+	}
+
+	accept, _, err := s.shouldAcceptBoardMetaUpdate(ctx, boardID, currentCID, currentBM, incomingCID, incomingBM)
+	if err != nil {
+		logger.Info("accept check failed", reqlog.F("error", err.Error()))
+		return
+	}
+	if !accept {
+		return
+	}
+
+	if err := s.Boards.Upsert(boardID, incomingCID); err != nil {
+		logger.Info("board save failed", reqlog.F("error", err.Error()))
+		return
+	}
+	s.markSeenBoardMetaCID(incomingCID)
+	s.publishNewBoardLogEntries(ctx, boardID, incomingBM, strOrEmpty(currentBM.LogHeadCID))
+	s.publishBoardAnnounce(boardID, incomingCID)
+}
+
+// handleSyncDigest serves the peer side of the anti-entropy protocol: for
+// every board in the caller's digest that this node also tracks, report
+// back this node's own entry when its LogHeadCID differs, so the caller
+// knows to pull it.
+func (s *Server) handleSyncDigest(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.boardRequestContext(r)
+	defer cancel()
+
+	var req SyncDigestRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	out := SyncDigestResponse{Boards: make([]BoardDigestEntry, 0)}
+	for _, entry := range req.Boards {
+		boardID := strings.TrimSpace(entry.BoardID)
+		if boardID == "" {
+			continue
+		}
+		refCID, bm, ok := s.loadBoardByID(ctx, boardID)
+		if !ok {
+			continue
+		}
+		if strOrEmpty(bm.LogHeadCID) == entry.LogHeadCID {
+			continue
+		}
+		out.Boards = append(out.Boards, BoardDigestEntry{
+			BoardID:      boardID,
+			BoardMetaCID: refCID,
+			LogHeadCID:   strOrEmpty(bm.LogHeadCID),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"flex-bbs/backend-go/bbs/attachments"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// validateAttachments confirms every one of atts' CIDs is a blob actually
+// held by s.Attachments and that its declared mime matches what gets
+// sniffed from its bytes, rejecting a post that references a missing blob
+// or lies about an attachment's type. A nil s.Attachments (role/config
+// doesn't serve blobs) skips validation entirely — see Server.Attachments.
+//
+// A non-empty Size or SHA256 is also cross-checked against the stored
+// blob (SHA256 against the CID itself, Size against the store's record),
+// so a Post can't carry an Attachment manifest that disagrees with what
+// it actually points at.
+func (s *Server) validateAttachments(ctx context.Context, atts []types.Attachment) error {
+	if s.Attachments == nil || len(atts) == 0 {
+		return nil
+	}
+	for _, a := range atts {
+		if a.CID == "" {
+			return errors.New("attachment cid is required")
+		}
+		if a.SHA256 != "" {
+			digest, err := attachments.ParseCIDv1Raw(a.CID)
+			if err != nil {
+				return fmt.Errorf("attachment %s: %w", a.CID, err)
+			}
+			if !strings.EqualFold(hex.EncodeToString(digest[:]), a.SHA256) {
+				return fmt.Errorf("attachment %s: declared sha256 %q does not match its cid", a.CID, a.SHA256)
+			}
+		}
+		if a.Size != 0 {
+			rc, meta, err := s.Attachments.Get(ctx, a.CID)
+			if err != nil {
+				if errors.Is(err, attachments.ErrNotFound) {
+					return fmt.Errorf("attachment %s: not found (upload it via POST /api/v1/attachments first)", a.CID)
+				}
+				return err
+			}
+			rc.Close()
+			if meta.Length != a.Size {
+				return fmt.Errorf("attachment %s: declared size %d does not match stored size %d", a.CID, a.Size, meta.Length)
+			}
+		}
+		if err := s.Attachments.VerifyAttachment(ctx, a.CID, a.Mime); err != nil {
+			if errors.Is(err, attachments.ErrNotFound) {
+				return fmt.Errorf("attachment %s: not found (upload it via POST /api/v1/attachments first)", a.CID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadAttachment accepts a raw blob body and stores it, returning the
+// CIDv1 (and its size) callers then reference from a Post's attachments.
+// mime comes from the request's Content-Type, same as any plain file
+// upload. Uploading the same bytes twice returns the same CID both times
+// — see Store.Put.
+func (s *Server) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	if s.Attachments == nil {
+		writeError(w, http.StatusNotImplemented, "attachments are not configured on this server")
+		return
+	}
+	mime := r.Header.Get("Content-Type")
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	defer r.Body.Close()
+	cid, size, err := s.Attachments.Put(r.Context(), http.MaxBytesReader(w, r.Body, 64<<20), mime)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"cid": cid, "size": size, "mime": mime})
+}
+
+// getBlob serves a previously uploaded attachment by CID. The CID is
+// itself a content hash, so it doubles as a strong ETag — the response is
+// safe to cache as immutable, and http.ServeContent answers conditional
+// (If-None-Match) and Range requests against it without this handler
+// having to implement either itself.
+func (s *Server) getBlob(w http.ResponseWriter, r *http.Request) {
+	if s.Attachments == nil {
+		writeError(w, http.StatusNotImplemented, "attachments are not configured on this server")
+		return
+	}
+	cid := r.PathValue("cid")
+	rc, meta, err := s.Attachments.Get(r.Context(), cid)
+	if err != nil {
+		if errors.Is(err, attachments.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "blob not found")
+			return
+		}
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer rc.Close()
+
+	rs, ok := rc.(io.ReadSeeker)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "blob store returned a non-seekable reader")
+		return
+	}
+
+	w.Header().Set("ETag", `"`+strings.TrimPrefix(meta.CID, "b")+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Content-Type", meta.Mime)
+	http.ServeContent(w, r, meta.CID, time.Time{}, rs)
+}
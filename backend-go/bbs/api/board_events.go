@@ -0,0 +1,438 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// boardEventHeartbeatInterval is how often handleBoardEventsSSE/
+// handleThreadEventsSSE write a ": heartbeat" comment line, so an idle
+// connection (and any proxy in front of it) doesn't time it out for lack
+// of traffic.
+const boardEventHeartbeatInterval = 15 * time.Second
+
+// boardEvent is one BoardLogEntry append published to eventBus
+// subscribers. Its SSE "event:" name is e.Op (createThread/addPost/
+// editPost/tombstonePost), so a client can dispatch on the event name
+// without parsing data first.
+type boardEvent struct {
+	Op           string `json:"op"`
+	BoardID      string `json:"boardId"`
+	LogCID       string `json:"logCid"`
+	ThreadID     string `json:"threadId,omitempty"`
+	PostCID      string `json:"postCid,omitempty"`
+	AuthorPubKey string `json:"authorPubKey,omitempty"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+}
+
+// eventBusBufferSize bounds each subscriber's channel; see eventBus.publish.
+const eventBusBufferSize = 64
+
+// eventBus fans boardEvents out to every SSE subscriber of the board they
+// belong to, each with its own bounded buffer — the same per-subscriber
+// shape as bbs/indexer's eventHub, kept as a separate (smaller) type here
+// because this bus is published to directly from the write handlers
+// (createThread, addPost, editPost, tombstonePost, advanceBoardLogHead,
+// syncBoardFromTrustedIndexersBestEffort) and has no reason to depend on
+// an Indexer being configured.
+type eventBus struct {
+	mu        sync.Mutex
+	subs      map[int]*boardEventSub
+	nextSubID int
+
+	// droppedSubscribersTotal counts subscribers evicted for falling
+	// behind; see publish.
+	droppedSubscribersTotal int64
+}
+
+type boardEventSub struct {
+	ch      chan boardEvent
+	boardID string
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[int]*boardEventSub)}
+}
+
+// subscribe registers a listener for every boardEvent published for
+// boardID and returns its channel plus an unsubscribe func. The channel
+// is closed by unsubscribe or, if this subscriber falls behind, by
+// publish itself.
+func (b *eventBus) subscribe(boardID string) (<-chan boardEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextSubID
+	b.nextSubID++
+	sub := &boardEventSub{ch: make(chan boardEvent, eventBusBufferSize), boardID: boardID}
+	b.subs[id] = sub
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if s, ok := b.subs[id]; ok {
+				delete(b.subs, id)
+				close(s.ch)
+			}
+		})
+	}
+	return sub.ch, unsubscribe
+}
+
+// publish fans e out to every subscriber of e.BoardID. A subscriber whose
+// buffer is already full when its turn comes is evicted — its channel
+// closed and droppedSubscribersTotal incremented — rather than letting it
+// block publish or any other subscriber. A dropped subscriber only misses
+// events from the point it was dropped; nothing here redelivers.
+func (b *eventBus) publish(e boardEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, sub := range b.subs {
+		if sub.boardID != e.BoardID {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(b.subs, id)
+			close(sub.ch)
+			atomic.AddInt64(&b.droppedSubscribersTotal, 1)
+		}
+	}
+}
+
+// publishBoardEvent is a no-op until s.events exists — initNetworkDeps
+// lazily creates it, same "nil disables" convention as the rest of
+// Server's optional deps, so a Server built directly in a test without
+// going through Handler()/initNetworkDeps still works, it just has no
+// subscribers to tell.
+func (s *Server) publishBoardEvent(e boardEvent) {
+	if s.events == nil {
+		return
+	}
+	s.events.publish(e)
+}
+
+// boardEventFromEntry builds the boardEvent publishBoardEvent expects
+// from a just-appended BoardLogEntry and the CID it saved under.
+func boardEventFromEntry(boardID, logCID string, e *types.BoardLogEntry) boardEvent {
+	return boardEvent{
+		Op:           e.Op,
+		BoardID:      boardID,
+		LogCID:       logCID,
+		ThreadID:     e.ThreadID,
+		PostCID:      strOrEmpty(e.PostCID),
+		AuthorPubKey: e.AuthorPubKey,
+		CreatedAt:    e.CreatedAt,
+	}
+}
+
+// boardLogEntriesAfter walks bm's board log (oldest first) and returns
+// only the entries after afterCID, for replaying what an SSE client with
+// a Last-Event-ID/?cursor= missed while disconnected. An empty afterCID
+// replays the whole log. found is false if afterCID was non-empty but
+// doesn't name an entry in this chain (a client resuming from a CID this
+// server can no longer see, e.g. after PrevLogCID diverged).
+func (s *Server) boardLogEntriesAfter(ctx context.Context, bm *types.BoardMeta, afterCID string) (entries []bbslog.EntryWithCID[types.BoardLogEntry], found bool, err error) {
+	loadLog := storage.Load(s.fetcher(), s.Storage.LoadBoardLogEntry)
+	chain, err := bbslog.FetchChain(ctx, bm.LogHeadCID, loadLog, func(e *types.BoardLogEntry) *string {
+		return e.PrevLogCID
+	}, bbslog.VerifyBoardLogEntry, 50_000)
+	if err != nil {
+		return nil, false, err
+	}
+	if afterCID == "" {
+		return chain, true, nil
+	}
+	for i, item := range chain {
+		if item.CID == afterCID {
+			return chain[i+1:], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// lastEventID returns the SSE resume cursor a client sent, preferring the
+// standard Last-Event-ID header and falling back to ?cursor= (or its
+// ?fromCid= alias, read by the /api/v1/watch/* routes) for a plain browser
+// EventSource that can't set custom headers on reconnect (it resends
+// Last-Event-ID automatically, but only once a stream has already
+// delivered at least one "id:" line — the query param lets a client seed
+// its very first connection too).
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		return cursor
+	}
+	return r.URL.Query().Get("fromCid")
+}
+
+// handleBoardEventsSSE serves GET /api/v1/boards/{boardId}/events: a
+// text/event-stream of boardEvents for every BoardLogEntry appended to
+// boardId, resumable by CID (see lastEventID).
+func (s *Server) handleBoardEventsSSE(w http.ResponseWriter, r *http.Request) {
+	boardID := r.PathValue("boardId")
+	s.streamBoardEvents(w, r, boardID, "")
+}
+
+// handleThreadEventsSSE serves GET /api/v1/threads/{threadId}/events: the
+// same stream as handleBoardEventsSSE, narrowed to events naming this
+// thread (plus the createThread event that established it).
+func (s *Server) handleThreadEventsSSE(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	threadID := r.PathValue("threadId")
+
+	tm, err := s.Storage.LoadThreadMeta(ctx, threadID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "thread not found")
+		return
+	}
+	if !bbslog.VerifyThreadMeta(tm) {
+		writeError(w, http.StatusBadGateway, "invalid threadMeta signature")
+		return
+	}
+	s.streamBoardEvents(w, r, tm.BoardID, threadID)
+}
+
+// streamBoardEvents is the shared SSE loop behind handleBoardEventsSSE and
+// handleThreadEventsSSE: subscribe to boardID first (so nothing published
+// between subscribing and replaying the backlog is missed), replay
+// anything after the client's resume cursor, then switch to live
+// delivery with a heartbeat every boardEventHeartbeatInterval.
+// subscriberThreadID, if non-empty, additionally filters both the replay
+// and the live stream down to events naming that thread.
+func (s *Server) streamBoardEvents(w http.ResponseWriter, r *http.Request, boardID, subscriberThreadID string) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	_, bm, ok := s.loadBoardByID(ctx, boardID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "board not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "streaming not supported")
+		return
+	}
+
+	s.initNetworkDeps()
+	ch, unsubscribe := s.events.subscribe(boardID)
+	defer unsubscribe()
+
+	backlog, found, err := s.boardLogEntriesAfter(ctx, bm, lastEventID(r))
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	if !found {
+		writeError(w, http.StatusBadRequest, "unknown Last-Event-ID/cursor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// CIDs already sent from the backlog, so a duplicate delivered live
+	// (appended after subscribe but already captured by the backlog walk
+	// above) isn't written twice.
+	seen := make(map[string]struct{}, len(backlog))
+	for _, item := range backlog {
+		if !item.ValidSignature {
+			continue
+		}
+		e := boardEventFromEntry(boardID, item.CID, item.Value)
+		if subscriberThreadID != "" && e.ThreadID != subscriberThreadID {
+			continue
+		}
+		writeBoardSSEEvent(w, e)
+		flusher.Flush()
+		seen[item.CID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(boardEventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, dup := seen[e.LogCID]; dup {
+				delete(seen, e.LogCID)
+				continue
+			}
+			if subscriberThreadID != "" && e.ThreadID != subscriberThreadID {
+				continue
+			}
+			writeBoardSSEEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// boardAnnounceEvent is published whenever a board's BoardMetaCID advances,
+// whether from a local write (advanceBoardLogHead) or an accepted
+// POST /api/v1/announce/board. Unlike boardEvent (one board's log
+// entries), this fans out across every board at once, for a directory-level
+// "something changed" feed rather than one board's own activity.
+type boardAnnounceEvent struct {
+	BoardID      string `json:"boardId"`
+	BoardMetaCID string `json:"boardMetaCid"`
+}
+
+// boardAnnounceBusBufferSize bounds each subscriber's channel; see
+// boardAnnounceBus.publish.
+const boardAnnounceBusBufferSize = 64
+
+// boardAnnounceBus fans boardAnnounceEvents out to every GET
+// /api/v1/watch/boards subscriber, with the same bounded-buffer,
+// drop-on-full-rather-than-block behavior as eventBus.
+type boardAnnounceBus struct {
+	mu        sync.Mutex
+	subs      map[int]chan boardAnnounceEvent
+	nextSubID int
+}
+
+func newBoardAnnounceBus() *boardAnnounceBus {
+	return &boardAnnounceBus{subs: make(map[int]chan boardAnnounceEvent)}
+}
+
+func (b *boardAnnounceBus) subscribe() (<-chan boardAnnounceEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextSubID
+	b.nextSubID++
+	ch := make(chan boardAnnounceEvent, boardAnnounceBusBufferSize)
+	b.subs[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if s, ok := b.subs[id]; ok {
+				delete(b.subs, id)
+				close(s)
+			}
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (b *boardAnnounceBus) publish(e boardAnnounceEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// publishBoardAnnounce is a no-op until s.boardAnnounces exists, same
+// "nil disables" convention as publishBoardEvent.
+func (s *Server) publishBoardAnnounce(boardID, boardMetaCID string) {
+	if s.boardAnnounces == nil {
+		return
+	}
+	s.boardAnnounces.publish(boardAnnounceEvent{BoardID: boardID, BoardMetaCID: boardMetaCID})
+}
+
+// watchBoards serves GET /api/v1/watch/boards: an text/event-stream of
+// boardAnnounceEvents for every board whose BoardMetaCID advances on this
+// server, local writes and accepted announces alike. There's no resume
+// cursor here (unlike the per-board streams): each board's own history is
+// already replayable from GET /api/v1/boards/{boardId}/events, and a
+// directory-level feed spanning every board has no single chain to walk
+// back through.
+func (s *Server) watchBoards(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "streaming not supported")
+		return
+	}
+
+	s.initNetworkDeps()
+	ch, unsubscribe := s.boardAnnounces.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(boardEventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: boardAnnounce\ndata: %s\n\n", b)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchThreads serves GET /api/v1/watch/threads?boardId=...: the same
+// stream as GET /api/v1/boards/{boardId}/events, addressed by query
+// parameter instead of path segment for parity with watchBoards.
+func (s *Server) watchThreads(w http.ResponseWriter, r *http.Request) {
+	boardID := strings.TrimSpace(r.URL.Query().Get("boardId"))
+	if boardID == "" {
+		writeError(w, http.StatusBadRequest, "boardId is required")
+		return
+	}
+	s.streamBoardEvents(w, r, boardID, "")
+}
+
+func writeBoardSSEEvent(w http.ResponseWriter, e boardEvent) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.LogCID, e.Op, b)
+}
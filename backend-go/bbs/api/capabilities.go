@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiProtocol and apiProtocolVersion identify the HTTP protocol this
+// server speaks on GET /api/v1/capabilities, independent of any future
+// application release versioning — bumping apiProtocolVersion signals a
+// breaking change to the request/response shapes themselves.
+const (
+	apiProtocol        = "flex-bbs-api"
+	apiProtocolVersion = "1"
+)
+
+// Feature names this server may advertise on GET /api/v1/capabilities.
+// A peer checks these (via peerSupportsFeature) before relying on the
+// corresponding optional behavior instead of discovering a 404/501 the
+// hard way.
+const (
+	featureWatch       = "watch"
+	featureDigestSync  = "digest-sync"
+	featureEd25519Auth = "ed25519-auth"
+)
+
+// capabilityProbeTTL bounds how long a cached peerCapabilitySet entry is
+// trusted before it's re-probed; peerSupportsFeature also forces a
+// re-probe outside this TTL when the caller reports a 404/501 from the
+// gated endpoint itself (invalidatePeerCapabilities), since that's a more
+// direct signal than the clock that the cached entry is stale.
+const capabilityProbeTTL = 15 * time.Minute
+
+// capabilities builds this node's own CapabilitiesResponse: watch and
+// digest-sync are always available (the routes are registered
+// unconditionally in Handler), ed25519-auth is only advertised when
+// AnnounceAuth is actually configured, since that's the piece of
+// information a caller needs — whether this node will reject an unsigned
+// announce/sync request.
+func (s *Server) capabilities() CapabilitiesResponse {
+	features := []string{featureWatch, featureDigestSync}
+	if s.AnnounceAuth != nil {
+		features = append(features, featureEd25519Auth)
+	}
+	return CapabilitiesResponse{
+		Version:  apiProtocolVersion,
+		Protocol: apiProtocol,
+		Features: features,
+	}
+}
+
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	_ = r
+	writeJSON(w, http.StatusOK, s.capabilities())
+}
+
+// peerCapabilityEntry is one cached probe result, paired with when it was
+// fetched so peerCapabilitySet.Get can decide whether it's still fresh.
+type peerCapabilityEntry struct {
+	capabilities CapabilitiesResponse
+	err          error
+	fetchedAt    time.Time
+}
+
+// peerCapabilitySet is the process-wide cache of per-trusted-indexer
+// capability probes, keyed by normalized base URL. Unlike antiEntropyPeerSet
+// (which tracks reachability), this tracks what a reachable peer actually
+// supports, so it's consulted before a call rather than after a failure.
+type peerCapabilitySet struct {
+	mu sync.Mutex
+	m  map[string]*peerCapabilityEntry
+}
+
+func newPeerCapabilitySet() *peerCapabilitySet {
+	return &peerCapabilitySet{m: make(map[string]*peerCapabilityEntry)}
+}
+
+func (s *peerCapabilitySet) get(baseURL string) (*peerCapabilityEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.m[baseURL]
+	return e, ok
+}
+
+func (s *peerCapabilitySet) set(baseURL string, caps CapabilitiesResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[baseURL] = &peerCapabilityEntry{capabilities: caps, err: err, fetchedAt: time.Now()}
+}
+
+// invalidate drops baseURL's cached entry outright, forcing the next
+// peerSupportsFeature call to re-probe regardless of capabilityProbeTTL.
+func (s *peerCapabilitySet) invalidate(baseURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, baseURL)
+}
+
+func (s *peerCapabilitySet) snapshot() map[string]*peerCapabilityEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]*peerCapabilityEntry, len(s.m))
+	for k, v := range s.m {
+		out[k] = v
+	}
+	return out
+}
+
+// probePeerCapabilities fetches baseURL's GET /api/v1/capabilities.
+func (s *Server) probePeerCapabilities(ctx context.Context, baseURL string) (CapabilitiesResponse, error) {
+	endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/capabilities"
+	rctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(rctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return CapabilitiesResponse{}, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return CapabilitiesResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<10))
+	if err != nil {
+		return CapabilitiesResponse{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return CapabilitiesResponse{}, errCapabilityProbeFailed(resp.StatusCode)
+	}
+	var out CapabilitiesResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return CapabilitiesResponse{}, err
+	}
+	return out, nil
+}
+
+type errCapabilityProbeFailed int
+
+func (e errCapabilityProbeFailed) Error() string {
+	return "capabilities probe: unexpected http status"
+}
+
+// peerSupportsFeature reports whether baseURL's most recent capability
+// probe (re-probing first if missing, expired past capabilityProbeTTL, or
+// never successfully reached) advertised feature. A peer this node has
+// never successfully probed is assumed not to support the feature — the
+// caller falls back to the conservative path rather than guessing.
+func (s *Server) peerSupportsFeature(ctx context.Context, baseURL string, feature string) bool {
+	if s.peerCapabilities == nil {
+		return false
+	}
+	if e, ok := s.peerCapabilities.get(baseURL); ok && e.err == nil && time.Since(e.fetchedAt) < capabilityProbeTTL {
+		return hasFeature(e.capabilities.Features, feature)
+	}
+
+	caps, err := s.probePeerCapabilities(ctx, baseURL)
+	s.peerCapabilities.set(baseURL, caps, err)
+	if err != nil {
+		return false
+	}
+	return hasFeature(caps.Features, feature)
+}
+
+// invalidatePeerCapabilities is called when a call to baseURL's gated
+// endpoint itself reports 404/501 — a stronger, more immediate signal
+// than capabilityProbeTTL that the cached capabilities (if any) are wrong
+// or stale, so the next peerSupportsFeature call re-probes instead of
+// trusting the cache for up to another capabilityProbeTTL.
+func (s *Server) invalidatePeerCapabilities(baseURL string) {
+	if s.peerCapabilities != nil {
+		s.peerCapabilities.invalidate(baseURL)
+	}
+}
+
+func hasFeature(features []string, feature string) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// probeAllTrustedIndexersOnce runs one capability probe against every
+// configured trusted indexer, populating peerCapabilities before the
+// first real anti-entropy round or announce forward needs to consult it.
+// It's started once, from initNetworkDeps (capabilitiesOnce), the same
+// "kick off a best-effort background pass at startup" shape
+// runAntiEntropyLoop uses for its own first tick.
+func (s *Server) probeAllTrustedIndexersOnce(ctx context.Context) {
+	if s.TrustedIndexers == nil {
+		return
+	}
+	if err := s.TrustedIndexers.Load(); err != nil {
+		return
+	}
+	peers := s.TrustedIndexers.List()
+	if len(peers) == 0 {
+		return
+	}
+	pool := newFetchPool(s.antiEntropyConcurrencyOrDefault())
+	pool.forEach(len(peers), func(i int) {
+		baseURL := peers[i]
+		caps, err := s.probePeerCapabilities(ctx, baseURL)
+		s.peerCapabilities.set(baseURL, caps, err)
+	})
+}
+
+// handlePeers serves GET /api/v1/peers: every trusted indexer's base URL
+// alongside the capabilities this node last probed for it, for operators
+// to check which peers support which optional behaviors without reading
+// logs.
+func (s *Server) handlePeers(w http.ResponseWriter, r *http.Request) {
+	_ = r
+	out := make([]PeerCapabilities, 0)
+	if s.TrustedIndexers != nil {
+		if err := s.TrustedIndexers.Load(); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		snapshot := s.peerCapabilities.snapshot()
+		for _, baseURL := range s.TrustedIndexers.List() {
+			entry := PeerCapabilities{BaseURL: baseURL}
+			if e, ok := snapshot[baseURL]; ok {
+				entry.Capabilities = e.capabilities
+				entry.ProbedAt = e.fetchedAt.UTC().Format(time.RFC3339)
+				if e.err != nil {
+					entry.Err = e.err.Error()
+				}
+			}
+			out = append(out, entry)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pageCursor is a decoded max_id/min_id pagination bound: the (createdAt,
+// cid) position of the last row the caller has already seen.
+type pageCursor struct {
+	createdAt time.Time
+	cid       string
+}
+
+// encodeCursor builds the opaque value accepted by the max_id/min_id query
+// parameters: base64url (no padding) of the row's CreatedAt (RFC3339Nano)
+// and cid, joined by "|". The cid tie-breaker is required because rows
+// (board-log entries especially) can share a CreatedAt at second
+// precision; sort order and cursor comparisons both key off the
+// (createdAt, cid) tuple so a page never skips or repeats a row as the
+// underlying log grows between requests.
+func encodeCursor(createdAt time.Time, cid string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + cid
+	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString([]byte(raw))
+}
+
+// decodeCursor reverses encodeCursor.
+func decodeCursor(raw string) (createdAt time.Time, cid string, err error) {
+	b, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("malformed cursor encoding: %w", err)
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return time.Time{}, "", fmt.Errorf("malformed cursor contents")
+	}
+	createdAt, err = time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("malformed cursor timestamp: %w", err)
+	}
+	return createdAt, parts[1], nil
+}
+
+// parsePageCursors reads the max_id/min_id query parameters into decoded
+// pagination bounds. A nil return for either means the caller didn't send
+// that bound.
+func parsePageCursors(r *http.Request) (maxID, minID *pageCursor, err error) {
+	if raw := r.URL.Query().Get("max_id"); raw != "" {
+		at, cid, derr := decodeCursor(raw)
+		if derr != nil {
+			return nil, nil, fmt.Errorf("invalid max_id: %w", derr)
+		}
+		maxID = &pageCursor{createdAt: at, cid: cid}
+	}
+	if raw := r.URL.Query().Get("min_id"); raw != "" {
+		at, cid, derr := decodeCursor(raw)
+		if derr != nil {
+			return nil, nil, fmt.Errorf("invalid min_id: %w", derr)
+		}
+		minID = &pageCursor{createdAt: at, cid: cid}
+	}
+	return maxID, minID, nil
+}
+
+// parseSQLPageCursors is parsePageCursors for the SQL-backed search
+// endpoints, which filter directly on a created_at column stored as plain
+// RFC3339 text rather than holding a time.Time around for comparisons.
+func parseSQLPageCursors(r *http.Request) (maxAt, maxCID, minAt, minCID string, err error) {
+	if raw := r.URL.Query().Get("max_id"); raw != "" {
+		at, cid, derr := decodeCursor(raw)
+		if derr != nil {
+			return "", "", "", "", fmt.Errorf("invalid max_id: %w", derr)
+		}
+		maxAt, maxCID = at.UTC().Format(time.RFC3339), cid
+	}
+	if raw := r.URL.Query().Get("min_id"); raw != "" {
+		at, cid, derr := decodeCursor(raw)
+		if derr != nil {
+			return "", "", "", "", fmt.Errorf("invalid min_id: %w", derr)
+		}
+		minAt, minCID = at.UTC().Format(time.RFC3339), cid
+	}
+	return maxAt, maxCID, minAt, minCID, nil
+}
+
+// compareCursorTuple orders (createdAt, cid) tuples the same way every
+// cursor-paginated listing is sorted: newest first, cid descending as the
+// tie-breaker. It returns -1 if a is older than b, 1 if a is newer, 0 if
+// they're the same position.
+func compareCursorTuple(atA time.Time, cidA string, atB time.Time, cidB string) int {
+	switch {
+	case atA.Before(atB):
+		return -1
+	case atA.After(atB):
+		return 1
+	case cidA < cidB:
+		return -1
+	case cidA > cidB:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// cursorWindow slices a (createdAt DESC, cid DESC) sorted list down to one
+// page, honoring an optional max_id (keep only rows strictly older than the
+// cursor) and min_id (keep only rows strictly newer, still in newest-first
+// order). filled reports whether rows older than the page remain (so a
+// "next" Link is worth emitting), and hasNewer reports whether rows newer than the
+// page were excluded (so a "prev" Link is worth emitting).
+func cursorWindow[T any](items []T, at func(T) time.Time, cid func(T) string, maxID, minID *pageCursor, limit int) (page []T, filled, hasNewer bool) {
+	start, end := 0, len(items)
+	if maxID != nil {
+		start = len(items)
+		for i, it := range items {
+			if compareCursorTuple(at(it), cid(it), maxID.createdAt, maxID.cid) < 0 {
+				start = i
+				break
+			}
+		}
+	}
+	if minID != nil {
+		end = 0
+		for i, it := range items {
+			if compareCursorTuple(at(it), cid(it), minID.createdAt, minID.cid) <= 0 {
+				break
+			}
+			end = i + 1
+		}
+	}
+	if start > end {
+		start = end
+	}
+	window := items[start:end]
+	hasNewer = start > 0
+	if len(window) > limit {
+		window = window[:limit]
+		filled = true
+	} else {
+		filled = end-start > 0 && start+len(window) < len(items)
+	}
+	return window, filled, hasNewer
+}
+
+// writeLinkHeader sets the RFC 5988 Link header for a cursor-paginated
+// response: rel="next" (older items, built from the oldest row on the
+// page) when the page was filled, and rel="prev" (newer items, built from
+// the newest row on the page) when the caller supplied min_id or newer
+// rows exist ahead of the page. It must be called before the response
+// body is written, since headers can't follow a WriteHeader call.
+func writeLinkHeader(w http.ResponseWriter, r *http.Request, nextCursor, prevCursor string) {
+	var links []string
+	if nextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorPageURL(r, "max_id", nextCursor)))
+	}
+	if prevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorPageURL(r, "min_id", prevCursor)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// cursorPageURL rebuilds the request's query string with max_id/min_id
+// replaced by the given cursor, leaving limit and any other params intact.
+func cursorPageURL(r *http.Request, param, value string) string {
+	u := *r.URL
+	q := u.Query()
+	q.Del("max_id")
+	q.Del("min_id")
+	q.Set(param, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
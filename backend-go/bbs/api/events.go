@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	bbsindexer "flex-bbs/backend-go/bbs/indexer"
+)
+
+// streamEvents tails Indexer.Subscribe for external clients (the C# and
+// future JS clients PostMeta.Client hints at) that want to follow a board
+// in real time instead of polling /api/v1/search/posts. It upgrades to a
+// websocket when the client asks for one (Upgrade: websocket), and falls
+// back to a chunked newline-delimited-JSON response otherwise, so a plain
+// HTTP client can tail the same stream with nothing fancier than a GET.
+//
+// Query params: boardId, threadId, author filter like their Subscribe
+// counterparts; types is an optional comma-separated list of event type
+// names (e.g. "post_indexed,post_tombstoned") narrowing which types are
+// delivered.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if s.Indexer == nil {
+		writeError(w, http.StatusServiceUnavailable, "indexer not configured")
+		return
+	}
+
+	filter := bbsindexer.EventFilter{
+		BoardID:      strings.TrimSpace(r.URL.Query().Get("boardId")),
+		ThreadID:     strings.TrimSpace(r.URL.Query().Get("threadId")),
+		AuthorPubKey: strings.TrimSpace(r.URL.Query().Get("author")),
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("types")); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, bbsindexer.EventType(t))
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	ch, unsubscribe, err := s.Indexer.Subscribe(ctx, filter)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer unsubscribe()
+
+	if ws, err := upgradeWebsocket(w, r); err == nil {
+		s.streamEventsWebsocket(ws, ch)
+		return
+	}
+	s.streamEventsHTTP(w, r, ch)
+}
+
+func (s *Server) streamEventsWebsocket(ws *websocketConn, ch <-chan bbsindexer.Event) {
+	defer ws.Close()
+
+	// The bridge only ever pushes; ReadLoop exists purely to notice the
+	// client going away (a close frame, or the read erroring out) without
+	// waiting on the next WriteText to find out the hard way.
+	done := make(chan struct{})
+	go func() {
+		_ = ws.ReadLoop()
+		close(done)
+	}()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if err := ws.WriteText(b); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *Server) streamEventsHTTP(w http.ResponseWriter, r *http.Request, ch <-chan bbsindexer.Event) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
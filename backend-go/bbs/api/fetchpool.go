@@ -0,0 +1,43 @@
+package api
+
+import "sync"
+
+// defaultMaxConcurrentFetches is Server.MaxConcurrentFetches's default: a
+// handler fanning out to many trusted indexers, or loading many
+// independently-known storage CIDs (e.g. a board's distinct ThreadMeta
+// entries), is bounded to this many requests/loads in flight at once, so a
+// burst of traffic against a board with hundreds of peers or threads can't
+// open unbounded goroutines and sockets.
+const defaultMaxConcurrentFetches = 8
+
+// fetchPool bounds concurrent work to its configured size via a semaphore
+// channel — the same pattern bbs/log.FetchChainsConcurrent uses for
+// fetching independent chains in parallel, applied here to the API layer's
+// own fan-out points (trusted-indexer sync, per-thread metadata loads).
+type fetchPool struct {
+	sem chan struct{}
+}
+
+func newFetchPool(size int) *fetchPool {
+	if size <= 0 {
+		size = defaultMaxConcurrentFetches
+	}
+	return &fetchPool{sem: make(chan struct{}, size)}
+}
+
+// forEach runs fn(i) for every i in [0,n), at most p's configured size of
+// them at a time, and blocks until every call has returned.
+func (p *fetchPool) forEach(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		p.sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-p.sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
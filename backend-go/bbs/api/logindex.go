@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/storage"
+)
+
+// isBoardLogDescendantIndexed is isBoardLogDescendant's O(log n) path: once
+// both CIDs' depths (distance from the board's genesis entry) are known,
+// the deeper one's ancestor at the shallower one's depth is found via
+// binary-lifting jumps (logIndexAncestorAt) instead of a hop-by-hop walk.
+func (s *Server) isBoardLogDescendantIndexed(ctx context.Context, boardID, headCID, ancestorCID string) (bool, error) {
+	headEntry, err := s.logIndexEntry(ctx, boardID, headCID)
+	if err != nil {
+		return false, err
+	}
+	ancestorEntry, err := s.logIndexEntry(ctx, boardID, ancestorCID)
+	if err != nil {
+		return false, err
+	}
+	if headEntry.Depth < ancestorEntry.Depth {
+		return false, nil
+	}
+	at, err := s.logIndexAncestorAt(ctx, boardID, headCID, ancestorEntry.Depth)
+	if err != nil {
+		return false, err
+	}
+	return at == ancestorCID, nil
+}
+
+// logIndexEntry returns cid's cached storage.LogIndexEntry, populating it
+// (and every entry between cid and the nearest already-cached ancestor, or
+// the board's genesis entry) first if it's missing. The walk down is
+// iterative, not recursive, so a cold cache on a long-unindexed chain costs
+// exactly what isBoardLogDescendantLinear would have — the index only pays
+// off on repeat lookups, which is the common case for a hot indexer/full
+// node fielding announces for the same small set of boards.
+func (s *Server) logIndexEntry(ctx context.Context, boardID, cid string) (storage.LogIndexEntry, error) {
+	if entry, ok := s.logIndex.Get(cid); ok {
+		return entry, nil
+	}
+
+	type step struct {
+		cid     string
+		prevCID string
+	}
+	var chain []step
+	current := cid
+	baseDepth := -1
+	for {
+		if entry, ok := s.logIndex.Get(current); ok {
+			baseDepth = entry.Depth
+			break
+		}
+		if len(chain) >= 50_000 {
+			return storage.LogIndexEntry{}, bbslog.ErrChainTooLong
+		}
+
+		e, err := s.Storage.LoadBoardLogEntry(ctx, current)
+		if err != nil {
+			return storage.LogIndexEntry{}, err
+		}
+		if !bbslog.VerifyBoardLogEntry(e) {
+			return storage.LogIndexEntry{}, fmt.Errorf("invalid boardLogEntry signature cid=%s", current)
+		}
+		if e.BoardID != boardID {
+			return storage.LogIndexEntry{}, fmt.Errorf("boardLogEntry boardId mismatch cid=%s got=%s want=%s", current, e.BoardID, boardID)
+		}
+
+		prevCID := strOrEmpty(e.PrevLogCID)
+		chain = append(chain, step{cid: current, prevCID: prevCID})
+		if prevCID == "" {
+			break
+		}
+		current = prevCID
+	}
+
+	depth := baseDepth
+	var last storage.LogIndexEntry
+	for i := len(chain) - 1; i >= 0; i-- {
+		depth++
+		entry := storage.LogIndexEntry{Depth: depth, PrevCID: chain[i].prevCID}
+		s.logIndex.Put(chain[i].cid, entry)
+		last = entry
+	}
+	return last, nil
+}
+
+// logIndexUp returns cid's ancestor 1<<k hops back, computing (and caching
+// via storage.LogIndex.SetUp) it the first time from already-known or
+// lazily-resolved shallower jumps: up(cid, k) = up(up(cid, k-1), k-1).
+// entry is cid's own already-resolved storage.LogIndexEntry, passed in so
+// callers walking a chain of jumps don't each re-fetch it from the cache.
+func (s *Server) logIndexUp(ctx context.Context, boardID, cid string, entry storage.LogIndexEntry, k int) (string, error) {
+	if k == 0 {
+		return entry.PrevCID, nil
+	}
+	if k < len(entry.Up) && entry.Up[k] != "" {
+		return entry.Up[k], nil
+	}
+
+	half, err := s.logIndexUp(ctx, boardID, cid, entry, k-1)
+	if err != nil || half == "" {
+		return half, err
+	}
+	halfEntry, err := s.logIndexEntry(ctx, boardID, half)
+	if err != nil {
+		return "", err
+	}
+	result, err := s.logIndexUp(ctx, boardID, half, halfEntry, k-1)
+	if err != nil {
+		return "", err
+	}
+	s.logIndex.SetUp(cid, k, result)
+	return result, nil
+}
+
+// logIndexAncestorAt returns cid's ancestor at targetDepth (which must be
+// <= cid's own depth), decomposing the hop count into its set bits so the
+// walk takes O(log n) jumps instead of one hop per level.
+func (s *Server) logIndexAncestorAt(ctx context.Context, boardID, cid string, targetDepth int) (string, error) {
+	entry, err := s.logIndexEntry(ctx, boardID, cid)
+	if err != nil {
+		return "", err
+	}
+	remaining := entry.Depth - targetDepth
+	if remaining < 0 {
+		return "", fmt.Errorf("logindex: target depth %d is above cid's own depth %d", targetDepth, entry.Depth)
+	}
+
+	for remaining > 0 {
+		k := bits.Len(uint(remaining)) - 1
+		next, err := s.logIndexUp(ctx, boardID, cid, entry, k)
+		if err != nil {
+			return "", err
+		}
+		if next == "" {
+			return "", fmt.Errorf("logindex: ran out of ancestors above depth 0 looking for depth %d", targetDepth)
+		}
+		cid = next
+		remaining -= 1 << k
+		entry, err = s.logIndexEntry(ctx, boardID, cid)
+		if err != nil {
+			return "", err
+		}
+	}
+	return cid, nil
+}
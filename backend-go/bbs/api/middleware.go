@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"flex-bbs/backend-go/bbs/reqlog"
+)
+
+// requestIDHeader is read (and, if absent, set) by WithRequestID so a
+// caller and every hop in between can correlate one request's logs.
+const requestIDHeader = "X-Request-ID"
+
+// crockford32 is the Crockford base32 alphabet newRequestID encodes into:
+// no I/L/O/U, so a request ID read aloud or copy-pasted can't be confused
+// with 1/1/0/V.
+const crockford32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newRequestID returns a 26-character, lexicographically sortable ID: a
+// 48-bit millisecond timestamp followed by 80 bits of randomness, encoded
+// like a ULID (https://github.com/ulid/spec). The repo has no ULID
+// dependency to reach for, and request IDs don't need anything fancier
+// than what crypto/rand already gives every other ID in this codebase.
+func newRequestID() string {
+	var entropy [10]byte
+	if _, err := rand.Read(entropy[:]); err != nil {
+		// crypto/rand failing is effectively unrecoverable for a process
+		// that also signs posts; degrade to an all-zero entropy suffix
+		// rather than panicking over a correlation ID.
+		entropy = [10]byte{}
+	}
+
+	var buf [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint64(buf[:8], ms<<16)
+	copy(buf[6:], entropy[:])
+
+	// buf is 128 bits; encoded 5 bits at a time that's 25.6 base32 digits,
+	// so the 26-digit encoding below treats it as a 130-bit value with 2
+	// zero bits padded onto the most significant end, same as a reference
+	// ULID encoder.
+	out := make([]byte, 26)
+	for i := range out {
+		out[i] = crockford32[bits130(buf, i*5)]
+	}
+	return string(out)
+}
+
+// bits130 reads the 5-bit group starting at bit offset pos out of buf,
+// treated as a 130-bit value whose top 2 bits are always 0 (buf itself is
+// only 128 bits).
+func bits130(buf [16]byte, pos int) byte {
+	var v byte
+	for i := 0; i < 5; i++ {
+		bitPos := pos + i - 2
+		var bit byte
+		if bitPos >= 0 {
+			byteIdx, bitOff := bitPos/8, uint(bitPos%8)
+			bit = (buf[byteIdx] >> (7 - bitOff)) & 1
+		}
+		v = v<<1 | bit
+	}
+	return v
+}
+
+type requestIDKey struct{}
+
+// requestIDFromContext returns the request ID WithRequestID attached to
+// ctx, or "" if the request wasn't routed through it (e.g. a handler
+// invoked directly from a test).
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID wraps next with request-ID propagation and structured
+// per-request logging: it reads X-Request-ID from the incoming request (or
+// mints one with newRequestID), echoes it back on the response, and
+// attaches both the ID and a reqlog.Logger carrying it to the request's
+// context so handlers can pull either out with requestIDFromContext or
+// reqlog.FromContext.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		logger := reqlog.FromContext(r.Context()).With(reqlog.F("req_id", id))
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = reqlog.NewContext(ctx, logger)
+		ctx = withRequestMetrics(ctx)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// minRequestDeadline and maxRequestDeadline bound the X-Deadline-Ms header
+// WithDeadline honors. Below the minimum, a board-log walk wouldn't have
+// time to do anything useful; above the maximum, a caller-supplied budget
+// could hold a handler (and the goroutine serving it) open far longer than
+// boardRequestTimeout ever would on its own.
+const (
+	minRequestDeadline = 100 * time.Millisecond
+	maxRequestDeadline = 60 * time.Second
+)
+
+// requestDeadlineHeader lets a caller bound how long it's willing to wait
+// for a board-reading handler, tighter than the server's own
+// boardRequestTimeout when it knows its own budget is shorter.
+const requestDeadlineHeader = "X-Deadline-Ms"
+
+// WithDeadline wraps next with a context.WithTimeout derived from the
+// X-Deadline-Ms request header, clamped to [minRequestDeadline,
+// maxRequestDeadline]. A missing or unparsable header leaves the request's
+// context untouched — boardRequestContext's own boardRequestTimeout still
+// applies downstream. context.WithTimeout always honors the *earliest* of
+// two nested deadlines, so this composes with boardRequestContext without
+// either needing to know about the other.
+func WithDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get(requestDeadlineHeader)
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ms, err := strconv.Atoi(raw)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d := time.Duration(ms) * time.Millisecond
+		switch {
+		case d < minRequestDeadline:
+			d = minRequestDeadline
+		case d > maxRequestDeadline:
+			d = maxRequestDeadline
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestMetrics accumulates counters for the lifetime of one request, so
+// the structured log line a handler emits when it finishes can report how
+// much work it actually did. It's the request-scoped counterpart to
+// operationProgress (which only exists for async-launched requests):
+// reportEntryWalked/reportPostLoaded bump both when both are present.
+type requestMetrics struct {
+	EntriesWalked int64
+}
+
+type requestMetricsKey struct{}
+
+func withRequestMetrics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestMetricsKey{}, &requestMetrics{})
+}
+
+// entriesWalked returns the running reportEntryWalked count for ctx's
+// request, or 0 if ctx wasn't routed through WithRequestID.
+func entriesWalked(ctx context.Context) int64 {
+	m, ok := ctx.Value(requestMetricsKey{}).(*requestMetrics)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadInt64(&m.EntriesWalked)
+}
+
+// ensureRequestID returns ctx unchanged if it already carries a request ID
+// (the usual case: ctx descends from a handler's WithRequestID-wrapped
+// context), or a copy carrying a freshly minted one and a reqlog.Logger
+// scoped to it. Background work that doesn't originate from an incoming
+// HTTP request — runAntiEntropyRound's periodic ticks — still makes
+// outbound calls worth tracing end-to-end, so it calls this once per round
+// to get an ID of its own.
+func ensureRequestID(ctx context.Context) context.Context {
+	if requestIDFromContext(ctx) != "" {
+		return ctx
+	}
+	id := newRequestID()
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return reqlog.NewContext(ctx, reqlog.FromContext(ctx).With(reqlog.F("req_id", id)))
+}
+
+// setOutboundRequestID copies ctx's request ID (if any) onto req's
+// X-Request-ID header, so a call chain spanning multiple indexers — an
+// announce forward, a search proxy, an anti-entropy round — stays
+// correlated across hops the same way WithRequestID correlates this node's
+// own log lines.
+func setOutboundRequestID(req *http.Request, ctx context.Context) {
+	if id := requestIDFromContext(ctx); id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+}
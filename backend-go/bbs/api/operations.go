@@ -0,0 +1,392 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// operationTTL is how long a completed/failed/cancelled operation stays in
+// the operationsManager after finishing before sweep reclaims it — long
+// enough for a mobile client on a flaky link to reconnect and poll once,
+// short enough that a server left running for days doesn't accumulate an
+// unbounded map of stale results.
+const operationTTL = 10 * time.Minute
+
+type operationStatus string
+
+const (
+	operationRunning   operationStatus = "running"
+	operationCompleted operationStatus = "completed"
+	operationFailed    operationStatus = "failed"
+	operationCancelled operationStatus = "cancelled"
+)
+
+// operationProgress holds the counters a long-running replay updates as it
+// walks a board log and loads the posts behind it, so a client polling
+// GET /api/v1/operations/{id} sees it's making progress rather than just
+// "running" with nothing further. reportEntryWalked/reportPostLoaded
+// increment it through the request context; a context with no progress
+// attached (i.e. any request not launched via the operations manager) is
+// a silent no-op, same "nil disables" convention as the rest of Server.
+type operationProgress struct {
+	EntriesWalked int64
+	PostsLoaded   int64
+}
+
+type operationProgressKey struct{}
+
+func withOperationProgress(ctx context.Context, p *operationProgress) context.Context {
+	return context.WithValue(ctx, operationProgressKey{}, p)
+}
+
+func reportEntryWalked(ctx context.Context) {
+	if p, ok := ctx.Value(operationProgressKey{}).(*operationProgress); ok {
+		atomic.AddInt64(&p.EntriesWalked, 1)
+	}
+	if m, ok := ctx.Value(requestMetricsKey{}).(*requestMetrics); ok {
+		atomic.AddInt64(&m.EntriesWalked, 1)
+	}
+}
+
+func reportPostLoaded(ctx context.Context) {
+	if p, ok := ctx.Value(operationProgressKey{}).(*operationProgress); ok {
+		atomic.AddInt64(&p.PostsLoaded, 1)
+	}
+}
+
+// operation tracks one async run of an existing handler, started by
+// operationsManager.start and polled/cancelled through Server's
+// /api/v1/operations routes.
+type operation struct {
+	ID        string
+	Kind      string
+	CreatedAt time.Time
+
+	cancel   context.CancelFunc
+	progress operationProgress
+
+	mu          sync.Mutex
+	status      operationStatus
+	completedAt time.Time
+	statusCode  int
+	result      json.RawMessage
+	err         string
+}
+
+// operationView is the JSON shape GET/POST /api/v1/operations/{id} report.
+type operationView struct {
+	OperationID string          `json:"operationId"`
+	Kind        string          `json:"kind"`
+	Status      operationStatus `json:"status"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+	Progress    struct {
+		EntriesWalked int64 `json:"entriesWalked"`
+		PostsLoaded   int64 `json:"postsLoaded"`
+	} `json:"progress"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func (op *operation) view() operationView {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	v := operationView{
+		OperationID: op.ID,
+		Kind:        op.Kind,
+		Status:      op.status,
+		CreatedAt:   op.CreatedAt,
+		Result:      op.result,
+		Error:       op.err,
+	}
+	v.Progress.EntriesWalked = atomic.LoadInt64(&op.progress.EntriesWalked)
+	v.Progress.PostsLoaded = atomic.LoadInt64(&op.progress.PostsLoaded)
+	if op.status != operationRunning {
+		completedAt := op.completedAt
+		v.CompletedAt = &completedAt
+	}
+	return v
+}
+
+// operationsManager holds every in-flight/recently-finished operation this
+// server knows about, keyed by ID, guarded by a single RWMutex (unlike
+// bbs/indexer's eventHub, which uses a plain Mutex) — reads (polling)
+// far outnumber writes (start/finish/cancel), so RWMutex lets concurrent
+// pollers proceed without blocking each other.
+type operationsManager struct {
+	mu      sync.RWMutex
+	ops     map[string]*operation
+	nextID  int64
+	ttl     time.Duration
+	nowFunc func() time.Time
+}
+
+func newOperationsManager() *operationsManager {
+	return &operationsManager{ops: make(map[string]*operation), ttl: operationTTL, nowFunc: time.Now}
+}
+
+func (m *operationsManager) newID() string {
+	id := atomic.AddInt64(&m.nextID, 1)
+	return "op_" + strconv.FormatInt(id, 36)
+}
+
+// start launches run in its own goroutine under a cancellable context
+// derived from parent, registers an operation to track it, and returns
+// immediately. run is given that context (carrying the new operation's
+// *operationProgress, retrievable via reportEntryWalked/reportPostLoaded)
+// and an httptest.ResponseRecorder to write its JSON result/status into;
+// start copies those into the operation once run returns.
+func (m *operationsManager) start(parent context.Context, kind string, run func(ctx context.Context, rec *httptest.ResponseRecorder)) *operation {
+	op := &operation{
+		ID:        m.newID(),
+		Kind:      kind,
+		CreatedAt: m.nowFunc(),
+		status:    operationRunning,
+	}
+	ctx, cancel := context.WithCancel(parent)
+	ctx = withOperationProgress(ctx, &op.progress)
+	op.cancel = cancel
+
+	m.mu.Lock()
+	m.ops[op.ID] = op
+	m.mu.Unlock()
+
+	go func() {
+		rec := httptest.NewRecorder()
+		run(ctx, rec)
+
+		op.mu.Lock()
+		defer op.mu.Unlock()
+		op.completedAt = m.nowFunc()
+		switch {
+		case ctx.Err() != nil:
+			op.status = operationCancelled
+			op.err = ctx.Err().Error()
+		case rec.Code >= 200 && rec.Code < 300:
+			op.status = operationCompleted
+			op.statusCode = rec.Code
+			op.result = json.RawMessage(rec.Body.Bytes())
+		default:
+			op.status = operationFailed
+			op.statusCode = rec.Code
+			op.err = rec.Body.String()
+		}
+	}()
+
+	return op
+}
+
+func (m *operationsManager) get(id string) (*operation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	op, ok := m.ops[id]
+	return op, ok
+}
+
+// cancel cancels op's context if it's still running, letting its handler's
+// ctx.Err() checks (context deadline/cancellation propagates the same way
+// a client closing its connection would) unwind it promptly. It does not
+// remove op from the map — a cancelled operation is still pollable until
+// sweep reclaims it, same as a completed or failed one.
+func (m *operationsManager) cancel(id string) (*operation, bool) {
+	m.mu.RLock()
+	op, ok := m.ops[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	op.mu.Lock()
+	running := op.status == operationRunning
+	op.mu.Unlock()
+	if running {
+		op.cancel()
+	}
+	return op, true
+}
+
+// sweep removes operations that finished more than m.ttl ago. It's called
+// opportunistically from the operations handlers rather than on its own
+// timer, so an idle server with no operations traffic doesn't need a
+// background goroutine just to expire an empty map.
+func (m *operationsManager) sweep() {
+	now := m.nowFunc()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, op := range m.ops {
+		op.mu.Lock()
+		done := op.status != operationRunning && now.Sub(op.completedAt) > m.ttl
+		op.mu.Unlock()
+		if done {
+			delete(m.ops, id)
+		}
+	}
+}
+
+// createOperationRequest is the POST /api/v1/operations body: kind names
+// which existing handler to run asynchronously, and params carries that
+// handler's usual path parameters (e.g. {"boardId": "..."} for
+// "listThreads"); query parameters (cursor, limit, ...) aren't supported
+// this way — an async caller wants the full replay, not one page of it.
+type createOperationRequest struct {
+	Kind   string            `json:"kind"`
+	Params map[string]string `json:"params"`
+}
+
+// operationHandlerFor resolves kind to the same handler Handler() routes
+// synchronous requests to, so an async run shares 100% of that handler's
+// logic (replay, validation, error handling) instead of a parallel
+// reimplementation that could drift from it. syncBoard has no standalone
+// sync handler of its own (syncBoardFromTrustedIndexersBestEffort is a
+// side effect of listThreads/getThread, not a route), so it's adapted by
+// syncBoardOperation below.
+func (s *Server) operationHandlerFor(kind string) http.HandlerFunc {
+	switch kind {
+	case "listThreads":
+		return s.listThreads
+	case "getThread":
+		return s.getThread
+	case "announceBoard":
+		return s.announceBoard
+	case "syncBoard":
+		return s.syncBoardOperation
+	default:
+		return nil
+	}
+}
+
+// createOperation serves POST /api/v1/operations: it builds a synthetic
+// GET request carrying req.Params as path values (the same ones
+// {boardId}/{threadId} routes would supply) and launches req.Kind's
+// handler against it via operations.start, returning 202 immediately
+// with an Operation-Location header pointing at the polling URL.
+func (s *Server) createOperation(w http.ResponseWriter, r *http.Request) {
+	s.initNetworkDeps()
+	s.operations.sweep()
+
+	var req createOperationRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	handler := s.operationHandlerFor(req.Kind)
+	if handler == nil {
+		writeError(w, http.StatusBadRequest, "unknown operation kind: "+req.Kind)
+		return
+	}
+
+	op := s.operations.start(r.Context(), req.Kind, func(ctx context.Context, rec *httptest.ResponseRecorder) {
+		opReq, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		if err != nil {
+			rec.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for k, v := range req.Params {
+			opReq.SetPathValue(k, v)
+		}
+		handler(rec, opReq)
+	})
+
+	w.Header().Set("Operation-Location", operationLocation(op))
+	writeJSON(w, http.StatusAccepted, operationView{
+		OperationID: op.ID,
+		Kind:        op.Kind,
+		Status:      operationRunning,
+		CreatedAt:   op.CreatedAt,
+	})
+}
+
+// getOperation serves GET /api/v1/operations/{id}: the operation's current
+// status, progress counters, and (once non-running) its result or error.
+func (s *Server) getOperation(w http.ResponseWriter, r *http.Request) {
+	op, ok := s.operations.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "operation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, op.view())
+}
+
+// cancelOperation serves DELETE /api/v1/operations/{id}: it cancels op's
+// context if still running and reports its (possibly now-cancelled)
+// state, the same view getOperation would return.
+func (s *Server) cancelOperation(w http.ResponseWriter, r *http.Request) {
+	op, ok := s.operations.cancel(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "operation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, op.view())
+}
+
+// operationLocation is the polling URL reported in a response's
+// Operation-Location header for op.
+func operationLocation(op *operation) string {
+	return "/api/v1/operations/" + op.ID
+}
+
+// startAsyncIfRequested lets an existing synchronous handler opt into
+// ?async=1: when present, it launches handler (the caller itself, with
+// async stripped from the query string, so the async run replays exactly
+// the same request otherwise) via the operations manager, writes 202 with
+// an Operation-Location header, and returns true so the caller returns
+// immediately instead of falling through to its synchronous body. When
+// ?async=1 isn't set it returns false and does nothing, so existing
+// clients keep today's synchronous behavior unchanged.
+func (s *Server) startAsyncIfRequested(w http.ResponseWriter, r *http.Request, kind string, handler http.HandlerFunc) bool {
+	if r.URL.Query().Get("async") != "1" {
+		return false
+	}
+	s.initNetworkDeps()
+	s.operations.sweep()
+
+	syncURL := *r.URL
+	q := syncURL.Query()
+	q.Del("async")
+	syncURL.RawQuery = q.Encode()
+
+	op := s.operations.start(r.Context(), kind, func(ctx context.Context, rec *httptest.ResponseRecorder) {
+		opReq := r.Clone(ctx)
+		opReq.URL = &syncURL
+		handler(rec, opReq)
+	})
+
+	w.Header().Set("Operation-Location", operationLocation(op))
+	writeJSON(w, http.StatusAccepted, operationView{
+		OperationID: op.ID,
+		Kind:        op.Kind,
+		Status:      operationRunning,
+		CreatedAt:   op.CreatedAt,
+	})
+	return true
+}
+
+// syncBoardOperation adapts syncBoardFromTrustedIndexersBestEffort (which
+// has no HTTP route or return value of its own — it's a side effect
+// listThreads/getThread trigger, not a handler) to the http.HandlerFunc
+// shape operationHandlerFor needs, so "syncBoard" can be launched as its
+// own operation. It reports whether the board's log head CID changed.
+func (s *Server) syncBoardOperation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	boardID := r.PathValue("boardId")
+	_, before, hadBefore := s.loadBoardByID(ctx, boardID)
+	s.syncBoardFromTrustedIndexersBestEffort(ctx, boardID)
+	_, after, ok := s.loadBoardByID(ctx, boardID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "board not found")
+		return
+	}
+	beforeHead := ""
+	if hadBefore {
+		beforeHead = strOrEmpty(before.LogHeadCID)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"boardId": boardID,
+		"changed": beforeHead != strOrEmpty(after.LogHeadCID),
+	})
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// peerHealthWindow bounds how many recent outcomes peerHealthEntry.SuccessRate
+// averages over — recent enough to react to a peer going bad within a few
+// requests, without one stale failure from an hour ago still dragging the
+// score down.
+const peerHealthWindow = 20
+
+// peerHealthOpenThreshold and peerHealthOpenCooldown tune the circuit: a
+// peer opens after this many consecutive failures (same magnitude as the
+// breaker this subsystem replaces) and stays open this long before a
+// single half-open probe is allowed through.
+const (
+	peerHealthOpenThreshold = 3
+	peerHealthOpenCooldown  = 30 * time.Second
+)
+
+// peerHealthRTTAlpha weights peerHealthEntry.rttEMA's exponential moving
+// average: each new sample counts for this fraction of the updated
+// estimate, the rest carried over from the prior estimate.
+const peerHealthRTTAlpha = 0.3
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// peerHealthEntry tracks one trusted indexer's recent reliability: a
+// sliding window of success/failure outcomes, a three-state circuit
+// (closed/open/half-open), and an RTT EMA, all behind their own mutex so
+// peerHealthSet's map lock is only held long enough to find or create the
+// entry.
+type peerHealthEntry struct {
+	mu sync.Mutex
+
+	outcomes            []bool
+	consecutiveFailures int
+
+	state            circuitState
+	openUntil        time.Time
+	halfOpenInFlight bool
+
+	rttEMA  time.Duration
+	haveRTT bool
+}
+
+// allowed reports whether a call to this peer should be attempted: true
+// outright while closed, false while open (unless the cooldown has
+// elapsed, in which case it transitions to half-open and allows exactly
+// one probe through), and false for every half-open call after the first
+// until that probe's outcome is recorded.
+func (e *peerHealthEntry) allowed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch e.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		return false
+	default: // circuitOpen
+		if time.Now().Before(e.openUntil) {
+			return false
+		}
+		e.state = circuitHalfOpen
+		e.halfOpenInFlight = true
+		return true
+	}
+}
+
+func (e *peerHealthEntry) recordOutcome(success bool, rtt time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.outcomes = append(e.outcomes, success)
+	if len(e.outcomes) > peerHealthWindow {
+		e.outcomes = e.outcomes[len(e.outcomes)-peerHealthWindow:]
+	}
+
+	if success {
+		if e.haveRTT {
+			e.rttEMA = time.Duration(float64(rtt)*peerHealthRTTAlpha + float64(e.rttEMA)*(1-peerHealthRTTAlpha))
+		} else {
+			e.rttEMA = rtt
+			e.haveRTT = true
+		}
+		e.consecutiveFailures = 0
+		e.state = circuitClosed
+		e.openUntil = time.Time{}
+		e.halfOpenInFlight = false
+		return
+	}
+
+	e.consecutiveFailures++
+	wasHalfOpen := e.state == circuitHalfOpen
+	e.halfOpenInFlight = false
+	if wasHalfOpen || e.consecutiveFailures >= peerHealthOpenThreshold {
+		e.state = circuitOpen
+		e.openUntil = time.Now().Add(peerHealthOpenCooldown)
+	}
+}
+
+// score combines recent success rate with an inverse-RTT bonus so
+// SortedPeers tries fast, reliable peers first. A peer with no outcomes
+// yet defaults to a neutral 1.0 success rate (same "assume healthy until
+// proven otherwise" stance Allowed takes for an unknown baseURL) and no
+// RTT bonus, so untried peers sort ahead of ones with a confirmed failure
+// but behind ones with a confirmed fast success.
+func (e *peerHealthEntry) score() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	successRate := 1.0
+	if len(e.outcomes) > 0 {
+		successes := 0
+		for _, ok := range e.outcomes {
+			if ok {
+				successes++
+			}
+		}
+		successRate = float64(successes) / float64(len(e.outcomes))
+	}
+
+	rttBonus := 0.0
+	if e.haveRTT {
+		rttBonus = 1.0 / (1.0 + e.rttEMA.Seconds())
+	}
+	return successRate*2 + rttBonus
+}
+
+func (e *peerHealthEntry) snapshot() PeerHealthStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	successRate := 1.0
+	if len(e.outcomes) > 0 {
+		successes := 0
+		for _, ok := range e.outcomes {
+			if ok {
+				successes++
+			}
+		}
+		successRate = float64(successes) / float64(len(e.outcomes))
+	}
+
+	out := PeerHealthStatus{
+		State:               e.state.String(),
+		SuccessRate:         successRate,
+		ConsecutiveFailures: e.consecutiveFailures,
+	}
+	if e.haveRTT {
+		out.RTTMillis = float64(e.rttEMA) / float64(time.Millisecond)
+	}
+	return out
+}
+
+// peerHealthSet is a mutex-protected, per-baseURL peerHealthEntry registry
+// shared across requests (lazily initialized once in Server.initNetworkDeps),
+// consulted by forwardBoardAnnounceBestEffort, syncBoardFromTrustedIndexersBestEffort,
+// and proxySearch before and after every call to a trusted indexer.
+type peerHealthSet struct {
+	mu sync.Mutex
+	m  map[string]*peerHealthEntry
+}
+
+func newPeerHealthSet() *peerHealthSet {
+	return &peerHealthSet{m: make(map[string]*peerHealthEntry)}
+}
+
+func (s *peerHealthSet) entry(baseURL string) *peerHealthEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.m[baseURL]
+	if !ok {
+		e = &peerHealthEntry{}
+		s.m[baseURL] = e
+	}
+	return e
+}
+
+func (s *peerHealthSet) Allowed(baseURL string) bool {
+	return s.entry(baseURL).allowed()
+}
+
+func (s *peerHealthSet) RecordSuccess(baseURL string, rtt time.Duration) {
+	s.entry(baseURL).recordOutcome(true, rtt)
+}
+
+func (s *peerHealthSet) RecordFailure(baseURL string) {
+	s.entry(baseURL).recordOutcome(false, 0)
+}
+
+// SortedPeers returns a copy of peers ordered by descending score (recent
+// success rate plus an inverse-RTT bonus), so callers iterating in order
+// (forwardBoardAnnounceBestEffort, proxySearch) try the peers most likely
+// to answer quickly first instead of always starting from list order.
+func (s *peerHealthSet) SortedPeers(peers []string) []string {
+	out := make([]string, len(peers))
+	copy(out, peers)
+	scores := make(map[string]float64, len(out))
+	for _, p := range out {
+		scores[p] = s.entry(p).score()
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return scores[out[i]] > scores[out[j]]
+	})
+	return out
+}
+
+// Snapshot returns every peer this set currently tracks and its health
+// status, for handlePeersHealth to report.
+func (s *peerHealthSet) Snapshot() map[string]PeerHealthStatus {
+	s.mu.Lock()
+	entries := make(map[string]*peerHealthEntry, len(s.m))
+	for k, v := range s.m {
+		entries[k] = v
+	}
+	s.mu.Unlock()
+
+	out := make(map[string]PeerHealthStatus, len(entries))
+	for k, e := range entries {
+		out[k] = e.snapshot()
+	}
+	return out
+}
+
+// handlePeersHealth serves GET /api/v1/peers/health: every trusted
+// indexer's circuit state, recent success rate, and RTT EMA, for
+// operators to see which peers are slow or tripped without reading logs.
+// A peer this node hasn't called yet (no tracked entry) is reported with
+// the same defaults Allowed/score assume: closed, 1.0 success rate, no
+// RTT.
+func (s *Server) handlePeersHealth(w http.ResponseWriter, r *http.Request) {
+	_ = r
+	out := make([]PeerHealthEntry, 0)
+	if s.TrustedIndexers != nil {
+		if err := s.TrustedIndexers.Load(); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		snapshot := s.peerHealth.Snapshot()
+		for _, baseURL := range s.TrustedIndexers.List() {
+			status, ok := snapshot[baseURL]
+			if !ok {
+				status = PeerHealthStatus{State: circuitClosed.String(), SuccessRate: 1.0}
+			}
+			out = append(out, PeerHealthEntry{BaseURL: baseURL, PeerHealthStatus: status})
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// providerRecord is one NDJSON line GET /routing/v1/providers/{boardId}
+// emits: a capability this node itself claims for boardId. It mirrors
+// bbs/routing's own unexported wire shape (providerWire) by field name,
+// not by shared type, since bbs/api has no reason to import bbs/routing
+// (only cmd/bbs-node wires the two together).
+type providerRecord struct {
+	BoardID string `json:"boardId"`
+	Role    string `json:"role"`
+}
+
+// routingProviders answers GET /routing/v1/providers/{boardId}. The path
+// deliberately echoes boxo's Delegated Routing V1
+// GET /routing/v1/providers/{cid} (cmd/bbs-node's delegated_routing_client.go
+// already speaks that protocol as a client, against external content
+// routers) but the resource here is a flex-bbs board ID, not a CID, and
+// the record schema is this package's own (boardId/role), not IPIP-337's
+// Peer schema — there's no raw content/DHT routing involved, just "which
+// bbs-node roles serve this board". One NDJSON line per capability this
+// node claims for boardId, rather than a JSON array, so a client can
+// start consuming records before the whole response is read (not that
+// there's ever more than two records here — one per role this node
+// might hold for a board).
+//
+// Unlike a full delegated-routing server, this node only ever describes
+// itself: it has no index of what other peers provide, so a caller
+// wanting a network-wide view queries several peers directly (see
+// bbs/routing.HTTPDelegatedRouter, which does exactly that).
+func (s *Server) routingProviders(w http.ResponseWriter, r *http.Request) {
+	boardID := r.PathValue("boardId")
+	if boardID == "" {
+		writeError(w, http.StatusBadRequest, "boardId is required")
+		return
+	}
+
+	var records []providerRecord
+	if s.indexesBoard(r.Context(), boardID) {
+		records = append(records, providerRecord{BoardID: boardID, Role: "indexer"})
+	}
+	if s.archivesBoard(boardID) {
+		records = append(records, providerRecord{BoardID: boardID, Role: "archiver"})
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return
+		}
+	}
+}
+
+// indexesBoard reports whether this node's indexer (if any) has a synced
+// head for boardID, i.e. whether it actually serves the board today
+// rather than merely having once heard of it.
+func (s *Server) indexesBoard(ctx context.Context, boardID string) bool {
+	if s.Indexer == nil {
+		return false
+	}
+	heads, err := s.Indexer.ListBoardHeads(ctx)
+	if err != nil {
+		return false
+	}
+	for _, h := range heads {
+		if h.BoardID == boardID {
+			return true
+		}
+	}
+	return false
+}
+
+// archivesBoard reports whether this node's role includes archiving and
+// boardID is one it's configured to track. Server has no handle on the
+// archive.Archiver goroutine itself (cmd/bbs-node only gives it
+// Storage/Boards/Indexer/TrustedIndexers/Attachments), but archive.Archiver
+// walks the same BoardsStore this field already holds, so a board known
+// to Boards is exactly the set it archives when this role can archive at
+// all.
+func (s *Server) archivesBoard(boardID string) bool {
+	if s.Role != "archiver" && s.Role != "full" {
+		return false
+	}
+	_, ok := s.Boards.Get(boardID)
+	return ok
+}
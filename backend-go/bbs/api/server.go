@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"sort"
@@ -15,9 +15,13 @@ import (
 	"sync"
 	"time"
 
+	"flex-bbs/backend-go/bbs/attachments"
 	"flex-bbs/backend-go/bbs/config"
 	bbsindexer "flex-bbs/backend-go/bbs/indexer"
 	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/middleware"
+	"flex-bbs/backend-go/bbs/republisher"
+	"flex-bbs/backend-go/bbs/reqlog"
 	"flex-bbs/backend-go/bbs/signature"
 	"flex-bbs/backend-go/bbs/storage"
 	"flex-bbs/backend-go/bbs/types"
@@ -29,9 +33,106 @@ type Server struct {
 	Boards          *config.BoardsStore
 	TrustedIndexers *config.TrustedIndexersStore
 	Indexer         *bbsindexer.Indexer
+	// Attachments is optional: a nil Store means this server doesn't serve
+	// or accept blob attachments, and addPost/createThread skip attachment
+	// validation entirely (so existing deployments without it configured
+	// keep working).
+	Attachments *attachments.Store
+
+	// Republisher is optional: a nil Republisher means this node isn't
+	// running one (cmd/bbs-node only starts one for archiver/full roles),
+	// and republisherStatus reports 501 rather than an empty/misleading
+	// status.
+	Republisher *republisher.Republisher
+
+	// Compress configures the gzip/deflate response compression Handler
+	// applies to every /api/v1/* route. The zero value enables it with
+	// middleware.CompressConfig's defaults; set CompressDisabled to turn
+	// it off entirely (e.g. when a reverse proxy in front of this server
+	// already handles content-encoding).
+	Compress         middleware.CompressConfig
+	CompressDisabled bool
+
+	// MaxConcurrentFetches bounds how many trusted-indexer HTTP calls or
+	// independent storage loads (e.g. a board's distinct ThreadMeta CIDs)
+	// a single request fans out at once. Zero uses defaultMaxConcurrentFetches.
+	MaxConcurrentFetches int
+
+	// IndexerQuorum is how many trusted indexers must independently report
+	// the same BoardMetaCID before syncBoardFromTrustedIndexersBestEffort
+	// accepts it. Zero (the default) accepts on the first indexer that
+	// reports a verifiable, differing BoardMeta.
+	IndexerQuorum int
+
+	// AntiEntropyInterval is how often runAntiEntropyLoop starts a new
+	// push-pull digest round with every trusted indexer. Zero uses
+	// defaultAntiEntropyInterval. Negative disables the loop entirely.
+	AntiEntropyInterval time.Duration
+	// AntiEntropyConcurrency bounds how many trusted indexers a single
+	// anti-entropy round talks to at once. Zero uses
+	// defaultMaxConcurrentFetches.
+	AntiEntropyConcurrency int
+
+	// AnnounceAuth is optional: a nil store means inbound announce/sync
+	// requests are accepted from anyone who can reach the HTTP port (the
+	// behavior every deployment had before this feature existed). Set it
+	// to require every caller of POST /api/v1/announce/board and POST
+	// /api/v1/sync/digest to sign with a keyId listed in the store.
+	AnnounceAuth *config.AnnounceAuthStore
+	// AnnounceKeyID and AnnouncePrivKey are this server's own signing
+	// identity: the keyId/private key forwardBoardAnnounceBestEffort and
+	// the anti-entropy round sign outbound requests with, and that GET
+	// /api/v1/peers/self reports (the pubkey only) for peers to trust.
+	// Both are required to sign outbound requests; leaving either empty
+	// just means outbound calls go out unsigned, same as before this
+	// feature existed.
+	AnnounceKeyID   string
+	AnnouncePrivKey string
 
 	httpClient        *http.Client
 	seenBoardMetaCIDs *seenSet
+	announceAuthSeen  *seenSet
+	storageFetcher    *storage.Fetcher
+	events            *eventBus
+	operations        *operationsManager
+	fetchPool         *fetchPool
+	boardAnnounces    *boardAnnounceBus
+	antiEntropyPeers  *antiEntropyPeerSet
+	antiEntropyOnce   sync.Once
+	logIndex          *storage.LogIndex
+	peerCapabilities  *peerCapabilitySet
+	capabilitiesOnce  sync.Once
+	peerHealth        *peerHealthSet
+}
+
+// defaultLogIndexCapacity bounds logIndex's entry count: large enough to
+// hold a full board's worth of log entries at the existing 50_000
+// FetchChain/isBoardLogDescendant walk cap, shared across every board a
+// process touches (entries are keyed by their own CID, so the cache isn't
+// partitioned per board).
+const defaultLogIndexCapacity = 50_000
+
+func (s *Server) fetchPoolSize() int {
+	if s.MaxConcurrentFetches > 0 {
+		return s.MaxConcurrentFetches
+	}
+	return defaultMaxConcurrentFetches
+}
+
+func (s *Server) indexerQuorum() int {
+	if s.IndexerQuorum > 0 {
+		return s.IndexerQuorum
+	}
+	return 1
+}
+
+// fetcher returns the Server's shared storage.Fetcher (initialized by
+// initNetworkDeps). It's shared across requests, not one per call, so
+// concurrent requests walking overlapping parts of the same board log —
+// the common case right after a burst of posts — single-flight onto one
+// Flex.GetValue per CID instead of each paying their own round-trip.
+func (s *Server) fetcher() *storage.Fetcher {
+	return s.storageFetcher
 }
 
 func (s *Server) Handler() http.Handler {
@@ -51,10 +152,62 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/v1/search/boards", s.searchBoards)
 	mux.HandleFunc("GET /api/v1/search/threads", s.searchThreads)
 	mux.HandleFunc("GET /api/v1/search/posts", s.searchPosts)
-	mux.HandleFunc("POST /api/v1/announce/board", s.announceBoard)
+	mux.HandleFunc("GET /api/v1/events", s.streamEvents)
+	mux.HandleFunc("GET /api/v1/boards/{boardId}/events", s.handleBoardEventsSSE)
+	mux.HandleFunc("GET /api/v1/threads/{threadId}/events", s.handleThreadEventsSSE)
+	mux.HandleFunc("GET /api/v1/watch/boards", s.watchBoards)
+	mux.HandleFunc("GET /api/v1/watch/threads", s.watchThreads)
+	mux.HandleFunc("POST /api/v1/operations", s.createOperation)
+	mux.HandleFunc("GET /api/v1/operations/{id}", s.getOperation)
+	mux.HandleFunc("DELETE /api/v1/operations/{id}", s.cancelOperation)
+	mux.HandleFunc("POST /api/v1/attachments", s.uploadAttachment)
+	mux.HandleFunc("GET /blob/{cid}", s.getBlob)
+	mux.HandleFunc("GET /api/v1/blobs/{cid}", s.getBlob)
+	mux.HandleFunc("POST /api/v1/announce/board", s.requireAnnounceAuth(s.announceBoard))
+	mux.HandleFunc("POST /api/v1/sync/digest", s.requireAnnounceAuth(s.handleSyncDigest))
+	mux.HandleFunc("GET /api/v1/peers/self", s.peersSelf)
+	mux.HandleFunc("GET /api/v1/capabilities", s.handleCapabilities)
+	mux.HandleFunc("GET /api/v1/peers", s.handlePeers)
+	mux.HandleFunc("GET /api/v1/peers/health", s.handlePeersHealth)
 	mux.HandleFunc("GET /api/v1/trusted-indexers", s.listTrustedIndexers)
+	mux.HandleFunc("GET /api/v1/indexer/peering/heads", s.indexerPeeringHeads)
+	mux.HandleFunc("GET /api/v1/republisher/status", s.republisherStatus)
+	mux.HandleFunc("GET /routing/v1/providers/{boardId}", s.routingProviders)
+
+	var h http.Handler = mux
+	if !s.CompressDisabled {
+		h = middleware.Compress(s.Compress, h)
+	}
+	return WithRequestID(WithDeadline(h))
+}
+
+// boardRequestTimeout bounds how long a board-reading handler (listBoards,
+// getBoard, listThreads, getThread) may spend walking the board log and
+// fetching backing storage before giving up, so a slow or wedged flexipfs
+// peer can't hold the request (and its goroutine) open indefinitely.
+const boardRequestTimeout = 10 * time.Second
+
+func (s *Server) boardRequestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), boardRequestTimeout)
+}
 
-	return mux
+// writeUpstreamError reports err as 404 Not Found if it's a storage.ErrNotFound
+// (the CID a board log/thread referenced simply doesn't exist upstream), 504
+// Gateway Timeout if it's a context deadline/cancellation (the request's
+// storage/network calls didn't finish in time), or 502 Bad Gateway for any
+// other upstream failure (including storage.ErrCorruptPayload, which is the
+// upstream's fault, not this server's). This replaces guessing a status from
+// err's formatted message with a deterministic errors.Is/As classification.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		writeError(w, http.StatusGatewayTimeout, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadGateway, err.Error())
 }
 
 func (s *Server) initNetworkDeps() {
@@ -64,6 +217,46 @@ func (s *Server) initNetworkDeps() {
 	if s.seenBoardMetaCIDs == nil {
 		s.seenBoardMetaCIDs = newSeenSet(4096, 30*time.Minute)
 	}
+	if s.announceAuthSeen == nil {
+		s.announceAuthSeen = newSeenSet(announceAuthSeenCapacity, announceAuthSeenTTL)
+	}
+	if s.storageFetcher == nil {
+		s.storageFetcher = storage.NewFetcher(0)
+	}
+	if s.events == nil {
+		s.events = newEventBus()
+	}
+	if s.operations == nil {
+		s.operations = newOperationsManager()
+	}
+	if s.fetchPool == nil {
+		s.fetchPool = newFetchPool(s.fetchPoolSize())
+	}
+	if s.peerHealth == nil {
+		s.peerHealth = newPeerHealthSet()
+	}
+	if s.boardAnnounces == nil {
+		s.boardAnnounces = newBoardAnnounceBus()
+	}
+	if s.antiEntropyPeers == nil {
+		s.antiEntropyPeers = newAntiEntropyPeerSet()
+	}
+	if s.logIndex == nil {
+		s.logIndex = storage.NewLogIndex(defaultLogIndexCapacity)
+	}
+	if s.peerCapabilities == nil {
+		s.peerCapabilities = newPeerCapabilitySet()
+	}
+	s.antiEntropyOnce.Do(func() {
+		if s.TrustedIndexers != nil && s.AntiEntropyInterval >= 0 {
+			go s.runAntiEntropyLoop(context.Background())
+		}
+	})
+	s.capabilitiesOnce.Do(func() {
+		if s.TrustedIndexers != nil {
+			go s.probeAllTrustedIndexersOnce(context.Background())
+		}
+	})
 }
 
 func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
@@ -71,7 +264,10 @@ func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) listBoards(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	start := time.Now()
+	ctx, cancel := s.boardRequestContext(r)
+	defer cancel()
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "listBoards"))
 	if err := s.Boards.Load(); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -82,20 +278,22 @@ func (s *Server) listBoards(w http.ResponseWriter, r *http.Request) {
 	for _, ref := range refs {
 		bm, err := s.Storage.LoadBoardMeta(ctx, ref.BoardMetaCID)
 		if err != nil {
-			log.Printf("api listBoards: load boardMeta cid=%s: %v", ref.BoardMetaCID, err)
+			logger.Info("load boardMeta failed", reqlog.F("cid", ref.BoardMetaCID), reqlog.F("error", err.Error()))
 			continue
 		}
 		if !bbslog.VerifyBoardMeta(bm) {
-			log.Printf("api listBoards: invalid boardMeta signature cid=%s boardId=%s", ref.BoardMetaCID, bm.BoardID)
+			logger.Info("invalid boardMeta signature", reqlog.F("cid", ref.BoardMetaCID), reqlog.F("board_id", bm.BoardID))
 			continue
 		}
 		out = append(out, BoardItem{BoardMetaCID: ref.BoardMetaCID, Board: *bm})
 	}
+	logger.Info("listBoards", reqlog.F("elapsed_ms", time.Since(start).Milliseconds()))
 	writeJSON(w, http.StatusOK, out)
 }
 
 func (s *Server) getBoard(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, cancel := s.boardRequestContext(r)
+	defer cancel()
 	boardID := r.PathValue("boardId")
 	refCID, bm, ok := s.loadBoardByID(ctx, boardID)
 	if !ok {
@@ -106,8 +304,17 @@ func (s *Server) getBoard(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if s.startAsyncIfRequested(w, r, "listThreads", s.listThreads) {
+		return
+	}
+	start := time.Now()
+	ctx, cancel := s.boardRequestContext(r)
+	defer cancel()
 	boardID := r.PathValue("boardId")
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "listThreads"), reqlog.F("board_id", boardID))
+	defer func() {
+		logger.Info("listThreads", reqlog.F("elapsed_ms", time.Since(start).Milliseconds()), reqlog.F("entries_walked", entriesWalked(ctx)))
+	}()
 	s.syncBoardFromTrustedIndexersBestEffort(ctx, boardID)
 	_, bm, ok := s.loadBoardByID(ctx, boardID)
 	if !ok {
@@ -115,21 +322,18 @@ func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit, offset := parseLimitOffset(r, 50, 0, 200)
-
-	loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
-		return s.Storage.LoadBoardLogEntry(ctx, cid)
-	}
+	loadLog := storage.Load(s.fetcher(), s.Storage.LoadBoardLogEntry)
 	boardLog, err := bbslog.FetchChain(ctx, bm.LogHeadCID, loadLog, func(e *types.BoardLogEntry) *string {
 		return e.PrevLogCID
 	}, bbslog.VerifyBoardLogEntry, 50_000)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, err.Error())
+		writeUpstreamError(w, err)
 		return
 	}
 
 	type threadState struct {
 		ThreadCID        string
+		LogCID           string
 		CreatedAt        string
 		RootPostCID      string
 		RootAuthorPubKey string
@@ -137,6 +341,7 @@ func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
 	}
 	byThread := make(map[string]*threadState)
 	for _, item := range boardLog {
+		reportEntryWalked(ctx)
 		if !item.ValidSignature {
 			continue
 		}
@@ -155,6 +360,7 @@ func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
 			}
 			byThread[e.ThreadID] = &threadState{
 				ThreadCID:        e.ThreadID,
+				LogCID:           item.CID,
 				CreatedAt:        e.CreatedAt,
 				RootPostCID:      *e.PostCID,
 				RootAuthorPubKey: e.AuthorPubKey,
@@ -196,33 +402,97 @@ func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	threads := make([]ThreadItem, 0, len(byThread))
+	// Every surviving thread's CID is already known at this point, so unlike
+	// the board-log walk above (an intrinsically sequential linked list),
+	// these ThreadMeta loads are independent and safe to fan out across the
+	// fetch pool instead of awaiting each one in turn.
+	live := make([]*threadState, 0, len(byThread))
 	for _, x := range byThread {
-		if x == nil || x.RootTombstoned {
-			continue
+		if x != nil && !x.RootTombstoned {
+			live = append(live, x)
 		}
+	}
+	loaded := make([]*ThreadItem, len(live))
+	s.fetchPool.forEach(len(live), func(i int) {
+		x := live[i]
+		reportPostLoaded(ctx)
 		tm, err := s.Storage.LoadThreadMeta(ctx, x.ThreadCID)
 		if err != nil {
-			log.Printf("api listThreads: load threadMeta cid=%s: %v", x.ThreadCID, err)
-			continue
+			logger.Info("load threadMeta failed", reqlog.F("cid", x.ThreadCID), reqlog.F("error", err.Error()))
+			return
 		}
 		if !bbslog.VerifyThreadMeta(tm) {
-			log.Printf("api listThreads: invalid threadMeta signature cid=%s threadId=%s", x.ThreadCID, tm.ThreadID)
-			continue
+			logger.Info("invalid threadMeta signature", reqlog.F("cid", x.ThreadCID), reqlog.F("thread_id", tm.ThreadID))
+			return
 		}
 		tm.ThreadID = x.ThreadCID
 		tm.RootPostCID = x.RootPostCID
-		threads = append(threads, ThreadItem{ThreadID: x.ThreadCID, ThreadMetaCID: x.ThreadCID, Thread: *tm})
+		loaded[i] = &ThreadItem{ThreadID: x.ThreadCID, ThreadMetaCID: x.ThreadCID, Thread: *tm}
+	})
+	threads := make([]ThreadItem, 0, len(loaded))
+	for _, it := range loaded {
+		if it != nil {
+			threads = append(threads, *it)
+		}
 	}
 
 	sortThreadsNewestFirst(threads)
-	threads = applyOffsetLimit(threads, offset, limit)
-	writeJSON(w, http.StatusOK, threads)
+
+	// Offset paging is kept for one release behind ?mode=offset; it's unsafe once
+	// listThreadsForBoard is backed by the append-only board log, since threads can
+	// shift between pages as new posts arrive. Cursor mode (the default) is stable
+	// against concurrent writes because it keys off the last row seen, not its index.
+	if r.URL.Query().Get("mode") == "offset" {
+		limit, offset := parseLimitOffset(r, 50, 0, 200)
+		writeJSON(w, http.StatusOK, applyOffsetLimit(threads, offset, limit))
+		return
+	}
+
+	limit, _ := parseLimitOffset(r, 50, 0, 200)
+	maxID, minID, err := parsePageCursors(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	threadCreatedAt := func(t ThreadItem) time.Time {
+		at, _ := time.Parse(time.RFC3339, t.Thread.CreatedAt)
+		return at
+	}
+	threadID := func(t ThreadItem) string { return t.ThreadID }
+	page, filled, hasNewer := cursorWindow(threads, threadCreatedAt, threadID, maxID, minID, limit)
+
+	var nextCursor, prevCursor string
+	if filled {
+		last := page[len(page)-1]
+		nextCursor = encodeCursor(threadCreatedAt(last), last.ThreadID)
+	}
+	if len(page) > 0 && (minID != nil || hasNewer) {
+		first := page[0]
+		prevCursor = encodeCursor(threadCreatedAt(first), first.ThreadID)
+	}
+	writeLinkHeader(w, r, nextCursor, prevCursor)
+
+	writeJSON(w, http.StatusOK, ThreadsPage{
+		Threads:    page,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Limit:      limit,
+	})
 }
 
 func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if s.startAsyncIfRequested(w, r, "getThread", s.getThread) {
+		return
+	}
+	start := time.Now()
+	ctx, cancel := s.boardRequestContext(r)
+	defer cancel()
 	threadCID := r.PathValue("threadId")
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "getThread"), reqlog.F("thread_id", threadCID))
+	defer func() {
+		logger.Info("getThread", reqlog.F("elapsed_ms", time.Since(start).Milliseconds()), reqlog.F("entries_walked", entriesWalked(ctx)))
+	}()
 
 	tm, err := s.Storage.LoadThreadMeta(ctx, threadCID)
 	if err != nil {
@@ -234,6 +504,7 @@ func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	boardID := tm.BoardID
+	logger = logger.With(reqlog.F("board_id", boardID))
 	s.syncBoardFromTrustedIndexersBestEffort(ctx, boardID)
 
 	var (
@@ -246,16 +517,15 @@ func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
 	var primaryPosts []bbslog.ReplayedPost
 	var primaryRoot string
 	if _, bm, ok := s.loadBoardByID(ctx, boardID); ok {
-		loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
-			return s.Storage.LoadBoardLogEntry(ctx, cid)
-		}
+		loadLog := storage.Load(s.fetcher(), s.Storage.LoadBoardLogEntry)
 		boardLog, err := bbslog.FetchChain(ctx, bm.LogHeadCID, loadLog, func(e *types.BoardLogEntry) *string {
 			return e.PrevLogCID
 		}, bbslog.VerifyBoardLogEntry, 50_000)
 		if err != nil {
-			log.Printf("api getThread: board log fetch failed boardId=%s: %v", boardID, err)
+			logger.Info("board log fetch failed", reqlog.F("error", err.Error()))
 		} else {
 			for _, item := range boardLog {
+				reportEntryWalked(ctx)
 				if !item.ValidSignature {
 					continue
 				}
@@ -267,11 +537,12 @@ func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
 			}
 
 			loadPost := func(ctx context.Context, cid string) (*types.Post, error) {
-				return s.Storage.LoadPost(ctx, cid)
+				reportPostLoaded(ctx)
+				return storage.Load(s.fetcher(), s.Storage.LoadPost)(ctx, cid)
 			}
-			replayed, err := bbslog.ReplayThread(ctx, boardLog, threadCID, loadPost, bbslog.VerifyPost, nil)
+			replayed, err := bbslog.ReplayThreadPrefetched(ctx, boardLog, threadCID, loadPost, bbslog.VerifyPost, nil, s.fetchPoolSize(), nil)
 			if err != nil {
-				log.Printf("api getThread: board log replay failed boardId=%s threadId=%s: %v", boardID, threadCID, err)
+				logger.Info("board log replay failed", reqlog.F("error", err.Error()))
 			} else {
 				primaryPosts = replayed
 			}
@@ -280,7 +551,7 @@ func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
 
 	fallbackPosts, fallbackRoot, fallbackErr := s.replayThreadFromTags(ctx, boardID, threadCID)
 	if fallbackErr != nil {
-		log.Printf("api getThread: tag replay failed boardId=%s threadId=%s: %v", boardID, threadCID, fallbackErr)
+		logger.Info("tag replay failed", reqlog.F("error", fallbackErr.Error()))
 	}
 
 	// Prefer the result that contains more posts (helps cross-device sync when boards.json is stale).
@@ -316,11 +587,58 @@ func (s *Server) getThread(w http.ResponseWriter, r *http.Request) {
 			TombstoneReason: p.TombstoneReason,
 		})
 	}
+	sort.Slice(outPosts, func(i, j int) bool {
+		a, b := outPosts[i].Post.CreatedAt, outPosts[j].Post.CreatedAt
+		if a == b {
+			return outPosts[i].CID > outPosts[j].CID
+		}
+		return a > b
+	})
+
+	// Offset paging is kept for one release behind ?mode=offset; see listThreads.
+	if r.URL.Query().Get("mode") == "offset" {
+		limit, offset := parseLimitOffset(r, 50, 0, 200)
+		writeJSON(w, http.StatusOK, ThreadResponse{
+			ThreadMetaCID: threadCID,
+			ThreadMeta:    threadMeta,
+			Posts:         applyOffsetLimit(outPosts, offset, limit),
+			Limit:         limit,
+		})
+		return
+	}
+
+	limit, _ := parseLimitOffset(r, 50, 0, 200)
+	maxID, minID, err := parsePageCursors(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	postCreatedAt := func(p ThreadPostItem) time.Time {
+		at, _ := time.Parse(time.RFC3339, p.Post.CreatedAt)
+		return at
+	}
+	postCID := func(p ThreadPostItem) string { return p.CID }
+	page, filled, hasNewer := cursorWindow(outPosts, postCreatedAt, postCID, maxID, minID, limit)
+
+	var nextCursor, prevCursor string
+	if filled {
+		last := page[len(page)-1]
+		nextCursor = encodeCursor(postCreatedAt(last), last.CID)
+	}
+	if len(page) > 0 && (minID != nil || hasNewer) {
+		first := page[0]
+		prevCursor = encodeCursor(postCreatedAt(first), first.CID)
+	}
+	writeLinkHeader(w, r, nextCursor, prevCursor)
 
 	writeJSON(w, http.StatusOK, ThreadResponse{
 		ThreadMetaCID: threadCID,
 		ThreadMeta:    threadMeta,
-		Posts:         outPosts,
+		Posts:         page,
+		NextCursor:    nextCursor,
+		PrevCursor:    prevCursor,
+		Limit:         limit,
 	})
 }
 
@@ -338,24 +656,37 @@ func (s *Server) replayThreadFromTags(ctx context.Context, boardID, threadID str
 		return nil, "", nil
 	}
 
-	var entries []bbslog.EntryWithCID[types.BoardLogEntry]
+	loadLog := storage.Load(s.fetcher(), s.Storage.LoadBoardLogEntry)
+	var (
+		mu      sync.Mutex
+		entries []bbslog.EntryWithCID[types.BoardLogEntry]
+		wg      sync.WaitGroup
+	)
 	for _, cid := range cids {
-		e, err := s.Storage.LoadBoardLogEntry(ctx, cid)
-		if err != nil {
-			continue
-		}
-		if e.Type != types.TypeBoardLogEntry {
-			continue
-		}
-		if e.BoardID != boardID || e.ThreadID != threadID {
-			continue
-		}
-		entries = append(entries, bbslog.EntryWithCID[types.BoardLogEntry]{
-			CID:            cid,
-			Value:          e,
-			ValidSignature: bbslog.VerifyBoardLogEntry(e),
-		})
+		cid := cid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e, err := loadLog(ctx, cid)
+			if err != nil {
+				return
+			}
+			if e.Type != types.TypeBoardLogEntry {
+				return
+			}
+			if e.BoardID != boardID || e.ThreadID != threadID {
+				return
+			}
+			mu.Lock()
+			entries = append(entries, bbslog.EntryWithCID[types.BoardLogEntry]{
+				CID:            cid,
+				Value:          e,
+				ValidSignature: bbslog.VerifyBoardLogEntry(e),
+			})
+			mu.Unlock()
+		}()
 	}
+	wg.Wait()
 
 	sort.Slice(entries, func(i, j int) bool {
 		a := entries[i].Value
@@ -394,6 +725,7 @@ func (s *Server) replayThreadFromTags(ctx context.Context, boardID, threadID str
 
 	var rootPostCID string
 	for _, item := range entries {
+		reportEntryWalked(ctx)
 		if !item.ValidSignature {
 			continue
 		}
@@ -405,9 +737,10 @@ func (s *Server) replayThreadFromTags(ctx context.Context, boardID, threadID str
 	}
 
 	loadPost := func(ctx context.Context, cid string) (*types.Post, error) {
-		return s.Storage.LoadPost(ctx, cid)
+		reportPostLoaded(ctx)
+		return storage.Load(s.fetcher(), s.Storage.LoadPost)(ctx, cid)
 	}
-	posts, err := bbslog.ReplayThread(ctx, entries, threadID, loadPost, bbslog.VerifyPost, nil)
+	posts, err := bbslog.ReplayThreadPrefetched(ctx, entries, threadID, loadPost, bbslog.VerifyPost, nil, 0, nil)
 	if err != nil {
 		return nil, "", err
 	}
@@ -429,6 +762,10 @@ func (s *Server) createThread(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "body.content is required")
 		return
 	}
+	if err := s.validateAttachments(ctx, req.Attachments); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	_, bm, ok := s.loadBoardByID(ctx, req.BoardID)
 	if !ok {
@@ -515,6 +852,7 @@ func (s *Server) createThread(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
+	s.publishBoardEvent(boardEventFromEntry(req.BoardID, logCID, entry))
 
 	threadMeta.ThreadID = threadCID
 	threadMeta.RootPostCID = rootPostCID
@@ -538,6 +876,10 @@ func (s *Server) addPost(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing required fields")
 		return
 	}
+	if err := s.validateAttachments(ctx, req.Attachments); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	tm, err := s.Storage.LoadThreadMeta(ctx, req.ThreadID)
 	if err != nil {
@@ -608,6 +950,7 @@ func (s *Server) addPost(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
+	s.publishBoardEvent(boardEventFromEntry(boardID, logCID, e))
 
 	writeJSON(w, http.StatusOK, AddPostResponse{
 		PostCID:      postCID,
@@ -628,6 +971,12 @@ func (s *Server) editPost(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing required fields")
 		return
 	}
+	if req.Attachments != nil {
+		if err := s.validateAttachments(ctx, req.Attachments); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	oldPost, err := s.Storage.LoadPost(ctx, oldCID)
 	if err != nil {
@@ -684,6 +1033,9 @@ func (s *Server) editPost(w http.ResponseWriter, r *http.Request) {
 	if req.DisplayName != nil {
 		newPost.DisplayName = *req.DisplayName
 	}
+	if req.Attachments != nil {
+		newPost.Attachments = req.Attachments
+	}
 	if newPost.Meta == nil {
 		newPost.Meta = map[string]any{}
 	}
@@ -723,6 +1075,7 @@ func (s *Server) editPost(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
+	s.publishBoardEvent(boardEventFromEntry(boardID, logCID, e))
 
 	writeJSON(w, http.StatusOK, EditPostResponse{
 		OldPostCID:   oldCID,
@@ -807,6 +1160,7 @@ func (s *Server) tombstonePost(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
+	s.publishBoardEvent(boardEventFromEntry(boardID, logCID, e))
 
 	writeJSON(w, http.StatusOK, TombstonePostResponse{
 		TargetPostCID: targetCID,
@@ -827,6 +1181,11 @@ func (s *Server) searchPosts(w http.ResponseWriter, r *http.Request) {
 	since := strings.TrimSpace(r.URL.Query().Get("since"))
 	until := strings.TrimSpace(r.URL.Query().Get("until"))
 	limit, offset := parseLimitOffset(r, 50, 0, 200)
+	maxAt, maxCID, minAt, minCID, err := parseSQLPageCursors(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	results, err := s.Indexer.SearchPosts(ctx, bbsindexer.SearchPostsParams{
 		Query:        q,
@@ -836,11 +1195,28 @@ func (s *Server) searchPosts(w http.ResponseWriter, r *http.Request) {
 		Until:        until,
 		Limit:        limit,
 		Offset:       offset,
+		MaxCreatedAt: maxAt,
+		MaxPostCID:   maxCID,
+		MinCreatedAt: minAt,
+		MinPostCID:   minCID,
 	})
 	if err != nil {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
+
+	var nextCursor, prevCursor string
+	if len(results) == limit {
+		last := results[len(results)-1]
+		at, _ := time.Parse(time.RFC3339, last.CreatedAt)
+		nextCursor = encodeCursor(at, last.PostCID)
+	}
+	if len(results) > 0 && (minAt != "" || maxAt != "") {
+		first := results[0]
+		at, _ := time.Parse(time.RFC3339, first.CreatedAt)
+		prevCursor = encodeCursor(at, first.PostCID)
+	}
+	writeLinkHeader(w, r, nextCursor, prevCursor)
 	writeJSON(w, http.StatusOK, results)
 }
 
@@ -852,17 +1228,39 @@ func (s *Server) searchBoards(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	q := strings.TrimSpace(r.URL.Query().Get("q"))
 	limit, offset := parseLimitOffset(r, 50, 0, 200)
+	maxAt, maxCID, minAt, minCID, err := parseSQLPageCursors(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	results, err := s.Indexer.SearchBoards(ctx, bbsindexer.SearchBoardsParams{
-		Query:  q,
-		Limit:  limit,
-		Offset: offset,
+		Query:        q,
+		Limit:        limit,
+		Offset:       offset,
+		MaxCreatedAt: maxAt,
+		MaxBoardID:   maxCID,
+		MinCreatedAt: minAt,
+		MinBoardID:   minCID,
 	})
 	if err != nil {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
 
+	var nextCursor, prevCursor string
+	if len(results) == limit {
+		last := results[len(results)-1]
+		at, _ := time.Parse(time.RFC3339, last.CreatedAt)
+		nextCursor = encodeCursor(at, last.BoardID)
+	}
+	if len(results) > 0 && (minAt != "" || maxAt != "") {
+		first := results[0]
+		at, _ := time.Parse(time.RFC3339, first.CreatedAt)
+		prevCursor = encodeCursor(at, first.BoardID)
+	}
+	writeLinkHeader(w, r, nextCursor, prevCursor)
+
 	out := make([]BoardItem, 0, len(results))
 	for _, b := range results {
 		out = append(out, BoardItem{
@@ -892,18 +1290,40 @@ func (s *Server) searchThreads(w http.ResponseWriter, r *http.Request) {
 	q := strings.TrimSpace(r.URL.Query().Get("q"))
 	boardID := strings.TrimSpace(r.URL.Query().Get("boardId"))
 	limit, offset := parseLimitOffset(r, 50, 0, 200)
+	maxAt, maxCID, minAt, minCID, err := parseSQLPageCursors(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	results, err := s.Indexer.SearchThreads(ctx, bbsindexer.SearchThreadsParams{
-		Query:   q,
-		BoardID: boardID,
-		Limit:   limit,
-		Offset:  offset,
+		Query:        q,
+		BoardID:      boardID,
+		Limit:        limit,
+		Offset:       offset,
+		MaxCreatedAt: maxAt,
+		MaxThreadID:  maxCID,
+		MinCreatedAt: minAt,
+		MinThreadID:  minCID,
 	})
 	if err != nil {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
 
+	var nextCursor, prevCursor string
+	if len(results) == limit {
+		last := results[len(results)-1]
+		at, _ := time.Parse(time.RFC3339, last.CreatedAt)
+		nextCursor = encodeCursor(at, last.ThreadID)
+	}
+	if len(results) > 0 && (minAt != "" || maxAt != "") {
+		first := results[0]
+		at, _ := time.Parse(time.RFC3339, first.CreatedAt)
+		prevCursor = encodeCursor(at, first.ThreadID)
+	}
+	writeLinkHeader(w, r, nextCursor, prevCursor)
+
 	out := make([]ThreadItem, 0, len(results))
 	for _, t := range results {
 		out = append(out, ThreadItem{
@@ -960,6 +1380,7 @@ func (s *Server) advanceBoardLogHead(ctx context.Context, bm *types.BoardMeta, b
 	}
 	s.markSeenBoardMetaCID(newBoardMetaCID)
 	_ = s.forwardBoardAnnounceBestEffort(ctx, newBoardMetaCID)
+	s.publishBoardAnnounce(boardID, newBoardMetaCID)
 	return newBoardMetaCID, nil
 }
 
@@ -968,6 +1389,9 @@ func (s *Server) announceBoard(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotImplemented, "announce is available in client/indexer/full roles")
 		return
 	}
+	if s.startAsyncIfRequested(w, r, "announceBoard", s.announceBoard) {
+		return
+	}
 	ctx := r.Context()
 	var req AnnounceBoardRequest
 	if err := readJSON(w, r, &req); err != nil {
@@ -1048,8 +1472,10 @@ func (s *Server) announceBoard(w http.ResponseWriter, r *http.Request) {
 		if s.Role != "client" {
 			forwarded = s.forwardBoardAnnounceBestEffort(ctx, req.BoardMetaCID)
 		}
+		s.publishBoardAnnounce(boardID, req.BoardMetaCID)
 	} else {
-		log.Printf("api announceBoard ignored boardId=%s reason=%s cid=%s", boardID, ignoredReason, req.BoardMetaCID)
+		reqlog.FromContext(ctx).With(reqlog.F("route", "announceBoard")).Info("announce ignored",
+			reqlog.F("board_id", boardID), reqlog.F("reason", ignoredReason), reqlog.F("cid", req.BoardMetaCID))
 	}
 
 	writeJSON(w, http.StatusOK, AnnounceBoardResponse{
@@ -1099,18 +1525,30 @@ func (s *Server) shouldAcceptBoardMetaUpdate(
 		return false, "rollback", nil
 	}
 
-	log.Printf(
-		"api announceBoard fork detected boardId=%s currentMeta=%s currentHead=%s incomingMeta=%s incomingHead=%s (keeping current)",
-		boardID, currentBoardMetaCID, currentHead, incomingBoardMetaCID, incomingHead,
-	)
+	reqlog.FromContext(ctx).With(reqlog.F("route", "shouldAcceptBoardMetaUpdate")).Info("fork detected (keeping current)",
+		reqlog.F("board_id", boardID), reqlog.F("current_meta", currentBoardMetaCID), reqlog.F("current_head", currentHead),
+		reqlog.F("incoming_meta", incomingBoardMetaCID), reqlog.F("incoming_head", incomingHead))
 	return false, "fork", nil
 }
 
+// isBoardLogDescendant reports whether ancestorCID is reachable from
+// headCID by following PrevLogCID, i.e. whether headCID descends from (or
+// equals) ancestorCID. When s.logIndex is available (the normal case —
+// initNetworkDeps always sets it), it answers via
+// isBoardLogDescendantIndexed's O(log n) binary-lifting jumps instead of
+// walking every entry in between; isBoardLogDescendantLinear remains as
+// the fallback for when the index is unavailable.
 func (s *Server) isBoardLogDescendant(ctx context.Context, boardID, headCID, ancestorCID string) (bool, error) {
 	if headCID == "" || ancestorCID == "" {
 		return false, nil
 	}
+	if s.logIndex != nil {
+		return s.isBoardLogDescendantIndexed(ctx, boardID, headCID, ancestorCID)
+	}
+	return s.isBoardLogDescendantLinear(ctx, boardID, headCID, ancestorCID)
+}
 
+func (s *Server) isBoardLogDescendantLinear(ctx context.Context, boardID, headCID, ancestorCID string) (bool, error) {
 	visited := make(map[string]struct{})
 	current := headCID
 	for current != "" {
@@ -1121,7 +1559,7 @@ func (s *Server) isBoardLogDescendant(ctx context.Context, boardID, headCID, anc
 			return false, nil
 		}
 		if len(visited) >= 50_000 {
-			return false, bbslog.ErrLogTooDeep
+			return false, bbslog.ErrChainTooLong
 		}
 		visited[current] = struct{}{}
 
@@ -1160,8 +1598,10 @@ func (s *Server) syncBoardFromTrustedIndexersBestEffort(ctx context.Context, boa
 	}
 	currentCID = strings.TrimSpace(currentCID)
 
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "clientBoardSync"), reqlog.F("board_id", boardID))
+
 	if err := s.TrustedIndexers.Load(); err != nil {
-		log.Printf("trusted indexers load error: %v", err)
+		logger.Info("trusted indexers load error", reqlog.F("error", err.Error()))
 		return
 	}
 	peers := s.TrustedIndexers.List()
@@ -1170,65 +1610,139 @@ func (s *Server) syncBoardFromTrustedIndexersBestEffort(ctx context.Context, boa
 	}
 
 	s.initNetworkDeps()
-	for _, baseURL := range peers {
+	peers = s.peerHealth.SortedPeers(peers)
+
+	// Query peers through the bounded pool rather than one at a time: with
+	// dozens of trusted indexers configured, a sequential loop would pay
+	// each one's 3s timeout in the worst case. ctx is canceled once an
+	// update that a quorum of indexers agreed on has been applied, so
+	// in-flight requests to the remaining peers are abandoned
+	// rather than run to completion for no benefit.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	quorum := s.indexerQuorum()
+
+	var (
+		mu       sync.Mutex
+		votes    = make(map[string]int)
+		metas    = make(map[string]*types.BoardMeta)
+		applyOne sync.Once
+	)
+
+	s.fetchPool.forEach(len(peers), func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		baseURL := peers[i]
+		if !s.peerHealth.Allowed(baseURL) {
+			return
+		}
 		endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/boards/" + url.PathEscape(boardID)
 
-		rctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		start := time.Now()
+		rctx, rcancel := context.WithTimeout(ctx, 3*time.Second)
 		req, err := http.NewRequestWithContext(rctx, http.MethodGet, endpoint, nil)
 		if err != nil {
-			cancel()
-			continue
+			rcancel()
+			return
 		}
+		setOutboundRequestID(req, ctx)
 
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
-			cancel()
-			continue
+			rcancel()
+			if !errors.Is(err, context.Canceled) {
+				s.peerHealth.RecordFailure(baseURL)
+			}
+			return
 		}
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
 		_ = resp.Body.Close()
-		cancel()
+		rcancel()
 
 		if resp.StatusCode == http.StatusNotFound {
-			continue
+			s.peerHealth.RecordSuccess(baseURL, time.Since(start))
+			return
 		}
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			continue
+			s.peerHealth.RecordFailure(baseURL)
+			return
 		}
+		s.peerHealth.RecordSuccess(baseURL, time.Since(start))
 
 		var item BoardItem
 		if err := json.Unmarshal(body, &item); err != nil {
-			continue
+			return
 		}
 		incomingCID := strings.TrimSpace(item.BoardMetaCID)
 		if incomingCID == "" || incomingCID == currentCID {
-			continue
+			return
 		}
 
 		incomingBM := item.Board
 		if strings.TrimSpace(incomingBM.BoardID) != boardID {
-			continue
+			return
 		}
 		if !bbslog.VerifyBoardMeta(&incomingBM) {
-			continue
+			return
 		}
 
-		accept, _, err := s.shouldAcceptBoardMetaUpdate(ctx, boardID, currentCID, currentBM, incomingCID, &incomingBM)
-		if err != nil {
-			log.Printf("client board sync failed boardId=%s base=%s: %v", boardID, baseURL, err)
-			continue
+		mu.Lock()
+		votes[incomingCID]++
+		reached := votes[incomingCID] >= quorum
+		if _, ok := metas[incomingCID]; !ok {
+			bm := incomingBM
+			metas[incomingCID] = &bm
 		}
-		if !accept {
-			continue
-		}
-
-		if err := s.Boards.Upsert(boardID, incomingCID); err != nil {
-			log.Printf("client board sync save failed boardId=%s: %v", boardID, err)
+		bm := metas[incomingCID]
+		mu.Unlock()
+		if !reached {
 			return
 		}
-		s.markSeenBoardMetaCID(incomingCID)
+
+		applyOne.Do(func() {
+			accept, _, err := s.shouldAcceptBoardMetaUpdate(ctx, boardID, currentCID, currentBM, incomingCID, bm)
+			if err != nil {
+				logger.Info("client board sync failed", reqlog.F("base_url", baseURL), reqlog.F("error", err.Error()))
+				return
+			}
+			if !accept {
+				return
+			}
+			if err := s.Boards.Upsert(boardID, incomingCID); err != nil {
+				logger.Info("client board sync save failed", reqlog.F("error", err.Error()))
+				return
+			}
+			s.markSeenBoardMetaCID(incomingCID)
+			s.publishNewBoardLogEntries(ctx, boardID, bm, strOrEmpty(currentBM.LogHeadCID))
+			cancel()
+		})
+	})
+}
+
+// publishNewBoardLogEntries publishes a boardEvent for every entry
+// syncBoardFromTrustedIndexersBestEffort just pulled in ahead of
+// afterLogCID (the board's previous log head), so local SSE subscribers
+// hear about remote activity too, not just writes this server served
+// itself. Best-effort like the sync it's called from: a walk failure
+// (e.g. a slow/unreachable flexipfs peer) is logged and swallowed rather
+// than surfaced, since the board update itself already succeeded.
+func (s *Server) publishNewBoardLogEntries(ctx context.Context, boardID string, bm *types.BoardMeta, afterLogCID string) {
+	entries, found, err := s.boardLogEntriesAfter(ctx, bm, afterLogCID)
+	if err != nil {
+		reqlog.FromContext(ctx).With(reqlog.F("route", "clientBoardSync"), reqlog.F("board_id", boardID)).
+			Info("event replay failed", reqlog.F("error", err.Error()))
 		return
 	}
+	if !found {
+		return
+	}
+	for _, item := range entries {
+		if !item.ValidSignature {
+			continue
+		}
+		s.publishBoardEvent(boardEventFromEntry(boardID, item.CID, item.Value))
+	}
 }
 
 func (s *Server) markSeenBoardMetaCID(boardMetaCID string) {
@@ -1240,11 +1754,12 @@ func (s *Server) markSeenBoardMetaCID(boardMetaCID string) {
 }
 
 func (s *Server) forwardBoardAnnounceBestEffort(ctx context.Context, boardMetaCID string) int {
+	logger := reqlog.FromContext(ctx).With(reqlog.F("route", "announceForward"))
 	if s.TrustedIndexers == nil {
 		return 0
 	}
 	if err := s.TrustedIndexers.Load(); err != nil {
-		log.Printf("trusted indexers load error: %v", err)
+		logger.Info("trusted indexers load error", reqlog.F("error", err.Error()))
 		return 0
 	}
 	peers := s.TrustedIndexers.List()
@@ -1253,37 +1768,47 @@ func (s *Server) forwardBoardAnnounceBestEffort(ctx context.Context, boardMetaCI
 	}
 
 	s.initNetworkDeps()
+	peers = s.peerHealth.SortedPeers(peers)
 	forwarded := 0
 	for _, baseURL := range peers {
+		if !s.peerHealth.Allowed(baseURL) {
+			continue
+		}
 		endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/announce/board"
 		reqBody, _ := json.Marshal(AnnounceBoardRequest{BoardMetaCID: boardMetaCID})
 
+		start := time.Now()
 		rctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 		req, err := http.NewRequestWithContext(rctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
 		if err != nil {
 			cancel()
-			log.Printf("announce forward: request error base=%s: %v", baseURL, err)
+			logger.Info("request error", reqlog.F("base_url", baseURL), reqlog.F("error", err.Error()))
 			continue
 		}
 		req.Header.Set("Content-Type", "application/json")
+		setOutboundRequestID(req, ctx)
+		s.signOutboundAnnounceRequestIfConfigured(req, reqBody)
 
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
 			cancel()
-			log.Printf("announce forward: http error base=%s: %v", baseURL, err)
+			s.peerHealth.RecordFailure(baseURL)
+			logger.Info("http error", reqlog.F("base_url", baseURL), reqlog.F("error", err.Error()))
 			continue
 		}
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
 		_ = resp.Body.Close()
 		cancel()
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			s.peerHealth.RecordFailure(baseURL)
 			msg := strings.TrimSpace(string(body))
 			if msg == "" {
 				msg = resp.Status
 			}
-			log.Printf("announce forward: http %d base=%s: %s", resp.StatusCode, baseURL, msg)
+			logger.Info("non-2xx response", reqlog.F("base_url", baseURL), reqlog.F("status", resp.StatusCode), reqlog.F("body", msg))
 			continue
 		}
+		s.peerHealth.RecordSuccess(baseURL, time.Since(start))
 		forwarded++
 	}
 	return forwarded
@@ -1302,6 +1827,64 @@ func (s *Server) listTrustedIndexers(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, s.TrustedIndexers.List())
 }
 
+// PeeringHeadsResponse is GET /api/v1/indexer/peering/heads's body: every
+// board this indexer knows about and the BoardMeta CID it's currently
+// synced to. A peer polling this (see bbs/indexer.PullFromPeer) resolves
+// each one through its own ordinary sync path rather than fetching log
+// entries directly from this server, so peering only needs to announce
+// pointers into the content both indexers already read from flex-ipfs.
+type PeeringHeadsResponse struct {
+	Boards []PeeringHead `json:"boards"`
+}
+
+type PeeringHead struct {
+	BoardID      string `json:"boardId"`
+	BoardMetaCID string `json:"boardMetaCid"`
+}
+
+func (s *Server) indexerPeeringHeads(w http.ResponseWriter, r *http.Request) {
+	if s.Indexer == nil {
+		writeError(w, http.StatusNotImplemented, "peering requires an indexer/full role")
+		return
+	}
+	heads, err := s.Indexer.ListBoardHeads(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := PeeringHeadsResponse{Boards: make([]PeeringHead, 0, len(heads))}
+	for _, h := range heads {
+		resp.Boards = append(resp.Boards, PeeringHead{BoardID: h.BoardID, BoardMetaCID: h.BoardMetaCID})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RepublisherStatusResponse is GET /api/v1/republisher/status's body: the
+// most recent republisher.Republisher.RunOnce result, for operators
+// checking whether board content is actually staying advertised.
+type RepublisherStatusResponse struct {
+	LastRunAt       time.Time     `json:"lastRunAt"`
+	LastRunDuration time.Duration `json:"lastRunDurationNs"`
+	CIDsRepublished int           `json:"cidsRepublished"`
+	Failures        int           `json:"failures"`
+	LastError       string        `json:"lastError,omitempty"`
+}
+
+func (s *Server) republisherStatus(w http.ResponseWriter, r *http.Request) {
+	if s.Republisher == nil {
+		writeError(w, http.StatusNotImplemented, "republishing requires an archiver/full role")
+		return
+	}
+	m := s.Republisher.Status()
+	writeJSON(w, http.StatusOK, RepublisherStatusResponse{
+		LastRunAt:       m.LastRunAt,
+		LastRunDuration: m.LastRunDuration,
+		CIDsRepublished: m.CIDsRepublished,
+		Failures:        m.Failures,
+		LastError:       m.LastError,
+	})
+}
+
 func (s *Server) proxySearch(w http.ResponseWriter, r *http.Request, apiPath string) {
 	if s.TrustedIndexers == nil {
 		writeError(w, http.StatusNotImplemented, "search requires an indexer/full role or a trusted indexer proxy")
@@ -1318,30 +1901,38 @@ func (s *Server) proxySearch(w http.ResponseWriter, r *http.Request, apiPath str
 	}
 
 	s.initNetworkDeps()
+	peers = s.peerHealth.SortedPeers(peers)
 
+	logger := reqlog.FromContext(r.Context()).With(reqlog.F("route", "searchProxy"))
 	query := strings.TrimSpace(r.URL.RawQuery)
 
 	var lastErr string
 	for _, baseURL := range peers {
+		if !s.peerHealth.Allowed(baseURL) {
+			continue
+		}
 		target := strings.TrimRight(baseURL, "/") + apiPath
 		if query != "" {
 			target += "?" + query
 		}
 
+		start := time.Now()
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 		if err != nil {
 			cancel()
 			lastErr = err.Error()
-			log.Printf("search proxy request error base=%s: %v", baseURL, err)
+			logger.Info("request error", reqlog.F("base_url", baseURL), reqlog.F("error", err.Error()))
 			continue
 		}
+		setOutboundRequestID(req, r.Context())
 
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
 			cancel()
+			s.peerHealth.RecordFailure(baseURL)
 			lastErr = err.Error()
-			log.Printf("search proxy http error base=%s: %v", baseURL, err)
+			logger.Info("http error", reqlog.F("base_url", baseURL), reqlog.F("error", err.Error()))
 			continue
 		}
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
@@ -1349,6 +1940,7 @@ func (s *Server) proxySearch(w http.ResponseWriter, r *http.Request, apiPath str
 		cancel()
 
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			s.peerHealth.RecordSuccess(baseURL, time.Since(start))
 			ct := strings.TrimSpace(resp.Header.Get("Content-Type"))
 			if ct == "" {
 				ct = "application/json; charset=utf-8"
@@ -1359,8 +1951,10 @@ func (s *Server) proxySearch(w http.ResponseWriter, r *http.Request, apiPath str
 			return
 		}
 
-		// For client errors, propagate as-is (likely a bad query).
+		// For client errors, propagate as-is (likely a bad query) — the peer
+		// answered correctly, so this isn't a health failure.
 		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusNotFound {
+			s.peerHealth.RecordSuccess(baseURL, time.Since(start))
 			ct := strings.TrimSpace(resp.Header.Get("Content-Type"))
 			if ct == "" {
 				ct = "application/json; charset=utf-8"
@@ -1371,12 +1965,13 @@ func (s *Server) proxySearch(w http.ResponseWriter, r *http.Request, apiPath str
 			return
 		}
 
+		s.peerHealth.RecordFailure(baseURL)
 		msg := strings.TrimSpace(string(body))
 		if msg == "" {
 			msg = resp.Status
 		}
 		lastErr = msg
-		log.Printf("search proxy failed base=%s status=%d: %s", baseURL, resp.StatusCode, msg)
+		logger.Info("non-2xx response", reqlog.F("base_url", baseURL), reqlog.F("status", resp.StatusCode), reqlog.F("body", msg))
 	}
 
 	if lastErr == "" {
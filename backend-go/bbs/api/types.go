@@ -13,6 +13,18 @@ type ThreadItem struct {
 	Thread        types.ThreadMeta `json:"thread"`
 }
 
+// ThreadsPage is the cursor-paginated response for GET .../threads. Pass
+// NextCursor back as ?max_id= to fetch older threads, or PrevCursor as
+// ?min_id= to fetch newer ones; an empty value means there's nothing more
+// in that direction. The same cursors are echoed in the response's Link
+// header (RFC 5988, rel="next"/"prev").
+type ThreadsPage struct {
+	Threads    []ThreadItem `json:"threads"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+	PrevCursor string       `json:"prevCursor,omitempty"`
+	Limit      int          `json:"limit"`
+}
+
 type ThreadPostItem struct {
 	CID             string     `json:"cid"`
 	Post            types.Post `json:"post"`
@@ -20,10 +32,17 @@ type ThreadPostItem struct {
 	TombstoneReason *string    `json:"tombstoneReason"`
 }
 
+// ThreadResponse is the cursor-paginated response for GET .../threads/{id}.
+// Posts are newest-first, same convention as ThreadsPage: NextCursor (echoed
+// in the Link header as rel="next") pages to older replies via ?max_id=,
+// PrevCursor (rel="prev") to newer ones via ?min_id=.
 type ThreadResponse struct {
 	ThreadMetaCID string           `json:"threadMetaCid"`
 	ThreadMeta    types.ThreadMeta `json:"threadMeta"`
 	Posts         []ThreadPostItem `json:"posts"`
+	NextCursor    string           `json:"nextCursor,omitempty"`
+	PrevCursor    string           `json:"prevCursor,omitempty"`
+	Limit         int              `json:"limit"`
 }
 
 type CreateThreadRequest struct {
@@ -62,9 +81,13 @@ type AddPostResponse struct {
 }
 
 type EditPostRequest struct {
-	Body          types.PostBody `json:"body"`
-	DisplayName   *string        `json:"displayName"`
-	AuthorPrivKey string         `json:"authorPrivKey"`
+	Body types.PostBody `json:"body"`
+	// Attachments, if nil, leaves the post's existing attachments
+	// unchanged; a non-nil (possibly empty) slice replaces them outright,
+	// same nil-means-no-change convention DisplayName uses via *string.
+	Attachments   []types.Attachment `json:"attachments"`
+	DisplayName   *string            `json:"displayName"`
+	AuthorPrivKey string             `json:"authorPrivKey"`
 }
 
 type EditPostResponse struct {
@@ -96,3 +119,71 @@ type AnnounceBoardResponse struct {
 	IgnoredReason string `json:"ignoredReason,omitempty"`
 	Forwarded     int    `json:"forwarded"`
 }
+
+// BoardDigestEntry is one board's compact anti-entropy summary: enough for
+// a peer to tell whether it has anything newer (LogHeadCID differs) without
+// sending the BoardMeta itself. BoardMetaCID is the reporting side's own
+// CID for the board — on a request it's the sender's current pointer, on a
+// response it's the CID the responder thinks is ahead.
+type BoardDigestEntry struct {
+	BoardID      string `json:"boardId"`
+	BoardMetaCID string `json:"boardMetaCid"`
+	LogHeadCID   string `json:"logHeadCid"`
+}
+
+type SyncDigestRequest struct {
+	Boards []BoardDigestEntry `json:"boards"`
+}
+
+type SyncDigestResponse struct {
+	Boards []BoardDigestEntry `json:"boards"`
+}
+
+// PeerSelfResponse is GET /api/v1/peers/self's body: this node's own
+// announce-signing identity, so a fresh peer can learn the keyId/pubkey
+// pair to add to its AnnounceAuth allow-list without out-of-band config.
+type PeerSelfResponse struct {
+	KeyID  string `json:"keyId"`
+	PubKey string `json:"pubKey"`
+	AuthOn bool   `json:"authOn"`
+}
+
+// CapabilitiesResponse is GET /api/v1/capabilities's body: what protocol
+// version this node speaks and which optional behaviors it implements, so
+// a caller can gate a call (e.g. POST /sync/digest) on whether the peer
+// actually understands it instead of discovering that the hard way.
+type CapabilitiesResponse struct {
+	Version  string   `json:"version"`
+	Protocol string   `json:"protocol"`
+	Features []string `json:"features"`
+}
+
+// PeerCapabilities is one entry in GET /api/v1/peers: a trusted indexer's
+// base URL alongside the last capability probe this node ran against it.
+// Err is set (and Capabilities left zero) when the most recent probe
+// failed, so operators can tell "never reachable" apart from "doesn't
+// support feature X".
+type PeerCapabilities struct {
+	BaseURL      string               `json:"baseUrl"`
+	Capabilities CapabilitiesResponse `json:"capabilities"`
+	Err          string               `json:"error,omitempty"`
+	ProbedAt     string               `json:"probedAt,omitempty"`
+}
+
+// PeerHealthStatus is one trusted indexer's circuit-breaker state: its
+// current state (closed/open/half-open), recent success rate over
+// peerHealthWindow outcomes, RTT EMA in milliseconds (0 if no successful
+// call has been recorded yet), and consecutive-failure count.
+type PeerHealthStatus struct {
+	State               string  `json:"state"`
+	SuccessRate         float64 `json:"successRate"`
+	RTTMillis           float64 `json:"rttMillis"`
+	ConsecutiveFailures int     `json:"consecutiveFailures"`
+}
+
+// PeerHealthEntry is GET /api/v1/peers/health's per-peer entry: a trusted
+// indexer's base URL alongside its PeerHealthStatus.
+type PeerHealthEntry struct {
+	BaseURL string `json:"baseUrl"`
+	PeerHealthStatus
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// websocketAcceptGUID is the magic value RFC 6455 §1.3 defines for deriving
+// Sec-WebSocket-Accept from a client's Sec-WebSocket-Key.
+const websocketAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket frame opcodes this bridge cares about; see RFC 6455 §5.2.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+var errWebsocketClosed = errors.New("websocket: connection closed")
+
+// websocketConn is a minimal RFC 6455 server-side connection: enough to
+// push unfragmented text frames to the client and notice when it closes,
+// without pulling in a full websocket library for this bridge's one-way
+// (server pushes Events, client never sends anything meaningful back)
+// use case.
+type websocketConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// upgradeWebsocket completes the RFC 6455 handshake against r if it's a
+// valid websocket upgrade request, hijacking the underlying connection on
+// success. Callers own the returned websocketConn's lifecycle (it must be
+// closed) and must not use w after this returns.
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Upgrade") != "websocket" {
+		return nil, fmt.Errorf("not a websocket upgrade request")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + websocketAcceptGUID))
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+
+	if _, err := fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", accept); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &websocketConn{conn: conn, br: rw.Reader}, nil
+}
+
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}
+
+// WriteText sends payload as a single unfragmented text frame. Server
+// frames are sent unmasked, per RFC 6455 §5.1 ("a server MUST NOT mask any
+// frames that it sends to the client").
+func (c *websocketConn) WriteText(payload []byte) error {
+	return c.writeFrame(wsOpText, payload)
+}
+
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	var header []byte
+	header = append(header, 0x80|opcode) // FIN set, no fragmentation
+
+	n := len(payload)
+	switch {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// ReadLoop blocks reading client frames until the connection closes or a
+// close frame arrives, answering pings with pongs along the way. The
+// bridge has nothing to learn from a client frame's payload (this is a
+// server-push stream), so ReadLoop exists only to notice disconnects that
+// a write alone wouldn't surface until the next write attempt.
+func (c *websocketConn) ReadLoop() error {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case wsOpClose:
+			return errWebsocketClosed
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *websocketConn) readFrame() (opcode byte, payload []byte, err error) {
+	head, err := readN(c.br, 2)
+	if err != nil {
+		return 0, nil, err
+	}
+	opcode = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		ext, err := readN(c.br, 2)
+		if err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext, err := readN(c.br, 8)
+		if err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	var maskKey []byte
+	if masked {
+		maskKey, err = readN(c.br, 4)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload, err = readN(c.br, int(length))
+	if err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+func readN(r io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
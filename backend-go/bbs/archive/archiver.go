@@ -2,13 +2,20 @@ package archive
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"flex-bbs/backend-go/bbs/config"
 	bbslog "flex-bbs/backend-go/bbs/log"
 	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/storage/car"
 	"flex-bbs/backend-go/bbs/types"
 )
 
@@ -18,11 +25,352 @@ type Archiver struct {
 	Dir     string
 }
 
+// manifestFileName is manifest.json's name under a.Dir; ArchivedCIDs skips
+// it when listing archived <cid>.json files.
+const manifestFileName = "manifest.json"
+
+// archiveManifest is SyncOnce's resumption state, persisted to
+// <a.Dir>/manifest.json. For each board it records the LogHeadCID already
+// archived, so a re-run with nothing new can skip that board entirely, and
+// when the board's sync last completed. CIDs indexes every file SyncOnce
+// has written by its sha256, so SyncOnce can skip re-writing content that
+// hasn't changed and Verify can detect a file that has silently rotted or
+// been tampered with since.
+type archiveManifest struct {
+	Boards map[string]*boardManifestEntry `json:"boards"`
+	CIDs   map[string]string              `json:"cids"`
+}
+
+type boardManifestEntry struct {
+	LogHeadCID   string    `json:"logHeadCid"`
+	LastSyncedAt time.Time `json:"lastSyncedAt"`
+}
+
+func (a *Archiver) manifestPath() string {
+	return filepath.Join(a.Dir, manifestFileName)
+}
+
+func (a *Archiver) loadManifest() (*archiveManifest, error) {
+	b, err := os.ReadFile(a.manifestPath())
+	if os.IsNotExist(err) {
+		return &archiveManifest{Boards: map[string]*boardManifestEntry{}, CIDs: map[string]string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m archiveManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("archive: decode manifest: %w", err)
+	}
+	if m.Boards == nil {
+		m.Boards = map[string]*boardManifestEntry{}
+	}
+	if m.CIDs == nil {
+		m.CIDs = map[string]string{}
+	}
+	return &m, nil
+}
+
+func (a *Archiver) saveManifest(m *archiveManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.manifestPath(), b, 0o644)
+}
+
+// SyncOnce writes every configured board's BoardMeta, BoardLogEntry chain,
+// and referenced ThreadMeta/Post objects out as loose <cid>.json files
+// under a.Dir, resuming from manifest.json rather than redoing the full
+// walk on every call: a board whose BoardMeta.LogHeadCID still matches the
+// manifest is skipped outright, and the log chain walk stops as soon as it
+// reaches the CID the manifest already has as that board's head, since
+// everything from there back was archived by an earlier run.
 func (a *Archiver) SyncOnce(ctx context.Context) error {
+	if err := os.MkdirAll(a.Dir, 0o755); err != nil {
+		return err
+	}
 	if err := a.Boards.Load(); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(a.Dir, 0o755); err != nil {
+	m, err := a.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range a.Boards.List() {
+		bm, err := a.Storage.LoadBoardMeta(ctx, ref.BoardMetaCID)
+		if err != nil {
+			continue
+		}
+		if !bbslog.VerifyBoardMeta(bm) {
+			continue
+		}
+
+		headCID := ""
+		if bm.LogHeadCID != nil {
+			headCID = *bm.LogHeadCID
+		}
+		board := m.Boards[ref.BoardID]
+		if board != nil && board.LogHeadCID == headCID {
+			continue
+		}
+		archivedHead := ""
+		if board != nil {
+			archivedHead = board.LogHeadCID
+		}
+
+		if err := a.saveJSONIfChanged(m, ref.BoardMetaCID, bm); err != nil {
+			continue
+		}
+
+		entries, err := a.fetchNewBoardLogEntries(ctx, bm.LogHeadCID, archivedHead)
+		if err != nil {
+			continue
+		}
+		for _, item := range entries {
+			if item.Value == nil {
+				continue
+			}
+			if err := a.saveJSONIfChanged(m, item.CID, item.Value); err != nil {
+				continue
+			}
+			a.maybeArchiveThreadMeta(ctx, m, item.Value.ThreadID)
+			a.maybeArchivePost(ctx, m, item.Value.PostCID)
+			a.maybeArchivePost(ctx, m, item.Value.OldPostCID)
+			a.maybeArchivePost(ctx, m, item.Value.NewPostCID)
+			a.maybeArchivePost(ctx, m, item.Value.TargetPostCID)
+		}
+
+		m.Boards[ref.BoardID] = &boardManifestEntry{LogHeadCID: headCID, LastSyncedAt: time.Now().UTC()}
+	}
+
+	return a.saveManifest(m)
+}
+
+// fetchNewBoardLogEntries walks a board log chain from headCID backwards,
+// the same traversal bbslog.FetchChain does, except it stops as soon as it
+// reaches archivedLogHeadCID (if non-empty) instead of continuing to the
+// chain's root — everything at or behind that CID was already archived by
+// an earlier SyncOnce run and doesn't need refetching. Entries are
+// returned oldest-first, like FetchChain.
+func (a *Archiver) fetchNewBoardLogEntries(ctx context.Context, headCID *string, archivedLogHeadCID string) ([]bbslog.EntryWithCID[types.BoardLogEntry], error) {
+	if headCID == nil || *headCID == "" {
+		return nil, nil
+	}
+	const maxDepth = 50_000
+
+	visited := make(map[string]struct{})
+	var out []bbslog.EntryWithCID[types.BoardLogEntry]
+
+	current := headCID
+	for current != nil && *current != "" {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cid := *current
+		if cid == archivedLogHeadCID {
+			break
+		}
+		if _, ok := visited[cid]; ok {
+			break
+		}
+		if len(out) >= maxDepth {
+			return nil, bbslog.ErrChainTooLong
+		}
+		visited[cid] = struct{}{}
+
+		e, err := a.Storage.LoadBoardLogEntry(ctx, cid)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bbslog.EntryWithCID[types.BoardLogEntry]{CID: cid, Value: e, ValidSignature: bbslog.VerifyBoardLogEntry(e)})
+		current = e.PrevLogCID
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+func (a *Archiver) maybeArchivePost(ctx context.Context, m *archiveManifest, cid *string) {
+	if cid == nil || *cid == "" {
+		return
+	}
+	p, err := a.Storage.LoadPost(ctx, *cid)
+	if err != nil {
+		return
+	}
+	if !bbslog.VerifyPost(p) {
+		return
+	}
+	_ = a.saveJSONIfChanged(m, *cid, p)
+}
+
+func (a *Archiver) maybeArchiveThreadMeta(ctx context.Context, m *archiveManifest, cid string) {
+	if cid == "" {
+		return
+	}
+	tm, err := a.Storage.LoadThreadMeta(ctx, cid)
+	if err != nil {
+		return
+	}
+	if !bbslog.VerifyThreadMeta(tm) {
+		return
+	}
+	_ = a.saveJSONIfChanged(m, cid, tm)
+}
+
+// saveJSONIfChanged writes v to <a.Dir>/<cid>.json and records its sha256
+// in m, unless m already has cid recorded with that same hash — the
+// common case on a re-sync, where most of a board's history hasn't
+// changed since the last run.
+func (a *Archiver) saveJSONIfChanged(m *archiveManifest, cid string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	hexSum := hex.EncodeToString(sum[:])
+	if existing, ok := m.CIDs[cid]; ok && existing == hexSum {
+		return nil
+	}
+	p := filepath.Join(a.Dir, cid+".json")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		return err
+	}
+	m.CIDs[cid] = hexSum
+	return nil
+}
+
+// typeTag reads just enough of an archived block to dispatch on its
+// types.Type* constant, the same trick storage.ImportBoard uses to decide
+// which concrete type to unmarshal into.
+type typeTag struct {
+	Type string `json:"type"`
+}
+
+// Verify re-reads every file manifest.json records, confirming each still
+// deserializes, still passes its matching bbslog.Verify* signature check,
+// and still hashes to the sha256 recorded when it was archived. A
+// long-lived archive directory can otherwise silently rot (a bad sector, an
+// interrupted write, manual tampering) without anything noticing until the
+// file is actually needed; Verify exists to catch that ahead of time.
+func (a *Archiver) Verify(ctx context.Context) error {
+	m, err := a.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	for cid, wantHex := range m.CIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b, err := os.ReadFile(filepath.Join(a.Dir, cid+".json"))
+		if err != nil {
+			return fmt.Errorf("archive: verify %s: %w", cid, err)
+		}
+		if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != wantHex {
+			return fmt.Errorf("archive: verify %s: content hash no longer matches manifest", cid)
+		}
+
+		var tag typeTag
+		if err := json.Unmarshal(b, &tag); err != nil {
+			return fmt.Errorf("archive: verify %s: decode type: %w", cid, err)
+		}
+		if err := verifyArchivedBlock(cid, tag.Type, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyArchivedBlock(cid, typ string, b []byte) error {
+	switch typ {
+	case types.TypeBoardMeta:
+		var bm types.BoardMeta
+		if err := json.Unmarshal(b, &bm); err != nil {
+			return fmt.Errorf("archive: verify %s: decode board meta: %w", cid, err)
+		}
+		if !bbslog.VerifyBoardMeta(&bm) {
+			return fmt.Errorf("archive: verify %s: board meta has an invalid signature", cid)
+		}
+	case types.TypeBoardLogEntry:
+		var e types.BoardLogEntry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return fmt.Errorf("archive: verify %s: decode board log entry: %w", cid, err)
+		}
+		if !bbslog.VerifyBoardLogEntry(&e) {
+			return fmt.Errorf("archive: verify %s: board log entry has an invalid signature", cid)
+		}
+	case types.TypeThreadMeta:
+		var tm types.ThreadMeta
+		if err := json.Unmarshal(b, &tm); err != nil {
+			return fmt.Errorf("archive: verify %s: decode thread meta: %w", cid, err)
+		}
+		if !bbslog.VerifyThreadMeta(&tm) {
+			return fmt.Errorf("archive: verify %s: thread meta has an invalid signature", cid)
+		}
+	case types.TypePost:
+		var p types.Post
+		if err := json.Unmarshal(b, &p); err != nil {
+			return fmt.Errorf("archive: verify %s: decode post: %w", cid, err)
+		}
+		if !bbslog.VerifyPost(&p) {
+			return fmt.Errorf("archive: verify %s: post has an invalid signature", cid)
+		}
+	default:
+		return fmt.Errorf("archive: verify %s: unknown type %q", cid, typ)
+	}
+	return nil
+}
+
+// ExportCAR walks the same board->boardLog->thread/post structure
+// SyncOnce does and writes it to out as a bbs/storage/car archive (the
+// same CAR-style format Storage.ExportBoard produces) instead of loose
+// <cid>.json files: a header naming roots, followed by one (cid, block)
+// frame per verified object. Unlike ExportBoard's putJSONBlock, each
+// block's bytes here are storage.CanonicalBytes(v) — the exact encoding
+// Storage hashes to assign v's CID — so a receiver can re-verify every CID
+// by hashing the block it was shipped alongside. Unlike SyncOnce, ExportCAR
+// always does a full walk; it has no manifest of its own to resume from.
+func (a *Archiver) ExportCAR(ctx context.Context, out io.Writer, roots []string) error {
+	cw, err := car.NewWriter(out, roots)
+	if err != nil {
+		return fmt.Errorf("archive: export car: %w", err)
+	}
+
+	var walkErr error
+	if err := a.walk(ctx, func(cid string, v any) {
+		if walkErr != nil {
+			return
+		}
+		b, err := storage.CanonicalBytes(v)
+		if err != nil {
+			walkErr = fmt.Errorf("archive: export car: canonicalize %s: %w", cid, err)
+			return
+		}
+		if _, err := cw.PutBlock(cid, b); err != nil {
+			walkErr = fmt.Errorf("archive: export car: write block %s: %w", cid, err)
+		}
+	}); err != nil {
+		return err
+	}
+	if walkErr != nil {
+		return walkErr
+	}
+	return cw.Flush()
+}
+
+// walk visits every verified object reachable from each board in a.Boards:
+// the board's BoardMeta, its BoardLogEntry chain, and every ThreadMeta/Post
+// the chain references. Unlike SyncOnce's traversal, it always walks the
+// full chain from scratch — ExportCAR, its only caller, has no persisted
+// state to resume from. A load or verify failure on one board, log entry,
+// or referenced object is skipped rather than aborting the walk.
+func (a *Archiver) walk(ctx context.Context, visit func(cid string, v any)) error {
+	if err := a.Boards.Load(); err != nil {
 		return err
 	}
 
@@ -34,7 +382,7 @@ func (a *Archiver) SyncOnce(ctx context.Context) error {
 		if !bbslog.VerifyBoardMeta(bm) {
 			continue
 		}
-		_ = a.saveJSON(ref.BoardMetaCID, bm)
+		visit(ref.BoardMetaCID, bm)
 
 		loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
 			return a.Storage.LoadBoardLogEntry(ctx, cid)
@@ -47,20 +395,20 @@ func (a *Archiver) SyncOnce(ctx context.Context) error {
 		}
 
 		for _, item := range boardLog {
-			_ = a.saveJSON(item.CID, item.Value)
+			visit(item.CID, item.Value)
 			if item.Value != nil {
-				a.maybeArchiveThreadMeta(ctx, item.Value.ThreadID)
-				a.maybeArchivePost(ctx, item.Value.PostCID)
-				a.maybeArchivePost(ctx, item.Value.OldPostCID)
-				a.maybeArchivePost(ctx, item.Value.NewPostCID)
-				a.maybeArchivePost(ctx, item.Value.TargetPostCID)
+				a.maybeVisitThreadMeta(ctx, item.Value.ThreadID, visit)
+				a.maybeVisitPost(ctx, item.Value.PostCID, visit)
+				a.maybeVisitPost(ctx, item.Value.OldPostCID, visit)
+				a.maybeVisitPost(ctx, item.Value.NewPostCID, visit)
+				a.maybeVisitPost(ctx, item.Value.TargetPostCID, visit)
 			}
 		}
 	}
 	return nil
 }
 
-func (a *Archiver) maybeArchivePost(ctx context.Context, cid *string) {
+func (a *Archiver) maybeVisitPost(ctx context.Context, cid *string, visit func(cid string, v any)) {
 	if cid == nil || *cid == "" {
 		return
 	}
@@ -71,10 +419,10 @@ func (a *Archiver) maybeArchivePost(ctx context.Context, cid *string) {
 	if !bbslog.VerifyPost(p) {
 		return
 	}
-	_ = a.saveJSON(*cid, p)
+	visit(*cid, p)
 }
 
-func (a *Archiver) maybeArchiveThreadMeta(ctx context.Context, cid string) {
+func (a *Archiver) maybeVisitThreadMeta(ctx context.Context, cid string, visit func(cid string, v any)) {
 	if cid == "" {
 		return
 	}
@@ -85,14 +433,37 @@ func (a *Archiver) maybeArchiveThreadMeta(ctx context.Context, cid string) {
 	if !bbslog.VerifyThreadMeta(tm) {
 		return
 	}
-	_ = a.saveJSON(cid, tm)
+	visit(cid, tm)
 }
 
-func (a *Archiver) saveJSON(cid string, v any) error {
-	b, err := json.MarshalIndent(v, "", "  ")
+// ArchivedCIDs lists every CID an Archiver with this Dir has written (one
+// <cid>.json file per saveJSONIfChanged call), for callers that want to
+// walk archived content without duplicating that naming scheme — currently
+// only bbs/republisher, which re-pins/re-provides archived objects so they
+// stay reachable even once nothing else references them. manifest.json
+// itself is not a CID and is skipped. A dir that doesn't exist yet (no
+// SyncOnce has run) returns an empty slice rather than an error, matching
+// SyncOnce's own "create it on demand" treatment of Dir.
+func ArchivedCIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return err
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	p := filepath.Join(a.Dir, cid+".json")
-	return os.WriteFile(p, b, 0o644)
+	cids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if name == manifestFileName {
+			continue
+		}
+		if ext := filepath.Ext(name); ext == ".json" {
+			cids = append(cids, strings.TrimSuffix(name, ext))
+		}
+	}
+	return cids, nil
 }
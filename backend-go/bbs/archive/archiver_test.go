@@ -0,0 +1,427 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/storage/car"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// newFakeContentAddressedFlex mirrors bbs/storage's fixture of the same
+// name: a fakeFlexIPFS-protocol server backed by an in-memory map keyed by
+// sha256(value), so each distinct object saved through it lands at its own
+// CID the way a real Flexible-IPFS DHT would.
+func newFakeContentAddressedFlex(t *testing.T) *storage.Storage {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		byCID = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_ = json.NewEncoder(w).Encode("peer1")
+		case "/api/v0/dht/putvaluewithattr":
+			value := r.URL.Query().Get("value")
+			sum := sha256.Sum256([]byte(value))
+			cid := "baf_" + hex.EncodeToString(sum[:8])
+			mu.Lock()
+			byCID[cid] = value
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": cid})
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			mu.Lock()
+			value, ok := byCID[cid]
+			mu.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(value)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return storage.New(flexipfs.New(srv.URL + "/api/v0"))
+}
+
+// seedArchiverFixture builds a fully signed board (BoardMeta, one
+// createThread BoardLogEntry, its ThreadMeta, and its root Post) into an
+// Archiver's Storage and registers it in its BoardsStore, the setup both
+// SyncOnce and ExportCAR need.
+func seedArchiverFixture(t *testing.T) (a *Archiver, boardMetaCID string) {
+	t.Helper()
+	ctx := context.Background()
+	st := newFakeContentAddressedFlex(t)
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	post := &types.Post{
+		Version:      types.Version1,
+		Type:         types.TypePost,
+		ThreadID:     "placeholder",
+		AuthorPubKey: "author1",
+		DisplayName:  "Author",
+		Body:         types.PostBody{Format: "plain", Content: "hello archive"},
+		CreatedAt:    "2025-01-01T00:00:00Z",
+	}
+	if err := signature.SignPost(priv, post); err != nil {
+		t.Fatalf("SignPost: %v", err)
+	}
+	postCID, err := st.SavePost(ctx, "bbs.archive-test", post)
+	if err != nil {
+		t.Fatalf("SavePost: %v", err)
+	}
+
+	thread := &types.ThreadMeta{
+		Version:     types.Version1,
+		Type:        types.TypeThreadMeta,
+		BoardID:     "bbs.archive-test",
+		Title:       "Hello",
+		RootPostCID: postCID,
+		CreatedAt:   "2025-01-01T00:00:00Z",
+		CreatedBy:   "author1",
+		Meta:        map[string]any{},
+	}
+	if err := signature.SignThreadMeta(priv, thread); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+	threadCID, err := st.SaveThreadMeta(ctx, thread)
+	if err != nil {
+		t.Fatalf("SaveThreadMeta: %v", err)
+	}
+
+	entry := &types.BoardLogEntry{
+		Version:      types.Version1,
+		Type:         types.TypeBoardLogEntry,
+		BoardID:      "bbs.archive-test",
+		Op:           types.OpCreateThread,
+		ThreadID:     threadCID,
+		PostCID:      &postCID,
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: "author1",
+	}
+	if err := signature.SignBoardLogEntry(priv, entry); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+	logCID, err := st.SaveBoardLogEntry(ctx, entry)
+	if err != nil {
+		t.Fatalf("SaveBoardLogEntry: %v", err)
+	}
+
+	bm := &types.BoardMeta{
+		Version:    types.Version1,
+		Type:       types.TypeBoardMeta,
+		BoardID:    "bbs.archive-test",
+		Title:      "Archive Test",
+		CreatedAt:  "2025-01-01T00:00:00Z",
+		CreatedBy:  "author1",
+		LogHeadCID: &logCID,
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+	bmCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+
+	boardsPath := filepath.Join(t.TempDir(), "boards.json")
+	writeBoardsFile(t, boardsPath, "bbs.archive-test", bmCID)
+
+	return &Archiver{
+		Storage: st,
+		Boards:  config.NewBoardsStore(boardsPath),
+		Dir:     filepath.Join(t.TempDir(), "archive"),
+	}, bmCID
+}
+
+// writeBoardsFile writes a single-board boards.json at path, overwriting
+// whatever was there before (used to point Boards.Load at a board's
+// updated BoardMetaCID between a test's two SyncOnce calls).
+func writeBoardsFile(t *testing.T, path, boardID, boardMetaCID string) {
+	t.Helper()
+	f := config.BoardsFile{Boards: []config.BoardRef{{BoardID: boardID, BoardMetaCID: boardMetaCID}}}
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("marshal boards file: %v", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("write boards file: %v", err)
+	}
+}
+
+func TestExportCAR_RoundTrip(t *testing.T) {
+	a, bmCID := seedArchiverFixture(t)
+
+	var buf bytes.Buffer
+	if err := a.ExportCAR(context.Background(), &buf, []string{bmCID}); err != nil {
+		t.Fatalf("ExportCAR: %v", err)
+	}
+
+	cr, err := car.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("car.NewReader: %v", err)
+	}
+	if len(cr.Roots) != 1 || cr.Roots[0] != bmCID {
+		t.Fatalf("roots = %v, want [%s]", cr.Roots, bmCID)
+	}
+
+	blocks := map[string][]byte{}
+	for {
+		cid, data, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("car Next: %v", err)
+		}
+		blocks[cid] = data
+	}
+
+	// SavePost/SaveThreadMeta/SaveBoardLogEntry/SaveBoardMeta each assign
+	// a CID via the fake server's sha256(value) keying above, so a block's
+	// declared CID round-trips back to a hash of its own bytes only if
+	// ExportCAR wrote storage.CanonicalBytes(v) rather than some other
+	// encoding (e.g. json.MarshalIndent, which would produce different
+	// bytes and therefore a different hash).
+	foundThread := false
+	for cid, data := range blocks {
+		sum := sha256.Sum256(data)
+		want := "baf_" + hex.EncodeToString(sum[:8])
+		if cid != want {
+			t.Fatalf("block %s hashes to %s, want itself", cid, want)
+		}
+		var tag struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &tag); err != nil {
+			t.Fatalf("decode block %s: %v", cid, err)
+		}
+		if tag.Type == types.TypeThreadMeta {
+			foundThread = true
+		}
+	}
+	if !foundThread {
+		t.Fatal("archive did not contain a thread meta block")
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("len(blocks) = %d, want 4 (board meta, log entry, thread meta, post)", len(blocks))
+	}
+}
+
+func TestSyncOnce_SkipsBoardWithUnchangedLogHead(t *testing.T) {
+	a, _ := seedArchiverFixture(t)
+	ctx := context.Background()
+
+	if err := a.SyncOnce(ctx); err != nil {
+		t.Fatalf("first SyncOnce: %v", err)
+	}
+	cids, err := ArchivedCIDs(a.Dir)
+	if err != nil {
+		t.Fatalf("ArchivedCIDs: %v", err)
+	}
+	if len(cids) != 4 {
+		t.Fatalf("len(cids) after first sync = %d, want 4", len(cids))
+	}
+
+	m, err := a.loadManifest()
+	if err != nil {
+		t.Fatalf("loadManifest: %v", err)
+	}
+	firstSync := m.Boards["bbs.archive-test"].LastSyncedAt
+
+	if err := a.SyncOnce(ctx); err != nil {
+		t.Fatalf("second SyncOnce: %v", err)
+	}
+	m2, err := a.loadManifest()
+	if err != nil {
+		t.Fatalf("loadManifest after second sync: %v", err)
+	}
+	if !m2.Boards["bbs.archive-test"].LastSyncedAt.Equal(firstSync) {
+		t.Fatalf("second SyncOnce re-synced an unchanged board: LastSyncedAt changed from %v to %v",
+			firstSync, m2.Boards["bbs.archive-test"].LastSyncedAt)
+	}
+}
+
+// appendBoardLogEntry builds and saves one more post + thread + board log
+// entry onto boardID, chained behind prevLogCID, so
+// TestSyncOnce_ArchivesOnlyNewLogEntries can grow a board's log across two
+// SyncOnce calls without repeating the same three Sign+Save calls twice.
+func appendBoardLogEntry(t *testing.T, ctx context.Context, st *storage.Storage, priv string, boardID string, prevLogCID *string, label, createdAt string) (logCID string) {
+	t.Helper()
+
+	post := &types.Post{
+		Version:      types.Version1,
+		Type:         types.TypePost,
+		ThreadID:     "placeholder",
+		AuthorPubKey: "author1",
+		DisplayName:  "Author",
+		Body:         types.PostBody{Format: "plain", Content: label},
+		CreatedAt:    createdAt,
+	}
+	if err := signature.SignPost(priv, post); err != nil {
+		t.Fatalf("SignPost: %v", err)
+	}
+	postCID, err := st.SavePost(ctx, boardID, post)
+	if err != nil {
+		t.Fatalf("SavePost: %v", err)
+	}
+
+	thread := &types.ThreadMeta{
+		Version:     types.Version1,
+		Type:        types.TypeThreadMeta,
+		BoardID:     boardID,
+		Title:       label,
+		RootPostCID: postCID,
+		CreatedAt:   createdAt,
+		CreatedBy:   "author1",
+		Meta:        map[string]any{},
+	}
+	if err := signature.SignThreadMeta(priv, thread); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+	threadCID, err := st.SaveThreadMeta(ctx, thread)
+	if err != nil {
+		t.Fatalf("SaveThreadMeta: %v", err)
+	}
+
+	entry := &types.BoardLogEntry{
+		Version:      types.Version1,
+		Type:         types.TypeBoardLogEntry,
+		BoardID:      boardID,
+		Op:           types.OpCreateThread,
+		ThreadID:     threadCID,
+		PostCID:      &postCID,
+		CreatedAt:    createdAt,
+		AuthorPubKey: "author1",
+		PrevLogCID:   prevLogCID,
+	}
+	if err := signature.SignBoardLogEntry(priv, entry); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+	logCID, err = st.SaveBoardLogEntry(ctx, entry)
+	if err != nil {
+		t.Fatalf("SaveBoardLogEntry: %v", err)
+	}
+	return logCID
+}
+
+func saveBoardMeta(t *testing.T, ctx context.Context, st *storage.Storage, priv string, boardID, logHeadCID string) string {
+	t.Helper()
+	bm := &types.BoardMeta{
+		Version:    types.Version1,
+		Type:       types.TypeBoardMeta,
+		BoardID:    boardID,
+		Title:      "Incremental Test",
+		CreatedAt:  "2025-01-01T00:00:00Z",
+		CreatedBy:  "author1",
+		LogHeadCID: &logHeadCID,
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+	bmCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+	return bmCID
+}
+
+func TestSyncOnce_ArchivesOnlyNewLogEntries(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeContentAddressedFlex(t)
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	boardID := "bbs.incremental-test"
+
+	entry1CID := appendBoardLogEntry(t, ctx, st, priv, boardID, nil, "first", "2025-01-01T00:00:00Z")
+	bm1CID := saveBoardMeta(t, ctx, st, priv, boardID, entry1CID)
+
+	boardsPath := filepath.Join(t.TempDir(), "boards.json")
+	writeBoardsFile(t, boardsPath, boardID, bm1CID)
+
+	a := &Archiver{Storage: st, Boards: config.NewBoardsStore(boardsPath), Dir: filepath.Join(t.TempDir(), "archive")}
+	if err := a.SyncOnce(ctx); err != nil {
+		t.Fatalf("first SyncOnce: %v", err)
+	}
+	cids, err := ArchivedCIDs(a.Dir)
+	if err != nil {
+		t.Fatalf("ArchivedCIDs: %v", err)
+	}
+	if len(cids) != 4 {
+		t.Fatalf("len(cids) after first sync = %d, want 4 (board meta, log entry, thread meta, post)", len(cids))
+	}
+
+	entry2CID := appendBoardLogEntry(t, ctx, st, priv, boardID, &entry1CID, "second", "2025-01-02T00:00:00Z")
+	bm2CID := saveBoardMeta(t, ctx, st, priv, boardID, entry2CID)
+	writeBoardsFile(t, boardsPath, boardID, bm2CID)
+
+	if err := a.SyncOnce(ctx); err != nil {
+		t.Fatalf("second SyncOnce: %v", err)
+	}
+	cids2, err := ArchivedCIDs(a.Dir)
+	if err != nil {
+		t.Fatalf("ArchivedCIDs after second sync: %v", err)
+	}
+	// bm1, entry1, thread1, post1 from the first sync, plus bm2, entry2,
+	// thread2, post2 newly archived by the second — the second walk stops
+	// on reaching entry1's CID, so nothing from the first sync is
+	// duplicated or lost.
+	if len(cids2) != 8 {
+		t.Fatalf("len(cids2) after second sync = %d, want 8", len(cids2))
+	}
+}
+
+func TestVerify_DetectsTamperedFile(t *testing.T) {
+	a, _ := seedArchiverFixture(t)
+	ctx := context.Background()
+	if err := a.SyncOnce(ctx); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	if err := a.Verify(ctx); err != nil {
+		t.Fatalf("Verify on an untouched archive: %v", err)
+	}
+
+	cids, err := ArchivedCIDs(a.Dir)
+	if err != nil {
+		t.Fatalf("ArchivedCIDs: %v", err)
+	}
+	if len(cids) == 0 {
+		t.Fatal("no archived CIDs to tamper with")
+	}
+	p := filepath.Join(a.Dir, cids[0]+".json")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatalf("read %s: %v", p, err)
+	}
+	if err := os.WriteFile(p, append(b, '\n'), 0o644); err != nil {
+		t.Fatalf("tamper %s: %v", p, err)
+	}
+
+	if err := a.Verify(ctx); err == nil {
+		t.Fatal("expected Verify to detect a tampered archived file")
+	}
+}
@@ -0,0 +1,161 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// errArchivedObjectTampered is loadArchived's error when a file's content
+// no longer hashes to what manifest.json recorded for it at write time.
+var errArchivedObjectTampered = errors.New("archive: object failed its integrity check")
+
+// Handler serves a directory an Archiver has synced to (see SyncOnce)
+// over HTTP, read-only, for external mirrors that want a verifiable
+// snapshot without their own FlexIPFS access.
+type Handler struct {
+	Archiver *Archiver
+}
+
+// Routes returns the archive's HTTP routes, to be mounted under
+// /archive/v1/ alongside a node's other handlers.
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /archive/v1/objects/{cid}", h.serveObject)
+	mux.HandleFunc("GET /archive/v1/boards/{cid}/log", h.serveBoardLog)
+	mux.HandleFunc("GET /archive/v1/manifest", h.serveManifest)
+	return mux
+}
+
+// serveObject serves the raw bytes previously written to <cid>.json,
+// refusing to serve anything whose content no longer hashes to what
+// manifest.json recorded for it when it was archived -- the same
+// tamper check Verify performs, done inline so a stale or tampered
+// read never goes out over the wire. The CID is itself a content
+// address, so it doubles as a strong ETag; http.ServeContent answers
+// If-None-Match and Range requests against it without this handler
+// having to implement either itself.
+func (h *Handler) serveObject(w http.ResponseWriter, r *http.Request) {
+	cid := r.PathValue("cid")
+	m, err := h.Archiver.loadManifest()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "load manifest: "+err.Error())
+		return
+	}
+	wantHex, ok := m.CIDs[cid]
+	if !ok {
+		writeError(w, http.StatusNotFound, "object not found")
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(h.Archiver.Dir, cid+".json"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "object not found")
+		return
+	}
+	if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != wantHex {
+		writeError(w, http.StatusInternalServerError, "archived object failed its integrity check")
+		return
+	}
+
+	w.Header().Set("ETag", `"sha256:`+cid+`"`)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	http.ServeContent(w, r, cid+".json", time.Time{}, bytes.NewReader(b))
+}
+
+// serveBoardLog streams the verified log chain for the board whose
+// BoardMeta is stored under {cid} as newline-delimited JSON, oldest
+// entry first, reading entirely from the archive directory rather than
+// from Archiver.Storage -- it's meant to work against a mirror that has
+// no FlexIPFS access of its own.
+func (h *Handler) serveBoardLog(w http.ResponseWriter, r *http.Request) {
+	cid := r.PathValue("cid")
+	var bm types.BoardMeta
+	if err := h.loadArchived(cid, &bm); err != nil {
+		writeError(w, http.StatusNotFound, "board meta not found")
+		return
+	}
+	if !bbslog.VerifyBoardMeta(&bm) {
+		writeError(w, http.StatusInternalServerError, "archived board meta has an invalid signature")
+		return
+	}
+
+	loadLogEntry := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
+		var e types.BoardLogEntry
+		if err := h.loadArchived(cid, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+	entries, err := bbslog.FetchChain(r.Context(), bm.LogHeadCID, loadLogEntry, func(e *types.BoardLogEntry) *string {
+		return e.PrevLogCID
+	}, bbslog.VerifyBoardLogEntry, 50_000)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "load board log: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	for _, item := range entries {
+		if item.Value == nil {
+			continue
+		}
+		if err := enc.Encode(item.Value); err != nil {
+			return
+		}
+	}
+}
+
+// serveManifest returns the resumable-sync manifest SyncOnce maintains,
+// so a mirror can tell what's already been synced without re-walking
+// the archive directory itself.
+func (h *Handler) serveManifest(w http.ResponseWriter, r *http.Request) {
+	m, err := h.Archiver.loadManifest()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "load manifest: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// loadArchived reads <a.Archiver.Dir>/<cid>.json and decodes it into out,
+// verifying its content still hashes to what manifest.json recorded for
+// it, the same tamper check serveObject performs.
+func (h *Handler) loadArchived(cid string, out any) error {
+	m, err := h.Archiver.loadManifest()
+	if err != nil {
+		return err
+	}
+	wantHex, ok := m.CIDs[cid]
+	if !ok {
+		return os.ErrNotExist
+	}
+	b, err := os.ReadFile(filepath.Join(h.Archiver.Dir, cid+".json"))
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != wantHex {
+		return errArchivedObjectTampered
+	}
+	return json.Unmarshal(b, out)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": message})
+}
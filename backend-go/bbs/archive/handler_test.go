@@ -0,0 +1,156 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestHandler_ServeObject_ConditionalAndRange(t *testing.T) {
+	a, bmCID := seedArchiverFixture(t)
+	if err := a.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	h := &Handler{Archiver: a}
+	srv := httptest.NewServer(h.Routes())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/archive/v1/objects/" + bmCID)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag != `"sha256:`+bmCID+`"` {
+		t.Fatalf("ETag = %q, want %q", etag, `"sha256:`+bmCID+`"`)
+	}
+	var bm types.BoardMeta
+	if err := json.NewDecoder(resp.Body).Decode(&bm); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if bm.BoardID != "bbs.archive-test" {
+		t.Fatalf("BoardID = %q", bm.BoardID)
+	}
+
+	req, _ := http.NewRequest("GET", srv.URL+"/archive/v1/objects/"+bmCID, nil)
+	req.Header.Set("If-None-Match", etag)
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET conditional: %v", err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusNotModified {
+		t.Fatalf("conditional status = %d, want 304", resp2.StatusCode)
+	}
+
+	reqRange, _ := http.NewRequest("GET", srv.URL+"/archive/v1/objects/"+bmCID, nil)
+	reqRange.Header.Set("Range", "bytes=0-3")
+	respRange, err := http.DefaultClient.Do(reqRange)
+	if err != nil {
+		t.Fatalf("GET range: %v", err)
+	}
+	defer respRange.Body.Close()
+	if respRange.StatusCode != http.StatusPartialContent {
+		t.Fatalf("range status = %d, want 206", respRange.StatusCode)
+	}
+
+	respMissing, err := http.Get(srv.URL + "/archive/v1/objects/no-such-cid")
+	if err != nil {
+		t.Fatalf("GET missing: %v", err)
+	}
+	respMissing.Body.Close()
+	if respMissing.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing status = %d, want 404", respMissing.StatusCode)
+	}
+}
+
+func TestHandler_ServeObject_RefusesTamperedContent(t *testing.T) {
+	a, bmCID := seedArchiverFixture(t)
+	if err := a.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(a.Dir, bmCID+".json"), []byte(`{"tampered":true}`), 0o644); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	h := &Handler{Archiver: a}
+	srv := httptest.NewServer(h.Routes())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/archive/v1/objects/" + bmCID)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+}
+
+func TestHandler_ServeBoardLog_StreamsNDJSON(t *testing.T) {
+	a, bmCID := seedArchiverFixture(t)
+	if err := a.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	h := &Handler{Archiver: a}
+	srv := httptest.NewServer(h.Routes())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/archive/v1/boards/" + bmCID + "/log")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var entries []types.BoardLogEntry
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		var e types.BoardLogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("unmarshal line: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 1 || entries[0].Op != types.OpCreateThread {
+		t.Fatalf("entries = %+v, want a single createThread entry", entries)
+	}
+}
+
+func TestHandler_ServeManifest(t *testing.T) {
+	a, _ := seedArchiverFixture(t)
+	if err := a.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	h := &Handler{Archiver: a}
+	srv := httptest.NewServer(h.Routes())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/archive/v1/manifest")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var m archiveManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(m.Boards) != 1 || len(m.CIDs) == 0 {
+		t.Fatalf("manifest = %+v, want one board and some CIDs", m)
+	}
+}
@@ -0,0 +1,95 @@
+// Package attachments is a content-addressed blob store for Post
+// attachments. Rather than one flexipfs PutValue (or one file) per blob,
+// it packs blobs into append-only "volume" files the way Facebook's
+// Haystack packs photos — chosen because a BBS's attachments are
+// typically many small images/files where per-blob filesystem or network
+// overhead would dominate at scale.
+package attachments
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// CIDv1 (raw codec, sha2-256 multihash) is the only CID shape this package
+// produces or accepts. codecRaw means the blob's bytes are stored
+// verbatim rather than wrapped in a dag-pb/dag-cbor node; see
+// https://github.com/multiformats/multicodec.
+const (
+	codecRaw           = 0x55
+	multihashSHA256    = 0x12
+	sha256DigestLength = 32
+)
+
+// cidBase32 is the unpadded lowercase base32 alphabet CIDv1 strings use
+// under the "b" multibase prefix: https://github.com/multiformats/multibase.
+// Unlike the base58btc did:key encodes (bbs/did), base32 is
+// case-insensitive, which keeps a CID safe to drop into a URL path (see
+// the /blob/{cid} handler in bbs/api) without percent-escaping.
+var cidBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrMalformedCID is returned by ParseCIDv1Raw for a string that isn't a
+// well-formed CIDv1/raw/sha2-256.
+var ErrMalformedCID = errors.New("attachments: malformed CID")
+
+// EncodeCIDv1Raw builds the CIDv1 string for a digest already computed by
+// the caller. Store.Put hashes a blob's bytes as they stream in rather
+// than buffering the whole blob first, so the digest (and therefore the
+// CID) is only known once the last byte has been written.
+func EncodeCIDv1Raw(digest [sha256DigestLength]byte) string {
+	buf := make([]byte, 0, 8+sha256DigestLength)
+	buf = appendUvarint(buf, 1) // CID version
+	buf = appendUvarint(buf, codecRaw)
+	buf = appendUvarint(buf, multihashSHA256)
+	buf = appendUvarint(buf, sha256DigestLength)
+	buf = append(buf, digest[:]...)
+	return "b" + cidBase32.EncodeToString(buf)
+}
+
+// ParseCIDv1Raw extracts the sha2-256 digest from a CID produced by
+// EncodeCIDv1Raw, rejecting any other CID version, codec, or multihash
+// function (this store never produces or stores those, so it need not
+// read them).
+func ParseCIDv1Raw(cid string) (digest [sha256DigestLength]byte, err error) {
+	if len(cid) < 2 || cid[0] != 'b' {
+		return digest, fmt.Errorf("%w: %q: not a base32 ('b'-prefixed) CID", ErrMalformedCID, cid)
+	}
+	buf, err := cidBase32.DecodeString(cid[1:])
+	if err != nil {
+		return digest, fmt.Errorf("%w: %q: %v", ErrMalformedCID, cid, err)
+	}
+	version, buf, err := takeUvarint(buf)
+	if err != nil || version != 1 {
+		return digest, fmt.Errorf("%w: %q: unsupported CID version", ErrMalformedCID, cid)
+	}
+	codec, buf, err := takeUvarint(buf)
+	if err != nil || codec != codecRaw {
+		return digest, fmt.Errorf("%w: %q: unsupported codec", ErrMalformedCID, cid)
+	}
+	fn, buf, err := takeUvarint(buf)
+	if err != nil || fn != multihashSHA256 {
+		return digest, fmt.Errorf("%w: %q: unsupported multihash function", ErrMalformedCID, cid)
+	}
+	size, buf, err := takeUvarint(buf)
+	if err != nil || size != sha256DigestLength || len(buf) != sha256DigestLength {
+		return digest, fmt.Errorf("%w: %q: unsupported digest size", ErrMalformedCID, cid)
+	}
+	copy(digest[:], buf)
+	return digest, nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func takeUvarint(buf []byte) (v uint64, rest []byte, err error) {
+	v, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, nil, errors.New("attachments: truncated varint")
+	}
+	return v, buf[n:], nil
+}
@@ -0,0 +1,63 @@
+package attachments
+
+import "container/list"
+
+// entry locates one stored blob within its volume, plus the metadata
+// Get/Tombstone need without reading the volume itself.
+type entry struct {
+	volumeID uint32
+	offset   int64
+	length   int64
+	mime     string
+	tomb     bool
+}
+
+// hotCache is a small bounded cache of the hottest index entries, fronting
+// Store's full in-memory index so a burst of repeated Gets for the same
+// few attachments (a popular thread's inline images) doesn't re-walk the
+// index map on every request.
+type hotCache struct {
+	max     int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type hotCacheItem struct {
+	cid   string
+	entry entry
+}
+
+func newHotCache(max int) *hotCache {
+	return &hotCache{max: max, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *hotCache) get(cid string) (entry, bool) {
+	el, ok := c.entries[cid]
+	if !ok {
+		return entry{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*hotCacheItem).entry, true
+}
+
+func (c *hotCache) put(cid string, e entry) {
+	if el, ok := c.entries[cid]; ok {
+		el.Value.(*hotCacheItem).entry = e
+		c.order.MoveToFront(el)
+		return
+	}
+	el := c.order.PushFront(&hotCacheItem{cid: cid, entry: e})
+	c.entries[cid] = el
+	if c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*hotCacheItem).cid)
+	}
+}
+
+func (c *hotCache) remove(cid string) {
+	if el, ok := c.entries[cid]; ok {
+		c.order.Remove(el)
+		delete(c.entries, cid)
+	}
+}
@@ -0,0 +1,396 @@
+package attachments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// hotCacheSize bounds the number of index entries Store keeps in its
+// hotCache, independent of how many blobs the store as a whole holds.
+const hotCacheSize = 4096
+
+// CompactionThreshold is the tombstoned-byte fraction of a (non-active)
+// volume's live+tombstoned bytes at which Tombstone triggers Compact on
+// it. 0.5 bounds wasted disk to at most roughly the size of the live data
+// in that volume, without compacting so eagerly that a burst of deletes
+// rewrites the same volume repeatedly.
+const CompactionThreshold = 0.5
+
+var (
+	// ErrNotFound is returned by Get and Tombstone for a CID never Put, or
+	// already reported via Tombstone.
+	ErrNotFound = errors.New("attachments: blob not found")
+)
+
+// Meta describes a stored blob without its bytes.
+type Meta struct {
+	CID        string
+	Mime       string
+	Length     int64
+	Tombstoned bool
+}
+
+// Store is a content-addressed blob store packing blobs into append-only
+// volume files (see volume.go) rather than one file per blob. It is safe
+// for concurrent use.
+type Store struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	volumes map[uint32]*volume
+	active  uint32
+	index   map[string]entry
+	hot     *hotCache
+}
+
+// Open opens (creating if necessary) a Store rooted at dir, replaying
+// every volume's idx log to rebuild the in-memory index.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("attachments: create %s: %w", dir, err)
+	}
+	ids, err := existingVolumeIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		ids = []uint32{0}
+	}
+
+	s := &Store{
+		dir:     dir,
+		maxSize: defaultMaxVolumeSize,
+		volumes: make(map[uint32]*volume, len(ids)),
+		index:   make(map[string]entry),
+		hot:     newHotCache(hotCacheSize),
+	}
+	for _, id := range ids {
+		v, err := openVolume(dir, id)
+		if err != nil {
+			s.closeLocked()
+			return nil, err
+		}
+		s.volumes[id] = v
+		if id >= s.active {
+			s.active = id
+		}
+		recs, err := readIdxRecords(v.idx)
+		if err != nil {
+			s.closeLocked()
+			return nil, fmt.Errorf("attachments: replay volume %d: %w", id, err)
+		}
+		for _, rec := range recs {
+			e := entry{volumeID: id, offset: rec.Offset, length: rec.Length, mime: rec.Mime, tomb: rec.Tomb}
+			if prev, ok := s.index[rec.CID]; ok && !prev.tomb {
+				v := s.volumes[prev.volumeID]
+				v.liveBytes -= prev.length
+			}
+			s.index[rec.CID] = e
+			if e.tomb {
+				v.tombBytes += e.length
+			} else {
+				v.liveBytes += e.length
+			}
+		}
+	}
+	return s, nil
+}
+
+func existingVolumeIDs(dir string) ([]uint32, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("attachments: read %s: %w", dir, err)
+	}
+	var ids []uint32
+	for _, de := range entries {
+		name := de.Name()
+		if !strings.HasPrefix(name, "vol-") || !strings.HasSuffix(name, ".dat") {
+			continue
+		}
+		idStr := strings.TrimSuffix(strings.TrimPrefix(name, "vol-"), ".dat")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// Close releases every volume's open file handles.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *Store) closeLocked() error {
+	var first error
+	for _, v := range s.volumes {
+		if err := v.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Put streams r into the active volume, hashing it as bytes arrive (the
+// CID isn't known until the last byte has been read), and returns the
+// resulting CIDv1 (raw codec, sha2-256) plus the blob's size in bytes.
+//
+// If the hash matches a blob already stored under that CID, the bytes
+// just written are discarded (the volume is truncated back to where they
+// started) rather than kept as a second copy of identical content — Put
+// is idempotent by CID.
+func (s *Store) Put(ctx context.Context, r io.Reader, mime string) (cid string, size int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.volumes[s.active]
+	offset := v.size
+	h := sha256.New()
+	n, err := io.Copy(v.data, io.TeeReader(r, h))
+	if err != nil {
+		return "", 0, fmt.Errorf("attachments: write blob: %w", err)
+	}
+
+	var digest [sha256DigestLength]byte
+	copy(digest[:], h.Sum(nil))
+	cid = EncodeCIDv1Raw(digest)
+
+	if existing, ok := s.index[cid]; ok && !existing.tomb {
+		if err := v.data.Truncate(offset); err != nil {
+			return "", 0, fmt.Errorf("attachments: discard duplicate write: %w", err)
+		}
+		return cid, existing.length, nil
+	}
+
+	v.size += n
+
+	if err := v.appendIdxRecord(idxRecord{CID: cid, Mime: mime, Offset: offset, Length: n}); err != nil {
+		return "", 0, fmt.Errorf("attachments: write idx record: %w", err)
+	}
+	e := entry{volumeID: v.id, offset: offset, length: n, mime: mime}
+	s.index[cid] = e
+	s.hot.put(cid, e)
+	v.liveBytes += n
+
+	if v.size >= s.maxSize {
+		if err := s.rollOverLocked(); err != nil {
+			return "", 0, err
+		}
+	}
+	return cid, n, nil
+}
+
+func (s *Store) rollOverLocked() error {
+	nextID := s.active + 1
+	v, err := openVolume(s.dir, nextID)
+	if err != nil {
+		return err
+	}
+	s.volumes[nextID] = v
+	s.active = nextID
+	return nil
+}
+
+// Get returns a reader over the stored blob for cid, plus its metadata.
+// The returned ReadCloser need not be read to EOF, but must be Closed. It
+// also implements io.Seeker (it's backed by an io.SectionReader over the
+// volume file), so callers like the /blob/{cid} handler can hand it
+// straight to http.ServeContent for Range support.
+func (s *Store) Get(ctx context.Context, cid string) (io.ReadCloser, Meta, error) {
+	s.mu.Lock()
+	e, ok := s.lookupLocked(cid)
+	if !ok || e.tomb {
+		s.mu.Unlock()
+		return nil, Meta{}, ErrNotFound
+	}
+	v := s.volumes[e.volumeID]
+	s.mu.Unlock()
+
+	return blobReader{io.NewSectionReader(v.data, e.offset, e.length)},
+		Meta{CID: cid, Mime: e.mime, Length: e.length}, nil
+}
+
+// blobReader adapts an io.SectionReader (Read+Seek, but no Close) to
+// io.ReadCloser: closing it is a no-op since it reads from a volume file
+// Store keeps open for the store's whole lifetime.
+type blobReader struct {
+	*io.SectionReader
+}
+
+func (blobReader) Close() error { return nil }
+
+// Tombstone marks cid's blob deleted: Get and VerifyAttachment stop
+// serving it, and its bytes become eligible for reclaiming the next time
+// its volume is compacted. It does not reclaim space itself — see
+// Compact.
+func (s *Store) Tombstone(ctx context.Context, cid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookupLocked(cid)
+	if !ok || e.tomb {
+		return ErrNotFound
+	}
+	v := s.volumes[e.volumeID]
+	if err := v.appendIdxRecord(idxRecord{CID: cid, Mime: e.mime, Offset: e.offset, Length: e.length, Tomb: true}); err != nil {
+		return fmt.Errorf("attachments: write tombstone record: %w", err)
+	}
+	e.tomb = true
+	s.index[cid] = e
+	s.hot.remove(cid)
+	v.liveBytes -= e.length
+	v.tombBytes += e.length
+
+	if v.id != s.active && v.tombBytes > 0 {
+		frac := float64(v.tombBytes) / float64(v.tombBytes+v.liveBytes)
+		if frac >= CompactionThreshold {
+			return s.compactLocked(v.id)
+		}
+	}
+	return nil
+}
+
+// Compact rewrites volumeID's data and idx files, dropping every
+// tombstoned blob and reclaiming its space. It refuses to compact the
+// active volume, which is still being appended to.
+func (s *Store) Compact(ctx context.Context, volumeID uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked(volumeID)
+}
+
+func (s *Store) compactLocked(volumeID uint32) error {
+	if volumeID == s.active {
+		return fmt.Errorf("attachments: cannot compact the active volume %d", volumeID)
+	}
+	v, ok := s.volumes[volumeID]
+	if !ok {
+		return fmt.Errorf("attachments: unknown volume %d", volumeID)
+	}
+
+	tmpData, err := os.OpenFile(volumeDataPath(s.dir, volumeID)+".compact", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("attachments: create compaction data file: %w", err)
+	}
+	tmpIdx, err := os.OpenFile(volumeIndexPath(s.dir, volumeID)+".compact", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		tmpData.Close()
+		return fmt.Errorf("attachments: create compaction idx file: %w", err)
+	}
+
+	var offset int64
+	for cid, e := range s.index {
+		if e.volumeID != volumeID || e.tomb {
+			continue
+		}
+		n, err := io.Copy(tmpData, io.NewSectionReader(v.data, e.offset, e.length))
+		if err != nil {
+			tmpData.Close()
+			tmpIdx.Close()
+			return fmt.Errorf("attachments: copy live blob %s: %w", cid, err)
+		}
+		rec := idxRecord{CID: cid, Mime: e.mime, Offset: offset, Length: n}
+		b, err := json.Marshal(rec)
+		if err != nil {
+			tmpData.Close()
+			tmpIdx.Close()
+			return fmt.Errorf("attachments: encode compacted idx record: %w", err)
+		}
+		b = append(b, '\n')
+		if _, err := tmpIdx.Write(b); err != nil {
+			tmpData.Close()
+			tmpIdx.Close()
+			return fmt.Errorf("attachments: write compacted idx record: %w", err)
+		}
+		s.index[cid] = entry{volumeID: volumeID, offset: offset, length: n, mime: e.mime}
+		offset += n
+	}
+	tmpData.Close()
+	tmpIdx.Close()
+
+	if err := v.close(); err != nil {
+		return fmt.Errorf("attachments: close volume %d before compaction swap: %w", volumeID, err)
+	}
+	if err := os.Rename(volumeDataPath(s.dir, volumeID)+".compact", volumeDataPath(s.dir, volumeID)); err != nil {
+		return fmt.Errorf("attachments: swap compacted data file: %w", err)
+	}
+	if err := os.Rename(volumeIndexPath(s.dir, volumeID)+".compact", volumeIndexPath(s.dir, volumeID)); err != nil {
+		return fmt.Errorf("attachments: swap compacted idx file: %w", err)
+	}
+
+	newV, err := openVolume(s.dir, volumeID)
+	if err != nil {
+		return fmt.Errorf("attachments: reopen compacted volume %d: %w", volumeID, err)
+	}
+	newV.liveBytes = offset
+	s.volumes[volumeID] = newV
+	s.hot = newHotCache(hotCacheSize)
+	return nil
+}
+
+func (s *Store) lookupLocked(cid string) (entry, bool) {
+	if e, ok := s.hot.get(cid); ok {
+		return e, true
+	}
+	e, ok := s.index[cid]
+	if ok {
+		s.hot.put(cid, e)
+	}
+	return e, ok
+}
+
+// sniffSampleSize caps how much of a blob VerifyAttachment reads before
+// asking net/http to sniff its MIME type — DetectContentType itself never
+// inspects more than 512 bytes.
+const sniffSampleSize = 512
+
+// VerifyAttachment confirms cid names a non-tombstoned blob in the store
+// and that declaredMime (as asserted by whoever signed the Post
+// referencing it) agrees with the type net/http sniffs from its leading
+// bytes, so a post can't claim "image/png" for a blob that's actually
+// something else.
+func (s *Store) VerifyAttachment(ctx context.Context, cid, declaredMime string) error {
+	rc, _, err := s.Get(ctx, cid)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	sample := make([]byte, sniffSampleSize)
+	n, err := io.ReadFull(rc, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("attachments: read %s for sniffing: %w", cid, err)
+	}
+	sniffed := http.DetectContentType(sample[:n])
+	if mimeBaseType(sniffed) != mimeBaseType(declaredMime) {
+		return fmt.Errorf("attachments: declared mime %q does not match sniffed mime %q for %s", declaredMime, sniffed, cid)
+	}
+	return nil
+}
+
+// mimeBaseType strips parameters ("; charset=...") and trailing space, so
+// "text/plain; charset=utf-8" compares equal to plain "text/plain" — the
+// form net/http.DetectContentType returns for arbitrary bytes never
+// carries meaningful charset info of its own.
+func mimeBaseType(mime string) string {
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	return strings.TrimSpace(strings.ToLower(mime))
+}
+
@@ -0,0 +1,256 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestPut_ContentAddressedAndStable(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	cid1, size1, err := s.Put(ctx, bytes.NewReader([]byte("hello, board")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	cid2, size2, err := s.Put(ctx, bytes.NewReader([]byte("hello, board")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if cid1 != cid2 {
+		t.Fatalf("Put of identical content produced different CIDs: %q != %q", cid1, cid2)
+	}
+	if size1 != size2 || size1 != int64(len("hello, board")) {
+		t.Fatalf("size1=%d size2=%d, want both %d", size1, size2, len("hello, board"))
+	}
+
+	digest, err := ParseCIDv1Raw(cid1)
+	if err != nil {
+		t.Fatalf("ParseCIDv1Raw: %v", err)
+	}
+	if got := EncodeCIDv1Raw(digest); got != cid1 {
+		t.Fatalf("round trip EncodeCIDv1Raw(ParseCIDv1Raw(%q)) = %q", cid1, got)
+	}
+}
+
+func TestPut_DuplicateContentDoesNotGrowTheVolume(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	content := []byte("hello, board")
+	if _, _, err := s.Put(ctx, bytes.NewReader(content), "text/plain"); err != nil {
+		t.Fatalf("Put (1st): %v", err)
+	}
+	sizeAfterFirst := s.volumes[s.active].size
+	if _, _, err := s.Put(ctx, bytes.NewReader(content), "text/plain"); err != nil {
+		t.Fatalf("Put (2nd): %v", err)
+	}
+	if got := s.volumes[s.active].size; got != sizeAfterFirst {
+		t.Fatalf("active volume size after duplicate Put = %d, want unchanged %d", got, sizeAfterFirst)
+	}
+}
+
+func TestGet_RoundTrip(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	want := []byte("attachment bytes")
+	cid, size, err := s.Put(ctx, bytes.NewReader(want), "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if size != int64(len(want)) {
+		t.Fatalf("size = %d, want %d", size, len(want))
+	}
+
+	rc, meta, err := s.Get(ctx, cid)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Get content = %q, want %q", got, want)
+	}
+	if meta.Mime != "application/octet-stream" || meta.Length != int64(len(want)) {
+		t.Fatalf("Get meta = %+v", meta)
+	}
+}
+
+func TestGet_UnknownCIDReturnsErrNotFound(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if _, _, err := s.Get(context.Background(), "bunknown"); err != ErrNotFound {
+		t.Fatalf("Get on unknown CID: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTombstone_HidesBlobFromGet(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	cid, _, err := s.Put(ctx, bytes.NewReader([]byte("gone soon")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Tombstone(ctx, cid); err != nil {
+		t.Fatalf("Tombstone: %v", err)
+	}
+	if _, _, err := s.Get(ctx, cid); err != ErrNotFound {
+		t.Fatalf("Get after Tombstone: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Tombstone(ctx, cid); err != ErrNotFound {
+		t.Fatalf("second Tombstone: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_SurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	cid, _, err := s.Put(ctx, bytes.NewReader([]byte("persisted")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s2, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s2.Close()
+	rc, _, err := s2.Get(ctx, cid)
+	if err != nil {
+		t.Fatalf("Get after reopen: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read after reopen: %v", err)
+	}
+	if string(got) != "persisted" {
+		t.Fatalf("Get after reopen = %q", got)
+	}
+}
+
+func TestCompact_ReclaimsTombstonedSpaceAndPreservesLiveBlobs(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	keepCID, _, err := s.Put(ctx, bytes.NewReader([]byte("keep me")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put keep: %v", err)
+	}
+	dropCID, _, err := s.Put(ctx, bytes.NewReader([]byte("drop me")), "text/plain")
+	if err != nil {
+		t.Fatalf("Put drop: %v", err)
+	}
+
+	// Force the dropped blob's volume to roll over so Compact has a
+	// non-active volume to rewrite.
+	s.mu.Lock()
+	volID := s.index[dropCID].volumeID
+	s.mu.Unlock()
+	if err := s.rollOverForTest(); err != nil {
+		t.Fatalf("roll over: %v", err)
+	}
+
+	if err := s.Tombstone(ctx, dropCID); err != nil {
+		t.Fatalf("Tombstone: %v", err)
+	}
+	if err := s.Compact(ctx, volID); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	if _, _, err := s.Get(ctx, dropCID); err != ErrNotFound {
+		t.Fatalf("Get tombstoned blob after Compact: err = %v, want ErrNotFound", err)
+	}
+	rc, _, err := s.Get(ctx, keepCID)
+	if err != nil {
+		t.Fatalf("Get kept blob after Compact: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read kept blob: %v", err)
+	}
+	if string(got) != "keep me" {
+		t.Fatalf("kept blob content = %q", got)
+	}
+}
+
+// rollOverForTest exposes rollOverLocked so TestCompact_* can force a fresh
+// active volume without waiting for defaultMaxVolumeSize bytes of Puts.
+func (s *Store) rollOverForTest() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rollOverLocked()
+}
+
+func TestVerifyAttachment(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	png := []byte("\x89PNG\r\n\x1a\n" + "rest of a fake png")
+	cid, _, err := s.Put(ctx, bytes.NewReader(png), "image/png")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.VerifyAttachment(ctx, cid, "image/png"); err != nil {
+		t.Fatalf("VerifyAttachment with matching mime: %v", err)
+	}
+	if err := s.VerifyAttachment(ctx, cid, "application/pdf"); err == nil {
+		t.Fatalf("expected VerifyAttachment to reject a mismatched declared mime")
+	}
+	if err := s.VerifyAttachment(ctx, "bunknown", "image/png"); err != ErrNotFound {
+		t.Fatalf("VerifyAttachment on unknown CID: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParseCIDv1Raw_RejectsMalformed(t *testing.T) {
+	cases := []string{"", "not-a-cid", "bZZZZZ!!!", "zSomeBase58String"}
+	for _, c := range cases {
+		if _, err := ParseCIDv1Raw(c); err == nil {
+			t.Errorf("ParseCIDv1Raw(%q): expected error, got nil", c)
+		}
+	}
+}
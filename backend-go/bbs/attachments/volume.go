@@ -0,0 +1,116 @@
+package attachments
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultMaxVolumeSize bounds how large a single volume's data file may
+// grow before Put rolls over to a new one: 1 GiB, matching Haystack's own
+// rationale — small enough that a volume's index fits comfortably in
+// memory and a compaction pass finishes in bounded time, large enough
+// that the number of volumes (and open file descriptors) stays small even
+// after years of attachments.
+const defaultMaxVolumeSize = 1 << 30
+
+// idxRecord is one line of a volume's "<id>.idx" file: an append-only log
+// of where each blob written to (or tombstoned in) the volume's "<id>.dat"
+// file landed. Store rebuilds its in-memory index by replaying this log,
+// newline-delimited JSON like the rest of flex-bbs's on-disk/on-wire
+// records (see bbs/storage.saveJSON, bbs/api's NDJSON event stream).
+type idxRecord struct {
+	CID    string `json:"cid"`
+	Mime   string `json:"mime"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+	Tomb   bool   `json:"tomb,omitempty"`
+}
+
+// volume is one append-only data file plus the idx log recording where
+// each blob written to it landed. Only the active volume (the most
+// recently created one) is ever appended to; older volumes are read-only
+// until Store.Compact rewrites one.
+type volume struct {
+	id   uint32
+	data *os.File
+	idx  *os.File
+
+	size      int64 // current length of data, i.e. the next Put's offset
+	liveBytes int64 // sum of length over non-tombstoned entries
+	tombBytes int64 // sum of length over tombstoned entries
+}
+
+func volumeDataPath(dir string, id uint32) string {
+	return filepath.Join(dir, fmt.Sprintf("vol-%06d.dat", id))
+}
+
+func volumeIndexPath(dir string, id uint32) string {
+	return filepath.Join(dir, fmt.Sprintf("vol-%06d.idx", id))
+}
+
+func openVolume(dir string, id uint32) (*volume, error) {
+	data, err := os.OpenFile(volumeDataPath(dir, id), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("attachments: open volume %d data: %w", id, err)
+	}
+	idx, err := os.OpenFile(volumeIndexPath(dir, id), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		data.Close()
+		return nil, fmt.Errorf("attachments: open volume %d index: %w", id, err)
+	}
+	info, err := data.Stat()
+	if err != nil {
+		data.Close()
+		idx.Close()
+		return nil, fmt.Errorf("attachments: stat volume %d data: %w", id, err)
+	}
+	return &volume{id: id, data: data, idx: idx, size: info.Size()}, nil
+}
+
+func (v *volume) close() error {
+	err := v.data.Close()
+	if ierr := v.idx.Close(); err == nil {
+		err = ierr
+	}
+	return err
+}
+
+// appendIdxRecord appends one record to v's idx log.
+func (v *volume) appendIdxRecord(rec idxRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("attachments: encode idx record: %w", err)
+	}
+	b = append(b, '\n')
+	_, err = v.idx.Write(b)
+	return err
+}
+
+// readIdxRecords replays v's entire idx log in order, for Open to rebuild
+// the in-memory index from.
+func readIdxRecords(idx *os.File) ([]idxRecord, error) {
+	if _, err := idx.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	var out []idxRecord
+	scanner := bufio.NewScanner(idx)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec idxRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("attachments: decode idx record: %w", err)
+		}
+		out = append(out, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
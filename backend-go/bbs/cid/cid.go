@@ -0,0 +1,214 @@
+// Package cid computes CIDv1/dag-cbor/sha2-256 identifiers for arbitrary
+// Go values — the dag-cbor sibling of bbs/attachments' CIDv1/raw scheme
+// (see attachments.EncodeCIDv1Raw). attachments hashes a blob's raw bytes
+// (codec 0x55); this package hashes a canonical CBOR encoding of
+// structured data (codec 0x71, "dag-cbor" in
+// https://github.com/multiformats/multicodec), which is what a real
+// content-addressed identifier for a Post/ThreadMeta/BoardMeta needs
+// instead of hashing whatever byte-for-byte JSON encoding happened to be
+// produced.
+//
+// There's no vendored IPLD/CBOR library in this tree, so Encode builds
+// its own canonical CBOR encoder (the RFC 8949 "core deterministic
+// encoding" subset dag-cbor requires: shortest-form integers, map keys
+// sorted by encoded length then bytewise) over the same
+// map[string]any/[]any/string/bool/nil/number shape encoding/json already
+// produces for any value — so a Post/ThreadMeta/BoardMeta's existing
+// `json:"..."` tags are reused as-is instead of needing a second,
+// parallel cbor-tag scheme to keep in sync.
+package cid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CIDv1 (dag-cbor codec, sha2-256 multihash) is the only CID shape this
+// package produces.
+const (
+	codecDagCBOR       = 0x71
+	multihashSHA256    = 0x12
+	sha256DigestLength = 32
+)
+
+// cidBase32 matches attachments.cidBase32 (unpadded, case-insensitive
+// base32 under the "b" multibase prefix); duplicated here rather than
+// exported from bbs/attachments since that package's encoding is scoped
+// to its own raw-codec CIDs, not a general-purpose multibase helper.
+var cidBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Cid is a CIDv1/dag-cbor/sha2-256 identifier string, e.g.
+// "bafyrei...". It's a distinct type from string so a function
+// signature makes clear it expects an already-encoded CID rather than an
+// arbitrary identifier.
+type Cid string
+
+// String implements fmt.Stringer.
+func (c Cid) String() string { return string(c) }
+
+// Encode computes the CIDv1/dag-cbor/sha2-256 identifier for obj. obj is
+// first round-tripped through encoding/json (so any type with `json:"..."`
+// struct tags works unmodified) into the generic
+// map[string]any/[]any/json.Number/string/bool/nil shape, which is then
+// encoded as canonical CBOR and hashed with sha2-256. The resulting
+// digest is wrapped as a multihash (0x12 <len> <digest>) and a CIDv1
+// header (0x01 <codec=0x71> <multihash>), then base32-encoded with the
+// "b" multibase prefix — the same structure attachments.EncodeCIDv1Raw
+// uses for codec 0x55.
+func Encode(obj any) (Cid, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("cid: marshal: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", fmt.Errorf("cid: decode: %w", err)
+	}
+
+	cbor, err := encodeCanonical(v)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(cbor)
+
+	buf := make([]byte, 0, 4+sha256DigestLength)
+	buf = appendUvarint(buf, 1) // CID version
+	buf = appendUvarint(buf, codecDagCBOR)
+	buf = appendUvarint(buf, multihashSHA256)
+	buf = appendUvarint(buf, sha256DigestLength)
+	buf = append(buf, digest[:]...)
+	return Cid("b" + cidBase32.EncodeToString(buf)), nil
+}
+
+// encodeCanonical CBOR-encodes v (one of the types json.Decoder.Decode
+// with UseNumber produces) following RFC 8949's deterministic encoding
+// rules: shortest-form heads, and map entries sorted by the byte length
+// of their encoded key, then bytewise lexicographic order of the
+// encoded key.
+func encodeCanonical(v any) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return []byte{0xf6}, nil
+	case bool:
+		if x {
+			return []byte{0xf5}, nil
+		}
+		return []byte{0xf4}, nil
+	case json.Number:
+		return encodeNumber(x)
+	case string:
+		return encodeTextString(x), nil
+	case []any:
+		return encodeArray(x)
+	case map[string]any:
+		return encodeMap(x)
+	default:
+		return nil, fmt.Errorf("cid: unsupported value of type %T", v)
+	}
+}
+
+func encodeNumber(n json.Number) ([]byte, error) {
+	if i, err := n.Int64(); err == nil {
+		if i >= 0 {
+			return appendHead(nil, 0, uint64(i)), nil
+		}
+		return appendHead(nil, 1, uint64(-i-1)), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("cid: invalid number %q: %w", n.String(), err)
+	}
+	buf := make([]byte, 0, 9)
+	buf = append(buf, 0xfb)
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], math.Float64bits(f))
+	return append(buf, tmp[:]...), nil
+}
+
+func encodeTextString(s string) []byte {
+	return append(appendHead(nil, 3, uint64(len(s))), s...)
+}
+
+func encodeArray(a []any) ([]byte, error) {
+	buf := appendHead(nil, 4, uint64(len(a)))
+	for _, el := range a {
+		eb, err := encodeCanonical(el)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, eb...)
+	}
+	return buf, nil
+}
+
+func encodeMap(m map[string]any) ([]byte, error) {
+	type entry struct{ keyBytes, valBytes []byte }
+	entries := make([]entry, 0, len(m))
+	for k, v := range m {
+		vb, err := encodeCanonical(v)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{encodeTextString(k), vb})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if len(entries[i].keyBytes) != len(entries[j].keyBytes) {
+			return len(entries[i].keyBytes) < len(entries[j].keyBytes)
+		}
+		return bytes.Compare(entries[i].keyBytes, entries[j].keyBytes) < 0
+	})
+
+	buf := appendHead(nil, 5, uint64(len(entries)))
+	for _, e := range entries {
+		buf = append(buf, e.keyBytes...)
+		buf = append(buf, e.valBytes...)
+	}
+	return buf, nil
+}
+
+// appendHead appends a CBOR major-type/argument head in shortest form,
+// matching encoding/binary's big-endian uint width rules rather than
+// attachments' varint (CBOR's head encoding and multiformats' unsigned
+// varint are different wire formats; appendUvarint below is the varint
+// one, used only for the outer CID multiformat fields).
+func appendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major<<5|byte(n))
+	case n <= 0xff:
+		return append(buf, major<<5|24, byte(n))
+	case n <= 0xffff:
+		var tmp [2]byte
+		binary.BigEndian.PutUint16(tmp[:], uint16(n))
+		return append(append(buf, major<<5|25), tmp[:]...)
+	case n <= 0xffffffff:
+		var tmp [4]byte
+		binary.BigEndian.PutUint32(tmp[:], uint32(n))
+		return append(append(buf, major<<5|26), tmp[:]...)
+	default:
+		var tmp [8]byte
+		binary.BigEndian.PutUint64(tmp[:], n)
+		return append(append(buf, major<<5|27), tmp[:]...)
+	}
+}
+
+// appendUvarint duplicates attachments' unexported helper of the same
+// name (itself a multiformats unsigned-varint, unrelated to CBOR's own
+// head encoding above) — it's unexported there, so this package keeps
+// its own copy rather than reaching across the package boundary,
+// matching the precedent set by bbs/routing duplicating cmd/bbs-node's
+// extractIP4FromMultiaddr.
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
@@ -0,0 +1,72 @@
+package cid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode_IsDeterministicRegardlessOfFieldOrder(t *testing.T) {
+	a := map[string]any{"type": "post", "threadId": "t1", "body": map[string]any{"format": "plain", "content": "hi"}}
+	b := map[string]any{"body": map[string]any{"content": "hi", "format": "plain"}, "threadId": "t1", "type": "post"}
+
+	ca, err := Encode(a)
+	if err != nil {
+		t.Fatalf("Encode(a): %v", err)
+	}
+	cb, err := Encode(b)
+	if err != nil {
+		t.Fatalf("Encode(b): %v", err)
+	}
+	if ca != cb {
+		t.Fatalf("Encode produced different CIDs for maps differing only in key order: %q vs %q", ca, cb)
+	}
+}
+
+func TestEncode_DiffersOnContentChange(t *testing.T) {
+	c1, err := Encode(map[string]any{"content": "hello"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	c2, err := Encode(map[string]any{"content": "world"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if c1 == c2 {
+		t.Fatal("expected different content to produce different CIDs")
+	}
+}
+
+func TestEncode_ProducesWellFormedCIDv1String(t *testing.T) {
+	c, err := Encode(map[string]any{"a": 1})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	s := c.String()
+	if !strings.HasPrefix(s, "b") {
+		t.Fatalf("Encode = %q, want a 'b'-prefixed multibase string", s)
+	}
+	raw, err := cidBase32.DecodeString(s[1:])
+	if err != nil {
+		t.Fatalf("base32 decode: %v", err)
+	}
+	if len(raw) != 4+sha256DigestLength {
+		t.Fatalf("decoded CID length = %d, want %d (version+codec+mh-fn+mh-len headers plus a 32-byte digest)", len(raw), 4+sha256DigestLength)
+	}
+	if raw[0] != 1 || raw[1] != codecDagCBOR || raw[2] != multihashSHA256 || raw[3] != sha256DigestLength {
+		t.Fatalf("unexpected CID header bytes: %v", raw[:4])
+	}
+}
+
+func TestEncode_IntegerAndFloatRoundTripDistinctly(t *testing.T) {
+	whole, err := Encode(map[string]any{"n": 2})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	fractional, err := Encode(map[string]any{"n": 2.5})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if whole == fractional {
+		t.Fatal("expected an integer and a fractional number to encode differently")
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AnnounceAuthFile is announce_auth.json's on-disk shape: one ed25519
+// public key per trusted peer, keyed by the keyId that peer signs its
+// requests as (see bbs/api's "BBS-Ed25519" Authorization scheme).
+type AnnounceAuthFile struct {
+	Peers map[string]string `json:"peers"`
+}
+
+// AnnounceAuthStore is the inbound-announce allow-list: which keyIds may
+// sign POST /api/v1/announce/board and POST /api/v1/sync/digest requests,
+// and which pubkey each one signs with. Unlike TrustedIndexersStore (which
+// just needs a base URL to call), verifying an inbound request only needs
+// the signer's pubkey — there's no requirement that a keyId here also be
+// one of TrustedIndexers' base URLs, since a peer's public signing key and
+// its current network address can rotate independently.
+type AnnounceAuthStore struct {
+	path string
+
+	mu    sync.Mutex
+	peers map[string]string
+}
+
+func NewAnnounceAuthStore(path string) *AnnounceAuthStore {
+	return &AnnounceAuthStore{path: path, peers: make(map[string]string)}
+}
+
+func (s *AnnounceAuthStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.peers = make(map[string]string)
+		return s.saveLocked()
+	}
+	if err != nil {
+		return err
+	}
+
+	s.peers = make(map[string]string)
+	if len(b) == 0 {
+		return nil
+	}
+
+	var f AnnounceAuthFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	for keyID, pubKey := range f.Peers {
+		keyID = strings.TrimSpace(keyID)
+		pubKey = strings.TrimSpace(pubKey)
+		if keyID == "" || pubKey == "" {
+			continue
+		}
+		s.peers[keyID] = pubKey
+	}
+	return nil
+}
+
+// PubKey returns keyID's configured ed25519 public key string (as
+// bbs/signature.ParsePublicKey parses), if any.
+func (s *AnnounceAuthStore) PubKey(keyID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pubKey, ok := s.peers[strings.TrimSpace(keyID)]
+	return pubKey, ok
+}
+
+// Upsert adds or replaces keyID's pubkey, reporting whether it was new.
+func (s *AnnounceAuthStore) Upsert(keyID, pubKey string) (bool, error) {
+	keyID = strings.TrimSpace(keyID)
+	pubKey = strings.TrimSpace(pubKey)
+	if keyID == "" {
+		return false, fmt.Errorf("keyId is empty")
+	}
+	if pubKey == "" {
+		return false, fmt.Errorf("pubKey is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, existed := s.peers[keyID]
+	s.peers[keyID] = pubKey
+	return !existed, s.saveLocked()
+}
+
+// Remove deletes keyID, reporting whether it was present.
+func (s *AnnounceAuthStore) Remove(keyID string) (bool, error) {
+	keyID = strings.TrimSpace(keyID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.peers[keyID]; !ok {
+		return false, nil
+	}
+	delete(s.peers, keyID)
+	return true, s.saveLocked()
+}
+
+func (s *AnnounceAuthStore) saveLocked() error {
+	keys := make([]string, 0, len(s.peers))
+	for k := range s.peers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := AnnounceAuthFile{Peers: make(map[string]string, len(s.peers))}
+	for _, k := range keys {
+		f.Peers[k] = s.peers[k]
+	}
+
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
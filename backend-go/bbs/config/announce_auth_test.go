@@ -0,0 +1,63 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAnnounceAuthStore_UpsertLoadRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "announce_auth.json")
+
+	s := NewAnnounceAuthStore(path)
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, ok := s.PubKey("peer-a"); ok {
+		t.Fatalf("expected no pubkey for unknown keyId")
+	}
+
+	isNew, err := s.Upsert("peer-a", "ed25519:abc")
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if !isNew {
+		t.Fatalf("expected isNew=true for first Upsert")
+	}
+	isNew, err = s.Upsert("peer-a", "ed25519:def")
+	if err != nil {
+		t.Fatalf("Upsert (replace): %v", err)
+	}
+	if isNew {
+		t.Fatalf("expected isNew=false for replacing Upsert")
+	}
+
+	// Reload from disk in a fresh store to confirm persistence.
+	s2 := NewAnnounceAuthStore(path)
+	if err := s2.Load(); err != nil {
+		t.Fatalf("Load (reload): %v", err)
+	}
+	pubKey, ok := s2.PubKey("peer-a")
+	if !ok || pubKey != "ed25519:def" {
+		t.Fatalf("reload mismatch: pubKey=%q ok=%v", pubKey, ok)
+	}
+
+	removed, err := s2.Remove("peer-a")
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if !removed {
+		t.Fatalf("expected removed=true")
+	}
+	if _, ok := s2.PubKey("peer-a"); ok {
+		t.Fatalf("expected no pubkey after Remove")
+	}
+
+	removed, err = s2.Remove("peer-a")
+	if err != nil {
+		t.Fatalf("Remove (already gone): %v", err)
+	}
+	if removed {
+		t.Fatalf("expected removed=false for already-removed keyId")
+	}
+}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
+	"time"
+
+	"flex-bbs/backend-go/bbs/dnslink"
 )
 
 type BoardRef struct {
@@ -15,13 +20,36 @@ type BoardRef struct {
 
 type BoardsFile struct {
 	Boards []BoardRef `json:"boards"`
+	// DNSLinks are domain names (bare, or "dnslink://"-prefixed) to expand
+	// via BoardsStore.DNSLink at Load, each contributing whatever BoardRefs
+	// its "_bbs.<domain>" TXT records resolve to. They're listed separately
+	// from Boards rather than overloading BoardRef's BoardMetaCID field,
+	// since a DNSLink name resolves to a BoardID too, not just a CID.
+	DNSLinks []string `json:"dnslinks,omitempty"`
 }
 
+// dnsLinkResolveTimeout bounds how long Load waits for DNSLink resolution
+// before giving up on the remaining names, so a single unreachable
+// nameserver can't hang every Load call indefinitely.
+const dnsLinkResolveTimeout = 10 * time.Second
+
 type BoardsStore struct {
 	path string
 
-	mu     sync.Mutex
-	byID   map[string]string
+	// DNSLink, if non-nil, lets Load expand each name in the loaded file's
+	// DNSLinks into its resolved BoardRef(s), alongside the directly
+	// addressed Boards entries. Nil (the default from NewBoardsStore)
+	// leaves Load's existing behavior unchanged for callers that don't
+	// want a DNS dependency.
+	DNSLink *dnslink.Resolver
+
+	mu   sync.Mutex
+	byID map[string]string
+	// dnslinks mirrors the loaded file's DNSLinks list, so saveLocked can
+	// round-trip it rather than dropping it on the next Upsert-triggered
+	// save (DNSLinks are configuration a user wrote into boards.json by
+	// hand, not something a Upsert caller knows to repeat).
+	dnslinks []string
 }
 
 func NewBoardsStore(path string) *BoardsStore {
@@ -65,9 +93,35 @@ func (s *BoardsStore) Load() error {
 		}
 		s.byID[br.BoardID] = br.BoardMetaCID
 	}
+	s.dnslinks = f.DNSLinks
+
+	if s.DNSLink != nil && len(f.DNSLinks) > 0 {
+		s.expandDNSLinksLocked(f.DNSLinks)
+	}
 	return nil
 }
 
+// expandDNSLinksLocked resolves each name in names through s.DNSLink,
+// merging every returned BoardRef into s.byID. A name that fails to
+// resolve (unreachable nameserver, no usable records, ...) is logged and
+// skipped rather than failing the whole Load, so one bad DNSLink entry
+// can't take every other board down with it.
+func (s *BoardsStore) expandDNSLinksLocked(names []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLinkResolveTimeout)
+	defer cancel()
+
+	for _, name := range names {
+		refs, err := s.DNSLink.Resolve(ctx, name)
+		if err != nil {
+			log.Printf("config: resolve dnslink %s: %v", name, err)
+			continue
+		}
+		for _, ref := range refs {
+			s.byID[ref.BoardID] = ref.BoardMetaCID
+		}
+	}
+}
+
 func (s *BoardsStore) List() []BoardRef {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -95,7 +149,7 @@ func (s *BoardsStore) Upsert(boardID, boardMetaCID string) error {
 }
 
 func (s *BoardsStore) saveLocked() error {
-	f := BoardsFile{Boards: make([]BoardRef, 0, len(s.byID))}
+	f := BoardsFile{Boards: make([]BoardRef, 0, len(s.byID)), DNSLinks: s.dnslinks}
 	for id, cid := range s.byID {
 		f.Boards = append(f.Boards, BoardRef{BoardID: id, BoardMetaCID: cid})
 	}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"flex-bbs/backend-go/bbs/dnslink"
 )
 
 func TestBoardsStore_Load_ReloadsFromDisk(t *testing.T) {
@@ -58,3 +61,52 @@ func TestBoardsStore_Load_ReloadsFromDisk(t *testing.T) {
 	}
 }
 
+func TestBoardsStore_Load_ExpandsDNSLinks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "boards.json")
+
+	f := BoardsFile{DNSLinks: []string{"bbs.example.org"}}
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	s := NewBoardsStore(path)
+	s.DNSLink = &dnslink.Resolver{
+		Lookup: func(ctx context.Context, name string) ([]string, error) {
+			if name != "_bbs.bbs.example.org" {
+				t.Fatalf("unexpected lookup name %q", name)
+			}
+			return []string{"bbs-board=bbs.general bbs-meta=baf_meta_1"}, nil
+		},
+	}
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	got := s.List()
+	if len(got) != 1 || got[0].BoardID != "bbs.general" || got[0].BoardMetaCID != "baf_meta_1" {
+		t.Fatalf("expected the DNSLink entry to expand into bbs.general, got=%#v", got)
+	}
+
+	// DNSLinks must survive a later Upsert-triggered save, since it's
+	// hand-written config, not something Upsert callers know to repeat.
+	if err := s.Upsert("bbs.other", "baf_other"); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var saved BoardsFile
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(saved.DNSLinks) != 1 || saved.DNSLinks[0] != "bbs.example.org" {
+		t.Fatalf("expected dnslinks to round-trip through saveLocked, got=%#v", saved.DNSLinks)
+	}
+}
+
@@ -18,8 +18,9 @@ type TrustedIndexersFile struct {
 type TrustedIndexersStore struct {
 	path string
 
-	mu  sync.Mutex
-	set map[string]struct{}
+	mu          sync.Mutex
+	set         map[string]struct{}
+	subscribers map[chan<- []string]struct{}
 }
 
 func NewTrustedIndexersStore(path string) *TrustedIndexersStore {
@@ -102,7 +103,10 @@ func (s *TrustedIndexersStore) Load() error {
 func (s *TrustedIndexersStore) List() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.listLocked()
+}
 
+func (s *TrustedIndexersStore) listLocked() []string {
 	out := make([]string, 0, len(s.set))
 	for u := range s.set {
 		out = append(out, u)
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+// SignedBundle is a trusted-indexers list signed by a root authority key,
+// distributed out-of-band (e.g. alongside a release) so operators don't
+// have to hand-curate trusted_indexers.json themselves.
+type SignedBundle struct {
+	Version         int      `json:"version"`
+	TrustedIndexers []string `json:"trustedIndexers"`
+	IssuedAt        string   `json:"issuedAt"`
+	SignerPubKey    string   `json:"signerPubKey"`
+	Signature       string   `json:"signature"`
+}
+
+// KeyRotation authorizes retiring OldPubKey in favor of NewPubKey. It must be
+// signed by OldPubKey, forming a chain of custody rooted at whatever key an
+// operator initially pinned: each rotation proves the old key endorsed the
+// new one, so a compromised-and-replaced signing key can be rotated out
+// without operators needing to re-pin trust by hand.
+type KeyRotation struct {
+	Version   int    `json:"version"`
+	OldPubKey string `json:"oldPubKey"`
+	NewPubKey string `json:"newPubKey"`
+	RotatedAt string `json:"rotatedAt"`
+	Signature string `json:"signature"`
+}
+
+func canonicalSignedBundlePayload(b *SignedBundle) string {
+	var sb strings.Builder
+	sb.WriteString("type=trustedIndexersBundle\n")
+	sb.WriteString(fmt.Sprintf("version=%d\n", b.Version))
+	sb.WriteString("trustedIndexers=" + strings.Join(b.TrustedIndexers, ",") + "\n")
+	sb.WriteString("issuedAt=" + b.IssuedAt + "\n")
+	sb.WriteString("signerPubKey=" + b.SignerPubKey)
+	return sb.String()
+}
+
+func canonicalKeyRotationPayload(r *KeyRotation) string {
+	var sb strings.Builder
+	sb.WriteString("type=trustedIndexerKeyRotation\n")
+	sb.WriteString(fmt.Sprintf("version=%d\n", r.Version))
+	sb.WriteString("oldPubKey=" + r.OldPubKey + "\n")
+	sb.WriteString("newPubKey=" + r.NewPubKey + "\n")
+	sb.WriteString("rotatedAt=" + r.RotatedAt)
+	return sb.String()
+}
+
+// SignBundle signs b as SignerPubKey, the counterpart of privKeyString.
+func SignBundle(privKeyString string, b *SignedBundle) error {
+	priv, err := signature.ParsePrivateKey(privKeyString)
+	if err != nil {
+		return err
+	}
+	pub, err := signature.PublicKeyFromPrivate(priv)
+	if err != nil {
+		return err
+	}
+	b.SignerPubKey = signature.PublicKeyString(pub)
+	sig, err := signature.SignBase64(priv, canonicalSignedBundlePayload(b))
+	if err != nil {
+		return err
+	}
+	b.Signature = sig
+	return nil
+}
+
+// SignKeyRotation signs r as having been authorized by oldPrivKeyString.
+func SignKeyRotation(oldPrivKeyString string, r *KeyRotation) error {
+	priv, err := signature.ParsePrivateKey(oldPrivKeyString)
+	if err != nil {
+		return err
+	}
+	pub, err := signature.PublicKeyFromPrivate(priv)
+	if err != nil {
+		return err
+	}
+	if r.OldPubKey == "" {
+		r.OldPubKey = signature.PublicKeyString(pub)
+	} else if r.OldPubKey != signature.PublicKeyString(pub) {
+		return fmt.Errorf("oldPrivKeyString does not match rotation.OldPubKey")
+	}
+	sig, err := signature.SignBase64(priv, canonicalKeyRotationPayload(r))
+	if err != nil {
+		return err
+	}
+	r.Signature = sig
+	return nil
+}
+
+func verifySignedBundle(b *SignedBundle) bool {
+	pub, err := signature.ParsePublicKey(b.SignerPubKey)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, canonicalSignedBundlePayload(b), b.Signature) == nil
+}
+
+func verifyKeyRotation(r *KeyRotation) bool {
+	pub, err := signature.ParsePublicKey(r.OldPubKey)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, canonicalKeyRotationPayload(r), r.Signature) == nil
+}
+
+// ResolveActiveSignerKey walks rotations (which need not be pre-sorted) from
+// rootPubKey, following each signed hand-off to its NewPubKey, and returns
+// the pubkey currently trusted to sign bundles. Returns rootPubKey unchanged
+// if there are no rotations for it.
+func ResolveActiveSignerKey(rootPubKey string, rotations []KeyRotation) (string, error) {
+	byOld := make(map[string]KeyRotation, len(rotations))
+	for _, r := range rotations {
+		if !verifyKeyRotation(&r) {
+			return "", fmt.Errorf("key rotation from %s: invalid signature", r.OldPubKey)
+		}
+		if _, dup := byOld[r.OldPubKey]; dup {
+			return "", fmt.Errorf("key %s has more than one rotation", r.OldPubKey)
+		}
+		byOld[r.OldPubKey] = r
+	}
+
+	current := rootPubKey
+	seen := map[string]struct{}{current: {}}
+	for {
+		next, ok := byOld[current]
+		if !ok {
+			return current, nil
+		}
+		if _, loop := seen[next.NewPubKey]; loop {
+			return "", fmt.Errorf("key rotation chain contains a cycle at %s", next.NewPubKey)
+		}
+		seen[next.NewPubKey] = struct{}{}
+		current = next.NewPubKey
+	}
+}
+
+// LoadSignedBundle reads and verifies a SignedBundle plus an optional chain
+// of key rotations from disk, checks that the bundle was signed by the key
+// currently active under rootPubKey, and replaces the store's in-memory set
+// with the bundle's contents (same normalization rules as Load).
+func (s *TrustedIndexersStore) LoadSignedBundle(bundlePath, rotationsPath, rootPubKey string) error {
+	bb, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return err
+	}
+	var bundle SignedBundle
+	if err := json.Unmarshal(bb, &bundle); err != nil {
+		return fmt.Errorf("parse bundle: %w", err)
+	}
+	if !verifySignedBundle(&bundle) {
+		return fmt.Errorf("bundle signature invalid")
+	}
+
+	var rotations []KeyRotation
+	if rotationsPath != "" {
+		rb, err := os.ReadFile(rotationsPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(rb) > 0 {
+			if err := json.Unmarshal(rb, &rotations); err != nil {
+				return fmt.Errorf("parse rotations: %w", err)
+			}
+		}
+	}
+
+	activeKey, err := ResolveActiveSignerKey(rootPubKey, rotations)
+	if err != nil {
+		return fmt.Errorf("resolve active signer key: %w", err)
+	}
+	if bundle.SignerPubKey != activeKey {
+		return fmt.Errorf("bundle signed by %s, want currently-active key %s", bundle.SignerPubKey, activeKey)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[string]struct{}, len(bundle.TrustedIndexers))
+	for _, raw := range bundle.TrustedIndexers {
+		n, err := NormalizeBaseURL(raw)
+		if err != nil {
+			continue
+		}
+		next[n] = struct{}{}
+	}
+	s.set = next
+	return s.saveLocked()
+}
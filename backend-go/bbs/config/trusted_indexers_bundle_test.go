@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+func TestLoadSignedBundle_VerifiesSignatureAndLoads(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "trusted_indexers.json")
+	bundlePath := filepath.Join(dir, "bundle.json")
+
+	rootPub, rootPriv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	bundle := SignedBundle{
+		Version:         1,
+		TrustedIndexers: []string{"https://indexer.example.com"},
+		IssuedAt:        "2025-01-01T00:00:00Z",
+	}
+	if err := SignBundle(rootPriv, &bundle); err != nil {
+		t.Fatalf("SignBundle: %v", err)
+	}
+	b, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(bundlePath, b, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	s := NewTrustedIndexersStore(storePath)
+	if err := s.LoadSignedBundle(bundlePath, "", rootPub); err != nil {
+		t.Fatalf("LoadSignedBundle: %v", err)
+	}
+	if got := s.List(); len(got) != 1 || got[0] != "https://indexer.example.com" {
+		t.Fatalf("List: %#v", got)
+	}
+}
+
+func TestLoadSignedBundle_RejectsBundleSignedByRetiredKey(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "trusted_indexers.json")
+	bundlePath := filepath.Join(dir, "bundle.json")
+	rotationsPath := filepath.Join(dir, "rotations.json")
+
+	rootPub, rootPriv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	_, newPriv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	newPub, err := deriveTestPubKey(newPriv)
+	if err != nil {
+		t.Fatalf("deriveTestPubKey: %v", err)
+	}
+
+	rotation := KeyRotation{Version: 1, NewPubKey: newPub, RotatedAt: "2025-02-01T00:00:00Z"}
+	if err := SignKeyRotation(rootPriv, &rotation); err != nil {
+		t.Fatalf("SignKeyRotation: %v", err)
+	}
+	rb, err := json.Marshal([]KeyRotation{rotation})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(rotationsPath, rb, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// Bundle still signed by the now-retired root key should be rejected.
+	bundle := SignedBundle{Version: 1, TrustedIndexers: []string{"https://indexer.example.com"}, IssuedAt: "2025-03-01T00:00:00Z"}
+	if err := SignBundle(rootPriv, &bundle); err != nil {
+		t.Fatalf("SignBundle: %v", err)
+	}
+	bb, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(bundlePath, bb, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	s := NewTrustedIndexersStore(storePath)
+	if err := s.LoadSignedBundle(bundlePath, rotationsPath, rootPub); err == nil {
+		t.Fatal("expected error for bundle signed by retired key")
+	}
+
+	// Re-signing with the new key should succeed.
+	bundle2 := SignedBundle{Version: 1, TrustedIndexers: []string{"https://indexer.example.com"}, IssuedAt: "2025-03-02T00:00:00Z"}
+	if err := SignBundle(newPriv, &bundle2); err != nil {
+		t.Fatalf("SignBundle: %v", err)
+	}
+	bb2, err := json.Marshal(bundle2)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(bundlePath, bb2, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := s.LoadSignedBundle(bundlePath, rotationsPath, rootPub); err != nil {
+		t.Fatalf("LoadSignedBundle after rotation: %v", err)
+	}
+}
+
+func deriveTestPubKey(privKeyString string) (string, error) {
+	priv, err := signature.ParsePrivateKey(privKeyString)
+	if err != nil {
+		return "", err
+	}
+	pub, err := signature.PublicKeyFromPrivate(priv)
+	if err != nil {
+		return "", err
+	}
+	return signature.PublicKeyString(pub), nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch starts an fsnotify watcher on the directory containing the store's
+// file and reloads the store whenever it changes, so changes made by other
+// processes (e.g. `bbs-node add-trusted-indexer` from the TUI) are picked up
+// without callers having to call Load themselves on every request.
+//
+// The directory, not the file, is watched: saveLocked() writes via a
+// rename-from-tmp, which on most filesystems replaces the inode rather than
+// writing in place, and a watch on the old inode would silently stop firing.
+//
+// The returned stop function closes the watcher and must be called to avoid
+// leaking the underlying OS resources; it is safe to call more than once.
+func (s *TrustedIndexersStore) Watch() (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(s.path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != filepath.Clean(s.path) {
+					continue
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if err := s.Load(); err != nil {
+					log.Printf("config trusted_indexers watch: reload %s: %v", s.path, err)
+					continue
+				}
+				s.notifySubscribers()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("config trusted_indexers watch: %v", err)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		close(done)
+		_ = watcher.Close()
+	}, nil
+}
+
+// Subscribe registers ch to receive the current list of trusted indexers
+// every time Watch reloads the store; an explicit Load call does not notify
+// subscribers. The channel is sent to on a best-effort basis: a slow
+// or non-draining subscriber will miss updates rather than block the
+// watcher goroutine. Call the returned unsubscribe func to stop delivery.
+func (s *TrustedIndexersStore) Subscribe(ch chan<- []string) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan<- []string]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, ch)
+	}
+}
+
+func (s *TrustedIndexersStore) notifySubscribers() {
+	s.mu.Lock()
+	list := s.listLocked()
+	subs := make([]chan<- []string, 0, len(s.subscribers))
+	for ch := range s.subscribers {
+		subs = append(subs, ch)
+	}
+	s.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- list:
+		default:
+		}
+	}
+}
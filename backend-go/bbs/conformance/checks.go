@@ -0,0 +1,304 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"flex-bbs/backend-go/bbs/api"
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// checks is the fixed suite Run exercises, in order. Each one asserts a
+// single documented behavior of the /api/v1/* surface (status codes, JSON
+// shapes, signature validity, pagination invariants, or role-gating) so a
+// failing Result names exactly what broke.
+var checks = []Check{
+	{Name: "healthz", Run: checkHealthz},
+	{Name: "list-boards-shape", Run: checkListBoardsShape},
+	{Name: "get-board-404", Run: checkGetBoardNotFound},
+	{Name: "get-board-fixture", Run: checkGetBoardFixture},
+	{Name: "create-thread-and-post-roundtrip", Run: checkCreateThreadAndPostRoundtrip},
+	{Name: "list-threads-pagination", Run: checkListThreadsPagination},
+	{Name: "role-gated-peering-heads", Run: checkRoleGatedPeeringHeads},
+}
+
+func checkHealthz(ctx context.Context, c *apiClient, opts Options) error {
+	status, err := c.do(ctx, http.MethodGet, "/healthz", nil, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET /healthz: want 200, got %d", status)
+	}
+	return nil
+}
+
+func checkListBoardsShape(ctx context.Context, c *apiClient, opts Options) error {
+	var boards []api.BoardItem
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/boards", nil, &boards)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET /api/v1/boards: want 200, got %d", status)
+	}
+	return nil
+}
+
+func checkGetBoardNotFound(ctx context.Context, c *apiClient, opts Options) error {
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/boards/bbs.conformance.does-not-exist", nil, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNotFound {
+		return fmt.Errorf("GET /api/v1/boards/<unknown>: want 404, got %d", status)
+	}
+	return nil
+}
+
+func checkGetBoardFixture(ctx context.Context, c *apiClient, opts Options) error {
+	if opts.FixtureBoardID == "" {
+		return skip("no FixtureBoardID given (pass --fixtures-car to load one)")
+	}
+
+	var item api.BoardItem
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/boards/"+opts.FixtureBoardID, nil, &item)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET /api/v1/boards/%s: want 200, got %d", opts.FixtureBoardID, status)
+	}
+	if item.Board.BoardID != opts.FixtureBoardID {
+		return fmt.Errorf("GET /api/v1/boards/%s: response boardId=%q, want %q", opts.FixtureBoardID, item.Board.BoardID, opts.FixtureBoardID)
+	}
+	if !bbslog.VerifyBoardMeta(&item.Board) {
+		return fmt.Errorf("GET /api/v1/boards/%s: returned BoardMeta has an invalid signature", opts.FixtureBoardID)
+	}
+	return nil
+}
+
+func checkCreateThreadAndPostRoundtrip(ctx context.Context, c *apiClient, opts Options) error {
+	if opts.FixtureBoardID == "" {
+		return skip("no FixtureBoardID given (pass --fixtures-car to load one): createThread needs an existing board, and there's no /api/v1 endpoint to create one")
+	}
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		return fmt.Errorf("generate author key: %w", err)
+	}
+
+	createReq := api.CreateThreadRequest{
+		BoardID:       opts.FixtureBoardID,
+		Title:         "conformance check thread",
+		Body:          types.PostBody{Format: "text", Content: "root post from the conformance suite"},
+		AuthorPrivKey: priv,
+	}
+	var createResp api.CreateThreadResponse
+	status, err := c.do(ctx, http.MethodPost, "/api/v1/threads", createReq, &createResp)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("POST /api/v1/threads: want 200, got %d", status)
+	}
+	if createResp.ThreadID == "" || createResp.RootPostCID == "" {
+		return fmt.Errorf("POST /api/v1/threads: response missing threadId/rootPostCid: %+v", createResp)
+	}
+
+	var thread api.ThreadResponse
+	status, err = c.do(ctx, http.MethodGet, "/api/v1/threads/"+createResp.ThreadID, nil, &thread)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET /api/v1/threads/%s: want 200, got %d", createResp.ThreadID, status)
+	}
+	if !bbslog.VerifyThreadMeta(&thread.ThreadMeta) {
+		return fmt.Errorf("GET /api/v1/threads/%s: returned ThreadMeta has an invalid signature", createResp.ThreadID)
+	}
+	if err := verifyPostInThread(thread, createResp.RootPostCID); err != nil {
+		return err
+	}
+
+	addReq := api.AddPostRequest{
+		ThreadID:      createResp.ThreadID,
+		Body:          types.PostBody{Format: "text", Content: "reply post from the conformance suite"},
+		AuthorPrivKey: priv,
+	}
+	var addResp api.AddPostResponse
+	status, err = c.do(ctx, http.MethodPost, "/api/v1/posts", addReq, &addResp)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("POST /api/v1/posts: want 200, got %d", status)
+	}
+	if addResp.PostCID == "" {
+		return fmt.Errorf("POST /api/v1/posts: response missing postCid: %+v", addResp)
+	}
+
+	status, err = c.do(ctx, http.MethodGet, "/api/v1/threads/"+createResp.ThreadID, nil, &thread)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET /api/v1/threads/%s (after reply): want 200, got %d", createResp.ThreadID, status)
+	}
+	if len(thread.Posts) < 2 {
+		return fmt.Errorf("GET /api/v1/threads/%s (after reply): want >= 2 posts, got %d", createResp.ThreadID, len(thread.Posts))
+	}
+	return verifyPostInThread(thread, addResp.PostCID)
+}
+
+// verifyPostInThread finds wantCID among thread.Posts and re-verifies its
+// signature, asserting the API served back genuinely verifiable signed
+// content rather than just echoing whatever bytes it was handed.
+func verifyPostInThread(thread api.ThreadResponse, wantCID string) error {
+	for _, item := range thread.Posts {
+		if item.CID != wantCID {
+			continue
+		}
+		if !bbslog.VerifyPost(&item.Post) {
+			return fmt.Errorf("thread %s: post %s has an invalid signature", thread.ThreadMetaCID, wantCID)
+		}
+		return nil
+	}
+	return fmt.Errorf("thread %s: post %s not found in response", thread.ThreadMetaCID, wantCID)
+}
+
+func checkListThreadsPagination(ctx context.Context, c *apiClient, opts Options) error {
+	if opts.FixtureBoardID == "" {
+		return skip("no FixtureBoardID given (pass --fixtures-car to load one)")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, priv, err := signature.GenerateKeyPair()
+		if err != nil {
+			return fmt.Errorf("generate author key: %w", err)
+		}
+		req := api.CreateThreadRequest{
+			BoardID:       opts.FixtureBoardID,
+			Title:         fmt.Sprintf("conformance pagination thread %d", i),
+			Body:          types.PostBody{Format: "text", Content: "pagination fixture post"},
+			AuthorPrivKey: priv,
+		}
+		var resp api.CreateThreadResponse
+		status, err := c.do(ctx, http.MethodPost, "/api/v1/threads", req, &resp)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("POST /api/v1/threads (pagination fixture %d): want 200, got %d", i, status)
+		}
+	}
+
+	var page1 api.ThreadsPage
+	path := "/api/v1/boards/" + opts.FixtureBoardID + "/threads?limit=1"
+	status, err := c.do(ctx, http.MethodGet, path, nil, &page1)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s: want 200, got %d", path, status)
+	}
+	if page1.Limit != 1 {
+		return fmt.Errorf("GET %s: response limit=%d, want 1", path, page1.Limit)
+	}
+	if len(page1.Threads) != 1 {
+		return fmt.Errorf("GET %s: got %d threads, want 1", path, len(page1.Threads))
+	}
+	if page1.NextCursor == "" {
+		return fmt.Errorf("GET %s: want a non-empty nextCursor (board has >= 2 threads now)", path)
+	}
+
+	path2 := "/api/v1/boards/" + opts.FixtureBoardID + "/threads?limit=1&max_id=" + page1.NextCursor
+	var page2 api.ThreadsPage
+	status, err = c.do(ctx, http.MethodGet, path2, nil, &page2)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s: want 200, got %d", path2, status)
+	}
+	if len(page2.Threads) != 1 {
+		return fmt.Errorf("GET %s: got %d threads, want 1", path2, len(page2.Threads))
+	}
+	if page2.Threads[0].ThreadID == page1.Threads[0].ThreadID {
+		return fmt.Errorf("GET %s: returned the same thread %s as page 1, max_id did not advance", path2, page2.Threads[0].ThreadID)
+	}
+
+	if page2.PrevCursor == "" {
+		return fmt.Errorf("GET %s: want a non-empty prevCursor (page 1's newer thread precedes page 2)", path2)
+	}
+	path3 := "/api/v1/boards/" + opts.FixtureBoardID + "/threads?limit=1&min_id=" + page2.PrevCursor
+	var page3 api.ThreadsPage
+	status, err = c.do(ctx, http.MethodGet, path3, nil, &page3)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s: want 200, got %d", path3, status)
+	}
+	if len(page3.Threads) != 1 || page3.Threads[0].ThreadID != page1.Threads[0].ThreadID {
+		return fmt.Errorf("GET %s: want min_id to page back to thread %s, got %#v", path3, page1.Threads[0].ThreadID, page3.Threads)
+	}
+	return nil
+}
+
+func checkRoleGatedPeeringHeads(ctx context.Context, c *apiClient, opts Options) error {
+	role, err := rawHealthzRole(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/indexer/peering/heads", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if role == "indexer" || role == "full" {
+		if status != http.StatusOK {
+			return fmt.Errorf("GET /api/v1/indexer/peering/heads (role=%s): want 200, got %d", role, status)
+		}
+		return nil
+	}
+	if status != http.StatusNotImplemented {
+		return fmt.Errorf("GET /api/v1/indexer/peering/heads (role=%s): want 501, got %d", role, status)
+	}
+	return nil
+}
+
+// rawHealthzRole issues GET /healthz and parses the role out of its
+// "ok role=<role>" plain-text body (healthz is the one endpoint in this API
+// that doesn't respond JSON).
+func rawHealthzRole(ctx context.Context, c *apiClient) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var sb strings.Builder
+	buf := make([]byte, 256)
+	for {
+		n, rerr := resp.Body.Read(buf)
+		sb.Write(buf[:n])
+		if rerr != nil {
+			break
+		}
+	}
+	body := sb.String()
+	const prefix = "ok role="
+	if i := strings.Index(body, prefix); i >= 0 {
+		return strings.TrimSpace(body[i+len(prefix):]), nil
+	}
+	return "", fmt.Errorf("GET /healthz: unexpected body %q", body)
+}
@@ -0,0 +1,150 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errSkip marks a Check as inapplicable rather than failed — e.g. a check
+// that needs FixtureBoardID when Run was called without one (the
+// -base-url-only case, where this package has no storage handle to load
+// fixtures into a node it doesn't own). Wrap it with a reason via
+// fmt.Errorf("%w: ...", errSkip, ...).
+var errSkip = errors.New("conformance: check skipped")
+
+// skip is the usual way a Check reports errSkip with a reason.
+func skip(reason string) error {
+	return fmt.Errorf("%w: %s", errSkip, reason)
+}
+
+// Check is one black-box assertion against a running bbs-node's HTTP API.
+// The suite (see checks.go) is a fixed slice of these, run in order against
+// a single apiClient.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, c *apiClient, opts Options) error
+}
+
+// Result is one Check's outcome.
+type Result struct {
+	Name     string
+	Passed   bool
+	Skipped  bool
+	Err      string
+	Duration time.Duration
+}
+
+// Report is the full suite's outcome, the source Report.JUnitXML and
+// Report.Summary render from.
+type Report struct {
+	Suite   string
+	Results []Result
+}
+
+// Failed returns how many checks neither passed nor were skipped.
+func (r *Report) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if !res.Passed && !res.Skipped {
+			n++
+		}
+	}
+	return n
+}
+
+// Options parameterizes which checks Run can exercise.
+type Options struct {
+	// FixtureBoardID, if non-empty, names a board already registered on
+	// the node under test (typically via Harness.LoadFixturesCAR) that
+	// read-path and round-trip checks use. Checks requiring it are
+	// skipped, not failed, when it's empty — the only way to guarantee
+	// board content exists is to have loaded it ourselves, which isn't
+	// possible against an arbitrary --base-url with no fixtures given.
+	FixtureBoardID string
+}
+
+// Run exercises every Check in the suite against baseURL in order,
+// collecting a Report. It never returns a non-nil error itself — individual
+// check failures land in the Report, not in err — so a caller can always
+// render a report even when every check fails.
+func Run(ctx context.Context, baseURL string, opts Options) (*Report, error) {
+	c := newAPIClient(baseURL)
+	report := &Report{Suite: "bbs-node-api"}
+
+	for _, chk := range checks {
+		start := time.Now()
+		err := chk.Run(ctx, c, opts)
+		res := Result{Name: chk.Name, Duration: time.Since(start)}
+		switch {
+		case err == nil:
+			res.Passed = true
+		case errors.Is(err, errSkip):
+			res.Skipped = true
+			res.Err = err.Error()
+		default:
+			res.Err = err.Error()
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+// apiClient is a thin JSON HTTP client over a bbs-node's /api/v1 surface,
+// deliberately dumb: it does no retries or auth, since conformance checks
+// care about exactly what status code and body a single request gets back.
+type apiClient struct {
+	baseURL string
+	hc      *http.Client
+}
+
+func newAPIClient(baseURL string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		hc:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do issues method path with an optional JSON-encoded body, decoding the
+// response body into out (if non-nil) as JSON. The returned status is valid
+// even when err is non-nil from a decode failure, so callers can still
+// assert on status codes for non-JSON error bodies.
+func (c *apiClient) do(ctx context.Context, method, path string, body, out any) (status int, err error) {
+	var reqBody io.Reader
+	if body != nil {
+		b, merr := json.Marshal(body)
+		if merr != nil {
+			return 0, fmt.Errorf("marshal %s %s request: %w", method, path, merr)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	if derr := json.NewDecoder(resp.Body).Decode(out); derr != nil {
+		return resp.StatusCode, fmt.Errorf("decode %s %s response: %w", method, path, derr)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,95 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestRunAgainstEphemeralClientNode(t *testing.T) {
+	h, err := StartEphemeralNode("client")
+	if err != nil {
+		t.Fatalf("StartEphemeralNode: %v", err)
+	}
+	defer h.Close()
+
+	report, err := Run(context.Background(), h.BaseURL, Options{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := report.Failed(); n != 0 {
+		t.Fatalf("expected no failures against a freshly started client node, got %d:\n%s", n, report.Summary())
+	}
+}
+
+func TestRunAgainstEphemeralFullNodeWithFixtures(t *testing.T) {
+	h, err := StartEphemeralNode("full")
+	if err != nil {
+		t.Fatalf("StartEphemeralNode: %v", err)
+	}
+	defer h.Close()
+
+	ctx := context.Background()
+	carPath := writeFixtureCAR(t, ctx, h)
+
+	boardID, boardMetaCID, err := h.LoadFixturesCAR(ctx, carPath)
+	if err != nil {
+		t.Fatalf("LoadFixturesCAR: %v", err)
+	}
+	if boardID == "" || boardMetaCID == "" {
+		t.Fatalf("LoadFixturesCAR: got empty boardID/boardMetaCID")
+	}
+
+	report, err := Run(ctx, h.BaseURL, Options{FixtureBoardID: boardID})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := report.Failed(); n != 0 {
+		t.Fatalf("expected no failures against a fixture-seeded full node, got %d:\n%s", n, report.Summary())
+	}
+}
+
+// writeFixtureCAR signs a fresh BoardMeta directly against h's storage (the
+// way `bbs-node init-board` would against a real node) and exports it to a
+// CAR file on disk, exercising the exact path a --fixtures-car would have
+// been produced by offline.
+func writeFixtureCAR(t *testing.T, ctx context.Context, h *Harness) string {
+	t.Helper()
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	bm := &types.BoardMeta{
+		Version:     1,
+		Type:        types.TypeBoardMeta,
+		BoardID:     "bbs.conformance.fixtures",
+		Title:       "Conformance Fixtures",
+		Description: "board seeded for bbs/conformance's own tests",
+		CreatedAt:   types.NowUTC(),
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+	cid, err := h.Storage.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Storage.ExportBoard(ctx, cid, &buf); err != nil {
+		t.Fatalf("ExportBoard: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixtures.car")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write fixtures car: %v", err)
+	}
+	return path
+}
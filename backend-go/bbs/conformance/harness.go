@@ -0,0 +1,152 @@
+// Package conformance runs a reproducible black-box test suite against a
+// bbs-node HTTP API — either one this package spins up itself in-process
+// (StartEphemeralNode) against a mock flex-ipfs, or an already-running node
+// reachable over HTTP (pass its base URL straight to Run). It's modeled on
+// gateway-conformance's approach of exercising the documented API surface
+// from outside the implementation, so multiple bbs-node builds (or a
+// from-scratch reimplementation) can be checked against the same suite.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"flex-bbs/backend-go/bbs/api"
+	"flex-bbs/backend-go/bbs/attachments"
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/flexipfs"
+	bbsindexer "flex-bbs/backend-go/bbs/indexer"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/storage/car"
+)
+
+// Harness is an ephemeral bbs-node wired up for conformance testing: a real
+// api.Server backed by real bbs/storage, config, and (for indexer/full
+// roles) bbs/indexer, but pointed at a mockFlexIPFS instead of a real
+// Flexible-IPFS deployment. Close tears down both httptest servers and the
+// scratch data directory.
+type Harness struct {
+	BaseURL string
+	Role    string
+	Storage *storage.Storage
+	Boards  *config.BoardsStore
+
+	httpSrv *httptest.Server
+	flexSrv *httptest.Server
+	dataDir string
+}
+
+// StartEphemeralNode brings up a Harness in role (client, indexer, archiver,
+// or full — see cmd/bbs-node's -role flag), following the same
+// Storage/Boards/Indexer/Attachments wiring cmd/bbs-node/main.go does for a
+// real node, minus flex-ipfs autostart and the background sync/archive/
+// publish loops this suite has no need to exercise.
+func StartEphemeralNode(role string) (*Harness, error) {
+	switch role {
+	case "client", "indexer", "archiver", "full":
+	default:
+		return nil, fmt.Errorf("conformance: unknown role %q (want client, indexer, archiver, or full)", role)
+	}
+
+	dataDir, err := os.MkdirTemp("", "bbs-conformance-")
+	if err != nil {
+		return nil, fmt.Errorf("conformance: scratch data dir: %w", err)
+	}
+
+	flexSrv := newMockFlexIPFS()
+	st := storage.New(flexipfs.New(flexSrv.URL + "/api/v0"))
+
+	boards := config.NewBoardsStore(filepath.Join(dataDir, "boards.json"))
+	if err := boards.Load(); err != nil {
+		flexSrv.Close()
+		os.RemoveAll(dataDir)
+		return nil, fmt.Errorf("conformance: load boards store: %w", err)
+	}
+
+	var ix *bbsindexer.Indexer
+	if role == "indexer" || role == "full" {
+		ix, err = bbsindexer.Open(":memory:", st)
+		if err != nil {
+			flexSrv.Close()
+			os.RemoveAll(dataDir)
+			return nil, fmt.Errorf("conformance: open indexer: %w", err)
+		}
+	}
+
+	attStore, err := attachments.Open(filepath.Join(dataDir, "attachments"))
+	if err != nil {
+		flexSrv.Close()
+		os.RemoveAll(dataDir)
+		return nil, fmt.Errorf("conformance: open attachments store: %w", err)
+	}
+
+	srv := &api.Server{
+		Role:        role,
+		Storage:     st,
+		Boards:      boards,
+		Indexer:     ix,
+		Attachments: attStore,
+	}
+	httpSrv := httptest.NewServer(srv.Handler())
+
+	return &Harness{
+		BaseURL: httpSrv.URL,
+		Role:    role,
+		Storage: st,
+		Boards:  boards,
+		httpSrv: httpSrv,
+		flexSrv: flexSrv,
+		dataDir: dataDir,
+	}, nil
+}
+
+// Close shuts down both httptest servers and removes the scratch data
+// directory.
+func (h *Harness) Close() {
+	h.httpSrv.Close()
+	h.flexSrv.Close()
+	os.RemoveAll(h.dataDir)
+}
+
+// LoadFixturesCAR imports a CAR-style archive (the same format
+// storage.ExportBoard writes and storage.ImportBoard reads) into h's
+// storage and registers the resulting board in h.Boards, returning the
+// board's ID and current BoardMetaCID so checks.go can exercise read-path
+// endpoints (getBoard, listThreads, ...) against known fixture content.
+//
+// storage.ImportBoard only returns the boardID, not the BoardMetaCID it
+// just imported, because Storage itself has no boardID->CID registry (see
+// config.BoardsStore for that, one layer up). Rather than changing that
+// method's signature for this one caller, LoadFixturesCAR peeks the
+// archive's own root CID list first: ExportBoard always writes the
+// BoardMeta CID as roots[0], and since the store is content-addressed,
+// re-saving the same BoardMeta bytes on import reproduces that exact CID.
+func (h *Harness) LoadFixturesCAR(ctx context.Context, path string) (boardID, boardMetaCID string, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("conformance: read fixtures car %s: %w", path, err)
+	}
+
+	cr, err := car.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", "", fmt.Errorf("conformance: read fixtures car header: %w", err)
+	}
+	if len(cr.Roots) == 0 {
+		return "", "", fmt.Errorf("conformance: fixtures car %s has no root CIDs", path)
+	}
+	boardMetaCID = cr.Roots[0]
+
+	boardID, err = h.Storage.ImportBoard(ctx, bytes.NewReader(b))
+	if err != nil {
+		return "", "", fmt.Errorf("conformance: import fixtures car %s: %w", path, err)
+	}
+
+	if err := h.Boards.Upsert(boardID, boardMetaCID); err != nil {
+		return "", "", fmt.Errorf("conformance: register imported board %s: %w", boardID, err)
+	}
+	return boardID, boardMetaCID, nil
+}
@@ -0,0 +1,85 @@
+package conformance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// mockFlexRecord is one put value plus the tags it was stored under, the
+// same shape bbs/storage's own fake flex-ipfs test servers use (see e.g.
+// storage.newFakePointerFlex) — getbyattrs only ever needs to match on
+// tags here, never attrs, since nothing in this suite queries by attr
+// alone.
+type mockFlexRecord struct {
+	value string
+	tags  []string
+}
+
+// newMockFlexIPFS starts an in-process, content-addressed fake of
+// Flexible-IPFS's HTTP API backing an ephemeral conformance node (see
+// StartEphemeralNode): just enough of put/get/getbyattrs/peerlist for
+// bbs/storage to operate against, with no persistence and no real DHT.
+// It's deliberately the same shape as the *_test.go fakes scattered across
+// bbs/storage rather than a new design, since this package needs the
+// identical behavior outside of a _test.go file (a bbs-node subcommand
+// can't import test-only helpers).
+func newMockFlexIPFS() *httptest.Server {
+	var (
+		mu    sync.Mutex
+		byCID = map[string]mockFlexRecord{}
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_ = json.NewEncoder(w).Encode("peer1")
+
+		case "/api/v0/dht/putvaluewithattr":
+			q := r.URL.Query()
+			value := q.Get("value")
+			sum := sha256.Sum256([]byte(value))
+			cid := "baf_" + hex.EncodeToString(sum[:16])
+			var tags []string
+			if raw := q.Get("tags"); raw != "" {
+				tags = strings.Split(raw, ",")
+			}
+			mu.Lock()
+			byCID[cid] = mockFlexRecord{value: value, tags: tags}
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": cid})
+
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			mu.Lock()
+			rec, ok := byCID[cid]
+			mu.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(rec.value)
+
+		case "/api/v0/dht/getbyattrs":
+			wantTag := r.URL.Query().Get("tags")
+			var matches []string
+			mu.Lock()
+			for cid, rec := range byCID {
+				for _, tag := range rec.tags {
+					if tag == wantTag {
+						matches = append(matches, cid)
+						break
+					}
+				}
+			}
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(matches)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
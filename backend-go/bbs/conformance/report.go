@@ -0,0 +1,91 @@
+package conformance
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuite/junitTestCase mirror just enough of the JUnit XML schema
+// (the de facto CI-matrix lingua franca) for a single flat suite — this
+// report has no nested suites or properties, so there's nothing else to
+// model.
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+	Skipped *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnitXML renders r as a JUnit XML report named suiteName, suitable for a
+// CI matrix job (one run per role) to publish as a standard test report.
+func (r *Report) JUnitXML(suiteName string) ([]byte, error) {
+	suite := junitTestSuite{Name: suiteName, Tests: len(r.Results)}
+	for _, res := range r.Results {
+		tc := junitTestCase{Name: res.Name, Time: res.Duration.Seconds()}
+		switch {
+		case res.Skipped:
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{Message: res.Err}
+		case !res.Passed:
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: "check failed", Text: res.Err}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("conformance: marshal junit xml: %w", err)
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// Summary renders a short human-readable pass/fail/skip report, one line
+// per check, for a terminal or CI log.
+func (r *Report) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d checks, %d failed, %d skipped\n", r.Suite, len(r.Results), r.Failed(), countSkipped(r.Results))
+	for _, res := range r.Results {
+		status := "PASS"
+		detail := ""
+		switch {
+		case res.Skipped:
+			status = "SKIP"
+			detail = " (" + res.Err + ")"
+		case !res.Passed:
+			status = "FAIL"
+			detail = ": " + res.Err
+		}
+		fmt.Fprintf(&sb, "  [%s] %s (%s)%s\n", status, res.Name, res.Duration, detail)
+	}
+	return sb.String()
+}
+
+func countSkipped(results []Result) int {
+	n := 0
+	for _, res := range results {
+		if res.Skipped {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AlgXChaCha20Poly1305 is the only Envelope.Alg Seal produces and Open
+// accepts. XChaCha20-Poly1305's 24-byte nonce is large enough to generate
+// at random per-seal without a collision-tracking scheme, unlike plain
+// ChaCha20-Poly1305's 12-byte nonce.
+const AlgXChaCha20Poly1305 = "xchacha20poly1305"
+
+// Envelope is the on-wire form of an encrypted Post.Body.Content (see
+// bbs/types.Post.Encrypt): JSON with every byte field base64-encoded
+// (standard alphabet, padded).
+type Envelope struct {
+	Alg   string `json:"alg"`
+	KID   string `json:"kid"`
+	Nonce string `json:"nonce"`
+	CT    string `json:"ct"`
+	AD    string `json:"ad"`
+}
+
+// Seal encrypts plaintext under ring's active key, binding ad as
+// associated data (authenticated but not encrypted — Open rejects the
+// envelope if ad doesn't match what Seal bound), and returns the
+// resulting Envelope.
+func Seal(ring *KeyRing, plaintext, ad []byte) (Envelope, error) {
+	label, key := ring.Active()
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("crypto: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Envelope{}, fmt.Errorf("crypto: generate nonce: %w", err)
+	}
+	ct := aead.Seal(nil, nonce, plaintext, ad)
+	return Envelope{
+		Alg:   AlgXChaCha20Poly1305,
+		KID:   label,
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		CT:    base64.StdEncoding.EncodeToString(ct),
+		AD:    base64.StdEncoding.EncodeToString(ad),
+	}, nil
+}
+
+// Open decrypts env using ring's key for env.KID — the key active when it
+// was sealed, which need not be ring's current active key — and checks
+// that ad matches the associated data Seal bound the ciphertext to.
+func Open(ring *KeyRing, env Envelope, ad []byte) ([]byte, error) {
+	if env.Alg != AlgXChaCha20Poly1305 {
+		return nil, fmt.Errorf("crypto: unsupported alg %q", env.Alg)
+	}
+	key, ok := ring.Lookup(env.KID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyLabel, env.KID)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decode nonce: %w", err)
+	}
+	ct, err := base64.StdEncoding.DecodeString(env.CT)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decode ciphertext: %w", err)
+	}
+	envAD, err := base64.StdEncoding.DecodeString(env.AD)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decode associated data: %w", err)
+	}
+	if !bytes.Equal(envAD, ad) {
+		return nil, errors.New("crypto: associated data mismatch")
+	}
+	pt, err := aead.Open(nil, nonce, ct, envAD)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: open: %w", err)
+	}
+	return pt, nil
+}
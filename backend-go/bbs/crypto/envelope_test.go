@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testKeyRing(t *testing.T, label string) *KeyRing {
+	t.Helper()
+	key := bytes.Repeat([]byte{0x11}, KeySize)
+	ring, err := NewKeyRing(label, key)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	plaintext := []byte("hello, board")
+	ad := []byte("thread-1\x00\x00createdAt")
+
+	env, err := Seal(ring, plaintext, ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if env.Alg != AlgXChaCha20Poly1305 {
+		t.Fatalf("Alg = %q, want %q", env.Alg, AlgXChaCha20Poly1305)
+	}
+	if env.KID != "k1" {
+		t.Fatalf("KID = %q, want %q", env.KID, "k1")
+	}
+
+	got, err := Open(ring, env, ad)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open = %q, want %q", got, plaintext)
+	}
+}
+
+func TestOpen_RejectsMismatchedAssociatedData(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	env, err := Seal(ring, []byte("secret"), []byte("thread-1\x00\x00t1"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := Open(ring, env, []byte("thread-2\x00\x00t1")); err == nil {
+		t.Fatalf("expected Open to reject mismatched associated data")
+	}
+}
+
+func TestOpen_RejectsTamperedCiphertext(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	ad := []byte("thread-1\x00\x00t1")
+	env, err := Seal(ring, []byte("secret"), ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	env.CT = env.CT[:len(env.CT)-4] + "AAAA"
+	if _, err := Open(ring, env, ad); err == nil {
+		t.Fatalf("expected Open to reject tampered ciphertext")
+	}
+}
+
+func TestOpen_AfterRotationUsesRetiredKey(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	ad := []byte("thread-1\x00\x00t1")
+	env, err := Seal(ring, []byte("secret"), ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if err := ring.AddKey("k2", bytes.Repeat([]byte{0x22}, KeySize)); err != nil {
+		t.Fatalf("AddKey: %v", err)
+	}
+	if err := ring.Rotate("k2"); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	// Old ciphertext, sealed under k1, must still open even though k2 is
+	// now active.
+	got, err := Open(ring, env, ad)
+	if err != nil {
+		t.Fatalf("Open after rotation: %v", err)
+	}
+	if string(got) != "secret" {
+		t.Fatalf("Open = %q, want %q", got, "secret")
+	}
+
+	// New ciphertext seals under the now-active k2.
+	env2, err := Seal(ring, []byte("secret2"), ad)
+	if err != nil {
+		t.Fatalf("Seal after rotation: %v", err)
+	}
+	if env2.KID != "k2" {
+		t.Fatalf("KID = %q, want %q", env2.KID, "k2")
+	}
+}
+
+func TestOpen_RejectsUnknownKeyLabel(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	ad := []byte("thread-1\x00\x00t1")
+	env, err := Seal(ring, []byte("secret"), ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	env.KID = "never-registered"
+	if _, err := Open(ring, env, ad); err == nil {
+		t.Fatalf("expected Open to reject an unknown key label")
+	}
+}
+
+func TestRotate_RejectsUnregisteredLabel(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	if err := ring.Rotate("never-added"); err == nil {
+		t.Fatalf("expected Rotate to reject a label that was never AddKey'd")
+	}
+}
+
+func TestNewKeyRing_RejectsWrongKeySize(t *testing.T) {
+	if _, err := NewKeyRing("k1", []byte("too-short")); err == nil {
+		t.Fatalf("expected NewKeyRing to reject a short key")
+	}
+}
@@ -0,0 +1,93 @@
+// Package crypto provides the envelope-encryption primitives behind
+// Post.Body confidentiality (see bbs/types.Post.Encrypt/Decrypt), following
+// the key-manager pattern from the cloudfoundry BBS encryption package: an
+// active key new ciphertext is sealed under, plus a set of retired keys
+// kept around so older ciphertext keeps decrypting after a rotation.
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// KeySize is the required length, in bytes, of every key a KeyRing holds —
+// an XChaCha20-Poly1305 key (see Seal/Open).
+const KeySize = 32
+
+var (
+	// ErrUnknownKeyLabel is returned by Rotate (and by Open, via its
+	// caller) for a label no key was ever registered under.
+	ErrUnknownKeyLabel = errors.New("crypto: unknown key label")
+)
+
+// KeyRing holds a board's (or other scope's) symmetric encryption keys,
+// keyed by label: exactly one active key new ciphertext is sealed under,
+// plus zero or more retired keys kept so ciphertext sealed before the last
+// rotation still opens. KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu          sync.RWMutex
+	activeLabel string
+	keys        map[string][]byte
+}
+
+// NewKeyRing returns a KeyRing whose active key is active, labeled
+// activeLabel. Register any retired keys afterward with AddKey.
+func NewKeyRing(activeLabel string, active []byte) (*KeyRing, error) {
+	if activeLabel == "" {
+		return nil, errors.New("crypto: active key label must not be empty")
+	}
+	if len(active) != KeySize {
+		return nil, fmt.Errorf("crypto: key %q: want %d bytes, got %d", activeLabel, KeySize, len(active))
+	}
+	return &KeyRing{
+		activeLabel: activeLabel,
+		keys:        map[string][]byte{activeLabel: append([]byte(nil), active...)},
+	}, nil
+}
+
+// AddKey registers a retired key under label, so ciphertext sealed under
+// it before a rotation can still be opened. It does not change the active
+// key — call Rotate for that.
+func (r *KeyRing) AddKey(label string, key []byte) error {
+	if label == "" {
+		return errors.New("crypto: key label must not be empty")
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("crypto: key %q: want %d bytes, got %d", label, KeySize, len(key))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[label] = append([]byte(nil), key...)
+	return nil
+}
+
+// Rotate makes the already-registered key labeled label the active one,
+// so Seal starts sealing under it. label must have been added first (via
+// NewKeyRing or AddKey); Rotate never introduces a new key.
+func (r *KeyRing) Rotate(label string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[label]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyLabel, label)
+	}
+	r.activeLabel = label
+	return nil
+}
+
+// Active returns the label and key Seal currently seals new ciphertext
+// under.
+func (r *KeyRing) Active() (label string, key []byte) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.activeLabel, r.keys[r.activeLabel]
+}
+
+// Lookup returns the key registered under label, for opening ciphertext
+// sealed under a key that isn't (or is no longer) active.
+func (r *KeyRing) Lookup(label string) (key []byte, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok = r.keys[label]
+	return key, ok
+}
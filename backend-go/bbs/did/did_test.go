@@ -0,0 +1,48 @@
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+func TestKeyResolver_RoundTrip(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	id, err := EncodeKeyDID(pub)
+	if err != nil {
+		t.Fatalf("EncodeKeyDID: %v", err)
+	}
+	if !IsDID(id) {
+		t.Fatalf("expected %q to look like a DID", id)
+	}
+
+	reg := NewRegistry()
+	got, err := reg.ResolvePublicKey(context.Background(), id)
+	if err != nil {
+		t.Fatalf("ResolvePublicKey: %v", err)
+	}
+	if !got.Equal(pub) {
+		t.Fatalf("resolved key mismatch")
+	}
+}
+
+func TestRegistry_UnsupportedMethod(t *testing.T) {
+	reg := NewRegistry()
+	_, err := reg.ResolvePublicKey(context.Background(), "did:web:example.com")
+	if err == nil {
+		t.Fatal("expected error for unregistered method")
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	cases := []string{"", "not-a-did", "did:key", "did::id"}
+	for _, c := range cases {
+		if _, _, err := Parse(c); err == nil {
+			t.Errorf("Parse(%q): expected error", c)
+		}
+	}
+}
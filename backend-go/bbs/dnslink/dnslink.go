@@ -0,0 +1,183 @@
+// Package dnslink resolves a human-memorable domain name to one or more
+// boards, modeled on IPFS's DNSLink: a TXT lookup against
+// "_bbs.<domain>" whose records name either a board's current
+// BoardMetaCID directly, or a pubkey to resolve through the BoardPointer
+// subsystem (see bbs/storage's PublishBoardPointer/ResolveBoardPointer).
+// This gives a board a name that can be delegated and updated without
+// republishing every downstream reference to its (opaque, content-
+// addressed) CID.
+package dnslink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BoardRef is one board a name resolved to.
+type BoardRef struct {
+	BoardID      string
+	BoardMetaCID string
+}
+
+// PointerResolver is the subset of storage.Storage's BoardPointer API a
+// Resolver needs to follow a "bbs-pointer=<pubkey>" TXT entry to its
+// current BoardMetaCID; storage.Storage satisfies this directly, so
+// Resolver never needs to import bbs/storage itself.
+type PointerResolver interface {
+	ResolveBoardPointer(ctx context.Context, pubKey, boardID string) (cid string, seq int64, err error)
+}
+
+// LookupTXT is the subset of net.Resolver's API Resolver needs, so tests
+// (and anything that wants to point this at a fake zone) can inject one
+// without running a real DNS server.
+type LookupTXT func(ctx context.Context, name string) ([]string, error)
+
+const (
+	// recordPrefix is prepended to the domain being resolved, mirroring
+	// IPFS's own DNSLink convention of querying _dnslink.<domain> rather
+	// than <domain> itself — a subdomain reserved for this purpose so it
+	// can coexist with a domain's other DNS records.
+	recordPrefix = "_bbs."
+
+	// DefaultTTL caches a successful Resolve for this long. Go's
+	// net.Resolver.LookupTXT doesn't surface a TXT record's own TTL (the
+	// stdlib resolver discards it), so Resolver can't honor the record's
+	// actual TTL the way a full DNS client could; DefaultTTL is the
+	// practical stand-in, overridable via Resolver.TTL.
+	DefaultTTL = 5 * time.Minute
+)
+
+// Resolver resolves dnslink names to BoardRefs, caching results for TTL (or
+// DefaultTTL if unset).
+type Resolver struct {
+	Lookup  LookupTXT
+	Pointer PointerResolver
+	TTL     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	refs    []BoardRef
+	expires time.Time
+}
+
+// NewResolver returns a Resolver using the system resolver for DNS lookups
+// and pr (typically a *storage.Storage) to follow bbs-pointer entries. pr
+// may be nil if the caller only ever expects bbs-meta (direct CID) entries;
+// a bbs-pointer entry then fails to resolve with a descriptive error.
+func NewResolver(pr PointerResolver) *Resolver {
+	return &Resolver{
+		Lookup: func(ctx context.Context, name string) ([]string, error) {
+			return net.DefaultResolver.LookupTXT(ctx, name)
+		},
+		Pointer: pr,
+	}
+}
+
+// Resolve looks up name's "_bbs.<name>" TXT records (name may optionally
+// carry a "dnslink://" prefix, matching how it'd appear in boards.json or
+// on the CLI) and expands each record into a BoardRef: a
+// "bbs-board=<id> bbs-meta=<cid>" record directly, or a
+// "bbs-board=<id> bbs-pointer=<pubkey>" record by following r.Pointer.
+// Records that parse to neither shape are skipped rather than failing the
+// whole resolution, since a domain may carry unrelated TXT records
+// alongside its bbs-board ones. A successful result is cached for r.TTL.
+func (r *Resolver) Resolve(ctx context.Context, name string) ([]BoardRef, error) {
+	name = strings.TrimPrefix(name, "dnslink://")
+	if name == "" {
+		return nil, fmt.Errorf("dnslink: empty name")
+	}
+
+	if refs, ok := r.cached(name); ok {
+		return refs, nil
+	}
+
+	txts, err := r.Lookup(ctx, recordPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("dnslink: lookup %s%s: %w", recordPrefix, name, err)
+	}
+
+	var refs []BoardRef
+	for _, txt := range txts {
+		ref, ok, err := r.parseRecord(ctx, txt)
+		if err != nil {
+			return nil, fmt.Errorf("dnslink: %s%s: %w", recordPrefix, name, err)
+		}
+		if ok {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("dnslink: %s%s has no usable bbs-board records", recordPrefix, name)
+	}
+
+	r.store(name, refs)
+	return refs, nil
+}
+
+// parseRecord parses one TXT record's space-separated key=value pairs into
+// a BoardRef. ok is false (with a nil error) for a record that carries no
+// bbs-board entry at all, since that's an unrelated TXT record rather than
+// a malformed one.
+func (r *Resolver) parseRecord(ctx context.Context, txt string) (ref BoardRef, ok bool, err error) {
+	fields := make(map[string]string)
+	for _, tok := range strings.Fields(txt) {
+		k, v, found := strings.Cut(tok, "=")
+		if !found {
+			continue
+		}
+		fields[k] = v
+	}
+
+	boardID := fields["bbs-board"]
+	if boardID == "" {
+		return BoardRef{}, false, nil
+	}
+
+	if meta := fields["bbs-meta"]; meta != "" {
+		return BoardRef{BoardID: boardID, BoardMetaCID: meta}, true, nil
+	}
+
+	pubKey := fields["bbs-pointer"]
+	if pubKey == "" {
+		return BoardRef{}, false, fmt.Errorf("record %q has bbs-board but neither bbs-meta nor bbs-pointer", txt)
+	}
+	if r.Pointer == nil {
+		return BoardRef{}, false, fmt.Errorf("record %q needs a bbs-pointer resolver, none configured", txt)
+	}
+	cid, _, err := r.Pointer.ResolveBoardPointer(ctx, pubKey, boardID)
+	if err != nil {
+		return BoardRef{}, false, fmt.Errorf("resolve pointer for board %s: %w", boardID, err)
+	}
+	return BoardRef{BoardID: boardID, BoardMetaCID: cid}, true, nil
+}
+
+func (r *Resolver) cached(name string) ([]BoardRef, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.cache[name]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.refs, true
+}
+
+func (r *Resolver) store(name string, refs []BoardRef) {
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cache == nil {
+		r.cache = make(map[string]cacheEntry)
+	}
+	r.cache[name] = cacheEntry{refs: refs, expires: time.Now().Add(ttl)}
+}
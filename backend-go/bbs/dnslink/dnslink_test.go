@@ -0,0 +1,113 @@
+package dnslink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func fakeLookup(zone map[string][]string) LookupTXT {
+	return func(ctx context.Context, name string) ([]string, error) {
+		txts, ok := zone[name]
+		if !ok {
+			return nil, fmt.Errorf("no such TXT record: %s", name)
+		}
+		return txts, nil
+	}
+}
+
+func TestResolveDirectMetaRecord(t *testing.T) {
+	r := &Resolver{
+		Lookup: fakeLookup(map[string][]string{
+			"_bbs.bbs.example.org": {"bbs-board=bbs.general bbs-meta=baf_meta_1"},
+		}),
+	}
+
+	refs, err := r.Resolve(context.Background(), "dnslink://bbs.example.org")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(refs) != 1 || refs[0] != (BoardRef{BoardID: "bbs.general", BoardMetaCID: "baf_meta_1"}) {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+}
+
+type fakePointerResolver struct {
+	cid string
+	err error
+}
+
+func (f *fakePointerResolver) ResolveBoardPointer(ctx context.Context, pubKey, boardID string) (string, int64, error) {
+	if f.err != nil {
+		return "", 0, f.err
+	}
+	return f.cid, 3, nil
+}
+
+func TestResolvePointerRecordFollowsPointerSubsystem(t *testing.T) {
+	r := &Resolver{
+		Lookup: fakeLookup(map[string][]string{
+			"_bbs.bbs.example.org": {"bbs-board=bbs.general bbs-pointer=ed25519:abc"},
+		}),
+		Pointer: &fakePointerResolver{cid: "baf_meta_latest"},
+	}
+
+	refs, err := r.Resolve(context.Background(), "bbs.example.org")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(refs) != 1 || refs[0] != (BoardRef{BoardID: "bbs.general", BoardMetaCID: "baf_meta_latest"}) {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+}
+
+func TestResolveSkipsUnrelatedTXTRecords(t *testing.T) {
+	r := &Resolver{
+		Lookup: fakeLookup(map[string][]string{
+			"_bbs.bbs.example.org": {
+				"v=spf1 -all",
+				"bbs-board=bbs.general bbs-meta=baf_meta_1",
+			},
+		}),
+	}
+
+	refs, err := r.Resolve(context.Background(), "bbs.example.org")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("expected only the bbs-board record to be kept, got %+v", refs)
+	}
+}
+
+func TestResolveCachesResult(t *testing.T) {
+	calls := 0
+	r := &Resolver{
+		Lookup: func(ctx context.Context, name string) ([]string, error) {
+			calls++
+			return []string{"bbs-board=bbs.general bbs-meta=baf_meta_1"}, nil
+		},
+		TTL: time.Hour,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Resolve(context.Background(), "bbs.example.org"); err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 DNS lookup across repeated Resolve calls, got %d", calls)
+	}
+}
+
+func TestResolveMissingPointerResolverErrors(t *testing.T) {
+	r := &Resolver{
+		Lookup: fakeLookup(map[string][]string{
+			"_bbs.bbs.example.org": {"bbs-board=bbs.general bbs-pointer=ed25519:abc"},
+		}),
+	}
+	if _, err := r.Resolve(context.Background(), "bbs.example.org"); err == nil {
+		t.Fatalf("expected an error resolving a bbs-pointer record with no Pointer configured")
+	}
+}
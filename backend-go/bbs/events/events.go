@@ -0,0 +1,213 @@
+// Package events is the storage layer's publish/subscribe chokepoint: a
+// small Bus interface that bbs/storage.Storage publishes to right after a
+// successful DHT put, so downstream code (websocket push, webhook
+// fan-out, notification daemons) can react without polling SQLite. The
+// default Bus is an in-process channel broker; NewBus's factory lets a
+// caller plug in an adapter over NATS/Redis/etc instead, without
+// bbs/storage needing to know which.
+//
+// This is deliberately a separate, lower-level hub from
+// bbs/indexer.Subscribe: that one fires once an indexed write has
+// committed to the indexer's SQLite database, while this one fires the
+// moment content lands in flex-ipfs, before (or even without) any indexer
+// ever seeing it.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Type identifies the kind of change an Event describes.
+type Type string
+
+const (
+	PostCreated      Type = "post_created"
+	ThreadCreated    Type = "thread_created"
+	BoardMetaUpdated Type = "board_meta_updated"
+	BoardLogAppended Type = "board_log_appended"
+)
+
+// Event is one storage-layer change, published by Storage's Save* methods
+// once the value they wrote is durably addressable. Fields not meaningful
+// to a given Type are left zero (e.g. ThreadID on a BoardMetaUpdated
+// event).
+type Event struct {
+	Type         Type   `json:"type"`
+	BoardID      string `json:"boardId"`
+	ThreadID     string `json:"threadId,omitempty"`
+	CID          string `json:"cid"`
+	AuthorPubKey string `json:"authorPubKey,omitempty"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+}
+
+// Filter narrows a subscription to the events a caller cares about; a
+// zero-value Filter matches everything. Every set field is a conjunction
+// (BoardID AND ThreadID AND ...); Types is a disjunction within itself
+// (any of these types).
+type Filter struct {
+	BoardID      string
+	ThreadID     string
+	AuthorPubKey string
+	Types        []Type
+}
+
+func (f Filter) matches(e Event) bool {
+	if f.BoardID != "" && f.BoardID != e.BoardID {
+		return false
+	}
+	if f.ThreadID != "" && f.ThreadID != e.ThreadID {
+		return false
+	}
+	if f.AuthorPubKey != "" && f.AuthorPubKey != e.AuthorPubKey {
+		return false
+	}
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if t == e.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrBusClosed is returned by Publish/Subscribe once Close has run.
+var ErrBusClosed = fmt.Errorf("events: bus closed")
+
+// Bus publishes Events and lets callers subscribe to a filtered stream of
+// them. Publish must not block on a slow subscriber — implementations
+// apply backpressure by dropping slow subscribers, not by blocking
+// publishers; see InProcessBus.
+type Bus interface {
+	Publish(ctx context.Context, e Event) error
+	Subscribe(ctx context.Context, filter Filter) (<-chan Event, error)
+}
+
+// defaultBufferSize bounds each subscriber's channel when an InProcessBus
+// is constructed with bufferSize <= 0; see InProcessBus.Subscribe.
+const defaultBufferSize = 64
+
+// InProcessBus is the default Bus: an in-process channel broker fanning
+// out Events to many subscribers, each with its own bounded buffer, so one
+// slow consumer can neither block another nor block Publish.
+type InProcessBus struct {
+	mu         sync.Mutex
+	subs       map[int]*subscription
+	nextSubID  int
+	bufferSize int
+	closed     bool
+
+	// droppedSubscribersTotal counts subscribers evicted for falling
+	// behind; see Publish.
+	droppedSubscribersTotal int64
+}
+
+type subscription struct {
+	ch     chan Event
+	filter Filter
+}
+
+// NewInProcessBus constructs an InProcessBus. bufferSize bounds each
+// subscriber's channel; bufferSize <= 0 uses defaultBufferSize.
+func NewInProcessBus(bufferSize int) *InProcessBus {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &InProcessBus{subs: make(map[int]*subscription), bufferSize: bufferSize}
+}
+
+// Publish fans e out to every subscriber whose filter matches it. A
+// subscriber whose buffer is already full when its turn comes is evicted
+// — its channel is closed and droppedSubscribersTotal incremented —
+// rather than letting it block every other subscriber or the caller
+// publishing the event. Events have no redelivery guarantee: a dropped
+// subscriber only misses events from the point it was dropped, not
+// before.
+func (b *InProcessBus) Publish(ctx context.Context, e Event) error {
+	_ = ctx
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	for id, sub := range b.subs {
+		if !sub.filter.matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(b.subs, id)
+			close(sub.ch)
+			atomic.AddInt64(&b.droppedSubscribersTotal, 1)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers filter and returns a channel of matching events. The
+// channel is closed when ctx is canceled, when Close runs, or — if this
+// subscriber falls far enough behind that its buffer fills — by the bus
+// itself; see Publish. Callers should range over the channel rather than
+// assume it stays open for the lifetime of ctx.
+func (b *InProcessBus) Subscribe(ctx context.Context, filter Filter) (<-chan Event, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrBusClosed
+	}
+
+	id := b.nextSubID
+	b.nextSubID++
+	sub := &subscription{ch: make(chan Event, b.bufferSize), filter: filter}
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if s, ok := b.subs[id]; ok {
+				delete(b.subs, id)
+				close(s.ch)
+			}
+		})
+	}
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+	return sub.ch, nil
+}
+
+// DroppedSubscribersTotal returns the number of subscribers this bus has
+// evicted for falling behind, for a caller to expose on its own metrics
+// registry.
+func (b *InProcessBus) DroppedSubscribersTotal() int64 {
+	return atomic.LoadInt64(&b.droppedSubscribersTotal)
+}
+
+// Close shuts down every subscriber. Further Publish/Subscribe calls
+// return ErrBusClosed.
+func (b *InProcessBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for id, sub := range b.subs {
+		delete(b.subs, id)
+		close(sub.ch)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInProcessBusDeliversInOrder(t *testing.T) {
+	b := NewInProcessBus(0)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := b.Subscribe(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	want := []Event{
+		{Type: PostCreated, BoardID: "b1", CID: "cid1"},
+		{Type: PostCreated, BoardID: "b1", CID: "cid2"},
+		{Type: ThreadCreated, BoardID: "b1", CID: "cid3"},
+	}
+	for _, e := range want {
+		if err := b.Publish(ctx, e); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	for i, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Fatalf("event %d: got %+v, want %+v", i, got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: timed out waiting for delivery", i)
+		}
+	}
+}
+
+func TestInProcessBusFilterMatching(t *testing.T) {
+	b := NewInProcessBus(0)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := b.Subscribe(ctx, Filter{BoardID: "bbs.general", Types: []Type{PostCreated}})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	_ = b.Publish(ctx, Event{Type: PostCreated, BoardID: "bbs.offtopic", CID: "skip1"})
+	_ = b.Publish(ctx, Event{Type: ThreadCreated, BoardID: "bbs.general", CID: "skip2"})
+	_ = b.Publish(ctx, Event{Type: PostCreated, BoardID: "bbs.general", CID: "match"})
+
+	select {
+	case got := <-ch:
+		if got.CID != "match" {
+			t.Fatalf("expected the only matching event, got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for the matching event")
+	}
+
+	select {
+	case got, ok := <-ch:
+		if ok {
+			t.Fatalf("expected no further events, got %+v", got)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInProcessBusDropsSlowSubscriberWithoutBlockingPublish(t *testing.T) {
+	b := NewInProcessBus(1)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := b.Subscribe(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			_ = b.Publish(ctx, Event{Type: PostCreated, CID: "cid"})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Publish blocked on a slow subscriber instead of dropping it")
+	}
+
+	if b.DroppedSubscribersTotal() != 1 {
+		t.Fatalf("expected exactly 1 dropped subscriber, got %d", b.DroppedSubscribersTotal())
+	}
+
+	// The subscriber's channel should now be closed.
+	drained := 0
+	for range ch {
+		drained++
+	}
+	_ = drained
+}
+
+func TestInProcessBusSubscribeUnsubscribesOnContextCancel(t *testing.T) {
+	b := NewInProcessBus(0)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := b.Subscribe(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("expected channel to close once ctx is canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for channel to close")
+	}
+}
+
+func TestInProcessBusPublishAfterCloseReturnsError(t *testing.T) {
+	b := NewInProcessBus(0)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := b.Publish(context.Background(), Event{Type: PostCreated}); err != ErrBusClosed {
+		t.Fatalf("expected ErrBusClosed, got %v", err)
+	}
+	if _, err := b.Subscribe(context.Background(), Filter{}); err != ErrBusClosed {
+		t.Fatalf("expected ErrBusClosed, got %v", err)
+	}
+}
+
+func TestNewDefaultsToInProcessBus(t *testing.T) {
+	bus, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, ok := bus.(*InProcessBus); !ok {
+		t.Fatalf("expected New with a zero Config to return an *InProcessBus, got %T", bus)
+	}
+}
+
+func TestNewUnknownKindErrors(t *testing.T) {
+	if _, err := New(Config{Kind: "nats"}); err == nil {
+		t.Fatalf("expected New to reject an unregistered kind")
+	}
+}
+
+func TestRegisterAdapterAndDispatch(t *testing.T) {
+	const kind = "test-adapter"
+	RegisterAdapter(kind, func(cfg Config) (Bus, error) {
+		return NewInProcessBus(cfg.BufferSize), nil
+	})
+
+	bus, err := New(Config{Kind: kind})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, ok := bus.(*InProcessBus); !ok {
+		t.Fatalf("expected the registered adapter's bus, got %T", bus)
+	}
+}
@@ -0,0 +1,57 @@
+package events
+
+import "fmt"
+
+// Adapter constructs a Bus for one pluggable backend kind (e.g. "nats",
+// "redis"). Register one with RegisterAdapter during program
+// initialization; New then dispatches to it by Config.Kind.
+type Adapter func(cfg Config) (Bus, error)
+
+// Config selects and configures a Bus via New. Kind "" or "memory" always
+// resolves to an InProcessBus regardless of what's registered via
+// RegisterAdapter, so the zero Config is always a working default.
+type Config struct {
+	// Kind names the backend: "memory" (default) or an adapter registered
+	// with RegisterAdapter.
+	Kind string
+	// BufferSize is InProcessBus's per-subscriber channel size; adapters
+	// may ignore it or repurpose it as they see fit.
+	BufferSize int
+	// DSN is an adapter-specific connection string (e.g. a NATS or Redis
+	// URL); ignored by InProcessBus.
+	DSN string
+}
+
+var adapters = map[string]Adapter{}
+
+// RegisterAdapter makes kind available to New/Config.Kind. Intended to be
+// called from an adapter package's init, the same pattern
+// cmd/bbs-node's ClientOption callers use to extend a subsystem without this
+// package importing every possible backend. Registering the same kind
+// twice panics, since it almost certainly means two adapter packages were
+// imported for the same name by mistake.
+func RegisterAdapter(kind string, a Adapter) {
+	if kind == "" || kind == "memory" {
+		panic(fmt.Sprintf("events: %q is reserved for the built-in in-process bus", kind))
+	}
+	if _, exists := adapters[kind]; exists {
+		panic(fmt.Sprintf("events: adapter %q already registered", kind))
+	}
+	adapters[kind] = a
+}
+
+// New constructs a Bus per cfg. An empty or "memory" Kind returns an
+// InProcessBus; any other Kind must have been registered with
+// RegisterAdapter first.
+func New(cfg Config) (Bus, error) {
+	switch cfg.Kind {
+	case "", "memory":
+		return NewInProcessBus(cfg.BufferSize), nil
+	default:
+		a, ok := adapters[cfg.Kind]
+		if !ok {
+			return nil, fmt.Errorf("events: unknown bus kind %q (no adapter registered)", cfg.Kind)
+		}
+		return a(cfg)
+	}
+}
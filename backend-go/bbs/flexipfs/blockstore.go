@@ -0,0 +1,184 @@
+package flexipfs
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BlockStore is a local content-addressed cache keyed by CID, sitting in
+// front of Client.GetValue/PutValueWithAttr so a value already seen
+// doesn't need a round trip to /dht/getvalue or /dht/putvaluewithattr to
+// be read or written again. Implementations must be safe for concurrent
+// use.
+type BlockStore interface {
+	// Has reports whether value is cached for cid.
+	Has(cid string) bool
+
+	// Get returns the cached value for cid, or ok=false if nothing is
+	// cached for it.
+	Get(cid string) (value []byte, ok bool)
+
+	// Put caches value under cid, overwriting whatever was cached there
+	// before.
+	Put(cid string, value []byte) error
+
+	// Delete removes cid from the cache, if present. Deleting a cid that
+	// isn't cached is not an error.
+	Delete(cid string) error
+}
+
+// BlockStoreStats is a snapshot of an LRUBlockStore's Get hit/miss counts
+// since it was created, for callers (e.g. a status/metrics endpoint) that
+// want to tell whether the cache is actually earning its keep.
+type BlockStoreStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// LRUBlockStore is an in-memory BlockStore bounded to capacity entries,
+// evicting the least-recently-used one once full -- the same
+// container/list-backed design as Storage.PointerCache, just keyed by
+// CID instead of pubKey+boardID and with no TTL (a CID's content never
+// changes, so there's nothing to expire).
+type LRUBlockStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	hits     int64
+	misses   int64
+}
+
+type lruBlockItem struct {
+	cid   string
+	value []byte
+}
+
+// NewLRUBlockStore returns an LRUBlockStore holding at most capacity
+// entries.
+func NewLRUBlockStore(capacity int) *LRUBlockStore {
+	return &LRUBlockStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (s *LRUBlockStore) Has(cid string) bool {
+	_, ok := s.Get(cid)
+	return ok
+}
+
+func (s *LRUBlockStore) Get(cid string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[cid]
+	if !ok {
+		s.misses++
+		return nil, false
+	}
+	s.ll.MoveToFront(el)
+	s.hits++
+	return el.Value.(*lruBlockItem).value, true
+}
+
+func (s *LRUBlockStore) Put(cid string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[cid]; ok {
+		el.Value.(*lruBlockItem).value = value
+		s.ll.MoveToFront(el)
+		return nil
+	}
+
+	el := s.ll.PushFront(&lruBlockItem{cid: cid, value: value})
+	s.items[cid] = el
+	if s.capacity > 0 && s.ll.Len() > s.capacity {
+		if oldest := s.ll.Back(); oldest != nil {
+			s.ll.Remove(oldest)
+			delete(s.items, oldest.Value.(*lruBlockItem).cid)
+		}
+	}
+	return nil
+}
+
+func (s *LRUBlockStore) Delete(cid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[cid]; ok {
+		s.ll.Remove(el)
+		delete(s.items, cid)
+	}
+	return nil
+}
+
+// Stats returns the cache's cumulative hit/miss counts since it was
+// created.
+func (s *LRUBlockStore) Stats() BlockStoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return BlockStoreStats{Hits: s.hits, Misses: s.misses}
+}
+
+// DiskBlockStore is a BlockStore backed by loose files under Dir, sharded
+// two levels deep by the CID's first four characters (aa/bb/<cid>) so a
+// cache holding millions of CIDs doesn't end up with millions of entries
+// in one directory.
+type DiskBlockStore struct {
+	Dir string
+}
+
+// NewDiskBlockStore returns a DiskBlockStore rooted at dir. dir is
+// created lazily by Put, not by this constructor.
+func NewDiskBlockStore(dir string) *DiskBlockStore {
+	return &DiskBlockStore{Dir: dir}
+}
+
+// shardPath returns the path Put writes cid's value to: <Dir>/aa/bb/<cid>,
+// where aa and bb are cid's first two and next two characters (padded
+// with "_" for a cid shorter than four characters, which shouldn't
+// happen in practice but must not panic).
+func (s *DiskBlockStore) shardPath(cid string) string {
+	a, b := "__", "__"
+	if len(cid) >= 2 {
+		a = cid[:2]
+	}
+	if len(cid) >= 4 {
+		b = cid[2:4]
+	}
+	return filepath.Join(s.Dir, a, b, cid)
+}
+
+func (s *DiskBlockStore) Has(cid string) bool {
+	_, err := os.Stat(s.shardPath(cid))
+	return err == nil
+}
+
+func (s *DiskBlockStore) Get(cid string) ([]byte, bool) {
+	b, err := os.ReadFile(s.shardPath(cid))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func (s *DiskBlockStore) Put(cid string, value []byte) error {
+	p := s.shardPath(cid)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, value, 0o644)
+}
+
+func (s *DiskBlockStore) Delete(cid string) error {
+	err := os.Remove(s.shardPath(cid))
+	if err != nil && os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package flexipfs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLRUBlockStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewLRUBlockStore(2)
+	_ = s.Put("cid1", []byte("a"))
+	_ = s.Put("cid2", []byte("b"))
+
+	// Touch cid1 so it's more recently used than cid2.
+	if _, ok := s.Get("cid1"); !ok {
+		t.Fatal("Get(cid1) = false, want true")
+	}
+
+	_ = s.Put("cid3", []byte("c"))
+
+	stats := s.Stats()
+	if stats.Hits != 1 {
+		t.Fatalf("Hits = %d, want 1", stats.Hits)
+	}
+	if stats.Misses != 0 {
+		t.Fatalf("Misses = %d, want 0", stats.Misses)
+	}
+
+	if s.Has("cid2") {
+		t.Fatal("cid2 should have been evicted as the least recently used")
+	}
+	if !s.Has("cid1") || !s.Has("cid3") {
+		t.Fatal("cid1 and cid3 should still be cached")
+	}
+
+	if _, ok := s.Get("missing"); ok {
+		t.Fatal("Get(missing) = true, want false")
+	}
+	if got := s.Stats().Misses; got != 2 {
+		t.Fatalf("Misses after the Has probes and a miss = %d, want 2", got)
+	}
+}
+
+func TestLRUBlockStore_Delete(t *testing.T) {
+	s := NewLRUBlockStore(10)
+	_ = s.Put("cid1", []byte("a"))
+	if err := s.Delete("cid1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if s.Has("cid1") {
+		t.Fatal("cid1 should no longer be cached")
+	}
+	if err := s.Delete("never-cached"); err != nil {
+		t.Fatalf("Delete(never-cached): %v, want nil", err)
+	}
+}
+
+func TestDiskBlockStore_RoundTripAndSharding(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskBlockStore(dir)
+
+	if err := s.Put("bafkreiabcd1234", []byte("hello")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	wantPath := filepath.Join(dir, "ba", "fk", "bafkreiabcd1234")
+	if !s.Has("bafkreiabcd1234") {
+		t.Fatalf("Has = false, want true (expected shard path %s)", wantPath)
+	}
+	v, ok := s.Get("bafkreiabcd1234")
+	if !ok || string(v) != "hello" {
+		t.Fatalf("Get = %q, %v, want \"hello\", true", v, ok)
+	}
+
+	if err := s.Delete("bafkreiabcd1234"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if s.Has("bafkreiabcd1234") {
+		t.Fatal("Has after Delete = true, want false")
+	}
+	if _, ok := s.Get("nonexistent"); ok {
+		t.Fatal("Get(nonexistent) = true, want false")
+	}
+}
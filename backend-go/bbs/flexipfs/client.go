@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +22,125 @@ type Client struct {
 	BaseURL    string
 	BaseDir    string
 	HTTPClient *http.Client
+
+	// Pool, if set, makes postQuery a round-robin/failover client across
+	// multiple gateway endpoints instead of always using BaseURL: each
+	// call starts from the pool's next rotation position and, on a
+	// connection error or 5xx response, retries the next candidate
+	// endpoint before giving up. See NewWithPool.
+	Pool *EndpointPool
+
+	// Cache, if set, is consulted before GetValue hits /dht/getvalue and
+	// populated after a successful GetValue or PutValueWithAttr, so a
+	// value already seen doesn't cost a second round trip to the gateway.
+	// Nil by default, matching Storage.PointerCache's opt-in convention --
+	// a Client behaves exactly as before until one is set. See
+	// NewLRUBlockStore and NewDiskBlockStore.
+	Cache BlockStore
+
+	getDeadline      deadline
+	putDeadline      deadline
+	peerListDeadline deadline
+
+	inFlightMu  sync.Mutex
+	inFlightSeq uint64
+	inFlight    map[uint64]InFlightRequest
+
+	// watchMu guards watcher and waiters, which WatchGetData/
+	// waitForGetData/notifyGetData use to let getValueUncached's
+	// placeholder-wait loop block on a getdata file appearing instead of
+	// polling for it; see getdatawatch.go.
+	watchMu sync.Mutex
+	watcher *fsWatcher
+	waiters map[string][]chan struct{}
+}
+
+// SetDeadline sets the get, put and peer-list deadlines together,
+// equivalent to calling SetGetDeadline, SetPutDeadline and
+// SetPeerListDeadline with the same t.
+func (c *Client) SetDeadline(t time.Time) {
+	c.SetGetDeadline(t)
+	c.SetPutDeadline(t)
+	c.SetPeerListDeadline(t)
+}
+
+// SetReadDeadline bounds every GetValue and PeerList call made after it
+// returns, equivalent to calling SetGetDeadline and SetPeerListDeadline
+// with the same t. Kept alongside the more granular setters for callers
+// that want one knob for "reads" without distinguishing which read. A
+// zero Time clears it.
+func (c *Client) SetReadDeadline(t time.Time) {
+	c.SetGetDeadline(t)
+	c.SetPeerListDeadline(t)
+}
+
+// SetWriteDeadline bounds every PutValueWithAttr call made after it
+// returns; equivalent to SetPutDeadline. A zero Time clears it.
+func (c *Client) SetWriteDeadline(t time.Time) {
+	c.SetPutDeadline(t)
+}
+
+// SetGetDeadline bounds every GetValue call made after it returns: once t
+// elapses, any such call still in flight has its context canceled, the
+// same way a stuck DHT lookup would be canceled by a ctx the caller
+// passed in directly — except this deadline can be reset, extended or
+// cleared at any time, and doing so supersedes a call already in flight
+// rather than waiting for the previous value to elapse. A zero Time
+// clears it.
+func (c *Client) SetGetDeadline(t time.Time) {
+	c.getDeadline.set(t)
+}
+
+// SetPutDeadline bounds every PutValueWithAttr call made after it
+// returns; see SetGetDeadline. A zero Time clears it.
+func (c *Client) SetPutDeadline(t time.Time) {
+	c.putDeadline.set(t)
+}
+
+// SetPeerListDeadline bounds every PeerList call made after it returns
+// (including the implicit PeerList probe PutValueWithAttr makes while
+// waiting for peers); see SetGetDeadline. A zero Time clears it.
+func (c *Client) SetPeerListDeadline(t time.Time) {
+	c.peerListDeadline.set(t)
+}
+
+// InFlightRequest describes one outstanding HTTP request to the
+// Flexible-IPFS gateway, as reported by InFlight.
+type InFlightRequest struct {
+	URL       string
+	StartedAt time.Time
+}
+
+// InFlight returns a snapshot of every request c currently has open
+// against the gateway, so an operator can tell what a stuck client is
+// waiting on (which URL, since when) without attaching a profiler.
+func (c *Client) InFlight() []InFlightRequest {
+	c.inFlightMu.Lock()
+	defer c.inFlightMu.Unlock()
+	out := make([]InFlightRequest, 0, len(c.inFlight))
+	for _, r := range c.inFlight {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+func (c *Client) trackInFlight(url string) uint64 {
+	c.inFlightMu.Lock()
+	defer c.inFlightMu.Unlock()
+	c.inFlightSeq++
+	id := c.inFlightSeq
+	if c.inFlight == nil {
+		c.inFlight = make(map[uint64]InFlightRequest)
+	}
+	c.inFlight[id] = InFlightRequest{URL: url, StartedAt: time.Now()}
+	return id
+}
+
+func (c *Client) untrackInFlight(id uint64) {
+	c.inFlightMu.Lock()
+	delete(c.inFlight, id)
+	c.inFlightMu.Unlock()
 }
 
 func New(baseURL string) *Client {
@@ -32,6 +152,21 @@ func New(baseURL string) *Client {
 	}
 }
 
+// NewWithPool returns a Client that fails over across pool's endpoints
+// (typically built from discoverFlexIPFSGWEndpointPoolMdns's health-probed
+// mDNS results) rather than always talking to a single BaseURL. BaseURL is
+// still set, from pool's first endpoint, so any code path that reads it
+// directly (readGetDataValue's local-file fallback, status reporting)
+// keeps working.
+func NewWithPool(pool *EndpointPool) *Client {
+	c := New("")
+	c.Pool = pool
+	if eps := pool.Endpoints(); len(eps) > 0 {
+		c.BaseURL = strings.TrimRight(eps[0], "/")
+	}
+	return c
+}
+
 func validateAttr(attr string) error {
 	attr = strings.TrimSpace(attr)
 	if attr == "" {
@@ -74,6 +209,9 @@ func (c *Client) PutValueWithAttr(ctx context.Context, value string, attrs, tags
 		}
 	}
 
+	ctx, release := c.putDeadline.wrap(ctx)
+	defer release()
+
 	// Flexible-IPFS currently crashes on put when its peer list is empty, returning HTTP 400 with no body.
 	// Avoid triggering that by waiting briefly for peers and failing if peerlist remains empty.
 	peerWaitUntil := time.Now().Add(30 * time.Second)
@@ -155,7 +293,11 @@ func (c *Client) PutValueWithAttr(ctx context.Context, value string, attrs, tags
 			return "", err
 		}
 		if status >= 200 && status < 300 {
-			return extractCID(body)
+			cid, err := extractCID(body)
+			if err == nil && c.Cache != nil {
+				_ = c.Cache.Put(cid, []byte(value))
+			}
+			return cid, err
 		}
 
 		httpErr := httpError(status, body, header, trailer)
@@ -207,11 +349,76 @@ func (c *Client) PutValueWithAttr(ctx context.Context, value string, attrs, tags
 	}
 }
 
+// GetValue returns the value stored at cid, consulting c.Cache first (if
+// set) and populating it after a successful fetch from flex-ipfs so a
+// repeat GetValue for the same cid doesn't round-trip the gateway again.
 func (c *Client) GetValue(ctx context.Context, cid string) ([]byte, error) {
+	if c.Cache != nil {
+		if v, ok := c.Cache.Get(cid); ok {
+			return v, nil
+		}
+	}
+	v, err := c.getValueUncached(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if c.Cache != nil {
+		_ = c.Cache.Put(cid, v)
+	}
+	return v, nil
+}
+
+// GetValueFresh re-fetches cid from the gateway even if it's already
+// cached, for callers (loadJSON's corrupt-payload retry, CID verification)
+// that already have a value in hand and suspect it's stale or corrupt.
+// It evicts cid from c.Cache, deletes any existing <cid>.* getdata files
+// so the placeholder-wait path in getValueUncached can't short-circuit on
+// the same corrupted file, and then fetches and caches it exactly like
+// GetValue.
+func (c *Client) GetValueFresh(ctx context.Context, cid string) ([]byte, error) {
+	if c.Cache != nil {
+		_ = c.Cache.Delete(cid)
+	}
+	c.invalidateGetDataFiles(cid)
+
+	v, err := c.getValueUncached(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	if c.Cache != nil {
+		_ = c.Cache.Put(cid, v)
+	}
+	return v, nil
+}
+
+// invalidateGetDataFiles removes any <cid>.* files readGetDataValue would
+// otherwise return from c.BaseDir's resolved getdata directories, so a
+// GetValueFresh call that follows re-downloads from the gateway instead of
+// reading back the same (possibly corrupt) local copy.
+func (c *Client) invalidateGetDataFiles(cid string) {
+	baseDir := strings.TrimSpace(c.BaseDir)
+	if baseDir == "" {
+		return
+	}
+	for _, dir := range resolveDataDirs(baseDir) {
+		matches, err := filepath.Glob(filepath.Join(dir, cid+".*"))
+		if err != nil {
+			continue
+		}
+		for _, p := range matches {
+			_ = os.Remove(p)
+		}
+	}
+}
+
+func (c *Client) getValueUncached(ctx context.Context, cid string) ([]byte, error) {
 	if b, err := c.readGetDataValue(cid); err == nil {
 		return b, nil
 	}
 
+	ctx, release := c.getDeadline.wrap(ctx)
+	defer release()
+
 	q := url.Values{}
 	q.Set("cid", cid)
 	body, status, header, trailer, err := c.postQuery(ctx, "/dht/getvalue", q)
@@ -237,10 +444,20 @@ func (c *Client) GetValue(ctx context.Context, cid string) ([]byte, error) {
 			if time.Now().After(pollUntil) {
 				break
 			}
+			// notify fires as soon as WatchGetData sees the file appear;
+			// if no watch is active it's nil and this select just waits
+			// out the timer, same as the unconditional poll used to.
+			notify := c.waitForGetData(cid)
+			timer := time.NewTimer(50 * time.Millisecond)
 			select {
 			case <-ctx.Done():
+				timer.Stop()
+				c.unwaitForGetData(cid, notify)
 				return nil, ctx.Err()
-			case <-time.After(50 * time.Millisecond):
+			case <-notify:
+				timer.Stop()
+			case <-timer.C:
+				c.unwaitForGetData(cid, notify)
 			}
 		}
 		return nil, fmt.Errorf("flexipfs getvalue pending: %s", strings.TrimSpace(string(v)))
@@ -337,6 +554,9 @@ func (c *Client) ListTags(ctx context.Context) ([]string, error) {
 }
 
 func (c *Client) PeerList(ctx context.Context) (string, error) {
+	ctx, release := c.peerListDeadline.wrap(ctx)
+	defer release()
+
 	body, status, header, trailer, err := c.postQuery(ctx, "/dht/peerlist", nil)
 	if err != nil {
 		return "", err
@@ -351,8 +571,83 @@ func (c *Client) PeerList(ctx context.Context) (string, error) {
 	return string(bytes.TrimSpace(body)), nil
 }
 
+// Pin asks flex-ipfs to keep cid pinned locally so garbage collection won't
+// drop it even once nothing currently references it by attr/tag. This is
+// distinct from PutValueWithAttr's implicit keep-while-tagged behavior:
+// Pin is for content this node wants to keep around on its own say-so after
+// the fact (see bbs/republisher, which re-pins board content periodically
+// so it doesn't silently drift out of reach). Flexible-IPFS's HTTP API has
+// no documented pin endpoint in this codebase yet, so /dht/pin is a new
+// addition following the existing /dht/* naming convention, not a
+// previously-observed endpoint.
+func (c *Client) Pin(ctx context.Context, cid string) error {
+	q := url.Values{}
+	q.Set("cid", cid)
+	body, status, header, trailer, err := c.postQuery(ctx, "/dht/pin", q)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return httpError(status, body, header, trailer)
+	}
+	return nil
+}
+
+// Provide re-announces cid to the DHT so neighbor nodes keep this node
+// listed as a source for it, the same re-advertisement GetValue/
+// PutValueWithAttr already trigger implicitly as a side effect of reading
+// or writing — Provide exists for content bbs/republisher wants to keep
+// advertised without re-fetching or re-writing it. Like Pin, /dht/provide
+// is a new endpoint added in the existing /dht/* namespace, not one
+// observed elsewhere in this client.
+func (c *Client) Provide(ctx context.Context, cid string) error {
+	q := url.Values{}
+	q.Set("cid", cid)
+	body, status, header, trailer, err := c.postQuery(ctx, "/dht/provide", q)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return httpError(status, body, header, trailer)
+	}
+	return nil
+}
+
 func (c *Client) postQuery(ctx context.Context, apiPath string, q url.Values) (body []byte, status int, header http.Header, trailer http.Header, err error) {
-	fullURL := c.BaseURL + apiPath
+	bases := []string{c.BaseURL}
+	if c.Pool != nil {
+		if candidates := c.Pool.Candidates(); len(candidates) > 0 {
+			bases = candidates
+		}
+	}
+
+	var lastBody []byte
+	var lastStatus int
+	var lastHeader, lastTrailer http.Header
+	var lastErr error
+	for _, base := range bases {
+		b, st, h, tr, err := c.postQueryOnce(ctx, base, apiPath, q)
+		// Only a connection error or 5xx is worth retrying against another
+		// endpoint; a 4xx means the request itself is bad and every other
+		// endpoint would reject it the same way, so return it immediately.
+		if err == nil && st < 500 {
+			if c.Pool != nil {
+				c.Pool.MarkHealthy(base)
+			}
+			return b, st, h, tr, nil
+		}
+
+		if c.Pool != nil {
+			c.Pool.MarkUnhealthy(base)
+		}
+		lastBody, lastStatus, lastHeader, lastTrailer = b, st, h, tr
+		lastErr = err
+	}
+	return lastBody, lastStatus, lastHeader, lastTrailer, lastErr
+}
+
+func (c *Client) postQueryOnce(ctx context.Context, base, apiPath string, q url.Values) (body []byte, status int, header http.Header, trailer http.Header, err error) {
+	fullURL := strings.TrimRight(base, "/") + apiPath
 	if q != nil {
 		encoded := q.Encode()
 		// Flexible-IPFS parses its query string via java.net.URI.getQuery() (percent-decoded but '+' preserved).
@@ -363,6 +658,9 @@ func (c *Client) postQuery(ctx context.Context, apiPath string, q url.Values) (b
 		fullURL += "?" + encoded
 	}
 
+	id := c.trackInFlight(fullURL)
+	defer c.untrackInFlight(id)
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURL, nil)
 	if err != nil {
 		return nil, 0, nil, nil, err
@@ -380,6 +678,26 @@ func (c *Client) postQuery(ctx context.Context, apiPath string, q url.Values) (b
 	return b, resp.StatusCode, resp.Header.Clone(), resp.Trailer, nil
 }
 
+// StatusError is returned by Client methods for a non-2xx HTTP response
+// from the Flexible-IPFS gateway, carrying the status code so callers
+// like storage can distinguish "not found" from other upstream failures
+// without string-matching the formatted message.
+type StatusError struct {
+	Status  int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("flexipfs http %d: %s", e.Status, e.Message)
+}
+
+// IsNotFound reports whether err is a StatusError for HTTP 404, as
+// returned by GetValue for a CID the gateway has no value for.
+func IsNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.Status == http.StatusNotFound
+}
+
 func httpError(status int, body []byte, header http.Header, trailer http.Header) error {
 	msg := strings.TrimSpace(string(body))
 	if msg == "" && header != nil {
@@ -430,7 +748,7 @@ func httpError(status int, body []byte, header http.Header, trailer http.Header)
 	if msg == "" {
 		msg = "empty response"
 	}
-	return fmt.Errorf("flexipfs http %d: %s", status, msg)
+	return &StatusError{Status: status, Message: msg}
 }
 
 func extractCID(body []byte) (string, error) {
@@ -610,19 +928,8 @@ func (c *Client) readGetDataValue(cid string) ([]byte, error) {
 		return nil, os.ErrNotExist
 	}
 
-	dataDirs := []string{
-		filepath.Join(baseDir, "getdata"),
-	}
-	if v := readKadrttProperty(baseDir, "ipfs.datapath"); v != "" {
-		if filepath.IsAbs(v) {
-			dataDirs = append(dataDirs, v)
-		} else {
-			dataDirs = append(dataDirs, filepath.Join(baseDir, v))
-		}
-	}
-
 	var firstErr error
-	for _, dir := range uniqStrings(dataDirs) {
+	for _, dir := range resolveDataDirs(baseDir) {
 		p := filepath.Join(dir, cid+".txt")
 		b, err := os.ReadFile(p)
 		if err == nil {
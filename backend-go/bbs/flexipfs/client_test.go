@@ -96,6 +96,70 @@ func TestGetValue_UnwrapsJSONString(t *testing.T) {
 	}
 }
 
+func TestGetValue_CacheHitSkipsGateway(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`"from-gateway"`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	c.Cache = NewLRUBlockStore(10)
+
+	b1, err := c.GetValue(context.Background(), "baf1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if string(b1) != "from-gateway" {
+		t.Fatalf("GetValue = %q", b1)
+	}
+	if calls != 1 {
+		t.Fatalf("calls after first GetValue = %d, want 1", calls)
+	}
+
+	b2, err := c.GetValue(context.Background(), "baf1")
+	if err != nil {
+		t.Fatalf("GetValue (cached): %v", err)
+	}
+	if string(b2) != "from-gateway" {
+		t.Fatalf("cached GetValue = %q", b2)
+	}
+	if calls != 1 {
+		t.Fatalf("calls after second GetValue = %d, want 1 (should be served from cache)", calls)
+	}
+}
+
+func TestPutValueWithAttr_PopulatesCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_, _ = w.Write([]byte(`"peer1"`))
+		case "/api/v0/dht/putvaluewithattr":
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": "baf_put"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	c.Cache = NewLRUBlockStore(10)
+
+	cid, err := c.PutValueWithAttr(context.Background(), "hello", nil, nil)
+	if err != nil {
+		t.Fatalf("PutValueWithAttr: %v", err)
+	}
+	if cid != "baf_put" {
+		t.Fatalf("cid = %q", cid)
+	}
+
+	v, ok := c.Cache.Get("baf_put")
+	if !ok || string(v) != "hello" {
+		t.Fatalf("Cache.Get(%q) = %q, %v, want %q, true", cid, v, ok, "hello")
+	}
+}
+
 func TestGetValue_ReadsFromGetDataFile_OnDownloadingPlaceholder(t *testing.T) {
 	baseDir := t.TempDir()
 	getDataDir := filepath.Join(baseDir, "getdata")
@@ -312,6 +376,110 @@ func TestPutValueWithAttr_FallsBackWithoutAttrs_OnEOF(t *testing.T) {
 	}
 }
 
+func TestGetValue_SetGetDeadlineCancelsInFlightRetry(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_ = json.NewEncoder(w).Encode("too-late")
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	c := New(srv.URL)
+	c.SetGetDeadline(time.Now().Add(time.Hour))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetValue(context.Background(), "cid1")
+		done <- err
+	}()
+
+	// Give the request a moment to actually reach the handler and start
+	// blocking, then supersede the deadline with one already in the past --
+	// the in-flight GetValue should be canceled immediately rather than
+	// waiting out the hour it started with.
+	time.Sleep(20 * time.Millisecond)
+	c.SetGetDeadline(time.Now().Add(-time.Second))
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("GetValue = nil error, want context canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SetGetDeadline did not cancel the in-flight GetValue")
+	}
+}
+
+func TestClient_InFlightTracksOutstandingRequests(t *testing.T) {
+	reached := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(reached)
+		<-release
+		_ = json.NewEncoder(w).Encode("peer1")
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL)
+	done := make(chan struct{})
+	go func() {
+		_, _ = c.PeerList(context.Background())
+		close(done)
+	}()
+
+	<-reached
+	inFlight := c.InFlight()
+	if len(inFlight) != 1 {
+		t.Fatalf("InFlight() = %+v, want exactly one outstanding request", inFlight)
+	}
+	if !strings.HasSuffix(inFlight[0].URL, "/dht/peerlist") {
+		t.Fatalf("InFlight()[0].URL = %q, want a /dht/peerlist request", inFlight[0].URL)
+	}
+
+	close(release)
+	<-done
+
+	if inFlight := c.InFlight(); len(inFlight) != 0 {
+		t.Fatalf("InFlight() after completion = %+v, want none", inFlight)
+	}
+}
+
+func TestPin_SendsCIDAndSucceedsOn2xx(t *testing.T) {
+	var gotPath, gotCID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotCID = r.URL.Query().Get("cid")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL)
+	if err := c.Pin(context.Background(), "baf_test"); err != nil {
+		t.Fatalf("Pin: %v", err)
+	}
+	if gotPath != "/dht/pin" || gotCID != "baf_test" {
+		t.Fatalf("unexpected request: path=%q cid=%q", gotPath, gotCID)
+	}
+}
+
+func TestProvide_SendsCIDAndSurfacesHTTPError(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL)
+	if err := c.Provide(context.Background(), "baf_test"); err == nil {
+		t.Fatal("expected Provide to surface the 500")
+	}
+	if gotPath != "/dht/provide" {
+		t.Fatalf("unexpected path: %q", gotPath)
+	}
+}
+
 func TestHttpError_UsesTrailerKeysAsMessage(t *testing.T) {
 	trailer := http.Header{}
 	trailer["No+target+node+found.%0A"] = nil
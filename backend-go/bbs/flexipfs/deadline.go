@@ -0,0 +1,87 @@
+package flexipfs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// deadline is a resettable per-operation timeout, modeled on the standard
+// net.Conn SetDeadline/SetReadDeadline/SetWriteDeadline pattern (the same
+// style gonet-type adapters use for a net.Conn backed by something other
+// than a raw socket): instead of each call getting a deadline baked into
+// the ctx it's handed, Client keeps one deadline object per operation that
+// every call for that operation consults, so a caller can tighten, extend
+// or clear it at any time without threading a fresh context through every
+// call site. Unlike a plain timer, resetting the deadline supersedes any
+// wrap already in flight: its derived ctx is canceled immediately, so a
+// caller bumping a deadline mid-retry-loop (or canceling just one
+// operation) doesn't have to wait for the old value to elapse first.
+type deadline struct {
+	mu      sync.Mutex
+	t       time.Time
+	seq     uint64
+	cancels map[uint64]context.CancelFunc
+}
+
+// set installs t as the new deadline and cancels every wrap currently in
+// flight, even when t is later than the old deadline, so none of them runs
+// out the deadline that was current when it started; only wraps made after
+// set see t. A zero Time clears the
+// deadline (no timeout) without canceling in-flight calls -- lifting a
+// limit shouldn't abort work already underway.
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	d.t = t
+	var cancels []context.CancelFunc
+	if !t.IsZero() {
+		for _, cancel := range d.cancels {
+			cancels = append(cancels, cancel)
+		}
+		d.cancels = nil
+	}
+	d.mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// wrap derives a context from parent that's canceled when d's deadline (if
+// any) elapses or is superseded by a later set call, and returns a release
+// func the caller must invoke once its call completes, stopping the timer
+// early so a call that finishes well before the deadline doesn't leave a
+// goroutine sleeping until it fires.
+func (d *deadline) wrap(parent context.Context) (context.Context, context.CancelFunc) {
+	d.mu.Lock()
+	t := d.t
+	ctx, cancel := context.WithCancel(parent)
+	d.seq++
+	id := d.seq
+	if d.cancels == nil {
+		d.cancels = make(map[uint64]context.CancelFunc)
+	}
+	d.cancels[id] = cancel
+	d.mu.Unlock()
+
+	release := func() {
+		cancel()
+		d.mu.Lock()
+		delete(d.cancels, id)
+		d.mu.Unlock()
+	}
+
+	if t.IsZero() {
+		return ctx, release
+	}
+	remaining := time.Until(t)
+	if remaining <= 0 {
+		cancel()
+		return ctx, release
+	}
+	timer := time.AfterFunc(remaining, cancel)
+	return ctx, func() {
+		timer.Stop()
+		release()
+	}
+}
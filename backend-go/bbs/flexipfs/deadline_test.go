@@ -0,0 +1,99 @@
+package flexipfs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeadlineWrapNoDeadline(t *testing.T) {
+	var d deadline
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("expected ctx to stay open with no deadline set")
+	default:
+	}
+}
+
+func TestDeadlineWrapExpires(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(10 * time.Millisecond))
+
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected ctx to be canceled once the deadline elapsed")
+	}
+}
+
+func TestDeadlineWrapPast(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(-time.Second))
+
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("expected ctx to already be canceled for a past deadline")
+	}
+}
+
+func TestDeadlineReleaseStopsTimer(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(time.Hour))
+
+	ctx, release := d.wrap(context.Background())
+	release()
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("expected release to cancel ctx immediately")
+	}
+}
+
+func TestDeadlineSetSupersedesInFlightWrap(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(time.Hour))
+
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("ctx should still be open before the new deadline is set")
+	default:
+	}
+
+	d.set(time.Now().Add(-time.Second))
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("expected set to cancel the wrap already in flight")
+	}
+}
+
+func TestDeadlineSetToZeroDoesNotCancelInFlightWrap(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(time.Hour))
+
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	d.set(time.Time{})
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("clearing the deadline should not cancel a call already in flight")
+	default:
+	}
+}
@@ -0,0 +1,91 @@
+package flexipfs
+
+import (
+	"context"
+	"sync"
+)
+
+// ExchangeSession deduplicates concurrent GetValue calls for the same CID
+// into a single in-flight fetch, the way boxo's blockservice sits in
+// front of an exchange: a batch of GetByAttrs results handed to many
+// concurrent callers hits the gateway at most once per CID, however many
+// callers ask for it, rather than once per caller. A session is scoped to
+// whatever batch of work it's created for -- it keeps no state beyond the
+// calls currently in flight, so there's no need to close or reuse one
+// across unrelated batches.
+type ExchangeSession struct {
+	client *Client
+
+	mu       sync.Mutex
+	inFlight map[string]*exchangeCall
+}
+
+type exchangeCall struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// NewExchangeSession returns an ExchangeSession that fetches through c.
+func NewExchangeSession(c *Client) *ExchangeSession {
+	return &ExchangeSession{client: c, inFlight: make(map[string]*exchangeCall)}
+}
+
+// GetValue fetches cid through the session's Client, same as calling
+// Client.GetValue directly, except a second GetValue(cid) already in
+// flight (from another goroutine) is joined instead of triggering a
+// second fetch.
+func (s *ExchangeSession) GetValue(ctx context.Context, cid string) ([]byte, error) {
+	s.mu.Lock()
+	if call, ok := s.inFlight[cid]; ok {
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &exchangeCall{done: make(chan struct{})}
+	s.inFlight[cid] = call
+	s.mu.Unlock()
+
+	call.value, call.err = s.client.GetValue(ctx, cid)
+
+	s.mu.Lock()
+	delete(s.inFlight, cid)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.value, call.err
+}
+
+// ExchangeResult is one cid's outcome from GetMany.
+type ExchangeResult struct {
+	Value []byte
+	Err   error
+}
+
+// GetMany fetches every cid in cids concurrently through GetValue,
+// returning each one's outcome keyed by cid, so a batch of GetByAttrs
+// results can be resolved in parallel while still sharing this session's
+// in-flight dedup.
+func (s *ExchangeSession) GetMany(ctx context.Context, cids []string) map[string]ExchangeResult {
+	results := make(map[string]ExchangeResult, len(cids))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, cid := range cids {
+		cid := cid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := s.GetValue(ctx, cid)
+			mu.Lock()
+			results[cid] = ExchangeResult{Value: v, Err: err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,93 @@
+package flexipfs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestExchangeSession_DedupsConcurrentGetValue starts many concurrent
+// GetValue calls for the same cid and asserts the gateway only sees one
+// of them -- the rest joined the one already in flight.
+func TestExchangeSession_DedupsConcurrentGetValue(t *testing.T) {
+	var calls int64
+	var release = make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/dht/getvalue" {
+			atomic.AddInt64(&calls, 1)
+			<-release
+			_ = json.NewEncoder(w).Encode("hello")
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	sess := NewExchangeSession(c)
+
+	const n = 10
+	var wg sync.WaitGroup
+	results := make([][]byte, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = sess.GetValue(context.Background(), "cid1")
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("gateway saw %d getvalue calls, want 1", got)
+	}
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("GetValue[%d]: %v", i, errs[i])
+		}
+		if string(results[i]) != "hello" {
+			t.Fatalf("GetValue[%d] = %q, want %q", i, results[i], "hello")
+		}
+	}
+}
+
+func TestExchangeSession_GetMany(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			_ = json.NewEncoder(w).Encode("value-" + cid)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	sess := NewExchangeSession(c)
+
+	results := sess.GetMany(context.Background(), []string{"a", "b", "c"})
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	for _, cid := range []string{"a", "b", "c"} {
+		r, ok := results[cid]
+		if !ok {
+			t.Fatalf("missing result for %q", cid)
+		}
+		if r.Err != nil {
+			t.Fatalf("GetMany[%q]: %v", cid, r.Err)
+		}
+		if string(r.Value) != "value-"+cid {
+			t.Fatalf("GetMany[%q] = %q, want %q", cid, r.Value, "value-"+cid)
+		}
+	}
+}
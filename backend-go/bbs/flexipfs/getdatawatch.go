@@ -0,0 +1,211 @@
+package flexipfs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsWatcher is a package-local alias so Client's watcher field doesn't
+// force every file in this package to import fsnotify, the same reasoning
+// BlockStore's implementations keep their own file.
+type fsWatcher = fsnotify.Watcher
+
+// resolveDataDirs returns the directories readGetDataValue and WatchGetData
+// both look for <cid>.txt (or <cid>.*) files under, given the client's
+// BaseDir: the fixed "getdata" subdirectory Flexible-IPFS always writes to,
+// plus whatever ipfs.datapath points at in kadrtt.properties, if anything.
+func resolveDataDirs(baseDir string) []string {
+	dataDirs := []string{
+		filepath.Join(baseDir, "getdata"),
+	}
+	if v := readKadrttProperty(baseDir, "ipfs.datapath"); v != "" {
+		if filepath.IsAbs(v) {
+			dataDirs = append(dataDirs, v)
+		} else {
+			dataDirs = append(dataDirs, filepath.Join(baseDir, v))
+		}
+	}
+	return uniqStrings(dataDirs)
+}
+
+// WatchGetData starts an fsnotify watch on c.BaseDir's resolved getdata
+// directories (see resolveDataDirs) so getValueUncached's placeholder-wait
+// loop can block on a per-CID notification instead of polling
+// readGetDataValue every 50ms. It's optional: a Client that never calls
+// WatchGetData, or whose BaseDir lives on a filesystem fsnotify doesn't
+// support (e.g. some network mounts), keeps working exactly as before --
+// waitForGetData returns nil whenever c.watcher is nil, which degrades the
+// wait loop's select back to its original poll-only behavior.
+//
+// The returned stop func closes the watcher and must be called once the
+// caller is done with it; it is safe to call more than once. Canceling ctx
+// has the same effect as calling stop.
+func (c *Client) WatchGetData(ctx context.Context) (stop func(), err error) {
+	baseDir := strings.TrimSpace(c.BaseDir)
+	if baseDir == "" {
+		return nil, fmt.Errorf("flexipfs: WatchGetData requires BaseDir to be set")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("flexipfs: new fsnotify watcher: %w", err)
+	}
+
+	watched := 0
+	for _, dir := range resolveDataDirs(baseDir) {
+		// A data dir that doesn't exist yet (e.g. the gateway hasn't
+		// downloaded anything so far) just can't be watched; the poll
+		// fallback still covers it.
+		if err := watcher.Add(dir); err == nil {
+			watched++
+		}
+	}
+	if watched == 0 {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("flexipfs: no getdata directory under %q could be watched", baseDir)
+	}
+
+	c.watchMu.Lock()
+	c.watcher = watcher
+	if c.waiters == nil {
+		c.waiters = make(map[string][]chan struct{})
+	}
+	c.watchMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+					continue
+				}
+				if cid := cidFromGetDataPath(ev.Name); cid != "" {
+					c.notifyGetData(cid)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				c.watchMu.Lock()
+				if c.watcher == watcher {
+					c.watcher = nil
+				}
+				c.watchMu.Unlock()
+				_ = watcher.Close()
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		close(done)
+		c.watchMu.Lock()
+		if c.watcher == watcher {
+			c.watcher = nil
+		}
+		c.watchMu.Unlock()
+		_ = watcher.Close()
+	}, nil
+}
+
+// cidFromGetDataPath extracts the CID a getdata event path is for, i.e. the
+// filename with its extension (if any) stripped -- "<cid>.txt" and
+// "<cid>.<ext>" both resolve to "<cid>", matching the file names
+// readGetDataValue itself looks for.
+func cidFromGetDataPath(path string) string {
+	base := filepath.Base(path)
+	if i := strings.Index(base, "."); i >= 0 {
+		return base[:i]
+	}
+	return base
+}
+
+// waitForGetData returns a channel that notifyGetData closes once a
+// getdata file for cid is created or written, or nil if no watcher is
+// active (the caller's select should treat a nil channel as "never fires",
+// which plain Go already does). Callers that stop waiting before it fires
+// must pass it to unwaitForGetData to avoid leaking the registration; once
+// it has fired, notifyGetData has already removed it.
+func (c *Client) waitForGetData(cid string) chan struct{} {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if c.watcher == nil {
+		return nil
+	}
+	ch := make(chan struct{})
+	c.waiters[cid] = append(c.waiters[cid], ch)
+	return ch
+}
+
+// unwaitForGetData removes ch from cid's waiter list; a no-op if ch is nil
+// (waitForGetData returned nil) or has already been notified and removed.
+func (c *Client) unwaitForGetData(cid string, ch chan struct{}) {
+	if ch == nil {
+		return
+	}
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	list := c.waiters[cid]
+	for i, w := range list {
+		if w == ch {
+			c.waiters[cid] = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(c.waiters[cid]) == 0 {
+		delete(c.waiters, cid)
+	}
+}
+
+// notifyGetData wakes every waiter registered for cid by closing its
+// channel, then clears the registration.
+func (c *Client) notifyGetData(cid string) {
+	c.watchMu.Lock()
+	chans := c.waiters[cid]
+	delete(c.waiters, cid)
+	c.watchMu.Unlock()
+	for _, ch := range chans {
+		close(ch)
+	}
+}
+
+// PrefetchCIDs issues the initial /dht/getvalue request for each of cids
+// concurrently (via GetValue, so c.Cache and the placeholder wait --
+// accelerated by WatchGetData, if active -- both still apply) and returns,
+// for each CID, a channel that receives its value once ready or is closed
+// without one on error. Callers processing a GetByAttrs result set can
+// start every CID's fetch up front and drain results as they complete
+// instead of awaiting one GetValue call at a time.
+func (c *Client) PrefetchCIDs(ctx context.Context, cids []string) map[string]<-chan []byte {
+	out := make(map[string]<-chan []byte, len(cids))
+	for _, cid := range cids {
+		cid := cid
+		ch := make(chan []byte, 1)
+		out[cid] = ch
+		go func() {
+			defer close(ch)
+			v, err := c.GetValue(ctx, cid)
+			if err != nil {
+				return
+			}
+			ch <- v
+		}()
+	}
+	return out
+}
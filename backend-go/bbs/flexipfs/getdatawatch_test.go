@@ -0,0 +1,160 @@
+package flexipfs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWaitForGetData_NilWithoutWatch(t *testing.T) {
+	c := New("http://unused")
+	if ch := c.waitForGetData("baf_test"); ch != nil {
+		t.Fatal("expected waitForGetData to return nil when no watch is active")
+	}
+}
+
+func TestWatchGetData_NotifiesWaiterOnFileCreate(t *testing.T) {
+	baseDir := t.TempDir()
+	getDataDir := filepath.Join(baseDir, "getdata")
+	if err := os.MkdirAll(getDataDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	c := New("http://unused")
+	c.BaseDir = baseDir
+
+	stop, err := c.WatchGetData(context.Background())
+	if err != nil {
+		t.Fatalf("WatchGetData: %v", err)
+	}
+	defer stop()
+
+	ch := c.waitForGetData("baf_test")
+	if ch == nil {
+		t.Fatal("waitForGetData returned nil while a watch is active")
+	}
+
+	if err := os.WriteFile(filepath.Join(getDataDir, "baf_test.txt"), []byte("Bhi"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected notifyGetData to fire once the getdata file was created")
+	}
+}
+
+func TestWatchGetData_StopStopsNotifying(t *testing.T) {
+	baseDir := t.TempDir()
+	getDataDir := filepath.Join(baseDir, "getdata")
+	if err := os.MkdirAll(getDataDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	c := New("http://unused")
+	c.BaseDir = baseDir
+
+	stop, err := c.WatchGetData(context.Background())
+	if err != nil {
+		t.Fatalf("WatchGetData: %v", err)
+	}
+	stop()
+
+	if ch := c.waitForGetData("baf_test"); ch != nil {
+		t.Fatal("expected waitForGetData to return nil after stop")
+	}
+}
+
+func TestGetValue_WatchGetDataWakesPendingPlaceholderWait(t *testing.T) {
+	baseDir := t.TempDir()
+	getDataDir := filepath.Join(baseDir, "getdata")
+	if err := os.MkdirAll(getDataDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dht/getvalue" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		cid := r.URL.Query().Get("cid")
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			_ = os.WriteFile(filepath.Join(getDataDir, cid+".txt"), []byte("Bhi"), 0o644)
+		}()
+		_, _ = w.Write([]byte(strconv.Quote("Downloading chunks for CID:" + cid)))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	c.BaseDir = baseDir
+	stop, err := c.WatchGetData(context.Background())
+	if err != nil {
+		t.Fatalf("WatchGetData: %v", err)
+	}
+	defer stop()
+
+	b, err := c.GetValue(context.Background(), "baf_test")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if string(b) != "hi" {
+		t.Fatalf("value mismatch: %q", string(b))
+	}
+}
+
+func TestPrefetchCIDs_ReturnsValuesConcurrently(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dht/getvalue" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		cid := r.URL.Query().Get("cid")
+		_, _ = w.Write([]byte(strconv.Quote("value-" + cid)))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	results := c.PrefetchCIDs(context.Background(), []string{"cid1", "cid2", "cid3"})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 channels, got %d", len(results))
+	}
+	for cid, ch := range results {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				t.Fatalf("%s: channel closed without a value", cid)
+			}
+			if want := "value-" + cid; string(v) != want {
+				t.Fatalf("%s: got %q, want %q", cid, v, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: timed out waiting for prefetch", cid)
+		}
+	}
+}
+
+func TestPrefetchCIDs_ClosesChannelWithoutValueOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api/v0")
+	results := c.PrefetchCIDs(context.Background(), []string{"cid1"})
+	ch := results["cid1"]
+	select {
+	case v, ok := <-ch:
+		if ok {
+			t.Fatalf("expected channel to close without a value, got %q", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for prefetch channel to close")
+	}
+}
@@ -0,0 +1,109 @@
+package flexipfs
+
+import (
+	"sync"
+)
+
+// endpointState is EndpointPool's per-endpoint bookkeeping: whether the
+// endpoint is currently believed reachable. The rotation position is kept
+// on the pool itself, not per endpoint.
+type endpointState struct {
+	url     string
+	healthy bool
+}
+
+// EndpointPool is an ordered set of Flexible-IPFS gateway HTTP endpoints
+// Client fails over across: postQuery starts at the pool's current
+// position and advances through the remaining endpoints on a 5xx or
+// connection error, trying endpoints already marked unhealthy only after
+// the healthy ones (see Candidates). It is safe for concurrent use.
+type EndpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpointState
+	next      int
+}
+
+// NewEndpointPool returns an EndpointPool over endpoints, in the order
+// given, all initially marked healthy. A caller that has already probed
+// them (e.g. mdns.go's discovery) should follow up with MarkUnhealthy for
+// any that failed.
+func NewEndpointPool(endpoints []string) *EndpointPool {
+	p := &EndpointPool{}
+	for _, e := range endpoints {
+		p.endpoints = append(p.endpoints, &endpointState{url: e, healthy: true})
+	}
+	return p
+}
+
+// Endpoints returns the pool's endpoints in rotation order, unfiltered by
+// health, mainly for status reporting.
+func (p *EndpointPool) Endpoints() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		out[i] = e.url
+	}
+	return out
+}
+
+// Candidates returns the pool's endpoints starting from its current
+// rotation position and wrapping around, with unhealthy endpoints sorted
+// to the back rather than dropped entirely — if every endpoint is
+// unhealthy, postQuery should still have something to try rather than
+// nothing. Calling Candidates also advances the rotation position by one,
+// so the next call (i.e. the next request) starts from a different
+// endpoint, spreading load round-robin across healthy endpoints.
+func (p *EndpointPool) Candidates() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.endpoints)
+	if n == 0 {
+		return nil
+	}
+	start := p.next
+	p.next = (p.next + 1) % n
+
+	ordered := make([]*endpointState, 0, n)
+	for i := 0; i < n; i++ {
+		ordered = append(ordered, p.endpoints[(start+i)%n])
+	}
+
+	healthy := make([]string, 0, n)
+	unhealthy := make([]string, 0, n)
+	for _, e := range ordered {
+		if e.healthy {
+			healthy = append(healthy, e.url)
+		} else {
+			unhealthy = append(unhealthy, e.url)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// MarkUnhealthy records that url failed a request (connection error or
+// 5xx), so Candidates sorts it behind the pool's healthy endpoints.
+func (p *EndpointPool) MarkUnhealthy(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.endpoints {
+		if e.url == url {
+			e.healthy = false
+			return
+		}
+	}
+}
+
+// MarkHealthy records that url served a non-5xx response, clearing any
+// prior MarkUnhealthy.
+func (p *EndpointPool) MarkHealthy(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.endpoints {
+		if e.url == url {
+			e.healthy = true
+			return
+		}
+	}
+}
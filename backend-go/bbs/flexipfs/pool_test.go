@@ -0,0 +1,36 @@
+package flexipfs
+
+import "testing"
+
+func TestEndpointPoolCandidatesRotates(t *testing.T) {
+	p := NewEndpointPool([]string{"a", "b", "c"})
+
+	first := p.Candidates()
+	second := p.Candidates()
+
+	if first[0] != "a" {
+		t.Fatalf("expected first call to start at a, got %v", first)
+	}
+	if second[0] != "b" {
+		t.Fatalf("expected second call to start at b, got %v", second)
+	}
+}
+
+func TestEndpointPoolSortsUnhealthyLast(t *testing.T) {
+	p := NewEndpointPool([]string{"a", "b", "c"})
+	p.MarkUnhealthy("a")
+
+	got := p.Candidates()
+	if got[len(got)-1] != "a" {
+		t.Fatalf("expected unhealthy endpoint a last, got %v", got)
+	}
+
+	p.MarkHealthy("a")
+	got = p.Candidates()
+	for _, e := range got {
+		if e == "a" {
+			return
+		}
+	}
+	t.Fatalf("expected a back in rotation after MarkHealthy, got %v", got)
+}
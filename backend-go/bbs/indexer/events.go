@@ -0,0 +1,225 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// EventType identifies the kind of change an Event describes.
+type EventType string
+
+const (
+	EventPostIndexed      EventType = "post_indexed"
+	EventThreadCreated    EventType = "thread_created"
+	EventBoardLogAdvanced EventType = "board_log_advanced"
+	EventPostTombstoned   EventType = "post_tombstoned"
+)
+
+// Event is one indexed change, published to subscribers of Indexer.Subscribe
+// as soon as the write it describes has committed. Fields not meaningful to
+// a given Type are left zero (e.g. PostCID on a BoardLogAdvanced event).
+type Event struct {
+	Type         EventType `json:"type"`
+	BoardID      string    `json:"boardId"`
+	ThreadID     string    `json:"threadId,omitempty"`
+	PostCID      string    `json:"postCid,omitempty"`
+	AuthorPubKey string    `json:"authorPubKey,omitempty"`
+	CreatedAt    string    `json:"createdAt,omitempty"`
+	// HeadCID is only set on EventBoardLogAdvanced: the board's new
+	// log_head_cid.
+	HeadCID string `json:"headCid,omitempty"`
+}
+
+// EventFilter narrows a subscription to the events a caller cares about;
+// a zero-value EventFilter matches everything. Every set field is a
+// conjunction (BoardID AND ThreadID AND ...); Types is a disjunction
+// within itself (any of these types).
+type EventFilter struct {
+	BoardID      string
+	ThreadID     string
+	AuthorPubKey string
+	Types        []EventType
+}
+
+func (f EventFilter) matches(e Event) bool {
+	if f.BoardID != "" && f.BoardID != e.BoardID {
+		return false
+	}
+	if f.ThreadID != "" && f.ThreadID != e.ThreadID {
+		return false
+	}
+	if f.AuthorPubKey != "" && f.AuthorPubKey != e.AuthorPubKey {
+		return false
+	}
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if t == e.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultEventBufferSize bounds each subscriber's channel when an EventHub
+// is constructed with bufferSize <= 0; see eventHub.Subscribe.
+const defaultEventBufferSize = 64
+
+// eventHub is a long-lived multiplexer fanning out Events to many
+// subscribers, each with its own bounded buffer, so one slow consumer can
+// neither block another nor block the write path publishing events.
+type eventHub struct {
+	mu         sync.Mutex
+	subs       map[int]*eventSub
+	nextSubID  int
+	bufferSize int
+	closed     bool
+
+	// droppedSubscribersTotal counts subscribers evicted for falling
+	// behind; see publish.
+	droppedSubscribersTotal int64
+}
+
+type eventSub struct {
+	ch     chan Event
+	filter EventFilter
+}
+
+func newEventHub(bufferSize int) *eventHub {
+	if bufferSize <= 0 {
+		bufferSize = defaultEventBufferSize
+	}
+	return &eventHub{subs: make(map[int]*eventSub), bufferSize: bufferSize}
+}
+
+// Subscribe registers filter and returns a channel of matching events along
+// with an unsubscribe func. The channel is closed when unsubscribe is
+// called, when the hub is closed (Indexer.Close), or — if this subscriber
+// falls far enough behind that its buffer fills — by the hub itself; see
+// publish. Callers should range over the channel rather than assume it
+// stays open for the lifetime of ctx.
+func (h *eventHub) Subscribe(filter EventFilter) (<-chan Event, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, ErrIndexerClosed
+	}
+
+	id := h.nextSubID
+	h.nextSubID++
+	sub := &eventSub{ch: make(chan Event, h.bufferSize), filter: filter}
+	h.subs[id] = sub
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if s, ok := h.subs[id]; ok {
+				delete(h.subs, id)
+				close(s.ch)
+			}
+		})
+	}
+	return sub.ch, unsubscribe, nil
+}
+
+// publish fans e out to every subscriber whose filter matches it. A
+// subscriber whose buffer is already full when its turn comes is evicted —
+// its channel is closed and droppedSubscribersTotal incremented — rather
+// than letting it block every other subscriber or the caller publishing
+// the event. Events have no redelivery guarantee: a dropped subscriber
+// only misses events from the point it was dropped, not before.
+func (h *eventHub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	for id, sub := range h.subs {
+		if !sub.filter.matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(h.subs, id)
+			close(sub.ch)
+			atomic.AddInt64(&h.droppedSubscribersTotal, 1)
+		}
+	}
+}
+
+// close shuts down every subscriber; called from Indexer.Close so no
+// subscriber channel is left open once the indexer itself is gone.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for id, sub := range h.subs {
+		delete(h.subs, id)
+		close(sub.ch)
+	}
+}
+
+// DroppedSubscribersTotal returns the number of subscribers this hub has
+// evicted for falling behind, for a caller to expose on its own metrics
+// registry.
+func (i *Indexer) DroppedSubscribersTotal() int64 {
+	return atomic.LoadInt64(&i.events.droppedSubscribersTotal)
+}
+
+// Subscribe registers filter against i's event hub and returns a channel of
+// matching events plus an unsubscribe func; see eventHub.Subscribe for the
+// channel's lifecycle. Canceling ctx also unsubscribes, so a caller can
+// tie delivery to a request's lifetime instead of remembering to call
+// unsubscribe itself on every exit path.
+func (i *Indexer) Subscribe(ctx context.Context, filter EventFilter) (<-chan Event, func(), error) {
+	if i.db == nil {
+		return nil, nil, ErrIndexerClosed
+	}
+	ch, unsubscribe, err := i.events.Subscribe(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+	return ch, unsubscribe, nil
+}
+
+// emit records e to be published once the enclosing write commits: inside
+// a runInTx/WithTx batch that's still open, or discarded if it rolls back.
+// It must only be called from within a runInTx callback (directly or via
+// WithTx), never against a write that isn't going through one of those, or
+// e will never be flushed.
+func (i *Indexer) emit(e Event) {
+	i.pendingEvents = append(i.pendingEvents, e)
+}
+
+// flushPendingEvents publishes every event buffered by emit since the last
+// flush/discard and clears the buffer. Called by runInTx/WithTx once their
+// transaction has committed.
+func (i *Indexer) flushPendingEvents() {
+	for _, e := range i.pendingEvents {
+		i.events.publish(e)
+	}
+	i.pendingEvents = nil
+}
+
+// discardPendingEvents drops every event buffered by emit without
+// publishing them. Called by runInTx/WithTx when their transaction rolls
+// back instead of committing.
+func (i *Indexer) discardPendingEvents() {
+	i.pendingEvents = nil
+}
@@ -0,0 +1,188 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestIndexer_SyncBoardByMetaCIDPublishesEvents(t *testing.T) {
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	postCID := "post_1"
+	post := &types.Post{
+		ThreadID:     "thread_1",
+		AuthorPubKey: "author1",
+		DisplayName:  "Author",
+		Body:         types.PostBody{Format: "plain", Content: "hello"},
+		CreatedAt:    "2025-01-01T00:00:00Z",
+	}
+	if err := signature.SignPost(priv, post); err != nil {
+		t.Fatalf("SignPost: %v", err)
+	}
+
+	thread := &types.ThreadMeta{
+		ThreadID:    "thread_1",
+		BoardID:     "bbs.general",
+		Title:       "Thread",
+		RootPostCID: postCID,
+		CreatedAt:   "2025-01-01T00:00:00Z",
+		CreatedBy:   "author1",
+	}
+	if err := signature.SignThreadMeta(priv, thread); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+
+	logCID := "log_1"
+	entry := &types.BoardLogEntry{
+		BoardID:      "bbs.general",
+		Op:           types.OpCreateThread,
+		ThreadID:     "thread_1",
+		PostCID:      &postCID,
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: "author1",
+	}
+	if err := signature.SignBoardLogEntry(priv, entry); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+
+	headCID := logCID
+	boardMetaCID := "boardmeta_1"
+	bm := &types.BoardMeta{
+		BoardID:    "bbs.general",
+		Title:      "General",
+		CreatedAt:  "2025-01-01T00:00:00Z",
+		CreatedBy:  "author1",
+		LogHeadCID: &headCID,
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+
+	st := fakeObjectServer(t, map[string]any{
+		boardMetaCID: bm,
+		logCID:       entry,
+		"thread_1":   thread,
+		postCID:      post,
+	})
+
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ch, unsubscribe, err := ix.Subscribe(ctx, EventFilter{BoardID: "bbs.general"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	t.Cleanup(unsubscribe)
+
+	if err := ix.SyncBoardByMetaCID(context.Background(), boardMetaCID); err != nil {
+		t.Fatalf("SyncBoardByMetaCID: %v", err)
+	}
+
+	var got []Event
+	for len(got) < 3 {
+		select {
+		case e := <-ch:
+			got = append(got, e)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for events, got %d so far: %#v", len(got), got)
+		}
+	}
+
+	if got[0].Type != EventThreadCreated || got[0].ThreadID != "thread_1" {
+		t.Fatalf("expected first event ThreadCreated for thread_1, got %#v", got[0])
+	}
+	if got[1].Type != EventPostIndexed || got[1].PostCID != postCID {
+		t.Fatalf("expected second event PostIndexed for %s, got %#v", postCID, got[1])
+	}
+	if got[2].Type != EventBoardLogAdvanced || got[2].HeadCID != headCID {
+		t.Fatalf("expected third event BoardLogAdvanced to %s, got %#v", headCID, got[2])
+	}
+}
+
+func TestEventHub_FilterAndUnsubscribe(t *testing.T) {
+	h := newEventHub(0)
+
+	ch, unsubscribe, err := h.Subscribe(EventFilter{BoardID: "bbs.general"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	h.publish(Event{Type: EventPostIndexed, BoardID: "bbs.other"})
+	h.publish(Event{Type: EventPostIndexed, BoardID: "bbs.general", PostCID: "post_1"})
+
+	select {
+	case e := <-ch:
+		if e.PostCID != "post_1" {
+			t.Fatalf("expected filtered event for bbs.general, got %#v", e)
+		}
+	default:
+		t.Fatalf("expected a buffered event")
+	}
+
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Fatalf("expected channel closed after unsubscribe")
+	}
+	// unsubscribe must be safe to call more than once.
+	unsubscribe()
+}
+
+func TestEventHub_DropsSlowSubscriberPastHighWaterMark(t *testing.T) {
+	h := newEventHub(2)
+
+	ch, unsubscribe, err := h.Subscribe(EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	t.Cleanup(unsubscribe)
+
+	for n := 0; n < 3; n++ {
+		h.publish(Event{Type: EventPostIndexed, PostCID: "post"})
+	}
+
+	// The first 2 publishes fit in the buffer and are still delivered even
+	// though the 3rd dropped this subscriber — a dropped subscriber only
+	// misses events from the point it was dropped, not before (see
+	// eventHub.publish). Drain them before checking the channel closed.
+	for n := 0; n < 2; n++ {
+		if _, ok := <-ch; !ok {
+			t.Fatalf("expected buffered event %d to still be delivered before the channel closes", n)
+		}
+	}
+	if _, ok := <-ch; ok {
+		t.Fatalf("expected a full buffer to be evicted and its channel closed")
+	}
+	if got := h.droppedSubscribersTotal; got != 1 {
+		t.Fatalf("expected droppedSubscribersTotal=1, got %d", got)
+	}
+}
+
+func TestEventHub_CloseClosesEverySubscriber(t *testing.T) {
+	h := newEventHub(0)
+
+	ch, _, err := h.Subscribe(EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	h.close()
+
+	if _, ok := <-ch; ok {
+		t.Fatalf("expected channel closed after hub close")
+	}
+	if _, _, err := h.Subscribe(EventFilter{}); err != ErrIndexerClosed {
+		t.Fatalf("expected ErrIndexerClosed subscribing to a closed hub, got %v", err)
+	}
+}
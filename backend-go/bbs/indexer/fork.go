@@ -0,0 +1,243 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// ForkPolicy decides what an Indexer does when a board's newly-announced
+// log head shares no common ancestor with the history it's already
+// processed for that board — i.e. a different writer's branch, or a
+// re-org after a rewritten log.
+type ForkPolicy int
+
+const (
+	// ForkPolicyReject refuses the new branch outright; SyncBoardByMetaCID
+	// returns ErrForkRejected and the board's indexed state is untouched.
+	// This is the zero value, so a fresh Indexer never rewrites history
+	// unless explicitly configured to.
+	ForkPolicyReject ForkPolicy = iota
+	// ForkPolicyPreferLongerChain accepts the new branch only if it's
+	// strictly deeper (more log entries) than what's currently indexed for
+	// the board, unwinding the old branch first.
+	ForkPolicyPreferLongerChain
+	// ForkPolicyPreferFirstSeen accepts the new branch only if this exact
+	// head was recorded in board_branches with an earlier first_seen_at
+	// than the branch currently indexed — i.e. it switches back to a
+	// branch this indexer saw before the one it currently has, rather than
+	// chasing whichever branch happens to be announced most recently.
+	ForkPolicyPreferFirstSeen
+)
+
+func (p ForkPolicy) String() string {
+	switch p {
+	case ForkPolicyReject:
+		return "reject"
+	case ForkPolicyPreferLongerChain:
+		return "prefer-longer-chain"
+	case ForkPolicyPreferFirstSeen:
+		return "prefer-first-seen"
+	default:
+		return fmt.Sprintf("ForkPolicy(%d)", int(p))
+	}
+}
+
+// ErrForkRejected is returned by SyncBoardByMetaCID when a board's new log
+// head diverges from its already-processed history and i.ForkPolicy didn't
+// accept the new branch.
+var ErrForkRejected = errors.New("board log fork rejected")
+
+func (i *Indexer) boardHasProcessedLogs(ctx context.Context, boardID string) (bool, error) {
+	var n int
+	if err := i.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM processed_logs WHERE board_id = ?`, boardID).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (i *Indexer) processedLogDepth(ctx context.Context, boardID string) (int, error) {
+	var n int
+	if err := i.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM processed_logs WHERE board_id = ? AND valid_sig = 1`, boardID).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// branchFirstSeen looks up when headCID was first recorded as a branch for
+// boardID, if ever.
+func (i *Indexer) branchFirstSeen(ctx context.Context, boardID, headCID string) (firstSeenAt string, ok bool, err error) {
+	err = i.db.QueryRowContext(ctx, `SELECT first_seen_at FROM board_branches WHERE board_id = ? AND head_cid = ?`, boardID, headCID).Scan(&firstSeenAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return firstSeenAt, true, nil
+}
+
+func (i *Indexer) recordBoardBranch(ctx context.Context, boardID, headCID string, depth int) error {
+	_, err := i.db.ExecContext(ctx, `
+		INSERT INTO board_branches(board_id, head_cid, depth, first_seen_at)
+		VALUES(?, ?, ?, ?)
+		ON CONFLICT(board_id, head_cid) DO NOTHING
+	`, boardID, headCID, depth, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// handleFork decides, per i.ForkPolicy, whether the board's current
+// processed history (whose head is currentHeadCID, the board's previously
+// indexed head — NOT the just-announced one) should be unwound to make
+// room for the new branch rooted at headCID, and does so if accepted.
+// newChainDepth is the number of (as yet unapplied) log entries the new
+// branch walk collected.
+func (i *Indexer) handleFork(ctx context.Context, boardID, currentHeadCID, headCID, terminalPrevCID string, newChainDepth int) (accepted bool, err error) {
+	switch i.ForkPolicy {
+	case ForkPolicyReject:
+		return false, nil
+
+	case ForkPolicyPreferLongerChain:
+		existingDepth, err := i.processedLogDepth(ctx, boardID)
+		if err != nil {
+			return false, err
+		}
+		if newChainDepth <= existingDepth {
+			return false, nil
+		}
+
+	case ForkPolicyPreferFirstSeen:
+		var currentFirstSeen string
+		if currentHeadCID != "" {
+			seen, ok, err := i.branchFirstSeen(ctx, boardID, currentHeadCID)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				// The currently-indexed branch was never itself recorded as
+				// a branch (it's the board's original, un-forked history),
+				// so it's older than any branch we could be switching to.
+				return false, nil
+			}
+			currentFirstSeen = seen
+		}
+		newFirstSeen, ok, err := i.branchFirstSeen(ctx, boardID, headCID)
+		if err != nil {
+			return false, err
+		}
+		if !ok || newFirstSeen >= currentFirstSeen {
+			return false, nil
+		}
+
+	default:
+		return false, fmt.Errorf("unknown fork policy %v", i.ForkPolicy)
+	}
+
+	if err := i.unwindBoardHistory(ctx, boardID); err != nil {
+		return false, err
+	}
+	if err := i.recordBoardBranch(ctx, boardID, headCID, newChainDepth); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// boardLogHead returns boards.log_head_cid for boardID ("" if the board
+// isn't known yet), i.e. the head this indexer has actually processed up
+// to — distinct from whatever a freshly-fetched BoardMeta claims.
+func (i *Indexer) boardLogHead(ctx context.Context, boardID string) (string, error) {
+	var head sql.NullString
+	err := i.db.QueryRowContext(ctx, `SELECT log_head_cid FROM boards WHERE board_id = ?`, boardID).Scan(&head)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return head.String, nil
+}
+
+// unwindBoardHistory reverses every processed_logs entry for boardID, in
+// the reverse order they were applied, undoing exactly the mutations
+// applyLog made (see reverseLogOp), then removes those processed_logs rows
+// so the board's history starts clean for the incoming branch.
+func (i *Indexer) unwindBoardHistory(ctx context.Context, boardID string) error {
+	rows, err := i.db.QueryContext(ctx, `
+		SELECT log_cid FROM processed_logs WHERE board_id = ? AND valid_sig = 1 ORDER BY rowid DESC
+	`, boardID)
+	if err != nil {
+		return err
+	}
+	var logCIDs []string
+	for rows.Next() {
+		var cid string
+		if err := rows.Scan(&cid); err != nil {
+			rows.Close()
+			return err
+		}
+		logCIDs = append(logCIDs, cid)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, cid := range logCIDs {
+		e, err := i.storage.LoadBoardLogEntry(ctx, cid)
+		if err != nil {
+			return fmt.Errorf("unwind %s: %w", cid, err)
+		}
+		if err := i.reverseLogOp(ctx, e); err != nil {
+			return fmt.Errorf("unwind %s: %w", cid, err)
+		}
+	}
+
+	_, err = i.db.ExecContext(ctx, `DELETE FROM processed_logs WHERE board_id = ?`, boardID)
+	return err
+}
+
+// reverseLogOp undoes exactly the per-op mutation applyLog made to
+// thread_posts for e: the inverse of applyCreateThread/applyAddPost is
+// deleting the thread_posts row they inserted, the inverse of
+// applyEditPost is reverting post_cid back to OldPostCID, and the inverse
+// of applyTombstone is clearing the tombstone flags. posts/threads rows are
+// left in place — they're content-addressed and harmless to keep around.
+func (i *Indexer) reverseLogOp(ctx context.Context, e *types.BoardLogEntry) error {
+	switch e.Op {
+	case types.OpCreateThread, types.OpAddPost:
+		if e.PostCID == nil || *e.PostCID == "" {
+			return nil
+		}
+		_, err := i.db.ExecContext(ctx, `
+			DELETE FROM thread_posts WHERE thread_id = ? AND post_cid = ?
+		`, e.ThreadID, *e.PostCID)
+		return err
+
+	case types.OpEditPost:
+		if e.OldPostCID == nil || *e.OldPostCID == "" || e.NewPostCID == nil || *e.NewPostCID == "" {
+			return nil
+		}
+		_, err := i.db.ExecContext(ctx, `
+			UPDATE thread_posts SET post_cid = ? WHERE thread_id = ? AND post_cid = ?
+		`, *e.OldPostCID, e.ThreadID, *e.NewPostCID)
+		return err
+
+	case types.OpTombstonePost:
+		if e.TargetPostCID == nil || *e.TargetPostCID == "" {
+			return nil
+		}
+		_, err := i.db.ExecContext(ctx, `
+			UPDATE thread_posts
+			SET tombstoned = 0, tombstone_reason = NULL, tombstone_created_at = NULL, tombstone_author_pubkey = NULL
+			WHERE thread_id = ? AND post_cid = ?
+		`, e.ThreadID, *e.TargetPostCID)
+		return err
+
+	default:
+		return fmt.Errorf("unknown op: %s", e.Op)
+	}
+}
@@ -0,0 +1,214 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// fakeLogEntryServer serves a fixed set of BoardLogEntry values keyed by CID,
+// speaking just enough of the Flexible-IPFS dht/getvalue protocol for
+// Storage.LoadBoardLogEntry to work against it.
+func fakeLogEntryServer(t *testing.T, entries map[string]*types.BoardLogEntry) *storage.Storage {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cid := r.URL.Query().Get("cid")
+		e, ok := entries[cid]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal entry %s: %v", cid, err)
+		}
+		wrapped, err := json.Marshal(string(b))
+		if err != nil {
+			t.Fatalf("wrap entry %s: %v", cid, err)
+		}
+		_, _ = w.Write(wrapped)
+	}))
+	t.Cleanup(srv.Close)
+	return storage.New(flexipfs.New(srv.URL + "/api/v0"))
+}
+
+func strp(s string) *string { return &s }
+
+func seedThreadPost(t *testing.T, ix *Indexer, threadID, postCID string, ordinal int) {
+	t.Helper()
+	if _, err := ix.db.ExecContext(context.Background(), `
+		INSERT INTO thread_posts(thread_id, ordinal, post_cid) VALUES(?, ?, ?)
+	`, threadID, ordinal, postCID); err != nil {
+		t.Fatalf("seed thread_posts: %v", err)
+	}
+}
+
+func seedProcessedLog(t *testing.T, ix *Indexer, boardID, logCID, threadID, op, prevLogCID string) {
+	t.Helper()
+	var prev any
+	if prevLogCID != "" {
+		prev = prevLogCID
+	}
+	if _, err := ix.db.ExecContext(context.Background(), `
+		INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
+		VALUES(?, ?, ?, ?, ?, ?, ?, 1)
+	`, logCID, boardID, threadID, op, "2025-01-01T00:00:00Z", "author1", prev); err != nil {
+		t.Fatalf("seed processed_logs: %v", err)
+	}
+}
+
+func TestUnwindBoardHistory_ReversesAddPostAndRestoresCleanState(t *testing.T) {
+	entries := map[string]*types.BoardLogEntry{
+		"log_1": {Type: "boardLogEntry", BoardID: "bbs.general", Op: types.OpAddPost, ThreadID: "thread_1", PostCID: strp("post_1")},
+		"log_2": {Type: "boardLogEntry", BoardID: "bbs.general", Op: types.OpAddPost, ThreadID: "thread_1", PostCID: strp("post_2"), PrevLogCID: strp("log_1")},
+	}
+	st := fakeLogEntryServer(t, entries)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	seedThreadPost(t, ix, "thread_1", "post_1", 1)
+	seedThreadPost(t, ix, "thread_1", "post_2", 2)
+	seedProcessedLog(t, ix, "bbs.general", "log_1", "thread_1", types.OpAddPost, "")
+	seedProcessedLog(t, ix, "bbs.general", "log_2", "thread_1", types.OpAddPost, "log_1")
+
+	if err := ix.unwindBoardHistory(context.Background(), "bbs.general"); err != nil {
+		t.Fatalf("unwindBoardHistory: %v", err)
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM thread_posts WHERE thread_id = ?`, "thread_1").Scan(&n); err != nil {
+		t.Fatalf("count thread_posts: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("thread_posts not fully unwound, %d rows remain", n)
+	}
+
+	has, err := ix.boardHasProcessedLogs(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("boardHasProcessedLogs: %v", err)
+	}
+	if has {
+		t.Fatalf("expected processed_logs cleared for board after unwind")
+	}
+}
+
+func TestHandleFork_RejectPolicyNeverAccepts(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	accepted, err := ix.handleFork(context.Background(), "bbs.general", "old_head", "new_head", "", 5)
+	if err != nil {
+		t.Fatalf("handleFork: %v", err)
+	}
+	if accepted {
+		t.Fatalf("ForkPolicyReject must never accept a fork")
+	}
+}
+
+func TestHandleFork_PreferLongerChainAcceptsOnlyWhenStrictlyDeeper(t *testing.T) {
+	entries := map[string]*types.BoardLogEntry{
+		"log_1": {Type: "boardLogEntry", BoardID: "bbs.general", Op: types.OpAddPost, ThreadID: "thread_1", PostCID: strp("post_1")},
+	}
+	st := fakeLogEntryServer(t, entries)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	ix.ForkPolicy = ForkPolicyPreferLongerChain
+
+	seedThreadPost(t, ix, "thread_1", "post_1", 1)
+	seedProcessedLog(t, ix, "bbs.general", "log_1", "thread_1", types.OpAddPost, "")
+
+	// A competing branch of equal depth must be rejected.
+	accepted, err := ix.handleFork(context.Background(), "bbs.general", "old_head", "new_head_short", "", 1)
+	if err != nil {
+		t.Fatalf("handleFork (equal depth): %v", err)
+	}
+	if accepted {
+		t.Fatalf("equal-depth branch must not be accepted")
+	}
+
+	// A strictly longer branch must be accepted and unwind the old history.
+	accepted, err = ix.handleFork(context.Background(), "bbs.general", "old_head", "new_head_long", "", 2)
+	if err != nil {
+		t.Fatalf("handleFork (longer): %v", err)
+	}
+	if !accepted {
+		t.Fatalf("strictly-longer branch must be accepted")
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM thread_posts WHERE thread_id = ?`, "thread_1").Scan(&n); err != nil {
+		t.Fatalf("count thread_posts: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("old branch's thread_posts should have been unwound, %d rows remain", n)
+	}
+
+	seen, ok, err := ix.branchFirstSeen(context.Background(), "bbs.general", "new_head_long")
+	if err != nil {
+		t.Fatalf("branchFirstSeen: %v", err)
+	}
+	if !ok || seen == "" {
+		t.Fatalf("expected new branch to be recorded in board_branches")
+	}
+}
+
+func TestHandleFork_PreferFirstSeenRejectsNewRejectsThenAcceptsKnownEarlierBranch(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	ix.ForkPolicy = ForkPolicyPreferFirstSeen
+
+	// The current branch was never itself recorded as a board_branches row
+	// (it's the original, un-forked history), so any never-before-seen
+	// competing branch must be rejected.
+	accepted, err := ix.handleFork(context.Background(), "bbs.general", "genesis_head", "unseen_head", "", 3)
+	if err != nil {
+		t.Fatalf("handleFork (unseen): %v", err)
+	}
+	if accepted {
+		t.Fatalf("a brand-new, never-recorded fork must be rejected under prefer-first-seen")
+	}
+
+	// Record "branch_a" as seen earlier than "branch_b" (inserted directly
+	// with explicit, unambiguously-ordered timestamps rather than via
+	// recordBoardBranch's time.Now(), which only has second resolution),
+	// then simulate the indexer currently sitting on branch_b and being
+	// asked to switch back to the earlier-seen branch_a.
+	for _, branch := range []struct{ headCID, firstSeenAt string }{
+		{"branch_a", "2025-01-01T00:00:00Z"},
+		{"branch_b", "2025-01-01T00:01:00Z"},
+	} {
+		if _, err := ix.db.ExecContext(context.Background(), `
+			INSERT INTO board_branches(board_id, head_cid, depth, first_seen_at) VALUES(?, ?, ?, ?)
+		`, "bbs.general", branch.headCID, 1, branch.firstSeenAt); err != nil {
+			t.Fatalf("seed board_branches %s: %v", branch.headCID, err)
+		}
+	}
+
+	accepted, err = ix.handleFork(context.Background(), "bbs.general", "branch_b", "branch_a", "", 1)
+	if err != nil {
+		t.Fatalf("handleFork (switch back): %v", err)
+	}
+	if !accepted {
+		t.Fatalf("switching back to an earlier-recorded branch must be accepted under prefer-first-seen")
+	}
+}
@@ -21,6 +21,28 @@ var ErrIndexerClosed = errors.New("indexer closed")
 type Indexer struct {
 	db      *sql.DB
 	storage *storage.Storage
+
+	// store holds the dialect-specific SQL for the boards/threads/posts
+	// write path (see store.go). Open defaults this to sqliteStore{};
+	// OpenWithStore lets a caller plug in another backend.
+	store Store
+
+	// tx is the active WithTx batch, if any; see WithTx and runInTx.
+	tx *sql.Tx
+
+	// events fans out PostIndexed/ThreadCreated/BoardLogAdvanced/
+	// PostTombstoned events to Subscribe callers; see events.go.
+	events *eventHub
+	// pendingEvents buffers events emitted by the write currently in
+	// progress, flushed once its transaction commits or discarded if it
+	// rolls back; see emit, flushPendingEvents, discardPendingEvents.
+	pendingEvents []Event
+
+	// ForkPolicy decides what happens when a synced board's new log head
+	// shares no common ancestor with what's already been processed for that
+	// board (see ForkPolicy* constants). Defaults to ForkPolicyReject, the
+	// safe choice: an indexer never rewrites history unless told to.
+	ForkPolicy ForkPolicy
 }
 
 func Open(path string, st *storage.Storage) (*Indexer, error) {
@@ -44,7 +66,7 @@ func Open(path string, st *storage.Storage) (*Indexer, error) {
 	}
 	db.SetMaxOpenConns(1)
 
-	ix := &Indexer{db: db, storage: st}
+	ix := &Indexer{db: db, storage: st, store: sqliteStore{}, events: newEventHub(0)}
 	if err := ix.migrate(context.Background()); err != nil {
 		_ = db.Close()
 		return nil, err
@@ -56,6 +78,7 @@ func (i *Indexer) Close() error {
 	if i.db == nil {
 		return nil
 	}
+	i.events.close()
 	err := i.db.Close()
 	i.db = nil
 	return err
@@ -74,6 +97,14 @@ func (i *Indexer) SyncBoardByMetaCID(ctx context.Context, boardMetaCID string) e
 		return fmt.Errorf("invalid boardMeta signature cid=%s", boardMetaCID)
 	}
 
+	// Capture the board's previously-indexed head before upsertBoard
+	// overwrites boards.log_head_cid with whatever this BoardMeta claims —
+	// handleFork needs the OLD head to compare branches, not the new one.
+	previousHeadCID, err := i.boardLogHead(ctx, bm.BoardID)
+	if err != nil {
+		return err
+	}
+
 	if err := i.upsertBoard(ctx, boardMetaCID, bm); err != nil {
 		return err
 	}
@@ -89,10 +120,27 @@ func (i *Indexer) SyncBoardByMetaCID(ctx context.Context, boardMetaCID string) e
 		return nil
 	}
 
-	newCIDs, err := i.collectUnprocessedLogCIDs(ctx, headCID, 50_000)
+	newCIDs, foundAncestor, terminalPrevCID, err := i.collectUnprocessedLogCIDs(ctx, headCID, 50_000)
 	if err != nil {
 		return err
 	}
+
+	if !foundAncestor {
+		hasExisting, err := i.boardHasProcessedLogs(ctx, bm.BoardID)
+		if err != nil {
+			return err
+		}
+		if hasExisting {
+			accepted, err := i.handleFork(ctx, bm.BoardID, previousHeadCID, headCID, terminalPrevCID, len(newCIDs))
+			if err != nil {
+				return err
+			}
+			if !accepted {
+				return fmt.Errorf("%w: board=%s head=%s policy=%s", ErrForkRejected, bm.BoardID, headCID, i.ForkPolicy)
+			}
+		}
+	}
+
 	for _, cid := range newCIDs {
 		if err := i.applyLog(ctx, cid); err != nil {
 			return err
@@ -102,10 +150,19 @@ func (i *Indexer) SyncBoardByMetaCID(ctx context.Context, boardMetaCID string) e
 	if err := i.setBoardLogHead(ctx, bm.BoardID, headCID); err != nil {
 		return err
 	}
+	i.events.publish(Event{Type: EventBoardLogAdvanced, BoardID: bm.BoardID, HeadCID: headCID})
 	return nil
 }
 
-func (i *Indexer) collectUnprocessedLogCIDs(ctx context.Context, headCID string, maxDepth int) ([]string, error) {
+// collectUnprocessedLogCIDs walks backward from headCID via PrevLogCID,
+// oldest-unprocessed-first, stopping as soon as it reaches a log_cid already
+// recorded in processed_logs (foundAncestor=true: the normal append-only
+// case, safe to replay newCIDs forward from there). If it instead runs out
+// of chain — PrevLogCID is empty (true genesis) or the next entry can't be
+// loaded (unreachable, e.g. garbage collected) — foundAncestor is false and
+// terminalPrevCID names where the walk gave up, for SyncBoardByMetaCID to
+// fork-check against this board's existing processed_logs.
+func (i *Indexer) collectUnprocessedLogCIDs(ctx context.Context, headCID string, maxDepth int) (cids []string, foundAncestor bool, terminalPrevCID string, err error) {
 	visited := make(map[string]struct{})
 	var newestFirst []string
 
@@ -115,34 +172,113 @@ func (i *Indexer) collectUnprocessedLogCIDs(ctx context.Context, headCID string,
 			break
 		}
 		if len(newestFirst) >= maxDepth {
-			return nil, bbslog.ErrLogTooDeep
+			return nil, false, "", bbslog.ErrChainTooLong
 		}
 		visited[current] = struct{}{}
 
 		processed, err := i.isLogProcessed(ctx, current)
 		if err != nil {
-			return nil, err
+			return nil, false, "", err
 		}
 		if processed {
-			break
+			reverseStrings(newestFirst)
+			return newestFirst, true, "", nil
 		}
 
-		e, err := i.storage.LoadBoardLogEntry(ctx, current)
-		if err != nil {
-			return nil, err
+		e, loadErr := i.storage.LoadBoardLogEntry(ctx, current)
+		if loadErr != nil {
+			// current's own data can't be read, so the walk can't go any
+			// further back than this; current is the terminal point.
+			reverseStrings(newestFirst)
+			return newestFirst, false, current, nil
 		}
 		newestFirst = append(newestFirst, current)
 
 		if e.PrevLogCID == nil || *e.PrevLogCID == "" {
-			break
+			reverseStrings(newestFirst)
+			return newestFirst, false, "", nil
 		}
 		current = *e.PrevLogCID
 	}
 
-	for i, j := 0, len(newestFirst)-1; i < j; i, j = i+1, j-1 {
-		newestFirst[i], newestFirst[j] = newestFirst[j], newestFirst[i]
+	reverseStrings(newestFirst)
+	return newestFirst, false, "", nil
+}
+
+func reverseStrings(s []string) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
 	}
-	return newestFirst, nil
+}
+
+// Querier is satisfied by both *sql.DB and *sql.Tx, letting the apply
+// helpers below run their writes either directly against the database or
+// inside a transaction without needing two copies of each helper. It's
+// exported so a Store implementation outside this package (see store.go
+// and bbs/store/pgstore) can be handed the same *sql.DB/*sql.Tx this
+// package already threads through runInTx and WithTx.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// runInTx runs fn against a single transaction covering all of its writes.
+// If a WithTx batch is already in progress, fn joins that transaction
+// (committed together with the rest of the batch); otherwise runInTx opens,
+// commits, and closes a transaction just for fn, so a single applyLog call
+// is never left half-applied by a cancellation or sqlite error partway
+// through.
+func (i *Indexer) runInTx(ctx context.Context, fn func(q Querier) error) error {
+	if i.tx != nil {
+		return fn(i.tx)
+	}
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		i.discardPendingEvents()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		i.discardPendingEvents()
+		return err
+	}
+	i.flushPendingEvents()
+	return nil
+}
+
+// WithTx batches every log Indexer applies while fn runs into a single
+// transaction, committing once fn returns successfully and rolling back
+// otherwise — useful when syncing many log entries at once, to pay for one
+// commit instead of one per log. WithTx batches don't nest. Like the rest of
+// Indexer, WithTx assumes single-threaded use (the underlying *sql.DB is
+// opened with SetMaxOpenConns(1) for the same reason), so fn must not be run
+// concurrently with other calls against i.
+func (i *Indexer) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if i.tx != nil {
+		return fmt.Errorf("indexer: WithTx does not nest")
+	}
+
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	i.tx = tx
+	defer func() { i.tx = nil }()
+
+	if err := fn(ctx); err != nil {
+		_ = tx.Rollback()
+		i.discardPendingEvents()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		i.discardPendingEvents()
+		return err
+	}
+	i.flushPendingEvents()
+	return nil
 }
 
 func (i *Indexer) applyLog(ctx context.Context, logCID string) error {
@@ -152,28 +288,35 @@ func (i *Indexer) applyLog(ctx context.Context, logCID string) error {
 	}
 	validSig := bbslog.VerifyBoardLogEntry(e)
 
-	if err := i.insertProcessedLog(ctx, logCID, e, validSig); err != nil {
-		return err
-	}
 	if !validSig {
-		return nil
+		// No op to apply, just the rejection record — still committed in its
+		// own transaction so it's never retried, without opening a tx for
+		// writes that aren't going to happen.
+		return i.runInTx(ctx, func(q Querier) error {
+			return i.insertProcessedLog(ctx, q, logCID, e, validSig)
+		})
 	}
 
-	switch e.Op {
-	case types.OpCreateThread:
-		return i.applyCreateThread(ctx, e)
-	case types.OpAddPost:
-		return i.applyAddPost(ctx, e)
-	case types.OpEditPost:
-		return i.applyEditPost(ctx, e)
-	case types.OpTombstonePost:
-		return i.applyTombstone(ctx, e)
-	default:
-		return fmt.Errorf("unknown op: %s", e.Op)
-	}
+	return i.runInTx(ctx, func(q Querier) error {
+		if err := i.insertProcessedLog(ctx, q, logCID, e, validSig); err != nil {
+			return err
+		}
+		switch e.Op {
+		case types.OpCreateThread:
+			return i.applyCreateThread(ctx, q, e)
+		case types.OpAddPost:
+			return i.applyAddPost(ctx, q, e)
+		case types.OpEditPost:
+			return i.applyEditPost(ctx, q, e)
+		case types.OpTombstonePost:
+			return i.applyTombstone(ctx, q, e)
+		default:
+			return fmt.Errorf("unknown op: %s", e.Op)
+		}
+	})
 }
 
-func (i *Indexer) applyCreateThread(ctx context.Context, e *types.BoardLogEntry) error {
+func (i *Indexer) applyCreateThread(ctx context.Context, q Querier, e *types.BoardLogEntry) error {
 	if e.PostCID == nil || *e.PostCID == "" {
 		return nil
 	}
@@ -192,20 +335,21 @@ func (i *Indexer) applyCreateThread(ctx context.Context, e *types.BoardLogEntry)
 	if tmCopy.RootPostCID == "" {
 		tmCopy.RootPostCID = *e.PostCID
 	}
-	if err := i.upsertThread(ctx, threadCID, &tmCopy); err != nil {
+	if err := i.upsertThread(ctx, q, threadCID, &tmCopy); err != nil {
 		return err
 	}
-	return i.appendPost(ctx, e.BoardID, threadCID, *e.PostCID)
+	i.emit(Event{Type: EventThreadCreated, BoardID: e.BoardID, ThreadID: threadCID, AuthorPubKey: tmCopy.CreatedBy, CreatedAt: tmCopy.CreatedAt})
+	return i.appendPost(ctx, q, e.BoardID, threadCID, *e.PostCID)
 }
 
-func (i *Indexer) applyAddPost(ctx context.Context, e *types.BoardLogEntry) error {
+func (i *Indexer) applyAddPost(ctx context.Context, q Querier, e *types.BoardLogEntry) error {
 	if e.PostCID == nil || *e.PostCID == "" {
 		return nil
 	}
-	return i.appendPost(ctx, e.BoardID, e.ThreadID, *e.PostCID)
+	return i.appendPost(ctx, q, e.BoardID, e.ThreadID, *e.PostCID)
 }
 
-func (i *Indexer) applyEditPost(ctx context.Context, e *types.BoardLogEntry) error {
+func (i *Indexer) applyEditPost(ctx context.Context, q Querier, e *types.BoardLogEntry) error {
 	if e.OldPostCID == nil || *e.OldPostCID == "" || e.NewPostCID == nil || *e.NewPostCID == "" {
 		return nil
 	}
@@ -229,19 +373,25 @@ func (i *Indexer) applyEditPost(ctx context.Context, e *types.BoardLogEntry) err
 	if e.AuthorPubKey != oldP.AuthorPubKey || e.AuthorPubKey != newP.AuthorPubKey {
 		return nil
 	}
+	ok, err := i.postSatisfiesEncryptionPolicy(ctx, q, e.BoardID, newP)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
 
-	if err := i.upsertPost(ctx, newCID, newP); err != nil {
+	if err := i.upsertPost(ctx, q, newCID, newP); err != nil {
 		return err
 	}
-	_, err = i.db.ExecContext(ctx, `
-		UPDATE thread_posts
-		SET post_cid = ?
-		WHERE thread_id = ? AND post_cid = ?
-	`, newCID, e.ThreadID, oldCID)
-	return err
+	if err := i.store.UpdatePostCID(ctx, q, e.ThreadID, oldCID, newCID); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventPostIndexed, BoardID: e.BoardID, ThreadID: e.ThreadID, PostCID: newCID, AuthorPubKey: newP.AuthorPubKey, CreatedAt: newP.CreatedAt})
+	return nil
 }
 
-func (i *Indexer) applyTombstone(ctx context.Context, e *types.BoardLogEntry) error {
+func (i *Indexer) applyTombstone(ctx context.Context, q Querier, e *types.BoardLogEntry) error {
 	if e.TargetPostCID == nil || *e.TargetPostCID == "" {
 		return nil
 	}
@@ -256,15 +406,18 @@ func (i *Indexer) applyTombstone(ctx context.Context, e *types.BoardLogEntry) er
 	if e.AuthorPubKey != p.AuthorPubKey {
 		return nil
 	}
-	_, err = i.db.ExecContext(ctx, `
-		UPDATE thread_posts
-		SET tombstoned = 1, tombstone_reason = ?, tombstone_created_at = ?, tombstone_author_pubkey = ?
-		WHERE thread_id = ? AND post_cid = ?
-	`, strPtrOrEmpty(e.Reason), e.CreatedAt, e.AuthorPubKey, e.ThreadID, targetCID)
-	return err
+	reason := ""
+	if e.Reason != nil {
+		reason = *e.Reason
+	}
+	if err := i.store.Tombstone(ctx, q, e.ThreadID, targetCID, reason, e.CreatedAt, e.AuthorPubKey); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventPostTombstoned, BoardID: e.BoardID, ThreadID: e.ThreadID, PostCID: targetCID, AuthorPubKey: e.AuthorPubKey, CreatedAt: e.CreatedAt})
+	return nil
 }
 
-func (i *Indexer) appendPost(ctx context.Context, boardID, threadID, postCID string) error {
+func (i *Indexer) appendPost(ctx context.Context, q Querier, boardID, threadID, postCID string) error {
 	p, err := i.storage.LoadPost(ctx, postCID)
 	if err != nil {
 		return err
@@ -275,23 +428,20 @@ func (i *Indexer) appendPost(ctx context.Context, boardID, threadID, postCID str
 	if p.ThreadID != threadID {
 		return nil
 	}
-	if err := i.upsertPost(ctx, postCID, p); err != nil {
+	ok, err := i.postSatisfiesEncryptionPolicy(ctx, q, boardID, p)
+	if err != nil {
 		return err
 	}
-
-	var nextOrdinal int
-	if err := i.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(ordinal), -1) + 1 FROM thread_posts WHERE thread_id = ?`, threadID).Scan(&nextOrdinal); err != nil {
+	if !ok {
+		return nil
+	}
+	if err := i.upsertPost(ctx, q, postCID, p); err != nil {
 		return err
 	}
-	_, err = i.db.ExecContext(ctx, `
-		INSERT INTO thread_posts (thread_id, ordinal, post_cid, tombstoned)
-		VALUES (?, ?, ?, 0)
-		ON CONFLICT(thread_id, ordinal) DO NOTHING
-	`, threadID, nextOrdinal, postCID)
-	if err != nil {
+	if err := i.store.AppendThreadPost(ctx, q, threadID, postCID); err != nil {
 		return err
 	}
-
+	i.emit(Event{Type: EventPostIndexed, BoardID: boardID, ThreadID: threadID, PostCID: postCID, AuthorPubKey: p.AuthorPubKey, CreatedAt: p.CreatedAt})
 	return nil
 }
 
@@ -302,147 +452,66 @@ func strPtrOrEmpty(s *string) any {
 	return *s
 }
 
-func (i *Indexer) migrate(ctx context.Context) error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS boards (
-			board_id TEXT PRIMARY KEY,
-			board_meta_cid TEXT NOT NULL,
-			title TEXT NOT NULL,
-			description TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			created_by TEXT NOT NULL,
-			signature TEXT NOT NULL,
-			log_head_cid TEXT
-		);`,
-		`CREATE TABLE IF NOT EXISTS threads (
-			thread_id TEXT PRIMARY KEY,
-			board_id TEXT NOT NULL,
-			title TEXT NOT NULL,
-			root_post_cid TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			created_by TEXT NOT NULL,
-			signature TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS posts (
-			post_cid TEXT PRIMARY KEY,
-			thread_id TEXT NOT NULL,
-			parent_post_cid TEXT,
-			author_pubkey TEXT NOT NULL,
-			display_name TEXT NOT NULL,
-			body_format TEXT NOT NULL,
-			body_content TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			edited_at TEXT,
-			signature TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS thread_posts (
-			thread_id TEXT NOT NULL,
-			ordinal INTEGER NOT NULL,
-			post_cid TEXT NOT NULL,
-			tombstoned INTEGER NOT NULL DEFAULT 0,
-			tombstone_reason TEXT,
-			tombstone_created_at TEXT,
-			tombstone_author_pubkey TEXT,
-			PRIMARY KEY(thread_id, ordinal)
-		);`,
-		`CREATE TABLE IF NOT EXISTS processed_logs (
-			log_cid TEXT PRIMARY KEY,
-			board_id TEXT NOT NULL,
-			thread_id TEXT NOT NULL,
-			op TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			author_pubkey TEXT NOT NULL,
-			prev_log_cid TEXT,
-			valid_sig INTEGER NOT NULL
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_threads_board ON threads(board_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_thread ON posts(thread_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_author ON posts(author_pubkey);`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_created ON posts(created_at);`,
-		`CREATE INDEX IF NOT EXISTS idx_thread_posts_post ON thread_posts(thread_id, post_cid);`,
-	}
-
-	for _, s := range stmts {
-		if _, err := i.db.ExecContext(ctx, s); err != nil {
-			return err
-		}
+func (i *Indexer) upsertBoard(ctx context.Context, cid string, bm *types.BoardMeta) error {
+	return i.store.UpsertBoard(ctx, i.db, cid, bm)
+}
+
+// postSatisfiesEncryptionPolicy enforces boardID's BoardMeta.EncryptionPolicy
+// against p's body: a "required" board rejects any post whose body isn't
+// encrypted, a "none" board rejects any post that is, and "optional" (or an
+// unknown board) allows either. A rejected post is skipped the same way an
+// invalid signature is — silently, since it arrived off an untrusted log
+// this indexer doesn't control, not a reason to fail the whole sync.
+func (i *Indexer) postSatisfiesEncryptionPolicy(ctx context.Context, q Querier, boardID string, p *types.Post) (bool, error) {
+	policy, err := i.boardEncryptionPolicy(ctx, q, boardID)
+	if err != nil {
+		return false, err
+	}
+	encrypted := types.IsEncryptedBodyFormat(p.Body.Format)
+	switch policy {
+	case types.EncryptionPolicyRequired:
+		return encrypted, nil
+	case types.EncryptionPolicyNone:
+		return !encrypted, nil
+	default: // "", types.EncryptionPolicyOptional
+		return true, nil
 	}
-	return nil
 }
 
-func (i *Indexer) upsertBoard(ctx context.Context, cid string, bm *types.BoardMeta) error {
-	_, err := i.db.ExecContext(ctx, `
-		INSERT INTO boards(board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(board_id) DO UPDATE SET
-			board_meta_cid=excluded.board_meta_cid,
-			title=excluded.title,
-			description=excluded.description,
-			created_at=excluded.created_at,
-			created_by=excluded.created_by,
-			signature=excluded.signature,
-			log_head_cid=excluded.log_head_cid
-	`, bm.BoardID, cid, bm.Title, bm.Description, bm.CreatedAt, bm.CreatedBy, bm.Signature, strPtrOrEmpty(bm.LogHeadCID))
-	return err
+// boardEncryptionPolicy returns boards.encryption_policy for boardID,
+// defaulting to EncryptionPolicyNone for a board this indexer hasn't seen
+// yet (upsertBoard always runs before any post referencing it, so this
+// only matters for a malformed log referencing an unknown board).
+func (i *Indexer) boardEncryptionPolicy(ctx context.Context, q Querier, boardID string) (string, error) {
+	var policy string
+	err := q.QueryRowContext(ctx, `SELECT encryption_policy FROM boards WHERE board_id = ?`, boardID).Scan(&policy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.EncryptionPolicyNone, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return policy, nil
 }
 
 func (i *Indexer) setBoardLogHead(ctx context.Context, boardID, headCID string) error {
-	_, err := i.db.ExecContext(ctx, `UPDATE boards SET log_head_cid = ? WHERE board_id = ?`, headCID, boardID)
-	return err
+	return i.store.SetBoardLogHead(ctx, i.db, boardID, headCID)
 }
 
-func (i *Indexer) upsertThread(ctx context.Context, threadCID string, tm *types.ThreadMeta) error {
-	_, err := i.db.ExecContext(ctx, `
-		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
-		VALUES(?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(thread_id) DO UPDATE SET
-			board_id=excluded.board_id,
-			title=excluded.title,
-			root_post_cid=excluded.root_post_cid,
-			created_at=excluded.created_at,
-			created_by=excluded.created_by,
-			signature=excluded.signature
-	`, threadCID, tm.BoardID, tm.Title, tm.RootPostCID, tm.CreatedAt, tm.CreatedBy, tm.Signature)
-	return err
+func (i *Indexer) upsertThread(ctx context.Context, q Querier, threadCID string, tm *types.ThreadMeta) error {
+	return i.store.UpsertThread(ctx, q, threadCID, tm)
 }
 
-func (i *Indexer) upsertPost(ctx context.Context, postCID string, p *types.Post) error {
-	_, err := i.db.ExecContext(ctx, `
-		INSERT INTO posts(post_cid, thread_id, parent_post_cid, author_pubkey, display_name, body_format, body_content, created_at, edited_at, signature)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(post_cid) DO UPDATE SET
-			thread_id=excluded.thread_id,
-			parent_post_cid=excluded.parent_post_cid,
-			author_pubkey=excluded.author_pubkey,
-			display_name=excluded.display_name,
-			body_format=excluded.body_format,
-			body_content=excluded.body_content,
-			created_at=excluded.created_at,
-			edited_at=excluded.edited_at,
-			signature=excluded.signature
-	`, postCID, p.ThreadID, strPtrOrEmpty(p.ParentPostCID), p.AuthorPubKey, p.DisplayName, p.Body.Format, p.Body.Content, p.CreatedAt, strPtrOrEmpty(p.EditedAt), p.Signature)
-	return err
+func (i *Indexer) upsertPost(ctx context.Context, q Querier, postCID string, p *types.Post) error {
+	return i.store.UpsertPost(ctx, q, postCID, p)
 }
 
 func (i *Indexer) isLogProcessed(ctx context.Context, cid string) (bool, error) {
-	var n int
-	if err := i.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM processed_logs WHERE log_cid = ?`, cid).Scan(&n); err != nil {
-		return false, err
-	}
-	return n > 0, nil
+	return i.store.IsLogProcessed(ctx, i.db, cid)
 }
 
-func (i *Indexer) insertProcessedLog(ctx context.Context, cid string, e *types.BoardLogEntry, validSig bool) error {
-	v := 0
-	if validSig {
-		v = 1
-	}
-	_, err := i.db.ExecContext(ctx, `
-		INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(log_cid) DO NOTHING
-	`, cid, e.BoardID, e.ThreadID, e.Op, e.CreatedAt, e.AuthorPubKey, strPtrOrEmpty(e.PrevLogCID), v)
-	return err
+func (i *Indexer) insertProcessedLog(ctx context.Context, q Querier, cid string, e *types.BoardLogEntry, validSig bool) error {
+	return i.store.InsertProcessedLog(ctx, q, cid, e, validSig)
 }
 
 func (i *Indexer) PruneOlderThan(ctx context.Context, ttl time.Duration) error {
@@ -450,6 +519,5 @@ func (i *Indexer) PruneOlderThan(ctx context.Context, ttl time.Duration) error {
 		return nil
 	}
 	cutoff := time.Now().Add(-ttl).UTC().Format(time.RFC3339)
-	_, err := i.db.ExecContext(ctx, `DELETE FROM processed_logs WHERE created_at < ?`, cutoff)
-	return err
+	return i.store.Prune(ctx, i.db, cutoff)
 }
@@ -0,0 +1,456 @@
+package indexer
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Migration is one versioned, idempotent-by-construction step in the
+// indexer's schema history. Versions are sortable RFC3339 timestamps
+// (e.g. "2024-11-14T12:00:00Z") prefixed to the migration's name so
+// migrations list in application order just by sorting the slice they're
+// registered in.
+type Migration interface {
+	Version() string
+	Name() string
+	// Checksum identifies the exact SQL this migration applied, so --repair
+	// can detect a migration whose embedded SQL was edited after release
+	// (which sqlite, having already applied the old version, would
+	// otherwise hide).
+	Checksum() string
+	Up(ctx context.Context, tx *sql.Tx) error
+}
+
+// downMigration is implemented by migrations that know how to undo
+// themselves. Not all migrations can be reversed cleanly (e.g. one that
+// drops a column under sqlite's column-drop emulation), so Down support is
+// optional; callers type-assert for it.
+type downMigration interface {
+	Down(ctx context.Context, tx *sql.Tx) error
+}
+
+// sqlMigration is a Migration whose Up (and optional Down) are just a
+// sequence of plain SQL statements, run one at a time in the same
+// transaction — which covers every migration this indexer needs so far.
+type sqlMigration struct {
+	version   string
+	name      string
+	upStmts   []string
+	downStmts []string // nil if this migration has no Down
+}
+
+func (m *sqlMigration) Version() string { return m.version }
+func (m *sqlMigration) Name() string    { return m.name }
+
+func (m *sqlMigration) Checksum() string {
+	sum := sha256.Sum256([]byte(strings.Join(m.upStmts, ";\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *sqlMigration) Up(ctx context.Context, tx *sql.Tx) error {
+	for _, stmt := range m.upStmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *sqlMigration) Down(ctx context.Context, tx *sql.Tx) error {
+	if len(m.downStmts) == 0 {
+		return fmt.Errorf("migration %s (%s) has no Down", m.version, m.name)
+	}
+	for _, stmt := range m.downStmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrations is the ordered, append-only history of schema changes. Never
+// edit a migration that has shipped — add a new one instead, even to fix a
+// mistake in an earlier one, so --repair's checksum check stays meaningful
+// and already-deployed databases upgrade the same way a fresh one does.
+var migrations = []Migration{
+	&sqlMigration{
+		version: "2024-11-14T12:00:00Z_InitialSchema",
+		name:    "InitialSchema",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS boards (
+				board_id TEXT PRIMARY KEY,
+				board_meta_cid TEXT NOT NULL,
+				title TEXT NOT NULL,
+				description TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				created_by TEXT NOT NULL,
+				signature TEXT NOT NULL,
+				log_head_cid TEXT
+			);`,
+			`CREATE TABLE IF NOT EXISTS threads (
+				thread_id TEXT PRIMARY KEY,
+				board_id TEXT NOT NULL,
+				title TEXT NOT NULL,
+				root_post_cid TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				created_by TEXT NOT NULL,
+				signature TEXT NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS posts (
+				post_cid TEXT PRIMARY KEY,
+				thread_id TEXT NOT NULL,
+				parent_post_cid TEXT,
+				author_pubkey TEXT NOT NULL,
+				display_name TEXT NOT NULL,
+				body_format TEXT NOT NULL,
+				body_content TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				edited_at TEXT,
+				signature TEXT NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS thread_posts (
+				thread_id TEXT NOT NULL,
+				ordinal INTEGER NOT NULL,
+				post_cid TEXT NOT NULL,
+				tombstoned INTEGER NOT NULL DEFAULT 0,
+				tombstone_reason TEXT,
+				tombstone_created_at TEXT,
+				tombstone_author_pubkey TEXT,
+				PRIMARY KEY(thread_id, ordinal)
+			);`,
+			`CREATE TABLE IF NOT EXISTS processed_logs (
+				log_cid TEXT PRIMARY KEY,
+				board_id TEXT NOT NULL,
+				thread_id TEXT NOT NULL,
+				op TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				author_pubkey TEXT NOT NULL,
+				prev_log_cid TEXT,
+				valid_sig INTEGER NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_board ON threads(board_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_thread ON posts(thread_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_author ON posts(author_pubkey);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_created ON posts(created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_thread_posts_post ON thread_posts(thread_id, post_cid);`,
+		},
+		downStmts: []string{
+			`DROP INDEX IF EXISTS idx_thread_posts_post;`,
+			`DROP INDEX IF EXISTS idx_posts_created;`,
+			`DROP INDEX IF EXISTS idx_posts_author;`,
+			`DROP INDEX IF EXISTS idx_posts_thread;`,
+			`DROP INDEX IF EXISTS idx_threads_board;`,
+			`DROP TABLE IF EXISTS processed_logs;`,
+			`DROP TABLE IF EXISTS thread_posts;`,
+			`DROP TABLE IF EXISTS posts;`,
+			`DROP TABLE IF EXISTS threads;`,
+			`DROP TABLE IF EXISTS boards;`,
+		},
+	},
+	&sqlMigration{
+		version: "2025-03-02T09:00:00Z_BoardBranches",
+		name:    "BoardBranches",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS board_branches (
+				board_id TEXT NOT NULL,
+				head_cid TEXT NOT NULL,
+				depth INTEGER NOT NULL,
+				first_seen_at TEXT NOT NULL,
+				PRIMARY KEY(board_id, head_cid)
+			);`,
+		},
+		downStmts: []string{
+			`DROP TABLE IF EXISTS board_branches;`,
+		},
+	},
+	&sqlMigration{
+		version: "2025-04-10T10:00:00Z_PostsFTS",
+		name:    "PostsFTS",
+		upStmts: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+				post_cid UNINDEXED,
+				display_name,
+				body_content,
+				tokenize = 'unicode61 remove_diacritics 2'
+			);`,
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_ai AFTER INSERT ON posts BEGIN
+				INSERT INTO posts_fts(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_au AFTER UPDATE ON posts BEGIN
+				DELETE FROM posts_fts WHERE post_cid = old.post_cid;
+				INSERT INTO posts_fts(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_ad AFTER DELETE ON posts BEGIN
+				DELETE FROM posts_fts WHERE post_cid = old.post_cid;
+			END;`,
+			// Backfill rows that predate this migration; posts_fts_ai keeps it in
+			// sync with every post written from here on.
+			`INSERT INTO posts_fts(post_cid, display_name, body_content)
+				SELECT post_cid, display_name, body_content FROM posts;`,
+		},
+		downStmts: []string{
+			`DROP TRIGGER IF EXISTS posts_fts_ad;`,
+			`DROP TRIGGER IF EXISTS posts_fts_au;`,
+			`DROP TRIGGER IF EXISTS posts_fts_ai;`,
+			`DROP TABLE IF EXISTS posts_fts;`,
+		},
+	},
+	&sqlMigration{
+		version: "2025-05-18T09:00:00Z_ThreadsAndBoardsFTS",
+		name:    "ThreadsAndBoardsFTS",
+		upStmts: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS threads_fts USING fts5(
+				thread_id UNINDEXED,
+				title,
+				tokenize = 'unicode61 remove_diacritics 2'
+			);`,
+			`CREATE TRIGGER IF NOT EXISTS threads_fts_ai AFTER INSERT ON threads BEGIN
+				INSERT INTO threads_fts(thread_id, title) VALUES (new.thread_id, new.title);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS threads_fts_au AFTER UPDATE ON threads BEGIN
+				DELETE FROM threads_fts WHERE thread_id = old.thread_id;
+				INSERT INTO threads_fts(thread_id, title) VALUES (new.thread_id, new.title);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS threads_fts_ad AFTER DELETE ON threads BEGIN
+				DELETE FROM threads_fts WHERE thread_id = old.thread_id;
+			END;`,
+			`INSERT INTO threads_fts(thread_id, title) SELECT thread_id, title FROM threads;`,
+
+			`CREATE VIRTUAL TABLE IF NOT EXISTS boards_fts USING fts5(
+				board_id UNINDEXED,
+				title,
+				description,
+				tokenize = 'unicode61 remove_diacritics 2'
+			);`,
+			`CREATE TRIGGER IF NOT EXISTS boards_fts_ai AFTER INSERT ON boards BEGIN
+				INSERT INTO boards_fts(board_id, title, description) VALUES (new.board_id, new.title, new.description);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS boards_fts_au AFTER UPDATE ON boards BEGIN
+				DELETE FROM boards_fts WHERE board_id = old.board_id;
+				INSERT INTO boards_fts(board_id, title, description) VALUES (new.board_id, new.title, new.description);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS boards_fts_ad AFTER DELETE ON boards BEGIN
+				DELETE FROM boards_fts WHERE board_id = old.board_id;
+			END;`,
+			`INSERT INTO boards_fts(board_id, title, description) SELECT board_id, title, description FROM boards;`,
+		},
+		downStmts: []string{
+			`DROP TRIGGER IF EXISTS boards_fts_ad;`,
+			`DROP TRIGGER IF EXISTS boards_fts_au;`,
+			`DROP TRIGGER IF EXISTS boards_fts_ai;`,
+			`DROP TABLE IF EXISTS boards_fts;`,
+			`DROP TRIGGER IF EXISTS threads_fts_ad;`,
+			`DROP TRIGGER IF EXISTS threads_fts_au;`,
+			`DROP TRIGGER IF EXISTS threads_fts_ai;`,
+			`DROP TABLE IF EXISTS threads_fts;`,
+		},
+	},
+	&sqlMigration{
+		// A second fts5 index over posts, tokenized as trigrams rather than
+		// unicode61 words, so SearchPostsParams{Mode: "substring"} can match
+		// inside a word (e.g. a partial Japanese compound) instead of only at
+		// token boundaries. Requires sqlite built with the fts5 trigram
+		// tokenizer (3.34+); posts_fts above is unaffected either way.
+		version: "2025-05-18T09:05:00Z_PostsTrigram",
+		name:    "PostsTrigram",
+		upStmts: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS posts_trgm USING fts5(
+				post_cid UNINDEXED,
+				display_name,
+				body_content,
+				tokenize = 'trigram'
+			);`,
+			`CREATE TRIGGER IF NOT EXISTS posts_trgm_ai AFTER INSERT ON posts BEGIN
+				INSERT INTO posts_trgm(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS posts_trgm_au AFTER UPDATE ON posts BEGIN
+				DELETE FROM posts_trgm WHERE post_cid = old.post_cid;
+				INSERT INTO posts_trgm(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS posts_trgm_ad AFTER DELETE ON posts BEGIN
+				DELETE FROM posts_trgm WHERE post_cid = old.post_cid;
+			END;`,
+			`INSERT INTO posts_trgm(post_cid, display_name, body_content) SELECT post_cid, display_name, body_content FROM posts;`,
+		},
+		downStmts: []string{
+			`DROP TRIGGER IF EXISTS posts_trgm_ad;`,
+			`DROP TRIGGER IF EXISTS posts_trgm_au;`,
+			`DROP TRIGGER IF EXISTS posts_trgm_ai;`,
+			`DROP TABLE IF EXISTS posts_trgm;`,
+		},
+	},
+	&sqlMigration{
+		// Adds boards.encryption_policy (see types.BoardMeta.EncryptionPolicy)
+		// and stops posts_fts/posts_trgm from indexing encrypted bodies —
+		// body_content is a crypto.Envelope's JSON for those, which is
+		// meaningless to search and would otherwise leak ciphertext length
+		// and structure into the FTS index. The insert/update triggers are
+		// replaced with versions guarded by a WHEN clause; DELETE doesn't
+		// need one since removing a row that was never indexed is a no-op.
+		version: "2025-07-29T09:00:00Z_EncryptedPosts",
+		name:    "EncryptedPosts",
+		upStmts: []string{
+			`ALTER TABLE boards ADD COLUMN encryption_policy TEXT NOT NULL DEFAULT 'none';`,
+
+			`DROP TRIGGER IF EXISTS posts_fts_ai;`,
+			`CREATE TRIGGER posts_fts_ai AFTER INSERT ON posts WHEN new.body_format NOT LIKE 'enc+%' BEGIN
+				INSERT INTO posts_fts(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`DROP TRIGGER IF EXISTS posts_fts_au;`,
+			`CREATE TRIGGER posts_fts_au_del AFTER UPDATE ON posts BEGIN
+				DELETE FROM posts_fts WHERE post_cid = old.post_cid;
+			END;`,
+			`CREATE TRIGGER posts_fts_au_ins AFTER UPDATE ON posts WHEN new.body_format NOT LIKE 'enc+%' BEGIN
+				INSERT INTO posts_fts(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+
+			`DROP TRIGGER IF EXISTS posts_trgm_ai;`,
+			`CREATE TRIGGER posts_trgm_ai AFTER INSERT ON posts WHEN new.body_format NOT LIKE 'enc+%' BEGIN
+				INSERT INTO posts_trgm(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+			`DROP TRIGGER IF EXISTS posts_trgm_au;`,
+			`CREATE TRIGGER posts_trgm_au_del AFTER UPDATE ON posts BEGIN
+				DELETE FROM posts_trgm WHERE post_cid = old.post_cid;
+			END;`,
+			`CREATE TRIGGER posts_trgm_au_ins AFTER UPDATE ON posts WHEN new.body_format NOT LIKE 'enc+%' BEGIN
+				INSERT INTO posts_trgm(post_cid, display_name, body_content) VALUES (new.post_cid, new.display_name, new.body_content);
+			END;`,
+
+			// Rows that predate this migration may already have indexed an
+			// encrypted post (body_format was "enc+..." but the old
+			// unconditional triggers indexed it anyway); drop those now that
+			// they'd no longer be inserted going forward.
+			`DELETE FROM posts_fts WHERE post_cid IN (SELECT post_cid FROM posts WHERE body_format LIKE 'enc+%');`,
+			`DELETE FROM posts_trgm WHERE post_cid IN (SELECT post_cid FROM posts WHERE body_format LIKE 'enc+%');`,
+		},
+	},
+	&sqlMigration{
+		// peers backs indexer-to-indexer peering (peers.go):
+		// EstablishPeering inserts a row per peer this node has accepted a
+		// GeneratePeeringToken from; last_pulled_at records when PullFromPeer
+		// last succeeded for the peer. PullFromPeer does not filter on it:
+		// every pull re-announces every board head the peer serves.
+		version: "2025-08-05T09:00:00Z_IndexerPeers",
+		name:    "IndexerPeers",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS peers (
+				name TEXT PRIMARY KEY,
+				pubkey TEXT NOT NULL,
+				endpoint TEXT NOT NULL,
+				established_at TEXT NOT NULL,
+				last_pulled_at TEXT
+			);`,
+		},
+		downStmts: []string{
+			`DROP TABLE IF EXISTS peers;`,
+		},
+	},
+}
+
+// migrate brings the schema up to date: it ensures migration_history
+// exists, then applies every registered migration whose version isn't
+// already recorded there, each in its own transaction, recording a
+// migration_history row on success.
+func (i *Indexer) migrate(ctx context.Context) error {
+	if _, err := i.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS migration_history (
+		version TEXT PRIMARY KEY,
+		applied_at TEXT NOT NULL,
+		checksum TEXT NOT NULL
+	);`); err != nil {
+		return err
+	}
+
+	applied, err := i.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version()]; ok {
+			continue
+		}
+		if err := i.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("migration %s (%s): %w", m.Version(), m.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (i *Indexer) appliedMigrations(ctx context.Context) (map[string]string, error) {
+	rows, err := i.db.QueryContext(ctx, `SELECT version, checksum FROM migration_history`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]string)
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	return applied, rows.Err()
+}
+
+func (i *Indexer) applyMigration(ctx context.Context, m Migration) error {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO migration_history(version, applied_at, checksum) VALUES(?, ?, ?)
+	`, m.Version(), time.Now().UTC().Format(time.RFC3339), m.Checksum()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationChecksumMismatch describes one migration whose recorded checksum
+// no longer matches the SQL embedded in the running binary.
+type MigrationChecksumMismatch struct {
+	Version  string
+	Name     string
+	Recorded string
+	Current  string
+}
+
+// VerifyMigrationChecksums re-checks every applied migration's recorded
+// checksum against the SQL compiled into this binary, for a developer-only
+// `--repair` mode: a mismatch means the migration's source changed after it
+// was already applied to this database, so the schema may no longer match
+// what the binary expects.
+func (i *Indexer) VerifyMigrationChecksums(ctx context.Context) ([]MigrationChecksumMismatch, error) {
+	applied, err := i.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []MigrationChecksumMismatch
+	for _, m := range migrations {
+		recorded, ok := applied[m.Version()]
+		if !ok {
+			continue
+		}
+		if current := m.Checksum(); current != recorded {
+			mismatches = append(mismatches, MigrationChecksumMismatch{
+				Version:  m.Version(),
+				Name:     m.Name(),
+				Recorded: recorded,
+				Current:  current,
+			})
+		}
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,70 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/storage"
+)
+
+func TestMigrate_RecordsHistoryAndIsIdempotent(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	applied, err := ix.appliedMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("appliedMigrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("recorded %d migrations, want %d", len(applied), len(migrations))
+	}
+
+	// Re-running migrate against an already-migrated db must be a no-op.
+	if err := ix.migrate(context.Background()); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	applied2, err := ix.appliedMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("appliedMigrations after second migrate: %v", err)
+	}
+	if len(applied2) != len(applied) {
+		t.Fatalf("migration_history grew on a repeat migrate: %d -> %d", len(applied), len(applied2))
+	}
+}
+
+func TestVerifyMigrationChecksums_DetectsTamperedHistory(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	mismatches, err := ix.VerifyMigrationChecksums(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyMigrationChecksums: %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("expected no mismatches on a freshly migrated db, got %v", mismatches)
+	}
+
+	if _, err := ix.db.ExecContext(context.Background(),
+		`UPDATE migration_history SET checksum = 'deadbeef' WHERE version = ?`,
+		migrations[0].Version(),
+	); err != nil {
+		t.Fatalf("tamper with migration_history: %v", err)
+	}
+
+	mismatches, err = ix.VerifyMigrationChecksums(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyMigrationChecksums after tampering: %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Version != migrations[0].Version() {
+		t.Fatalf("mismatches = %#v, want exactly migration %s flagged", mismatches, migrations[0].Version())
+	}
+}
@@ -0,0 +1,280 @@
+package indexer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+// Peer is one entry in the peers table: another indexer this node has
+// accepted a peering token from (via EstablishPeering), and whose board
+// heads PullFromPeer pulls from.
+type Peer struct {
+	Name          string
+	PubKey        string // base64 standard encoding of the peer's ed25519 public key
+	Endpoint      string // the peer's HTTP base URL, normalized by config.NormalizeBaseURL
+	EstablishedAt time.Time
+	LastPulledAt  time.Time // zero if PullFromPeer has never succeeded for this peer
+}
+
+// peeringTokenPayload is GeneratePeeringToken's signed content: a
+// self-certifying claim "the holder of PubKey's private key is willing to
+// be called PeerName at Endpoint". EstablishPeering verifies Sig against
+// PubKey (embedded in the same payload) rather than against any key it
+// already knows, the same trust-on-first-use model
+// config.TrustedIndexersStore uses for indexer base URLs.
+type peeringTokenPayload struct {
+	PeerName string `json:"peerName"`
+	PubKey   string `json:"pubKey"`
+	Endpoint string `json:"endpoint"`
+	Nonce    string `json:"nonce"`
+	IssuedAt string `json:"issuedAt"`
+}
+
+type peeringToken struct {
+	Payload peeringTokenPayload `json:"payload"`
+	Sig     string              `json:"sig"`
+}
+
+// ErrPeeringTokenInvalid is returned by EstablishPeering for a token whose
+// signature doesn't verify against its own embedded public key.
+var ErrPeeringTokenInvalid = errors.New("indexer: peering token signature invalid")
+
+// GeneratePeeringToken mints a signed peering token for peerName/localURL,
+// offered to another indexer (out of band — e.g. pasted into its
+// EstablishPeering call) so it can add this node as a peer. priv is the
+// local node's own ed25519 key; the token carries its public half, which
+// the receiving node records in its peers table without a prior exchange.
+// EstablishPeering checks each token only against the key embedded in it.
+func GeneratePeeringToken(ctx context.Context, priv ed25519.PrivateKey, peerName, localURL string) (token string, err error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("indexer: generate peering token: invalid private key")
+	}
+	endpoint, err := config.NormalizeBaseURL(localURL)
+	if err != nil {
+		return "", fmt.Errorf("indexer: generate peering token: %w", err)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("indexer: generate peering token: %w", err)
+	}
+
+	payload := peeringTokenPayload{
+		PeerName: peerName,
+		PubKey:   base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		Endpoint: endpoint,
+		Nonce:    base64.StdEncoding.EncodeToString(nonce),
+		IssuedAt: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	canon, err := signature.CanonicalJSON(payload)
+	if err != nil {
+		return "", fmt.Errorf("indexer: generate peering token: %w", err)
+	}
+	sig, err := signature.SignBase64(priv, string(canon))
+	if err != nil {
+		return "", fmt.Errorf("indexer: generate peering token: %w", err)
+	}
+
+	b, err := json.Marshal(peeringToken{Payload: payload, Sig: sig})
+	if err != nil {
+		return "", fmt.Errorf("indexer: generate peering token: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// EstablishPeering verifies token (as minted by GeneratePeeringToken on the
+// peer side) and records it in the peers table, replacing any existing
+// peer of the same name. It does not itself pull anything; call
+// PullFromPeer afterward (or on a schedule) to replicate the peer's board
+// log stream.
+func (i *Indexer) EstablishPeering(ctx context.Context, token string) error {
+	raw, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("indexer: establish peering: %w", err)
+	}
+	var pt peeringToken
+	if err := json.Unmarshal(raw, &pt); err != nil {
+		return fmt.Errorf("indexer: establish peering: %w", err)
+	}
+	if pt.Payload.PeerName == "" || pt.Payload.PubKey == "" || pt.Payload.Endpoint == "" {
+		return fmt.Errorf("indexer: establish peering: token missing required fields")
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(pt.Payload.PubKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("indexer: establish peering: invalid pubKey: %w", err)
+	}
+	canon, err := signature.CanonicalJSON(pt.Payload)
+	if err != nil {
+		return fmt.Errorf("indexer: establish peering: %w", err)
+	}
+	if err := signature.VerifyBase64(pub, string(canon), pt.Sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrPeeringTokenInvalid, err)
+	}
+
+	endpoint, err := config.NormalizeBaseURL(pt.Payload.Endpoint)
+	if err != nil {
+		return fmt.Errorf("indexer: establish peering: %w", err)
+	}
+
+	_, err = i.db.ExecContext(ctx, `
+		INSERT INTO peers (name, pubkey, endpoint, established_at, last_pulled_at)
+		VALUES (?, ?, ?, ?, NULL)
+		ON CONFLICT(name) DO UPDATE SET pubkey = excluded.pubkey, endpoint = excluded.endpoint, established_at = excluded.established_at
+	`, pt.Payload.PeerName, pt.Payload.PubKey, endpoint, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("indexer: establish peering: %w", err)
+	}
+	return nil
+}
+
+// ListPeers returns every peer EstablishPeering has recorded, ordered by
+// name.
+func (i *Indexer) ListPeers(ctx context.Context) ([]Peer, error) {
+	rows, err := i.db.QueryContext(ctx, `SELECT name, pubkey, endpoint, established_at, last_pulled_at FROM peers ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: list peers: %w", err)
+	}
+	defer rows.Close()
+
+	var peers []Peer
+	for rows.Next() {
+		var p Peer
+		var establishedAt string
+		var lastPulledAt sql.NullString
+		if err := rows.Scan(&p.Name, &p.PubKey, &p.Endpoint, &establishedAt, &lastPulledAt); err != nil {
+			return nil, fmt.Errorf("indexer: list peers: %w", err)
+		}
+		p.EstablishedAt, _ = time.Parse(time.RFC3339Nano, establishedAt)
+		if lastPulledAt.Valid {
+			p.LastPulledAt, _ = time.Parse(time.RFC3339Nano, lastPulledAt.String)
+		}
+		peers = append(peers, p)
+	}
+	return peers, rows.Err()
+}
+
+// DeletePeering removes peerName from the peers table. Pulling from a peer
+// that's already mid-flight when this runs isn't interrupted; it simply
+// won't be found on the next PullFromPeer call.
+func (i *Indexer) DeletePeering(ctx context.Context, peerName string) error {
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM peers WHERE name = ?`, peerName); err != nil {
+		return fmt.Errorf("indexer: delete peering %q: %w", peerName, err)
+	}
+	return nil
+}
+
+// BoardHead is one board this indexer knows about and the most recent
+// BoardMeta CID it has applied for it, as served by the peering/heads HTTP
+// endpoint for PullFromPeer to consume.
+type BoardHead struct {
+	BoardID      string
+	BoardMetaCID string
+}
+
+// ListBoardHeads returns every board this indexer has a head for, ordered
+// by board ID. It backs the peering/heads endpoint a peer node polls via
+// PullFromPeer: announcing board_meta_cid (not log_head_cid) lets the
+// puller re-enter through the normal SyncBoardByMetaCID/Syncer path, which
+// resolves however much of the log it hasn't already applied rather than
+// requiring the two indexers to agree on a shared log offset.
+func (i *Indexer) ListBoardHeads(ctx context.Context) ([]BoardHead, error) {
+	rows, err := i.db.QueryContext(ctx, `SELECT board_id, board_meta_cid FROM boards ORDER BY board_id`)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: list board heads: %w", err)
+	}
+	defer rows.Close()
+
+	var heads []BoardHead
+	for rows.Next() {
+		var h BoardHead
+		if err := rows.Scan(&h.BoardID, &h.BoardMetaCID); err != nil {
+			return nil, fmt.Errorf("indexer: list board heads: %w", err)
+		}
+		heads = append(heads, h)
+	}
+	return heads, rows.Err()
+}
+
+// peerHeadsResponse is the JSON a peer's /api/v1/indexer/peering/heads
+// endpoint returns: every board it knows about and that board's current
+// BoardMeta CID, so PullFromPeer can hand each to its own Syncer without
+// ever fetching content bytes over HTTP — the heads are just pointers into
+// the shared content-addressed storage both indexers already read from.
+type peerHeadsResponse struct {
+	Boards []struct {
+		BoardID      string `json:"boardId"`
+		BoardMetaCID string `json:"boardMetaCid"`
+	} `json:"boards"`
+}
+
+// PullFromPeer fetches peerName's current board heads over HTTP and
+// submits each one to syncer, letting the ordinary sync path (which reads
+// content by CID from the shared flex-ipfs storage, not from the peer's
+// HTTP server) fetch and apply whatever log entries are new. It returns
+// the number of boards submitted.
+func (i *Indexer) PullFromPeer(ctx context.Context, httpClient *http.Client, peerName string, syncer *Syncer) (int, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	var endpoint string
+	row := i.db.QueryRowContext(ctx, `SELECT endpoint FROM peers WHERE name = ?`, peerName)
+	if err := row.Scan(&endpoint); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("indexer: pull from peer %q: unknown peer", peerName)
+		}
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+	u.Path = u.Path + "/api/v1/indexer/peering/heads"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("indexer: pull from peer %q: http %d", peerName, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+
+	var heads peerHeadsResponse
+	if err := json.Unmarshal(body, &heads); err != nil {
+		return 0, fmt.Errorf("indexer: pull from peer %q: %w", peerName, err)
+	}
+
+	for _, b := range heads.Boards {
+		syncer.SubmitBoard(b.BoardMetaCID)
+	}
+
+	if _, err := i.db.ExecContext(ctx, `UPDATE peers SET last_pulled_at = ? WHERE name = ?`,
+		time.Now().UTC().Format(time.RFC3339Nano), peerName); err != nil {
+		return len(heads.Boards), fmt.Errorf("indexer: pull from peer %q: record last_pulled_at: %w", peerName, err)
+	}
+	return len(heads.Boards), nil
+}
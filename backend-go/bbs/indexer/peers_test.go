@@ -0,0 +1,215 @@
+package indexer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGeneratePeeringTokenAndEstablishPeeringRoundTrip(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	token, err := GeneratePeeringToken(context.Background(), priv, "alice", "https://alice.example/api/v1")
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken: %v", err)
+	}
+
+	if err := ix.EstablishPeering(context.Background(), token); err != nil {
+		t.Fatalf("EstablishPeering: %v", err)
+	}
+
+	peers, err := ix.ListPeers(context.Background())
+	if err != nil {
+		t.Fatalf("ListPeers: %v", err)
+	}
+	if len(peers) != 1 {
+		t.Fatalf("expected 1 peer, got %d", len(peers))
+	}
+	if peers[0].Name != "alice" {
+		t.Fatalf("expected peer name alice, got %q", peers[0].Name)
+	}
+	if peers[0].Endpoint != "https://alice.example/api/v1" {
+		t.Fatalf("unexpected endpoint %q", peers[0].Endpoint)
+	}
+	wantPub := base64.StdEncoding.EncodeToString(pub)
+	if peers[0].PubKey != wantPub {
+		t.Fatalf("expected pubkey %q, got %q", wantPub, peers[0].PubKey)
+	}
+	if peers[0].EstablishedAt.IsZero() {
+		t.Fatalf("expected established_at to be set")
+	}
+	if !peers[0].LastPulledAt.IsZero() {
+		t.Fatalf("expected last_pulled_at to be unset before any pull")
+	}
+}
+
+func TestEstablishPeeringRejectsTamperedToken(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	token, err := GeneratePeeringToken(context.Background(), priv, "alice", "https://alice.example")
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken: %v", err)
+	}
+
+	tampered := token[:len(token)-4] + "AAAA"
+	if err := ix.EstablishPeering(context.Background(), tampered); err == nil {
+		t.Fatalf("expected EstablishPeering to reject a tampered token")
+	}
+}
+
+func TestEstablishPeeringUpsertsExistingPeer(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	_, priv1, _ := ed25519.GenerateKey(nil)
+	token1, err := GeneratePeeringToken(context.Background(), priv1, "alice", "https://alice-old.example")
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken (1): %v", err)
+	}
+	if err := ix.EstablishPeering(context.Background(), token1); err != nil {
+		t.Fatalf("EstablishPeering (1): %v", err)
+	}
+
+	_, priv2, _ := ed25519.GenerateKey(nil)
+	token2, err := GeneratePeeringToken(context.Background(), priv2, "alice", "https://alice-new.example")
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken (2): %v", err)
+	}
+	if err := ix.EstablishPeering(context.Background(), token2); err != nil {
+		t.Fatalf("EstablishPeering (2): %v", err)
+	}
+
+	peers, err := ix.ListPeers(context.Background())
+	if err != nil {
+		t.Fatalf("ListPeers: %v", err)
+	}
+	if len(peers) != 1 {
+		t.Fatalf("expected re-establishing the same peer name to upsert, got %d rows", len(peers))
+	}
+	if peers[0].Endpoint != "https://alice-new.example" {
+		t.Fatalf("expected upsert to replace endpoint, got %q", peers[0].Endpoint)
+	}
+}
+
+func TestPullFromPeerSubmitsHeadsAndRecordsLastPulledAt(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/indexer/peering/heads" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"boards":[{"boardId":"bbs.general","boardMetaCid":"meta_1"},{"boardId":"bbs.off-topic","boardMetaCid":"meta_2"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, priv, _ := ed25519.GenerateKey(nil)
+	token, err := GeneratePeeringToken(context.Background(), priv, "alice", srv.URL)
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken: %v", err)
+	}
+	if err := ix.EstablishPeering(context.Background(), token); err != nil {
+		t.Fatalf("EstablishPeering: %v", err)
+	}
+
+	syncer := ix.StartSyncer(context.Background(), SyncerConfig{})
+	t.Cleanup(syncer.Stop)
+
+	n, err := ix.PullFromPeer(context.Background(), srv.Client(), "alice", syncer)
+	if err != nil {
+		t.Fatalf("PullFromPeer: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 boards submitted, got %d", n)
+	}
+
+	peers, err := ix.ListPeers(context.Background())
+	if err != nil {
+		t.Fatalf("ListPeers: %v", err)
+	}
+	if len(peers) != 1 || peers[0].LastPulledAt.IsZero() {
+		t.Fatalf("expected last_pulled_at to be recorded after a successful pull")
+	}
+}
+
+func TestDeletePeeringRemovesPeer(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	_, priv, _ := ed25519.GenerateKey(nil)
+	token, err := GeneratePeeringToken(context.Background(), priv, "alice", "https://alice.example")
+	if err != nil {
+		t.Fatalf("GeneratePeeringToken: %v", err)
+	}
+	if err := ix.EstablishPeering(context.Background(), token); err != nil {
+		t.Fatalf("EstablishPeering: %v", err)
+	}
+
+	if err := ix.DeletePeering(context.Background(), "alice"); err != nil {
+		t.Fatalf("DeletePeering: %v", err)
+	}
+
+	peers, err := ix.ListPeers(context.Background())
+	if err != nil {
+		t.Fatalf("ListPeers: %v", err)
+	}
+	if len(peers) != 0 {
+		t.Fatalf("expected peer to be removed, got %d remaining", len(peers))
+	}
+
+	if err := ix.DeletePeering(context.Background(), "nobody"); err != nil {
+		t.Fatalf("DeletePeering of an unknown peer should be a no-op, got: %v", err)
+	}
+}
+
+func TestPullFromPeerUnknownPeer(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	syncer := ix.StartSyncer(context.Background(), SyncerConfig{})
+	t.Cleanup(syncer.Stop)
+
+	if _, err := ix.PullFromPeer(context.Background(), nil, "nobody", syncer); err == nil {
+		t.Fatalf("expected PullFromPeer to fail for an unknown peer")
+	}
+}
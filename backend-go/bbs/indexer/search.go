@@ -3,6 +3,8 @@ package indexer
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -12,14 +14,85 @@ type SearchPostsParams struct {
 	AuthorPubKey string
 	Since        string
 	Until        string
-	Limit        int
-	Offset       int
+	// IncludeTombstoned includes posts a tombstonePost log entry has hidden.
+	// Off by default, matching every other read path in this package.
+	IncludeTombstoned bool
+	Limit             int
+	Offset            int
+
+	// MaxCreatedAt/MaxPostCID and MinCreatedAt/MinPostCID implement cursor
+	// pagination: when MaxCreatedAt is set, only posts strictly older than
+	// the (created_at, post_cid) tuple are returned; when MinCreatedAt is
+	// set, only posts strictly newer are returned. Offset is ignored once
+	// either is set. The post_cid tie-break matters because posts can share
+	// a created_at at second precision.
+	MaxCreatedAt string
+	MaxPostCID   string
+	MinCreatedAt string
+	MinPostCID   string
+
+	// Mode selects how Query is turned into an FTS5 query: "match" (the
+	// default) passes it through as an FTS5 bareword query, "phrase"
+	// wraps it as an exact phrase, "prefix" appends `*` to each term, and
+	// "substring" matches inside a word via posts_trgm's trigram index
+	// instead of posts_fts's word-tokenized one. Ignored when Query is "".
+	Mode string
+	// RankBy orders matches: "bm25" (the default) ranks by posts_fts's
+	// relevance score; "recency" ignores relevance and orders by
+	// created_at, same as the no-Query path.
+	RankBy string
+	// Snippet, when true, populates Snippet and Highlights on each result
+	// using FTS5's snippet()/highlight(); it's off by default since both
+	// cost an extra pass over the matched column.
+	Snippet bool
+}
+
+const (
+	SearchModeMatch     = "match"
+	SearchModePhrase    = "phrase"
+	SearchModePrefix    = "prefix"
+	SearchModeSubstring = "substring"
+
+	SearchRankByBM25    = "bm25"
+	SearchRankByRecency = "recency"
+)
+
+// ftsMatchExpr translates a user-entered query into the FTS5 MATCH
+// expression for the given mode. "match" (the default) and "" pass the
+// query straight through, since FTS5's own bareword query syntax already
+// ANDs terms together. "phrase" and "substring" quote it as a single
+// phrase (substring relies on the caller querying the trigram index,
+// where a quoted phrase of 3+ chars matches anywhere inside a word).
+// "prefix" quotes and stars every term individually.
+func ftsMatchExpr(query, mode string) string {
+	quote := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+	switch mode {
+	case SearchModePhrase, SearchModeSubstring:
+		return quote(query)
+	case SearchModePrefix:
+		fields := strings.Fields(query)
+		for i, f := range fields {
+			fields[i] = quote(f) + "*"
+		}
+		return strings.Join(fields, " ")
+	default:
+		return query
+	}
 }
 
 type SearchBoardsParams struct {
 	Query  string
 	Limit  int
 	Offset int
+
+	// MaxCreatedAt/MaxBoardID and MinCreatedAt/MinBoardID implement cursor
+	// pagination the same way SearchPostsParams's Max/Min fields do, keyed
+	// off (created_at, board_id) instead of (created_at, post_cid). Offset
+	// is ignored once either is set.
+	MaxCreatedAt string
+	MaxBoardID   string
+	MinCreatedAt string
+	MinBoardID   string
 }
 
 type SearchBoardResult struct {
@@ -38,6 +111,14 @@ type SearchThreadsParams struct {
 	BoardID string
 	Limit   int
 	Offset  int
+
+	// MaxCreatedAt/MaxThreadID and MinCreatedAt/MinThreadID implement cursor
+	// pagination the same way SearchPostsParams's Max/Min fields do, keyed
+	// off (created_at, thread_id). Offset is ignored once either is set.
+	MaxCreatedAt string
+	MaxThreadID  string
+	MinCreatedAt string
+	MinThreadID  string
 }
 
 type SearchThreadResult struct {
@@ -60,6 +141,12 @@ type SearchPostResult struct {
 	BodyContent  string  `json:"bodyContent"`
 	CreatedAt    string  `json:"createdAt"`
 	EditedAt     *string `json:"editedAt"`
+	// Snippet and Highlights are only populated when SearchPostsParams.Snippet
+	// is set: Snippet is body_content truncated around the match with "..."
+	// ellipses, Highlights is the full body_content with matches wrapped in
+	// <mark></mark>, both via FTS5's snippet()/highlight().
+	Snippet    string `json:"snippet,omitempty"`
+	Highlights string `json:"highlights,omitempty"`
 }
 
 func (i *Indexer) SearchPosts(ctx context.Context, p SearchPostsParams) ([]SearchPostResult, error) {
@@ -81,7 +168,9 @@ func (i *Indexer) SearchPosts(ctx context.Context, p SearchPostsParams) ([]Searc
 		where []string
 		args  []any
 	)
-	where = append(where, "tp.tombstoned = 0")
+	if !p.IncludeTombstoned {
+		where = append(where, "tp.tombstoned = 0")
+	}
 	if p.BoardID != "" {
 		where = append(where, "t.board_id = ?")
 		args = append(args, p.BoardID)
@@ -98,30 +187,82 @@ func (i *Indexer) SearchPosts(ctx context.Context, p SearchPostsParams) ([]Searc
 		where = append(where, "p.created_at <= ?")
 		args = append(args, p.Until)
 	}
+	if p.MaxCreatedAt != "" {
+		where = append(where, "(p.created_at < ? OR (p.created_at = ? AND p.post_cid < ?))")
+		args = append(args, p.MaxCreatedAt, p.MaxCreatedAt, p.MaxPostCID)
+	}
+	if p.MinCreatedAt != "" {
+		where = append(where, "(p.created_at > ? OR (p.created_at = ? AND p.post_cid > ?))")
+		args = append(args, p.MinCreatedAt, p.MinCreatedAt, p.MinPostCID)
+	}
+	if p.MaxCreatedAt != "" || p.MinCreatedAt != "" {
+		p.Offset = 0
+	}
+
+	// With a Query, rank by full-text relevance (bm25) via posts_fts (or,
+	// for Mode=="substring", the trigram-tokenized posts_trgm); without
+	// one, a plain scan ordered by recency is both simpler and faster
+	// (there's nothing to rank).
+	var q string
+	withSnippet := p.Query != "" && p.Snippet
 	if p.Query != "" {
-		where = append(where, "p.body_content LIKE ?")
-		args = append(args, "%"+p.Query+"%")
-	}
-
-	q := `
-		SELECT
-			p.post_cid,
-			p.thread_id,
-			t.board_id,
-			p.author_pubkey,
-			p.display_name,
-			p.body_format,
-			p.body_content,
-			p.created_at,
-			p.edited_at
-		FROM posts p
-		JOIN thread_posts tp ON tp.thread_id = p.thread_id AND tp.post_cid = p.post_cid
-		JOIN threads t ON t.thread_id = p.thread_id
-	`
-	if len(where) > 0 {
+		ftsTable := "posts_fts"
+		if p.Mode == SearchModeSubstring {
+			ftsTable = "posts_trgm"
+		}
+		where = append([]string{ftsTable + " MATCH ?"}, where...)
+		args = append([]any{ftsMatchExpr(p.Query, p.Mode)}, args...)
+
+		snippetCols := ""
+		if withSnippet {
+			// Column 2 is body_content in both posts_fts and posts_trgm.
+			snippetCols = fmt.Sprintf(`,
+				snippet(%s, 2, '...', '...', '...', 12),
+				highlight(%s, 2, '<mark>', '</mark>')`, ftsTable, ftsTable)
+		}
+		q = fmt.Sprintf(`
+			SELECT
+				p.post_cid,
+				p.thread_id,
+				t.board_id,
+				p.author_pubkey,
+				p.display_name,
+				p.body_format,
+				p.body_content,
+				p.created_at,
+				p.edited_at%s
+			FROM %s
+			JOIN posts p ON p.post_cid = %s.post_cid
+			JOIN thread_posts tp ON tp.thread_id = p.thread_id AND tp.post_cid = p.post_cid
+			JOIN threads t ON t.thread_id = p.thread_id
+		`, snippetCols, ftsTable, ftsTable)
 		q += " WHERE " + strings.Join(where, " AND ")
+		if p.RankBy == SearchRankByRecency {
+			q += " ORDER BY p.created_at DESC, p.post_cid DESC LIMIT ? OFFSET ?"
+		} else {
+			q += fmt.Sprintf(" ORDER BY bm25(%s) LIMIT ? OFFSET ?", ftsTable)
+		}
+	} else {
+		q = `
+			SELECT
+				p.post_cid,
+				p.thread_id,
+				t.board_id,
+				p.author_pubkey,
+				p.display_name,
+				p.body_format,
+				p.body_content,
+				p.created_at,
+				p.edited_at
+			FROM posts p
+			JOIN thread_posts tp ON tp.thread_id = p.thread_id AND tp.post_cid = p.post_cid
+			JOIN threads t ON t.thread_id = p.thread_id
+		`
+		if len(where) > 0 {
+			q += " WHERE " + strings.Join(where, " AND ")
+		}
+		q += " ORDER BY p.created_at DESC, p.post_cid DESC LIMIT ? OFFSET ?"
 	}
-	q += " ORDER BY p.created_at DESC LIMIT ? OFFSET ?"
 	args = append(args, p.Limit, p.Offset)
 
 	rows, err := i.db.QueryContext(ctx, q, args...)
@@ -134,7 +275,7 @@ func (i *Indexer) SearchPosts(ctx context.Context, p SearchPostsParams) ([]Searc
 	for rows.Next() {
 		var r SearchPostResult
 		var edited sql.NullString
-		if err := rows.Scan(
+		dest := []any{
 			&r.PostCID,
 			&r.ThreadID,
 			&r.BoardID,
@@ -144,7 +285,11 @@ func (i *Indexer) SearchPosts(ctx context.Context, p SearchPostsParams) ([]Searc
 			&r.BodyContent,
 			&r.CreatedAt,
 			&edited,
-		); err != nil {
+		}
+		if withSnippet {
+			dest = append(dest, &r.Snippet, &r.Highlights)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 		if edited.Valid {
@@ -173,32 +318,65 @@ func (i *Indexer) SearchBoards(ctx context.Context, p SearchBoardsParams) ([]Sea
 		p.Offset = 0
 	}
 
+	var cursorWhere []string
+	var cursorArgs []any
+	if p.MaxCreatedAt != "" {
+		cursorWhere = append(cursorWhere, "(created_at < ? OR (created_at = ? AND board_id < ?))")
+		cursorArgs = append(cursorArgs, p.MaxCreatedAt, p.MaxCreatedAt, p.MaxBoardID)
+	}
+	if p.MinCreatedAt != "" {
+		cursorWhere = append(cursorWhere, "(created_at > ? OR (created_at = ? AND board_id > ?))")
+		cursorArgs = append(cursorArgs, p.MinCreatedAt, p.MinCreatedAt, p.MinBoardID)
+	}
+	if len(cursorWhere) > 0 {
+		p.Offset = 0
+	}
+
 	var (
-		where []string
-		args  []any
+		q    string
+		args []any
 	)
+	// Rank by bm25 via boards_fts when there's a query to rank; a bare
+	// listing has nothing to rank, so it's a plain recency scan.
 	if p.Query != "" {
-		where = append(where, "(board_id LIKE ? OR title LIKE ? OR description LIKE ?)")
-		q := "%" + p.Query + "%"
-		args = append(args, q, q, q)
-	}
-
-	q := `
-		SELECT
-			board_id,
-			board_meta_cid,
-			title,
-			description,
-			created_at,
-			created_by,
-			signature,
-			log_head_cid
-		FROM boards
-	`
-	if len(where) > 0 {
-		q += " WHERE " + strings.Join(where, " AND ")
+		q = `
+			SELECT
+				b.board_id,
+				b.board_meta_cid,
+				b.title,
+				b.description,
+				b.created_at,
+				b.created_by,
+				b.signature,
+				b.log_head_cid
+			FROM boards_fts
+			JOIN boards b ON b.board_id = boards_fts.board_id
+			WHERE boards_fts MATCH ?
+		`
+		args = append([]any{p.Query}, cursorArgs...)
+		for _, c := range cursorWhere {
+			q += " AND " + c
+		}
+		q += " ORDER BY bm25(boards_fts) LIMIT ? OFFSET ?"
+	} else {
+		q = `
+			SELECT
+				board_id,
+				board_meta_cid,
+				title,
+				description,
+				created_at,
+				created_by,
+				signature,
+				log_head_cid
+			FROM boards
+		`
+		if len(cursorWhere) > 0 {
+			q += " WHERE " + strings.Join(cursorWhere, " AND ")
+		}
+		args = append(args, cursorArgs...)
+		q += " ORDER BY created_at DESC, board_id DESC LIMIT ? OFFSET ?"
 	}
-	q += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
 	args = append(args, p.Limit, p.Offset)
 
 	rows, err := i.db.QueryContext(ctx, q, args...)
@@ -234,6 +412,41 @@ func (i *Indexer) SearchBoards(ctx context.Context, p SearchBoardsParams) ([]Sea
 	return out, nil
 }
 
+// GetBoardByID looks up a single board row by its board_id, returning
+// (nil, nil) if no such board exists.
+func (i *Indexer) GetBoardByID(ctx context.Context, boardID string) (*SearchBoardResult, error) {
+	if i.db == nil {
+		return nil, ErrIndexerClosed
+	}
+
+	var r SearchBoardResult
+	var logHead sql.NullString
+	err := i.db.QueryRowContext(ctx, `
+		SELECT board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid
+		FROM boards
+		WHERE board_id = ?
+	`, boardID).Scan(
+		&r.BoardID,
+		&r.BoardMetaCID,
+		&r.Title,
+		&r.Description,
+		&r.CreatedAt,
+		&r.CreatedBy,
+		&r.Signature,
+		&logHead,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if logHead.Valid {
+		r.LogHeadCID = &logHead.String
+	}
+	return &r, nil
+}
+
 func (i *Indexer) SearchThreads(ctx context.Context, p SearchThreadsParams) ([]SearchThreadResult, error) {
 	if i.db == nil {
 		return nil, ErrIndexerClosed
@@ -257,27 +470,55 @@ func (i *Indexer) SearchThreads(ctx context.Context, p SearchThreadsParams) ([]S
 		where = append(where, "board_id = ?")
 		args = append(args, p.BoardID)
 	}
+	if p.MaxCreatedAt != "" {
+		where = append(where, "(created_at < ? OR (created_at = ? AND thread_id < ?))")
+		args = append(args, p.MaxCreatedAt, p.MaxCreatedAt, p.MaxThreadID)
+	}
+	if p.MinCreatedAt != "" {
+		where = append(where, "(created_at > ? OR (created_at = ? AND thread_id > ?))")
+		args = append(args, p.MinCreatedAt, p.MinCreatedAt, p.MinThreadID)
+	}
+	if p.MaxCreatedAt != "" || p.MinCreatedAt != "" {
+		p.Offset = 0
+	}
+
+	// Rank by bm25 via threads_fts when there's a query to rank; a bare
+	// listing has nothing to rank, so it's a plain recency scan.
+	var q string
 	if p.Query != "" {
-		where = append(where, "(title LIKE ? OR thread_id LIKE ?)")
-		q := "%" + p.Query + "%"
-		args = append(args, q, q)
-	}
-
-	q := `
-		SELECT
-			thread_id,
-			board_id,
-			title,
-			root_post_cid,
-			created_at,
-			created_by,
-			signature
-		FROM threads
-	`
-	if len(where) > 0 {
+		where = append([]string{"threads_fts MATCH ?"}, where...)
+		args = append([]any{ftsMatchExpr(p.Query, SearchModeMatch)}, args...)
+		q = `
+			SELECT
+				t.thread_id,
+				t.board_id,
+				t.title,
+				t.root_post_cid,
+				t.created_at,
+				t.created_by,
+				t.signature
+			FROM threads_fts
+			JOIN threads t ON t.thread_id = threads_fts.thread_id
+		`
 		q += " WHERE " + strings.Join(where, " AND ")
+		q += " ORDER BY bm25(threads_fts) LIMIT ? OFFSET ?"
+	} else {
+		q = `
+			SELECT
+				thread_id,
+				board_id,
+				title,
+				root_post_cid,
+				created_at,
+				created_by,
+				signature
+			FROM threads
+		`
+		if len(where) > 0 {
+			q += " WHERE " + strings.Join(where, " AND ")
+		}
+		q += " ORDER BY created_at DESC, thread_id DESC LIMIT ? OFFSET ?"
 	}
-	q += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
 	args = append(args, p.Limit, p.Offset)
 
 	rows, err := i.db.QueryContext(ctx, q, args...)
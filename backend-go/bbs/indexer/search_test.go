@@ -2,12 +2,52 @@ package indexer
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"flex-bbs/backend-go/bbs/flexipfs"
 	"flex-bbs/backend-go/bbs/storage"
 )
 
+func seedSearchFixtures(t *testing.T, ix *Indexer) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_1', 'bbs.general', 'Thread', 'post_1', '2025-01-01T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		t.Fatalf("seed threads: %v", err)
+	}
+
+	posts := []struct {
+		cid, body string
+		tombstone bool
+	}{
+		{"post_1", "the quick brown fox", false},
+		{"post_2", "a slow turtle ambles by", false},
+		{"post_3", "quick quick quick repeated word for ranking", false},
+		{"post_4", "quick fox but tombstoned", true},
+	}
+	for ord, p := range posts {
+		if _, err := ix.db.ExecContext(ctx, `
+			INSERT INTO posts(post_cid, thread_id, author_pubkey, display_name, body_format, body_content, created_at, signature)
+			VALUES(?, 'thread_1', 'author1', 'Author', 'plain', ?, '2025-01-01T00:00:00Z', 'sig')
+		`, p.cid, p.body); err != nil {
+			t.Fatalf("seed posts %s: %v", p.cid, err)
+		}
+		tombstoned := 0
+		if p.tombstone {
+			tombstoned = 1
+		}
+		if _, err := ix.db.ExecContext(ctx, `
+			INSERT INTO thread_posts(thread_id, ordinal, post_cid, tombstoned) VALUES('thread_1', ?, ?, ?)
+		`, ord, p.cid, tombstoned); err != nil {
+			t.Fatalf("seed thread_posts %s: %v", p.cid, err)
+		}
+	}
+}
+
 func TestGetBoardByID_ReturnsRow(t *testing.T) {
 	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
 	ix, err := Open(":memory:", st)
@@ -43,3 +83,340 @@ func TestGetBoardByID_ReturnsRow(t *testing.T) {
 		t.Fatalf("expected nil, got: %#v", missing)
 	}
 }
+
+func TestSearchPosts_FullTextMatchesAndRanksByBM25(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	got, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "quick"})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	// post_4 is tombstoned and excluded by default; post_1 and post_3 both
+	// contain "quick", post_3 repeatedly (so it should rank first by bm25).
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %#v", len(got), got)
+	}
+	if got[0].PostCID != "post_3" {
+		t.Fatalf("expected post_3 (denser match) ranked first, got %q", got[0].PostCID)
+	}
+}
+
+func TestSearchPosts_IncludeTombstonedFlag(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	without, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "fox"})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(without) != 1 {
+		t.Fatalf("expected 1 non-tombstoned match for 'fox', got %d: %#v", len(without), without)
+	}
+
+	with, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "fox", IncludeTombstoned: true})
+	if err != nil {
+		t.Fatalf("SearchPosts with IncludeTombstoned: %v", err)
+	}
+	if len(with) != 2 {
+		t.Fatalf("expected 2 matches for 'fox' including tombstoned, got %d: %#v", len(with), with)
+	}
+}
+
+func TestSearchPosts_EmptyQueryFallsBackToRecencyScan(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	got, err := ix.SearchPosts(context.Background(), SearchPostsParams{})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 non-tombstoned posts, got %d: %#v", len(got), got)
+	}
+}
+
+func TestMigration_PostsFTSBackfillsExistingPosts(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	// Simulate upgrading a database that already had posts before this
+	// migration shipped: wipe posts_fts (as if the table/triggers never
+	// existed yet) and re-run just this migration's Up to confirm it
+	// backfills the existing rows, not only rows inserted from here on.
+	if _, err := ix.db.ExecContext(context.Background(), `DELETE FROM posts_fts`); err != nil {
+		t.Fatalf("clear posts_fts: %v", err)
+	}
+
+	var m Migration
+	for _, cand := range migrations {
+		if cand.Name() == "PostsFTS" {
+			m = cand
+		}
+	}
+	if m == nil {
+		t.Fatalf("PostsFTS migration not registered")
+	}
+
+	tx, err := ix.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if err := m.Up(context.Background(), tx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM posts_fts`).Scan(&n); err != nil {
+		t.Fatalf("count posts_fts: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("expected backfill to restore all 4 seeded posts, got %d", n)
+	}
+}
+
+func TestSearchPosts_SubstringModeMatchesInsideWord(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	// "ambl" is a substring of "ambles" but not a whole token, so it only
+	// matches via posts_trgm, not posts_fts's word tokenizer.
+	got, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "ambl", Mode: SearchModeSubstring})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(got) != 1 || got[0].PostCID != "post_2" {
+		t.Fatalf("expected post_2 via substring match, got %#v", got)
+	}
+}
+
+func TestSearchPosts_RankByRecencyIgnoresBM25(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+	// seedSearchFixtures gives every post the same created_at, so re-seed
+	// post_1 with a later one here to actually exercise recency ordering
+	// rather than relying on the post_cid tie-break.
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `DELETE FROM posts WHERE post_cid = 'post_1'`); err != nil {
+		t.Fatalf("delete post_1: %v", err)
+	}
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO posts(post_cid, thread_id, author_pubkey, display_name, body_format, body_content, created_at, signature)
+		VALUES('post_1', 'thread_1', 'author1', 'Author', 'plain', 'the quick brown fox', '2025-01-02T00:00:00Z', 'sig')
+	`); err != nil {
+		t.Fatalf("re-seed post_1 with a later created_at: %v", err)
+	}
+
+	got, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "quick", RankBy: SearchRankByRecency})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %#v", len(got), got)
+	}
+	// post_1 was created after post_3, so recency order puts it first
+	// regardless of post_3's denser match (which would rank it first under bm25).
+	if got[0].PostCID != "post_1" {
+		t.Fatalf("expected post_1 first by recency, got %q", got[0].PostCID)
+	}
+}
+
+func TestSearchPosts_SnippetPopulatesHighlights(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	got, err := ix.SearchPosts(context.Background(), SearchPostsParams{Query: "quick", Snippet: true})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatalf("expected matches")
+	}
+	for _, r := range got {
+		if r.Highlights == "" || !strings.Contains(r.Highlights, "<mark>") {
+			t.Fatalf("expected highlighted match, got %q", r.Highlights)
+		}
+	}
+}
+
+func TestSearchThreads_FullTextRanksByBM25(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_1', 'bbs.general', 'Quick foxes', 'post_1', '2025-01-01T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		t.Fatalf("seed thread_1: %v", err)
+	}
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_2', 'bbs.general', 'Slow turtles', 'post_2', '2025-01-02T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		t.Fatalf("seed thread_2: %v", err)
+	}
+
+	got, err := ix.SearchThreads(ctx, SearchThreadsParams{Query: "foxes"})
+	if err != nil {
+		t.Fatalf("SearchThreads: %v", err)
+	}
+	if len(got) != 1 || got[0].ThreadID != "thread_1" {
+		t.Fatalf("expected only thread_1 to match 'foxes', got %#v", got)
+	}
+}
+
+func TestSearchBoards_FullTextRanksByBM25(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO boards(board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid)
+		VALUES('bbs.general', 'baf_general', 'General Discussion', 'Anything goes', '2025-01-01T00:00:00Z', 'me', 'sig', NULL)
+	`); err != nil {
+		t.Fatalf("seed bbs.general: %v", err)
+	}
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO boards(board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid)
+		VALUES('bbs.support', 'baf_support', 'Support', 'Ask for help', '2025-01-02T00:00:00Z', 'me', 'sig', NULL)
+	`); err != nil {
+		t.Fatalf("seed bbs.support: %v", err)
+	}
+
+	got, err := ix.SearchBoards(ctx, SearchBoardsParams{Query: "help"})
+	if err != nil {
+		t.Fatalf("SearchBoards: %v", err)
+	}
+	if len(got) != 1 || got[0].BoardID != "bbs.support" {
+		t.Fatalf("expected only bbs.support to match 'help', got %#v", got)
+	}
+}
+
+func TestSearchPosts_CursorPaginationTieBreaksOnPostCID(t *testing.T) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+	seedSearchFixtures(t, ix)
+
+	// All seeded posts share a created_at down to the second, so ordering
+	// (and pagination) must fall back to post_cid descending: post_3,
+	// post_2, post_1 (post_4 is tombstoned and excluded by default).
+	first, err := ix.SearchPosts(context.Background(), SearchPostsParams{Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchPosts: %v", err)
+	}
+	if len(first) != 2 || first[0].PostCID != "post_3" || first[1].PostCID != "post_2" {
+		t.Fatalf("expected [post_3, post_2], got %#v", first)
+	}
+
+	last := first[len(first)-1]
+	second, err := ix.SearchPosts(context.Background(), SearchPostsParams{
+		Limit:        2,
+		MaxCreatedAt: last.CreatedAt,
+		MaxPostCID:   last.PostCID,
+	})
+	if err != nil {
+		t.Fatalf("SearchPosts with MaxCreatedAt/MaxPostCID: %v", err)
+	}
+	if len(second) != 1 || second[0].PostCID != "post_1" {
+		t.Fatalf("expected [post_1] after the cursor, got %#v", second)
+	}
+}
+
+func BenchmarkSearchPosts_100kPosts(b *testing.B) {
+	st := storage.New(flexipfs.New("http://127.0.0.1:5001/api/v0"))
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		b.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = ix.Close() }()
+
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_bench', 'bbs.general', 'Bench Thread', 'post_0', '2025-01-01T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		b.Fatalf("seed threads: %v", err)
+	}
+
+	const numPosts = 100_000
+	tx, err := ix.db.BeginTx(ctx, nil)
+	if err != nil {
+		b.Fatalf("BeginTx: %v", err)
+	}
+	for n := 0; n < numPosts; n++ {
+		cid := fmt.Sprintf("post_%d", n)
+		body := fmt.Sprintf("benchmark post number %d about quick foxes and slow turtles", n)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO posts(post_cid, thread_id, author_pubkey, display_name, body_format, body_content, created_at, signature)
+			VALUES(?, 'thread_bench', 'author1', 'Author', 'plain', ?, '2025-01-01T00:00:00Z', 'sig')
+		`, cid, body); err != nil {
+			b.Fatalf("insert post %d: %v", n, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO thread_posts(thread_id, ordinal, post_cid, tombstoned) VALUES('thread_bench', ?, ?, 0)
+		`, n, cid); err != nil {
+			b.Fatalf("insert thread_posts %d: %v", n, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("seed %d posts: %v", numPosts, err)
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := ix.SearchPosts(ctx, SearchPostsParams{Query: "quick foxes", Limit: 20}); err != nil {
+			b.Fatalf("SearchPosts: %v", err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// Store is the set of database operations the indexer needs to record a
+// board's boards/threads/posts/thread_posts/processed_logs state, factored
+// out of the sqlite-specific queries that used to be inline in indexer.go
+// so a deployment can swap backends — e.g. bbs/store/pgstore for Postgres,
+// where concurrent writers and the surrounding operational tooling matter
+// more than sqlite's zero-ops simplicity.
+//
+// Every method takes a Querier rather than assuming a particular *sql.DB,
+// so it runs equally well standalone or joined into one of runInTx/WithTx's
+// transactions — the transaction machinery itself is plain database/sql and
+// already backend-agnostic; only the query text (placeholders, upsert
+// syntax, column types) differs per dialect, which is exactly what Store
+// exists to isolate.
+//
+// This covers the write path applyLog/Syncer drive on every log entry.
+// fork.go's branch bookkeeping, migrations.go's schema DDL, and search.go's
+// FTS5 queries are sqlite-specific features of later requests and are not
+// routed through Store — porting those to a second dialect is follow-up
+// work, not something this interface tries to paper over.
+type Store interface {
+	UpsertBoard(ctx context.Context, q Querier, cid string, bm *types.BoardMeta) error
+	UpsertThread(ctx context.Context, q Querier, threadCID string, tm *types.ThreadMeta) error
+	UpsertPost(ctx context.Context, q Querier, postCID string, p *types.Post) error
+	AppendThreadPost(ctx context.Context, q Querier, threadID, postCID string) error
+	UpdatePostCID(ctx context.Context, q Querier, threadID, oldPostCID, newPostCID string) error
+	Tombstone(ctx context.Context, q Querier, threadID, postCID, reason, createdAt, authorPubKey string) error
+	IsLogProcessed(ctx context.Context, q Querier, logCID string) (bool, error)
+	InsertProcessedLog(ctx context.Context, q Querier, logCID string, e *types.BoardLogEntry, validSig bool) error
+	SetBoardLogHead(ctx context.Context, q Querier, boardID, headCID string) error
+	Prune(ctx context.Context, q Querier, cutoff string) error
+}
+
+// sqliteStore is the default Store, implementing it with the same
+// sqlite-flavored SQL (? placeholders, INSERT ... ON CONFLICT DO UPDATE)
+// this package has always used.
+type sqliteStore struct{}
+
+func (sqliteStore) UpsertBoard(ctx context.Context, q Querier, cid string, bm *types.BoardMeta) error {
+	policy := bm.EncryptionPolicy
+	if policy == "" {
+		policy = types.EncryptionPolicyNone
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO boards(board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid, encryption_policy)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(board_id) DO UPDATE SET
+			board_meta_cid=excluded.board_meta_cid,
+			title=excluded.title,
+			description=excluded.description,
+			created_at=excluded.created_at,
+			created_by=excluded.created_by,
+			signature=excluded.signature,
+			log_head_cid=excluded.log_head_cid,
+			encryption_policy=excluded.encryption_policy
+	`, bm.BoardID, cid, bm.Title, bm.Description, bm.CreatedAt, bm.CreatedBy, bm.Signature, strPtrOrEmpty(bm.LogHeadCID), policy)
+	return err
+}
+
+func (sqliteStore) UpsertThread(ctx context.Context, q Querier, threadCID string, tm *types.ThreadMeta) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(thread_id) DO UPDATE SET
+			board_id=excluded.board_id,
+			title=excluded.title,
+			root_post_cid=excluded.root_post_cid,
+			created_at=excluded.created_at,
+			created_by=excluded.created_by,
+			signature=excluded.signature
+	`, threadCID, tm.BoardID, tm.Title, tm.RootPostCID, tm.CreatedAt, tm.CreatedBy, tm.Signature)
+	return err
+}
+
+func (sqliteStore) UpsertPost(ctx context.Context, q Querier, postCID string, p *types.Post) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO posts(post_cid, thread_id, parent_post_cid, author_pubkey, display_name, body_format, body_content, created_at, edited_at, signature)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(post_cid) DO UPDATE SET
+			thread_id=excluded.thread_id,
+			parent_post_cid=excluded.parent_post_cid,
+			author_pubkey=excluded.author_pubkey,
+			display_name=excluded.display_name,
+			body_format=excluded.body_format,
+			body_content=excluded.body_content,
+			created_at=excluded.created_at,
+			edited_at=excluded.edited_at,
+			signature=excluded.signature
+	`, postCID, p.ThreadID, strPtrOrEmpty(p.ParentPostCID), p.AuthorPubKey, p.DisplayName, p.Body.Format, p.Body.Content, p.CreatedAt, strPtrOrEmpty(p.EditedAt), p.Signature)
+	return err
+}
+
+func (sqliteStore) AppendThreadPost(ctx context.Context, q Querier, threadID, postCID string) error {
+	var nextOrdinal int
+	if err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(ordinal), -1) + 1 FROM thread_posts WHERE thread_id = ?`, threadID).Scan(&nextOrdinal); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO thread_posts (thread_id, ordinal, post_cid, tombstoned)
+		VALUES (?, ?, ?, 0)
+		ON CONFLICT(thread_id, ordinal) DO NOTHING
+	`, threadID, nextOrdinal, postCID)
+	return err
+}
+
+func (sqliteStore) UpdatePostCID(ctx context.Context, q Querier, threadID, oldPostCID, newPostCID string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE thread_posts
+		SET post_cid = ?
+		WHERE thread_id = ? AND post_cid = ?
+	`, newPostCID, threadID, oldPostCID)
+	return err
+}
+
+func (sqliteStore) Tombstone(ctx context.Context, q Querier, threadID, postCID, reason, createdAt, authorPubKey string) error {
+	var reasonArg any
+	if reason != "" {
+		reasonArg = reason
+	}
+	_, err := q.ExecContext(ctx, `
+		UPDATE thread_posts
+		SET tombstoned = 1, tombstone_reason = ?, tombstone_created_at = ?, tombstone_author_pubkey = ?
+		WHERE thread_id = ? AND post_cid = ?
+	`, reasonArg, createdAt, authorPubKey, threadID, postCID)
+	return err
+}
+
+func (sqliteStore) IsLogProcessed(ctx context.Context, q Querier, logCID string) (bool, error) {
+	var n int
+	if err := q.QueryRowContext(ctx, `SELECT COUNT(1) FROM processed_logs WHERE log_cid = ?`, logCID).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (sqliteStore) InsertProcessedLog(ctx context.Context, q Querier, logCID string, e *types.BoardLogEntry, validSig bool) error {
+	v := 0
+	if validSig {
+		v = 1
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(log_cid) DO NOTHING
+	`, logCID, e.BoardID, e.ThreadID, e.Op, e.CreatedAt, e.AuthorPubKey, strPtrOrEmpty(e.PrevLogCID), v)
+	return err
+}
+
+func (sqliteStore) SetBoardLogHead(ctx context.Context, q Querier, boardID, headCID string) error {
+	_, err := q.ExecContext(ctx, `UPDATE boards SET log_head_cid = ? WHERE board_id = ?`, headCID, boardID)
+	return err
+}
+
+func (sqliteStore) Prune(ctx context.Context, q Querier, cutoff string) error {
+	_, err := q.ExecContext(ctx, `DELETE FROM processed_logs WHERE created_at < ?`, cutoff)
+	return err
+}
+
+// OpenWithStore opens an Indexer against an already-open db and store,
+// bypassing Open's sqlite-specific DSN construction and schema migration —
+// useful for a non-sqlite backend (see bbs/store/pgstore), whose schema a
+// deployment applies out of band rather than through migrations.go's
+// sqlite-flavored DDL. The caller is responsible for db's schema being
+// current for store's dialect before handing it here.
+func OpenWithStore(db *sql.DB, store Store, st *storage.Storage) (*Indexer, error) {
+	if st == nil || st.Flex == nil {
+		return nil, fmt.Errorf("storage is required")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+	return &Indexer{db: db, storage: st, store: store, events: newEventHub(0)}, nil
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSqliteStore_AppendThreadPostAssignsSequentialOrdinals(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_1', 'bbs.general', 'Thread', 'post_1', '2025-01-01T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		t.Fatalf("seed thread: %v", err)
+	}
+
+	store := sqliteStore{}
+	for _, cid := range []string{"post_1", "post_2", "post_3"} {
+		if _, err := ix.db.ExecContext(ctx, `
+			INSERT INTO posts(post_cid, thread_id, author_pubkey, display_name, body_format, body_content, created_at, signature)
+			VALUES(?, 'thread_1', 'author1', 'Author', 'plain', 'body', '2025-01-01T00:00:00Z', 'sig')
+		`, cid); err != nil {
+			t.Fatalf("seed post %s: %v", cid, err)
+		}
+		if err := store.AppendThreadPost(ctx, ix.db, "thread_1", cid); err != nil {
+			t.Fatalf("AppendThreadPost %s: %v", cid, err)
+		}
+	}
+
+	rows, err := ix.db.QueryContext(ctx, `SELECT ordinal, post_cid FROM thread_posts WHERE thread_id = 'thread_1' ORDER BY ordinal`)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var ordinal int
+		var cid string
+		if err := rows.Scan(&ordinal, &cid); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if ordinal != len(got) {
+			t.Fatalf("expected ordinal %d, got %d", len(got), ordinal)
+		}
+		got = append(got, cid)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 thread_posts rows, got %d", len(got))
+	}
+}
+
+func TestSqliteStore_UpdatePostCIDAndTombstone(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	ctx := context.Background()
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES('thread_1', 'bbs.general', 'Thread', 'post_1', '2025-01-01T00:00:00Z', 'author1', 'sig')
+	`); err != nil {
+		t.Fatalf("seed thread: %v", err)
+	}
+	if _, err := ix.db.ExecContext(ctx, `
+		INSERT INTO thread_posts(thread_id, ordinal, post_cid, tombstoned) VALUES('thread_1', 0, 'post_1', 0)
+	`); err != nil {
+		t.Fatalf("seed thread_posts: %v", err)
+	}
+
+	store := sqliteStore{}
+	if err := store.UpdatePostCID(ctx, ix.db, "thread_1", "post_1", "post_2"); err != nil {
+		t.Fatalf("UpdatePostCID: %v", err)
+	}
+	if err := store.Tombstone(ctx, ix.db, "thread_1", "post_2", "spam", "2025-01-02T00:00:00Z", "author1"); err != nil {
+		t.Fatalf("Tombstone: %v", err)
+	}
+
+	var tombstoned int
+	var reason string
+	if err := ix.db.QueryRowContext(ctx, `SELECT tombstoned, tombstone_reason FROM thread_posts WHERE thread_id = 'thread_1' AND post_cid = 'post_2'`).Scan(&tombstoned, &reason); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if tombstoned != 1 || reason != "spam" {
+		t.Fatalf("expected tombstoned=1 reason=spam, got tombstoned=%d reason=%q", tombstoned, reason)
+	}
+}
@@ -0,0 +1,560 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// SyncerConfig configures StartSyncer.
+type SyncerConfig struct {
+	// Workers bounds how many storage fetches (board metas, board log
+	// entries, posts, thread metas) run concurrently. Storage reads and
+	// signature verification are CPU/IO bound and safe in parallel; the
+	// sqlite write path is not (Open sets MaxOpenConns(1)), so Workers has
+	// no effect on how many goroutines write to the database — that's
+	// always exactly one, see writeLoop.
+	Workers int
+	// QueueSize bounds the channel of submitted boards and the channel of
+	// resolved bundles waiting on the writer, giving backpressure against a
+	// burst of SubmitBoard calls or a writer that's falling behind.
+	QueueSize int
+	// Debounce coalesces repeated SubmitBoard calls for the same board
+	// within this window into a single sync.
+	Debounce time.Duration
+}
+
+func (c SyncerConfig) withDefaults() SyncerConfig {
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 64
+	}
+	if c.Debounce <= 0 {
+		c.Debounce = 2 * time.Second
+	}
+	return c
+}
+
+// SyncerMetrics are Prometheus-style counters describing a Syncer's work.
+// They're plain atomically-updated fields rather than wired to a specific
+// metrics client, so this package doesn't have to pick one for callers that
+// don't want it; a caller that does can register them with whatever
+// registry it likes by reading Syncer.Metrics() on a scrape interval.
+type SyncerMetrics struct {
+	LogsAppliedTotal       int64
+	SigInvalidTotal        int64
+	FetchLatencySecondsSum int64 // accumulated as nanoseconds; see FetchLatencyCount
+	FetchLatencyCount      int64
+}
+
+func (m *SyncerMetrics) observeFetch(d time.Duration) {
+	atomic.AddInt64(&m.FetchLatencySecondsSum, int64(d))
+	atomic.AddInt64(&m.FetchLatencyCount, 1)
+}
+
+// Snapshot returns a point-in-time copy of m, safe to read concurrently
+// with the Syncer that's updating it.
+func (m *SyncerMetrics) Snapshot() SyncerMetrics {
+	return SyncerMetrics{
+		LogsAppliedTotal:       atomic.LoadInt64(&m.LogsAppliedTotal),
+		SigInvalidTotal:        atomic.LoadInt64(&m.SigInvalidTotal),
+		FetchLatencySecondsSum: atomic.LoadInt64(&m.FetchLatencySecondsSum),
+		FetchLatencyCount:      atomic.LoadInt64(&m.FetchLatencyCount),
+	}
+}
+
+// resolvedLog is one board log entry a worker has already fetched from
+// storage and signature-checked, including whatever op-specific attachment
+// (a post, a thread meta) applyLog's dispatch would also have needed, so
+// the writer can apply it without any storage I/O of its own. A nil
+// attachment where the op expects one means it failed to verify, exactly
+// as applyLog's own dispatch would treat it: a silent no-op, not an error.
+type resolvedLog struct {
+	logCID   string
+	entry    *types.BoardLogEntry
+	validSig bool
+
+	threadMeta *types.ThreadMeta
+	post       *types.Post
+	oldPost    *types.Post
+	newPost    *types.Post
+	targetPost *types.Post
+
+	err error // a hard fetch error, distinct from a failed verification
+}
+
+// appliableLog is one board's fully-resolved sync: its BoardMeta plus every
+// new log entry in chain order (oldest first), ready for the writer to
+// apply in a single pass. err is set if resolving the board or any of its
+// logs failed, in which case logs may be a partial, unusable prefix.
+type appliableLog struct {
+	boardMetaCID    string
+	boardID         string
+	bm              *types.BoardMeta
+	previousHeadCID string
+	headCID         string
+	foundAncestor   bool
+	terminalPrevCID string
+	logs            []resolvedLog
+	err             error
+}
+
+// Syncer fans the storage-fetch and signature-verification work of syncing
+// many boards out across SyncerConfig.Workers goroutines, while keeping
+// every database write on a single goroutine, following the fan-out/fan-in
+// shape FetchChainsConcurrent already uses for a single chain: many
+// goroutines resolve, one applies, so sqlite's single-writer constraint is
+// never in question.
+type Syncer struct {
+	indexer *Indexer
+	cfg     SyncerConfig
+	metrics SyncerMetrics
+
+	submit chan string
+	toDB   chan appliableLog
+
+	mu      sync.Mutex
+	pending map[string]bool // boardMetaCID -> a debounce timer is already running for it
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// StartSyncer launches cfg.Workers resolve goroutines and one writer
+// goroutine, all stopped by Syncer.Stop (or by ctx being canceled). Submit
+// boards to sync with SubmitBoard.
+func (i *Indexer) StartSyncer(ctx context.Context, cfg SyncerConfig) *Syncer {
+	cfg = cfg.withDefaults()
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &Syncer{
+		indexer: i,
+		cfg:     cfg,
+		submit:  make(chan string, cfg.QueueSize),
+		toDB:    make(chan appliableLog, cfg.QueueSize),
+		pending: make(map[string]bool),
+		cancel:  cancel,
+	}
+
+	s.wg.Add(cfg.Workers)
+	for n := 0; n < cfg.Workers; n++ {
+		go s.resolveWorker(ctx)
+	}
+	s.wg.Add(1)
+	go s.writeLoop(ctx)
+
+	return s
+}
+
+// Metrics returns a snapshot of this syncer's counters.
+func (s *Syncer) Metrics() SyncerMetrics { return s.metrics.Snapshot() }
+
+// Stop cancels every resolve worker and the writer, then waits for all of
+// them to exit.
+func (s *Syncer) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// SubmitBoard queues boardMetaCID to be resolved and applied. Repeated
+// submissions of the same board within cfg.Debounce coalesce into a single
+// sync, so a burst of re-announcements of one active board doesn't queue a
+// resolve per announcement.
+func (s *Syncer) SubmitBoard(boardMetaCID string) {
+	s.mu.Lock()
+	if s.pending[boardMetaCID] {
+		s.mu.Unlock()
+		return
+	}
+	s.pending[boardMetaCID] = true
+	s.mu.Unlock()
+
+	time.AfterFunc(s.cfg.Debounce, func() {
+		s.mu.Lock()
+		delete(s.pending, boardMetaCID)
+		s.mu.Unlock()
+
+		select {
+		case s.submit <- boardMetaCID:
+		default:
+			// Queue is full; drop rather than block the timer goroutine.
+			// The board will sync on its next announcement.
+		}
+	})
+}
+
+// resolveWorker pulls a board off submit, resolves its full unprocessed log
+// suffix, and hands the bundle to the writer. Workers of these run
+// concurrently, bounded by cfg.Workers.
+func (s *Syncer) resolveWorker(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case boardMetaCID, ok := <-s.submit:
+			if !ok {
+				return
+			}
+			bundle := s.resolveBoard(ctx, boardMetaCID)
+			select {
+			case s.toDB <- bundle:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// resolveBoard loads boardMetaCID's BoardMeta, figures out which of its log
+// entries are new (the same walk SyncBoardByMetaCID does), then resolves
+// and verifies each of those entries — and whatever post/thread meta their
+// op needs — concurrently, bounded by cfg.Workers.
+func (s *Syncer) resolveBoard(ctx context.Context, boardMetaCID string) appliableLog {
+	i := s.indexer
+
+	start := time.Now()
+	bm, err := i.storage.LoadBoardMeta(ctx, boardMetaCID)
+	s.metrics.observeFetch(time.Since(start))
+	if err != nil {
+		return appliableLog{boardMetaCID: boardMetaCID, err: err}
+	}
+	if !bbslog.VerifyBoardMeta(bm) {
+		return appliableLog{boardMetaCID: boardMetaCID, err: fmt.Errorf("invalid boardMeta signature cid=%s", boardMetaCID)}
+	}
+
+	previousHeadCID, err := i.boardLogHead(ctx, bm.BoardID)
+	if err != nil {
+		return appliableLog{boardMetaCID: boardMetaCID, boardID: bm.BoardID, err: err}
+	}
+
+	bundle := appliableLog{boardMetaCID: boardMetaCID, boardID: bm.BoardID, bm: bm, previousHeadCID: previousHeadCID}
+	if bm.LogHeadCID == nil || *bm.LogHeadCID == "" {
+		return bundle
+	}
+	bundle.headCID = *bm.LogHeadCID
+
+	processed, err := i.isLogProcessed(ctx, bundle.headCID)
+	if err != nil {
+		bundle.err = err
+		return bundle
+	}
+	if processed {
+		bundle.headCID = "" // nothing new for the writer to do
+		return bundle
+	}
+
+	cids, foundAncestor, terminalPrevCID, err := i.collectUnprocessedLogCIDs(ctx, bundle.headCID, 50_000)
+	if err != nil {
+		bundle.err = err
+		return bundle
+	}
+	bundle.foundAncestor = foundAncestor
+	bundle.terminalPrevCID = terminalPrevCID
+
+	logs := make([]resolvedLog, len(cids))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.cfg.Workers)
+	for idx, cid := range cids {
+		idx, cid := idx, cid
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			logs[idx] = s.resolveLog(ctx, cid)
+		}()
+	}
+	wg.Wait()
+
+	for _, l := range logs {
+		if l.err != nil {
+			bundle.err = l.err
+			return bundle
+		}
+	}
+	bundle.logs = logs
+	return bundle
+}
+
+// resolveLog fetches and verifies logCID and whatever attachment its op
+// needs, without writing anything — the same data applyLog's dispatch
+// fetches, just gathered up front instead of inline with the write.
+func (s *Syncer) resolveLog(ctx context.Context, logCID string) resolvedLog {
+	i := s.indexer
+
+	start := time.Now()
+	e, err := i.storage.LoadBoardLogEntry(ctx, logCID)
+	s.metrics.observeFetch(time.Since(start))
+	if err != nil {
+		return resolvedLog{logCID: logCID, err: err}
+	}
+
+	validSig := bbslog.VerifyBoardLogEntry(e)
+	rl := resolvedLog{logCID: logCID, entry: e, validSig: validSig}
+	if !validSig {
+		atomic.AddInt64(&s.metrics.SigInvalidTotal, 1)
+		return rl
+	}
+
+	fetch := func(cid string) (*types.Post, error) {
+		start := time.Now()
+		p, err := i.storage.LoadPost(ctx, cid)
+		s.metrics.observeFetch(time.Since(start))
+		return p, err
+	}
+
+	switch e.Op {
+	case types.OpCreateThread:
+		if e.PostCID == nil || *e.PostCID == "" {
+			return rl
+		}
+		start := time.Now()
+		tm, err := i.storage.LoadThreadMeta(ctx, e.ThreadID)
+		s.metrics.observeFetch(time.Since(start))
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyThreadMeta(tm) {
+			rl.threadMeta = tm
+		}
+		p, err := fetch(*e.PostCID)
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyPost(p) {
+			rl.post = p
+		}
+
+	case types.OpAddPost:
+		if e.PostCID == nil || *e.PostCID == "" {
+			return rl
+		}
+		p, err := fetch(*e.PostCID)
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyPost(p) {
+			rl.post = p
+		}
+
+	case types.OpEditPost:
+		if e.OldPostCID == nil || *e.OldPostCID == "" || e.NewPostCID == nil || *e.NewPostCID == "" {
+			return rl
+		}
+		oldP, err := fetch(*e.OldPostCID)
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyPost(oldP) {
+			rl.oldPost = oldP
+		}
+		newP, err := fetch(*e.NewPostCID)
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyPost(newP) {
+			rl.newPost = newP
+		}
+
+	case types.OpTombstonePost:
+		if e.TargetPostCID == nil || *e.TargetPostCID == "" {
+			return rl
+		}
+		p, err := fetch(*e.TargetPostCID)
+		if err != nil {
+			return resolvedLog{logCID: logCID, err: err}
+		}
+		if bbslog.VerifyPost(p) {
+			rl.targetPost = p
+		}
+	}
+	return rl
+}
+
+// writeLoop is the syncer's single database writer: it applies each
+// resolved bundle off toDB in the order the resolve workers produced it,
+// per board, inside transactions — exactly the write path SyncBoardByMetaCID
+// takes, just fed pre-resolved data instead of fetching inline.
+func (s *Syncer) writeLoop(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case bundle, ok := <-s.toDB:
+			if !ok {
+				return
+			}
+			if err := s.applyBundle(ctx, bundle); err != nil {
+				log.Printf("indexer syncer: sync board meta=%s board=%s: %v", bundle.boardMetaCID, bundle.boardID, err)
+			}
+		}
+	}
+}
+
+func (s *Syncer) applyBundle(ctx context.Context, bundle appliableLog) error {
+	if bundle.err != nil {
+		return bundle.err
+	}
+	i := s.indexer
+
+	if err := i.upsertBoard(ctx, bundle.boardMetaCID, bundle.bm); err != nil {
+		return err
+	}
+	if bundle.headCID == "" {
+		return nil
+	}
+
+	if !bundle.foundAncestor {
+		hasExisting, err := i.boardHasProcessedLogs(ctx, bundle.boardID)
+		if err != nil {
+			return err
+		}
+		if hasExisting {
+			accepted, err := i.handleFork(ctx, bundle.boardID, bundle.previousHeadCID, bundle.headCID, bundle.terminalPrevCID, len(bundle.logs))
+			if err != nil {
+				return err
+			}
+			if !accepted {
+				return fmt.Errorf("%w: board=%s head=%s policy=%s", ErrForkRejected, bundle.boardID, bundle.headCID, i.ForkPolicy)
+			}
+		}
+	}
+
+	for _, rl := range bundle.logs {
+		if err := i.applyResolvedLog(ctx, rl); err != nil {
+			return err
+		}
+		atomic.AddInt64(&s.metrics.LogsAppliedTotal, 1)
+	}
+
+	if err := i.setBoardLogHead(ctx, bundle.boardID, bundle.headCID); err != nil {
+		return err
+	}
+	i.events.publish(Event{Type: EventBoardLogAdvanced, BoardID: bundle.boardID, HeadCID: bundle.headCID})
+	return nil
+}
+
+// applyResolvedLog is applyLog's write path, fed a resolvedLog instead of
+// fetching from storage itself.
+func (i *Indexer) applyResolvedLog(ctx context.Context, rl resolvedLog) error {
+	return i.runInTx(ctx, func(q Querier) error {
+		if err := i.insertProcessedLog(ctx, q, rl.logCID, rl.entry, rl.validSig); err != nil {
+			return err
+		}
+		if !rl.validSig {
+			return nil
+		}
+		switch rl.entry.Op {
+		case types.OpCreateThread:
+			return i.applyResolvedCreateThread(ctx, q, rl.entry, rl.threadMeta, rl.post)
+		case types.OpAddPost:
+			if rl.entry.PostCID == nil || *rl.entry.PostCID == "" || rl.post == nil {
+				return nil
+			}
+			return i.appendResolvedPost(ctx, q, rl.entry.BoardID, rl.entry.ThreadID, *rl.entry.PostCID, rl.post)
+		case types.OpEditPost:
+			return i.applyResolvedEditPost(ctx, q, rl.entry, rl.oldPost, rl.newPost)
+		case types.OpTombstonePost:
+			return i.applyResolvedTombstone(ctx, q, rl.entry, rl.targetPost)
+		default:
+			return fmt.Errorf("unknown op: %s", rl.entry.Op)
+		}
+	})
+}
+
+func (i *Indexer) applyResolvedCreateThread(ctx context.Context, q Querier, e *types.BoardLogEntry, tm *types.ThreadMeta, rootPost *types.Post) error {
+	if e.PostCID == nil || *e.PostCID == "" || tm == nil || rootPost == nil {
+		return nil
+	}
+	if tm.BoardID != e.BoardID {
+		return nil
+	}
+	tmCopy := *tm
+	if tmCopy.RootPostCID == "" {
+		tmCopy.RootPostCID = *e.PostCID
+	}
+	if err := i.upsertThread(ctx, q, e.ThreadID, &tmCopy); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventThreadCreated, BoardID: e.BoardID, ThreadID: e.ThreadID, AuthorPubKey: tmCopy.CreatedBy, CreatedAt: tmCopy.CreatedAt})
+	return i.appendResolvedPost(ctx, q, e.BoardID, e.ThreadID, *e.PostCID, rootPost)
+}
+
+// appendResolvedPost is appendPost's write path for a post that's already
+// been loaded and verified.
+func (i *Indexer) appendResolvedPost(ctx context.Context, q Querier, boardID, threadID, postCID string, p *types.Post) error {
+	if p.ThreadID != threadID {
+		return nil
+	}
+	ok, err := i.postSatisfiesEncryptionPolicy(ctx, q, boardID, p)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if err := i.upsertPost(ctx, q, postCID, p); err != nil {
+		return err
+	}
+	if err := i.store.AppendThreadPost(ctx, q, threadID, postCID); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventPostIndexed, BoardID: boardID, ThreadID: threadID, PostCID: postCID, AuthorPubKey: p.AuthorPubKey, CreatedAt: p.CreatedAt})
+	return nil
+}
+
+func (i *Indexer) applyResolvedEditPost(ctx context.Context, q Querier, e *types.BoardLogEntry, oldPost, newPost *types.Post) error {
+	if e.OldPostCID == nil || *e.OldPostCID == "" || e.NewPostCID == nil || *e.NewPostCID == "" {
+		return nil
+	}
+	if oldPost == nil || newPost == nil {
+		return nil
+	}
+	if e.AuthorPubKey != oldPost.AuthorPubKey || e.AuthorPubKey != newPost.AuthorPubKey {
+		return nil
+	}
+	ok, err := i.postSatisfiesEncryptionPolicy(ctx, q, e.BoardID, newPost)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	if err := i.upsertPost(ctx, q, *e.NewPostCID, newPost); err != nil {
+		return err
+	}
+	if err := i.store.UpdatePostCID(ctx, q, e.ThreadID, *e.OldPostCID, *e.NewPostCID); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventPostIndexed, BoardID: e.BoardID, ThreadID: e.ThreadID, PostCID: *e.NewPostCID, AuthorPubKey: newPost.AuthorPubKey, CreatedAt: newPost.CreatedAt})
+	return nil
+}
+
+func (i *Indexer) applyResolvedTombstone(ctx context.Context, q Querier, e *types.BoardLogEntry, target *types.Post) error {
+	if e.TargetPostCID == nil || *e.TargetPostCID == "" || target == nil {
+		return nil
+	}
+	if e.AuthorPubKey != target.AuthorPubKey {
+		return nil
+	}
+	reason := ""
+	if e.Reason != nil {
+		reason = *e.Reason
+	}
+	if err := i.store.Tombstone(ctx, q, e.ThreadID, *e.TargetPostCID, reason, e.CreatedAt, e.AuthorPubKey); err != nil {
+		return err
+	}
+	i.emit(Event{Type: EventPostTombstoned, BoardID: e.BoardID, ThreadID: e.ThreadID, PostCID: *e.TargetPostCID, AuthorPubKey: e.AuthorPubKey, CreatedAt: e.CreatedAt})
+	return nil
+}
@@ -0,0 +1,189 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// fakeObjectServer serves arbitrary JSON-marshalable values keyed by CID,
+// speaking the same dht/getvalue protocol as fakeLogEntryServer but generic
+// over BoardMeta/ThreadMeta/Post/BoardLogEntry rather than just the latter.
+func fakeObjectServer(t *testing.T, values map[string]any) *storage.Storage {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cid := r.URL.Query().Get("cid")
+		v, ok := values[cid]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", cid, err)
+		}
+		wrapped, err := json.Marshal(string(b))
+		if err != nil {
+			t.Fatalf("wrap %s: %v", cid, err)
+		}
+		_, _ = w.Write(wrapped)
+	}))
+	t.Cleanup(srv.Close)
+	return storage.New(flexipfs.New(srv.URL + "/api/v0"))
+}
+
+// waitForCondition polls cond until it's true or the deadline passes,
+// matching the async nature of Syncer's resolve/write goroutines.
+func waitForCondition(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !cond() {
+		t.Fatalf("condition not met before deadline")
+	}
+}
+
+func TestSyncer_SubmitBoardAppliesThreadAndPost(t *testing.T) {
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	postCID := "post_1"
+	post := &types.Post{
+		ThreadID:     "thread_1",
+		AuthorPubKey: "author1",
+		DisplayName:  "Author",
+		Body:         types.PostBody{Format: "plain", Content: "hello"},
+		CreatedAt:    "2025-01-01T00:00:00Z",
+	}
+	if err := signature.SignPost(priv, post); err != nil {
+		t.Fatalf("SignPost: %v", err)
+	}
+
+	thread := &types.ThreadMeta{
+		ThreadID:    "thread_1",
+		BoardID:     "bbs.general",
+		Title:       "Thread",
+		RootPostCID: postCID,
+		CreatedAt:   "2025-01-01T00:00:00Z",
+		CreatedBy:   "author1",
+	}
+	if err := signature.SignThreadMeta(priv, thread); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+
+	logCID := "log_1"
+	entry := &types.BoardLogEntry{
+		BoardID:      "bbs.general",
+		Op:           types.OpCreateThread,
+		ThreadID:     "thread_1",
+		PostCID:      &postCID,
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: "author1",
+	}
+	if err := signature.SignBoardLogEntry(priv, entry); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+
+	headCID := logCID
+	boardMetaCID := "boardmeta_1"
+	bm := &types.BoardMeta{
+		BoardID:    "bbs.general",
+		Title:      "General",
+		CreatedAt:  "2025-01-01T00:00:00Z",
+		CreatedBy:  "author1",
+		LogHeadCID: &headCID,
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+
+	st := fakeObjectServer(t, map[string]any{
+		boardMetaCID: bm,
+		logCID:       entry,
+		"thread_1":   thread,
+		postCID:      post,
+	})
+
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	syncer := ix.StartSyncer(context.Background(), SyncerConfig{Workers: 2, Debounce: 10 * time.Millisecond})
+	t.Cleanup(syncer.Stop)
+
+	syncer.SubmitBoard(boardMetaCID)
+
+	waitForCondition(t, func() bool {
+		processed, err := ix.isLogProcessed(context.Background(), logCID)
+		return err == nil && processed
+	})
+
+	var gotHead string
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT log_head_cid FROM boards WHERE board_id = ?`, "bbs.general").Scan(&gotHead); err != nil {
+		t.Fatalf("select log_head_cid: %v", err)
+	}
+	if gotHead != headCID {
+		t.Fatalf("expected board head advanced to %q, got %q", headCID, gotHead)
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM thread_posts WHERE thread_id = ?`, "thread_1").Scan(&n); err != nil {
+		t.Fatalf("count thread_posts: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the root post indexed once, got %d rows", n)
+	}
+
+	metrics := syncer.Metrics()
+	if metrics.LogsAppliedTotal != 1 {
+		t.Fatalf("expected LogsAppliedTotal=1, got %d", metrics.LogsAppliedTotal)
+	}
+	if metrics.FetchLatencyCount == 0 {
+		t.Fatalf("expected at least one fetch latency observation")
+	}
+}
+
+func TestSyncer_SubmitBoardDebouncesRepeatedAnnouncements(t *testing.T) {
+	st := fakeObjectServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	syncer := ix.StartSyncer(context.Background(), SyncerConfig{Debounce: 200 * time.Millisecond})
+	t.Cleanup(syncer.Stop)
+
+	// Announce the same (nonexistent) board repeatedly within the debounce
+	// window; only one resolve should ever be queued for it.
+	for n := 0; n < 5; n++ {
+		syncer.SubmitBoard("missing_board_meta")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	// The board doesn't exist on the fake server, so the resolve fails and
+	// is logged rather than applied; its single LoadBoardMeta fetch shows
+	// the five submissions coalesced into one resolve.
+	waitForCondition(t, func() bool { return syncer.Metrics().FetchLatencyCount >= 1 })
+	if got := syncer.Metrics().FetchLatencyCount; got != 1 {
+		t.Fatalf("expected one board meta fetch for 5 debounced submissions, got %d", got)
+	}
+}
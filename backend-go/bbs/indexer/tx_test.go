@@ -0,0 +1,145 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestWithTx_CommitsBatchedWritesTogether(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	err = ix.WithTx(context.Background(), func(ctx context.Context) error {
+		for _, cid := range []string{"log_1", "log_2"} {
+			if err := ix.runInTx(ctx, func(q Querier) error {
+				if _, err := q.ExecContext(ctx, `
+					INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
+					VALUES(?, 'bbs.general', 'thread_1', 'addPost', '2025-01-01T00:00:00Z', 'author1', NULL, 1)
+				`, cid); err != nil {
+					return err
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if ix.tx != nil {
+		t.Fatalf("expected WithTx to clear the active transaction once it returns")
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM processed_logs`).Scan(&n); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected both batched writes committed, got %d rows", n)
+	}
+}
+
+func TestWithTx_RollsBackAllWritesOnError(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	wantErr := errors.New("boom")
+	err = ix.WithTx(context.Background(), func(ctx context.Context) error {
+		if err := ix.runInTx(ctx, func(q Querier) error {
+			if _, err := q.ExecContext(ctx, `
+				INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
+				VALUES('log_1', 'bbs.general', 'thread_1', 'addPost', '2025-01-01T00:00:00Z', 'author1', NULL, 1)
+			`); err != nil {
+				return err
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("WithTx error = %v, want %v", err, wantErr)
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM processed_logs`).Scan(&n); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected the whole batch rolled back, got %d rows", n)
+	}
+}
+
+func TestWithTx_DoesNotNest(t *testing.T) {
+	st := fakeLogEntryServer(t, nil)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	err = ix.WithTx(context.Background(), func(ctx context.Context) error {
+		return ix.WithTx(ctx, func(ctx context.Context) error { return nil })
+	})
+	if err == nil {
+		t.Fatalf("expected an error nesting WithTx batches")
+	}
+}
+
+func TestApplyLog_InvalidSignatureStillRecordsProcessedLogRowButNoOp(t *testing.T) {
+	entries := map[string]*types.BoardLogEntry{
+		"log_bad": {
+			Type: "boardLogEntry", BoardID: "bbs.general", Op: types.OpAddPost,
+			ThreadID: "thread_1", PostCID: strp("post_1"),
+			AuthorPubKey: "not-a-valid-pubkey", Signature: "not-a-valid-sig",
+		},
+	}
+	st := fakeLogEntryServer(t, entries)
+	ix, err := Open(":memory:", st)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	if err := ix.applyLog(context.Background(), "log_bad"); err != nil {
+		t.Fatalf("applyLog: %v", err)
+	}
+
+	processed, err := ix.isLogProcessed(context.Background(), "log_bad")
+	if err != nil {
+		t.Fatalf("isLogProcessed: %v", err)
+	}
+	if !processed {
+		t.Fatalf("expected an invalid-signature log to still be recorded as processed")
+	}
+
+	var validSig int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT valid_sig FROM processed_logs WHERE log_cid = ?`, "log_bad").Scan(&validSig); err != nil {
+		t.Fatalf("select valid_sig: %v", err)
+	}
+	if validSig != 0 {
+		t.Fatalf("expected valid_sig=0 for an invalid signature, got %d", validSig)
+	}
+
+	var n int
+	if err := ix.db.QueryRowContext(context.Background(), `SELECT COUNT(1) FROM thread_posts WHERE thread_id = ?`, "thread_1").Scan(&n); err != nil {
+		t.Fatalf("count thread_posts: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("an invalid-signature log must not be applied, got %d thread_posts rows", n)
+	}
+}
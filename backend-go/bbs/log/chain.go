@@ -0,0 +1,244 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+var (
+	// ErrBrokenPrevLink is the errors.Is target for a ChainError whose
+	// Reason is ChainBreakPrevLinkMismatch or ChainBreakCIDMismatch: the
+	// chain's linked-list structure itself doesn't hold together.
+	ErrBrokenPrevLink = errors.New("log: prevLogCid does not match previous entry")
+
+	// ErrSignatureInvalid is the errors.Is target for a ChainError whose
+	// Reason is ChainBreakInvalidSignature.
+	ErrSignatureInvalid = errors.New("log: signature did not verify")
+)
+
+// ChainBreakReason identifies which invariant a BoardLogEntry chain failed.
+type ChainBreakReason string
+
+const (
+	ChainBreakCIDMismatch      ChainBreakReason = "cid_mismatch"
+	ChainBreakPrevLinkMismatch ChainBreakReason = "prev_link_mismatch"
+	ChainBreakInvalidSignature ChainBreakReason = "invalid_signature"
+	ChainBreakNonMonotonicTime ChainBreakReason = "non_monotonic_created_at"
+	ChainBreakInconsistentOp   ChainBreakReason = "inconsistent_op_fields"
+)
+
+// ChainError identifies the first broken link in a board log chain, so
+// callers (e.g. indexers) can quarantine the forked branch at that CID
+// instead of silently accepting or rejecting the whole chain.
+type ChainError struct {
+	CID    string
+	Reason ChainBreakReason
+	Err    error
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("board log chain broken at cid=%s (%s): %v", e.CID, e.Reason, e.Err)
+}
+
+func (e *ChainError) Unwrap() error { return e.Err }
+
+// VerifyBoardLogChain validates entries (as returned by FetchChain, oldest
+// first) end-to-end: every entry's signature must verify, entries[i].PrevLogCID
+// must equal the CID of entries[i-1], CreatedAt must be non-decreasing, and
+// each op's CID fields must be consistent with its kind. head, if non-empty,
+// must equal the CID of the last entry.
+//
+// CIDs themselves are opaque content addresses assigned by the flexipfs
+// store (see storage.Storage); this package has no way to recompute one
+// from an entry's bytes, so CID identity is checked by comparing the CIDs
+// the caller attached to each entry (item.CID), not by rehashing content.
+func VerifyBoardLogChain(entries []EntryWithCID[types.BoardLogEntry], head string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var prevCID string
+	var prevCreatedAt time.Time
+	havePrevCreatedAt := false
+
+	for i, item := range entries {
+		e := item.Value
+		if e == nil {
+			return &ChainError{CID: item.CID, Reason: ChainBreakInvalidSignature, Err: fmt.Errorf("%w: nil entry", ErrSignatureInvalid)}
+		}
+		if !item.ValidSignature {
+			return &ChainError{CID: item.CID, Reason: ChainBreakInvalidSignature, Err: ErrSignatureInvalid}
+		}
+
+		if i == 0 {
+			if e.PrevLogCID != nil && *e.PrevLogCID != "" {
+				return &ChainError{CID: item.CID, Reason: ChainBreakPrevLinkMismatch, Err: fmt.Errorf("%w: root entry has non-empty prevLogCid %q", ErrBrokenPrevLink, *e.PrevLogCID)}
+			}
+		} else {
+			if e.PrevLogCID == nil || *e.PrevLogCID != prevCID {
+				got := ""
+				if e.PrevLogCID != nil {
+					got = *e.PrevLogCID
+				}
+				return &ChainError{CID: item.CID, Reason: ChainBreakPrevLinkMismatch, Err: fmt.Errorf("%w: prevLogCid=%q does not match previous entry cid=%q", ErrBrokenPrevLink, got, prevCID)}
+			}
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, e.CreatedAt)
+		if err != nil {
+			createdAt, err = time.Parse(time.RFC3339Nano, e.CreatedAt)
+		}
+		if err == nil {
+			if havePrevCreatedAt && createdAt.Before(prevCreatedAt) {
+				return &ChainError{CID: item.CID, Reason: ChainBreakNonMonotonicTime, Err: fmt.Errorf("createdAt=%s precedes previous entry's %s", e.CreatedAt, prevCreatedAt.Format(time.RFC3339))}
+			}
+			prevCreatedAt = createdAt
+			havePrevCreatedAt = true
+		}
+
+		if err := validateOpFields(e); err != nil {
+			return &ChainError{CID: item.CID, Reason: ChainBreakInconsistentOp, Err: err}
+		}
+
+		prevCID = item.CID
+	}
+
+	if head != "" && prevCID != head {
+		last := entries[len(entries)-1]
+		return &ChainError{CID: last.CID, Reason: ChainBreakCIDMismatch, Err: fmt.Errorf("%w: chain tip cid=%q does not match claimed head=%q", ErrBrokenPrevLink, last.CID, head)}
+	}
+	return nil
+}
+
+// ChainPolicy controls how ReplayThread/ReplayThreadPrefetched react when
+// ChainVerification.Policy enforcement (see ChainVerification) finds a
+// board log entry whose PrevLogCID doesn't match the previous entry's
+// CID, or a PrevLogCID a single author has already used for an earlier
+// entry.
+type ChainPolicy int
+
+const (
+	// ChainPolicyStrict stops replay at the first broken entry: the
+	// entries before it are still returned, alongside an *ErrChainBroken
+	// describing where enforcement gave up.
+	ChainPolicyStrict ChainPolicy = iota
+	// ChainPolicyLenient reports the break via ChainVerification.OnBreak
+	// (if set) but keeps every entry, including the one that broke the
+	// chain, exactly as if enforcement weren't configured at all.
+	ChainPolicyLenient
+	// ChainPolicyRepair behaves like ChainPolicyLenient, except the
+	// broken entry's own CID becomes the new expected PrevLogCID, so a
+	// single bad or reordered entry doesn't also flag every entry after
+	// it.
+	ChainPolicyRepair
+)
+
+// ErrChainBroken is returned by ReplayThread/ReplayThreadPrefetched (under
+// ChainPolicyStrict), and passed to ChainVerification.OnBreak (under
+// ChainPolicyLenient/ChainPolicyRepair), when boardLog[Index]'s PrevLogCID
+// was Got instead of the Expected CID — the CID of the last entry that
+// extended the chain. A same-author fork (Got being a PrevLogCID that
+// author already used) is reported with that same Expected.
+type ErrChainBroken struct {
+	Index    int
+	Expected string
+	Got      string
+}
+
+func (e *ErrChainBroken) Error() string {
+	return fmt.Sprintf("log: chain broken at index %d: expected prevLogCid=%q, got %q", e.Index, e.Expected, e.Got)
+}
+
+func (e *ErrChainBroken) Unwrap() error { return ErrBrokenPrevLink }
+
+// ChainVerification configures PrevLogCID enforcement for ReplayThread and
+// ReplayThreadPrefetched, as an alternative to (or layered on top of)
+// running VerifyBoardLogChain over the same boardLog up front: Policy
+// picks what happens to a broken entry, and OnBreak, if set, is notified
+// every time one is found regardless of Policy. Forks — a single author
+// reusing a PrevLogCID it already used for an earlier entry — are
+// reported the same way as an ordinary out-of-order break, tracked via an
+// internal per-author "last PrevLogCID seen" map so a signer can't evade
+// detection by forking off an ancestor further back than the immediately
+// preceding entry.
+//
+// ReplayThreadDAG doesn't take a ChainVerification: its linearizeDAG pass
+// already resolves forks structurally (by picking a single deterministic
+// total order across every tip), so there's nothing left for this to
+// enforce by the time replayEntries sees the entries.
+type ChainVerification struct {
+	Policy  ChainPolicy
+	OnBreak func(ErrChainBroken)
+}
+
+// verifyAndFilterChain enforces cv (see ChainVerification) over boardLog,
+// in order, and returns the entries replay should proceed with. cv == nil
+// disables enforcement and returns boardLog unchanged.
+func verifyAndFilterChain(boardLog []EntryWithCID[types.BoardLogEntry], cv *ChainVerification) ([]EntryWithCID[types.BoardLogEntry], error) {
+	if cv == nil {
+		return boardLog, nil
+	}
+
+	lastSeenPrevLogCid := make(map[string]string)
+	out := make([]EntryWithCID[types.BoardLogEntry], 0, len(boardLog))
+
+	var expected string
+	for i, item := range boardLog {
+		e := item.Value
+		if e == nil {
+			continue
+		}
+		got := ""
+		if e.PrevLogCID != nil {
+			got = *e.PrevLogCID
+		}
+
+		_, forked := lastSeenPrevLogCid[e.AuthorPubKey]
+		forked = forked && lastSeenPrevLogCid[e.AuthorPubKey] == got
+		broken := i > 0 && got != expected
+
+		if broken || forked {
+			brokenErr := ErrChainBroken{Index: i, Expected: expected, Got: got}
+			if cv.Policy == ChainPolicyStrict {
+				return out, &brokenErr
+			}
+			if cv.OnBreak != nil {
+				cv.OnBreak(brokenErr)
+			}
+			if cv.Policy == ChainPolicyRepair {
+				expected = item.CID
+			}
+		} else {
+			expected = item.CID
+		}
+
+		lastSeenPrevLogCid[e.AuthorPubKey] = got
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func validateOpFields(e *types.BoardLogEntry) error {
+	has := func(p *string) bool { return p != nil && *p != "" }
+
+	switch e.Op {
+	case types.OpCreateThread, types.OpAddPost:
+		if !has(e.PostCID) {
+			return fmt.Errorf("op=%s requires postCid", e.Op)
+		}
+	case types.OpEditPost:
+		if !has(e.OldPostCID) || !has(e.NewPostCID) {
+			return fmt.Errorf("op=%s requires oldPostCid and newPostCid", e.Op)
+		}
+	case types.OpTombstonePost:
+		if !has(e.TargetPostCID) {
+			return fmt.Errorf("op=%s requires targetPostCid", e.Op)
+		}
+	default:
+		return fmt.Errorf("unknown op: %s", e.Op)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func signedEntry(t *testing.T, priv string, e *types.BoardLogEntry) *types.BoardLogEntry {
+	t.Helper()
+	if err := signature.SignBoardLogEntry(priv, e); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+	return e
+}
+
+func TestVerifyBoardLogChain_Valid(t *testing.T) {
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	postCID := "baf_post"
+	e1 := signedEntry(t, priv, &types.BoardLogEntry{
+		BoardID:   "bbs.general",
+		Op:        types.OpCreateThread,
+		ThreadID:  "baf_thread",
+		PostCID:   &postCID,
+		CreatedAt: "2025-01-01T00:00:00Z",
+	})
+	cid1 := "log1"
+
+	e2 := signedEntry(t, priv, &types.BoardLogEntry{
+		BoardID:    "bbs.general",
+		Op:         types.OpAddPost,
+		ThreadID:   "baf_thread",
+		PostCID:    &postCID,
+		CreatedAt:  "2025-01-01T00:01:00Z",
+		PrevLogCID: &cid1,
+	})
+	cid2 := "log2"
+
+	entries := []EntryWithCID[types.BoardLogEntry]{
+		{CID: cid1, Value: e1, ValidSignature: true},
+		{CID: cid2, Value: e2, ValidSignature: true},
+	}
+
+	if err := VerifyBoardLogChain(entries, cid2); err != nil {
+		t.Fatalf("VerifyBoardLogChain: %v", err)
+	}
+}
+
+func TestVerifyBoardLogChain_BrokenPrevLink(t *testing.T) {
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	postCID := "baf_post"
+	e1 := signedEntry(t, priv, &types.BoardLogEntry{
+		BoardID:   "bbs.general",
+		Op:        types.OpCreateThread,
+		ThreadID:  "baf_thread",
+		PostCID:   &postCID,
+		CreatedAt: "2025-01-01T00:00:00Z",
+	})
+
+	wrongPrev := "not-log1"
+	e2 := signedEntry(t, priv, &types.BoardLogEntry{
+		BoardID:    "bbs.general",
+		Op:         types.OpAddPost,
+		ThreadID:   "baf_thread",
+		PostCID:    &postCID,
+		CreatedAt:  "2025-01-01T00:01:00Z",
+		PrevLogCID: &wrongPrev,
+	})
+
+	entries := []EntryWithCID[types.BoardLogEntry]{
+		{CID: "log1", Value: e1, ValidSignature: true},
+		{CID: "log2", Value: e2, ValidSignature: true},
+	}
+
+	err = VerifyBoardLogChain(entries, "log2")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	chainErr, ok := err.(*ChainError)
+	if !ok {
+		t.Fatalf("expected *ChainError, got %T", err)
+	}
+	if chainErr.Reason != ChainBreakPrevLinkMismatch || chainErr.CID != "log2" {
+		t.Fatalf("unexpected chain error: %#v", chainErr)
+	}
+}
+
+func TestVerifyBoardLogChain_InconsistentOpFields(t *testing.T) {
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	e1 := signedEntry(t, priv, &types.BoardLogEntry{
+		BoardID:   "bbs.general",
+		Op:        types.OpEditPost,
+		ThreadID:  "baf_thread",
+		CreatedAt: "2025-01-01T00:00:00Z",
+	})
+
+	entries := []EntryWithCID[types.BoardLogEntry]{
+		{CID: "log1", Value: e1, ValidSignature: true},
+	}
+
+	err = VerifyBoardLogChain(entries, "log1")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	chainErr, ok := err.(*ChainError)
+	if !ok || chainErr.Reason != ChainBreakInconsistentOp {
+		t.Fatalf("unexpected chain error: %#v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"context"
+	"sort"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// FetchDAG walks the BoardLogEntry history backward from every CID in
+// heads, following prevCID the same way FetchChain does, but merges all of
+// them into a single CID-keyed DAG instead of one linear slice. Two heads
+// that fork from a shared ancestor (two moderators signing distinct
+// entries with the same PrevLogCID) share that ancestor's node rather than
+// fetching or storing it twice: once a CID is already in nodes, the walk
+// for whichever head reaches it second stops, since everything above it
+// was already pulled in by the head that got there first. tips is heads,
+// filtered to the non-empty ones, for callers that need to know where
+// traversal started (e.g. to detect the fork in the first place).
+func FetchDAG[T any](
+	ctx context.Context,
+	heads []*string,
+	load LoadFunc[T],
+	prevCID func(*T) *string,
+	verify func(*T) bool,
+	maxNodes int,
+) (nodes map[string]EntryWithCID[T], tips []string, err error) {
+	if maxNodes <= 0 {
+		maxNodes = 10_000
+	}
+	nodes = make(map[string]EntryWithCID[T])
+
+	for _, head := range heads {
+		if head == nil || *head == "" {
+			continue
+		}
+		tips = append(tips, *head)
+
+		current := head
+		for current != nil && *current != "" {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+
+			cid := *current
+			if _, ok := nodes[cid]; ok {
+				break
+			}
+			if len(nodes) >= maxNodes {
+				return nil, nil, ErrChainTooLong
+			}
+
+			v, err := load(ctx, cid)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes[cid] = EntryWithCID[T]{CID: cid, Value: v, ValidSignature: verify(v)}
+			current = prevCID(v)
+		}
+	}
+	return nodes, tips, nil
+}
+
+// linearizeDAG flattens a board log DAG into the deterministic total order
+// ReplayThreadDAG replays: a topological sort by PrevLogCID, breaking ties
+// between entries whose parent has already been emitted (i.e. concurrent
+// forks) lexicographically by (CreatedAt, AuthorPubKey, CID). Because that
+// tie-break never depends on which tip a replica discovered first, every
+// replica linearizes the same DAG into the same sequence.
+func linearizeDAG(nodes map[string]EntryWithCID[types.BoardLogEntry]) []EntryWithCID[types.BoardLogEntry] {
+	children := make(map[string][]string)
+	indegree := make(map[string]int, len(nodes))
+	for cid := range nodes {
+		indegree[cid] = 0
+	}
+	for cid, item := range nodes {
+		if item.Value == nil {
+			continue
+		}
+		parent := item.Value.PrevLogCID
+		if parent == nil || *parent == "" {
+			continue
+		}
+		if _, ok := nodes[*parent]; !ok {
+			continue
+		}
+		children[*parent] = append(children[*parent], cid)
+		indegree[cid]++
+	}
+
+	less := func(a, b string) bool {
+		ea, eb := nodes[a].Value, nodes[b].Value
+		if ea.CreatedAt != eb.CreatedAt {
+			return ea.CreatedAt < eb.CreatedAt
+		}
+		if ea.AuthorPubKey != eb.AuthorPubKey {
+			return ea.AuthorPubKey < eb.AuthorPubKey
+		}
+		return a < b
+	}
+
+	var ready []string
+	for cid, deg := range indegree {
+		if deg == 0 {
+			ready = append(ready, cid)
+		}
+	}
+
+	out := make([]EntryWithCID[types.BoardLogEntry], 0, len(nodes))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		cid := ready[0]
+		ready = ready[1:]
+		out = append(out, nodes[cid])
+		for _, child := range children[cid] {
+			indegree[child]--
+			if indegree[child] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+	return out
+}
+
+// ReplayThreadDAG replays a board log DAG (as returned by FetchDAG) for a
+// single thread, linearizing it into the deterministic order linearizeDAG
+// produces before feeding it through the same edit/tombstone reducer
+// ReplayThread uses. Two replicas that fetched the DAG starting from
+// different tips converge on an identical []ReplayedPost: linearization
+// doesn't care which tip was discovered first, only the entries' own
+// PrevLogCID/CreatedAt/AuthorPubKey/CID. policy, if non-nil, is consulted
+// for every entry before it's applied, so e.g. an OpTombstonePost signed
+// by an author outside a board's moderator allowlist can be dropped
+// instead of silently taking effect just because it happened to land on
+// the winning fork.
+func ReplayThreadDAG(
+	ctx context.Context,
+	dag map[string]EntryWithCID[types.BoardLogEntry],
+	threadID string,
+	loadPost LoadFunc[types.Post],
+	verifyPost func(*types.Post) bool,
+	policy func(*types.BoardLogEntry) bool,
+) ([]ReplayedPost, error) {
+	ordered := linearizeDAG(dag)
+	return replayEntries(ctx, ordered, threadID, loadPost, verifyPost, nil, policy, 0)
+}
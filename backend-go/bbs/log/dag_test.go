@@ -0,0 +1,178 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// buildForkedThread builds a shared create-thread + add-post history for
+// threadID, then forks it into two BoardLogEntry tips over the same post
+// p1CID: one OpEditPost and one OpTombstonePost, both signed by pubStr.
+// tombstoneCreatedAt lets a test control ordering relative to the edit
+// (e.g. stamping the tombstone earlier forces linearizeDAG to apply it
+// before the edit, the case where a policy rejecting it actually changes
+// the outcome). It returns the logs/posts maps (for loadLog/loadPost) and
+// the two tip CIDs.
+func buildForkedThread(t *testing.T, tombstoneCreatedAt string) (logs map[string]*types.BoardLogEntry, posts map[string]*types.Post, threadID, editTipCID, tombstoneTipCID string) {
+	t.Helper()
+	pubStr, privStr, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	priv, err := signature.ParsePrivateKey(privStr)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+
+	threadID = "baf_thread"
+	boardID := "bbs.general"
+	rootPostCID := "baf_root"
+	p1CID := "baf_p1"
+	p1EditedCID := "baf_p1_edited"
+
+	pRoot := &types.Post{
+		Version: 1, Type: types.TypePost, ThreadID: threadID, AuthorPubKey: pubStr,
+		DisplayName: "alice", Body: types.PostBody{Format: "markdown", Content: "root"},
+		CreatedAt: "2025-01-01T00:00:00Z", Meta: map[string]any{},
+	}
+	pRoot.Signature, _ = signature.SignBase64(priv, signature.CanonicalPostPayload(pRoot))
+
+	p1 := &types.Post{
+		Version: 1, Type: types.TypePost, ThreadID: threadID, AuthorPubKey: pubStr,
+		DisplayName: "alice", Body: types.PostBody{Format: "markdown", Content: "hi"},
+		CreatedAt: "2025-01-01T00:01:00Z", Meta: map[string]any{},
+	}
+	p1.Signature, _ = signature.SignBase64(priv, signature.CanonicalPostPayload(p1))
+
+	p1Edited := &types.Post{
+		Version: 1, Type: types.TypePost, ThreadID: threadID, AuthorPubKey: pubStr,
+		DisplayName: "alice", Body: types.PostBody{Format: "markdown", Content: "hi (edited)"},
+		CreatedAt: p1.CreatedAt, Meta: map[string]any{},
+	}
+	p1Edited.Signature, _ = signature.SignBase64(priv, signature.CanonicalPostPayload(p1Edited))
+
+	log1CID, log2CID := "baf_log1", "baf_log2"
+	log1 := &types.BoardLogEntry{
+		Version: 1, Type: types.TypeBoardLogEntry, BoardID: boardID, Op: types.OpCreateThread,
+		ThreadID: threadID, PostCID: &rootPostCID, CreatedAt: "2025-01-01T00:00:10Z", AuthorPubKey: pubStr,
+	}
+	log1.Signature, _ = signature.SignBase64(priv, signature.CanonicalBoardLogEntryPayload(log1))
+
+	log2 := &types.BoardLogEntry{
+		Version: 1, Type: types.TypeBoardLogEntry, BoardID: boardID, Op: types.OpAddPost,
+		ThreadID: threadID, PostCID: &p1CID, CreatedAt: "2025-01-01T00:01:10Z", AuthorPubKey: pubStr,
+		PrevLogCID: &log1CID,
+	}
+	log2.Signature, _ = signature.SignBase64(priv, signature.CanonicalBoardLogEntryPayload(log2))
+
+	forkedAt := "2025-01-01T00:02:10Z"
+
+	logEdit := &types.BoardLogEntry{
+		Version: 1, Type: types.TypeBoardLogEntry, BoardID: boardID, Op: types.OpEditPost,
+		ThreadID: threadID, OldPostCID: &p1CID, NewPostCID: &p1EditedCID, CreatedAt: forkedAt,
+		AuthorPubKey: pubStr, PrevLogCID: &log2CID,
+	}
+	logEdit.Signature, _ = signature.SignBase64(priv, signature.CanonicalBoardLogEntryPayload(logEdit))
+	editTipCID = "baf_log3_edit"
+
+	reason := "spam"
+	logTombstone := &types.BoardLogEntry{
+		Version: 1, Type: types.TypeBoardLogEntry, BoardID: boardID, Op: types.OpTombstonePost,
+		ThreadID: threadID, TargetPostCID: &p1CID, Reason: &reason, CreatedAt: tombstoneCreatedAt,
+		AuthorPubKey: pubStr, PrevLogCID: &log2CID,
+	}
+	logTombstone.Signature, _ = signature.SignBase64(priv, signature.CanonicalBoardLogEntryPayload(logTombstone))
+	tombstoneTipCID = "baf_log3_tombstone"
+
+	posts = map[string]*types.Post{rootPostCID: pRoot, p1CID: p1, p1EditedCID: p1Edited}
+	logs = map[string]*types.BoardLogEntry{
+		log1CID: log1, log2CID: log2,
+		editTipCID: logEdit, tombstoneTipCID: logTombstone,
+	}
+	return logs, posts, threadID, editTipCID, tombstoneTipCID
+}
+
+func TestReplayThreadDAG_ConvergesRegardlessOfTipDiscoveryOrder(t *testing.T) {
+	// Same CreatedAt on both tips: only the (AuthorPubKey, CID) tie-break
+	// decides their relative order, never which tip a replica saw first.
+	logs, posts, threadID, editTipCID, tombstoneTipCID := buildForkedThread(t, "2025-01-01T00:02:10Z")
+
+	loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) { return logs[cid], nil }
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	prevCID := func(e *types.BoardLogEntry) *string { return e.PrevLogCID }
+
+	replay := func(heads []*string) []ReplayedPost {
+		dag, _, err := FetchDAG(context.Background(), heads, loadLog, prevCID, VerifyBoardLogEntry, 100)
+		if err != nil {
+			t.Fatalf("FetchDAG: %v", err)
+		}
+		out, err := ReplayThreadDAG(context.Background(), dag, threadID, loadPost, VerifyPost, nil)
+		if err != nil {
+			t.Fatalf("ReplayThreadDAG: %v", err)
+		}
+		return out
+	}
+
+	dag, _, err := FetchDAG(context.Background(), []*string{&editTipCID, &tombstoneTipCID}, loadLog, prevCID, VerifyBoardLogEntry, 100)
+	if err != nil {
+		t.Fatalf("FetchDAG: %v", err)
+	}
+	if len(dag) != 4 {
+		t.Fatalf("expected the shared log1/log2 ancestry to be fetched once each: got %d nodes", len(dag))
+	}
+
+	replicaA := replay([]*string{&editTipCID, &tombstoneTipCID})
+	replicaB := replay([]*string{&tombstoneTipCID, &editTipCID})
+
+	if len(replicaA) != len(replicaB) {
+		t.Fatalf("replica lengths differ: %d vs %d", len(replicaA), len(replicaB))
+	}
+	for i := range replicaA {
+		a, b := replicaA[i], replicaB[i]
+		if a.CID != b.CID || a.Tombstoned != b.Tombstoned {
+			t.Fatalf("replica %d diverged: %#v vs %#v", i, a, b)
+		}
+	}
+}
+
+func TestReplayThreadDAG_PolicyDropsUnauthorizedTombstone(t *testing.T) {
+	// Tombstone stamped earlier than the edit, so linearizeDAG applies it
+	// first: without a policy rejecting it, it would take effect and
+	// survive the later edit (the reducer carries Tombstoned across a
+	// rename). The policy must be what keeps it from landing, not timing.
+	logs, posts, threadID, editTipCID, tombstoneTipCID := buildForkedThread(t, "2025-01-01T00:02:00Z")
+
+	loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) { return logs[cid], nil }
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	prevCID := func(e *types.BoardLogEntry) *string { return e.PrevLogCID }
+
+	dag, _, err := FetchDAG(context.Background(), []*string{&editTipCID, &tombstoneTipCID}, loadLog, prevCID, VerifyBoardLogEntry, 100)
+	if err != nil {
+		t.Fatalf("FetchDAG: %v", err)
+	}
+
+	const modAllowlistEmpty = "" // no pubkey is an allowed moderator
+	policy := func(e *types.BoardLogEntry) bool {
+		if e.Op != types.OpTombstonePost {
+			return true
+		}
+		return e.AuthorPubKey == modAllowlistEmpty
+	}
+
+	out, err := ReplayThreadDAG(context.Background(), dag, threadID, loadPost, VerifyPost, policy)
+	if err != nil {
+		t.Fatalf("ReplayThreadDAG: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("post count: %d", len(out))
+	}
+	if out[1].Tombstoned {
+		t.Fatalf("tombstone op from an author outside the mod allowlist was applied: %#v", out[1])
+	}
+	if out[1].Post.Body.Content != "hi (edited)" {
+		t.Fatalf("expected the edit to still apply, got: %#v", out[1].Post)
+	}
+}
@@ -0,0 +1,117 @@
+package log
+
+import "context"
+
+// ChainIterator walks a board log (or any PrevLogCID-style linked chain)
+// one entry at a time, newest first, without materializing the whole chain
+// into a slice the way FetchChain does. Useful when a caller only needs the
+// first few entries (e.g. "has anything changed since cursor X?") out of a
+// chain that may be tens of thousands of entries deep.
+type ChainIterator[T any] struct {
+	ctx      context.Context
+	load     LoadFunc[T]
+	prevCID  func(*T) *string
+	verify   func(*T) bool
+	maxDepth int
+
+	current *string
+	visited map[string]struct{}
+	seen    int
+
+	cur EntryWithCID[T]
+	err error
+}
+
+// NewChainIterator returns an iterator starting at headCID. Call Next until
+// it returns false, then check Err for anything other than "end of chain".
+func NewChainIterator[T any](
+	ctx context.Context,
+	headCID *string,
+	load LoadFunc[T],
+	prevCID func(*T) *string,
+	verify func(*T) bool,
+	maxDepth int,
+) *ChainIterator[T] {
+	if maxDepth <= 0 {
+		maxDepth = 10_000
+	}
+	return &ChainIterator[T]{
+		ctx:      ctx,
+		load:     load,
+		prevCID:  prevCID,
+		verify:   verify,
+		maxDepth: maxDepth,
+		current:  headCID,
+		visited:  make(map[string]struct{}),
+	}
+}
+
+// Next advances the iterator and reports whether a new entry is available
+// via Value. It returns false at the end of the chain or on error (use Err
+// to distinguish the two).
+func (it *ChainIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	if it.current == nil || *it.current == "" {
+		return false
+	}
+	if it.seen >= it.maxDepth {
+		it.err = ErrChainTooLong
+		return false
+	}
+
+	cid := *it.current
+	if _, ok := it.visited[cid]; ok {
+		return false
+	}
+	it.visited[cid] = struct{}{}
+	it.seen++
+
+	v, err := it.load(it.ctx, cid)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.cur = EntryWithCID[T]{CID: cid, Value: v, ValidSignature: it.verify(v)}
+	it.current = it.prevCID(v)
+	return true
+}
+
+// Value returns the entry produced by the most recent call to Next.
+func (it *ChainIterator[T]) Value() EntryWithCID[T] {
+	return it.cur
+}
+
+// Err returns the first error encountered, or nil if iteration simply ran
+// off the start of the chain (PrevLogCID == nil).
+func (it *ChainIterator[T]) Err() error {
+	return it.err
+}
+
+// WalkChain streams headCID's chain newest-first into visit, stopping early
+// (without an error) if visit returns false. It's a convenience wrapper
+// around ChainIterator for callers who'd rather not manage the Next/Value
+// loop themselves.
+func WalkChain[T any](
+	ctx context.Context,
+	headCID *string,
+	load LoadFunc[T],
+	prevCID func(*T) *string,
+	verify func(*T) bool,
+	maxDepth int,
+	visit func(EntryWithCID[T]) bool,
+) error {
+	it := NewChainIterator(ctx, headCID, load, prevCID, verify, maxDepth)
+	for it.Next() {
+		if !visit(it.Value()) {
+			return nil
+		}
+	}
+	return it.Err()
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestChainIterator_StreamsNewestFirst(t *testing.T) {
+	prev1 := "log1"
+	prev2 := "log2"
+	entries := map[string]*types.BoardLogEntry{
+		"log3": {BoardID: "b", CreatedAt: "2025-01-03T00:00:00Z", PrevLogCID: &prev2},
+		"log2": {BoardID: "b", CreatedAt: "2025-01-02T00:00:00Z", PrevLogCID: &prev1},
+		"log1": {BoardID: "b", CreatedAt: "2025-01-01T00:00:00Z"},
+	}
+	load := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
+		return entries[cid], nil
+	}
+	head := "log3"
+
+	it := NewChainIterator(context.Background(), &head, load,
+		func(e *types.BoardLogEntry) *string { return e.PrevLogCID },
+		func(e *types.BoardLogEntry) bool { return true }, 0)
+
+	var got []string
+	for it.Next() {
+		got = append(got, it.Value().CID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	want := []string{"log3", "log2", "log1"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWalkChain_StopsEarly(t *testing.T) {
+	prev1 := "log1"
+	entries := map[string]*types.BoardLogEntry{
+		"log2": {BoardID: "b", CreatedAt: "2025-01-02T00:00:00Z", PrevLogCID: &prev1},
+		"log1": {BoardID: "b", CreatedAt: "2025-01-01T00:00:00Z"},
+	}
+	load := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
+		return entries[cid], nil
+	}
+	head := "log2"
+
+	visited := 0
+	err := WalkChain(context.Background(), &head, load,
+		func(e *types.BoardLogEntry) *string { return e.PrevLogCID },
+		func(e *types.BoardLogEntry) bool { return true }, 0,
+		func(e EntryWithCID[types.BoardLogEntry]) bool {
+			visited++
+			return false
+		})
+	if err != nil {
+		t.Fatalf("WalkChain: %v", err)
+	}
+	if visited != 1 {
+		t.Fatalf("expected WalkChain to stop after first entry, visited %d", visited)
+	}
+}
@@ -3,9 +3,14 @@ package log
 import (
 	"context"
 	"errors"
+	"sync"
 )
 
-var ErrLogTooDeep = errors.New("log chain too deep")
+// ErrChainTooLong is the errors.Is target for FetchChain, FetchDAG, and
+// ChainIterator giving up because a chain exceeded the caller's maxDepth
+// (or, since FetchDAG/FetchChain default to 10_000 when maxDepth <= 0, the
+// package default) before reaching its root.
+var ErrChainTooLong = errors.New("log: chain exceeds max depth")
 
 type LoadFunc[T any] func(ctx context.Context, cid string) (*T, error)
 
@@ -35,12 +40,23 @@ func FetchChain[T any](
 
 	current := headCID
 	for current != nil && *current != "" {
+		// The chain is an intrinsically sequential linked list (each next CID
+		// is only known after decoding the current entry), so there's no
+		// useful speculative prefetch within a single chain; what we can do
+		// is bail out promptly if the caller's deadline/cancellation fires
+		// mid-walk instead of finishing the traversal regardless. Batches of
+		// independent chains can still be fetched concurrently with bounded
+		// parallelism via FetchChainsConcurrent below.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		cid := *current
 		if _, ok := visited[cid]; ok {
 			break
 		}
 		if len(out) >= maxDepth {
-			return nil, ErrLogTooDeep
+			return nil, ErrChainTooLong
 		}
 		visited[cid] = struct{}{}
 
@@ -56,6 +72,43 @@ func FetchChain[T any](
 	return out, nil
 }
 
+// FetchChainsConcurrent runs FetchChain for each head in heads, bounded to at
+// most concurrency chains in flight at once, and returns their results in
+// the same order as heads. If any chain returns an error, that slot's error
+// is returned in errs at the same index; callers that want fail-fast
+// semantics should check errs themselves (FetchChainsConcurrent always
+// collects every result rather than canceling siblings on the first error).
+func FetchChainsConcurrent[T any](
+	ctx context.Context,
+	heads []*string,
+	load LoadFunc[T],
+	prevCID func(*T) *string,
+	verify func(*T) bool,
+	maxDepth int,
+	concurrency int,
+) (results [][]EntryWithCID[T], errs []error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results = make([][]EntryWithCID[T], len(heads))
+	errs = make([]error, len(heads))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, head := range heads {
+		i, head := i, head
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = FetchChain(ctx, head, load, prevCID, verify, maxDepth)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
+
 func reverse[T any](s []EntryWithCID[T]) {
 	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
 		s[i], s[j] = s[j], s[i]
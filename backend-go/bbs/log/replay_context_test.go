@@ -0,0 +1,54 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestFetchChain_RespectsCancellation(t *testing.T) {
+	cid := "log1"
+	entry := &types.BoardLogEntry{BoardID: "b", CreatedAt: "2025-01-01T00:00:00Z"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	load := func(ctx context.Context, c string) (*types.BoardLogEntry, error) {
+		return entry, nil
+	}
+	_, err := FetchChain(ctx, &cid, load, func(e *types.BoardLogEntry) *string { return e.PrevLogCID }, func(e *types.BoardLogEntry) bool { return true }, 0)
+	if err == nil {
+		t.Fatal("expected error from canceled context")
+	}
+}
+
+func TestFetchChainsConcurrent_BoundsAndOrdering(t *testing.T) {
+	entries := map[string]*types.BoardLogEntry{
+		"a": {BoardID: "board-a", CreatedAt: "2025-01-01T00:00:00Z"},
+		"b": {BoardID: "board-b", CreatedAt: "2025-01-01T00:00:00Z"},
+		"c": {BoardID: "board-c", CreatedAt: "2025-01-01T00:00:00Z"},
+	}
+	load := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
+		return entries[cid], nil
+	}
+	cidA, cidB, cidC := "a", "b", "c"
+	heads := []*string{&cidA, &cidB, &cidC}
+
+	results, errs := FetchChainsConcurrent(context.Background(), heads, load,
+		func(e *types.BoardLogEntry) *string { return e.PrevLogCID },
+		func(e *types.BoardLogEntry) bool { return true },
+		10, 2)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("chain %d: %v", i, err)
+		}
+	}
+	wantBoards := []string{"board-a", "board-b", "board-c"}
+	for i, want := range wantBoards {
+		if len(results[i]) != 1 || results[i][0].Value.BoardID != want {
+			t.Fatalf("chain %d: got %#v, want board %s", i, results[i], want)
+		}
+	}
+}
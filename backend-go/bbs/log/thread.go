@@ -2,11 +2,39 @@ package log
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"flex-bbs/backend-go/bbs/types"
 )
 
+// ErrPostMissing is the errors.Is target for ReplayThread/ReplayThreadDAG
+// failing because a post CID referenced by the board log (via PostCID,
+// NewPostCID, or an OldPostCID needing loadOldForAuth) could not be
+// loaded. It wraps whatever the caller's loadPost/loadOldForAuth
+// returned, so e.g. errors.Is(err, storage.ErrNotFound) still works
+// through it.
+var ErrPostMissing = errors.New("log: referenced post could not be loaded")
+
+// ErrReplayCancelled is the errors.Is target for a replay stopping early
+// because ctx was cancelled or hit its deadline. It wraps ctx.Err()
+// directly, so errors.Is(err, context.DeadlineExceeded) still works
+// through it — the extra sentinel just lets a caller tell "we gave up"
+// apart from ErrPostMissing ("a post was unreachable") without comparing
+// against context.Canceled/context.DeadlineExceeded itself.
+var ErrReplayCancelled = errors.New("log: replay cancelled")
+
+// checkCtx returns a wrapped, errors.Is-able error if ctx has been
+// cancelled or hit its deadline, and nil otherwise.
+func checkCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrReplayCancelled, err)
+	}
+	return nil
+}
+
 type ReplayedPost struct {
 	CID             string
 	Post            *types.Post
@@ -14,6 +42,9 @@ type ReplayedPost struct {
 	TombstoneReason *string
 }
 
+// cv, if non-nil, enforces PrevLogCID chain integrity over boardLog before
+// replay (see ChainVerification); under ChainPolicyStrict a broken chain
+// truncates the result and returns an *ErrChainBroken.
 func ReplayThread(
 	ctx context.Context,
 	boardLog []EntryWithCID[types.BoardLogEntry],
@@ -21,6 +52,228 @@ func ReplayThread(
 	loadPost LoadFunc[types.Post],
 	verifyPost func(*types.Post) bool,
 	loadOldForAuth LoadFunc[types.Post],
+	cv *ChainVerification,
+) ([]ReplayedPost, error) {
+	filtered, chainErr := verifyAndFilterChain(boardLog, cv)
+	out, err := replayEntries(ctx, filtered, threadID, loadPost, verifyPost, loadOldForAuth, nil, 0)
+	if err != nil {
+		return out, err
+	}
+	return out, chainErr
+}
+
+// ReplayThreadPrefetched behaves exactly like ReplayThread, except it
+// first collects every distinct post CID boardLog references for
+// threadID (PostCID/NewPostCID, plus OldPostCID when loadOldForAuth is
+// set) and fetches them all in one batch, bounded to concurrency at once
+// (DefaultPrefetchConcurrency if concurrency <= 0), before handing
+// control to the same sequential reducer ReplayThread uses.
+//
+// This only pays off when loadPost/loadOldForAuth themselves dedupe and
+// cache by CID (e.g. a func wrapping storage.Load/storage.Fetcher) — the
+// prefetch pass's results aren't threaded through to the reducer pass
+// directly, they're expected to already be sitting in that shared cache
+// by the time replayEntries calls loadPost/loadOldForAuth again for the
+// same CID. With a plain uncached loader this just fetches every post
+// twice.
+//
+// cv behaves exactly as it does for ReplayThread.
+func ReplayThreadPrefetched(
+	ctx context.Context,
+	boardLog []EntryWithCID[types.BoardLogEntry],
+	threadID string,
+	loadPost LoadFunc[types.Post],
+	verifyPost func(*types.Post) bool,
+	loadOldForAuth LoadFunc[types.Post],
+	concurrency int,
+	cv *ChainVerification,
+) ([]ReplayedPost, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultPrefetchConcurrency
+	}
+
+	filtered, chainErr := verifyAndFilterChain(boardLog, cv)
+
+	cids := threadPostCIDs(filtered, threadID, loadOldForAuth != nil)
+	if len(cids) > 0 {
+		if err := prefetchCIDs(ctx, cids, loadPost, concurrency); err != nil {
+			return nil, err
+		}
+	}
+	out, err := replayEntries(ctx, filtered, threadID, loadPost, verifyPost, loadOldForAuth, nil, 0)
+	if err != nil {
+		return out, err
+	}
+	return out, chainErr
+}
+
+// DefaultPrefetchConcurrency is ReplayThreadPrefetched's batch-prefetch
+// worker pool size when concurrency <= 0.
+const DefaultPrefetchConcurrency = 16
+
+// ReplayOptions bounds and tunes a single ReplayThreadWithOptions call.
+// The zero value behaves exactly like ReplayThread: no cap, no per-post
+// timeout, no prefetch.
+type ReplayOptions struct {
+	// MaxPosts stops accepting new posts (OpCreateThread/OpAddPost) once
+	// this many distinct posts have been applied; 0 means unlimited.
+	// Edits/tombstones against already-accepted posts are unaffected by
+	// the cap.
+	MaxPosts int
+
+	// PerPostTimeout, if > 0, bounds each individual loadPost/
+	// loadOldForAuth call with its own context.WithTimeout derived from
+	// ctx, separate from ctx's own overall deadline — so one unusually
+	// slow fetch can time out without consuming the whole replay's
+	// remaining budget.
+	PerPostTimeout time.Duration
+
+	// VerifyConcurrency, if > 1, prefetches loadPost for every post CID
+	// boardLog references (exactly like ReplayThreadPrefetched's
+	// concurrency) using this many workers before the deterministic
+	// reduce pass runs. <= 1 means no prefetch.
+	VerifyConcurrency int
+}
+
+// withPerCallTimeout wraps load so every call gets its own
+// context.WithTimeout(ctx, d), instead of sharing ctx's overall deadline.
+func withPerCallTimeout(load LoadFunc[types.Post], d time.Duration) LoadFunc[types.Post] {
+	return func(ctx context.Context, cid string) (*types.Post, error) {
+		callCtx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return load(callCtx, cid)
+	}
+}
+
+// ReplayThreadWithOptions behaves like ReplayThread, but applies opts:
+// VerifyConcurrency > 1 prefetches posts like ReplayThreadPrefetched,
+// PerPostTimeout bounds individual loadPost/loadOldForAuth calls, and
+// MaxPosts caps how many distinct posts are accepted. It's meant for
+// large board logs driven by a request-scoped ctx, where a slow or
+// cancelled replay should give up promptly instead of burning CPU on
+// ed25519 verification and fetches for a caller that's already gone.
+func ReplayThreadWithOptions(
+	ctx context.Context,
+	boardLog []EntryWithCID[types.BoardLogEntry],
+	threadID string,
+	loadPost LoadFunc[types.Post],
+	verifyPost func(*types.Post) bool,
+	loadOldForAuth LoadFunc[types.Post],
+	cv *ChainVerification,
+	opts ReplayOptions,
+) ([]ReplayedPost, error) {
+	filtered, chainErr := verifyAndFilterChain(boardLog, cv)
+
+	if opts.VerifyConcurrency > 1 {
+		cids := threadPostCIDs(filtered, threadID, loadOldForAuth != nil)
+		if len(cids) > 0 {
+			if err := prefetchCIDs(ctx, cids, loadPost, opts.VerifyConcurrency); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if opts.PerPostTimeout > 0 {
+		loadPost = withPerCallTimeout(loadPost, opts.PerPostTimeout)
+		if loadOldForAuth != nil {
+			loadOldForAuth = withPerCallTimeout(loadOldForAuth, opts.PerPostTimeout)
+		}
+	}
+
+	out, err := replayEntries(ctx, filtered, threadID, loadPost, verifyPost, loadOldForAuth, nil, opts.MaxPosts)
+	if err != nil {
+		return out, err
+	}
+	return out, chainErr
+}
+
+// threadPostCIDs returns the distinct post CIDs boardLog's entries for
+// threadID reference, in first-seen order. includeOld also collects
+// OldPostCID (only useful to prefetch when the reducer might call
+// loadOldForAuth for it).
+func threadPostCIDs(boardLog []EntryWithCID[types.BoardLogEntry], threadID string, includeOld bool) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	add := func(cid *string) {
+		if cid == nil || *cid == "" {
+			return
+		}
+		if _, ok := seen[*cid]; ok {
+			return
+		}
+		seen[*cid] = struct{}{}
+		out = append(out, *cid)
+	}
+
+	for _, item := range boardLog {
+		e := item.Value
+		if !item.ValidSignature || e.ThreadID != threadID {
+			continue
+		}
+		switch e.Op {
+		case types.OpCreateThread, types.OpAddPost:
+			add(e.PostCID)
+		case types.OpEditPost:
+			add(e.NewPostCID)
+			if includeOld {
+				add(e.OldPostCID)
+			}
+		}
+	}
+	return out
+}
+
+// prefetchCIDs loads every cid in cids via load, running up to
+// concurrency loads at once, and discards the results — callers rely on
+// load itself caching by CID so a later, sequential call for the same cid
+// is free. It returns the first error encountered, if any.
+func prefetchCIDs(ctx context.Context, cids []string, load LoadFunc[types.Post], concurrency int) error {
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, cid := range cids {
+		cid := cid
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := load(ctx, cid); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// replayEntries is the shared reducer behind both ReplayThread (entries
+// already in chain order) and ReplayThreadDAG (entries linearized from a
+// DAG into the same deterministic order). policy, if non-nil, is
+// consulted for every entry in threadID before it's applied; a false
+// result drops the entry exactly like an invalid signature would.
+// maxPosts, if > 0, stops accepting new posts (OpCreateThread/OpAddPost)
+// once that many have been applied; 0 means unlimited. ctx is checked at
+// least once per entry, and again before every loadPost/loadOldForAuth
+// call, so a cancelled/expired ctx stops the reduce pass promptly instead
+// of running every remaining entry's signature verification and fetches
+// first.
+func replayEntries(
+	ctx context.Context,
+	boardLog []EntryWithCID[types.BoardLogEntry],
+	threadID string,
+	loadPost LoadFunc[types.Post],
+	verifyPost func(*types.Post) bool,
+	loadOldForAuth LoadFunc[types.Post],
+	policy func(*types.BoardLogEntry) bool,
+	maxPosts int,
 ) ([]ReplayedPost, error) {
 	type slot struct {
 		ReplayedPost
@@ -30,8 +283,19 @@ func ReplayThread(
 		order      []slot
 		indexByCID = make(map[string]int)
 	)
+	partial := func() []ReplayedPost {
+		out := make([]ReplayedPost, 0, len(order))
+		for _, s := range order {
+			out = append(out, s.ReplayedPost)
+		}
+		return out
+	}
 
 	for _, item := range boardLog {
+		if err := checkCtx(ctx); err != nil {
+			return partial(), err
+		}
+
 		e := item.Value
 		if !item.ValidSignature {
 			continue
@@ -39,6 +303,9 @@ func ReplayThread(
 		if e.ThreadID != threadID {
 			continue
 		}
+		if policy != nil && !policy(e) {
+			continue
+		}
 
 		switch e.Op {
 		case types.OpCreateThread, types.OpAddPost:
@@ -46,9 +313,15 @@ func ReplayThread(
 				continue
 			}
 			postCID := *e.PostCID
+			if _, exists := indexByCID[postCID]; !exists && maxPosts > 0 && len(order) >= maxPosts {
+				continue
+			}
+			if err := checkCtx(ctx); err != nil {
+				return partial(), err
+			}
 			p, err := loadPost(ctx, postCID)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: post cid=%s: %w", ErrPostMissing, postCID, err)
 			}
 			if !verifyPost(p) {
 				continue
@@ -75,10 +348,13 @@ func ReplayThread(
 			}
 			oldP := order[idx].Post
 			if oldP == nil && loadOldForAuth != nil {
+				if err := checkCtx(ctx); err != nil {
+					return partial(), err
+				}
 				var err error
 				oldP, err = loadOldForAuth(ctx, oldCID)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("%w: post cid=%s: %w", ErrPostMissing, oldCID, err)
 				}
 				if !verifyPost(oldP) {
 					continue
@@ -88,9 +364,12 @@ func ReplayThread(
 				continue
 			}
 
+			if err := checkCtx(ctx); err != nil {
+				return partial(), err
+			}
 			newP, err := loadPost(ctx, newCID)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: post cid=%s: %w", ErrPostMissing, newCID, err)
 			}
 			if !verifyPost(newP) {
 				continue
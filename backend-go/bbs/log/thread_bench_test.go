@@ -0,0 +1,154 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// benchThreadLog builds n OpAddPost entries for a single thread, each
+// referencing a distinct post CID, with ValidSignature already set so the
+// benchmark measures ReplayThread's/ReplayThreadPrefetched's post-fetch
+// phase rather than signature verification.
+func benchThreadLog(n int) []EntryWithCID[types.BoardLogEntry] {
+	threadID := "baf_thread"
+	out := make([]EntryWithCID[types.BoardLogEntry], 0, n)
+	for i := 0; i < n; i++ {
+		postCID := fmt.Sprintf("baf_post_%d", i)
+		e := &types.BoardLogEntry{
+			Type:      types.TypeBoardLogEntry,
+			ThreadID:  threadID,
+			Op:        types.OpAddPost,
+			PostCID:   &postCID,
+			CreatedAt: time.Unix(int64(i), 0).UTC().Format(time.RFC3339),
+		}
+		out = append(out, EntryWithCID[types.BoardLogEntry]{
+			CID:            "baf_log_" + postCID,
+			Value:          e,
+			ValidSignature: true,
+		})
+	}
+	return out
+}
+
+// benchRoundTripLoader simulates a single Flex.GetValue round-trip's
+// latency, with no caching of its own — the same shape as a loadPost
+// closure that calls straight through to Storage.LoadPost.
+func benchRoundTripLoader(latency time.Duration) LoadFunc[types.Post] {
+	return func(ctx context.Context, cid string) (*types.Post, error) {
+		time.Sleep(latency)
+		return &types.Post{Type: types.TypePost, PostCID: &cid}, nil
+	}
+}
+
+// poolCachingLoader wraps base with an in-memory, single-flighted,
+// N-way-bounded cache — standing in for storage.Load(storage.NewFetcher(n), ...)
+// without this package importing bbs/storage.
+func poolCachingLoader(base LoadFunc[types.Post], n int) LoadFunc[types.Post] {
+	sem := make(chan struct{}, n)
+	var mu sync.Mutex
+	cache := make(map[string]*types.Post)
+	inflight := make(map[string]chan struct{})
+
+	return func(ctx context.Context, cid string) (*types.Post, error) {
+		mu.Lock()
+		if p, ok := cache[cid]; ok {
+			mu.Unlock()
+			return p, nil
+		}
+		if done, ok := inflight[cid]; ok {
+			mu.Unlock()
+			<-done
+			mu.Lock()
+			p := cache[cid]
+			mu.Unlock()
+			return p, nil
+		}
+		done := make(chan struct{})
+		inflight[cid] = done
+		mu.Unlock()
+
+		sem <- struct{}{}
+		p, err := base(ctx, cid)
+		<-sem
+
+		mu.Lock()
+		if err == nil {
+			cache[cid] = p
+		}
+		delete(inflight, cid)
+		mu.Unlock()
+		close(done)
+		return p, err
+	}
+}
+
+// BenchmarkReplayThread_Sequential replays a 1000-entry thread through the
+// plain ReplayThread, one loadPost round-trip at a time: wall-clock scales
+// with n regardless of the artificial per-load latency below.
+func BenchmarkReplayThread_Sequential(b *testing.B) {
+	boardLog := benchThreadLog(1000)
+	loadPost := benchRoundTripLoader(time.Millisecond)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, func(*types.Post) bool { return true }, nil, nil); err != nil {
+			b.Fatalf("ReplayThread: %v", err)
+		}
+	}
+}
+
+// BenchmarkReplayThreadPrefetched_Pooled replays the same 1000-entry thread
+// through ReplayThreadPrefetched backed by a pooled/single-flighted loader
+// (concurrency 16): the batch prefetch pays roughly n/16 round-trips'
+// worth of wall-clock instead of n, and the reducer's own sequential
+// loadPost calls that follow are cache hits.
+func BenchmarkReplayThreadPrefetched_Pooled(b *testing.B) {
+	boardLog := benchThreadLog(1000)
+	const concurrency = 16
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		loadPost := poolCachingLoader(benchRoundTripLoader(time.Millisecond), concurrency)
+		if _, err := ReplayThreadPrefetched(context.Background(), boardLog, "baf_thread", loadPost, func(*types.Post) bool { return true }, nil, concurrency, nil); err != nil {
+			b.Fatalf("ReplayThreadPrefetched: %v", err)
+		}
+	}
+}
+
+// BenchmarkReplayThreadWithOptions_10k_Sequential replays a 10k-entry
+// board log with VerifyConcurrency left at its zero value (no prefetch):
+// wall-clock scales with n, one loadPost round-trip at a time.
+func BenchmarkReplayThreadWithOptions_10k_Sequential(b *testing.B) {
+	boardLog := benchThreadLog(10_000)
+	loadPost := benchRoundTripLoader(time.Millisecond)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReplayThreadWithOptions(context.Background(), boardLog, "baf_thread", loadPost, func(*types.Post) bool { return true }, nil, nil, ReplayOptions{}); err != nil {
+			b.Fatalf("ReplayThreadWithOptions: %v", err)
+		}
+	}
+}
+
+// BenchmarkReplayThreadWithOptions_10k_Concurrent replays the same
+// 10k-entry board log with VerifyConcurrency set: the batch prefetch
+// pays roughly n/concurrency round-trips' worth of wall-clock instead of
+// n, demonstrating the speedup VerifyConcurrency is for.
+func BenchmarkReplayThreadWithOptions_10k_Concurrent(b *testing.B) {
+	boardLog := benchThreadLog(10_000)
+	const concurrency = 32
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		loadPost := poolCachingLoader(benchRoundTripLoader(time.Millisecond), concurrency)
+		opts := ReplayOptions{VerifyConcurrency: concurrency}
+		if _, err := ReplayThreadWithOptions(context.Background(), boardLog, "baf_thread", loadPost, func(*types.Post) bool { return true }, nil, nil, opts); err != nil {
+			b.Fatalf("ReplayThreadWithOptions: %v", err)
+		}
+	}
+}
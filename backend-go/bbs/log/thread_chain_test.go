@@ -0,0 +1,155 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// chainTestEntry builds a minimal, already-"verified" BoardLogEntry/post
+// pair for exercising ChainVerification without signing — ReplayThread's
+// chain enforcement runs independently of signature checks.
+func chainTestEntry(cid, prevCID, author, postCID string) (EntryWithCID[types.BoardLogEntry], *types.Post) {
+	var prev *string
+	if prevCID != "" {
+		prev = &prevCID
+	}
+	p := &types.Post{ThreadID: "baf_thread", AuthorPubKey: author, Body: types.PostBody{Content: postCID}}
+	e := &types.BoardLogEntry{
+		BoardID:      "bbs.general",
+		Op:           types.OpAddPost,
+		ThreadID:     "baf_thread",
+		PostCID:      &postCID,
+		AuthorPubKey: author,
+		PrevLogCID:   prev,
+	}
+	return EntryWithCID[types.BoardLogEntry]{CID: cid, Value: e, ValidSignature: true}, p
+}
+
+func TestReplayThread_ChainPolicyStrict_TruncatesAtBreak(t *testing.T) {
+	e1, p1 := chainTestEntry("log1", "", "alice", "post1")
+	e2, p2 := chainTestEntry("log2", "not-log1", "alice", "post2")
+	e3, p3 := chainTestEntry("log3", "log2", "alice", "post3")
+
+	posts := map[string]*types.Post{"post1": p1, "post2": p2, "post3": p3}
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	verifyPost := func(*types.Post) bool { return true }
+
+	boardLog := []EntryWithCID[types.BoardLogEntry]{e1, e2, e3}
+	cv := &ChainVerification{Policy: ChainPolicyStrict}
+
+	out, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, cv)
+	brokenErr, ok := err.(*ErrChainBroken)
+	if !ok {
+		t.Fatalf("expected *ErrChainBroken, got %v (%T)", err, err)
+	}
+	if brokenErr.Index != 1 || brokenErr.Expected != "log1" || brokenErr.Got != "not-log1" {
+		t.Fatalf("unexpected ErrChainBroken: %#v", brokenErr)
+	}
+	if len(out) != 1 || out[0].CID != "post1" {
+		t.Fatalf("expected replay truncated to just post1, got %#v", out)
+	}
+}
+
+func TestReplayThread_ChainPolicyLenient_ReportsButKeepsReplaying(t *testing.T) {
+	e1, p1 := chainTestEntry("log1", "", "alice", "post1")
+	e2, p2 := chainTestEntry("log2", "not-log1", "alice", "post2")
+
+	posts := map[string]*types.Post{"post1": p1, "post2": p2}
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	verifyPost := func(*types.Post) bool { return true }
+
+	var reported []ErrChainBroken
+	cv := &ChainVerification{
+		Policy:  ChainPolicyLenient,
+		OnBreak: func(e ErrChainBroken) { reported = append(reported, e) },
+	}
+
+	boardLog := []EntryWithCID[types.BoardLogEntry]{e1, e2}
+	out, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, cv)
+	if err != nil {
+		t.Fatalf("ReplayThread: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected both entries replayed despite the break, got %#v", out)
+	}
+	if len(reported) != 1 || reported[0].Index != 1 {
+		t.Fatalf("expected exactly one reported break at index 1, got %#v", reported)
+	}
+}
+
+func TestReplayThread_ChainPolicyRepair_ResetsExpectationAfterBreak(t *testing.T) {
+	e1, p1 := chainTestEntry("log1", "", "alice", "post1")
+	e2, p2 := chainTestEntry("log2", "not-log1", "alice", "post2")
+	e3, p3 := chainTestEntry("log3", "log2", "alice", "post3")
+
+	posts := map[string]*types.Post{"post1": p1, "post2": p2, "post3": p3}
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	verifyPost := func(*types.Post) bool { return true }
+
+	var reported []ErrChainBroken
+	cv := &ChainVerification{
+		Policy:  ChainPolicyRepair,
+		OnBreak: func(e ErrChainBroken) { reported = append(reported, e) },
+	}
+
+	boardLog := []EntryWithCID[types.BoardLogEntry]{e1, e2, e3}
+	out, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, cv)
+	if err != nil {
+		t.Fatalf("ReplayThread: %v", err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("expected all three entries replayed, got %#v", out)
+	}
+	// log3's PrevLogCID correctly points at log2, so once the repair
+	// resets the expected chain at log2, nothing after it should break.
+	if len(reported) != 1 || reported[0].Index != 1 {
+		t.Fatalf("expected exactly one reported break at index 1, got %#v", reported)
+	}
+}
+
+func TestReplayThread_ChainVerification_DetectsSameAuthorFork(t *testing.T) {
+	e1, p1 := chainTestEntry("log1", "", "alice", "post1")
+	// Both log2 and log3 claim log1 as their parent, signed by the same
+	// author: a fork attempt, not merely an out-of-order entry.
+	e2, p2 := chainTestEntry("log2", "log1", "alice", "post2")
+	e3, p3 := chainTestEntry("log3", "log1", "alice", "post3")
+
+	posts := map[string]*types.Post{"post1": p1, "post2": p2, "post3": p3}
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	verifyPost := func(*types.Post) bool { return true }
+
+	boardLog := []EntryWithCID[types.BoardLogEntry]{e1, e2, e3}
+	cv := &ChainVerification{Policy: ChainPolicyStrict}
+
+	out, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, cv)
+	brokenErr, ok := err.(*ErrChainBroken)
+	if !ok {
+		t.Fatalf("expected *ErrChainBroken for the forked entry, got %v (%T)", err, err)
+	}
+	if brokenErr.Index != 2 {
+		t.Fatalf("expected the fork to be caught at index 2, got %#v", brokenErr)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected replay truncated before the forked entry, got %#v", out)
+	}
+}
+
+func TestReplayThread_ChainVerificationNil_BehavesLikeBefore(t *testing.T) {
+	e1, p1 := chainTestEntry("log1", "", "alice", "post1")
+	e2, p2 := chainTestEntry("log2", "not-log1", "alice", "post2")
+
+	posts := map[string]*types.Post{"post1": p1, "post2": p2}
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) { return posts[cid], nil }
+	verifyPost := func(*types.Post) bool { return true }
+
+	boardLog := []EntryWithCID[types.BoardLogEntry]{e1, e2}
+	out, err := ReplayThread(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, nil)
+	if err != nil {
+		t.Fatalf("ReplayThread: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected both entries replayed with no chain enforcement configured, got %#v", out)
+	}
+}
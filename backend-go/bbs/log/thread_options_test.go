@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestReplayThreadWithOptions_MaxPostsCapsNewPosts(t *testing.T) {
+	boardLog := benchThreadLog(5)
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) {
+		return &types.Post{Type: types.TypePost, PostCID: &cid}, nil
+	}
+	verifyPost := func(*types.Post) bool { return true }
+
+	out, err := ReplayThreadWithOptions(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, nil, ReplayOptions{MaxPosts: 2})
+	if err != nil {
+		t.Fatalf("ReplayThreadWithOptions: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected replay capped at 2 posts, got %d", len(out))
+	}
+}
+
+func TestReplayThreadWithOptions_RespectsCancellation(t *testing.T) {
+	boardLog := benchThreadLog(100)
+	loadPost := func(ctx context.Context, cid string) (*types.Post, error) {
+		return &types.Post{Type: types.TypePost, PostCID: &cid}, nil
+	}
+	verifyPost := func(*types.Post) bool { return true }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ReplayThreadWithOptions(ctx, boardLog, "baf_thread", loadPost, verifyPost, nil, nil, ReplayOptions{})
+	if !errors.Is(err, ErrReplayCancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping both ErrReplayCancelled and context.Canceled, got %v", err)
+	}
+}
+
+func TestReplayThreadWithOptions_VerifyConcurrencyPrefetches(t *testing.T) {
+	boardLog := benchThreadLog(50)
+	const concurrency = 8
+	loadPost := poolCachingLoader(func(ctx context.Context, cid string) (*types.Post, error) {
+		return &types.Post{Type: types.TypePost, PostCID: &cid}, nil
+	}, concurrency)
+	verifyPost := func(*types.Post) bool { return true }
+
+	out, err := ReplayThreadWithOptions(context.Background(), boardLog, "baf_thread", loadPost, verifyPost, nil, nil, ReplayOptions{VerifyConcurrency: concurrency})
+	if err != nil {
+		t.Fatalf("ReplayThreadWithOptions: %v", err)
+	}
+	if len(out) != 50 {
+		t.Fatalf("expected all 50 posts replayed, got %d", len(out))
+	}
+}
@@ -152,7 +152,7 @@ func TestReplayThread_EditAndTombstone(t *testing.T) {
 		t.Fatalf("FetchChain: %v", err)
 	}
 
-	out, err := ReplayThread(context.Background(), chain, threadID, loadPost, VerifyPost, nil)
+	out, err := ReplayThread(context.Background(), chain, threadID, loadPost, VerifyPost, nil, nil)
 	if err != nil {
 		t.Fatalf("ReplayThread: %v", err)
 	}
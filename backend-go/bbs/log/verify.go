@@ -5,7 +5,21 @@ import (
 	"flex-bbs/backend-go/bbs/types"
 )
 
+// The mojibake-recovery scaffolding below (recoverUTF8FromLatin1 and its
+// call sites) only applies to types.Version1 records, which used the
+// hand-rolled key=value canonicalization. Version2 records are signed
+// over RFC 8785 canonical JSON, and Version3 records use the
+// flex-canon/v2 algorithm (see signature.CanonicalPostPayloadV2 and
+// siblings), both of which verify byte-exact, so neither ever needs the
+// fixup pass.
 func VerifyPost(p *types.Post) bool {
+	if p.Version >= types.Version3 {
+		return verifyPostCanonV2(p)
+	}
+	if p.Version >= types.Version2 {
+		return verifyPostJCS(p)
+	}
+
 	pub, err := signature.ParsePublicKey(p.AuthorPubKey)
 	if err != nil {
 		if recovered, ok := recoverUTF8FromLatin1(p.AuthorPubKey); ok {
@@ -65,7 +79,34 @@ func VerifyPost(p *types.Post) bool {
 	return true
 }
 
+func verifyPostJCS(p *types.Post) bool {
+	pub, err := signature.ParsePublicKey(p.AuthorPubKey)
+	if err != nil {
+		return false
+	}
+	payload, err := signature.CanonicalPostJSON(p)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, string(payload), p.Signature) == nil
+}
+
+func verifyPostCanonV2(p *types.Post) bool {
+	pub, err := signature.ParsePublicKey(p.AuthorPubKey)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, signature.CanonicalPostPayloadV2(p), p.Signature) == nil
+}
+
 func VerifyBoardLogEntry(e *types.BoardLogEntry) bool {
+	if e.Version >= types.Version3 {
+		return verifyBoardLogEntryCanonV2(e)
+	}
+	if e.Version >= types.Version2 {
+		return verifyBoardLogEntryJCS(e)
+	}
+
 	pub, err := signature.ParsePublicKey(e.AuthorPubKey)
 	if err != nil {
 		if recovered, ok := recoverUTF8FromLatin1(e.AuthorPubKey); ok {
@@ -161,7 +202,34 @@ func VerifyBoardLogEntry(e *types.BoardLogEntry) bool {
 	return true
 }
 
+func verifyBoardLogEntryJCS(e *types.BoardLogEntry) bool {
+	pub, err := signature.ParsePublicKey(e.AuthorPubKey)
+	if err != nil {
+		return false
+	}
+	payload, err := signature.CanonicalBoardLogEntryJSON(e)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, string(payload), e.Signature) == nil
+}
+
+func verifyBoardLogEntryCanonV2(e *types.BoardLogEntry) bool {
+	pub, err := signature.ParsePublicKey(e.AuthorPubKey)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, signature.CanonicalBoardLogEntryPayloadV2(e), e.Signature) == nil
+}
+
 func VerifyThreadMeta(m *types.ThreadMeta) bool {
+	if m.Version >= types.Version3 {
+		return verifyThreadMetaCanonV2(m)
+	}
+	if m.Version >= types.Version2 {
+		return verifyThreadMetaJCS(m)
+	}
+
 	pub, err := signature.ParsePublicKey(m.CreatedBy)
 	if err != nil {
 		if recovered, ok := recoverUTF8FromLatin1(m.CreatedBy); ok {
@@ -211,7 +279,34 @@ func VerifyThreadMeta(m *types.ThreadMeta) bool {
 	return true
 }
 
+func verifyThreadMetaJCS(m *types.ThreadMeta) bool {
+	pub, err := signature.ParsePublicKey(m.CreatedBy)
+	if err != nil {
+		return false
+	}
+	payload, err := signature.CanonicalThreadMetaJSON(m)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, string(payload), m.Signature) == nil
+}
+
+func verifyThreadMetaCanonV2(m *types.ThreadMeta) bool {
+	pub, err := signature.ParsePublicKey(m.CreatedBy)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, signature.CanonicalThreadMetaPayloadV2(m), m.Signature) == nil
+}
+
 func VerifyBoardMeta(m *types.BoardMeta) bool {
+	if m.Version >= types.Version3 {
+		return verifyBoardMetaCanonV2(m)
+	}
+	if m.Version >= types.Version2 {
+		return verifyBoardMetaJCS(m)
+	}
+
 	pub, err := signature.ParsePublicKey(m.CreatedBy)
 	if err != nil {
 		if recovered, ok := recoverUTF8FromLatin1(m.CreatedBy); ok {
@@ -264,3 +359,40 @@ func VerifyBoardMeta(m *types.BoardMeta) bool {
 	*m = fixed
 	return true
 }
+
+func verifyBoardMetaJCS(m *types.BoardMeta) bool {
+	pub, err := signature.ParsePublicKey(m.CreatedBy)
+	if err != nil {
+		return false
+	}
+	payload, err := signature.CanonicalBoardMetaJSON(m)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, string(payload), m.Signature) == nil
+}
+
+func verifyBoardMetaCanonV2(m *types.BoardMeta) bool {
+	pub, err := signature.ParsePublicKey(m.CreatedBy)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, signature.CanonicalBoardMetaPayloadV2(m), m.Signature) == nil
+}
+
+// VerifyBoardPointer checks p's signature only; it has no legacy version to
+// dispatch on (see types.BoardPointer), so there's no mojibake-recovery
+// fallback here. Callers resolving a pointer (storage.ResolveBoardPointer)
+// also need to reject an expired ValidUntil, which isn't this function's
+// concern since expiry isn't part of what the signature covers.
+func VerifyBoardPointer(p *types.BoardPointer) bool {
+	pub, err := signature.ParsePublicKey(p.PubKey)
+	if err != nil {
+		return false
+	}
+	payload, err := signature.CanonicalBoardPointerJSON(p)
+	if err != nil {
+		return false
+	}
+	return signature.VerifyBase64(pub, string(payload), p.Signature) == nil
+}
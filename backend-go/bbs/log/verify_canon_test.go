@@ -0,0 +1,125 @@
+package log
+
+import (
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// These tests cover the flex-canon/v2 (types.Version3) signing path
+// alongside the pre-existing Version1 (flex-canon/v1) and Version2 (JCS)
+// paths, to make sure a node that rotates a single key across record
+// versions — or simply holds a mix of old and new records signed by
+// different keys — can still verify all three.
+
+func TestVerifyBoardMeta_CanonV2(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	bm := &types.BoardMeta{
+		BoardID:     "bbs.general",
+		Title:       "General",
+		Description: "multi\nline description",
+		CreatedAt:   "2026-01-01T00:00:00Z",
+	}
+	if err := signature.SignBoardMetaCanonV2(priv, bm); err != nil {
+		t.Fatalf("SignBoardMetaCanonV2: %v", err)
+	}
+	if bm.Version != types.Version3 {
+		t.Fatalf("Version = %d, want %d", bm.Version, types.Version3)
+	}
+	if !VerifyBoardMeta(bm) {
+		t.Fatalf("VerifyBoardMeta failed for a flex-canon/v2 payload")
+	}
+
+	// Tampering with a field the v1 scheme couldn't even represent safely
+	// (a literal newline inside Description) must still invalidate the
+	// signature under v2.
+	bm.Description = "multi\nline description!"
+	if VerifyBoardMeta(bm) {
+		t.Fatalf("VerifyBoardMeta accepted a tampered flex-canon/v2 payload")
+	}
+}
+
+func TestVerifyPost_CanonV2(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	p := &types.Post{
+		ThreadID:    "baf_thread",
+		DisplayName: "alice",
+		Body:        types.PostBody{Format: "markdown", Content: "line one\nline two"},
+		CreatedAt:   "2026-01-01T00:00:00Z",
+	}
+	if err := signature.SignPostCanonV2(priv, p); err != nil {
+		t.Fatalf("SignPostCanonV2: %v", err)
+	}
+	if !VerifyPost(p) {
+		t.Fatalf("VerifyPost failed for a flex-canon/v2 payload")
+	}
+}
+
+// TestKeyRotation_AcrossCanonicalizationVersions signs one record with each
+// of Version1, Version2 (JCS), and Version3 (flex-canon/v2) under the same
+// key, as would happen if an operator upgraded a node mid-stream without
+// re-signing its history, and checks every record still verifies and that
+// cross-version payload confusion doesn't let one version's signature
+// pass for another's.
+func TestKeyRotation_AcrossCanonicalizationVersions(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	v1 := &types.ThreadMeta{BoardID: "bbs.general", Title: "v1 thread", CreatedAt: "2026-01-01T00:00:00Z"}
+	if err := signature.SignThreadMeta(priv, v1); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+	v2 := &types.ThreadMeta{BoardID: "bbs.general", Title: "v2 thread", CreatedAt: "2026-01-01T00:01:00Z"}
+	if err := signature.SignThreadMetaJCS(priv, v2); err != nil {
+		t.Fatalf("SignThreadMetaJCS: %v", err)
+	}
+	v3 := &types.ThreadMeta{BoardID: "bbs.general", Title: "v3 thread", CreatedAt: "2026-01-01T00:02:00Z"}
+	if err := signature.SignThreadMetaCanonV2(priv, v3); err != nil {
+		t.Fatalf("SignThreadMetaCanonV2: %v", err)
+	}
+
+	for _, m := range []*types.ThreadMeta{v1, v2, v3} {
+		if !VerifyThreadMeta(m) {
+			t.Fatalf("VerifyThreadMeta failed for version %d", m.Version)
+		}
+	}
+
+	// v3's signature was computed over a different canonical encoding than
+	// v1's; splicing it onto v1's fields must not verify.
+	forged := *v1
+	forged.Signature = v3.Signature
+	if VerifyThreadMeta(&forged) {
+		t.Fatalf("VerifyThreadMeta accepted a Version3 signature on a Version1 payload")
+	}
+}
+
+func TestCanonicalAlgorithmByID(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := signature.CanonicalAlgorithmByID("flex-canon/v1"); !ok {
+		t.Fatalf("flex-canon/v1 not registered")
+	}
+	if _, ok := signature.CanonicalAlgorithmByID("flex-canon/v2"); !ok {
+		t.Fatalf("flex-canon/v2 not registered")
+	}
+	if _, ok := signature.CanonicalAlgorithmByID("flex-canon/v999"); ok {
+		t.Fatalf("unregistered algorithm ID unexpectedly found")
+	}
+}
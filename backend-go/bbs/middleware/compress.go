@@ -0,0 +1,216 @@
+// Package middleware holds small, reusable http.Handler wrappers shared
+// across bbs-node's HTTP servers.
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMinSize is CompressConfig.MinSize's default: responses smaller
+// than this pass through uncompressed rather than spending CPU on a gzip
+// stream that wouldn't shrink them meaningfully.
+const defaultMinSize = 1024
+
+// skipContentTypePrefixes names response Content-Types Compress never
+// re-compresses, either because they're already compressed (images) or
+// because compressing them saves little while adding CPU (already
+// negotiated by the client, e.g. video).
+var skipContentTypePrefixes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+}
+
+// CompressConfig configures Compress.
+type CompressConfig struct {
+	// MinSize is the response-body threshold below which Compress leaves
+	// a response uncompressed. Zero uses defaultMinSize.
+	MinSize int
+	// Level is passed to gzip.NewWriterLevel/flate.NewWriter; zero uses
+	// gzip.DefaultCompression.
+	Level int
+}
+
+func (c CompressConfig) withDefaults() CompressConfig {
+	if c.MinSize <= 0 {
+		c.MinSize = defaultMinSize
+	}
+	if c.Level == 0 {
+		c.Level = gzip.DefaultCompression
+	}
+	return c
+}
+
+// Compress wraps next with transparent gzip/deflate content-encoding: it
+// negotiates the client's Accept-Encoding, buffers the response body, and
+// only compresses once it's seen at least cfg.MinSize bytes and the
+// response's Content-Type isn't in skipContentTypePrefixes. A response
+// below the threshold is flushed through unmodified (identity encoding),
+// Content-Length included; a compressed one has Content-Length dropped
+// (the compressed size isn't known until the whole body is buffered and
+// written, and isn't worth buffering twice to compute) and Vary:
+// Accept-Encoding set either way, since the response does depend on the
+// request's Accept-Encoding even when a byte-for-byte pass-through.
+//
+// brotli ("br") is not offered: this module doesn't vendor a brotli
+// encoder, and gzip/deflate alone already cover every client this server
+// expects (flex-bbs-node peers and browsers both send Accept-Encoding:
+// gzip at minimum).
+func Compress(cfg CompressConfig, next http.Handler) http.Handler {
+	cfg = cfg.withDefaults()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		w.Header().Add("Vary", "Accept-Encoding")
+		if enc == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressingResponseWriter{ResponseWriter: w, cfg: cfg, encoding: enc}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip over deflate when a client offers both
+// (gzip is more widely optimized and what most flex-bbs clients send
+// first), returning "" when neither is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	gzipOK, deflateOK := false, false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip":
+			gzipOK = true
+		case "deflate":
+			deflateOK = true
+		}
+	}
+	switch {
+	case gzipOK:
+		return "gzip"
+	case deflateOK:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressingResponseWriter buffers a handler's output so Compress can
+// decide, once cfg.MinSize bytes have accumulated (or the handler
+// finishes, whichever comes first), whether to flush it compressed or as
+// plain identity bytes.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	cfg      CompressConfig
+	encoding string
+
+	wroteHeader bool
+	statusCode  int
+	buf         []byte
+	enc         io.WriteCloser // non-nil once compression has started
+}
+
+func (cw *compressingResponseWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.statusCode = status
+}
+
+func (cw *compressingResponseWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.statusCode = http.StatusOK
+		cw.wroteHeader = true
+	}
+
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if isCompressibleContentType(cw.ResponseWriter.Header().Get("Content-Type")) && len(cw.buf) >= cw.cfg.MinSize {
+		if err := cw.startCompressing(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// startCompressing flushes the response headers with Content-Encoding
+// set, drops any caller-set Content-Length (the compressed size isn't
+// known yet), and switches cw into streaming the rest of the body through
+// the chosen encoder.
+func (cw *compressingResponseWriter) startCompressing() error {
+	h := cw.ResponseWriter.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", cw.encoding)
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+
+	switch cw.encoding {
+	case "gzip":
+		gw, err := gzip.NewWriterLevel(cw.ResponseWriter, cw.cfg.Level)
+		if err != nil {
+			return err
+		}
+		cw.enc = gw
+	case "deflate":
+		fw, err := flate.NewWriter(cw.ResponseWriter, cw.cfg.Level)
+		if err != nil {
+			return err
+		}
+		cw.enc = fw
+	}
+
+	if len(cw.buf) > 0 {
+		buffered := cw.buf
+		cw.buf = nil
+		if _, err := cw.enc.Write(buffered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes whatever Write buffered without ever reaching cfg.MinSize
+// as plain identity bytes, or closes the active encoder, finalizing
+// whichever path Write took. Safe to call once per request; Compress
+// always calls it via defer.
+func (cw *compressingResponseWriter) Close() error {
+	if cw.enc != nil {
+		return cw.enc.Close()
+	}
+	if !cw.wroteHeader {
+		return nil
+	}
+	cw.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(len(cw.buf)))
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	_, err := cw.ResponseWriter.Write(cw.buf)
+	return err
+}
+
+func isCompressibleContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.TrimSpace(strings.ToLower(ct))
+	for _, prefix := range skipContentTypePrefixes {
+		if strings.HasPrefix(ct, prefix) {
+			return false
+		}
+	}
+	return true
+}
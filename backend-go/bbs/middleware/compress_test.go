@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func bigJSONBody(n int) string {
+	var sb strings.Builder
+	sb.WriteString(`{"items":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`"item-value-padding-to-make-this-response-large-enough"`)
+	}
+	sb.WriteString(`]}`)
+	return sb.String()
+}
+
+func jsonHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestCompress_NoAcceptEncodingPassesThroughIdentity(t *testing.T) {
+	body := bigJSONBody(200)
+	h := Compress(CompressConfig{}, jsonHandler(body))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected no Content-Encoding, got %q", enc)
+	}
+	if rec.Body.String() != body {
+		t.Fatalf("body mismatch for identity response")
+	}
+}
+
+func TestCompress_GzipClientGetsValidGzipStream(t *testing.T) {
+	body := bigJSONBody(200)
+	h := Compress(CompressConfig{}, jsonHandler(body))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("expected Content-Encoding: gzip, got %q", enc)
+	}
+	if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Fatalf("expected Vary: Accept-Encoding, got %q", vary)
+	}
+
+	gr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	decoded, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("read gzip stream: %v", err)
+	}
+	if string(decoded) != body {
+		t.Fatalf("decoded body mismatch")
+	}
+}
+
+func TestCompress_DeflateOnlyClient(t *testing.T) {
+	body := bigJSONBody(200)
+	h := Compress(CompressConfig{}, jsonHandler(body))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "deflate" {
+		t.Fatalf("expected Content-Encoding: deflate, got %q", enc)
+	}
+
+	fr := flate.NewReader(rec.Body)
+	decoded, err := io.ReadAll(fr)
+	if err != nil {
+		t.Fatalf("read deflate stream: %v", err)
+	}
+	if string(decoded) != body {
+		t.Fatalf("decoded body mismatch")
+	}
+}
+
+func TestCompress_DropsContentLengthWhenCompressed(t *testing.T) {
+	body := bigJSONBody(200)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "12345")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	})
+	wrapped := Compress(CompressConfig{}, h)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, req)
+
+	if cl := rec.Header().Get("Content-Length"); cl != "" {
+		t.Fatalf("expected Content-Length to be dropped on a compressed response, got %q", cl)
+	}
+}
+
+func TestCompress_SmallBodyPassesThroughUncompressed(t *testing.T) {
+	body := `{"ok":true}`
+	h := Compress(CompressConfig{MinSize: 1024}, jsonHandler(body))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected a small body to stay uncompressed, got Content-Encoding %q", enc)
+	}
+	if rec.Body.String() != body {
+		t.Fatalf("body mismatch: %q", rec.Body.String())
+	}
+	if cl := rec.Header().Get("Content-Length"); cl != "11" {
+		t.Fatalf("expected Content-Length to be set for an uncompressed response, got %q", cl)
+	}
+}
+
+func TestCompress_SkipsAlreadyCompressedContentType(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 4096)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	})
+	wrapped := Compress(CompressConfig{}, h)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected image/png to be skipped, got Content-Encoding %q", enc)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), body) {
+		t.Fatalf("body mismatch for skipped content type")
+	}
+}
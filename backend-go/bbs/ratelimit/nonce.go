@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore records nonces (in practice, a signed write's own signature
+// bytes, which are already unique per distinct payload+key) so a write
+// handler can reject a replay of a payload it has already accepted, not
+// just verify that the signature is valid.
+type NonceStore interface {
+	// CheckAndStore atomically checks whether nonce has been seen before
+	// and, if not, records it with expiresAt, returning seen=false; a
+	// nonce already stored and not yet expired returns seen=true. A
+	// caller that gets seen=true must treat the write as a replay and
+	// reject it. expiresAt bounds how long the store remembers the nonce:
+	// once it has passed, the same nonce is accepted again, so nonce
+	// reuse is only detectable within that window.
+	CheckAndStore(ctx context.Context, nonce string, expiresAt time.Time) (seen bool, err error)
+
+	// Close releases any resources the store holds (file handles, etc).
+	Close() error
+}
+
+// MemoryNonceStore is an in-process NonceStore backed by a map, suitable
+// for a single bbs-node instance or tests. Expired entries are swept
+// lazily: CheckAndStore drops any entry whose expiry has passed before
+// checking nonce itself, so the map never grows past roughly the number of
+// distinct writes seen within the longest expiresAt window in use.
+type MemoryNonceStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryNonceStore returns an empty MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) CheckAndStore(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for n, exp := range s.entries {
+		if now.After(exp) {
+			delete(s.entries, n)
+		}
+	}
+
+	if exp, ok := s.entries[nonce]; ok && now.Before(exp) {
+		return true, nil
+	}
+	s.entries[nonce] = expiresAt
+	return false, nil
+}
+
+func (s *MemoryNonceStore) Close() error { return nil }
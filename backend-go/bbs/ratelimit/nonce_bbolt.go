@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// noncesBucket is the single bbolt bucket BboltNonceStore keeps all nonces
+// in, keyed by the nonce string with its expiry (big-endian Unix nanos) as
+// the value.
+var noncesBucket = []byte("nonces")
+
+// BboltNonceStore is a NonceStore backed by a bbolt database file, for a
+// bbs-node deployment where replay protection must survive a restart
+// (MemoryNonceStore's map does not).
+type BboltNonceStore struct {
+	db *bbolt.DB
+}
+
+// OpenBboltNonceStore opens (creating if necessary) a bbolt-backed
+// NonceStore at path.
+func OpenBboltNonceStore(path string) (*BboltNonceStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: open nonce db %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(noncesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ratelimit: init nonce db %s: %w", path, err)
+	}
+	return &BboltNonceStore{db: db}, nil
+}
+
+func (s *BboltNonceStore) CheckAndStore(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	var seen bool
+	now := time.Now()
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(noncesBucket)
+
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if decodeNonceExpiry(v).Before(now) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if v := b.Get([]byte(nonce)); v != nil && !decodeNonceExpiry(v).Before(now) {
+			seen = true
+			return nil
+		}
+		return b.Put([]byte(nonce), encodeNonceExpiry(expiresAt))
+	})
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: check nonce: %w", err)
+	}
+	return seen, nil
+}
+
+func (s *BboltNonceStore) Close() error {
+	return s.db.Close()
+}
+
+func encodeNonceExpiry(t time.Time) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(t.UnixNano()))
+	return buf
+}
+
+func decodeNonceExpiry(b []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
@@ -0,0 +1,91 @@
+// Package ratelimit provides the write-path defenses cmd/bbs-node's post
+// handlers (handleCreatePost, handleEditPost, handlePostActions' tombstone
+// branch) apply on top of verifying the Ed25519 signature on a write:
+// bounding how often a given signer (or IP) may write, and noticing the
+// exact same signed payload submitted twice. Limiter covers the former
+// with a token bucket per key; NonceStore (nonce.go) covers the latter.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Config controls a Limiter's token bucket: WritesPerMinute is the
+// sustained refill rate and BurstSize is the bucket capacity, i.e. how
+// many writes a key may make back-to-back before it's throttled down to
+// the sustained rate.
+type Config struct {
+	WritesPerMinute int
+	BurstSize       int
+}
+
+// bucket is a single key's token bucket, lazily refilled on Allow rather
+// than on a ticker, so an idle Limiter costs nothing between calls.
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Limiter is a keyed token-bucket rate limiter: each distinct key (an
+// authorPubKey, a client IP, ...) gets its own independent bucket sized by
+// Config. It is safe for concurrent use.
+type Limiter struct {
+	cfg Config
+
+	ratePerSecond float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New returns a Limiter enforcing cfg. A zero or negative WritesPerMinute
+// or BurstSize disables limiting entirely (Allow always reports true),
+// which lets callers wire a Limiter unconditionally and gate it on config
+// rather than on a nil check.
+func New(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:           cfg,
+		ratePerSecond: float64(cfg.WritesPerMinute) / 60,
+		buckets:       make(map[string]*bucket),
+	}
+}
+
+// Allow reports whether a write for key may proceed right now, consuming
+// one token if so. Distinct keys never affect each other's bucket.
+func (l *Limiter) Allow(key string) bool {
+	if l.cfg.WritesPerMinute <= 0 || l.cfg.BurstSize <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.BurstSize), lastRefill: now}
+		l.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.lastRefill).Seconds()
+		b.tokens += elapsed * l.ratePerSecond
+		if max := float64(l.cfg.BurstSize); b.tokens > max {
+			b.tokens = max
+		}
+		b.lastRefill = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Reset drops key's bucket, so its next Allow call starts fresh at full
+// burst capacity. Tests use this; production code has no need to.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllowsBurstThenThrottles(t *testing.T) {
+	l := New(Config{WritesPerMinute: 60, BurstSize: 3})
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow("alice") {
+			t.Fatalf("call %d: expected allow within burst", i)
+		}
+	}
+	if l.Allow("alice") {
+		t.Fatal("expected 4th call to be throttled")
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	l := New(Config{WritesPerMinute: 60, BurstSize: 1})
+
+	if !l.Allow("alice") {
+		t.Fatal("expected alice's first call to be allowed")
+	}
+	if !l.Allow("bob") {
+		t.Fatal("expected bob's first call to be allowed, independent of alice's bucket")
+	}
+	if l.Allow("alice") {
+		t.Fatal("expected alice's second call to be throttled")
+	}
+}
+
+func TestLimiterDisabledWhenUnconfigured(t *testing.T) {
+	l := New(Config{})
+	for i := 0; i < 100; i++ {
+		if !l.Allow("anyone") {
+			t.Fatalf("call %d: expected unconfigured Limiter to always allow", i)
+		}
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	l := New(Config{WritesPerMinute: 60, BurstSize: 1})
+	if !l.Allow("alice") {
+		t.Fatal("expected first call to be allowed")
+	}
+	l.Reset("alice")
+	if !l.Allow("alice") {
+		t.Fatal("expected call after Reset to be allowed again")
+	}
+}
+
+func TestMemoryNonceStoreRejectsReplay(t *testing.T) {
+	s := NewMemoryNonceStore()
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour)
+
+	seen, err := s.CheckAndStore(ctx, "sig-1", exp)
+	if err != nil {
+		t.Fatalf("CheckAndStore: %v", err)
+	}
+	if seen {
+		t.Fatal("expected first use of nonce to report seen=false")
+	}
+
+	seen, err = s.CheckAndStore(ctx, "sig-1", exp)
+	if err != nil {
+		t.Fatalf("CheckAndStore: %v", err)
+	}
+	if !seen {
+		t.Fatal("expected replayed nonce to report seen=true")
+	}
+}
+
+func TestMemoryNonceStoreExpires(t *testing.T) {
+	s := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	seen, err := s.CheckAndStore(ctx, "sig-1", time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatalf("CheckAndStore: %v", err)
+	}
+	if seen {
+		t.Fatal("expected first use to report seen=false")
+	}
+
+	seen, err = s.CheckAndStore(ctx, "sig-1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CheckAndStore: %v", err)
+	}
+	if seen {
+		t.Fatal("expected an already-expired nonce to be reusable")
+	}
+}
@@ -0,0 +1,224 @@
+// Package republisher periodically re-pins and re-provides every CID a
+// node's boards still reference (and, for archiver/full roles, every CID
+// bbs/archive has written to disk) through the flex-ipfs API, so long-lived
+// content stays advertised in the DHT instead of silently drifting out of
+// reach once no one's queried it in a while. It's modeled on boxo's
+// namesys/republisher: same "periodically re-announce what we already
+// have" shape, scaled down to flex-bbs's own content types via
+// storage.Storage.WalkBoard and bbs/archive.ArchivedCIDs instead of IPNS
+// records.
+package republisher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"flex-bbs/backend-go/bbs/archive"
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/storage"
+)
+
+// Strategy selects which CIDs a run republishes.
+type Strategy string
+
+const (
+	// StrategyPinned walks every board in Boards via storage.WalkBoard —
+	// the BoardMeta, BoardLogEntry chain, and every ThreadMeta/Post CID a
+	// board currently references.
+	StrategyPinned Strategy = "pinned"
+	// StrategyArchived walks every CID bbs/archive has written to
+	// ArchiveDir (see archive.ArchivedCIDs). Only meaningful alongside an
+	// archiver/full role, since only those roles run an Archiver at all.
+	StrategyArchived Strategy = "archived"
+	// StrategyAll does both.
+	StrategyAll Strategy = "all"
+)
+
+// DefaultInterval is how often cmd/bbs-node's republish loop re-runs a
+// Republisher by default (--republish-interval), matching boxo's
+// namesys/republisher's own "once every 12h is enough" assumption for
+// reachability refresh.
+const DefaultInterval = 12 * time.Hour
+
+// DefaultConcurrency bounds how many Pin/Provide calls a RunOnce has in
+// flight at once when Concurrency is unset, matching
+// bbs/log.DefaultPrefetchConcurrency's choice of worker count for a
+// similar "many independent small flex-ipfs calls" workload.
+const DefaultConcurrency = 16
+
+// Metrics is a snapshot of a Republisher's most recent RunOnce, served by
+// api.Server's GET /api/v1/republisher/status.
+type Metrics struct {
+	LastRunAt       time.Time     `json:"lastRunAt"`
+	// LastRunDuration marshals as its integer nanosecond count, same as
+	// any other time.Duration passed through encoding/json with no custom
+	// marshaler.
+	LastRunDuration time.Duration `json:"lastRunDurationNs"`
+	CIDsRepublished int           `json:"cidsRepublished"`
+	Failures        int           `json:"failures"`
+	LastError       string        `json:"lastError,omitempty"`
+}
+
+// Republisher holds everything one RunOnce needs: where to find CIDs
+// (Storage/Boards for StrategyPinned, ArchiveDir for StrategyArchived) and
+// where to send Pin/Provide calls (Flex).
+type Republisher struct {
+	Storage *storage.Storage
+	Boards  *config.BoardsStore
+	Flex    *flexipfs.Client
+
+	// ArchiveDir is bbs/archive.Archiver's output directory; required for
+	// StrategyArchived/StrategyAll, ignored otherwise.
+	ArchiveDir string
+
+	Strategy    Strategy
+	Concurrency int
+
+	mu      sync.Mutex
+	metrics Metrics
+}
+
+// Status returns a snapshot of the most recently completed RunOnce. The
+// zero Metrics (LastRunAt.IsZero()) means no run has completed yet.
+func (r *Republisher) Status() Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.metrics
+}
+
+// RunOnce collects every CID r's strategy selects, re-pins and
+// re-provides each through Flex (bounded to Concurrency at once), and
+// records the result in Status. A per-CID Pin/Provide failure is counted
+// but doesn't stop the run — one unreachable or GC'd block shouldn't block
+// republishing the rest. It returns the error (if any) from collecting the
+// CID list itself, since that failure means the run may have covered only
+// part of what it should have.
+func (r *Republisher) RunOnce(ctx context.Context) error {
+	start := time.Now()
+	cids, collectErr := r.collectCIDs(ctx)
+
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var republished, failures int64
+	var lastErrMu sync.Mutex
+	var lastErr error
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, cid := range cids {
+		cid := cid
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := r.republishOne(ctx, cid); err != nil {
+				atomic.AddInt64(&failures, 1)
+				lastErrMu.Lock()
+				lastErr = err
+				lastErrMu.Unlock()
+				return
+			}
+			atomic.AddInt64(&republished, 1)
+		}()
+	}
+	wg.Wait()
+
+	m := Metrics{
+		LastRunAt:       start,
+		LastRunDuration: time.Since(start),
+		CIDsRepublished: int(republished),
+		Failures:        int(failures),
+	}
+	if lastErr != nil {
+		m.LastError = lastErr.Error()
+	} else if collectErr != nil {
+		m.LastError = collectErr.Error()
+	}
+
+	r.mu.Lock()
+	r.metrics = m
+	r.mu.Unlock()
+
+	return collectErr
+}
+
+// republishOne re-pins then re-provides cid, so a failed Provide still
+// leaves the content pinned locally (pinning is the more important of the
+// two: it's what stops this node's own GC, where re-providing only helps
+// neighbors' routing tables).
+func (r *Republisher) republishOne(ctx context.Context, cid string) error {
+	if err := r.Flex.Pin(ctx, cid); err != nil {
+		return fmt.Errorf("republisher: pin %s: %w", cid, err)
+	}
+	if err := r.Flex.Provide(ctx, cid); err != nil {
+		return fmt.Errorf("republisher: provide %s: %w", cid, err)
+	}
+	return nil
+}
+
+// collectCIDs gathers the deduplicated set of CIDs r's strategy selects.
+// A failed board walk or archive directory read is recorded as the first
+// error encountered but doesn't stop collection of the rest — a board
+// with a broken log chain shouldn't prevent republishing every other
+// board.
+func (r *Republisher) collectCIDs(ctx context.Context) ([]string, error) {
+	strategy := r.Strategy
+	if strategy == "" {
+		strategy = StrategyPinned
+	}
+
+	seen := make(map[string]struct{})
+	var cids []string
+	add := func(cid string) {
+		if cid == "" {
+			return
+		}
+		if _, ok := seen[cid]; ok {
+			return
+		}
+		seen[cid] = struct{}{}
+		cids = append(cids, cid)
+	}
+
+	var firstErr error
+	noteErr := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if strategy == StrategyPinned || strategy == StrategyAll {
+		if err := r.Boards.Load(); err != nil {
+			noteErr(fmt.Errorf("republisher: load boards: %w", err))
+		}
+		for _, ref := range r.Boards.List() {
+			err := r.Storage.WalkBoard(ctx, ref.BoardMetaCID, func(cid string, _ storage.WalkedKind) error {
+				add(cid)
+				return ctx.Err()
+			})
+			if err != nil {
+				noteErr(fmt.Errorf("republisher: walk board %s: %w", ref.BoardID, err))
+			}
+		}
+	}
+
+	if strategy == StrategyArchived || strategy == StrategyAll {
+		archived, err := archive.ArchivedCIDs(r.ArchiveDir)
+		if err != nil {
+			noteErr(fmt.Errorf("republisher: list archived cids: %w", err))
+		}
+		for _, cid := range archived {
+			add(cid)
+		}
+	}
+
+	return cids, firstErr
+}
@@ -0,0 +1,282 @@
+package republisher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/storage"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// newFakeFlex starts a content-addressed fake Flexible-IPFS server (the
+// same shape as storage's own export_test.go fake) plus a record of every
+// Pin/Provide call it receives, so tests can assert on what a run actually
+// tried to republish.
+type fakeFlex struct {
+	mu      sync.Mutex
+	byCID   map[string]string
+	pinned  map[string]int
+	provide map[string]int
+	failPin map[string]bool
+}
+
+func newFakeFlex(t *testing.T) (*storage.Storage, *fakeFlex) {
+	t.Helper()
+	f := &fakeFlex{
+		byCID:   map[string]string{},
+		pinned:  map[string]int{},
+		provide: map[string]int{},
+		failPin: map[string]bool{},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_ = json.NewEncoder(w).Encode("peer1")
+		case "/api/v0/dht/putvaluewithattr":
+			value := r.URL.Query().Get("value")
+			sum := sha256.Sum256([]byte(value))
+			cid := "baf_" + hex.EncodeToString(sum[:8])
+			f.mu.Lock()
+			f.byCID[cid] = value
+			f.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": cid})
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			f.mu.Lock()
+			value, ok := f.byCID[cid]
+			f.mu.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(value)
+		case "/api/v0/dht/pin":
+			cid := r.URL.Query().Get("cid")
+			f.mu.Lock()
+			f.pinned[cid]++
+			fail := f.failPin[cid]
+			f.mu.Unlock()
+			if fail {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/api/v0/dht/provide":
+			cid := r.URL.Query().Get("cid")
+			f.mu.Lock()
+			f.provide[cid]++
+			f.mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return storage.New(flexipfs.New(srv.URL + "/api/v0")), f
+}
+
+// seedBoard builds and saves a minimal but fully signed board, mirroring
+// storage's own export_test.go seedBoard (unexported there, so this test
+// builds its own rather than reaching across a package boundary).
+func seedBoard(t *testing.T, st *storage.Storage, boardID string) string {
+	t.Helper()
+	ctx := context.Background()
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	post := &types.Post{
+		Version:      types.Version1,
+		Type:         types.TypePost,
+		ThreadID:     "placeholder",
+		AuthorPubKey: "author1",
+		DisplayName:  "Author",
+		Body:         types.PostBody{Format: "plain", Content: "hello board"},
+		CreatedAt:    "2025-01-01T00:00:00Z",
+	}
+	if err := signature.SignPost(priv, post); err != nil {
+		t.Fatalf("SignPost: %v", err)
+	}
+	postCID, err := st.SavePost(ctx, boardID, post)
+	if err != nil {
+		t.Fatalf("SavePost: %v", err)
+	}
+
+	thread := &types.ThreadMeta{
+		Version:     types.Version1,
+		Type:        types.TypeThreadMeta,
+		BoardID:     boardID,
+		Title:       "Hello",
+		RootPostCID: postCID,
+		CreatedAt:   "2025-01-01T00:00:00Z",
+		CreatedBy:   "author1",
+		Meta:        map[string]any{},
+	}
+	if err := signature.SignThreadMeta(priv, thread); err != nil {
+		t.Fatalf("SignThreadMeta: %v", err)
+	}
+	threadCID, err := st.SaveThreadMeta(ctx, thread)
+	if err != nil {
+		t.Fatalf("SaveThreadMeta: %v", err)
+	}
+
+	entry := &types.BoardLogEntry{
+		Version:      types.Version1,
+		Type:         types.TypeBoardLogEntry,
+		BoardID:      boardID,
+		Op:           types.OpCreateThread,
+		ThreadID:     threadCID,
+		PostCID:      &postCID,
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: "author1",
+	}
+	if err := signature.SignBoardLogEntry(priv, entry); err != nil {
+		t.Fatalf("SignBoardLogEntry: %v", err)
+	}
+	logCID, err := st.SaveBoardLogEntry(ctx, entry)
+	if err != nil {
+		t.Fatalf("SaveBoardLogEntry: %v", err)
+	}
+
+	bm := &types.BoardMeta{
+		Version:    types.Version1,
+		Type:       types.TypeBoardMeta,
+		BoardID:    boardID,
+		Title:      "Republisher Test",
+		CreatedAt:  "2025-01-01T00:00:00Z",
+		CreatedBy:  "author1",
+		LogHeadCID: &logCID,
+	}
+	if err := signature.SignBoardMeta(priv, bm); err != nil {
+		t.Fatalf("SignBoardMeta: %v", err)
+	}
+	bmCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+	return bmCID
+}
+
+func newBoardsStore(t *testing.T, boardID, boardMetaCID string) *config.BoardsStore {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "boards.json")
+	f := config.BoardsFile{Boards: []config.BoardRef{{BoardID: boardID, BoardMetaCID: boardMetaCID}}}
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal boards file: %v", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("write boards file: %v", err)
+	}
+	s := config.NewBoardsStore(path)
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return s
+}
+
+func TestRunOnce_PinnedStrategyRepublishesEveryWalkedCID(t *testing.T) {
+	ctx := context.Background()
+	st, fake := newFakeFlex(t)
+	bmCID := seedBoard(t, st, "bbs.republish-test")
+	boards := newBoardsStore(t, "bbs.republish-test", bmCID)
+
+	r := &Republisher{Storage: st, Boards: boards, Flex: st.Flex, Strategy: StrategyPinned}
+	if err := r.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	status := r.Status()
+	if status.CIDsRepublished != 4 {
+		t.Fatalf("CIDsRepublished = %d, want 4 (board meta, log entry, thread meta, post)", status.CIDsRepublished)
+	}
+	if status.Failures != 0 {
+		t.Fatalf("Failures = %d, want 0", status.Failures)
+	}
+	if status.LastRunAt.IsZero() {
+		t.Fatal("expected LastRunAt to be set")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.pinned[bmCID] != 1 {
+		t.Fatalf("expected the board meta CID to be pinned once, got %d", fake.pinned[bmCID])
+	}
+	if fake.provide[bmCID] != 1 {
+		t.Fatalf("expected the board meta CID to be provided once, got %d", fake.provide[bmCID])
+	}
+}
+
+func TestRunOnce_RecordsPerCIDFailuresWithoutAbortingTheRun(t *testing.T) {
+	ctx := context.Background()
+	st, fake := newFakeFlex(t)
+	bmCID := seedBoard(t, st, "bbs.republish-fail-test")
+	boards := newBoardsStore(t, "bbs.republish-fail-test", bmCID)
+
+	fake.mu.Lock()
+	fake.failPin[bmCID] = true
+	fake.mu.Unlock()
+
+	r := &Republisher{Storage: st, Boards: boards, Flex: st.Flex, Strategy: StrategyPinned}
+	if err := r.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	status := r.Status()
+	if status.Failures != 1 {
+		t.Fatalf("Failures = %d, want 1", status.Failures)
+	}
+	if status.CIDsRepublished != 3 {
+		t.Fatalf("CIDsRepublished = %d, want 3 (the other 3 CIDs still succeed)", status.CIDsRepublished)
+	}
+	if status.LastError == "" {
+		t.Fatal("expected LastError to be set")
+	}
+}
+
+func TestRunOnce_ArchivedStrategyWalksArchiveDirInsteadOfBoards(t *testing.T) {
+	ctx := context.Background()
+	st, fake := newFakeFlex(t)
+
+	archiveDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(archiveDir, "baf_archived1.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write archived fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(archiveDir, "baf_archived2.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write archived fixture: %v", err)
+	}
+
+	boards := newBoardsStore(t, "bbs.unused", "baf_unused")
+
+	r := &Republisher{Storage: st, Boards: boards, Flex: st.Flex, ArchiveDir: archiveDir, Strategy: StrategyArchived}
+	if err := r.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	status := r.Status()
+	if status.CIDsRepublished != 2 {
+		t.Fatalf("CIDsRepublished = %d, want 2", status.CIDsRepublished)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.pinned["baf_archived1"] != 1 || fake.pinned["baf_archived2"] != 1 {
+		t.Fatalf("expected both archived CIDs to be pinned: %+v", fake.pinned)
+	}
+	if fake.pinned["baf_unused"] != 0 {
+		t.Fatal("StrategyArchived should not walk boards.json at all")
+	}
+}
@@ -0,0 +1,110 @@
+// Package reqlog is a minimal structured logger for per-request API
+// diagnostics. It exists alongside the standard "log" package (still used
+// for server-lifecycle and background messages) because handlers that want
+// machine-parseable output — req_id, route, board_id, elapsed_ms and the
+// like, one JSON object per line — need a shape log.Printf can't give them
+// without ad hoc Sprintf formatting at every call site.
+package reqlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Field is one structured logging key/value pair.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F builds a Field. Value is marshaled with encoding/json, so it may be any
+// JSON-representable type.
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// sink is the shared, mutex-guarded writer behind a Logger and every
+// Logger derived from it via With, so concurrent requests logging through
+// the same underlying io.Writer never interleave partial JSON lines.
+type sink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (s *sink) write(line []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.out.Write(line)
+}
+
+// Logger writes one JSON object per line to an underlying io.Writer. The
+// zero value is not usable; construct one with New, or pull the
+// request-scoped one out of a handler's context with FromContext.
+type Logger struct {
+	sink   *sink
+	fields []Field
+}
+
+// New returns a Logger writing to out. A nil out defaults to os.Stderr.
+func New(out io.Writer) *Logger {
+	if out == nil {
+		out = os.Stderr
+	}
+	return &Logger{sink: &sink{out: out}}
+}
+
+// With returns a Logger that prefixes every future line with fields, in
+// addition to whatever fields l already carries. It doesn't mutate l, so a
+// base logger can be reused to derive loggers for several requests.
+func (l *Logger) With(fields ...Field) *Logger {
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &Logger{sink: l.sink, fields: merged}
+}
+
+// Info writes one JSON log line at level "info": a "time" (RFC3339Nano),
+// "level", "msg", then l's carried fields followed by fields, later keys
+// overwriting earlier ones of the same name. Marshal failures are dropped
+// rather than surfaced — logging must never be what breaks a request.
+func (l *Logger) Info(msg string, fields ...Field) {
+	entry := make(map[string]any, len(l.fields)+len(fields)+3)
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = "info"
+	entry["msg"] = msg
+	for _, f := range l.fields {
+		entry[f.Key] = f.Value
+	}
+	for _, f := range fields {
+		entry[f.Key] = f.Value
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.sink.write(append(line, '\n'))
+}
+
+type contextKey struct{}
+
+var defaultLogger = New(os.Stderr)
+
+// NewContext returns a copy of ctx carrying l, retrievable with FromContext.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Logger attached to ctx by NewContext, or a
+// default Logger writing to os.Stderr with no carried fields if ctx has
+// none — callers never need to nil-check the result.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return defaultLogger
+}
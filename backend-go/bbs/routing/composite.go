@@ -0,0 +1,96 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CompositeRouter fans a query out across every Router it wraps and
+// merges the results, deduping by (BoardID, Role, BaseURL). This is what
+// cmd/bbs-node actually wires up: MDNSRouter for LAN-local discovery
+// alongside HTTPDelegatedRouter for peer-to-peer discovery, queried
+// together as one Router.
+type CompositeRouter struct {
+	Routers []Router
+}
+
+// NewCompositeRouter builds a CompositeRouter over the given Routers.
+// Find queries run against all of them concurrently, so merged results
+// come back in completion order; Provide calls them in the order given.
+func NewCompositeRouter(routers ...Router) *CompositeRouter {
+	return &CompositeRouter{Routers: routers}
+}
+
+func dedupeRecords(records []ProviderRecord) []ProviderRecord {
+	seen := make(map[ProviderRecord]bool, len(records))
+	out := make([]ProviderRecord, 0, len(records))
+	for _, r := range records {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// fanOut runs query against every sub-router concurrently and merges
+// their results. A sub-router's error is dropped (best effort — one
+// unreachable or misconfigured Router shouldn't blank out whatever the
+// others found), unless every sub-router fails, in which case the last
+// error seen is returned alongside the (empty) result.
+func (c *CompositeRouter) fanOut(query func(Router) ([]ProviderRecord, error)) ([]ProviderRecord, error) {
+	var (
+		mu      sync.Mutex
+		all     []ProviderRecord
+		lastErr error
+		failed  int
+	)
+	var wg sync.WaitGroup
+	for _, r := range c.Routers {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			records, err := query(r)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				failed++
+				return
+			}
+			all = append(all, records...)
+		}()
+	}
+	wg.Wait()
+
+	if len(c.Routers) > 0 && failed == len(c.Routers) {
+		return nil, lastErr
+	}
+	return dedupeRecords(all), nil
+}
+
+func (c *CompositeRouter) FindIndexers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return c.fanOut(func(r Router) ([]ProviderRecord, error) { return r.FindIndexers(ctx, boardID) })
+}
+
+func (c *CompositeRouter) FindArchivers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return c.fanOut(func(r Router) ([]ProviderRecord, error) { return r.FindArchivers(ctx, boardID) })
+}
+
+// Provide calls Provide on every sub-router, so a future Router that can
+// act on it (unlike MDNSRouter/HTTPDelegatedRouter's current no-ops)
+// picks it up automatically. It returns a joined error of every
+// sub-router's failure rather than stopping at the first one, since
+// they're independent announcements.
+func (c *CompositeRouter) Provide(ctx context.Context, boardID, role string) error {
+	var errs []error
+	for _, r := range c.Routers {
+		if err := r.Provide(ctx, boardID, role); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+package routing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpDelegatedRequestTimeout bounds each peer query in
+// HTTPDelegatedRouter.FindIndexers/FindArchivers, so one unreachable
+// endpoint can't stall discovery against every other one.
+const httpDelegatedRequestTimeout = 5 * time.Second
+
+// providerWire is the NDJSON line shape api.Server's
+// GET /routing/v1/providers/{boardId} handler emits (see
+// bbs/api/routing.go's providerRecord). Declared independently here
+// rather than importing bbs/api, since only cmd/bbs-node needs both
+// packages and bbs/routing has no other reason to depend on bbs/api.
+type providerWire struct {
+	BoardID string `json:"boardId"`
+	Role    string `json:"role"`
+}
+
+// HTTPDelegatedRouter queries known peers' GET
+// /routing/v1/providers/{boardId} endpoints and accepts their
+// NDJSON-streamed provider records, following the same request/response
+// shape boxo's delegated-routing HTTP client uses against
+// /routing/v1/providers/{cid}.
+type HTTPDelegatedRouter struct {
+	// Endpoints returns the bbs-node base URLs to query, evaluated fresh
+	// on every Find call so it can be backed by something that changes
+	// over time (e.g. config.TrustedIndexersStore.List).
+	Endpoints func() []string
+	// Client is the HTTP client used for requests. Defaults to an
+	// httpDelegatedRequestTimeout-timeout client when nil.
+	Client *http.Client
+}
+
+// NewHTTPDelegatedRouter builds an HTTPDelegatedRouter over the given
+// endpoints source (see Endpoints).
+func NewHTTPDelegatedRouter(endpoints func() []string) *HTTPDelegatedRouter {
+	return &HTTPDelegatedRouter{Endpoints: endpoints}
+}
+
+func (h *HTTPDelegatedRouter) client() *http.Client {
+	if h.Client != nil {
+		return h.Client
+	}
+	return &http.Client{Timeout: httpDelegatedRequestTimeout}
+}
+
+func (h *HTTPDelegatedRouter) findByRole(ctx context.Context, boardID, wantRole string) ([]ProviderRecord, error) {
+	if h.Endpoints == nil {
+		return nil, nil
+	}
+	endpoints := h.Endpoints()
+
+	var out []ProviderRecord
+	for _, baseURL := range endpoints {
+		records, err := h.queryPeer(ctx, baseURL, boardID)
+		if err != nil {
+			log.Printf("routing: query %s for board %s providers: %v", baseURL, boardID, err)
+			continue
+		}
+		for _, r := range records {
+			if r.Role != wantRole {
+				continue
+			}
+			out = append(out, ProviderRecord{BoardID: boardID, Role: wantRole, BaseURL: baseURL})
+		}
+	}
+	return out, nil
+}
+
+func (h *HTTPDelegatedRouter) queryPeer(ctx context.Context, baseURL, boardID string) ([]providerWire, error) {
+	ctx, cancel := context.WithTimeout(ctx, httpDelegatedRequestTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimRight(baseURL, "/") + "/routing/v1/providers/" + url.PathEscape(boardID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var records []providerWire
+	sc := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var rec providerWire
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+func (h *HTTPDelegatedRouter) FindIndexers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return h.findByRole(ctx, boardID, "indexer")
+}
+
+func (h *HTTPDelegatedRouter) FindArchivers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return h.findByRole(ctx, boardID, "archiver")
+}
+
+// Provide is a no-op: this router has no peer designated to push a
+// provide-announcement to, and api.Server's /routing/v1/providers
+// endpoint reports a node's capability by reflecting its own
+// Boards/Indexer config rather than accepting pushed records (see
+// bbs/api/routing.go). A future router backed by a write-capable
+// announce endpoint could implement this meaningfully.
+func (h *HTTPDelegatedRouter) Provide(ctx context.Context, boardID, role string) error {
+	return nil
+}
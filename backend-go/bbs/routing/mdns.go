@@ -0,0 +1,165 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultMDNSBBSNodePort is the HTTP port a bbs-node colocated with a
+// discovered flex-ipfs gw is assumed to listen on, matching the
+// -http default in cmd/bbs-node.
+const defaultMDNSBBSNodePort = 8080
+
+// mdnsProbeTimeout bounds each candidate's /healthz probe, so one
+// unreachable host can't stall FindIndexers/FindArchivers.
+const mdnsProbeTimeout = 2 * time.Second
+
+// MDNSRouter reports bbs-node peers discovered via LAN presence rather
+// than a real announcement: any host whose flex-ipfs gw was seen over
+// mDNS (Endpoints, populated by cmd/bbs-node's existing
+// discoverFlexIPFSGWEndpoint(s)Mdns) is assumed to also run a bbs-node on
+// Port, and is probed via /healthz to confirm its role before being
+// reported as a provider. This is exactly the heuristic
+// maybeTrustIndexerFromFlexIPFSGWMdns used to apply directly against a
+// config.TrustedIndexersStore, reshaped behind the Router interface.
+//
+// bbs/routing intentionally doesn't depend on the mDNS library itself
+// (github.com/grandcat/zeroconf): cmd/bbs-node already owns LAN discovery
+// for the flex-ipfs gw's own sake, so MDNSRouter just takes whatever
+// endpoints that discovery already found and turns them into candidate
+// bbs-node peers.
+type MDNSRouter struct {
+	// Endpoints are flex-ipfs gw multiaddr-ish strings as returned by
+	// cmd/bbs-node's discoverFlexIPFSGWEndpoint(s)Mdns (e.g.
+	// "/ip4/10.0.0.5/tcp/4001/..."). Only the /ip4/ host is used.
+	Endpoints []string
+	// Port overrides defaultMDNSBBSNodePort when non-zero.
+	Port int
+	// ProbeRole reports the role a bbs-node at baseURL self-reports via
+	// /healthz. Defaults to httpProbeRole when nil.
+	ProbeRole func(ctx context.Context, baseURL string) (string, error)
+}
+
+// NewMDNSRouter builds an MDNSRouter over the given flex-ipfs gw
+// endpoints (see Endpoints).
+func NewMDNSRouter(endpoints []string) *MDNSRouter {
+	return &MDNSRouter{Endpoints: endpoints}
+}
+
+func (m *MDNSRouter) port() int {
+	if m.Port > 0 {
+		return m.Port
+	}
+	return defaultMDNSBBSNodePort
+}
+
+func (m *MDNSRouter) probeRole(ctx context.Context, baseURL string) (string, error) {
+	if m.ProbeRole != nil {
+		return m.ProbeRole(ctx, baseURL)
+	}
+	return httpProbeRole(ctx, baseURL)
+}
+
+// findByRole is shared by FindIndexers/FindArchivers: it probes every
+// candidate baseURL built from m.Endpoints and keeps the ones whose
+// self-reported role matches wantRole or "full". boardID is accepted for
+// Router-interface symmetry only — this heuristic has no way to scope a
+// candidate to a specific board, it can only tell you "this LAN host
+// claims to be an indexer/archiver at all".
+func (m *MDNSRouter) findByRole(ctx context.Context, boardID, wantRole string) ([]ProviderRecord, error) {
+	var out []ProviderRecord
+	seen := make(map[string]bool)
+	for _, ep := range m.Endpoints {
+		ip := extractIP4FromMultiaddr(ep)
+		if ip == "" {
+			continue
+		}
+		baseURL := fmt.Sprintf("http://%s:%d", ip, m.port())
+		if seen[baseURL] {
+			continue
+		}
+		seen[baseURL] = true
+
+		role, err := m.probeRole(ctx, baseURL)
+		if err != nil {
+			continue
+		}
+		if role != wantRole && role != "full" {
+			continue
+		}
+		out = append(out, ProviderRecord{BoardID: boardID, Role: wantRole, BaseURL: baseURL})
+	}
+	return out, nil
+}
+
+func (m *MDNSRouter) FindIndexers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return m.findByRole(ctx, boardID, "indexer")
+}
+
+func (m *MDNSRouter) FindArchivers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return m.findByRole(ctx, boardID, "archiver")
+}
+
+// Provide is a no-op: LAN presence isn't something this node can announce
+// per-board, only cmd/bbs-node's mDNS advertise (which runs regardless of
+// routing.Router) makes it discoverable at all.
+func (m *MDNSRouter) Provide(ctx context.Context, boardID, role string) error {
+	return nil
+}
+
+// httpProbeRole is the default MDNSRouter.ProbeRole: it parses the "ok
+// role=<role>" plain-text body /healthz returns (see api.Server.healthz).
+func httpProbeRole(ctx context.Context, baseURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, mdnsProbeTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/healthz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := (&http.Client{Timeout: mdnsProbeTimeout}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	buf := make([]byte, 256)
+	n, _ := resp.Body.Read(buf)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("healthz http %d", resp.StatusCode)
+	}
+
+	s := strings.TrimSpace(string(buf[:n]))
+	const prefix = "ok role="
+	if !strings.HasPrefix(s, prefix) {
+		return "", fmt.Errorf("unexpected healthz response: %q", s)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(s, prefix)), nil
+}
+
+// extractIP4FromMultiaddr mirrors cmd/bbs-node's unexported helper of the
+// same name: it pulls the dotted-quad host out of an "/ip4/<ip>/..."
+// multiaddr-ish string, the shape discoverFlexIPFSGWEndpoint(s)Mdns
+// returns.
+func extractIP4FromMultiaddr(addr string) string {
+	const prefix = "/ip4/"
+	i := strings.Index(addr, prefix)
+	if i < 0 {
+		return ""
+	}
+	rest := addr[i+len(prefix):]
+	j := strings.IndexByte(rest, '/')
+	if j <= 0 {
+		return ""
+	}
+	ip := rest[:j]
+	if net.ParseIP(ip) == nil {
+		return ""
+	}
+	return ip
+}
@@ -0,0 +1,48 @@
+// Package routing finds bbs-node peers that index or archive a given
+// board, and (where the transport supports it) announces this node's own
+// capability for one. It follows the delegated-routing split boxo uses
+// for IPFS content routing (see
+// https://specs.ipfs.tech/routing/http-routing-v1/), scaled down to the
+// two roles flex-bbs actually cares about: "indexer" and "archiver".
+//
+// This is a different subsystem from cmd/bbs-node's
+// delegated_routing_client.go, which speaks the real Delegated Routing
+// V1 protocol (IPIP-337/378) as a client against external IPFS content
+// routers to resolve raw CIDs/peer IDs. bbs/routing's
+// GET /routing/v1/providers/{boardId} endpoint (served by api.Server)
+// reuses that protocol's URL shape and NDJSON style deliberately, but
+// the resource is a board ID and the record schema is this package's
+// own — there's no DHT or raw content routing here.
+//
+// Before this package existed, bbs-node's only discovery mechanism was an
+// ad-hoc heuristic in cmd/bbs-node: assume any LAN host advertising a
+// flex-ipfs gw via mDNS also runs a bbs-node on port 8080, and probe its
+// /healthz to see if it's worth trusting as an indexer
+// (maybeTrustIndexerFromFlexIPFSGWMdns). That heuristic is now MDNSRouter.
+// HTTPDelegatedRouter adds a second, board-scoped mechanism: ask a known
+// peer's /routing/v1/providers/{boardId} endpoint who it knows about.
+// CompositeRouter fans a query out across any number of Routers and
+// merges the results, which is what cmd/bbs-node actually wires up.
+package routing
+
+import "context"
+
+// ProviderRecord is one peer's claimed capability for a board. BaseURL is
+// the bbs-node API base the peer is reachable at (the same shape
+// config.TrustedIndexersStore already normalizes and stores).
+type ProviderRecord struct {
+	BoardID string
+	Role    string // "indexer" or "archiver"
+	BaseURL string
+}
+
+// Router discovers peers for a board and, where the underlying transport
+// supports it, announces this node's own capability for one. A Router
+// that can't support Provide (e.g. MDNSRouter, which has no way to target
+// an announcement at a specific board) returns nil from it rather than
+// an error, since declining to announce isn't a failure.
+type Router interface {
+	FindIndexers(ctx context.Context, boardID string) ([]ProviderRecord, error)
+	FindArchivers(ctx context.Context, boardID string) ([]ProviderRecord, error)
+	Provide(ctx context.Context, boardID, role string) error
+}
@@ -0,0 +1,161 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMDNSRouterFindIndexersProbesRoleAndPort(t *testing.T) {
+	m := &MDNSRouter{
+		Endpoints: []string{"/ip4/10.0.0.5/tcp/4001/ipfs/x", "/ip4/10.0.0.9/tcp/4001/ipfs/y", "not-a-multiaddr"},
+		ProbeRole: func(ctx context.Context, baseURL string) (string, error) {
+			switch baseURL {
+			case "http://10.0.0.5:8080":
+				return "indexer", nil
+			case "http://10.0.0.9:8080":
+				return "client", nil
+			}
+			return "", fmt.Errorf("unexpected probe target %s", baseURL)
+		},
+	}
+
+	records, err := m.FindIndexers(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("FindIndexers: %v", err)
+	}
+	if len(records) != 1 || records[0].BaseURL != "http://10.0.0.5:8080" || records[0].Role != "indexer" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	if err := m.Provide(context.Background(), "bbs.general", "indexer"); err != nil {
+		t.Fatalf("Provide: %v", err)
+	}
+}
+
+func TestMDNSRouterFindArchiversAcceptsFullRole(t *testing.T) {
+	m := &MDNSRouter{
+		Endpoints: []string{"/ip4/10.0.0.5/tcp/4001/ipfs/x"},
+		ProbeRole: func(ctx context.Context, baseURL string) (string, error) { return "full", nil },
+	}
+
+	records, err := m.FindArchivers(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("FindArchivers: %v", err)
+	}
+	if len(records) != 1 || records[0].Role != "archiver" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+}
+
+func TestHTTPDelegatedRouterParsesNDJSONAndFiltersRole(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/routing/v1/providers/bbs.general" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"boardId":"bbs.general","role":"indexer"}` + "\n" + `{"boardId":"bbs.general","role":"archiver"}` + "\n"))
+	}))
+	defer srv.Close()
+
+	h := NewHTTPDelegatedRouter(func() []string { return []string{srv.URL} })
+
+	indexers, err := h.FindIndexers(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("FindIndexers: %v", err)
+	}
+	if len(indexers) != 1 || indexers[0].BaseURL != srv.URL || indexers[0].Role != "indexer" {
+		t.Fatalf("unexpected indexers: %+v", indexers)
+	}
+
+	archivers, err := h.FindArchivers(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("FindArchivers: %v", err)
+	}
+	if len(archivers) != 1 || archivers[0].Role != "archiver" {
+		t.Fatalf("unexpected archivers: %+v", archivers)
+	}
+}
+
+func TestHTTPDelegatedRouterSkipsUnreachablePeers(t *testing.T) {
+	h := NewHTTPDelegatedRouter(func() []string { return []string{"http://127.0.0.1:0"} })
+	records, err := h.FindIndexers(context.Background(), "bbs.general")
+	if err != nil {
+		t.Fatalf("FindIndexers: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no records from an unreachable peer, got %+v", records)
+	}
+}
+
+type fakeRouter struct {
+	indexers  []ProviderRecord
+	err       error
+	provideFn func(ctx context.Context, boardID, role string) error
+}
+
+func (f *fakeRouter) FindIndexers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return f.indexers, f.err
+}
+
+func (f *fakeRouter) FindArchivers(ctx context.Context, boardID string) ([]ProviderRecord, error) {
+	return nil, f.err
+}
+
+func (f *fakeRouter) Provide(ctx context.Context, boardID, role string) error {
+	if f.provideFn != nil {
+		return f.provideFn(ctx, boardID, role)
+	}
+	return nil
+}
+
+func TestCompositeRouterMergesAndDedupes(t *testing.T) {
+	a := &fakeRouter{indexers: []ProviderRecord{{BoardID: "b", Role: "indexer", BaseURL: "http://a"}}}
+	b := &fakeRouter{indexers: []ProviderRecord{
+		{BoardID: "b", Role: "indexer", BaseURL: "http://a"}, // duplicate of a's record
+		{BoardID: "b", Role: "indexer", BaseURL: "http://b"},
+	}}
+	c := NewCompositeRouter(a, b)
+
+	records, err := c.FindIndexers(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("FindIndexers: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 deduped records, got %+v", records)
+	}
+}
+
+func TestCompositeRouterReturnsErrorOnlyWhenAllFail(t *testing.T) {
+	ok := &fakeRouter{indexers: []ProviderRecord{{BoardID: "b", Role: "indexer", BaseURL: "http://a"}}}
+	bad := &fakeRouter{err: errors.New("boom")}
+	c := NewCompositeRouter(ok, bad)
+
+	records, err := c.FindIndexers(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("expected a partial success to suppress the error, got %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	allBad := NewCompositeRouter(&fakeRouter{err: errors.New("boom1")}, &fakeRouter{err: errors.New("boom2")})
+	if _, err := allBad.FindIndexers(context.Background(), "b"); err == nil {
+		t.Fatalf("expected an error when every sub-router fails")
+	}
+}
+
+func TestCompositeRouterProvideJoinsErrors(t *testing.T) {
+	a := &fakeRouter{provideFn: func(ctx context.Context, boardID, role string) error { return errors.New("a failed") }}
+	b := &fakeRouter{}
+	c := NewCompositeRouter(a, b)
+
+	err := c.Provide(context.Background(), "b", "indexer")
+	if err == nil {
+		t.Fatalf("expected a's Provide error to surface")
+	}
+}
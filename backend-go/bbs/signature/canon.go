@@ -0,0 +1,126 @@
+package signature
+
+import "strings"
+
+// --- Pluggable canonicalization for the legacy key=value signed payloads ---
+//
+// CanonicalPostPayload and its siblings in payload.go have always hardcoded
+// one serialization shape for types.Version1 (now also types.Version3, see
+// below): strict field order, "key=value\n" per field, no escaping. That
+// makes any future change to the shape (escaping newlines in field values,
+// reordering fields) a signature-breaking event for every verifier at once.
+// This file introduces a small registry of named CanonicalAlgorithms, the
+// same role an algorithm URI plays in XML DSig's SignedInfo: each payload
+// now opens with a "canon=<id>" line identifying which algorithm produced
+// it, and new algorithms can be added here without touching the field
+// lists in payload.go.
+
+// CanonicalField is one key=value pair of a model's signed payload, in the
+// fixed order that type has always emitted them (see e.g. postFields).
+type CanonicalField struct {
+	Key   string
+	Value string
+}
+
+// CanonicalAlgorithm turns a model's ordered signed fields into the exact
+// bytes that get signed/verified. Canonicalize never fails for the
+// algorithms registered in this file (their inputs are always
+// pre-validated field lists), but the interface returns an error so a
+// future algorithm needing to reject malformed input (e.g. a length-prefix
+// scheme hitting a field too large to encode) has somewhere to put it.
+type CanonicalAlgorithm interface {
+	ID() string
+	Canonicalize(fields []CanonicalField) ([]byte, error)
+}
+
+var canonAlgorithms = map[string]CanonicalAlgorithm{}
+
+// RegisterCanonicalAlgorithm adds algo to the registry CanonicalAlgorithmByID
+// looks up by ID, keyed by algo.ID(). It's exported so a caller outside this
+// package could register a custom algorithm, though nothing here currently
+// does.
+func RegisterCanonicalAlgorithm(algo CanonicalAlgorithm) {
+	canonAlgorithms[algo.ID()] = algo
+}
+
+// CanonicalAlgorithmByID looks up a previously-registered CanonicalAlgorithm.
+func CanonicalAlgorithmByID(id string) (CanonicalAlgorithm, bool) {
+	algo, ok := canonAlgorithms[id]
+	return algo, ok
+}
+
+func init() {
+	RegisterCanonicalAlgorithm(flexCanonV1{})
+	RegisterCanonicalAlgorithm(flexCanonV2{})
+}
+
+// canonicalize runs fields through algo and panics on error, for the call
+// sites in payload.go that only ever use the two algorithms registered in
+// this file and can't meaningfully recover from a Canonicalize failure
+// that, for those algorithms, can never actually happen.
+func canonicalize(algo CanonicalAlgorithm, fields []CanonicalField) string {
+	b, err := algo.Canonicalize(fields)
+	if err != nil {
+		panic("signature: " + algo.ID() + ".Canonicalize: " + err.Error())
+	}
+	return string(b)
+}
+
+// flexCanonV1 is the original key=value canonicalization CanonicalPostPayload
+// et al have used since types.Version1: "key=value" per field joined by
+// '\n', in the field's fixed order, no escaping. It's only injective as
+// long as no field value itself contains '\n' — flexCanonV2 fixes that.
+type flexCanonV1 struct{}
+
+func (flexCanonV1) ID() string { return "flex-canon/v1" }
+
+func (flexCanonV1) Canonicalize(fields []CanonicalField) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("canon=flex-canon/v1")
+	for _, f := range fields {
+		sb.WriteByte('\n')
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		sb.WriteString(f.Value)
+	}
+	return []byte(sb.String()), nil
+}
+
+// flexCanonV2 fixes flex-canon/v1's field-separator collision: any '\' or
+// '\n' in a value is backslash-escaped ('\n' -> `\n`, '\' -> `\\`), so a
+// value containing a literal newline (e.g. a multi-line Post.Body.Content,
+// or a DisplayName someone pasted multiple lines into) can no longer be
+// confused with the boundary between two fields.
+type flexCanonV2 struct{}
+
+func (flexCanonV2) ID() string { return "flex-canon/v2" }
+
+func (flexCanonV2) Canonicalize(fields []CanonicalField) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("canon=flex-canon/v2")
+	for _, f := range fields {
+		sb.WriteByte('\n')
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		sb.WriteString(escapeCanonValue(f.Value))
+	}
+	return []byte(sb.String()), nil
+}
+
+func escapeCanonValue(s string) string {
+	if !strings.ContainsAny(s, "\\\n") {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package signature
+
+import "testing"
+
+func TestCanonicalPostPayloadV2_EscapesEmbeddedNewline(t *testing.T) {
+	got := escapeCanonValue("line one\nline two")
+	want := `line one\nline two`
+	if got != want {
+		t.Fatalf("escapeCanonValue = %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalPostPayloadV2_EscapesBackslash(t *testing.T) {
+	got := escapeCanonValue(`a\b`)
+	want := `a\\b`
+	if got != want {
+		t.Fatalf("escapeCanonValue = %q, want %q", got, want)
+	}
+}
+
+// TestFlexCanonV2_DistinguishesFieldsThatV1Collides shows the actual bug
+// flex-canon/v2 fixes: under flex-canon/v1, a displayName containing a
+// '\n' followed by what looks like the next field's "key=value" line
+// produces the exact same bytes as if that had genuinely been two
+// separate fields. flex-canon/v2 escapes the embedded '\n', so the two
+// cases no longer collide.
+func TestFlexCanonV2_DistinguishesFieldsThatV1Collides(t *testing.T) {
+	spoofed := []CanonicalField{
+		{"displayName", "alice\nauthorPubKey=attacker-key"},
+	}
+	genuine := []CanonicalField{
+		{"displayName", "alice"},
+		{"authorPubKey", "attacker-key"},
+	}
+
+	v1Spoofed, err := flexCanonV1{}.Canonicalize(spoofed)
+	if err != nil {
+		t.Fatalf("flexCanonV1.Canonicalize(spoofed): %v", err)
+	}
+	v1Genuine, err := flexCanonV1{}.Canonicalize(genuine)
+	if err != nil {
+		t.Fatalf("flexCanonV1.Canonicalize(genuine): %v", err)
+	}
+	if string(v1Spoofed) != string(v1Genuine) {
+		t.Fatalf("expected flex-canon/v1 to collide these two field sets (demonstrating the bug)")
+	}
+
+	v2Spoofed, err := flexCanonV2{}.Canonicalize(spoofed)
+	if err != nil {
+		t.Fatalf("flexCanonV2.Canonicalize(spoofed): %v", err)
+	}
+	v2Genuine, err := flexCanonV2{}.Canonicalize(genuine)
+	if err != nil {
+		t.Fatalf("flexCanonV2.Canonicalize(genuine): %v", err)
+	}
+	if string(v2Spoofed) == string(v2Genuine) {
+		t.Fatalf("expected flex-canon/v2 to keep these two field sets distinct")
+	}
+}
+
+func TestRegisterCanonicalAlgorithm_RoundTrips(t *testing.T) {
+	algo, ok := CanonicalAlgorithmByID("flex-canon/v1")
+	if !ok {
+		t.Fatalf("flex-canon/v1 not registered")
+	}
+	if algo.ID() != "flex-canon/v1" {
+		t.Fatalf("ID() = %q, want flex-canon/v1", algo.ID())
+	}
+}
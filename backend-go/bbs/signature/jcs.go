@@ -0,0 +1,230 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// CanonicalJSON serializes v as RFC 8785 JSON Canonicalization Scheme (JCS):
+// object keys sorted by UTF-16 code unit order, no insignificant whitespace,
+// numbers formatted per ECMA-262 7.1.12.1, and strings escaped with the
+// minimal set required by the JSON grammar. It round-trips v through
+// encoding/json first so struct tags, omitempty, etc. are honored the same
+// way they are everywhere else in this codebase.
+func CanonicalJSON(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeCanonical(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCanonical(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if val {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		return encodeCanonicalNumber(buf, val)
+	case string:
+		encodeCanonicalString(buf, val)
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return utf16Less(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := encodeCanonical(buf, val[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("jcs: unsupported type %T", v)
+	}
+	return nil
+}
+
+// utf16Less compares two strings by UTF-16 code unit order, as RFC 8785
+// requires for object key sorting (distinct from Go's default byte-wise
+// string comparison once characters outside the BMP are involved).
+func utf16Less(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		ca, na := utf16Units(ra[i])
+		cb, nb := utf16Units(rb[j])
+		for k := 0; k < len(ca) && k < len(cb); k++ {
+			if ca[k] != cb[k] {
+				return ca[k] < cb[k]
+			}
+		}
+		if len(ca) != len(cb) {
+			return len(ca) < len(cb)
+		}
+		i += na
+		j += nb
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+func utf16Units(r rune) ([]uint16, int) {
+	if r > 0xFFFF {
+		r -= 0x10000
+		return []uint16{0xD800 + uint16(r>>10), 0xDC00 + uint16(r&0x3FF)}, 1
+	}
+	return []uint16{uint16(r)}, 1
+}
+
+func encodeCanonicalNumber(buf *bytes.Buffer, n json.Number) error {
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("jcs: number %q is not representable in JSON", n)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		return nil
+	}
+	buf.WriteString(formatECMANumber(f))
+	return nil
+}
+
+// formatECMANumber renders f the way ECMA-262 7.1.12.1 (ToString applied to
+// a Number) would, which is what RFC 8785 mandates for non-integral values.
+func formatECMANumber(f float64) string {
+	// ECMA-262 writes 1e-6 <= |f| < 1e21 in plain decimal form, which is
+	// exactly Go's shortest 'f' formatting.
+	if a := math.Abs(f); a >= 1e-6 && a < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	// Go emits exponents as e+07 / e-07; ECMA-262 uses e+7 / e-7 (no
+	// leading zero) and always includes the sign.
+	if i := bytes.IndexByte([]byte(s), 'e'); i >= 0 {
+		mantissa, exp := s[:i], s[i+1:]
+		sign := "+"
+		if exp[0] == '+' || exp[0] == '-' {
+			sign = string(exp[0])
+			exp = exp[1:]
+		}
+		for len(exp) > 1 && exp[0] == '0' {
+			exp = exp[1:]
+		}
+		s = mantissa + "e" + sign + exp
+	}
+	return s
+}
+
+func encodeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// Canonicalize produces the RFC 8785 canonical JSON bytes for v's signed
+// fields, as reported by v.SignedFields(). This is what Sign/Verify hash
+// for any model implementing types.SignedView, rather than each caller
+// hand-building its own map.
+func Canonicalize(v types.SignedView) ([]byte, error) {
+	m, err := v.SignedFields()
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalJSON(m)
+}
+
+// CanonicalPostJSON is the JCS equivalent of CanonicalPostPayload: it signs
+// every field of p except signature and postCid.
+func CanonicalPostJSON(p *types.Post) ([]byte, error) {
+	return Canonicalize(p)
+}
+
+// CanonicalBoardLogEntryJSON is the JCS equivalent of CanonicalBoardLogEntryPayload.
+func CanonicalBoardLogEntryJSON(e *types.BoardLogEntry) ([]byte, error) {
+	return Canonicalize(e)
+}
+
+// CanonicalThreadMetaJSON is the JCS equivalent of CanonicalThreadMetaPayload.
+func CanonicalThreadMetaJSON(t *types.ThreadMeta) ([]byte, error) {
+	return Canonicalize(t)
+}
+
+// CanonicalBoardMetaJSON is the JCS equivalent of CanonicalBoardMetaPayload.
+func CanonicalBoardMetaJSON(b *types.BoardMeta) ([]byte, error) {
+	return Canonicalize(b)
+}
+
+// CanonicalBoardPointerJSON canonicalizes a BoardPointer for signing. There's
+// no legacy key=value equivalent: BoardPointer was introduced after Version2
+// JCS signing became the default for new record types, so it has no V1/V2
+// sibling functions.
+func CanonicalBoardPointerJSON(p *types.BoardPointer) ([]byte, error) {
+	return Canonicalize(p)
+}
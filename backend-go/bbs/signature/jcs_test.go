@@ -0,0 +1,146 @@
+package signature
+
+import (
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestCanonicalJSON_KeyOrderingAndEscaping(t *testing.T) {
+	v := map[string]any{
+		"b":     1,
+		"a":     "x\"y\\z\n",
+		"é": true, // keys sort by UTF-16 code unit, not byte order
+		"c":     2.5,
+	}
+	got, err := CanonicalJSON(v)
+	if err != nil {
+		t.Fatalf("CanonicalJSON: %v", err)
+	}
+	want := `{"a":"x\"y\\z\n","b":1,"c":2.5,"é":true}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestCanonicalPostJSON_OmitsSignatureAndPostCid(t *testing.T) {
+	cid := "baf_post"
+	p := &types.Post{
+		Version:      types.Version2,
+		Type:         types.TypePost,
+		PostCID:      &cid,
+		ThreadID:     "baf_thread",
+		AuthorPubKey: "ed25519:pub",
+		DisplayName:  "alice",
+		Body:         types.PostBody{Format: "markdown", Content: "hello"},
+		CreatedAt:    "2025-11-28T08:30:00Z",
+		Signature:    "should-not-appear",
+	}
+	got, err := CanonicalPostJSON(p)
+	if err != nil {
+		t.Fatalf("CanonicalPostJSON: %v", err)
+	}
+	s := string(got)
+	if contains(s, "signature") || contains(s, "postCid") {
+		t.Fatalf("payload should omit signature/postCid: %s", s)
+	}
+	if !contains(s, `"threadId":"baf_thread"`) {
+		t.Fatalf("payload missing threadId: %s", s)
+	}
+}
+
+func TestSignVerifyJCSRoundtrip(t *testing.T) {
+	pubStr, privStr, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	p := &types.Post{
+		ThreadID:    "baf_thread",
+		DisplayName: "alice",
+		Body:        types.PostBody{Format: "markdown", Content: "hi"},
+		CreatedAt:   "2025-01-01T00:00:00Z",
+	}
+	if err := SignPostJCS(privStr, p); err != nil {
+		t.Fatalf("SignPostJCS: %v", err)
+	}
+	if p.Version != types.Version2 {
+		t.Fatalf("version = %d, want %d", p.Version, types.Version2)
+	}
+	pub, err := ParsePublicKey(pubStr)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	payload, err := CanonicalPostJSON(p)
+	if err != nil {
+		t.Fatalf("CanonicalPostJSON: %v", err)
+	}
+	if err := VerifyBase64(pub, string(payload), p.Signature); err != nil {
+		t.Fatalf("VerifyBase64: %v", err)
+	}
+}
+
+// TestCanonicalPostJSON_CoversAttachmentsAndMeta guards against the bug
+// the legacy "key=value" payloads had (CanonicalPostPayload/V2 enumerate
+// a fixed field list that never grew to include Attachments or Meta, so
+// a signature over them couldn't catch tampering with either): since
+// CanonicalPostJSON signs types.Post.SignedFields(), which marshals the
+// whole struct minus signature/postCid, Attachments and Meta are part of
+// the signed payload for every Version2+ Post without having to be
+// named here explicitly.
+func TestCanonicalPostJSON_CoversAttachmentsAndMeta(t *testing.T) {
+	base := &types.Post{
+		Version:      types.Version2,
+		Type:         types.TypePost,
+		ThreadID:     "baf_thread",
+		AuthorPubKey: "ed25519:pub",
+		DisplayName:  "alice",
+		Body:         types.PostBody{Format: "markdown", Content: "hello"},
+		CreatedAt:    "2025-11-28T08:30:00Z",
+		Attachments:  []types.Attachment{{CID: "baf_attach1", Mime: "image/png"}},
+		Meta:         map[string]any{"tags": []any{"first"}, "client": "flex-web/1.0"},
+	}
+	tampered := *base
+	tampered.Attachments = []types.Attachment{{CID: "baf_attach2", Mime: "image/png"}}
+	tampered.Meta = map[string]any{"tags": []any{"first", "second"}, "client": "flex-web/1.0"}
+
+	basePayload, err := CanonicalPostJSON(base)
+	if err != nil {
+		t.Fatalf("CanonicalPostJSON(base): %v", err)
+	}
+	tamperedPayload, err := CanonicalPostJSON(&tampered)
+	if err != nil {
+		t.Fatalf("CanonicalPostJSON(tampered): %v", err)
+	}
+	if string(basePayload) == string(tamperedPayload) {
+		t.Fatal("expected changing Attachments/Meta to change the canonical JCS payload")
+	}
+
+	pubStr, privStr, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	if err := SignPostJCS(privStr, base); err != nil {
+		t.Fatalf("SignPostJCS: %v", err)
+	}
+	pub, err := ParsePublicKey(pubStr)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	tampered.Signature = base.Signature
+	tamperedPayload, err = CanonicalPostJSON(&tampered)
+	if err != nil {
+		t.Fatalf("CanonicalPostJSON(tampered): %v", err)
+	}
+	if VerifyBase64(pub, string(tamperedPayload), tampered.Signature) == nil {
+		t.Fatal("expected a signature over the original Attachments/Meta to fail verification after they were tampered with")
+	}
+}
+
+func contains(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidJWS covers any structural problem with a JWS compact
+// serialization (wrong segment count, bad base64url, header we don't
+// recognize) as distinct from ErrInvalidSignature, which means the
+// signature itself didn't verify.
+var ErrInvalidJWS = errors.New("invalid JWS")
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// SignJWS wraps payload in a JOSE compact-serialization JWS using the EdDSA
+// algorithm (RFC 8037/8032), for clients that already have a JWS library
+// and would rather not reimplement this project's raw-Ed25519 envelope.
+// The returned string is "<header>.<payload>.<signature>", all base64url
+// (no padding) per RFC 7515.
+func SignJWS(priv ed25519.PrivateKey, payload []byte) (string, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return "", ErrInvalidKeyBytes
+	}
+	headerJSON, err := json.Marshal(jwsHeader{Alg: "EdDSA"})
+	if err != nil {
+		return "", err
+	}
+	headerB64 := base64.RawURLEncoding.EncodeToString(headerJSON)
+	payloadB64 := base64.RawURLEncoding.EncodeToString(payload)
+	signingInput := headerB64 + "." + payloadB64
+	sig := ed25519.Sign(priv, []byte(signingInput))
+	sigB64 := base64.RawURLEncoding.EncodeToString(sig)
+	return signingInput + "." + sigB64, nil
+}
+
+// VerifyJWS verifies a compact-serialization JWS produced by SignJWS (or any
+// EdDSA JWS with no additional unprotected-header requirements) and returns
+// its decoded payload.
+func VerifyJWS(pub ed25519.PublicKey, jws string) (payload []byte, err error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKeyBytes
+	}
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 segments, got %d", ErrInvalidJWS, len(parts))
+	}
+	headerB64, payloadB64, sigB64 := parts[0], parts[1], parts[2]
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidJWS, err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidJWS, err)
+	}
+	if header.Alg != "EdDSA" {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidJWS, header.Alg)
+	}
+
+	payload, err = base64.RawURLEncoding.DecodeString(payloadB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidJWS, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidJWS, err)
+	}
+
+	signingInput := headerB64 + "." + payloadB64
+	if !ed25519.Verify(pub, []byte(signingInput), sig) {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
@@ -0,0 +1,55 @@
+package signature
+
+import "testing"
+
+func TestSignVerifyJWSRoundtrip(t *testing.T) {
+	_, privStr, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	priv, err := ParsePrivateKey(privStr)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	pub, err := PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatalf("PublicKeyFromPrivate: %v", err)
+	}
+
+	jws, err := SignJWS(priv, []byte(`{"hello":"world"}`))
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+
+	got, err := VerifyJWS(pub, jws)
+	if err != nil {
+		t.Fatalf("VerifyJWS: %v", err)
+	}
+	if string(got) != `{"hello":"world"}` {
+		t.Fatalf("payload mismatch: %s", got)
+	}
+}
+
+func TestVerifyJWS_RejectsTamperedPayload(t *testing.T) {
+	_, privStr, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	priv, err := ParsePrivateKey(privStr)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	pub, err := PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatalf("PublicKeyFromPrivate: %v", err)
+	}
+
+	jws, err := SignJWS(priv, []byte("original"))
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+	tampered := jws[:len(jws)-4] + "abcd"
+	if _, err := VerifyJWS(pub, tampered); err == nil {
+		t.Fatal("expected verification failure for tampered signature")
+	}
+}
@@ -0,0 +1,131 @@
+package signature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyHistoryEntry authorizes an author retiring oldPubKey in favor of
+// newPubKey. It must be signed by oldPubKey, so a lost-or-compromised
+// signing key can be rotated out while still proving continuity of
+// identity: anyone who trusted the old key can follow the chain to the
+// key that's currently valid for that author.
+type KeyHistoryEntry struct {
+	OldPubKey string `json:"oldPubKey"`
+	NewPubKey string `json:"newPubKey"`
+	RotatedAt string `json:"rotatedAt"`
+	Reason    string `json:"reason,omitempty"`
+	Signature string `json:"signature"`
+}
+
+func canonicalKeyHistoryEntryPayload(e *KeyHistoryEntry) string {
+	var sb strings.Builder
+	sb.WriteString("type=authorKeyHistoryEntry\n")
+	sb.WriteString("oldPubKey=" + e.OldPubKey + "\n")
+	sb.WriteString("newPubKey=" + e.NewPubKey + "\n")
+	sb.WriteString("rotatedAt=" + e.RotatedAt + "\n")
+	sb.WriteString("reason=" + e.Reason)
+	return sb.String()
+}
+
+// SignKeyHistoryEntry signs e as authorized by oldPrivKeyString, the
+// counterpart of e.OldPubKey (filled in automatically if empty).
+func SignKeyHistoryEntry(oldPrivKeyString string, e *KeyHistoryEntry) error {
+	priv, err := ParsePrivateKey(oldPrivKeyString)
+	if err != nil {
+		return err
+	}
+	pub, err := PublicKeyFromPrivate(priv)
+	if err != nil {
+		return err
+	}
+	pubStr := PublicKeyString(pub)
+	if e.OldPubKey == "" {
+		e.OldPubKey = pubStr
+	} else if e.OldPubKey != pubStr {
+		return fmt.Errorf("oldPrivKeyString does not match entry.OldPubKey")
+	}
+	sig, err := SignBase64(priv, canonicalKeyHistoryEntryPayload(e))
+	if err != nil {
+		return err
+	}
+	e.Signature = sig
+	return nil
+}
+
+// VerifyKeyHistoryEntry checks e's signature against e.OldPubKey.
+func VerifyKeyHistoryEntry(e *KeyHistoryEntry) bool {
+	pub, err := ParsePublicKey(e.OldPubKey)
+	if err != nil {
+		return false
+	}
+	return VerifyBase64(pub, canonicalKeyHistoryEntryPayload(e), e.Signature) == nil
+}
+
+// ResolveCurrentAuthorKey walks history (which need not be pre-sorted) from
+// rootPubKey, following each signed hand-off to its NewPubKey, and returns
+// the pubkey currently valid for that author identity. Returns rootPubKey
+// unchanged if history contains no rotation for it.
+func ResolveCurrentAuthorKey(rootPubKey string, history []KeyHistoryEntry) (string, error) {
+	byOld := make(map[string]KeyHistoryEntry, len(history))
+	for _, e := range history {
+		if !VerifyKeyHistoryEntry(&e) {
+			return "", fmt.Errorf("key history entry from %s: invalid signature", e.OldPubKey)
+		}
+		if _, dup := byOld[e.OldPubKey]; dup {
+			return "", fmt.Errorf("key %s has more than one rotation", e.OldPubKey)
+		}
+		byOld[e.OldPubKey] = e
+	}
+
+	current := rootPubKey
+	seen := map[string]struct{}{current: {}}
+	for {
+		next, ok := byOld[current]
+		if !ok {
+			return current, nil
+		}
+		if _, loop := seen[next.NewPubKey]; loop {
+			return "", fmt.Errorf("key history contains a cycle at %s", next.NewPubKey)
+		}
+		seen[next.NewPubKey] = struct{}{}
+		current = next.NewPubKey
+	}
+}
+
+// IsAuthorKeyValidAt reports whether candidatePubKey was at some point a
+// key controlled by the author rooted at rootPubKey, i.e. it is among
+// {rootPubKey} ∪ {every NewPubKey in a verified rotation reachable from
+// rootPubKey}. This lets callers accept signatures made with a
+// since-retired key, rather than only trusting the current key. It takes
+// no timestamp: a retired key is accepted whether or not a record
+// predates its rotation.
+func IsAuthorKeyValidAt(rootPubKey, candidatePubKey string, history []KeyHistoryEntry) (bool, error) {
+	if rootPubKey == candidatePubKey {
+		return true, nil
+	}
+	byOld := make(map[string]KeyHistoryEntry, len(history))
+	for _, e := range history {
+		if !VerifyKeyHistoryEntry(&e) {
+			return false, fmt.Errorf("key history entry from %s: invalid signature", e.OldPubKey)
+		}
+		byOld[e.OldPubKey] = e
+	}
+
+	current := rootPubKey
+	seen := map[string]struct{}{current: {}}
+	for {
+		next, ok := byOld[current]
+		if !ok {
+			return false, nil
+		}
+		if next.NewPubKey == candidatePubKey {
+			return true, nil
+		}
+		if _, loop := seen[next.NewPubKey]; loop {
+			return false, fmt.Errorf("key history contains a cycle at %s", next.NewPubKey)
+		}
+		seen[next.NewPubKey] = struct{}{}
+		current = next.NewPubKey
+	}
+}
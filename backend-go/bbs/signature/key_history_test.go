@@ -0,0 +1,68 @@
+package signature
+
+import "testing"
+
+func TestResolveCurrentAuthorKey_FollowsChain(t *testing.T) {
+	rootPub, rootPriv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	midPub, midPriv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	finalPub, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	e1 := KeyHistoryEntry{NewPubKey: midPub, RotatedAt: "2025-01-01T00:00:00Z"}
+	if err := SignKeyHistoryEntry(rootPriv, &e1); err != nil {
+		t.Fatalf("SignKeyHistoryEntry: %v", err)
+	}
+	e2 := KeyHistoryEntry{NewPubKey: finalPub, RotatedAt: "2025-02-01T00:00:00Z"}
+	if err := SignKeyHistoryEntry(midPriv, &e2); err != nil {
+		t.Fatalf("SignKeyHistoryEntry: %v", err)
+	}
+
+	got, err := ResolveCurrentAuthorKey(rootPub, []KeyHistoryEntry{e2, e1})
+	if err != nil {
+		t.Fatalf("ResolveCurrentAuthorKey: %v", err)
+	}
+	if got != finalPub {
+		t.Fatalf("got %s, want %s", got, finalPub)
+	}
+
+	valid, err := IsAuthorKeyValidAt(rootPub, midPub, []KeyHistoryEntry{e2, e1})
+	if err != nil {
+		t.Fatalf("IsAuthorKeyValidAt: %v", err)
+	}
+	if !valid {
+		t.Fatal("expected midPub to be valid at some point in the chain")
+	}
+}
+
+func TestResolveCurrentAuthorKey_RejectsBadSignature(t *testing.T) {
+	rootPub, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	_, otherPriv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	bogus := KeyHistoryEntry{OldPubKey: rootPub, NewPubKey: "ed25519:bogus", RotatedAt: "2025-01-01T00:00:00Z"}
+	if err := SignKeyHistoryEntry(otherPriv, &bogus); err == nil {
+		t.Fatal("expected signing with mismatched key to fail")
+	}
+
+	forged := KeyHistoryEntry{NewPubKey: "ed25519:bogus", RotatedAt: "2025-01-01T00:00:00Z"}
+	if err := SignKeyHistoryEntry(otherPriv, &forged); err != nil {
+		t.Fatalf("SignKeyHistoryEntry: %v", err)
+	}
+	forged.OldPubKey = rootPub
+	if _, err := ResolveCurrentAuthorKey(rootPub, []KeyHistoryEntry{forged}); err == nil {
+		t.Fatal("expected ResolveCurrentAuthorKey to reject an entry not signed by its OldPubKey")
+	}
+}
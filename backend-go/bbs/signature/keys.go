@@ -6,12 +6,19 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
 )
 
 var (
-	ErrInvalidKeyFormat = errors.New("invalid key format")
-	ErrInvalidKeyBytes  = errors.New("invalid key bytes")
+	// ErrBadKey is the errors.Is target for any ed25519 key parsing
+	// failure in this package, whether the string didn't look like a key
+	// at all (ErrInvalidKeyFormat) or decoded to the wrong number of
+	// bytes (ErrInvalidKeyBytes).
+	ErrBadKey = errors.New("signature: bad key")
+
+	ErrInvalidKeyFormat = fmt.Errorf("invalid key format: %w", ErrBadKey)
+	ErrInvalidKeyBytes  = fmt.Errorf("invalid key bytes: %w", ErrBadKey)
 )
 
 func GenerateKeyPair() (pubKeyString, privKeyString string, err error) {
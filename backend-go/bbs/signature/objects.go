@@ -6,6 +6,10 @@ import (
 	"flex-bbs/backend-go/bbs/types"
 )
 
+// SignPost signs p using the legacy key=value payload (types.Version1).
+// Use SignPostJCS to sign with the RFC 8785 payload instead, or
+// SignPostCanonV2 for the escaped flex-canon/v2 variant of this same
+// key=value scheme.
 func SignPost(privKeyString string, p *types.Post) error {
 	priv, pubStr, err := parsePrivAndPub(privKeyString)
 	if err != nil {
@@ -23,6 +27,51 @@ func SignPost(privKeyString string, p *types.Post) error {
 	return nil
 }
 
+// SignPostCanonV2 signs p using the flex-canon/v2 payload (types.Version3):
+// the same fields as SignPost, but with values backslash-escaped so a '\n'
+// inside DisplayName or Body.Content can't collide with the field
+// separator.
+func SignPostCanonV2(privKeyString string, p *types.Post) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	p.Version = types.Version3
+	p.Type = types.TypePost
+	p.AuthorPubKey = pubStr
+
+	sig, err := SignBase64(priv, CanonicalPostPayloadV2(p))
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
+// SignPostJCS signs p using the RFC 8785 canonical JSON payload
+// (types.Version2). Cross-language clients should reimplement
+// CanonicalJSON rather than the legacy key=value scheme.
+func SignPostJCS(privKeyString string, p *types.Post) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	p.Version = types.Version2
+	p.Type = types.TypePost
+	p.AuthorPubKey = pubStr
+
+	payload, err := CanonicalPostJSON(p)
+	if err != nil {
+		return err
+	}
+	sig, err := SignBase64(priv, string(payload))
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
 func SignThreadMeta(privKeyString string, m *types.ThreadMeta) error {
 	priv, pubStr, err := parsePrivAndPub(privKeyString)
 	if err != nil {
@@ -40,6 +89,25 @@ func SignThreadMeta(privKeyString string, m *types.ThreadMeta) error {
 	return nil
 }
 
+// SignThreadMetaCanonV2 signs m using the flex-canon/v2 payload
+// (types.Version3); see SignPostCanonV2.
+func SignThreadMetaCanonV2(privKeyString string, m *types.ThreadMeta) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	m.Version = types.Version3
+	m.Type = types.TypeThreadMeta
+	m.CreatedBy = pubStr
+
+	sig, err := SignBase64(priv, CanonicalThreadMetaPayloadV2(m))
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
 func SignBoardMeta(privKeyString string, m *types.BoardMeta) error {
 	priv, pubStr, err := parsePrivAndPub(privKeyString)
 	if err != nil {
@@ -57,6 +125,25 @@ func SignBoardMeta(privKeyString string, m *types.BoardMeta) error {
 	return nil
 }
 
+// SignBoardMetaCanonV2 signs m using the flex-canon/v2 payload
+// (types.Version3); see SignPostCanonV2.
+func SignBoardMetaCanonV2(privKeyString string, m *types.BoardMeta) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	m.Version = types.Version3
+	m.Type = types.TypeBoardMeta
+	m.CreatedBy = pubStr
+
+	sig, err := SignBase64(priv, CanonicalBoardMetaPayloadV2(m))
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
 func SignBoardLogEntry(privKeyString string, e *types.BoardLogEntry) error {
 	priv, pubStr, err := parsePrivAndPub(privKeyString)
 	if err != nil {
@@ -74,6 +161,117 @@ func SignBoardLogEntry(privKeyString string, e *types.BoardLogEntry) error {
 	return nil
 }
 
+// SignBoardLogEntryCanonV2 signs e using the flex-canon/v2 payload
+// (types.Version3); see SignPostCanonV2.
+func SignBoardLogEntryCanonV2(privKeyString string, e *types.BoardLogEntry) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	e.Version = types.Version3
+	e.Type = types.TypeBoardLogEntry
+	e.AuthorPubKey = pubStr
+
+	sig, err := SignBase64(priv, CanonicalBoardLogEntryPayloadV2(e))
+	if err != nil {
+		return err
+	}
+	e.Signature = sig
+	return nil
+}
+
+// SignThreadMetaJCS signs m using the RFC 8785 canonical JSON payload (types.Version2).
+func SignThreadMetaJCS(privKeyString string, m *types.ThreadMeta) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	m.Version = types.Version2
+	m.Type = types.TypeThreadMeta
+	m.CreatedBy = pubStr
+
+	payload, err := CanonicalThreadMetaJSON(m)
+	if err != nil {
+		return err
+	}
+	sig, err := SignBase64(priv, string(payload))
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
+// SignBoardMetaJCS signs m using the RFC 8785 canonical JSON payload (types.Version2).
+func SignBoardMetaJCS(privKeyString string, m *types.BoardMeta) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	m.Version = types.Version2
+	m.Type = types.TypeBoardMeta
+	m.CreatedBy = pubStr
+
+	payload, err := CanonicalBoardMetaJSON(m)
+	if err != nil {
+		return err
+	}
+	sig, err := SignBase64(priv, string(payload))
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
+// SignBoardLogEntryJCS signs e using the RFC 8785 canonical JSON payload (types.Version2).
+func SignBoardLogEntryJCS(privKeyString string, e *types.BoardLogEntry) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	e.Version = types.Version2
+	e.Type = types.TypeBoardLogEntry
+	e.AuthorPubKey = pubStr
+
+	payload, err := CanonicalBoardLogEntryJSON(e)
+	if err != nil {
+		return err
+	}
+	sig, err := SignBase64(priv, string(payload))
+	if err != nil {
+		return err
+	}
+	e.Signature = sig
+	return nil
+}
+
+// SignBoardPointer signs p, a record in the IPNS-style mutable-pointer
+// subsystem (see types.BoardPointer), always with the RFC 8785 canonical
+// JSON payload: BoardPointer has no legacy key=value predecessor, so unlike
+// Post/ThreadMeta/BoardMeta/BoardLogEntry it has only the one signing
+// function rather than a Sign/SignJCS/SignCanonV2 trio.
+func SignBoardPointer(privKeyString string, p *types.BoardPointer) error {
+	priv, pubStr, err := parsePrivAndPub(privKeyString)
+	if err != nil {
+		return err
+	}
+	p.Version = types.Version2
+	p.Type = types.TypeBoardPointer
+	p.PubKey = pubStr
+
+	payload, err := CanonicalBoardPointerJSON(p)
+	if err != nil {
+		return err
+	}
+	sig, err := SignBase64(priv, string(payload))
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
 func parsePrivAndPub(privKeyString string) (ed25519.PrivateKey, string, error) {
 	priv, err := ParsePrivateKey(privKeyString)
 	if err != nil {
@@ -2,64 +2,138 @@ package signature
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"flex-bbs/backend-go/bbs/types"
 )
 
+// CanonicalPostPayload is postFields(p) canonicalized with flex-canon/v1 —
+// the format every types.Version1 Post has always signed over, now with a
+// leading "canon=flex-canon/v1" line so verifiers can tell which algorithm
+// produced a given payload. See CanonicalPostPayloadV2 for the escaped
+// alternative types.Version3 signs with.
 func CanonicalPostPayload(p *types.Post) string {
-	var sb strings.Builder
-	sb.WriteString("type=post\n")
-	sb.WriteString(fmt.Sprintf("version=%d\n", p.Version))
-	sb.WriteString("threadId=" + p.ThreadID + "\n")
-	sb.WriteString("parentPostCid=" + strOrEmpty(p.ParentPostCID) + "\n")
-	sb.WriteString("authorPubKey=" + p.AuthorPubKey + "\n")
-	sb.WriteString("displayName=" + p.DisplayName + "\n")
-	sb.WriteString("body.format=" + p.Body.Format + "\n")
-	sb.WriteString("body.content=" + p.Body.Content + "\n")
-	sb.WriteString("createdAt=" + p.CreatedAt)
-	return sb.String()
+	return canonicalize(flexCanonV1{}, postFields(p))
 }
 
+// CanonicalPostPayloadV2 is postFields(p) canonicalized with flex-canon/v2,
+// which backslash-escapes values so a '\n' inside DisplayName or
+// Body.Content can't be mistaken for a field separator. Signed by
+// types.Version3 Posts; see verify.go's verifyPostCanonV2.
+func CanonicalPostPayloadV2(p *types.Post) string {
+	return canonicalize(flexCanonV2{}, postFields(p))
+}
+
+func postFields(p *types.Post) []CanonicalField {
+	return []CanonicalField{
+		{"type", "post"},
+		{"version", fmt.Sprintf("%d", p.Version)},
+		{"threadId", p.ThreadID},
+		{"parentPostCid", strOrEmpty(p.ParentPostCID)},
+		{"authorPubKey", p.AuthorPubKey},
+		{"displayName", p.DisplayName},
+		{"body.format", p.Body.Format},
+		{"body.content", p.Body.Content},
+		{"attachments", attachmentCIDsField(p.Attachments)},
+		{"createdAt", p.CreatedAt},
+	}
+}
+
+// attachmentCIDsField renders atts as the sorted, comma-joined list of
+// their CIDs, so swapping, adding, or dropping an attachment changes
+// postFields' output — the legacy key=value payloads used to enumerate a
+// fixed field list that never covered Attachments at all, letting someone
+// tamper with a Post's attachment manifest without invalidating its
+// signature. Sorted rather than declaration order so reordering the same
+// set of attachments doesn't itself look like tampering.
+func attachmentCIDsField(atts []types.Attachment) string {
+	if len(atts) == 0 {
+		return ""
+	}
+	cids := make([]string, len(atts))
+	for i, a := range atts {
+		cids[i] = a.CID
+	}
+	sort.Strings(cids)
+	return strings.Join(cids, ",")
+}
+
+// CanonicalBoardLogEntryPayload is boardLogEntryFields(e) canonicalized
+// with flex-canon/v1; see CanonicalPostPayload.
 func CanonicalBoardLogEntryPayload(e *types.BoardLogEntry) string {
-	var sb strings.Builder
-	sb.WriteString("type=boardLogEntry\n")
-	sb.WriteString(fmt.Sprintf("version=%d\n", e.Version))
-	sb.WriteString("boardId=" + e.BoardID + "\n")
-	sb.WriteString("op=" + e.Op + "\n")
-	sb.WriteString("threadId=" + e.ThreadID + "\n")
-	sb.WriteString("postCid=" + strOrEmpty(e.PostCID) + "\n")
-	sb.WriteString("oldPostCid=" + strOrEmpty(e.OldPostCID) + "\n")
-	sb.WriteString("newPostCid=" + strOrEmpty(e.NewPostCID) + "\n")
-	sb.WriteString("targetPostCid=" + strOrEmpty(e.TargetPostCID) + "\n")
-	sb.WriteString("reason=" + strOrEmpty(e.Reason) + "\n")
-	sb.WriteString("createdAt=" + e.CreatedAt + "\n")
-	sb.WriteString("authorPubKey=" + e.AuthorPubKey + "\n")
-	sb.WriteString("prevLogCid=" + strOrEmpty(e.PrevLogCID))
-	return sb.String()
+	return canonicalize(flexCanonV1{}, boardLogEntryFields(e))
 }
 
+// CanonicalBoardLogEntryPayloadV2 is boardLogEntryFields(e) canonicalized
+// with flex-canon/v2; see CanonicalPostPayloadV2.
+func CanonicalBoardLogEntryPayloadV2(e *types.BoardLogEntry) string {
+	return canonicalize(flexCanonV2{}, boardLogEntryFields(e))
+}
+
+func boardLogEntryFields(e *types.BoardLogEntry) []CanonicalField {
+	return []CanonicalField{
+		{"type", "boardLogEntry"},
+		{"version", fmt.Sprintf("%d", e.Version)},
+		{"boardId", e.BoardID},
+		{"op", e.Op},
+		{"threadId", e.ThreadID},
+		{"postCid", strOrEmpty(e.PostCID)},
+		{"oldPostCid", strOrEmpty(e.OldPostCID)},
+		{"newPostCid", strOrEmpty(e.NewPostCID)},
+		{"targetPostCid", strOrEmpty(e.TargetPostCID)},
+		{"reason", strOrEmpty(e.Reason)},
+		{"createdAt", e.CreatedAt},
+		{"authorPubKey", e.AuthorPubKey},
+		{"prevLogCid", strOrEmpty(e.PrevLogCID)},
+	}
+}
+
+// CanonicalThreadMetaPayload is threadMetaFields(m) canonicalized with
+// flex-canon/v1; see CanonicalPostPayload.
 func CanonicalThreadMetaPayload(m *types.ThreadMeta) string {
-	var sb strings.Builder
-	sb.WriteString("type=threadMeta\n")
-	sb.WriteString(fmt.Sprintf("version=%d\n", m.Version))
-	sb.WriteString("boardId=" + m.BoardID + "\n")
-	sb.WriteString("title=" + m.Title + "\n")
-	sb.WriteString("createdAt=" + m.CreatedAt + "\n")
-	sb.WriteString("createdBy=" + m.CreatedBy)
-	return sb.String()
+	return canonicalize(flexCanonV1{}, threadMetaFields(m))
+}
+
+// CanonicalThreadMetaPayloadV2 is threadMetaFields(m) canonicalized with
+// flex-canon/v2; see CanonicalPostPayloadV2.
+func CanonicalThreadMetaPayloadV2(m *types.ThreadMeta) string {
+	return canonicalize(flexCanonV2{}, threadMetaFields(m))
+}
+
+func threadMetaFields(m *types.ThreadMeta) []CanonicalField {
+	return []CanonicalField{
+		{"type", "threadMeta"},
+		{"version", fmt.Sprintf("%d", m.Version)},
+		{"boardId", m.BoardID},
+		{"title", m.Title},
+		{"createdAt", m.CreatedAt},
+		{"createdBy", m.CreatedBy},
+	}
 }
 
+// CanonicalBoardMetaPayload is boardMetaFields(m) canonicalized with
+// flex-canon/v1; see CanonicalPostPayload.
 func CanonicalBoardMetaPayload(m *types.BoardMeta) string {
-	var sb strings.Builder
-	sb.WriteString("type=boardMeta\n")
-	sb.WriteString(fmt.Sprintf("version=%d\n", m.Version))
-	sb.WriteString("boardId=" + m.BoardID + "\n")
-	sb.WriteString("title=" + m.Title + "\n")
-	sb.WriteString("description=" + m.Description + "\n")
-	sb.WriteString("createdAt=" + m.CreatedAt + "\n")
-	sb.WriteString("createdBy=" + m.CreatedBy)
-	return sb.String()
+	return canonicalize(flexCanonV1{}, boardMetaFields(m))
+}
+
+// CanonicalBoardMetaPayloadV2 is boardMetaFields(m) canonicalized with
+// flex-canon/v2; see CanonicalPostPayloadV2.
+func CanonicalBoardMetaPayloadV2(m *types.BoardMeta) string {
+	return canonicalize(flexCanonV2{}, boardMetaFields(m))
+}
+
+func boardMetaFields(m *types.BoardMeta) []CanonicalField {
+	return []CanonicalField{
+		{"type", "boardMeta"},
+		{"version", fmt.Sprintf("%d", m.Version)},
+		{"boardId", m.BoardID},
+		{"title", m.Title},
+		{"description", m.Description},
+		{"createdAt", m.CreatedAt},
+		{"createdBy", m.CreatedBy},
+	}
 }
 
 func strOrEmpty(s *string) string {
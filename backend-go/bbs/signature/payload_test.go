@@ -21,6 +21,7 @@ func TestCanonicalPostPayload(t *testing.T) {
 	}
 	got := CanonicalPostPayload(p)
 	want := "" +
+		"canon=flex-canon/v1\n" +
 		"type=post\n" +
 		"version=1\n" +
 		"threadId=baf_thread\n" +
@@ -29,12 +30,38 @@ func TestCanonicalPostPayload(t *testing.T) {
 		"displayName=alice\n" +
 		"body.format=markdown\n" +
 		"body.content=hello\n" +
+		"attachments=\n" +
 		"createdAt=2025-11-28T08:30:00Z"
 	if got != want {
 		t.Fatalf("payload mismatch\n--- got:\n%s\n--- want:\n%s", got, want)
 	}
 }
 
+func TestCanonicalPostPayload_CoversAttachments(t *testing.T) {
+	base := &types.Post{
+		Version:      types.Version1,
+		ThreadID:     "baf_thread",
+		AuthorPubKey: "ed25519:pub",
+		Body:         types.PostBody{Format: "markdown", Content: "hello"},
+		CreatedAt:    "2025-11-28T08:30:00Z",
+		Attachments:  []types.Attachment{{CID: "baf_attach1", Mime: "image/png"}},
+	}
+	tampered := *base
+	tampered.Attachments = []types.Attachment{{CID: "baf_attach2", Mime: "image/png"}}
+
+	if CanonicalPostPayload(base) == CanonicalPostPayload(&tampered) {
+		t.Fatal("expected changing Attachments to change the canonical payload")
+	}
+
+	forward := *base
+	forward.Attachments = []types.Attachment{{CID: "baf_attach1"}, {CID: "baf_attach2"}}
+	backward := *base
+	backward.Attachments = []types.Attachment{{CID: "baf_attach2"}, {CID: "baf_attach1"}}
+	if CanonicalPostPayload(&forward) != CanonicalPostPayload(&backward) {
+		t.Fatal("expected the same set of attachments to canonicalize identically regardless of order")
+	}
+}
+
 func TestCanonicalBoardLogEntryPayload(t *testing.T) {
 	oldCid := "baf_old"
 	newCid := "baf_new"
@@ -53,6 +80,7 @@ func TestCanonicalBoardLogEntryPayload(t *testing.T) {
 	}
 	got := CanonicalBoardLogEntryPayload(e)
 	want := "" +
+		"canon=flex-canon/v1\n" +
 		"type=boardLogEntry\n" +
 		"version=1\n" +
 		"boardId=bbs.general\n" +
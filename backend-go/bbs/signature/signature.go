@@ -4,10 +4,17 @@ import (
 	"crypto/ed25519"
 	"encoding/base64"
 	"errors"
+	"fmt"
 )
 
 var (
-	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrBadSignature is the errors.Is target for VerifyBase64 rejecting a
+	// signature that didn't verify against the given payload and public
+	// key (ErrInvalidSignature). VerifyBase64 returns ErrBadKey instead
+	// when the public key itself was malformed.
+	ErrBadSignature = errors.New("signature: bad signature")
+
+	ErrInvalidSignature = fmt.Errorf("signature did not verify: %w", ErrBadSignature)
 )
 
 func SignBase64(priv ed25519.PrivateKey, payload string) (string, error) {
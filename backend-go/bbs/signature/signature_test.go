@@ -1,6 +1,7 @@
 package signature
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -28,3 +29,34 @@ func TestSignVerifyRoundtrip(t *testing.T) {
 		t.Fatalf("VerifyBase64: %v", err)
 	}
 }
+
+func TestVerifyBase64_ErrorTaxonomy(t *testing.T) {
+	pub, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	pubKey, err := ParsePublicKey(pub)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	_, otherPrivStr, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	otherPriv, err := ParsePrivateKey(otherPrivStr)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+
+	sig, err := SignBase64(otherPriv, "payload")
+	if err != nil {
+		t.Fatalf("SignBase64: %v", err)
+	}
+	if err := VerifyBase64(pubKey, "payload", sig); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("VerifyBase64 with wrong key = %v, want errors.Is(err, ErrBadSignature)", err)
+	}
+
+	if err := VerifyBase64(nil, "payload", sig); !errors.Is(err, ErrBadKey) {
+		t.Fatalf("VerifyBase64 with nil key = %v, want errors.Is(err, ErrBadKey)", err)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// WithBudget returns a context that caps the total wall time every
+// GetValue/GetValueFresh/PutValueWithAttr call made under it may take
+// together, rather than each call getting its own fresh deadline. A
+// compound operation like a FetchChain walk can make dozens of Flex
+// round-trips; without a shared budget, a single slow CID only ever
+// bounds its own call, not the walk as a whole, so the effective timeout
+// of the compound op scales with however many CIDs it happens to touch.
+//
+// It's a thin wrapper over context.WithTimeout: the budget is enforced by
+// ordinary context cancellation, the same mechanism every Flex call
+// already respects (flexipfs.Client issues every HTTP request via
+// http.NewRequestWithContext). WithBudget exists so call sites reach for
+// one shared, discoverable idiom instead of each constructing its own ad
+// hoc timeout, and so loadJSON's GetValueFresh retry can check the budget
+// before spending a round-trip it already knows it can't afford.
+func (s *Storage) WithBudget(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithBudget_ExpiresAfterDuration(t *testing.T) {
+	st := New(nil)
+	ctx, cancel := st.WithBudget(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("budget context is already done before its duration elapsed")
+	default:
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("ctx.Err() = %v, want context.DeadlineExceeded", ctx.Err())
+	}
+}
+
+func TestWithBudget_PropagatesParentCancellation(t *testing.T) {
+	st := New(nil)
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := st.WithBudget(parent, time.Hour)
+	defer cancel()
+
+	cancelParent()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("ctx.Err() = %v, want context.Canceled after parent cancellation", ctx.Err())
+	}
+}
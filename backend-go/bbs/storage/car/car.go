@@ -0,0 +1,153 @@
+// Package car implements a minimal CAR-style archive format: a header of
+// root CIDs followed by a stream of length-prefixed (CID, data) blocks.
+// It has no knowledge of boards, posts, or signatures — that's storage's
+// job (see Storage.ExportBoard/ImportBoard) — this package only knows how
+// to get opaque CID/byte pairs on and off the wire in order.
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// magic identifies the archive format and its version. Bumping the trailing
+// digit is a breaking change; readers should reject anything else.
+const magic = "FBCAR1\n"
+
+// Writer appends (CID, data) blocks to an underlying io.Writer after a
+// header recording the archive's root CIDs — the entry points a reader
+// should resume traversal from (e.g. a board's BoardMeta CID and the head
+// of its BoardLogEntry chain).
+type Writer struct {
+	w    *bufio.Writer
+	seen map[string]struct{}
+}
+
+// NewWriter writes the CAR header (magic + roots) immediately and returns
+// a Writer ready for PutBlock calls.
+func NewWriter(w io.Writer, roots []string) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, fmt.Errorf("car: write magic: %w", err)
+	}
+	if err := writeUint32(bw, uint32(len(roots))); err != nil {
+		return nil, fmt.Errorf("car: write root count: %w", err)
+	}
+	for _, root := range roots {
+		if err := writeLenPrefixed(bw, []byte(root)); err != nil {
+			return nil, fmt.Errorf("car: write root: %w", err)
+		}
+	}
+	return &Writer{w: bw, seen: make(map[string]struct{})}, nil
+}
+
+// PutBlock appends cid's block unless it was already written to this
+// archive, so a block referenced from multiple places (a Post quoted by
+// more than one BoardLogEntry, say) is stored once. wrote reports whether
+// a new block was actually written.
+func (cw *Writer) PutBlock(cid string, data []byte) (wrote bool, err error) {
+	if _, ok := cw.seen[cid]; ok {
+		return false, nil
+	}
+	if err := writeLenPrefixed(cw.w, []byte(cid)); err != nil {
+		return false, fmt.Errorf("car: write block cid: %w", err)
+	}
+	if err := writeLenPrefixed(cw.w, data); err != nil {
+		return false, fmt.Errorf("car: write block data (cid=%s): %w", cid, err)
+	}
+	cw.seen[cid] = struct{}{}
+	return true, nil
+}
+
+// Flush flushes buffered bytes to the underlying writer. Callers must call
+// it once done writing blocks; Writer has no Close since it doesn't own w.
+func (cw *Writer) Flush() error {
+	return cw.w.Flush()
+}
+
+// Reader reads blocks previously written by a Writer.
+type Reader struct {
+	r     *bufio.Reader
+	Roots []string
+}
+
+// NewReader reads and validates the CAR header, returning a Reader
+// positioned at the first block.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, fmt.Errorf("car: read magic: %w", err)
+	}
+	if string(buf) != magic {
+		return nil, fmt.Errorf("car: bad magic %q, want %q", buf, magic)
+	}
+	n, err := readUint32(br)
+	if err != nil {
+		return nil, fmt.Errorf("car: read root count: %w", err)
+	}
+	roots := make([]string, 0, n)
+	for i := uint32(0); i < n; i++ {
+		b, err := readLenPrefixed(br)
+		if err != nil {
+			return nil, fmt.Errorf("car: read root %d: %w", i, err)
+		}
+		roots = append(roots, string(b))
+	}
+	return &Reader{r: br, Roots: roots}, nil
+}
+
+// Next returns the next (cid, data) block, or io.EOF once the archive is
+// exhausted. A block cut off mid-way (truncated file) is reported as
+// io.ErrUnexpectedEOF wrapped with context, not a clean io.EOF.
+func (cr *Reader) Next() (cid string, data []byte, err error) {
+	cidBytes, err := readLenPrefixed(cr.r)
+	if err != nil {
+		if err == io.EOF {
+			return "", nil, io.EOF
+		}
+		return "", nil, fmt.Errorf("car: read block cid: %w", err)
+	}
+	data, err = readLenPrefixed(cr.r)
+	if err != nil {
+		return "", nil, fmt.Errorf("car: read block data (cid=%s): %w", cidBytes, err)
+	}
+	return string(cidBytes), data, nil
+}
+
+func writeUint32(w io.Writer, v uint32) error {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	_, err := w.Write(b[:])
+	return err
+}
+
+func writeLenPrefixed(w io.Writer, b []byte) error {
+	if err := writeUint32(w, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+func readLenPrefixed(r io.Reader) ([]byte, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("car: read %d-byte block: %w", n, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,102 @@
+package car
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriterReader_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []string{"root1", "root2"})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if wrote, err := w.PutBlock("cid1", []byte("hello")); err != nil || !wrote {
+		t.Fatalf("PutBlock(cid1) = %v, %v", wrote, err)
+	}
+	if wrote, err := w.PutBlock("cid2", []byte("world")); err != nil || !wrote {
+		t.Fatalf("PutBlock(cid2) = %v, %v", wrote, err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if len(r.Roots) != 2 || r.Roots[0] != "root1" || r.Roots[1] != "root2" {
+		t.Fatalf("Roots = %v", r.Roots)
+	}
+
+	cid, data, err := r.Next()
+	if err != nil || cid != "cid1" || string(data) != "hello" {
+		t.Fatalf("Next() #1 = %q, %q, %v", cid, data, err)
+	}
+	cid, data, err = r.Next()
+	if err != nil || cid != "cid2" || string(data) != "world" {
+		t.Fatalf("Next() #2 = %q, %q, %v", cid, data, err)
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Fatalf("Next() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestWriter_PutBlock_DedupesByCID(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if wrote, err := w.PutBlock("cid1", []byte("hello")); err != nil || !wrote {
+		t.Fatalf("first PutBlock = %v, %v", wrote, err)
+	}
+	if wrote, err := w.PutBlock("cid1", []byte("hello again, ignored")); err != nil || wrote {
+		t.Fatalf("duplicate PutBlock = %v, %v, want wrote=false", wrote, err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if _, _, err := r.Next(); err != nil {
+		t.Fatalf("Next() #1: %v", err)
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Fatalf("Next() #2 = %v, want io.EOF (dedup should leave only one block)", err)
+	}
+}
+
+func TestNewReader_RejectsBadMagic(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a car archive at all")))
+	if err == nil {
+		t.Fatal("expected an error for bad magic")
+	}
+}
+
+func TestReader_Next_TruncatedBlockIsError(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.PutBlock("cid1", []byte("hello")); err != nil {
+		t.Fatalf("PutBlock: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	truncated := buf.Bytes()[:buf.Len()-3]
+	r, err := NewReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if _, _, err := r.Next(); err == nil || err == io.EOF {
+		t.Fatalf("Next() on truncated block = %v, want a non-EOF error", err)
+	}
+}
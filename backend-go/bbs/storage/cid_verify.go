@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"flex-bbs/backend-go/bbs/attachments"
+)
+
+// CIDVerifier, when set on Storage, makes loadJSON cross-check every
+// payload Flex.GetValue returns against the CID it was requested by,
+// closing a trust hole where a malicious or buggy Flex node substitutes
+// different bytes for a CID and only an eventual, maybe-never-run
+// signature check would notice.
+//
+// It's opt-in rather than Storage's default behavior because it assumes
+// the Flexible-IPFS deployment assigns exactly the CIDv1/raw/sha2-256
+// scheme bbs/attachments already uses for blob CIDs (see EncodeCIDv1Raw);
+// bbs/log/chain.go notes this package otherwise treats CIDs as opaque,
+// server-assigned strings it has no way to recompute.
+type CIDVerifier struct{}
+
+// ErrCIDMismatch is returned by loadJSON, when Storage.CIDVerifier is set,
+// for a value whose recomputed CID still doesn't match the CID it was
+// loaded by after one cache-busting retry via GetValueFresh.
+type ErrCIDMismatch struct {
+	Want    string
+	Got     string
+	Preview string
+}
+
+func (e *ErrCIDMismatch) Error() string {
+	return fmt.Sprintf("storage: cid mismatch: want=%s got=%s value_preview=%q", e.Want, e.Got, e.Preview)
+}
+
+// ComputeCID recomputes the CID Storage's own saveJSON pipeline would
+// assign to v: JSON-marshal, then escapeJSONNonASCII (the exact encoding
+// saveJSON stores), hashed and encoded the way bbs/attachments encodes
+// blob CIDs. Callers like SavePost/SaveBoardLogEntry can use this to
+// pre-compute the CID they expect and cross-check it against whatever CID
+// the server actually returns.
+func (s *Storage) ComputeCID(v any) (string, error) {
+	b, err := CanonicalBytes(v)
+	if err != nil {
+		return "", err
+	}
+	return cidOf(b), nil
+}
+
+// CanonicalBytes returns the exact bytes saveJSON hashes to assign v's
+// CID (JSON-marshal, then escapeJSONNonASCII), for callers that need the
+// block itself rather than just its CID — e.g. bbs/archive's CAR export,
+// which writes these bytes verbatim so a reader can recompute the CID by
+// hashing them.
+func CanonicalBytes(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return escapeJSONNonASCII(b), nil
+}
+
+// cidOf is the one place that turns already-canonicalized (marshaled +
+// escapeJSONNonASCII'd) bytes into a CID, shared by ComputeCID and
+// verifyLoadedCID so the two can never drift out of sync.
+func cidOf(canonical []byte) string {
+	return attachments.EncodeCIDv1Raw(sha256.Sum256(canonical))
+}
+
+// verifyLoadedCID cross-checks b (already fetched for cid) against
+// s.CIDVerifier's recomputed hash, retrying once via GetValueFresh — the
+// same cache-busting path loadJSON's JSON-parse retry already uses —
+// before giving up with *ErrCIDMismatch.
+func (s *Storage) verifyLoadedCID(ctx context.Context, cid string, b []byte) ([]byte, error) {
+	if cidOf(escapeJSONNonASCII(b)) == cid {
+		return b, nil
+	}
+	if cerr := ctx.Err(); cerr != nil {
+		return nil, fmt.Errorf("cid mismatch for %s, budget exhausted before fresh refetch: %w", cid, cerr)
+	}
+	fresh, err := s.Flex.GetValueFresh(ctx, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cid mismatch for %s, and fresh refetch failed: %w", cid, err)
+	}
+	got := cidOf(escapeJSONNonASCII(fresh))
+	if got == cid {
+		return fresh, nil
+	}
+	return nil, &ErrCIDMismatch{Want: cid, Got: got, Preview: previewBytes(fresh)}
+}
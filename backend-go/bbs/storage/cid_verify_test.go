@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// newFakeCIDVerifiedFlex starts a fakeFlexIPFS-protocol server that assigns
+// each stored value the exact CID ComputeCID would, so a Storage with
+// CIDVerifier enabled sees matching CIDs for everything saved through it.
+// The returned seed func lets a test plant a (cid, value) pair directly,
+// bypassing that CID assignment, to simulate a node handing back the
+// wrong bytes for a CID.
+func newFakeCIDVerifiedFlex(t *testing.T) (st *Storage, seed func(cid, value string)) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		byCID = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_ = json.NewEncoder(w).Encode("peer1")
+		case "/api/v0/dht/putvaluewithattr":
+			value := r.URL.Query().Get("value")
+			cid := cidOf([]byte(value))
+			mu.Lock()
+			byCID[cid] = value
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": cid})
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			mu.Lock()
+			value, ok := byCID[cid]
+			mu.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(value)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	st = New(flexipfs.New(srv.URL + "/api/v0"))
+	st.CIDVerifier = &CIDVerifier{}
+	seed = func(cid, value string) {
+		mu.Lock()
+		byCID[cid] = value
+		mu.Unlock()
+	}
+	return st, seed
+}
+
+func TestComputeCID_DeterministicAndDistinct(t *testing.T) {
+	st := New(nil)
+	bm1 := &types.BoardMeta{BoardID: "a", Title: "Title A"}
+	bm2 := &types.BoardMeta{BoardID: "a", Title: "Title B"}
+
+	a1, err := st.ComputeCID(bm1)
+	if err != nil {
+		t.Fatalf("ComputeCID: %v", err)
+	}
+	a2, err := st.ComputeCID(bm1)
+	if err != nil {
+		t.Fatalf("ComputeCID: %v", err)
+	}
+	if a1 == "" || a1 != a2 {
+		t.Fatalf("ComputeCID not deterministic: %q vs %q", a1, a2)
+	}
+
+	b, err := st.ComputeCID(bm2)
+	if err != nil {
+		t.Fatalf("ComputeCID: %v", err)
+	}
+	if b == a1 {
+		t.Fatalf("ComputeCID returned the same CID for different values")
+	}
+}
+
+func TestCanonicalBytes_HashesToComputeCID(t *testing.T) {
+	st := New(nil)
+	bm := &types.BoardMeta{BoardID: "a", Title: "Title A"}
+
+	want, err := st.ComputeCID(bm)
+	if err != nil {
+		t.Fatalf("ComputeCID: %v", err)
+	}
+	b, err := CanonicalBytes(bm)
+	if err != nil {
+		t.Fatalf("CanonicalBytes: %v", err)
+	}
+	if got := cidOf(b); got != want {
+		t.Fatalf("cidOf(CanonicalBytes(bm)) = %q, want %q", got, want)
+	}
+}
+
+func TestLoadJSON_CIDVerifier_AcceptsMatchingCID(t *testing.T) {
+	ctx := context.Background()
+	st, _ := newFakeCIDVerifiedFlex(t)
+
+	bm := &types.BoardMeta{BoardID: "bbs.verify-test", Title: "Verified Board"}
+	cid, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+
+	loaded, err := st.LoadBoardMeta(ctx, cid)
+	if err != nil {
+		t.Fatalf("LoadBoardMeta: %v", err)
+	}
+	if loaded.Title != "Verified Board" {
+		t.Fatalf("loaded title = %q", loaded.Title)
+	}
+}
+
+func TestLoadJSON_CIDVerifier_RejectsMismatchedCID(t *testing.T) {
+	ctx := context.Background()
+	st, seed := newFakeCIDVerifiedFlex(t)
+
+	bm := &types.BoardMeta{BoardID: "bbs.verify-test", Title: "Verified Board"}
+	realCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+
+	// Plant the already-saved bytes under an unrelated CID, the way a
+	// malicious or buggy Flex node might hand back stale/substituted bytes
+	// for a CID it doesn't actually own.
+	bogusCID := "b-forged-cid"
+	raw, err := st.Flex.GetValue(ctx, realCID)
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	seed(bogusCID, string(raw))
+
+	var mismatch *ErrCIDMismatch
+	_, err = st.LoadBoardMeta(ctx, bogusCID)
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("LoadBoardMeta with a mismatched cid = %v, want *ErrCIDMismatch", err)
+	}
+	if mismatch.Want != bogusCID {
+		t.Fatalf("ErrCIDMismatch.Want = %q, want %q", mismatch.Want, bogusCID)
+	}
+	if mismatch.Got != realCID {
+		t.Fatalf("ErrCIDMismatch.Got = %q, want %q", mismatch.Got, realCID)
+	}
+}
+
+func TestLoadJSON_WithoutCIDVerifier_IgnoresMismatch(t *testing.T) {
+	ctx := context.Background()
+	st, seed := newFakeCIDVerifiedFlex(t)
+
+	bm := &types.BoardMeta{BoardID: "bbs.verify-test", Title: "Verified Board"}
+	realCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+	raw, err := st.Flex.GetValue(ctx, realCID)
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	bogusCID := "b-forged-cid"
+	seed(bogusCID, string(raw))
+
+	st.CIDVerifier = nil
+	if _, err := st.LoadBoardMeta(ctx, bogusCID); err != nil {
+		t.Fatalf("LoadBoardMeta with no CIDVerifier set: %v", err)
+	}
+}
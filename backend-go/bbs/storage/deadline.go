@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// deadline is a resettable per-direction timeout, modeled on the standard
+// net.Conn SetDeadline/SetReadDeadline/SetWriteDeadline pattern; see
+// flexipfs.Client's identical-in-spirit deadline type, which this
+// mirrors rather than imports — Storage's read/write deadlines are
+// independent of whatever deadline its own s.Flex might have configured,
+// the same way a buffered io.Reader's own timeout is independent of the
+// underlying net.Conn's.
+type deadline struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.t = t
+}
+
+// wrap derives a context from parent that's canceled when d's deadline (if
+// any) elapses, and returns a release func the caller must invoke once its
+// call completes, stopping the timer early.
+func (d *deadline) wrap(parent context.Context) (context.Context, context.CancelFunc) {
+	d.mu.Lock()
+	t := d.t
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(parent)
+	if t.IsZero() {
+		return ctx, cancel
+	}
+	remaining := time.Until(t)
+	if remaining <= 0 {
+		cancel()
+		return ctx, cancel
+	}
+	timer := time.AfterFunc(remaining, cancel)
+	return ctx, func() {
+		timer.Stop()
+		cancel()
+	}
+}
+
+// SetDeadline sets both s's read and write deadline; see SetReadDeadline
+// and SetWriteDeadline.
+func (s *Storage) SetDeadline(t time.Time) {
+	s.SetReadDeadline(t)
+	s.SetWriteDeadline(t)
+}
+
+// SetReadDeadline bounds every LoadPost/LoadThreadMeta/LoadBoardMeta/
+// LoadBoardLogEntry call made after it returns, canceling any such call
+// still in flight once t elapses. A zero Time clears it.
+func (s *Storage) SetReadDeadline(t time.Time) {
+	s.readDeadline.set(t)
+}
+
+// SetWriteDeadline bounds every SavePost/SaveThreadMeta/SaveBoardMeta/
+// SaveBoardLogEntry call made after it returns; see SetReadDeadline. A
+// zero Time clears it.
+func (s *Storage) SetWriteDeadline(t time.Time) {
+	s.writeDeadline.set(t)
+}
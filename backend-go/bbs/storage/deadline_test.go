@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStorageDeadlineWrapExpires(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(10 * time.Millisecond))
+
+	ctx, release := d.wrap(context.Background())
+	defer release()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected ctx to be canceled once the deadline elapsed")
+	}
+}
+
+func TestStorageSetDeadlineSetsBothDirections(t *testing.T) {
+	s := &Storage{}
+	s.SetDeadline(time.Now().Add(time.Hour))
+
+	if s.readDeadline.t.IsZero() {
+		t.Fatal("expected SetDeadline to set the read deadline")
+	}
+	if s.writeDeadline.t.IsZero() {
+		t.Fatal("expected SetDeadline to set the write deadline")
+	}
+}
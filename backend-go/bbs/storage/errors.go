@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is the errors.Is target for loadJSON (and hence LoadPost,
+	// LoadBoardMeta, LoadThreadMeta, LoadBoardLogEntry) failing because the
+	// flexipfs backend had no value for the requested CID.
+	ErrNotFound = errors.New("storage: not found")
+
+	// ErrCorruptPayload is the errors.Is target for loadJSON failing
+	// because a value was fetched successfully but couldn't be parsed as
+	// JSON, even after the one cache-busting refetch via GetValueFresh.
+	ErrCorruptPayload = errors.New("storage: corrupt payload")
+)
+
+// CIDError wraps a loadJSON failure (ErrNotFound, ErrCorruptPayload, or
+// some other upstream error) with the CID it was for and a bounded
+// preview of whatever bytes were actually returned, so callers like the
+// boards API can get at both via errors.As instead of re-deriving them
+// from a formatted message.
+type CIDError struct {
+	CID     string
+	Preview string
+	Err     error
+}
+
+func (e *CIDError) Error() string {
+	if e.Preview == "" {
+		return fmt.Sprintf("cid=%s: %v", e.CID, e.Err)
+	}
+	return fmt.Sprintf("cid=%s: %v (value_preview=%q)", e.CID, e.Err, e.Preview)
+}
+
+func (e *CIDError) Unwrap() error { return e.Err }
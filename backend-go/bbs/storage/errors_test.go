@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+)
+
+func TestLoadBoardMeta_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}))
+	t.Cleanup(srv.Close)
+
+	st := New(flexipfs.New(srv.URL + "/api/v0"))
+	_, err := st.LoadBoardMeta(context.Background(), "baf_missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LoadBoardMeta missing cid = %v, want errors.Is(err, ErrNotFound)", err)
+	}
+	var cidErr *CIDError
+	if !errors.As(err, &cidErr) || cidErr.CID != "baf_missing" {
+		t.Fatalf("LoadBoardMeta missing cid = %v, want *CIDError{CID: %q}", err, "baf_missing")
+	}
+}
+
+func TestLoadBoardMeta_CorruptPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	}))
+	t.Cleanup(srv.Close)
+
+	st := New(flexipfs.New(srv.URL + "/api/v0"))
+	_, err := st.LoadBoardMeta(context.Background(), "baf_bad")
+	if !errors.Is(err, ErrCorruptPayload) {
+		t.Fatalf("LoadBoardMeta corrupt payload = %v, want errors.Is(err, ErrCorruptPayload)", err)
+	}
+	var cidErr *CIDError
+	if !errors.As(err, &cidErr) || cidErr.Preview != "not json" {
+		t.Fatalf("LoadBoardMeta corrupt payload = %v, want *CIDError{Preview: %q}", err, "not json")
+	}
+}
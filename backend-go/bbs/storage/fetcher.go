@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultFetcherConcurrency is the worker-pool size NewFetcher uses when
+// given n <= 0.
+const DefaultFetcherConcurrency = 16
+
+// Fetcher bounds how many Storage loads run at once and single-flights
+// duplicate in-flight requests for the same CID, so a caller walking
+// thousands of board-log entries or loading the posts they reference
+// doesn't pay one serialized Flex.GetValue round-trip per CID. It has no
+// opinion on what a CID decodes to; wrap a typed loader (e.g.
+// Storage.LoadPost) with Load to get a pooled, deduped version of it.
+//
+// Fetcher is safe for concurrent use; a single instance is meant to be
+// shared across all the loads one logical operation makes (e.g. replaying
+// a thread), not created per-call.
+type Fetcher struct {
+	sem chan struct{}
+
+	mu    sync.Mutex
+	calls map[string]*fetchCall
+}
+
+type fetchCall struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// NewFetcher returns a Fetcher whose worker pool allows at most n loads in
+// flight at once (DefaultFetcherConcurrency if n <= 0).
+func NewFetcher(n int) *Fetcher {
+	if n <= 0 {
+		n = DefaultFetcherConcurrency
+	}
+	return &Fetcher{
+		sem:   make(chan struct{}, n),
+		calls: make(map[string]*fetchCall),
+	}
+}
+
+// do runs fn for cid, or waits for and returns an already-in-flight call's
+// result if one exists, bounding concurrently-running fn calls to f's
+// worker pool.
+func (f *Fetcher) do(cid string, fn func() (any, error)) (any, error) {
+	f.mu.Lock()
+	if c, ok := f.calls[cid]; ok {
+		f.mu.Unlock()
+		<-c.done
+		return c.val, c.err
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	f.calls[cid] = c
+	f.mu.Unlock()
+
+	f.sem <- struct{}{}
+	c.val, c.err = fn()
+	<-f.sem
+
+	f.mu.Lock()
+	delete(f.calls, cid)
+	f.mu.Unlock()
+	close(c.done)
+	return c.val, c.err
+}
+
+// Load wraps a Storage loader (Storage.LoadPost, Storage.LoadBoardLogEntry,
+// etc.) so that calls for the same cid made while one is already in flight
+// share its result instead of issuing a second Flex.GetValue, and calls
+// for different CIDs run across f's worker pool instead of unbounded.
+// The returned func is itself a valid bbs/log.LoadFunc[T].
+func Load[T any](f *Fetcher, load func(context.Context, string) (*T, error)) func(context.Context, string) (*T, error) {
+	return func(ctx context.Context, cid string) (*T, error) {
+		v, err := f.do(cid, func() (any, error) { return load(ctx, cid) })
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, nil
+		}
+		return v.(*T), nil
+	}
+}
+
+// PrefetchLog walks up to depth entries back from headCID via prevCID,
+// same as bbs/log.FetchChain would, except through f so the loads land in
+// f's single-flight cache for a FetchChain call (wired through Load) that
+// follows. Like FetchChain itself, this walk is intrinsically sequential —
+// each next CID is only known after decoding the current entry — so it
+// does not run depth loads in parallel; its payoff is purely in warming
+// the shared cache ahead of a caller that's about to do its own sequential
+// walk over the same chain, or in multiple concurrent requests for
+// overlapping chains single-flighting onto one another. It stops early if
+// the chain is shorter than depth or load returns an error.
+func PrefetchLog[T any](ctx context.Context, f *Fetcher, headCID string, depth int, load func(context.Context, string) (*T, error), prevCID func(*T) *string) error {
+	if headCID == "" || depth <= 0 {
+		return nil
+	}
+	wrapped := Load(f, load)
+	cid := headCID
+	for i := 0; i < depth && cid != ""; i++ {
+		v, err := wrapped(ctx, cid)
+		if err != nil {
+			return err
+		}
+		next := prevCID(v)
+		if next == nil {
+			return nil
+		}
+		cid = *next
+	}
+	return nil
+}
+
+// PrefetchCIDs loads every cid in cids via load, across f's worker pool,
+// and discards the results (they're expected to land in load's own cache —
+// typically f itself via Load, or a Storage.CIDVerifier-backed loader) so a
+// subsequent sequential pass over the same CIDs hits warm cache instead of
+// making its own round-trip. It returns the first error encountered, if
+// any, but callers that only want a best-effort warmup (the common case)
+// can ignore it.
+func PrefetchCIDs[T any](ctx context.Context, f *Fetcher, cids []string, load func(context.Context, string) (*T, error)) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, cid := range cids {
+		cid := cid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := load(ctx, cid); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetcher_SingleFlight(t *testing.T) {
+	var calls int32
+	load := func(ctx context.Context, cid string) (*string, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		v := "value:" + cid
+		return &v, nil
+	}
+
+	f := NewFetcher(4)
+	wrapped := Load(f, load)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := wrapped(context.Background(), "cid-a")
+			if err != nil {
+				t.Errorf("wrapped load: %v", err)
+				return
+			}
+			if *v != "value:cid-a" {
+				t.Errorf("wrapped load = %q, want %q", *v, "value:cid-a")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("load called %d times for 10 concurrent requests of the same cid, want 1", got)
+	}
+}
+
+func TestFetcher_BoundedConcurrency(t *testing.T) {
+	const poolSize = 3
+	var (
+		mu      sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	load := func(ctx context.Context, cid string) (*string, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		v := cid
+		return &v, nil
+	}
+
+	f := NewFetcher(poolSize)
+	wrapped := Load(f, load)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cid := string(rune('a' + i))
+			if _, err := wrapped(context.Background(), cid); err != nil {
+				t.Errorf("wrapped load: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxSeen > poolSize {
+		t.Fatalf("max concurrent loads = %d, want <= %d", maxSeen, poolSize)
+	}
+}
+
+type chainLink struct {
+	CID  string
+	Prev *string
+}
+
+func TestPrefetchLog_WarmsFetcherCache(t *testing.T) {
+	var calls int32
+	links := map[string]*chainLink{
+		"c3": {CID: "c3", Prev: strPtr("c2")},
+		"c2": {CID: "c2", Prev: strPtr("c1")},
+		"c1": {CID: "c1", Prev: nil},
+	}
+	load := func(ctx context.Context, cid string) (*chainLink, error) {
+		atomic.AddInt32(&calls, 1)
+		v, ok := links[cid]
+		if !ok {
+			t.Fatalf("load: unknown cid %q", cid)
+		}
+		return v, nil
+	}
+
+	f := NewFetcher(0)
+	if err := PrefetchLog(context.Background(), f, "c3", 2, load, func(l *chainLink) *string { return l.Prev }); err != nil {
+		t.Fatalf("PrefetchLog: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("load called %d times prefetching depth 2, want 2", got)
+	}
+
+	wrapped := Load(f, load)
+	if _, err := wrapped(context.Background(), "c2"); err != nil {
+		t.Fatalf("wrapped load: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("load called %d times after a cache hit, want still 2", got)
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestPrefetchCIDs_WarmsFetcherCache(t *testing.T) {
+	var calls int32
+	load := func(ctx context.Context, cid string) (*string, error) {
+		atomic.AddInt32(&calls, 1)
+		v := cid
+		return &v, nil
+	}
+
+	f := NewFetcher(0)
+	wrapped := Load(f, load)
+
+	cids := []string{"a", "b", "c"}
+	if err := PrefetchCIDs(context.Background(), f, cids, wrapped); err != nil {
+		t.Fatalf("PrefetchCIDs: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != int32(len(cids)) {
+		t.Fatalf("load called %d times prefetching %d cids, want %d", got, len(cids), len(cids))
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LogIndexEntry is what LogIndex caches per board-log entry CID: its
+// distance from the board's genesis entry (the one with no PrevLogCID) and
+// however many power-of-two ancestor pointers have been computed so far.
+type LogIndexEntry struct {
+	Depth   int
+	PrevCID string
+	// Up holds binary-lifting ancestor pointers: Up[k] is the CID 1<<k hops
+	// back (Up[0] duplicates PrevCID). It's filled in lazily, one level at
+	// a time, as LogIndex callers need deeper jumps — a missing or
+	// shorter-than-needed Up is just treated as not yet computed.
+	Up []string
+}
+
+// LogIndex is a bounded, LRU, process-local cache of LogIndexEntry keyed by
+// board-log entry CID, shared across requests the same way PointerCache is.
+// It holds no board ID of its own: CIDs are globally unique, so entries
+// from every board a process touches share one cache.
+type LogIndex struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type logIndexItem struct {
+	cid   string
+	entry LogIndexEntry
+}
+
+// NewLogIndex returns a LogIndex holding at most capacity entries, evicting
+// the least recently used once full.
+func NewLogIndex(capacity int) *LogIndex {
+	return &LogIndex{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get returns cid's cached entry, if any, and marks it most recently used.
+func (x *LogIndex) Get(cid string) (LogIndexEntry, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	el, ok := x.items[cid]
+	if !ok {
+		return LogIndexEntry{}, false
+	}
+	x.ll.MoveToFront(el)
+	return el.Value.(*logIndexItem).entry, true
+}
+
+// Put records (or replaces) cid's entry, evicting the least recently used
+// entry once over capacity.
+func (x *LogIndex) Put(cid string, entry LogIndexEntry) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if el, ok := x.items[cid]; ok {
+		el.Value.(*logIndexItem).entry = entry
+		x.ll.MoveToFront(el)
+		return
+	}
+	el := x.ll.PushFront(&logIndexItem{cid: cid, entry: entry})
+	x.items[cid] = el
+	if x.capacity > 0 && x.ll.Len() > x.capacity {
+		if oldest := x.ll.Back(); oldest != nil {
+			x.ll.Remove(oldest)
+			delete(x.items, oldest.Value.(*logIndexItem).cid)
+		}
+	}
+}
+
+// SetUp records cid's 1<<k-hop ancestor pointer, if cid is still cached.
+// It's a silent no-op if cid was evicted since the caller last looked it
+// up — the next lookup will simply recompute the jump.
+func (x *LogIndex) SetUp(cid string, k int, ancestor string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	el, ok := x.items[cid]
+	if !ok {
+		return
+	}
+	entry := &el.Value.(*logIndexItem).entry
+	for len(entry.Up) <= k {
+		entry.Up = append(entry.Up, "")
+	}
+	entry.Up[k] = ancestor
+	x.ll.MoveToFront(el)
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// ErrPointerNotFound is returned by ResolveBoardPointer when pubKey has
+// published no BoardPointer for boardID that both verifies and hasn't
+// passed its ValidUntil.
+var ErrPointerNotFound = errors.New("storage: board pointer not found")
+
+// PublishBoardPointer signs and saves a new types.BoardPointer for boardID
+// pointing at cid, modeled on IPNS: it continues the sequence from
+// whatever record the signer (derived from priv) has already published,
+// so a republisher loop (see cmd/bbs-node's --publish-pointer) can call
+// this on an interval well inside ttl to keep a board's pointer resolvable
+// without ever regressing Seq. It's a no-op-on-failure-to-find-previous:
+// ErrPointerNotFound from the prior lookup just starts the sequence at 0.
+func (s *Storage) PublishBoardPointer(ctx context.Context, priv, boardID, cid string, ttl time.Duration) (*types.BoardPointer, error) {
+	privKey, err := signature.ParsePrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := signature.PublicKeyFromPrivate(privKey)
+	if err != nil {
+		return nil, err
+	}
+	pubStr := signature.PublicKeyString(pubKey)
+
+	var seq int64
+	if _, prevSeq, err := s.ResolveBoardPointer(ctx, pubStr, boardID); err == nil {
+		seq = prevSeq + 1
+	} else if !errors.Is(err, ErrPointerNotFound) {
+		return nil, err
+	}
+
+	p := &types.BoardPointer{
+		BoardID:      boardID,
+		Seq:          seq,
+		BoardMetaCID: cid,
+		ValidUntil:   time.Now().UTC().Add(ttl).Format(time.RFC3339),
+	}
+	if err := signature.SignBoardPointer(priv, p); err != nil {
+		return nil, err
+	}
+	if _, err := s.SaveBoardPointer(ctx, p); err != nil {
+		return nil, err
+	}
+
+	if s.PointerCache != nil {
+		s.PointerCache.set(pubStr, boardID, pointerCacheEntry{cid: p.BoardMetaCID, seq: p.Seq, found: true})
+	}
+	return p, nil
+}
+
+// ResolveBoardPointer finds the BoardPointer with the highest Seq that
+// pubKey has published for boardID, rejecting any record whose signature
+// doesn't verify or whose ValidUntil has passed. If s.PointerCache is set,
+// a resolution (including a negative one) is served from and fed back
+// into the cache, so repeated resolution of the same pubKey+boardID
+// doesn't re-scan flex-ipfs every time.
+func (s *Storage) ResolveBoardPointer(ctx context.Context, pubKey, boardID string) (cid string, seq int64, err error) {
+	if s.PointerCache != nil {
+		if entry, ok := s.PointerCache.get(pubKey, boardID); ok {
+			if !entry.found {
+				return "", 0, ErrPointerNotFound
+			}
+			return entry.cid, entry.seq, nil
+		}
+	}
+
+	cid, seq, err = s.resolveBoardPointerUncached(ctx, pubKey, boardID)
+	if s.PointerCache == nil {
+		return cid, seq, err
+	}
+	switch {
+	case err == nil:
+		s.PointerCache.set(pubKey, boardID, pointerCacheEntry{cid: cid, seq: seq, found: true})
+	case errors.Is(err, ErrPointerNotFound):
+		s.PointerCache.set(pubKey, boardID, pointerCacheEntry{found: false})
+	}
+	return cid, seq, err
+}
+
+func (s *Storage) resolveBoardPointerUncached(ctx context.Context, pubKey, boardID string) (string, int64, error) {
+	cids, err := s.ListBoardPointerCIDs(ctx, boardID, pubKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := time.Now().UTC()
+	var best *types.BoardPointer
+	for _, c := range cids {
+		p, err := s.LoadBoardPointer(ctx, c)
+		if err != nil {
+			continue
+		}
+		if p.PubKey != pubKey || p.BoardID != boardID {
+			continue
+		}
+		if !bbslog.VerifyBoardPointer(p) {
+			continue
+		}
+		eol, err := time.Parse(time.RFC3339, p.ValidUntil)
+		if err != nil || now.After(eol) {
+			continue
+		}
+		if best == nil || p.Seq > best.Seq {
+			best = p
+		}
+	}
+	if best == nil {
+		return "", 0, ErrPointerNotFound
+	}
+	return best.BoardMetaCID, best.Seq, nil
+}
+
+// pointerCacheEntry is what PointerCache stores per pubKey+boardID: either
+// a resolved (cid, seq) pair, or found=false recording that resolution
+// came up empty (negative caching), so a board author who hasn't
+// published a pointer yet doesn't cause a flex-ipfs scan on every lookup.
+type pointerCacheEntry struct {
+	cid   string
+	seq   int64
+	found bool
+}
+
+// PointerCache is a bounded, TTL-expiring LRU cache for ResolveBoardPointer
+// results, keyed by pubKey+boardID. Storage.PointerCache is nil by default
+// (matching CIDVerifier/Events' opt-in convention): set it via
+// NewPointerCache to avoid re-scanning flex-ipfs for every resolution of
+// the same pointer.
+type PointerCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type pointerCacheItem struct {
+	key     string
+	entry   pointerCacheEntry
+	expires time.Time
+}
+
+// NewPointerCache returns a PointerCache holding at most capacity entries
+// (oldest evicted first once full) with each entry valid for ttl.
+func NewPointerCache(capacity int, ttl time.Duration) *PointerCache {
+	return &PointerCache{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func pointerCacheKey(pubKey, boardID string) string {
+	return pubKey + "\x00" + boardID
+}
+
+func (c *PointerCache) get(pubKey, boardID string) (pointerCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := pointerCacheKey(pubKey, boardID)
+	el, ok := c.items[key]
+	if !ok {
+		return pointerCacheEntry{}, false
+	}
+	item := el.Value.(*pointerCacheItem)
+	if time.Now().After(item.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return pointerCacheEntry{}, false
+	}
+	c.ll.MoveToFront(el)
+	return item.entry, true
+}
+
+func (c *PointerCache) set(pubKey, boardID string, entry pointerCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := pointerCacheKey(pubKey, boardID)
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*pointerCacheItem)
+		item.entry = entry
+		item.expires = time.Now().Add(c.ttl)
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&pointerCacheItem{key: key, entry: entry, expires: time.Now().Add(c.ttl)})
+	c.items[key] = el
+	if c.capacity > 0 && c.ll.Len() > c.capacity {
+		if oldest := c.ll.Back(); oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*pointerCacheItem).key)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+// newFakePointerFlex starts a fake Flexible-IPFS server supporting put,
+// get, and getbyattrs, the three calls PublishBoardPointer/
+// ResolveBoardPointer need — unlike newFakeContentAddressedFlex
+// (export_test.go), which never needs getbyattrs because ExportBoard
+// walks a known CID chain rather than searching by tag.
+func newFakePointerFlex(t *testing.T) *Storage {
+	t.Helper()
+	type record struct {
+		value string
+		tags  []string
+	}
+	var (
+		mu    sync.Mutex
+		byCID = map[string]record{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/peerlist":
+			_ = json.NewEncoder(w).Encode("peer1")
+		case "/api/v0/dht/putvaluewithattr":
+			q := r.URL.Query()
+			value := q.Get("value")
+			sum := sha256.Sum256([]byte(value))
+			cid := "baf_" + hex.EncodeToString(sum[:8])
+			mu.Lock()
+			byCID[cid] = record{value: value, tags: strings.Split(q.Get("tags"), ",")}
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": cid})
+		case "/api/v0/dht/getvalue":
+			cid := r.URL.Query().Get("cid")
+			mu.Lock()
+			rec, ok := byCID[cid]
+			mu.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(rec.value)
+		case "/api/v0/dht/getbyattrs":
+			wantTag := r.URL.Query().Get("tags")
+			var matches []string
+			mu.Lock()
+			for cid, rec := range byCID {
+				for _, tag := range rec.tags {
+					if tag == wantTag {
+						matches = append(matches, cid)
+						break
+					}
+				}
+			}
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(matches)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return New(flexipfs.New(srv.URL + "/api/v0"))
+}
+
+func TestPublishBoardPointerThenResolveFindsIt(t *testing.T) {
+	st := newFakePointerFlex(t)
+	ctx := context.Background()
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	p, err := st.PublishBoardPointer(ctx, priv, "bbs.general", "baf_meta_1", time.Hour)
+	if err != nil {
+		t.Fatalf("PublishBoardPointer: %v", err)
+	}
+	if p.Seq != 0 {
+		t.Fatalf("expected first publish to start at seq 0, got %d", p.Seq)
+	}
+
+	cid, seq, err := st.ResolveBoardPointer(ctx, p.PubKey, "bbs.general")
+	if err != nil {
+		t.Fatalf("ResolveBoardPointer: %v", err)
+	}
+	if cid != "baf_meta_1" || seq != 0 {
+		t.Fatalf("resolved (%s, %d), want (baf_meta_1, 0)", cid, seq)
+	}
+}
+
+func TestPublishBoardPointerIncrementsSeqAndWinsOnResolve(t *testing.T) {
+	st := newFakePointerFlex(t)
+	ctx := context.Background()
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	if _, err := st.PublishBoardPointer(ctx, priv, "bbs.general", "baf_meta_1", time.Hour); err != nil {
+		t.Fatalf("PublishBoardPointer (1): %v", err)
+	}
+	p2, err := st.PublishBoardPointer(ctx, priv, "bbs.general", "baf_meta_2", time.Hour)
+	if err != nil {
+		t.Fatalf("PublishBoardPointer (2): %v", err)
+	}
+	if p2.Seq != 1 {
+		t.Fatalf("expected second publish to bump seq to 1, got %d", p2.Seq)
+	}
+
+	cid, seq, err := st.ResolveBoardPointer(ctx, p2.PubKey, "bbs.general")
+	if err != nil {
+		t.Fatalf("ResolveBoardPointer: %v", err)
+	}
+	if cid != "baf_meta_2" || seq != 1 {
+		t.Fatalf("resolved (%s, %d), want (baf_meta_2, 1)", cid, seq)
+	}
+}
+
+func TestResolveBoardPointerUnknownPubKeyReturnsNotFound(t *testing.T) {
+	st := newFakePointerFlex(t)
+	if _, _, err := st.ResolveBoardPointer(context.Background(), "ed25519:doesnotexist", "bbs.general"); err != ErrPointerNotFound {
+		t.Fatalf("expected ErrPointerNotFound, got %v", err)
+	}
+}
+
+func TestResolveBoardPointerRejectsExpiredRecord(t *testing.T) {
+	st := newFakePointerFlex(t)
+	ctx := context.Background()
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	p, err := st.PublishBoardPointer(ctx, priv, "bbs.general", "baf_meta_1", -time.Hour)
+	if err != nil {
+		t.Fatalf("PublishBoardPointer: %v", err)
+	}
+
+	if _, _, err := st.ResolveBoardPointer(ctx, p.PubKey, "bbs.general"); err != ErrPointerNotFound {
+		t.Fatalf("expected ErrPointerNotFound for an expired record, got %v", err)
+	}
+}
+
+func TestResolveBoardPointerUsesCache(t *testing.T) {
+	st := newFakePointerFlex(t)
+	st.PointerCache = NewPointerCache(16, time.Minute)
+	ctx := context.Background()
+	_, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	p, err := st.PublishBoardPointer(ctx, priv, "bbs.general", "baf_meta_1", time.Hour)
+	if err != nil {
+		t.Fatalf("PublishBoardPointer: %v", err)
+	}
+
+	// PublishBoardPointer's own seq-continuation lookup ran against an
+	// empty cache and scanned flex-ipfs; the publish then stored its new
+	// pointer in the cache, so this resolve is served from the cache.
+	cid, seq, err := st.ResolveBoardPointer(ctx, p.PubKey, "bbs.general")
+	if err != nil {
+		t.Fatalf("ResolveBoardPointer: %v", err)
+	}
+	if cid != "baf_meta_1" || seq != 0 {
+		t.Fatalf("resolved (%s, %d), want (baf_meta_1, 0)", cid, seq)
+	}
+
+	// A negative resolution should also be cached.
+	if _, _, err := st.ResolveBoardPointer(ctx, "ed25519:doesnotexist", "bbs.general"); err != ErrPointerNotFound {
+		t.Fatalf("expected ErrPointerNotFound, got %v", err)
+	}
+	if entry, ok := st.PointerCache.get("ed25519:doesnotexist", "bbs.general"); !ok || entry.found {
+		t.Fatalf("expected negative cache entry, got ok=%v entry=%+v", ok, entry)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"unicode/utf16"
 
+	"flex-bbs/backend-go/bbs/events"
 	"flex-bbs/backend-go/bbs/flexipfs"
 	"flex-bbs/backend-go/bbs/types"
 )
@@ -19,16 +20,48 @@ const (
 	AttrObjTypeThreadMetaV1    = "threadmeta_1"
 	AttrObjTypeBoardLogEntryV1 = "boardlogentry_1"
 	AttrObjTypePostV1          = "post_1"
+	AttrObjTypeBoardPointerV1  = "boardpointer_1"
 )
 
 type Storage struct {
 	Flex *flexipfs.Client
+
+	// CIDVerifier, if non-nil, enables local content-address verification
+	// of every value loadJSON fetches; see CIDVerifier's own doc comment.
+	// Nil (the default returned by New) leaves loadJSON's existing
+	// trust-the-server behavior unchanged.
+	CIDVerifier *CIDVerifier
+
+	// Events, if non-nil, receives a PostCreated/ThreadCreated/
+	// BoardMetaUpdated/BoardLogAppended event after each corresponding
+	// Save* method's DHT put succeeds. Nil (the default returned by New)
+	// leaves saving side-effect-free, matching CIDVerifier's opt-in
+	// convention.
+	Events events.Bus
+
+	// PointerCache, if non-nil, caches ResolveBoardPointer results (see
+	// pointer.go); nil (the default returned by New) leaves resolution
+	// side-effect-free, matching CIDVerifier/Events' opt-in convention.
+	PointerCache *PointerCache
+
+	readDeadline  deadline
+	writeDeadline deadline
 }
 
 func New(flex *flexipfs.Client) *Storage {
 	return &Storage{Flex: flex}
 }
 
+// publish fans out e on s.Events if one is configured, swallowing the
+// error: a dropped or unavailable event bus shouldn't fail the save whose
+// content has already landed safely in flex-ipfs.
+func (s *Storage) publish(ctx context.Context, e events.Event) {
+	if s.Events == nil {
+		return
+	}
+	_ = s.Events.Publish(ctx, e)
+}
+
 func TagBoard(boardID string) string {
 	return fmt.Sprintf("board_%s", boardID)
 }
@@ -37,8 +70,22 @@ func TagBoardThread(boardID, threadID string) string {
 	return fmt.Sprintf("board_%s-thread_%s", boardID, threadID)
 }
 
+// TagBoardPointer tags a BoardPointer so Storage.ListBoardPointerCIDs can
+// find every record a pubkey has published for boardID via GetByAttrs,
+// without the flexipfs backend needing a put-at-a-fixed-key primitive (it
+// only has content-addressed put/get): the tag plays the role a named key
+// would in a true IPNS implementation.
+func TagBoardPointer(boardID, pubKey string) string {
+	return fmt.Sprintf("pointer_%s-%s", boardID, pubKey)
+}
+
 func (s *Storage) SavePost(ctx context.Context, boardID string, p *types.Post) (string, error) {
-	return s.saveJSON(ctx, []string{AttrObjTypePostV1}, []string{TagBoardThread(boardID, p.ThreadID)}, p)
+	cid, err := s.saveJSON(ctx, []string{AttrObjTypePostV1}, []string{TagBoardThread(boardID, p.ThreadID)}, p)
+	if err != nil {
+		return "", err
+	}
+	s.publish(ctx, events.Event{Type: events.PostCreated, BoardID: boardID, ThreadID: p.ThreadID, CID: cid, AuthorPubKey: p.AuthorPubKey, CreatedAt: p.CreatedAt})
+	return cid, nil
 }
 
 func (s *Storage) LoadPost(ctx context.Context, cid string) (*types.Post, error) {
@@ -55,6 +102,7 @@ func (s *Storage) SaveThreadMeta(ctx context.Context, m *types.ThreadMeta) (stri
 		return "", err
 	}
 	_, _ = s.saveJSON(ctx, []string{AttrObjTypeThreadMetaV1}, []string{TagBoardThread(m.BoardID, cid)}, m)
+	s.publish(ctx, events.Event{Type: events.ThreadCreated, BoardID: m.BoardID, ThreadID: m.ThreadID, CID: cid, AuthorPubKey: m.CreatedBy, CreatedAt: m.CreatedAt})
 	return cid, nil
 }
 
@@ -67,7 +115,12 @@ func (s *Storage) LoadThreadMeta(ctx context.Context, cid string) (*types.Thread
 }
 
 func (s *Storage) SaveBoardMeta(ctx context.Context, m *types.BoardMeta) (string, error) {
-	return s.saveJSON(ctx, []string{AttrObjTypeBoardMetaV1}, []string{TagBoard(m.BoardID)}, m)
+	cid, err := s.saveJSON(ctx, []string{AttrObjTypeBoardMetaV1}, []string{TagBoard(m.BoardID)}, m)
+	if err != nil {
+		return "", err
+	}
+	s.publish(ctx, events.Event{Type: events.BoardMetaUpdated, BoardID: m.BoardID, CID: cid, AuthorPubKey: m.CreatedBy, CreatedAt: m.CreatedAt})
+	return cid, nil
 }
 
 func (s *Storage) LoadBoardMeta(ctx context.Context, cid string) (*types.BoardMeta, error) {
@@ -79,7 +132,12 @@ func (s *Storage) LoadBoardMeta(ctx context.Context, cid string) (*types.BoardMe
 }
 
 func (s *Storage) SaveBoardLogEntry(ctx context.Context, e *types.BoardLogEntry) (string, error) {
-	return s.saveJSON(ctx, []string{AttrObjTypeBoardLogEntryV1}, []string{TagBoardThread(e.BoardID, e.ThreadID)}, e)
+	cid, err := s.saveJSON(ctx, []string{AttrObjTypeBoardLogEntryV1}, []string{TagBoardThread(e.BoardID, e.ThreadID)}, e)
+	if err != nil {
+		return "", err
+	}
+	s.publish(ctx, events.Event{Type: events.BoardLogAppended, BoardID: e.BoardID, ThreadID: e.ThreadID, CID: cid, AuthorPubKey: e.AuthorPubKey, CreatedAt: e.CreatedAt})
+	return cid, nil
 }
 
 func (s *Storage) LoadBoardLogEntry(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
@@ -90,35 +148,63 @@ func (s *Storage) LoadBoardLogEntry(ctx context.Context, cid string) (*types.Boa
 	return &e, nil
 }
 
+func (s *Storage) SaveBoardPointer(ctx context.Context, p *types.BoardPointer) (string, error) {
+	return s.saveJSON(ctx, []string{AttrObjTypeBoardPointerV1}, []string{TagBoardPointer(p.BoardID, p.PubKey)}, p)
+}
+
+func (s *Storage) LoadBoardPointer(ctx context.Context, cid string) (*types.BoardPointer, error) {
+	var p types.BoardPointer
+	if err := s.loadJSON(ctx, cid, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListBoardPointerCIDs returns every BoardPointer CID published under
+// pubKey for boardID, in no particular order: ResolveBoardPointer loads
+// and verifies each to find the one with the highest Seq.
+func (s *Storage) ListBoardPointerCIDs(ctx context.Context, boardID, pubKey string) ([]string, error) {
+	return s.Flex.GetByAttrs(ctx, []string{AttrObjTypeBoardPointerV1}, []string{TagBoardPointer(boardID, pubKey)}, false)
+}
+
 func (s *Storage) saveJSON(ctx context.Context, attrs []string, tags []string, v any) (string, error) {
 	b, err := json.Marshal(v)
 	if err != nil {
 		return "", err
 	}
 	b = escapeJSONNonASCII(b)
+
+	ctx, release := s.writeDeadline.wrap(ctx)
+	defer release()
 	return s.Flex.PutValueWithAttr(ctx, string(b), attrs, tags)
 }
 
 func (s *Storage) loadJSON(ctx context.Context, cid string, out any) error {
+	ctx, release := s.readDeadline.wrap(ctx)
+	defer release()
+
 	b, err := s.Flex.GetValue(ctx, cid)
 	if err != nil {
+		if flexipfs.IsNotFound(err) {
+			return &CIDError{CID: cid, Err: fmt.Errorf("%w: %v", ErrNotFound, err)}
+		}
 		return err
 	}
 
+	if s.CIDVerifier != nil {
+		b, err = s.verifyLoadedCID(ctx, cid, b)
+		if err != nil {
+			return err
+		}
+	}
+
 	tryUnmarshal := func(payload []byte) error {
 		if err := json.Unmarshal(payload, out); err != nil {
 			trim := bytes.TrimSpace(payload)
 			// Only include a short preview when the payload clearly isn't JSON (e.g. "Not Found"),
 			// to avoid leaking post bodies into logs/errors.
 			if len(trim) > 0 && trim[0] != '{' && trim[0] != '[' {
-				preview := trim
-				if len(preview) > 160 {
-					preview = preview[:160]
-				}
-				preview = bytes.ReplaceAll(preview, []byte{'\r'}, []byte{' '})
-				preview = bytes.ReplaceAll(preview, []byte{'\n'}, []byte{' '})
-				preview = bytes.ReplaceAll(preview, []byte{'\t'}, []byte{' '})
-				return fmt.Errorf("unmarshal cid=%s: %w (value_preview=%q)", cid, err, string(preview))
+				return fmt.Errorf("unmarshal cid=%s: %w (value_preview=%q)", cid, err, previewBytes(trim))
 			}
 			return fmt.Errorf("unmarshal cid=%s: %w", cid, err)
 		}
@@ -127,20 +213,39 @@ func (s *Storage) loadJSON(ctx context.Context, cid string, out any) error {
 
 	if err := tryUnmarshal(b); err != nil {
 		// Flexible-IPFS may write a corrupted/incomplete getdata cache file and refuse to overwrite it.
-		// Retry once by forcing a fresh fetch (invalidates any existing <cid>.txt cache file first).
+		// Retry once by forcing a fresh fetch (invalidates any existing <cid>.txt cache file first) —
+		// but only if a caller's WithBudget deadline hasn't already expired; spending a round-trip we
+		// already know will be cancelled just obscures the real error behind a context one.
+		if cerr := ctx.Err(); cerr != nil {
+			return &CIDError{CID: cid, Preview: previewBytes(bytes.TrimSpace(b)), Err: fmt.Errorf("%w: %v (budget exhausted before fresh refetch: %v)", ErrCorruptPayload, err, cerr)}
+		}
 		fresh, ferr := s.Flex.GetValueFresh(ctx, cid)
 		if ferr != nil {
-			return fmt.Errorf("%v (fresh fetch failed: %w)", err, ferr)
+			return &CIDError{CID: cid, Preview: previewBytes(bytes.TrimSpace(b)), Err: fmt.Errorf("%w: %v (fresh fetch failed: %v)", ErrCorruptPayload, err, ferr)}
 		}
-		uerr := tryUnmarshal(fresh)
-		if uerr == nil {
-			return nil
+		if uerr := tryUnmarshal(fresh); uerr != nil {
+			return &CIDError{CID: cid, Preview: previewBytes(bytes.TrimSpace(fresh)), Err: fmt.Errorf("%w: %v (after fresh fetch: %v)", ErrCorruptPayload, err, uerr)}
 		}
-		return fmt.Errorf("%v (after fresh fetch: %v)", err, uerr)
+		return nil
 	}
 	return nil
 }
 
+// previewBytes trims and flattens b to a short, single-line snippet
+// suitable for embedding in an error message, so operators get a clue
+// about what a bad payload actually was without dumping its full content
+// into logs/errors.
+func previewBytes(b []byte) string {
+	preview := bytes.TrimSpace(b)
+	if len(preview) > 160 {
+		preview = preview[:160]
+	}
+	preview = bytes.ReplaceAll(preview, []byte{'\r'}, []byte{' '})
+	preview = bytes.ReplaceAll(preview, []byte{'\n'}, []byte{' '})
+	preview = bytes.ReplaceAll(preview, []byte{'\t'}, []byte{' '})
+	return string(preview)
+}
+
 func escapeJSONNonASCII(b []byte) []byte {
 	for _, c := range b {
 		if c >= 0x80 {
@@ -8,7 +8,9 @@ import (
 	"net/url"
 	"sync"
 	"testing"
+	"time"
 
+	"flex-bbs/backend-go/bbs/events"
 	"flex-bbs/backend-go/bbs/flexipfs"
 	"flex-bbs/backend-go/bbs/types"
 )
@@ -88,3 +90,61 @@ func TestSaveThreadMeta_DoublePutTags(t *testing.T) {
 		t.Fatalf("value not encodable: %v", err)
 	}
 }
+
+func TestSavePost_PublishesEventOnSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/dht/putvaluewithattr":
+			_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": "baf_post"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	bus := events.NewInProcessBus(0)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ch, err := bus.Subscribe(ctx, events.Filter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	st := New(flexipfs.New(srv.URL + "/api/v0"))
+	st.Events = bus
+
+	p := &types.Post{
+		ThreadID:     "thread_1",
+		AuthorPubKey: "ed25519:pub",
+		Body:         types.PostBody{Format: "plain", Content: "hi"},
+		CreatedAt:    "2025-01-01T00:00:00Z",
+	}
+	cid, err := st.SavePost(context.Background(), "bbs.general", p)
+	if err != nil {
+		t.Fatalf("SavePost: %v", err)
+	}
+
+	select {
+	case e := <-ch:
+		if e.Type != events.PostCreated || e.BoardID != "bbs.general" || e.ThreadID != "thread_1" || e.CID != cid {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for PostCreated event")
+	}
+}
+
+func TestSavePost_NoEventsBusIsANoop(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"CID_file": "baf_post"})
+	}))
+	t.Cleanup(srv.Close)
+
+	st := New(flexipfs.New(srv.URL + "/api/v0"))
+	p := &types.Post{ThreadID: "thread_1", AuthorPubKey: "ed25519:pub", Body: types.PostBody{Format: "plain", Content: "hi"}, CreatedAt: "2025-01-01T00:00:00Z"}
+	if _, err := st.SavePost(context.Background(), "bbs.general", p); err != nil {
+		t.Fatalf("SavePost: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// WalkedKind identifies which object kind a WalkBoard visit call is
+// reporting on.
+type WalkedKind string
+
+const (
+	WalkedBoardMeta     WalkedKind = "boardMeta"
+	WalkedBoardLogEntry WalkedKind = "boardLogEntry"
+	WalkedThreadMeta    WalkedKind = "threadMeta"
+	WalkedPost          WalkedKind = "post"
+)
+
+// WalkBoard visits every CID reachable from a board's BoardMeta: the
+// BoardMeta itself, its full BoardLogEntry chain, and every ThreadMeta/Post
+// CID the chain references. It's ExportBoard's traversal (see export.go)
+// generalized for callers that want to do something other than write CAR
+// blocks — bbs/republisher uses it to re-pin/re-provide everything a board
+// still references without needing a throwaway archive writer. visit order
+// matches ExportBoard's write order: BoardMeta first, then each log entry
+// interleaved with the ThreadMeta/Post CIDs it references.
+//
+// Unlike ExportBoard, WalkBoard does not load or re-verify the ThreadMeta/
+// Post bodies behind each referenced CID — a caller that only needs the CID
+// (to pin or re-provide it, say) would otherwise pay for a fetch it
+// immediately discards. BoardMeta and the log chain are still loaded and
+// verified, since WalkBoard needs their contents to find the rest of the
+// CIDs in the first place.
+//
+// A visit error aborts the walk and is returned as-is, the same way a
+// write error aborts ExportBoard.
+func (s *Storage) WalkBoard(ctx context.Context, boardMetaCID string, visit func(cid string, kind WalkedKind) error) error {
+	bm, err := s.LoadBoardMeta(ctx, boardMetaCID)
+	if err != nil {
+		return fmt.Errorf("storage: walk: load board meta %s: %w", boardMetaCID, err)
+	}
+	if !bbslog.VerifyBoardMeta(bm) {
+		return fmt.Errorf("storage: walk: board meta %s has an invalid signature", boardMetaCID)
+	}
+	if err := visit(boardMetaCID, WalkedBoardMeta); err != nil {
+		return err
+	}
+
+	loadLog := func(ctx context.Context, cid string) (*types.BoardLogEntry, error) {
+		return s.LoadBoardLogEntry(ctx, cid)
+	}
+	chain, err := bbslog.FetchChain(ctx, bm.LogHeadCID, loadLog, func(e *types.BoardLogEntry) *string {
+		return e.PrevLogCID
+	}, bbslog.VerifyBoardLogEntry, 50_000)
+	if err != nil {
+		return fmt.Errorf("storage: walk: fetch board log chain: %w", err)
+	}
+
+	seenThreads := make(map[string]struct{})
+	for _, item := range chain {
+		if item.Value == nil {
+			continue
+		}
+		if err := visit(item.CID, WalkedBoardLogEntry); err != nil {
+			return err
+		}
+		if item.Value.ThreadID != "" {
+			if _, ok := seenThreads[item.Value.ThreadID]; !ok {
+				seenThreads[item.Value.ThreadID] = struct{}{}
+				if err := visit(item.Value.ThreadID, WalkedThreadMeta); err != nil {
+					return err
+				}
+			}
+		}
+		for _, cid := range []*string{item.Value.PostCID, item.Value.OldPostCID, item.Value.NewPostCID, item.Value.TargetPostCID} {
+			if cid == nil || *cid == "" {
+				continue
+			}
+			if err := visit(*cid, WalkedPost); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
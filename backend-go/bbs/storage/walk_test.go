@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/types"
+)
+
+func TestWalkBoard_VisitsEveryReachableCIDInOrder(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeContentAddressedFlex(t)
+	bmCID := seedBoard(t, st)
+
+	var kinds []WalkedKind
+	var cids []string
+	err := st.WalkBoard(ctx, bmCID, func(cid string, kind WalkedKind) error {
+		kinds = append(kinds, kind)
+		cids = append(cids, cid)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkBoard: %v", err)
+	}
+
+	want := []WalkedKind{WalkedBoardMeta, WalkedBoardLogEntry, WalkedThreadMeta, WalkedPost}
+	if len(kinds) != len(want) {
+		t.Fatalf("visited %d CIDs, want %d: %+v", len(kinds), len(want), kinds)
+	}
+	for i, k := range want {
+		if kinds[i] != k {
+			t.Fatalf("visit[%d] kind = %q, want %q", i, kinds[i], k)
+		}
+	}
+	if cids[0] != bmCID {
+		t.Fatalf("first visited CID = %q, want board meta CID %q", cids[0], bmCID)
+	}
+}
+
+func TestWalkBoard_StopsOnVisitError(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeContentAddressedFlex(t)
+	bmCID := seedBoard(t, st)
+
+	boom := errors.New("boom")
+	calls := 0
+	err := st.WalkBoard(ctx, bmCID, func(cid string, kind WalkedKind) error {
+		calls++
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("WalkBoard error = %v, want %v", err, boom)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the walk to stop after the first visit error, got %d calls", calls)
+	}
+}
+
+func TestWalkBoard_RejectsInvalidBoardMetaSignature(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeContentAddressedFlex(t)
+
+	bm := &types.BoardMeta{
+		Version:   types.Version1,
+		Type:      types.TypeBoardMeta,
+		BoardID:   "bbs.walk-invalid",
+		Title:     "Unsigned",
+		CreatedAt: "2025-01-01T00:00:00Z",
+		CreatedBy: "author1",
+		Signature: "not-a-real-signature",
+	}
+	bmCID, err := st.SaveBoardMeta(ctx, bm)
+	if err != nil {
+		t.Fatalf("SaveBoardMeta: %v", err)
+	}
+
+	if err := st.WalkBoard(ctx, bmCID, func(string, WalkedKind) error { return nil }); err == nil {
+		t.Fatal("expected WalkBoard to reject a board meta with an invalid signature")
+	}
+}
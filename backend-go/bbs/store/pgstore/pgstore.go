@@ -0,0 +1,144 @@
+// Package pgstore implements bbs/indexer's Store interface against
+// Postgres, for deployments that have outgrown a single sqlite file (no
+// concurrent writers, no replica, no managed backups) but still want the
+// same log-replay/fork-detection logic in bbs/indexer driving the writes.
+//
+// Unlike sqlite, Postgres happily accepts concurrent writers, so callers
+// are not limited to db.SetMaxOpenConns(1); size the pool for the
+// deployment as usual.
+//
+// The schema this package expects is the Postgres-dialect equivalent of
+// bbs/indexer/migrations.go's InitialSchema and BoardBranches migrations
+// (boards, threads, posts, thread_posts, processed_logs, board_branches).
+// migrations.go's DDL is sqlite-flavored (AUTOINCREMENT, STRICT tables)
+// and is not run against this backend; a Postgres deployment is expected
+// to apply the equivalent schema out of band until a dialect-aware
+// migration runner exists. The sqlite-only subsystems built on top of
+// that schema — fork/branch bookkeeping in fork.go, FTS5 search in
+// search.go — are out of scope here; this package only backs the
+// boards/threads/posts/thread_posts/processed_logs write path behind
+// indexer.Store.
+package pgstore
+
+import (
+	"context"
+
+	"flex-bbs/backend-go/bbs/indexer"
+	"flex-bbs/backend-go/bbs/types"
+)
+
+// Store is a Postgres-dialect implementation of indexer.Store.
+type Store struct{}
+
+var _ indexer.Store = Store{}
+
+func (Store) UpsertBoard(ctx context.Context, q indexer.Querier, cid string, bm *types.BoardMeta) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO boards(board_id, board_meta_cid, title, description, created_at, created_by, signature, log_head_cid)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT(board_id) DO UPDATE SET
+			board_meta_cid=excluded.board_meta_cid,
+			title=excluded.title,
+			description=excluded.description,
+			created_at=excluded.created_at,
+			created_by=excluded.created_by,
+			signature=excluded.signature,
+			log_head_cid=excluded.log_head_cid
+	`, bm.BoardID, cid, bm.Title, bm.Description, bm.CreatedAt, bm.CreatedBy, bm.Signature, bm.LogHeadCID)
+	return err
+}
+
+func (Store) UpsertThread(ctx context.Context, q indexer.Querier, threadCID string, tm *types.ThreadMeta) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO threads(thread_id, board_id, title, root_post_cid, created_at, created_by, signature)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT(thread_id) DO UPDATE SET
+			board_id=excluded.board_id,
+			title=excluded.title,
+			root_post_cid=excluded.root_post_cid,
+			created_at=excluded.created_at,
+			created_by=excluded.created_by,
+			signature=excluded.signature
+	`, threadCID, tm.BoardID, tm.Title, tm.RootPostCID, tm.CreatedAt, tm.CreatedBy, tm.Signature)
+	return err
+}
+
+func (Store) UpsertPost(ctx context.Context, q indexer.Querier, postCID string, p *types.Post) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO posts(post_cid, thread_id, parent_post_cid, author_pubkey, display_name, body_format, body_content, created_at, edited_at, signature)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT(post_cid) DO UPDATE SET
+			thread_id=excluded.thread_id,
+			parent_post_cid=excluded.parent_post_cid,
+			author_pubkey=excluded.author_pubkey,
+			display_name=excluded.display_name,
+			body_format=excluded.body_format,
+			body_content=excluded.body_content,
+			created_at=excluded.created_at,
+			edited_at=excluded.edited_at,
+			signature=excluded.signature
+	`, postCID, p.ThreadID, p.ParentPostCID, p.AuthorPubKey, p.DisplayName, p.Body.Format, p.Body.Content, p.CreatedAt, p.EditedAt, p.Signature)
+	return err
+}
+
+func (Store) AppendThreadPost(ctx context.Context, q indexer.Querier, threadID, postCID string) error {
+	var nextOrdinal int
+	if err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(ordinal), -1) + 1 FROM thread_posts WHERE thread_id = $1`, threadID).Scan(&nextOrdinal); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO thread_posts (thread_id, ordinal, post_cid, tombstoned)
+		VALUES ($1, $2, $3, false)
+		ON CONFLICT(thread_id, ordinal) DO NOTHING
+	`, threadID, nextOrdinal, postCID)
+	return err
+}
+
+func (Store) UpdatePostCID(ctx context.Context, q indexer.Querier, threadID, oldPostCID, newPostCID string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE thread_posts
+		SET post_cid = $1
+		WHERE thread_id = $2 AND post_cid = $3
+	`, newPostCID, threadID, oldPostCID)
+	return err
+}
+
+func (Store) Tombstone(ctx context.Context, q indexer.Querier, threadID, postCID, reason, createdAt, authorPubKey string) error {
+	var reasonArg any
+	if reason != "" {
+		reasonArg = reason
+	}
+	_, err := q.ExecContext(ctx, `
+		UPDATE thread_posts
+		SET tombstoned = true, tombstone_reason = $1, tombstone_created_at = $2, tombstone_author_pubkey = $3
+		WHERE thread_id = $4 AND post_cid = $5
+	`, reasonArg, createdAt, authorPubKey, threadID, postCID)
+	return err
+}
+
+func (Store) IsLogProcessed(ctx context.Context, q indexer.Querier, logCID string) (bool, error) {
+	var n int
+	if err := q.QueryRowContext(ctx, `SELECT COUNT(1) FROM processed_logs WHERE log_cid = $1`, logCID).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (Store) InsertProcessedLog(ctx context.Context, q indexer.Querier, logCID string, e *types.BoardLogEntry, validSig bool) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO processed_logs(log_cid, board_id, thread_id, op, created_at, author_pubkey, prev_log_cid, valid_sig)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT(log_cid) DO NOTHING
+	`, logCID, e.BoardID, e.ThreadID, e.Op, e.CreatedAt, e.AuthorPubKey, e.PrevLogCID, validSig)
+	return err
+}
+
+func (Store) SetBoardLogHead(ctx context.Context, q indexer.Querier, boardID, headCID string) error {
+	_, err := q.ExecContext(ctx, `UPDATE boards SET log_head_cid = $1 WHERE board_id = $2`, headCID, boardID)
+	return err
+}
+
+func (Store) Prune(ctx context.Context, q indexer.Querier, cutoff string) error {
+	_, err := q.ExecContext(ctx, `DELETE FROM processed_logs WHERE created_at < $1`, cutoff)
+	return err
+}
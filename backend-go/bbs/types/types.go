@@ -1,21 +1,58 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-bbs/backend-go/bbs/crypto"
+)
 
 const (
 	Version1 = 1
+	// Version2 signs payloads using RFC 8785 JSON Canonicalization Scheme
+	// (see signature.CanonicalJSON) instead of the legacy key=value encoding.
+	Version2 = 2
+	// Version3 signs payloads using the same key=value encoding as
+	// Version1, but canonicalized with flex-canon/v2 (see
+	// signature.CanonicalPostPayloadV2 and siblings) instead of
+	// flex-canon/v1: values are backslash-escaped so a '\n' inside a field
+	// like displayName or body.content can't collide with the field
+	// separator. It's a new revision of the legacy scheme, not a
+	// replacement for Version2's unrelated JCS encoding.
+	Version3 = 3
 
 	TypePost          = "post"
 	TypeThreadMeta    = "threadMeta"
 	TypeBoardMeta     = "boardMeta"
 	TypeBoardLogEntry = "boardLogEntry"
+	TypeBoardPointer  = "boardPointer"
 
 	OpCreateThread  = "createThread"
 	OpAddPost       = "addPost"
 	OpEditPost      = "editPost"
 	OpTombstonePost = "tombstonePost"
+
+	// BoardMeta.EncryptionPolicy values. EncryptionPolicyNone is the zero
+	// value/default, matching a board that predates this field.
+	EncryptionPolicyNone     = "none"
+	EncryptionPolicyOptional = "optional"
+	EncryptionPolicyRequired = "required"
+
+	// BodyFormatEncPrefix prefixes Post.Body.Format on an encrypted post:
+	// Body.Content holds a crypto.Envelope's JSON rather than content in
+	// the named inner format. See Post.Encrypt/Decrypt.
+	BodyFormatEncPrefix = "enc+"
 )
 
+// IsEncryptedBodyFormat reports whether format names an encrypted body, as
+// Post.Encrypt leaves it (Body.Content is then a crypto.Envelope, not
+// plain format content).
+func IsEncryptedBodyFormat(format string) bool {
+	return strings.HasPrefix(format, BodyFormatEncPrefix)
+}
+
 type PostBody struct {
 	Format  string `json:"format"`
 	Content string `json:"content"`
@@ -24,6 +61,15 @@ type PostBody struct {
 type Attachment struct {
 	CID  string `json:"cid"`
 	Mime string `json:"mime"`
+
+	// Size and SHA256 are the uploader's own claims about the blob behind
+	// CID, carried alongside it so a signature over this Attachment (and
+	// therefore the Post/edit it's attached to) commits to more than just
+	// the CID string. Either may be left zero/empty to skip that half of
+	// validateAttachments' cross-check — CID alone is still sufficient to
+	// resolve the blob.
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 type Post struct {
@@ -64,7 +110,13 @@ type BoardMeta struct {
 	LogHeadCID  *string `json:"logHeadCid"`
 	CreatedAt   string  `json:"createdAt"`
 	CreatedBy   string  `json:"createdBy"`
-	Signature   string  `json:"signature"`
+	// EncryptionPolicy governs whether Posts under this board may/must
+	// encrypt Body (see Post.Encrypt): one of EncryptionPolicyNone,
+	// EncryptionPolicyOptional, or EncryptionPolicyRequired. Indexer
+	// enforces it when applying a board's log (bbs/indexer); an empty
+	// value behaves as EncryptionPolicyNone.
+	EncryptionPolicy string `json:"encryptionPolicy,omitempty"`
+	Signature        string `json:"signature"`
 }
 
 type BoardLogEntry struct {
@@ -84,6 +136,124 @@ type BoardLogEntry struct {
 	Signature     string  `json:"signature"`
 }
 
+// BoardPointer is a signed, mutable pointer from a board author's pubkey to
+// that board's current BoardMetaCID, modeled on IPNS: a board's CID changes
+// every time its meta or log head is updated, so clients that only know the
+// author's pubkey (not the latest CID) resolve it through the highest-Seq
+// BoardPointer that pubkey has published for BoardID (see
+// storage.Storage.PublishBoardPointer/ResolveBoardPointer). ValidUntil bounds
+// how long a resolver may trust a cached record without seeing a fresher one
+// republished; it carries no legacy version, so it's always signed with JCS
+// (see signature.SignBoardPointer).
+type BoardPointer struct {
+	Version      int    `json:"version"`
+	Type         string `json:"type"`
+	BoardID      string `json:"boardId"`
+	Seq          int64  `json:"seq"`
+	BoardMetaCID string `json:"boardMetaCid"`
+	ValidUntil   string `json:"validUntil"`
+	PubKey       string `json:"pubKey"`
+	Signature    string `json:"signature"`
+}
+
 func NowUTC() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
+
+// SignedView exposes the subset of a model's fields that a signature
+// covers, as a plain map ready for RFC 8785 canonicalization (see
+// signature.Canonicalize). It excludes whichever fields aren't part of
+// the signed payload — the signature itself, and, for Post, the postCid
+// that's only known once the post has been addressed by its own content.
+type SignedView interface {
+	SignedFields() (map[string]any, error)
+}
+
+func signedFieldsOf(v any, drop ...string) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for _, k := range drop {
+		delete(m, k)
+	}
+	return m, nil
+}
+
+func (p *Post) SignedFields() (map[string]any, error) {
+	return signedFieldsOf(p, "signature", "postCid")
+}
+
+// Encrypt replaces p.Body with a crypto.Envelope sealing the current
+// body.content under ring's active key, turning body.format into
+// "enc+<format>" (see BodyFormatEncPrefix). The envelope's associated
+// data binds threadId, postCid (or "" if not yet assigned), and
+// createdAt, so ciphertext sealed for this post can't be replayed into
+// another thread or post. Call Encrypt before signing: the signature
+// covers Body as stored, ciphertext included, so verification doesn't
+// need the key either.
+func (p *Post) Encrypt(ring *crypto.KeyRing) error {
+	if IsEncryptedBodyFormat(p.Body.Format) {
+		return fmt.Errorf("post already encrypted (format %q)", p.Body.Format)
+	}
+	env, err := crypto.Seal(ring, []byte(p.Body.Content), p.encryptionAD())
+	if err != nil {
+		return err
+	}
+	envJSON, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	p.Body = PostBody{Format: BodyFormatEncPrefix + p.Body.Format, Content: string(envJSON)}
+	return nil
+}
+
+// Decrypt reverses Encrypt: it returns the inner format and plaintext
+// content once ring can open the envelope and its associated data still
+// matches this exact post.
+func (p *Post) Decrypt(ring *crypto.KeyRing) (format, content string, err error) {
+	inner, ok := strings.CutPrefix(p.Body.Format, BodyFormatEncPrefix)
+	if !ok {
+		return "", "", fmt.Errorf("post is not encrypted (format %q)", p.Body.Format)
+	}
+	var env crypto.Envelope
+	if err := json.Unmarshal([]byte(p.Body.Content), &env); err != nil {
+		return "", "", fmt.Errorf("decode envelope: %w", err)
+	}
+	pt, err := crypto.Open(ring, env, p.encryptionAD())
+	if err != nil {
+		return "", "", err
+	}
+	return inner, string(pt), nil
+}
+
+// encryptionAD is the associated data Encrypt/Decrypt bind ciphertext to:
+// threadId || postCid-or-empty || createdAt, NUL-separated so the three
+// fields can't be confused by concatenation alone.
+func (p *Post) encryptionAD() []byte {
+	postCID := ""
+	if p.PostCID != nil {
+		postCID = *p.PostCID
+	}
+	return []byte(p.ThreadID + "\x00" + postCID + "\x00" + p.CreatedAt)
+}
+
+func (m *ThreadMeta) SignedFields() (map[string]any, error) {
+	return signedFieldsOf(m, "signature")
+}
+
+func (m *BoardMeta) SignedFields() (map[string]any, error) {
+	return signedFieldsOf(m, "signature")
+}
+
+func (e *BoardLogEntry) SignedFields() (map[string]any, error) {
+	return signedFieldsOf(e, "signature")
+}
+
+func (p *BoardPointer) SignedFields() (map[string]any, error) {
+	return signedFieldsOf(p, "signature")
+}
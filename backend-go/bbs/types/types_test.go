@@ -0,0 +1,99 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/crypto"
+)
+
+func testKeyRing(t *testing.T) *crypto.KeyRing {
+	t.Helper()
+	ring, err := crypto.NewKeyRing("k1", bytes.Repeat([]byte{0x11}, crypto.KeySize))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestPost_EncryptDecrypt_RoundTrip(t *testing.T) {
+	postCID := "post-1"
+	p := &Post{
+		ThreadID:  "thread-1",
+		PostCID:   &postCID,
+		CreatedAt: "2025-01-01T00:00:00Z",
+		Body:      PostBody{Format: "markdown", Content: "hello"},
+	}
+	ring := testKeyRing(t)
+
+	if err := p.Encrypt(ring); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if p.Body.Format != "enc+markdown" {
+		t.Fatalf("Body.Format = %q, want %q", p.Body.Format, "enc+markdown")
+	}
+	if !IsEncryptedBodyFormat(p.Body.Format) {
+		t.Fatalf("IsEncryptedBodyFormat(%q) = false, want true", p.Body.Format)
+	}
+
+	format, content, err := p.Decrypt(ring)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if format != "markdown" || content != "hello" {
+		t.Fatalf("Decrypt = (%q, %q), want (%q, %q)", format, content, "markdown", "hello")
+	}
+}
+
+func TestPost_Decrypt_RejectsReplayIntoAnotherThread(t *testing.T) {
+	postCID := "post-1"
+	p := &Post{
+		ThreadID:  "thread-1",
+		PostCID:   &postCID,
+		CreatedAt: "2025-01-01T00:00:00Z",
+		Body:      PostBody{Format: "markdown", Content: "hello"},
+	}
+	ring := testKeyRing(t)
+	if err := p.Encrypt(ring); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	replayed := *p
+	replayed.ThreadID = "thread-2"
+	if _, _, err := replayed.Decrypt(ring); err == nil {
+		t.Fatalf("expected Decrypt to reject ciphertext replayed into a different thread")
+	}
+}
+
+func TestPost_Decrypt_RejectsUnencryptedBody(t *testing.T) {
+	p := &Post{Body: PostBody{Format: "markdown", Content: "hello"}}
+	if _, _, err := p.Decrypt(testKeyRing(t)); err == nil {
+		t.Fatalf("expected Decrypt to reject a post that was never encrypted")
+	}
+}
+
+func TestPost_Encrypt_RejectsDoubleEncryption(t *testing.T) {
+	p := &Post{Body: PostBody{Format: "markdown", Content: "hello"}}
+	ring := testKeyRing(t)
+	if err := p.Encrypt(ring); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if err := p.Encrypt(ring); err == nil {
+		t.Fatalf("expected a second Encrypt to be rejected")
+	}
+}
+
+func TestIsEncryptedBodyFormat(t *testing.T) {
+	cases := map[string]bool{
+		"markdown":     false,
+		"plain":        false,
+		"enc+markdown": true,
+		"enc+plain":    true,
+		"":             false,
+	}
+	for format, want := range cases {
+		if got := IsEncryptedBodyFormat(format); got != want {
+			t.Errorf("IsEncryptedBodyFormat(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -29,25 +31,62 @@ type FlexIPFSClient interface {
 	// PutValue は属性なしで値をDHTに格納します（基本的なDHT put）。
 	PutValue(ctx context.Context, key string, value []byte) error
 
+	// PutSignedValue は payload を priv で署名したエンベロープとして格納します。
+	// 署名・公開鍵・連番は予約attrs（_sig/_pubkey/_seq）に格納されるため、
+	// 既存の PutValueWithAttr 基盤をそのまま利用できます。
+	PutSignedValue(ctx context.Context, key string, payload []byte, attrs map[string]string, tags []string, priv ed25519.PrivateKey) error
+
+	// GetSignedValue は PutSignedValue で格納された値を取得し、署名を検証した
+	// 上でpayloadを返します。expectedPubKey が非nilの場合、埋め込まれた公開鍵が
+	// 一致することも確認します。
+	GetSignedValue(ctx context.Context, key string, expectedPubKey ed25519.PublicKey) ([]byte, error)
+
 	// FindProviders は指定されたキーのプロバイダーを検索します。
 	FindProviders(ctx context.Context, key string) (*FlexFindProvidersResponse, error)
 
+	// FindProvidersStream は FindProviders のストリーミング版です。バックエンドが
+	// NDJSONで応答を返せる場合、DHTウォークの完了を待たずにレコードが届き次第
+	// チャネルに流します。ctx がキャンセルされるかレスポンスがEOFに達すると
+	// チャネルはcloseされます。
+	FindProvidersStream(ctx context.Context, key string) (<-chan FlexProviderEvent, error)
+
 	// Provide はこのノードが指定されたキーを提供できることをアナウンスします。
 	Provide(ctx context.Context, key string) error
 
 	// PeerList はDHT内のピアのリストを取得します。
 	PeerList(ctx context.Context) (*FlexPeerListResponse, error)
 
+	// GetIPNS は指定された名前のIPNSレコードを取得します。
+	GetIPNS(ctx context.Context, name string) (*FlexIPNSRecord, error)
+
+	// PutIPNS は指定された名前にIPNSレコードを発行します。
+	PutIPNS(ctx context.Context, name string, record *FlexIPNSRecord) error
+
 	// BaseURL は Flexible-IPFS API のベースURLを返します。
 	BaseURL() string
 }
 
+// FlexIPNSRecord は /routing/v1/ipns/{name} でやり取りされるIPNSレコードです。
+// Value はシリアライズされたレコード本体（application/vnd.ipfs.ipns-record）を表します。
+type FlexIPNSRecord struct {
+	Name  string `json:"name"`
+	Value []byte `json:"value"`
+}
+
 // FlexGetValueResponse は /dht/getvalue からのレスポンスを表します。
 type FlexGetValueResponse struct {
 	Value []byte            `json:"value"`
 	Attrs map[string]string `json:"attrs,omitempty"`
 	Tags  []string          `json:"tags,omitempty"`
 	Type  string            `json:"type,omitempty"` // デコードされた型情報
+
+	// Verified and Signer are populated automatically when Attrs carries a
+	// PutSignedValue envelope (_sig/_pubkey/_seq): Verified reports whether
+	// the signature checked out against the embedded public key, and Signer
+	// is that key, base64-encoded. Both are zero for ordinary,
+	// unsigned values.
+	Verified bool   `json:"-"`
+	Signer   string `json:"-"`
 }
 
 // FlexFindProvidersResponse は /dht/findprovs からのレスポンスを表します。
@@ -61,6 +100,15 @@ type FlexPeer struct {
 	Addrs []string `json:"addrs,omitempty"`
 }
 
+// FlexProviderEvent is one record (or decode error) emitted by
+// FindProvidersStream. Err is set instead of Peer when a single record on the
+// stream fails to decode; that doesn't terminate the stream, since later
+// records downstream may still decode fine.
+type FlexProviderEvent struct {
+	Peer FlexPeer
+	Err  error
+}
+
 // FlexPeerListResponse は /dht/peerlist からのレスポンスを表します。
 type FlexPeerListResponse struct {
 	Peers []FlexPeer `json:"peers"`
@@ -83,6 +131,13 @@ func (e *FlexErrorResponse) Error() string {
 type FlexClientError struct {
 	Op  string // 操作名
 	Err error  // 元となるエラー
+
+	// Attempts is how many HTTP attempts were made before giving up (1 if
+	// retries are disabled or the first attempt's error wasn't retryable).
+	Attempts int
+	// LastStatus is the HTTP status code of the final attempt, or 0 if it
+	// never got a response (connect/timeout error, or the breaker was open).
+	LastStatus int
 }
 
 func (e *FlexClientError) Error() string {
@@ -101,24 +156,57 @@ func (e *FlexClientError) Unwrap() error {
 type httpFlexIPFSClient struct {
 	baseURL    string
 	httpClient *http.Client
+
+	resilience clientResilience
+	breakers   map[string]*circuitBreaker
+	breakersMu sync.Mutex
 }
 
 // NewFlexIPFSClient は新しい Flexible-IPFS HTTPクライアントを作成します。
-func NewFlexIPFSClient(baseURL string) FlexIPFSClient {
-	return &httpFlexIPFSClient{
+// opts で WithRetryPolicy / WithPerOpTimeout / WithCircuitBreaker /
+// WithOnRetry / WithOnCircuitOpen を渡すと、読み取り系メソッドの自動リトライ
+// や書き込み系メソッドの5xx限定リトライ、サーキットブレーカーを有効化できます。
+// 何も渡さなければ従来どおり1回きりの呼び出しです。
+func NewFlexIPFSClient(baseURL string, opts ...ClientOption) FlexIPFSClient {
+	c := &httpFlexIPFSClient{
 		baseURL: strings.TrimRight(baseURL, "/"),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		breakers: make(map[string]*circuitBreaker),
 	}
+	for _, opt := range opts {
+		opt(&c.resilience)
+	}
+	return c
 }
 
 // NewFlexIPFSClientWithHTTPClient はカスタムHTTPクライアントを使用してクライアントを作成します。
-func NewFlexIPFSClientWithHTTPClient(baseURL string, httpClient *http.Client) FlexIPFSClient {
-	return &httpFlexIPFSClient{
+func NewFlexIPFSClientWithHTTPClient(baseURL string, httpClient *http.Client, opts ...ClientOption) FlexIPFSClient {
+	c := &httpFlexIPFSClient{
 		baseURL:    strings.TrimRight(baseURL, "/"),
 		httpClient: httpClient,
+		breakers:   make(map[string]*circuitBreaker),
+	}
+	for _, opt := range opts {
+		opt(&c.resilience)
 	}
+	return c
+}
+
+// timeoutFor returns the per-op timeout configured via WithPerOpTimeout for
+// op, or zero if none was set (meaning: use c.httpClient's own timeout).
+func (c *httpFlexIPFSClient) timeoutFor(op string) time.Duration {
+	return c.resilience.perOpTimeout[op]
+}
+
+// withOpTimeout returns ctx bounded by op's per-op timeout, if one is
+// configured, along with the cancel func to defer.
+func (c *httpFlexIPFSClient) withOpTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	if d := c.timeoutFor(op); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
 }
 
 func (c *httpFlexIPFSClient) BaseURL() string {
@@ -126,183 +214,265 @@ func (c *httpFlexIPFSClient) BaseURL() string {
 }
 
 func (c *httpFlexIPFSClient) PutValueWithAttr(ctx context.Context, key string, value []byte, attrs map[string]string, tags []string) error {
+	const op = "PutValueWithAttr"
 	endpoint := c.baseURL + "/dht/putvaluewithattr"
 
-	// マルチパートフォームを作成
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+	ctx, cancel := c.withOpTimeout(ctx, op)
+	defer cancel()
 
-	// キーを追加
-	if err := writer.WriteField("arg", key); err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
-	}
+	attempt := func(ctx context.Context) (int, error) {
+		// マルチパートフォームを作成（リトライのたびに新しいボディを作り直す）
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
 
-	// 値をファイルパートとして追加
-	part, err := writer.CreateFormFile("file", "value")
-	if err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
-	}
-	if _, err := part.Write(value); err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
-	}
-
-	// 属性を追加
-	if len(attrs) > 0 {
-		attrsJSON, err := json.Marshal(attrs)
+		if err := writer.WriteField("arg", key); err != nil {
+			return 0, err
+		}
+		part, err := writer.CreateFormFile("file", "value")
 		if err != nil {
-			return &FlexClientError{Op: "PutValueWithAttr", Err: fmt.Errorf("marshal attrs: %w", err)}
+			return 0, err
 		}
-		if err := writer.WriteField("attrs", string(attrsJSON)); err != nil {
-			return &FlexClientError{Op: "PutValueWithAttr", Err: err}
+		if _, err := part.Write(value); err != nil {
+			return 0, err
 		}
-	}
-
-	// タグを追加
-	if len(tags) > 0 {
-		tagsJSON, err := json.Marshal(tags)
-		if err != nil {
-			return &FlexClientError{Op: "PutValueWithAttr", Err: fmt.Errorf("marshal tags: %w", err)}
+		if len(attrs) > 0 {
+			attrsJSON, err := json.Marshal(attrs)
+			if err != nil {
+				return 0, fmt.Errorf("marshal attrs: %w", err)
+			}
+			if err := writer.WriteField("attrs", string(attrsJSON)); err != nil {
+				return 0, err
+			}
 		}
-		if err := writer.WriteField("tags", string(tagsJSON)); err != nil {
-			return &FlexClientError{Op: "PutValueWithAttr", Err: err}
+		if len(tags) > 0 {
+			tagsJSON, err := json.Marshal(tags)
+			if err != nil {
+				return 0, fmt.Errorf("marshal tags: %w", err)
+			}
+			if err := writer.WriteField("tags", string(tagsJSON)); err != nil {
+				return 0, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return 0, err
 		}
-	}
-
-	if err := writer.Close(); err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
-	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
-	if err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &FlexClientError{Op: "PutValueWithAttr", Err: err}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return resp.StatusCode, c.parseErrorResponse(resp, op)
+		}
+		return resp.StatusCode, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return c.parseErrorResponse(resp, "PutValueWithAttr")
+	// Writes only retry on a failure that couldn't have reached the server
+	// (no bytes sent) or on 502/503/504, never on an ambiguous outcome that
+	// might mean the value was already stored.
+	retryable := func(err error, status int) bool {
+		if status == 0 {
+			return isRetryableReadErr(err) || errors.Is(err, errCircuitOpen)
+		}
+		return isRetryableWriteStatus(status)
 	}
 
-	return nil
+	status, attempts, err := withRetry(ctx, &c.resilience, c.breakers, &c.breakersMu, op, retryable, attempt)
+	return wrapFlexError(err, op, attempts, status)
 }
 
 func (c *httpFlexIPFSClient) GetValue(ctx context.Context, key string) (*FlexGetValueResponse, error) {
+	const op = "GetValue"
 	endpoint := c.baseURL + "/dht/getvalue"
 
-	params := url.Values{}
-	params.Set("arg", key)
+	ctx, cancel := c.withOpTimeout(ctx, op)
+	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, &FlexClientError{Op: "GetValue", Err: err}
-	}
+	var out *FlexGetValueResponse
+	attempt := func(ctx context.Context) (int, error) {
+		params := url.Values{}
+		params.Set("arg", key)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, &FlexClientError{Op: "GetValue", Err: err}
-	}
-	defer resp.Body.Close()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
+		if err != nil {
+			return 0, err
+		}
 
-	if resp.StatusCode >= 400 {
-		return nil, c.parseErrorResponse(resp, "GetValue")
-	}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, &FlexClientError{Op: "GetValue", Err: err}
-	}
+		if resp.StatusCode >= 400 {
+			return resp.StatusCode, c.parseErrorResponse(resp, op)
+		}
 
-	// まずJSON（attrs/tagsを含む構造化されたレスポンス）としてパースを試みる
-	var result FlexGetValueResponse
-	if err := json.Unmarshal(body, &result); err == nil && result.Value != nil {
-		// 属性から型を推論
-		if result.Attrs != nil {
-			if t, ok := result.Attrs["type"]; ok {
-				result.Type = t
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+
+		// まずJSON（attrs/tagsを含む構造化されたレスポンス）としてパースを試みる
+		var result FlexGetValueResponse
+		if err := json.Unmarshal(body, &result); err == nil && result.Value != nil {
+			if result.Attrs != nil {
+				if t, ok := result.Attrs["type"]; ok {
+					result.Type = t
+				}
+				if verified, signer, vErr := verifySignedEnvelope(key, result.Value, result.Attrs); vErr == nil {
+					result.Verified, result.Signer = verified, signer
+				}
+			}
+			if vErr := verifyCID(key, result.Value); vErr != nil {
+				return resp.StatusCode, vErr
 			}
+			out = &result
+			return resp.StatusCode, nil
 		}
-		return &result, nil
+
+		// フォールバック: 生データとして扱う
+		if vErr := verifyCID(key, body); vErr != nil {
+			return resp.StatusCode, vErr
+		}
+		out = &FlexGetValueResponse{Value: body}
+		return resp.StatusCode, nil
 	}
 
-	// フォールバック: 生データとして扱う
-	return &FlexGetValueResponse{
-		Value: body,
-	}, nil
+	status, attempts, err := withRetry(ctx, &c.resilience, c.breakers, &c.breakersMu, op, getValueRetryable, attempt)
+	if err != nil {
+		return nil, wrapFlexError(err, op, attempts, status)
+	}
+	return out, nil
 }
 
 func (c *httpFlexIPFSClient) PutValue(ctx context.Context, key string, value []byte) error {
+	const op = "PutValue"
 	endpoint := c.baseURL + "/dht/putvalue"
 
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+	ctx, cancel := c.withOpTimeout(ctx, op)
+	defer cancel()
 
-	if err := writer.WriteField("arg", key); err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
-	}
-
-	part, err := writer.CreateFormFile("file", "value")
-	if err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
-	}
-	if _, err := part.Write(value); err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
-	}
+	attempt := func(ctx context.Context) (int, error) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
 
-	if err := writer.Close(); err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
-	}
+		if err := writer.WriteField("arg", key); err != nil {
+			return 0, err
+		}
+		part, err := writer.CreateFormFile("file", "value")
+		if err != nil {
+			return 0, err
+		}
+		if _, err := part.Write(value); err != nil {
+			return 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return 0, err
+		}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
-	if err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &FlexClientError{Op: "PutValue", Err: err}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return resp.StatusCode, c.parseErrorResponse(resp, op)
+		}
+		return resp.StatusCode, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return c.parseErrorResponse(resp, "PutValue")
+	retryable := func(err error, status int) bool {
+		if status == 0 {
+			return isRetryableReadErr(err) || errors.Is(err, errCircuitOpen)
+		}
+		return isRetryableWriteStatus(status)
 	}
 
-	return nil
+	status, attempts, err := withRetry(ctx, &c.resilience, c.breakers, &c.breakersMu, op, retryable, attempt)
+	return wrapFlexError(err, op, attempts, status)
 }
 
 func (c *httpFlexIPFSClient) FindProviders(ctx context.Context, key string) (*FlexFindProvidersResponse, error) {
+	const op = "FindProviders"
 	endpoint := c.baseURL + "/dht/findprovs"
 
-	params := url.Values{}
-	params.Set("arg", key)
+	ctx, cancel := c.withOpTimeout(ctx, op)
+	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, &FlexClientError{Op: "FindProviders", Err: err}
-	}
+	var out *FlexFindProvidersResponse
+	attempt := func(ctx context.Context) (int, error) {
+		params := url.Values{}
+		params.Set("arg", key)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, &FlexClientError{Op: "FindProviders", Err: err}
-	}
-	defer resp.Body.Close()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
+		if err != nil {
+			return 0, err
+		}
 
-	if resp.StatusCode >= 400 {
-		return nil, c.parseErrorResponse(resp, "FindProviders")
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return resp.StatusCode, c.parseErrorResponse(resp, op)
+		}
+
+		var result FlexFindProvidersResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return resp.StatusCode, err
+		}
+		out = &result
+		return resp.StatusCode, nil
 	}
 
-	var result FlexFindProvidersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, &FlexClientError{Op: "FindProviders", Err: err}
+	status, attempts, err := withRetry(ctx, &c.resilience, c.breakers, &c.breakersMu, op, idempotentReadRetryable, attempt)
+	if err != nil {
+		return nil, wrapFlexError(err, op, attempts, status)
 	}
+	return out, nil
+}
 
-	return &result, nil
+// FindProvidersStream は Flexible-IPFS の /dht/findprovs が単発レスポンスしか
+// 返さないため、FindProviders を呼んだ結果を丸ごとチャネルに流すだけの
+// フォールバック実装です。真のストリーミングが欲しい場合は
+// NewDelegatedRoutingClient を使ってください。
+func (c *httpFlexIPFSClient) FindProvidersStream(ctx context.Context, key string) (<-chan FlexProviderEvent, error) {
+	ch := make(chan FlexProviderEvent)
+	go func() {
+		defer close(ch)
+		resp, err := c.FindProviders(ctx, key)
+		if err != nil {
+			select {
+			case ch <- FlexProviderEvent{Err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		for _, p := range resp.Providers {
+			select {
+			case ch <- FlexProviderEvent{Peer: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
 }
 
 func (c *httpFlexIPFSClient) Provide(ctx context.Context, key string) error {
@@ -330,29 +500,78 @@ func (c *httpFlexIPFSClient) Provide(ctx context.Context, key string) error {
 }
 
 func (c *httpFlexIPFSClient) PeerList(ctx context.Context) (*FlexPeerListResponse, error) {
+	const op = "PeerList"
 	endpoint := c.baseURL + "/dht/peerlist"
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
-	if err != nil {
-		return nil, &FlexClientError{Op: "PeerList", Err: err}
+	ctx, cancel := c.withOpTimeout(ctx, op)
+	defer cancel()
+
+	var out *FlexPeerListResponse
+	attempt := func(ctx context.Context) (int, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return resp.StatusCode, c.parseErrorResponse(resp, op)
+		}
+
+		var result FlexPeerListResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return resp.StatusCode, err
+		}
+		out = &result
+		return resp.StatusCode, nil
 	}
 
-	resp, err := c.httpClient.Do(req)
+	status, attempts, err := withRetry(ctx, &c.resilience, c.breakers, &c.breakersMu, op, idempotentReadRetryable, attempt)
 	if err != nil {
-		return nil, &FlexClientError{Op: "PeerList", Err: err}
+		return nil, wrapFlexError(err, op, attempts, status)
 	}
-	defer resp.Body.Close()
+	return out, nil
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, c.parseErrorResponse(resp, "PeerList")
-	}
+func (c *httpFlexIPFSClient) PutSignedValue(ctx context.Context, key string, payload []byte, attrs map[string]string, tags []string, priv ed25519.PrivateKey) error {
+	return putSignedValue(ctx, c, key, payload, attrs, tags, priv)
+}
 
-	var result FlexPeerListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, &FlexClientError{Op: "PeerList", Err: err}
-	}
+func (c *httpFlexIPFSClient) GetSignedValue(ctx context.Context, key string, expectedPubKey ed25519.PublicKey) ([]byte, error) {
+	return getSignedValue(ctx, c, key, expectedPubKey)
+}
+
+func (c *httpFlexIPFSClient) GetIPNS(ctx context.Context, name string) (*FlexIPNSRecord, error) {
+	// Flexible-IPFS の /dht/* APIにはIPNSの概念がないため、このバックエンドでは未対応です。
+	return nil, &FlexClientError{Op: "GetIPNS", Err: fmt.Errorf("flexipfs does not support IPNS; use NewDelegatedRoutingClient instead")}
+}
 
-	return &result, nil
+func (c *httpFlexIPFSClient) PutIPNS(ctx context.Context, name string, record *FlexIPNSRecord) error {
+	return &FlexClientError{Op: "PutIPNS", Err: fmt.Errorf("flexipfs does not support IPNS; use NewDelegatedRoutingClient instead")}
+}
+
+// wrapFlexError attaches attempts/status bookkeeping to the error a retry
+// loop gave up on. *FlexErrorResponse (a protocol-level error the server
+// itself reported) is returned unchanged, since Attempts/LastStatus live on
+// FlexClientError specifically and callers already type-assert on
+// *FlexErrorResponse to recognize server-reported failures.
+func wrapFlexError(err error, op string, attempts, status int) error {
+	if err == nil {
+		return nil
+	}
+	if fe, ok := err.(*FlexClientError); ok {
+		fe.Attempts, fe.LastStatus = attempts, status
+		return fe
+	}
+	if _, ok := err.(*FlexErrorResponse); ok {
+		return err
+	}
+	return &FlexClientError{Op: op, Err: err, Attempts: attempts, LastStatus: status}
 }
 
 func (c *httpFlexIPFSClient) parseErrorResponse(resp *http.Response, op string) error {
@@ -380,6 +599,7 @@ type MockFlexIPFSClient struct {
 
 	baseURL string
 	storage map[string]*mockStorageEntry
+	ipns    map[string]*FlexIPNSRecord
 
 	// エラーシナリオをテストするためのフック関数
 	PutValueWithAttrFunc func(ctx context.Context, key string, value []byte, attrs map[string]string, tags []string) error
@@ -388,6 +608,13 @@ type MockFlexIPFSClient struct {
 	FindProvidersFunc    func(ctx context.Context, key string) (*FlexFindProvidersResponse, error)
 	ProvideFunc          func(ctx context.Context, key string) error
 	PeerListFunc         func(ctx context.Context) (*FlexPeerListResponse, error)
+
+	// FindProvidersStreamFunc overrides FindProvidersStream entirely when set.
+	FindProvidersStreamFunc func(ctx context.Context, key string) (<-chan FlexProviderEvent, error)
+	// StreamDelay is the inter-record delay FindProvidersStream's default
+	// implementation sleeps between emitting providers, to let tests exercise
+	// cancellation and slow-producer behavior without a real DHT walk.
+	StreamDelay time.Duration
 }
 
 type mockStorageEntry struct {
@@ -401,6 +628,7 @@ func NewMockFlexIPFSClient(baseURL string) *MockFlexIPFSClient {
 	return &MockFlexIPFSClient{
 		baseURL: baseURL,
 		storage: make(map[string]*mockStorageEntry),
+		ipns:    make(map[string]*FlexIPNSRecord),
 	}
 }
 
@@ -475,10 +703,21 @@ func (m *MockFlexIPFSClient) GetValue(ctx context.Context, key string) (*FlexGet
 	if t, ok := attrsCopy["type"]; ok {
 		result.Type = t
 	}
+	if verified, signer, err := verifySignedEnvelope(key, result.Value, result.Attrs); err == nil {
+		result.Verified, result.Signer = verified, signer
+	}
 
 	return result, nil
 }
 
+func (m *MockFlexIPFSClient) PutSignedValue(ctx context.Context, key string, payload []byte, attrs map[string]string, tags []string, priv ed25519.PrivateKey) error {
+	return putSignedValue(ctx, m, key, payload, attrs, tags, priv)
+}
+
+func (m *MockFlexIPFSClient) GetSignedValue(ctx context.Context, key string, expectedPubKey ed25519.PublicKey) ([]byte, error) {
+	return getSignedValue(ctx, m, key, expectedPubKey)
+}
+
 func (m *MockFlexIPFSClient) PutValue(ctx context.Context, key string, value []byte) error {
 	if m.PutValueFunc != nil {
 		return m.PutValueFunc(ctx, key, value)
@@ -512,6 +751,37 @@ func (m *MockFlexIPFSClient) FindProviders(ctx context.Context, key string) (*Fl
 	}, nil
 }
 
+func (m *MockFlexIPFSClient) FindProvidersStream(ctx context.Context, key string) (<-chan FlexProviderEvent, error) {
+	if m.FindProvidersStreamFunc != nil {
+		return m.FindProvidersStreamFunc(ctx, key)
+	}
+
+	resp, err := m.FindProviders(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan FlexProviderEvent)
+	go func() {
+		defer close(ch)
+		for _, p := range resp.Providers {
+			if m.StreamDelay > 0 {
+				select {
+				case <-time.After(m.StreamDelay):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case ch <- FlexProviderEvent{Peer: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 func (m *MockFlexIPFSClient) Provide(ctx context.Context, key string) error {
 	if m.ProvideFunc != nil {
 		return m.ProvideFunc(ctx, key)
@@ -541,6 +811,29 @@ func (m *MockFlexIPFSClient) PeerList(ctx context.Context) (*FlexPeerListRespons
 	}, nil
 }
 
+func (m *MockFlexIPFSClient) GetIPNS(ctx context.Context, name string) (*FlexIPNSRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rec, ok := m.ipns[name]
+	if !ok {
+		return nil, &FlexErrorResponse{Message: "ipns record not found", Code: "NOT_FOUND"}
+	}
+	valueCopy := make([]byte, len(rec.Value))
+	copy(valueCopy, rec.Value)
+	return &FlexIPNSRecord{Name: rec.Name, Value: valueCopy}, nil
+}
+
+func (m *MockFlexIPFSClient) PutIPNS(ctx context.Context, name string, record *FlexIPNSRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	valueCopy := make([]byte, len(record.Value))
+	copy(valueCopy, record.Value)
+	m.ipns[name] = &FlexIPNSRecord{Name: name, Value: valueCopy}
+	return nil
+}
+
 // GetStorageKeys はモックストレージ内の全てのキーを返します（テスト用）。
 func (m *MockFlexIPFSClient) GetStorageKeys() []string {
 	m.mu.RLock()
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// --- API: GET /api/v1/boards/{boardId}/events ---
+
+// handleBoardEventsSSE streams boardID's BoardLogEntry appends as
+// Server-Sent Events, mirroring handleThreadEventsSSE's shape
+// (heartbeatInterval comment lines, ctx tied to the request). It differs
+// from the thread-events stream in two ways the request log requires:
+//
+//   - it's backed by logs.Hub, an in-process pub/sub hub with bounded,
+//     drop-oldest-on-overflow per-subscriber buffers (see
+//     board_log_hub.go), rather than a poll loop;
+//   - resuming after a disconnect is by CID, not SeqNum: a client sends
+//     back either the standard SSE "Last-Event-ID" header or a ?cursor=
+//     query parameter with the last CID it saw, and the stream replays
+//     logs.List's backlog after that CID before switching to live
+//     updates from the hub.
+//
+// Returns 501 if logs is nil (board logs not configured) or the
+// connection doesn't support flushing.
+func handleBoardEventsSSE(w http.ResponseWriter, r *http.Request, boardID string, logs *BoardLogStore) {
+	if logs == nil || logs.Hub == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Subscribe before reading the backlog so nothing published between
+	// here and the List call below is missed.
+	ch, unsubscribe := logs.Hub.Subscribe(ctx, boardID)
+	defer unsubscribe()
+
+	backlog, err := logs.List(boardID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("cursor")
+	}
+	start := 0
+	if cursor != "" {
+		for i, e := range backlog {
+			if e.CID == cursor {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Entries we've already sent from the backlog, so a duplicate
+	// delivered live (appended after Subscribe but already captured by
+	// the List call above) isn't written twice.
+	seen := make(map[string]struct{}, len(backlog)-start)
+	for _, e := range backlog[start:] {
+		writeBoardLogSSEEvent(w, e)
+		flusher.Flush()
+		seen[e.CID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, dup := seen[e.CID]; dup {
+				delete(seen, e.CID)
+				continue
+			}
+			writeBoardLogSSEEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func writeBoardLogSSEEvent(w http.ResponseWriter, e BoardLogEntryWithCID) {
+	b, err := json.Marshal(e.Entry)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", e.CID, b)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleBoardEventsSSE_NilLogsReturnsNotImplemented(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/boards/board-1/events", nil)
+	w := httptest.NewRecorder()
+	handleBoardEventsSSE(w, r, "board-1", nil)
+
+	if w.Result().StatusCode != 501 {
+		t.Fatalf("status=%d, want 501", w.Result().StatusCode)
+	}
+}
+
+func TestHandleBoardEventsSSE_NoHubReturnsNotImplemented(t *testing.T) {
+	store := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+
+	r := httptest.NewRequest("GET", "/api/v1/boards/board-1/events", nil)
+	w := httptest.NewRecorder()
+	handleBoardEventsSSE(w, r, "board-1", store)
+
+	if w.Result().StatusCode != 501 {
+		t.Fatalf("status=%d, want 501", w.Result().StatusCode)
+	}
+}
+
+func TestHandleBoardEventsSSE_StreamsBacklogThenLiveAppend(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	store := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	store.Hub = NewBoardLogHub()
+
+	e1 := signedBoardLogEntry(t, pub, priv, "board-1", "createThread", nil)
+	cid1, err := store.AppendEntry("board-1", e1)
+	if err != nil {
+		t.Fatalf("AppendEntry(1): %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/api/v1/boards/board-1/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handleBoardEventsSSE(w, r, "board-1", store)
+	}()
+
+	// Give the handler time to subscribe and replay the backlog before
+	// appending a second entry, so we can assert on both.
+	time.Sleep(50 * time.Millisecond)
+	e2 := signedBoardLogEntry(t, pub, priv, "board-1", "addPost", &cid1)
+	if _, err := store.AppendEntry("board-1", e2); err != nil {
+		t.Fatalf("AppendEntry(2): %v", err)
+	}
+
+	<-done
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"op":"createThread"`) {
+		t.Fatalf("body missing backlog entry: %s", body)
+	}
+	if !strings.Contains(body, `"op":"addPost"`) {
+		t.Fatalf("body missing live-appended entry: %s", body)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("Content-Type=%q, want text/event-stream", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandleBoardEventsSSE_ResumesAfterCursor(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	store := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	store.Hub = NewBoardLogHub()
+
+	e1 := signedBoardLogEntry(t, pub, priv, "board-1", "createThread", nil)
+	cid1, err := store.AppendEntry("board-1", e1)
+	if err != nil {
+		t.Fatalf("AppendEntry(1): %v", err)
+	}
+	e2 := signedBoardLogEntry(t, pub, priv, "board-1", "addPost", &cid1)
+	if _, err := store.AppendEntry("board-1", e2); err != nil {
+		t.Fatalf("AppendEntry(2): %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/api/v1/boards/board-1/events", nil).WithContext(ctx)
+	r.Header.Set("Last-Event-ID", cid1)
+	w := httptest.NewRecorder()
+
+	handleBoardEventsSSE(w, r, "board-1", store)
+
+	body := w.Body.String()
+	if strings.Contains(body, `"op":"createThread"`) {
+		t.Fatalf("body replayed entry before the resume cursor: %s", body)
+	}
+	if !strings.Contains(body, `"op":"addPost"`) {
+		t.Fatalf("body missing entry after resume cursor: %s", body)
+	}
+}
@@ -1,24 +1,18 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"flex-bbs/backend-go/cmd/bbs-node/canon"
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
 // --- API: POST /api/v1/posts/{postCid}/edit (#20: edit post) ---
-//
-// 差し替えメモ:
-// 合体時にテストを使わない(リポジトリに残さない)運用なら、以下を削除してOK。
-// - cmd/bbs-node/api_v1_posts_edit_test.go
-// - このファイル内の seedPostForTests
 
 // editPostRequest は POST /api/v1/posts/{postCid}/edit の入力。
 // NOTE: meta/attachments などが来ても無視してOK (署名対象外の想定)。
@@ -37,42 +31,29 @@ type editPostRequest struct {
 	Signature       string `json:"signature"`
 	SignatureBase64 string `json:"signatureBase64"`
 
-	// 差し替えメモ:
-	// 合体(本実装)で API スキーマを共通パッケージ/モデルに寄せる場合は、この struct を移動/統合する。
+	// BoardID/LogEntry: createPostRequest 参照(board_log_write.go)。
+	BoardID  string         `json:"boardId,omitempty"`
+	LogEntry *BoardLogEntry `json:"boardLogEntry,omitempty"`
 }
 
-// editPostResponse は POST /api/v1/posts/{postCid}/edit の出力(暫定)。
-// NOTE: 合体(本実装)時に CID/履歴/ログ等の仕様に合わせて変更する。
+// editPostResponse は POST /api/v1/posts/{postCid}/edit の出力。
 type editPostResponse struct {
 	OldPostCid string `json:"oldPostCid"`
 	NewPostCid string `json:"newPostCid"`
-}
 
-type storedPost struct {
-	PostCid      string
-	ThreadID     string
-	AuthorPubKey string
-	BodyFormat   string
-	BodyContent  string
-	CreatedAt    string
-	EditedAt     string
+	// BoardLogCid: createPostResponse 参照。
+	BoardLogCid string `json:"boardLogCid,omitempty"`
 }
 
-var (
-	postsStoreMu sync.RWMutex
-	postsStore   = map[string]storedPost{}
-)
-
 func init() {
 	// main.go を触らずに DefaultServeMux にルーティングを登録する。
-	//
-	// 差し替えメモ:
-	// 合体(本実装)でルーター構成を整理する場合は、ここでの HandleFunc 登録を
-	// 新しいルーティング層へ移す。
-	http.HandleFunc("/api/v1/posts/", handleEditPost)
+	// /api/v1/posts/{postCid}/{edit,tombstone} をまとめて受けるのは
+	// posts.handlePostActions (api_v1_posts_tombstone.go) の役割で、
+	// edit 以外のパスはそこから handleEditPost に戻ってくる。
+	http.HandleFunc("/api/v1/posts/", posts.handlePostActions)
 }
 
-func handleEditPost(w http.ResponseWriter, r *http.Request) {
+func (h *postsHandlers) handleEditPost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -108,27 +89,30 @@ func handleEditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	postsStoreMu.RLock()
-	old, found := postsStore[postCid]
-	postsStoreMu.RUnlock()
-	if !found {
+	store := h.postStore()
+	old, err := store.Get(r.Context(), postCid)
+	if errors.Is(err, poststore.ErrNotFound) {
 		writeJSONError(w, http.StatusNotFound, "not_found", "post not found")
 		return
 	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
 
 	// 編集ルール(暫定): 投稿者のみ編集可。
-	if old.AuthorPubKey != req.AuthorPubKey {
+	if old.Post.AuthorPubKey != req.AuthorPubKey {
 		writeJSONError(w, http.StatusForbidden, "forbidden", "authorPubKey does not match")
 		return
 	}
 	// 編集ルール(暫定): threadId は変更不可。
-	if old.ThreadID != req.ThreadID {
+	if old.Post.ThreadID != req.ThreadID {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "threadId mismatch")
 		return
 	}
 
 	// 編集ルール(暫定): editedAt は createdAt より前にしない。
-	if t0, err0 := time.Parse(time.RFC3339Nano, old.CreatedAt); err0 == nil {
+	if t0, err0 := time.Parse(time.RFC3339Nano, old.Post.CreatedAt); err0 == nil {
 		if t1, err1 := time.Parse(time.RFC3339Nano, req.EditedAt); err1 == nil {
 			if t1.Before(t0) {
 				writeJSONError(w, http.StatusBadRequest, "invalid_request", "editedAt must be >= createdAt")
@@ -142,7 +126,11 @@ func handleEditPost(w http.ResponseWriter, r *http.Request) {
 		sig = req.SignatureBase64
 	}
 
-	payload := PostEditSignPayload(
+	if !h.checkWriteLimits(w, r, req.AuthorPubKey, sig) {
+		return
+	}
+
+	payload, err := PostEditSignPayload(
 		req.Version,
 		req.ThreadID,
 		postCid,
@@ -151,8 +139,12 @@ func handleEditPost(w http.ResponseWriter, r *http.Request) {
 		req.Body.Content,
 		req.EditedAt,
 	)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "payload_error", err.Error())
+		return
+	}
 
-	ok, err := VerifyPayloadEd25519(req.AuthorPubKey, payload, sig)
+	ok, err = VerifyPayloadEd25519(req.AuthorPubKey, payload, sig)
 	if err != nil {
 		writeJSONError(w, http.StatusBadRequest, "invalid_signature", err.Error())
 		return
@@ -162,27 +154,35 @@ func handleEditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、ここは「本物のCID生成・永続化」に差し替える。
-	// いまはとりあえず動かすため、署名ペイロードのSHA256を暫定CIDとして返す。
-	sum := sha256.Sum256([]byte(payload))
-	newPostCid := "sha256:" + hex.EncodeToString(sum[:])
+	newPost := poststore.Post{
+		ThreadID:      old.Post.ThreadID,
+		ParentPostCID: old.Post.ParentPostCID,
+		AuthorPubKey:  old.Post.AuthorPubKey,
+		DisplayName:   old.Post.DisplayName,
+		BodyFormat:    req.Body.Format,
+		BodyContent:   req.Body.Content,
+		CreatedAt:     old.Post.CreatedAt,
+		EditedAt:      req.EditedAt,
+	}
+	newPostCid, err := store.Put(r.Context(), newPost)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
 
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、このインメモリ保存はストレージ層/FlexIPFS/DB に置き換える。
-	postsStoreMu.Lock()
-	postsStore[newPostCid] = storedPost{
-		PostCid:      newPostCid,
-		ThreadID:     old.ThreadID,
-		AuthorPubKey: old.AuthorPubKey,
-		BodyFormat:   req.Body.Format,
-		BodyContent:  req.Body.Content,
-		CreatedAt:    old.CreatedAt,
-		EditedAt:     req.EditedAt,
-	}
-	postsStoreMu.Unlock()
+	resp := editPostResponse{OldPostCid: postCid, NewPostCid: newPostCid}
+	if req.LogEntry != nil {
+		logCid, err := appendPostBoardLogEntry(r.Context(), h.boardLogStore(), req.LogEntry,
+			req.BoardID, "editPost", req.AuthorPubKey, req.ThreadID,
+			nil, &postCid, &newPostCid, nil)
+		if err != nil {
+			writeBoardLogAppendError(w, err)
+			return
+		}
+		resp.BoardLogCid = logCid
+	}
 
-	writeJSON(w, http.StatusOK, editPostResponse{OldPostCid: postCid, NewPostCid: newPostCid})
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func parsePostEditPath(path string) (string, bool) {
@@ -206,8 +206,6 @@ func parsePostEditPath(path string) (string, bool) {
 }
 
 func validateEditPostRequest(req editPostRequest) error {
-	// 差し替えメモ:
-	// 合体(本実装)で共通のバリデーション層を作る場合は、ここを共通関数へ寄せる。
 	if req.Version <= 0 {
 		return errors.New("version must be > 0")
 	}
@@ -235,10 +233,8 @@ func validateEditPostRequest(req editPostRequest) error {
 	return nil
 }
 
-// PostEditSignPayload は Post(編集)用の署名ペイロードを作る。
-//
-// 差し替えメモ:
-// 合体(本実装)のタイミングで、仕様が決まったら key.go 側へ移して共通化してもOK。
+// PostEditSignPayload builds the canonical, domain-separated signing
+// payload for a post edit; see PostSignPayload and canon.SignedPost.
 func PostEditSignPayload(
 	version int,
 	threadID string,
@@ -247,31 +243,21 @@ func PostEditSignPayload(
 	bodyFormat string,
 	bodyContent string,
 	editedAt string,
-) string {
-	return BuildSignPayload([][2]string{
-		{"type", "postEdit"},
-		{"version", strconv.Itoa(version)},
-		{"threadId", threadID},
-		{"postCid", postCid},
-		{"authorPubKey", authorPubKey},
-		{"body.format", bodyFormat},
-		{"body.content", bodyContent},
-		{"editedAt", editedAt},
-	})
-}
-
-// seedPostForTests はテスト用に in-memory store に投稿を登録する。
-//
-// 差し替えメモ:
-// 合体(本実装)でテスト無し運用なら、この関数自体を削除してOK。
-func seedPostForTests(p storedPost) {
-	postsStoreMu.Lock()
-	postsStore[p.PostCid] = p
-	postsStoreMu.Unlock()
-}
-
-func resetPostsStoreForTests() {
-	postsStoreMu.Lock()
-	postsStore = map[string]storedPost{}
-	postsStoreMu.Unlock()
+) (string, error) {
+	sp := canon.SignedPost{
+		Version:      version,
+		ThreadID:     threadID,
+		PostCID:      &postCid,
+		AuthorPubKey: authorPubKey,
+		Body: &canon.SignedPostBody{
+			Format:  bodyFormat,
+			Content: bodyContent,
+		},
+		EditedAt: &editedAt,
+	}
+	payload, err := canon.EncodeSignedPost(canon.PrefixPostEdit, sp)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
 }
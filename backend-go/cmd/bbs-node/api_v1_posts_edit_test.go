@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
 type editPostReqBody struct {
@@ -31,13 +33,15 @@ func TestEditPost_OK(t *testing.T) {
 
 	oldCid := "oldcid"
 	threadID := "thread-1"
-	seedPostForTests(storedPost{
-		PostCid:      oldCid,
-		ThreadID:     threadID,
-		AuthorPubKey: kp.Public,
-		BodyFormat:   "md",
-		BodyContent:  "hello",
-		CreatedAt:    "2025-01-01T00:00:00Z",
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     threadID,
+			AuthorPubKey: kp.Public,
+			BodyFormat:   "md",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-01-01T00:00:00Z",
+		},
 	})
 
 	var req editPostReqBody
@@ -48,7 +52,10 @@ func TestEditPost_OK(t *testing.T) {
 	req.Body.Content = "hello edited"
 	req.EditedAt = "2025-01-02T00:00:00Z"
 
-	payload := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	payload, err := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	if err != nil {
+		t.Fatalf("PostEditSignPayload: %v", err)
+	}
 	sig, err := SignPayloadEd25519(kp.Private, payload)
 	if err != nil {
 		t.Fatalf("SignPayloadEd25519: %v", err)
@@ -59,7 +66,7 @@ func TestEditPost_OK(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/edit", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleEditPost(w, r)
+	posts.handleEditPost(w, r)
 	resp := w.Result()
 	defer resp.Body.Close()
 
@@ -77,21 +84,19 @@ func TestEditPost_OK(t *testing.T) {
 	if out.OldPostCid != oldCid {
 		t.Fatalf("OldPostCid=%q", out.OldPostCid)
 	}
-	if out.NewPostCid == "" || !strings.HasPrefix(out.NewPostCid, "sha256:") {
+	if out.NewPostCid == "" || !strings.HasPrefix(out.NewPostCid, "b") {
 		t.Fatalf("NewPostCid=%q", out.NewPostCid)
 	}
 
-	postsStoreMu.RLock()
-	p, ok := postsStore[out.NewPostCid]
-	postsStoreMu.RUnlock()
-	if !ok {
-		t.Fatalf("new post not stored")
+	stored, err := posts.postStore().Get(r.Context(), out.NewPostCid)
+	if err != nil {
+		t.Fatalf("new post not stored: %v", err)
 	}
-	if p.BodyContent != req.Body.Content {
-		t.Fatalf("stored content=%q", p.BodyContent)
+	if stored.Post.BodyContent != req.Body.Content {
+		t.Fatalf("stored content=%q", stored.Post.BodyContent)
 	}
-	if p.EditedAt != req.EditedAt {
-		t.Fatalf("stored editedAt=%q", p.EditedAt)
+	if stored.Post.EditedAt != req.EditedAt {
+		t.Fatalf("stored editedAt=%q", stored.Post.EditedAt)
 	}
 }
 
@@ -103,13 +108,15 @@ func TestEditPost_AuthorMismatch_Forbidden(t *testing.T) {
 
 	oldCid := "oldcid"
 	threadID := "thread-1"
-	seedPostForTests(storedPost{
-		PostCid:      oldCid,
-		ThreadID:     threadID,
-		AuthorPubKey: kpAuthor.Public,
-		BodyFormat:   "md",
-		BodyContent:  "hello",
-		CreatedAt:    "2025-01-01T00:00:00Z",
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     threadID,
+			AuthorPubKey: kpAuthor.Public,
+			BodyFormat:   "md",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-01-01T00:00:00Z",
+		},
 	})
 
 	var req editPostReqBody
@@ -120,7 +127,10 @@ func TestEditPost_AuthorMismatch_Forbidden(t *testing.T) {
 	req.Body.Content = "hello edited"
 	req.EditedAt = "2025-01-02T00:00:00Z"
 
-	payload := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	payload, err := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	if err != nil {
+		t.Fatalf("PostEditSignPayload: %v", err)
+	}
 	sig, _ := SignPayloadEd25519(kpOther.Private, payload)
 	req.Signature = sig
 
@@ -128,7 +138,7 @@ func TestEditPost_AuthorMismatch_Forbidden(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/edit", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleEditPost(w, r)
+	posts.handleEditPost(w, r)
 	if w.Result().StatusCode != http.StatusForbidden {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
@@ -142,13 +152,15 @@ func TestEditPost_InvalidSignature_Unauthorized(t *testing.T) {
 
 	oldCid := "oldcid"
 	threadID := "thread-1"
-	seedPostForTests(storedPost{
-		PostCid:      oldCid,
-		ThreadID:     threadID,
-		AuthorPubKey: kpAuthor.Public,
-		BodyFormat:   "md",
-		BodyContent:  "hello",
-		CreatedAt:    "2025-01-01T00:00:00Z",
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     threadID,
+			AuthorPubKey: kpAuthor.Public,
+			BodyFormat:   "md",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-01-01T00:00:00Z",
+		},
 	})
 
 	var req editPostReqBody
@@ -159,7 +171,10 @@ func TestEditPost_InvalidSignature_Unauthorized(t *testing.T) {
 	req.Body.Content = "hello edited"
 	req.EditedAt = "2025-01-02T00:00:00Z"
 
-	payload := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	payload, err := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	if err != nil {
+		t.Fatalf("PostEditSignPayload: %v", err)
+	}
 	sig, _ := SignPayloadEd25519(kpOther.Private, payload)
 	req.Signature = sig
 
@@ -167,7 +182,7 @@ func TestEditPost_InvalidSignature_Unauthorized(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/edit", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleEditPost(w, r)
+	posts.handleEditPost(w, r)
 	if w.Result().StatusCode != http.StatusUnauthorized {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
@@ -189,7 +204,10 @@ func TestEditPost_NotFound(t *testing.T) {
 	req.Body.Content = "hello edited"
 	req.EditedAt = "2025-01-02T00:00:00Z"
 
-	payload := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	payload, err := PostEditSignPayload(req.Version, req.ThreadID, oldCid, req.AuthorPubKey, req.Body.Format, req.Body.Content, req.EditedAt)
+	if err != nil {
+		t.Fatalf("PostEditSignPayload: %v", err)
+	}
 	sig, _ := SignPayloadEd25519(kp.Private, payload)
 	req.Signature = sig
 
@@ -197,7 +215,7 @@ func TestEditPost_NotFound(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/edit", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleEditPost(w, r)
+	posts.handleEditPost(w, r)
 	if w.Result().StatusCode != http.StatusNotFound {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
@@ -1,16 +1,15 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
+
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
 // --- API: POST /api/v1/posts (thread post) ---
@@ -34,37 +33,29 @@ type createPostRequest struct {
 	Signature       string `json:"signature"`
 	SignatureBase64 string `json:"signatureBase64"`
 
-	// 差し替えメモ:
-	// 合体(本実装)で API スキーマを共通パッケージ/モデルに寄せる場合は、この struct を移動/統合する。
+	// BoardID と LogEntry は任意。両方あれば、投稿の保存後に LogEntry を
+	// BoardID の BoardLogStore へ追記する(h.boardLogStore() が nil なら
+	// 何もしない)。LogEntry は呼び出し側が自分の鍵で署名済みのものを渡す
+	// — このハンドラーは代理署名しない(board_log_write.go 参照)。
+	BoardID  string         `json:"boardId,omitempty"`
+	LogEntry *BoardLogEntry `json:"boardLogEntry,omitempty"`
 }
 
-// createPostResponse は POST /api/v1/posts の出力(暫定)。
-// NOTE: 合体(本実装)時に CID 生成/永続化の仕様に合わせて変更する。
+// createPostResponse は POST /api/v1/posts の出力。
 type createPostResponse struct {
 	PostCid string `json:"postCid"`
-}
 
-// postStoreItem は暫定のインメモリ保存用。
-type postStoreItem struct {
-	PostCid string
-	Req     createPostRequest
+	// BoardLogCid is the CID the request's LogEntry was appended under,
+	// if it supplied one and it was accepted.
+	BoardLogCid string `json:"boardLogCid,omitempty"`
 }
 
-var (
-	postsMu       sync.RWMutex
-	postsByThread = map[string][]postStoreItem{}
-)
-
 func init() {
 	// main.go を触らずに DefaultServeMux にルーティングを登録する。
-	//
-	// 差し替えメモ:
-	// 合体(本実装)でルーター構成を整理する場合は、ここでの HandleFunc 登録を
-	// 新しいルーティング層へ移す。
-	http.HandleFunc("/api/v1/posts", handleCreatePost)
+	http.HandleFunc("/api/v1/posts", posts.handleCreatePost)
 }
 
-func handleCreatePost(w http.ResponseWriter, r *http.Request) {
+func (h *postsHandlers) handleCreatePost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -102,7 +93,11 @@ func handleCreatePost(w http.ResponseWriter, r *http.Request) {
 		sig = req.SignatureBase64
 	}
 
-	payload := PostSignPayload(
+	if !h.checkWriteLimits(w, r, req.AuthorPubKey, sig) {
+		return
+	}
+
+	payload, err := PostSignPayload(
 		req.Version,
 		req.ThreadID,
 		req.ParentPostCid,
@@ -112,6 +107,10 @@ func handleCreatePost(w http.ResponseWriter, r *http.Request) {
 		req.Body.Content,
 		req.CreatedAt,
 	)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "payload_error", err.Error())
+		return
+	}
 
 	ok, err := VerifyPayloadEd25519(req.AuthorPubKey, payload, sig)
 	if err != nil {
@@ -123,24 +122,40 @@ func handleCreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、ここは「本物のCID生成・永続化」に差し替える。
-	// いまはとりあえず動かすため、署名ペイロードのSHA256を暫定CIDとして返す。
-	sum := sha256.Sum256([]byte(payload))
-	postCid := "sha256:" + hex.EncodeToString(sum[:])
+	p := poststore.Post{
+		ThreadID:     req.ThreadID,
+		AuthorPubKey: req.AuthorPubKey,
+		DisplayName:  req.DisplayName,
+		BodyFormat:   req.Body.Format,
+		BodyContent:  req.Body.Content,
+		CreatedAt:    req.CreatedAt,
+	}
+	if req.ParentPostCid != nil {
+		p.ParentPostCID = *req.ParentPostCid
+	}
 
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、このインメモリ保存はストレージ層/FlexIPFS/DB に置き換える。
-	postsMu.Lock()
-	postsByThread[req.ThreadID] = append(postsByThread[req.ThreadID], postStoreItem{PostCid: postCid, Req: req})
-	postsMu.Unlock()
+	postCid, err := h.postStore().Put(r.Context(), p)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
 
-	writeJSON(w, http.StatusCreated, createPostResponse{PostCid: postCid})
+	resp := createPostResponse{PostCid: postCid}
+	if req.LogEntry != nil {
+		logCid, err := appendPostBoardLogEntry(r.Context(), h.boardLogStore(), req.LogEntry,
+			req.BoardID, "addPost", req.AuthorPubKey, req.ThreadID,
+			&postCid, nil, nil, nil)
+		if err != nil {
+			writeBoardLogAppendError(w, err)
+			return
+		}
+		resp.BoardLogCid = logCid
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 func validateCreatePostRequest(req createPostRequest) error {
-	// 差し替えメモ:
-	// 合体(本実装)で共通のバリデーション層を作る場合は、ここを共通関数へ寄せる。
 	if req.Version <= 0 {
 		return errors.New("version must be > 0")
 	}
@@ -169,8 +184,6 @@ func validateCreatePostRequest(req createPostRequest) error {
 }
 
 func isRFC3339OrNano(s string) bool {
-	// 差し替えメモ:
-	// 合体(本実装)で createdAt の仕様を厳密化/変更する場合は、ここ(許可するフォーマット)を調整する。
 	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
 		return true
 	}
@@ -180,17 +193,8 @@ func isRFC3339OrNano(s string) bool {
 	return false
 }
 
-// resetPostsStore clears the in-memory posts store (used by tests).
-//
-// 差し替えメモ:
-// 合体(本実装)でテスト無し運用なら、この関数自体を削除してOK。
-func resetPostsStore() {
-	postsMu.Lock()
-	postsByThread = map[string][]postStoreItem{}
-	postsMu.Unlock()
-}
-
-// --- small JSON helpers (local to this file) ---
+// --- small JSON helpers (local to this file, shared by the other
+// api_v1_posts_*.go handlers) ---
 
 type jsonErrorResponse struct {
 	Error string `json:"error"`
@@ -25,7 +25,7 @@ type createPostReqBody struct {
 }
 
 func TestCreatePost_OK(t *testing.T) {
-	resetPostsStore()
+	resetPostsStoreForTests()
 
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -41,7 +41,10 @@ func TestCreatePost_OK(t *testing.T) {
 	req.Body.Content = "hello"
 	req.CreatedAt = "2025-01-01T00:00:00Z"
 
-	payload := PostSignPayload(req.Version, req.ThreadID, nil, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	payload, err := PostSignPayload(req.Version, req.ThreadID, nil, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	if err != nil {
+		t.Fatalf("PostSignPayload: %v", err)
+	}
 	sig, err := SignPayloadEd25519(kp.Private, payload)
 	if err != nil {
 		t.Fatalf("SignPayloadEd25519: %v", err)
@@ -52,7 +55,7 @@ func TestCreatePost_OK(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleCreatePost(w, r)
+	posts.handleCreatePost(w, r)
 	resp := w.Result()
 	defer resp.Body.Close()
 
@@ -64,13 +67,13 @@ func TestCreatePost_OK(t *testing.T) {
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
-	if out.PostCid == "" || !strings.HasPrefix(out.PostCid, "sha256:") {
+	if out.PostCid == "" || !strings.HasPrefix(out.PostCid, "b") {
 		t.Fatalf("postCid=%q", out.PostCid)
 	}
 }
 
 func TestCreatePost_InvalidSignature_Unauthorized(t *testing.T) {
-	resetPostsStore()
+	resetPostsStoreForTests()
 
 	kpAuthor, _ := GenerateKeyPair()
 	kpOther, _ := GenerateKeyPair()
@@ -84,7 +87,10 @@ func TestCreatePost_InvalidSignature_Unauthorized(t *testing.T) {
 	req.Body.Content = "hello"
 	req.CreatedAt = "2025-01-01T00:00:00Z"
 
-	payload := PostSignPayload(req.Version, req.ThreadID, nil, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	payload, err := PostSignPayload(req.Version, req.ThreadID, nil, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	if err != nil {
+		t.Fatalf("PostSignPayload: %v", err)
+	}
 	sig, _ := SignPayloadEd25519(kpOther.Private, payload)
 	req.Signature = sig
 
@@ -92,20 +98,20 @@ func TestCreatePost_InvalidSignature_Unauthorized(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleCreatePost(w, r)
+	posts.handleCreatePost(w, r)
 	if w.Result().StatusCode != http.StatusUnauthorized {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
 }
 
 func TestCreatePost_BadRequest_MissingFields(t *testing.T) {
-	resetPostsStore()
+	resetPostsStoreForTests()
 
 	b := []byte(`{"version":1}`)
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", bytes.NewReader(b))
 	w := httptest.NewRecorder()
 
-	handleCreatePost(w, r)
+	posts.handleCreatePost(w, r)
 	if w.Result().StatusCode != http.StatusBadRequest {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
@@ -114,7 +120,7 @@ func TestCreatePost_BadRequest_MissingFields(t *testing.T) {
 func TestCreatePost_MethodNotAllowed(t *testing.T) {
 	r := httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil)
 	w := httptest.NewRecorder()
-	handleCreatePost(w, r)
+	posts.handleCreatePost(w, r)
 	if w.Result().StatusCode != http.StatusMethodNotAllowed {
 		t.Fatalf("status=%d", w.Result().StatusCode)
 	}
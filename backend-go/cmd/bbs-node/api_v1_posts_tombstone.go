@@ -1,23 +1,18 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"flex-bbs/backend-go/cmd/bbs-node/canon"
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
 // --- API: POST /api/v1/posts/{postCid}/tombstone (#21: logical delete) ---
-//
-// 差し替えメモ:
-// 合体(本実装)でルーター構成を整理する場合は、init() の HandleFunc 登録を
-// 新しいルーティング層へ移す。
 
 // tombstonePostRequest は POST /api/v1/posts/{postCid}/tombstone の入力。
 // NOTE: meta/attachments などが来ても無視してOK (署名対象外の想定)。
@@ -28,61 +23,51 @@ type tombstonePostRequest struct {
 	TombstonedAt string `json:"tombstonedAt"`
 
 	// Signature は PostTombstoneSignPayload を Ed25519 署名した base64(StdEncoding) 文字列。
-	// 互換性のため signatureBase64 も受け付ける。
+	// 互換性のため signatureBase64 も受け付ける。ModeratorKeyID が指定されている場合は
+	// 使わない(ModeratorSignature を参照)。
 	Signature       string `json:"signature"`
 	SignatureBase64 string `json:"signatureBase64"`
+
+	// ModeratorKeyID, if set, names a BoardMeta.ModeratorKeys entry the
+	// board owner issued with "tombstone" scope; ModeratorSignature must
+	// then hold that key's signature over ModeratorTombstoneSignPayload,
+	// and Signature/SignatureBase64 must be empty -- a request carries
+	// either an author signature or a moderator signature, never both.
+	ModeratorKeyID     string `json:"moderatorKeyId,omitempty"`
+	ModeratorSignature string `json:"moderatorSignature,omitempty"`
+
+	// BoardID/LogEntry: createPostRequest 参照(board_log_write.go)。
+	// ModeratorKeyID を使う場合、BoardID はそのキーが属する板を指すために必須。
+	BoardID  string         `json:"boardId,omitempty"`
+	LogEntry *BoardLogEntry `json:"boardLogEntry,omitempty"`
 }
 
-// tombstonePostResponse は POST /api/v1/posts/{postCid}/tombstone の出力(暫定)。
+// tombstonePostResponse は POST /api/v1/posts/{postCid}/tombstone の出力。
 type tombstonePostResponse struct {
 	OldPostCid string `json:"oldPostCid"`
 	NewPostCid string `json:"newPostCid"`
-}
-
-type storedPost struct {
-	PostCid       string
-	ThreadID      string
-	AuthorPubKey  string
-	BodyFormat    string
-	BodyContent   string
-	CreatedAt     string
-	EditedAt      string
-	TombstonedAt  string
-	IsTombstoned  bool
-	OriginalPost  string
-	OriginalTitle string
-}
 
-var (
-	postsStoreMu sync.RWMutex
-	postsStore   = map[string]storedPost{}
-)
-
-func init() {
-	// main.go を触らずに DefaultServeMux にルーティングを登録する。
-	http.HandleFunc("/api/v1/posts/", handlePostActions)
+	// BoardLogCid: createPostResponse 参照。
+	BoardLogCid string `json:"boardLogCid,omitempty"`
 }
 
 // handlePostActions は /api/v1/posts/{postCid}/... 系をまとめて受ける(暫定)。
 //
 // 差し替えメモ:
-// 合体(本実装)で edit/tombstone を分割するなら、ここをルーター層に分離する。
-func handlePostActions(w http.ResponseWriter, r *http.Request) {
+// edit/tombstone を分割するなら、ここをルーター層に分離する。ただし
+// api_v1_posts_edit.go の handleEditPost がこのパターンの
+// /api/v1/posts/ 登録を既に持っているため、ここでは tombstone 以外の
+// パスは handleEditPost に譲る。
+func (h *postsHandlers) handlePostActions(w http.ResponseWriter, r *http.Request) {
 	postCid, action, ok := parsePostActionPath(r.URL.Path)
-	if !ok {
-		http.NotFound(w, r)
+	if !ok || action != "tombstone" {
+		h.handleEditPost(w, r)
 		return
 	}
-
-	switch action {
-	case "tombstone":
-		handleTombstonePost(w, r, postCid)
-	default:
-		http.NotFound(w, r)
-	}
+	h.handleTombstonePost(w, r, postCid)
 }
 
-func handleTombstonePost(w http.ResponseWriter, r *http.Request, postCid string) {
+func (h *postsHandlers) handleTombstonePost(w http.ResponseWriter, r *http.Request, postCid string) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -104,40 +89,80 @@ func handleTombstonePost(w http.ResponseWriter, r *http.Request, postCid string)
 	req.TombstonedAt = strings.TrimSpace(req.TombstonedAt)
 	req.Signature = strings.TrimSpace(req.Signature)
 	req.SignatureBase64 = strings.TrimSpace(req.SignatureBase64)
+	req.ModeratorKeyID = strings.TrimSpace(req.ModeratorKeyID)
+	req.ModeratorSignature = strings.TrimSpace(req.ModeratorSignature)
+	req.BoardID = strings.TrimSpace(req.BoardID)
 
 	if err := validateTombstonePostRequest(req); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
 		return
 	}
 
-	postsStoreMu.RLock()
-	old, found := postsStore[postCid]
-	postsStoreMu.RUnlock()
-	if !found {
+	store := h.postStore()
+	old, err := store.Get(r.Context(), postCid)
+	if errors.Is(err, poststore.ErrNotFound) {
 		writeJSONError(w, http.StatusNotFound, "not_found", "post not found")
 		return
 	}
-
-	// 削除ルール(暫定): 投稿者のみ tombstone 可。
-	if old.AuthorPubKey != req.AuthorPubKey {
-		writeJSONError(w, http.StatusForbidden, "forbidden", "authorPubKey does not match")
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
 		return
 	}
 
+	isModerator := req.ModeratorKeyID != ""
+	var moderatorKey ModeratorKey
+	if isModerator {
+		lookup := h.boardsLookup()
+		if lookup == nil {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "moderator tombstones are not configured on this node")
+			return
+		}
+		bm, ok := lookup.BoardMetaByID(req.BoardID)
+		if !ok {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "unknown board")
+			return
+		}
+		key, ok := bm.FindModeratorKey(req.ModeratorKeyID)
+		if !ok {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "unknown moderator key")
+			return
+		}
+		if key.IssuedBy != bm.CreatedBy {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "moderator key was not issued by this board's owner")
+			return
+		}
+		validIssuance, err := VerifyModeratorKeyIssuance(key)
+		if err != nil || !validIssuance {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "moderator key issuance signature is invalid")
+			return
+		}
+		if !key.Authorized(req.BoardID, ModeratorScopeTombstone, time.Now().UTC()) {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "moderator key is expired, revoked, or lacks the tombstone scope")
+			return
+		}
+		moderatorKey = key
+	} else {
+		// 削除ルール(暫定): 投稿者のみ tombstone 可。
+		if old.Post.AuthorPubKey != req.AuthorPubKey {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "authorPubKey does not match")
+			return
+		}
+	}
+
 	// 削除ルール(暫定): threadId は変更不可。
-	if old.ThreadID != req.ThreadID {
+	if old.Post.ThreadID != req.ThreadID {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "threadId mismatch")
 		return
 	}
 
 	// 削除ルール(暫定): 既に tombstone 済みなら弾く。
-	if old.IsTombstoned {
+	if old.Tombstoned {
 		writeJSONError(w, http.StatusConflict, "already_tombstoned", "post already tombstoned")
 		return
 	}
 
 	// 削除ルール(暫定): tombstonedAt は createdAt より前にしない。
-	if t0, err0 := time.Parse(time.RFC3339Nano, old.CreatedAt); err0 == nil {
+	if t0, err0 := time.Parse(time.RFC3339Nano, old.Post.CreatedAt); err0 == nil {
 		if t1, err1 := time.Parse(time.RFC3339Nano, req.TombstonedAt); err1 == nil {
 			if t1.Before(t0) {
 				writeJSONError(w, http.StatusBadRequest, "invalid_request", "tombstonedAt must be >= createdAt")
@@ -146,52 +171,91 @@ func handleTombstonePost(w http.ResponseWriter, r *http.Request, postCid string)
 		}
 	}
 
-	sig := req.Signature
-	if sig == "" {
-		sig = req.SignatureBase64
-	}
+	tombstonedBy := req.AuthorPubKey
+	if isModerator {
+		if !h.checkWriteLimits(w, r, moderatorKey.PubKey, req.ModeratorSignature) {
+			return
+		}
 
-	payload := PostTombstoneSignPayload(
-		req.Version,
-		req.ThreadID,
-		postCid,
-		req.AuthorPubKey,
-		req.TombstonedAt,
-	)
+		payload := ModeratorTombstoneSignPayload(req.Version, req.BoardID, req.ThreadID, postCid, req.ModeratorKeyID, req.TombstonedAt)
+		ok, err := VerifyPayloadEd25519(moderatorKey.PubKey, payload, req.ModeratorSignature)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_signature", err.Error())
+			return
+		}
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "invalid_signature", "signature verification failed")
+			return
+		}
+		tombstonedBy = "moderator:" + req.ModeratorKeyID
+	} else {
+		sig := req.Signature
+		if sig == "" {
+			sig = req.SignatureBase64
+		}
 
-	ok, err := VerifyPayloadEd25519(req.AuthorPubKey, payload, sig)
+		if !h.checkWriteLimits(w, r, req.AuthorPubKey, sig) {
+			return
+		}
+
+		payload, err := PostTombstoneSignPayload(
+			req.Version,
+			req.ThreadID,
+			postCid,
+			req.AuthorPubKey,
+			req.TombstonedAt,
+		)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "payload_error", err.Error())
+			return
+		}
+
+		ok, err := VerifyPayloadEd25519(req.AuthorPubKey, payload, sig)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_signature", err.Error())
+			return
+		}
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "invalid_signature", "signature verification failed")
+			return
+		}
+	}
+
+	// tombstone マーカーは本文を空にした新しい post として Put し、
+	// その新 CID を Tombstone で oldCid に紐付ける。
+	marker := poststore.Post{
+		ThreadID:      old.Post.ThreadID,
+		ParentPostCID: old.Post.ParentPostCID,
+		AuthorPubKey:  old.Post.AuthorPubKey,
+		DisplayName:   old.Post.DisplayName,
+		BodyFormat:    old.Post.BodyFormat,
+		BodyContent:   "",
+		CreatedAt:     old.Post.CreatedAt,
+		EditedAt:      old.Post.EditedAt,
+	}
+	newPostCid, err := store.Put(r.Context(), marker)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_signature", err.Error())
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
 		return
 	}
-	if !ok {
-		writeJSONError(w, http.StatusUnauthorized, "invalid_signature", "signature verification failed")
+	if err := store.Tombstone(r.Context(), postCid, newPostCid, req.TombstonedAt, tombstonedBy); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
 		return
 	}
 
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、ここは「本物のCID生成・永続化」に差し替える。
-	// いまはとりあえず動かすため、署名ペイロードのSHA256を暫定CIDとして返す。
-	sum := sha256.Sum256([]byte(payload))
-	newPostCid := "sha256:" + hex.EncodeToString(sum[:])
-
-	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、このインメモリ保存はストレージ層/FlexIPFS/DB に置き換える。
-	postsStoreMu.Lock()
-	postsStore[newPostCid] = storedPost{
-		PostCid:      newPostCid,
-		ThreadID:     old.ThreadID,
-		AuthorPubKey: old.AuthorPubKey,
-		BodyFormat:   old.BodyFormat,
-		BodyContent:  "",
-		CreatedAt:    old.CreatedAt,
-		EditedAt:     old.EditedAt,
-		TombstonedAt: req.TombstonedAt,
-		IsTombstoned: true,
+	resp := tombstonePostResponse{OldPostCid: postCid, NewPostCid: newPostCid}
+	if req.LogEntry != nil {
+		logCid, err := appendPostBoardLogEntry(r.Context(), h.boardLogStore(), req.LogEntry,
+			req.BoardID, "tombstonePost", req.AuthorPubKey, req.ThreadID,
+			nil, nil, nil, &postCid)
+		if err != nil {
+			writeBoardLogAppendError(w, err)
+			return
+		}
+		resp.BoardLogCid = logCid
 	}
-	postsStoreMu.Unlock()
 
-	writeJSON(w, http.StatusOK, tombstonePostResponse{OldPostCid: postCid, NewPostCid: newPostCid})
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func parsePostActionPath(path string) (postCid string, action string, ok bool) {
@@ -217,51 +281,60 @@ func validateTombstonePostRequest(req tombstonePostRequest) error {
 	if req.ThreadID == "" {
 		return errors.New("threadId is required")
 	}
-	if req.AuthorPubKey == "" {
-		return errors.New("authorPubKey is required")
-	}
 	if req.TombstonedAt == "" {
 		return errors.New("tombstonedAt is required")
 	}
 	if !isRFC3339OrNano(req.TombstonedAt) {
 		return errors.New("tombstonedAt must be RFC3339 or RFC3339Nano")
 	}
-	if req.Signature == "" && req.SignatureBase64 == "" {
+
+	hasAuthorSig := req.Signature != "" || req.SignatureBase64 != ""
+	hasModeratorSig := req.ModeratorKeyID != "" || req.ModeratorSignature != ""
+
+	if hasAuthorSig && hasModeratorSig {
+		return errors.New("cannot supply both an author signature and a moderator signature")
+	}
+	if hasModeratorSig {
+		if req.ModeratorKeyID == "" {
+			return errors.New("moderatorKeyId is required when moderatorSignature is set")
+		}
+		if req.ModeratorSignature == "" {
+			return errors.New("moderatorSignature is required when moderatorKeyId is set")
+		}
+		if req.BoardID == "" {
+			return errors.New("boardId is required when moderatorKeyId is set")
+		}
+		return nil
+	}
+
+	if req.AuthorPubKey == "" {
+		return errors.New("authorPubKey is required")
+	}
+	if !hasAuthorSig {
 		return errors.New("signature is required")
 	}
 	return nil
 }
 
-// PostTombstoneSignPayload は Post(tombstone)用の署名ペイロードを作る。
-//
-// 差し替えメモ:
-// 合体(本実装)のタイミングで、仕様が決まったら key.go 側へ移して共通化してもOK。
+// PostTombstoneSignPayload builds the canonical, domain-separated signing
+// payload for a post tombstone; see PostSignPayload and canon.SignedPost.
 func PostTombstoneSignPayload(
 	version int,
 	threadID string,
 	postCid string,
 	authorPubKey string,
 	tombstonedAt string,
-) string {
-	return BuildSignPayload([][2]string{
-		{"type", "postTombstone"},
-		{"version", strconv.Itoa(version)},
-		{"threadId", threadID},
-		{"postCid", postCid},
-		{"authorPubKey", authorPubKey},
-		{"tombstonedAt", tombstonedAt},
-	})
-}
-
-// seedPostForTests はテスト用に in-memory store に投稿を登録する。
-//
-// 差し替えメモ:
-// 合体(本実装)でテスト無し運用なら、
-// - cmd/bbs-node/api_v1_posts_tombstone_test.go
-// - この関数(seedPostForTests)
-// を削除してOK。
-func seedPostForTests(p storedPost) {
-	postsStoreMu.Lock()
-	postsStore[p.PostCid] = p
-	postsStoreMu.Unlock()
+) (string, error) {
+	sp := canon.SignedPost{
+		Version:      version,
+		ThreadID:     threadID,
+		PostCID:      &postCid,
+		AuthorPubKey: authorPubKey,
+		TombstonedAt: &tombstonedAt,
+	}
+	payload, err := canon.EncodeSignedPost(canon.PrefixPostTombstone, sp)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
 }
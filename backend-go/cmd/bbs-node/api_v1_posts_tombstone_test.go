@@ -6,15 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-)
+	"time"
 
-// 差し替えメモ:
-// 合体時にテストを使わない(リポジトリに残さない)運用なら、このファイルは削除してOK。
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
+)
 
 func TestTombstonePost_OK(t *testing.T) {
-	postsStoreMu.Lock()
-	postsStore = map[string]storedPost{}
-	postsStoreMu.Unlock()
+	resetPostsStoreForTests()
 
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -22,17 +20,22 @@ func TestTombstonePost_OK(t *testing.T) {
 	}
 
 	oldCid := "sha256:old"
-	seedPostForTests(storedPost{
-		PostCid:      oldCid,
-		ThreadID:     "thread-1",
-		AuthorPubKey: kp.Public,
-		BodyFormat:   "text/plain",
-		BodyContent:  "hello",
-		CreatedAt:    "2025-12-19T00:00:00Z",
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     "thread-1",
+			AuthorPubKey: kp.Public,
+			BodyFormat:   "text/plain",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-12-19T00:00:00Z",
+		},
 	})
 
 	tombstonedAt := "2025-12-19T01:00:00Z"
-	payload := PostTombstoneSignPayload(1, "thread-1", oldCid, kp.Public, tombstonedAt)
+	payload, err := PostTombstoneSignPayload(1, "thread-1", oldCid, kp.Public, tombstonedAt)
+	if err != nil {
+		t.Fatalf("PostTombstoneSignPayload: %v", err)
+	}
 	sig, err := SignPayloadEd25519(kp.Private, payload)
 	if err != nil {
 		t.Fatalf("SignPayloadEd25519: %v", err)
@@ -50,7 +53,7 @@ func TestTombstonePost_OK(t *testing.T) {
 
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
 	w := httptest.NewRecorder()
-	handlePostActions(w, r)
+	posts.handlePostActions(w, r)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
@@ -67,21 +70,17 @@ func TestTombstonePost_OK(t *testing.T) {
 		t.Fatalf("expected newPostCid")
 	}
 
-	postsStoreMu.RLock()
-	p, ok := postsStore[resp.NewPostCid]
-	postsStoreMu.RUnlock()
-	if !ok {
-		t.Fatalf("expected new post saved")
+	stored, err := posts.postStore().Get(r.Context(), resp.NewPostCid)
+	if err != nil {
+		t.Fatalf("expected new post saved: %v", err)
 	}
-	if !p.IsTombstoned {
-		t.Fatalf("expected IsTombstoned")
+	if !stored.Tombstoned {
+		t.Fatalf("expected Tombstoned")
 	}
 }
 
 func TestTombstonePost_InvalidSignature(t *testing.T) {
-	postsStoreMu.Lock()
-	postsStore = map[string]storedPost{}
-	postsStoreMu.Unlock()
+	resetPostsStoreForTests()
 
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -89,17 +88,22 @@ func TestTombstonePost_InvalidSignature(t *testing.T) {
 	}
 
 	oldCid := "sha256:old"
-	seedPostForTests(storedPost{
-		PostCid:      oldCid,
-		ThreadID:     "thread-1",
-		AuthorPubKey: kp.Public,
-		BodyFormat:   "text/plain",
-		BodyContent:  "hello",
-		CreatedAt:    "2025-12-19T00:00:00Z",
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     "thread-1",
+			AuthorPubKey: kp.Public,
+			BodyFormat:   "text/plain",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-12-19T00:00:00Z",
+		},
 	})
 
 	tombstonedAt := "2025-12-19T01:00:00Z"
-	payload := PostTombstoneSignPayload(1, "thread-1", oldCid, kp.Public, tombstonedAt)
+	payload, err := PostTombstoneSignPayload(1, "thread-1", oldCid, kp.Public, tombstonedAt)
+	if err != nil {
+		t.Fatalf("PostTombstoneSignPayload: %v", err)
+	}
 	sig, err := SignPayloadEd25519(kp.Private, payload)
 	if err != nil {
 		t.Fatalf("SignPayloadEd25519: %v", err)
@@ -117,7 +121,7 @@ func TestTombstonePost_InvalidSignature(t *testing.T) {
 
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
 	w := httptest.NewRecorder()
-	handlePostActions(w, r)
+	posts.handlePostActions(w, r)
 
 	if w.Code != http.StatusUnauthorized {
 		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
@@ -125,9 +129,7 @@ func TestTombstonePost_InvalidSignature(t *testing.T) {
 }
 
 func TestTombstonePost_NotFound(t *testing.T) {
-	postsStoreMu.Lock()
-	postsStore = map[string]storedPost{}
-	postsStoreMu.Unlock()
+	resetPostsStoreForTests()
 
 	reqBody := map[string]any{
 		"version":      1,
@@ -140,9 +142,318 @@ func TestTombstonePost_NotFound(t *testing.T) {
 
 	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/sha256:nope/tombstone", bytes.NewReader(b))
 	w := httptest.NewRecorder()
-	handlePostActions(w, r)
+	posts.handlePostActions(w, r)
 
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
 	}
 }
+
+// seedModeratorBoard seeds a post authored by authorKP under boardID, and
+// wires a boardMetaLookup with a single BoardMeta owned by ownerKP listing
+// key as its only moderator key, so a test can exercise
+// handleTombstonePost's moderator path without authorPubKey matching.
+func seedModeratorBoard(t *testing.T, boardID string, ownerKP, authorKP KeyPair, key ModeratorKey) (oldCid string) {
+	t.Helper()
+	resetPostsStoreForTests()
+	t.Cleanup(func() { setBoardMetaLookupForTests(nil) })
+
+	oldCid = "sha256:old"
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     "thread-1",
+			AuthorPubKey: authorKP.Public,
+			BodyFormat:   "text/plain",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-12-19T00:00:00Z",
+		},
+	})
+
+	bm := BoardMeta{
+		BoardID:       boardID,
+		CreatedBy:     ownerKP.Public,
+		ModeratorKeys: []ModeratorKey{key},
+	}
+	setBoardMetaLookupForTests(staticBoardMetaLookup{bm})
+	return oldCid
+}
+
+func TestTombstonePost_ModeratorKey_OK(t *testing.T) {
+	ownerKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	authorKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	modKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	key := ModeratorKey{
+		KeyID:     "mod-1",
+		PubKey:    modKP.Public,
+		BoardID:   "board-1",
+		Scopes:    []string{ModeratorScopeTombstone},
+		NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedBy:  ownerKP.Public,
+	}
+	if err := SignModeratorKeyIssuance(ownerKP.Private, &key); err != nil {
+		t.Fatalf("SignModeratorKeyIssuance: %v", err)
+	}
+
+	oldCid := seedModeratorBoard(t, "board-1", ownerKP, authorKP, key)
+
+	tombstonedAt := "2025-12-19T01:00:00Z"
+	payload := ModeratorTombstoneSignPayload(1, "board-1", "thread-1", oldCid, "mod-1", tombstonedAt)
+	sig, err := SignPayloadEd25519(modKP.Private, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+
+	reqBody := map[string]any{
+		"version":            1,
+		"threadId":           "thread-1",
+		"boardId":            "board-1",
+		"tombstonedAt":       tombstonedAt,
+		"moderatorKeyId":     "mod-1",
+		"moderatorSignature": sig,
+	}
+	b, _ := json.Marshal(reqBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handlePostActions(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var resp tombstonePostResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	stored, err := posts.postStore().Get(r.Context(), resp.NewPostCid)
+	if err != nil {
+		t.Fatalf("expected new post saved: %v", err)
+	}
+	if !stored.Tombstoned || stored.TombstonedBy != "moderator:mod-1" {
+		t.Fatalf("stored = %+v, want Tombstoned with TombstonedBy=moderator:mod-1", stored)
+	}
+}
+
+func TestTombstonePost_ModeratorKey_Expired(t *testing.T) {
+	ownerKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	authorKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	modKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	key := ModeratorKey{
+		KeyID:     "mod-1",
+		PubKey:    modKP.Public,
+		BoardID:   "board-1",
+		Scopes:    []string{ModeratorScopeTombstone},
+		NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), // expired long before "now"
+		IssuedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedBy:  ownerKP.Public,
+	}
+	if err := SignModeratorKeyIssuance(ownerKP.Private, &key); err != nil {
+		t.Fatalf("SignModeratorKeyIssuance: %v", err)
+	}
+
+	oldCid := seedModeratorBoard(t, "board-1", ownerKP, authorKP, key)
+
+	tombstonedAt := "2025-12-19T01:00:00Z"
+	payload := ModeratorTombstoneSignPayload(1, "board-1", "thread-1", oldCid, "mod-1", tombstonedAt)
+	sig, err := SignPayloadEd25519(modKP.Private, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+
+	reqBody := map[string]any{
+		"version":            1,
+		"threadId":           "thread-1",
+		"boardId":            "board-1",
+		"tombstonedAt":       tombstonedAt,
+		"moderatorKeyId":     "mod-1",
+		"moderatorSignature": sig,
+	}
+	b, _ := json.Marshal(reqBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handlePostActions(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status=%d body=%s, want 403 for an expired moderator key", w.Code, w.Body.String())
+	}
+}
+
+func TestTombstonePost_ModeratorKey_WrongBoard(t *testing.T) {
+	ownerKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	authorKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	modKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	// Key is bound to "other-board", not the "board-1" the request names.
+	key := ModeratorKey{
+		KeyID:     "mod-1",
+		PubKey:    modKP.Public,
+		BoardID:   "other-board",
+		Scopes:    []string{ModeratorScopeTombstone},
+		NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedBy:  ownerKP.Public,
+	}
+	if err := SignModeratorKeyIssuance(ownerKP.Private, &key); err != nil {
+		t.Fatalf("SignModeratorKeyIssuance: %v", err)
+	}
+
+	oldCid := seedModeratorBoard(t, "board-1", ownerKP, authorKP, key)
+
+	tombstonedAt := "2025-12-19T01:00:00Z"
+	payload := ModeratorTombstoneSignPayload(1, "board-1", "thread-1", oldCid, "mod-1", tombstonedAt)
+	sig, err := SignPayloadEd25519(modKP.Private, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+
+	reqBody := map[string]any{
+		"version":            1,
+		"threadId":           "thread-1",
+		"boardId":            "board-1",
+		"tombstonedAt":       tombstonedAt,
+		"moderatorKeyId":     "mod-1",
+		"moderatorSignature": sig,
+	}
+	b, _ := json.Marshal(reqBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handlePostActions(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status=%d body=%s, want 403 for a key bound to a different board", w.Code, w.Body.String())
+	}
+}
+
+func TestTombstonePost_ModeratorKey_Revoked(t *testing.T) {
+	ownerKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	authorKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	modKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	key := ModeratorKey{
+		KeyID:     "mod-1",
+		PubKey:    modKP.Public,
+		BoardID:   "board-1",
+		Scopes:    []string{ModeratorScopeTombstone},
+		NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		IssuedBy:  ownerKP.Public,
+		Revoked:   true,
+	}
+	if err := SignModeratorKeyIssuance(ownerKP.Private, &key); err != nil {
+		t.Fatalf("SignModeratorKeyIssuance: %v", err)
+	}
+
+	oldCid := seedModeratorBoard(t, "board-1", ownerKP, authorKP, key)
+
+	tombstonedAt := "2025-12-19T01:00:00Z"
+	payload := ModeratorTombstoneSignPayload(1, "board-1", "thread-1", oldCid, "mod-1", tombstonedAt)
+	sig, err := SignPayloadEd25519(modKP.Private, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+
+	reqBody := map[string]any{
+		"version":            1,
+		"threadId":           "thread-1",
+		"boardId":            "board-1",
+		"tombstonedAt":       tombstonedAt,
+		"moderatorKeyId":     "mod-1",
+		"moderatorSignature": sig,
+	}
+	b, _ := json.Marshal(reqBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handlePostActions(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status=%d body=%s, want 403 for a revoked moderator key", w.Code, w.Body.String())
+	}
+}
+
+func TestTombstonePost_RejectsBothAuthorAndModeratorSignature(t *testing.T) {
+	resetPostsStoreForTests()
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	oldCid := "sha256:old"
+	seedPostForTests(poststore.StoredPost{
+		CID: oldCid,
+		Post: poststore.Post{
+			ThreadID:     "thread-1",
+			AuthorPubKey: kp.Public,
+			BodyFormat:   "text/plain",
+			BodyContent:  "hello",
+			CreatedAt:    "2025-12-19T00:00:00Z",
+		},
+	})
+
+	reqBody := map[string]any{
+		"version":            1,
+		"threadId":           "thread-1",
+		"boardId":            "board-1",
+		"authorPubKey":       kp.Public,
+		"tombstonedAt":       "2025-12-19T01:00:00Z",
+		"signature":          "author-sig",
+		"moderatorKeyId":     "mod-1",
+		"moderatorSignature": "mod-sig",
+	}
+	b, _ := json.Marshal(reqBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts/"+oldCid+"/tombstone", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handlePostActions(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s, want 400 for a request with both signature kinds", w.Code, w.Body.String())
+	}
+}
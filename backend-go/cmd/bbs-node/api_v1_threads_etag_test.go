@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testThreadETagGetter struct {
+	testThreadGetter
+	etag    string
+	lastMod time.Time
+	etagErr error
+}
+
+func (g testThreadETagGetter) GetThreadETag(ctx context.Context, threadID string) (string, time.Time, error) {
+	if g.etagErr != nil {
+		return "", time.Time{}, g.etagErr
+	}
+	return g.etag, g.lastMod, nil
+}
+
+func TestIfNoneMatchSatisfied(t *testing.T) {
+	cases := []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{"", `W/"abc"`, false},
+		{"*", `W/"abc"`, true},
+		{`W/"abc"`, `W/"abc"`, true},
+		{`"abc"`, `W/"abc"`, true},
+		{`W/"abc", W/"def"`, `W/"def"`, true},
+		{`W/"xyz"`, `W/"abc"`, false},
+	}
+	for _, c := range cases {
+		if got := ifNoneMatchSatisfied(c.ifNoneMatch, c.etag); got != c.want {
+			t.Errorf("ifNoneMatchSatisfied(%q, %q) = %v, want %v", c.ifNoneMatch, c.etag, got, c.want)
+		}
+	}
+}
+
+func TestComputeThreadETag_StableForIdenticalResponse(t *testing.T) {
+	resp := GetThreadResponse{
+		Thread:    ThreadDetail{ThreadID: "t1"},
+		ThreadLog: []ThreadLogEntry{{SeqNum: 1, Op: "create_thread", CreatedAt: "2024-01-01T00:00:00Z"}},
+		Posts:     []PostView{{CID: "cid1"}},
+	}
+	etag1, lastMod1 := computeThreadETag(resp)
+	etag2, lastMod2 := computeThreadETag(resp)
+	if etag1 != etag2 || etag1 == "" {
+		t.Fatalf("etag not stable/derived: %q vs %q", etag1, etag2)
+	}
+	if !lastMod1.Equal(lastMod2) || lastMod1.IsZero() {
+		t.Fatalf("lastMod not stable/derived: %v vs %v", lastMod1, lastMod2)
+	}
+
+	resp.Posts = append(resp.Posts, PostView{CID: "cid2"})
+	etag3, _ := computeThreadETag(resp)
+	if etag3 == etag1 {
+		t.Fatalf("expected etag to change when response content changes")
+	}
+}
+
+func TestGetThread_ETagFastPath_Returns304(t *testing.T) {
+	orig := threadGetter
+	defer func() { threadGetter = orig }()
+	threadGetter = testThreadETagGetter{etag: `W/"fixed-etag"`}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1", nil)
+	r.Header.Set("If-None-Match", `W/"fixed-etag"`)
+	w := httptest.NewRecorder()
+	handleGetThread(w, r)
+
+	if w.Result().StatusCode != http.StatusNotModified {
+		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
+	}
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected empty body on 304, got %q", w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != `W/"fixed-etag"` {
+		t.Fatalf("ETag header = %q", got)
+	}
+}
+
+func TestGetThread_ETagFastPath_MismatchFallsThroughTo200(t *testing.T) {
+	orig := threadGetter
+	defer func() { threadGetter = orig }()
+	threadGetter = testThreadETagGetter{etag: `W/"current-etag"`}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1", nil)
+	r.Header.Set("If-None-Match", `W/"stale-etag"`)
+	w := httptest.NewRecorder()
+	handleGetThread(w, r)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=0, must-revalidate" {
+		t.Fatalf("Cache-Control header = %q", got)
+	}
+}
+
+func TestGetThread_NoETagSupport_ComputesFromFullResponse(t *testing.T) {
+	orig := threadGetter
+	defer func() { threadGetter = orig }()
+	threadGetter = defaultThreadGetter{}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1", nil)
+	w := httptest.NewRecorder()
+	handleGetThread(w, r)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header to be set")
+	}
+
+	r2 := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1", nil)
+	r2.Header.Set("If-None-Match", etag)
+	w2 := httptest.NewRecorder()
+	handleGetThread(w2, r2)
+	if w2.Result().StatusCode != http.StatusNotModified {
+		t.Fatalf("second request status=%d, want 304 (no fast-path getter)", w2.Result().StatusCode)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- API: GET /api/v1/threads/{threadId}/events ---
+
+// heartbeatInterval is how often the SSE stream emits a comment line so
+// intermediaries (proxies, load balancers) don't time out an idle
+// connection and drop it.
+const heartbeatInterval = 15 * time.Second
+
+// defaultLongPollWait is how long a long-polling request blocks waiting for
+// a new entry when ?wait= is omitted; maxLongPollWait bounds how long it can
+// ask to block for.
+const (
+	defaultLongPollWait = 30 * time.Second
+	maxLongPollWait     = 60 * time.Second
+)
+
+// ThreadWatcher は threadId の ThreadLog に新しいエントリが追加されるのを
+// 監視する責務。sinceSeq より後のエントリだけをチャネルに流す。
+// ThreadGetter 同様、実装はテストや本実装で差し替え可能にしている。
+type ThreadWatcher interface {
+	Subscribe(ctx context.Context, threadID string, sinceSeq int64) (<-chan ThreadLogEntry, error)
+}
+
+// threadWatcher はハンドラが使う実装(テストで差し替え可能)。
+var threadWatcher ThreadWatcher = newPollingThreadWatcher(threadGetter, 500*time.Millisecond)
+
+// pollingThreadWatcher implements ThreadWatcher by polling a ThreadGetter on
+// an interval and diffing against the last SeqNum it has seen. It's the
+// simplest implementation that works against any ThreadGetter, including
+// defaultThreadGetter's empty stub; a future IPFS pubsub-backed ThreadWatcher
+// can replace it without the handler changing.
+type pollingThreadWatcher struct {
+	getter   ThreadGetter
+	interval time.Duration
+}
+
+func newPollingThreadWatcher(getter ThreadGetter, interval time.Duration) *pollingThreadWatcher {
+	return &pollingThreadWatcher{getter: getter, interval: interval}
+}
+
+// Subscribe returns a channel fed by a background goroutine that polls
+// w.getter.GetThread every w.interval and forwards any ThreadLogEntry whose
+// SeqNum is greater than the highest one forwarded so far (starting from
+// sinceSeq). The goroutine exits, closing the channel, once ctx is done.
+func (w *pollingThreadWatcher) Subscribe(ctx context.Context, threadID string, sinceSeq int64) (<-chan ThreadLogEntry, error) {
+	ch := make(chan ThreadLogEntry)
+	go func() {
+		defer close(ch)
+		last := sinceSeq
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				resp, err := w.getter.GetThread(ctx, threadID)
+				if err != nil {
+					continue
+				}
+				for _, e := range resp.ThreadLog {
+					if e.SeqNum <= last {
+						continue
+					}
+					select {
+					case ch <- e:
+						last = e.SeqNum
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// handleThreadEvents streams new ThreadLogEntry items for threadID. Clients
+// that send "Accept: application/json" get long-polling: the request blocks
+// (respecting ?wait=, capped at maxLongPollWait) until a new entry arrives
+// or the deadline fires, then returns a JSON array (possibly empty). Every
+// other client gets Server-Sent Events: a "data: " line per entry plus a
+// ": heartbeat" comment every heartbeatInterval so intermediaries don't drop
+// the connection while the thread is quiet.
+func handleThreadEvents(w http.ResponseWriter, r *http.Request, threadID string) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	since, err := parseSinceSeq(r)
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		handleThreadEventsLongPoll(w, r, threadID, since)
+		return
+	}
+	handleThreadEventsSSE(w, r, threadID, since)
+}
+
+// parseThreadEventsIDFromPath extracts {threadId} from
+// /api/v1/threads/{threadId}/events, returning ok=false for any other path.
+func parseThreadEventsIDFromPath(path string) (string, bool) {
+	const prefix = "/api/v1/threads/"
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(path, prefix)
+	threadID, ok := strings.CutSuffix(rest, "/events")
+	if !ok || threadID == "" || strings.Contains(threadID, "/") {
+		return "", false
+	}
+	return threadID, true
+}
+
+func parseSinceSeq(r *http.Request) (int64, error) {
+	raw := r.URL.Query().Get("since")
+	if raw == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid since: %w", err)
+	}
+	return seq, nil
+}
+
+func parseWait(r *http.Request) (time.Duration, error) {
+	raw := r.URL.Query().Get("wait")
+	if raw == "" {
+		return defaultLongPollWait, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid wait: %w", err)
+	}
+	if d > maxLongPollWait {
+		d = maxLongPollWait
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d, nil
+}
+
+// handleThreadEventsLongPoll blocks until a new entry arrives or ?wait=
+// elapses, then responds with a JSON array of the entries collected (empty
+// if the deadline fired first).
+func handleThreadEventsLongPoll(w http.ResponseWriter, r *http.Request, threadID string, since int64) {
+	wait, err := parseWait(r)
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+
+	ch, err := threadWatcher.Subscribe(ctx, threadID, since)
+	if err != nil {
+		writeJSONError(w, r, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+
+	entries := []ThreadLogEntry{}
+	select {
+	case e, ok := <-ch:
+		if ok {
+			entries = append(entries, e)
+		}
+	case <-ctx.Done():
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleThreadEventsSSE streams entries as Server-Sent Events until the
+// client disconnects.
+func handleThreadEventsSSE(w http.ResponseWriter, r *http.Request, threadID string, since int64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, r, http.StatusNotImplemented, codeNotImplemented, "streaming not supported")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	ch, err := threadWatcher.Subscribe(ctx, threadID, since)
+	if err != nil {
+		writeJSONError(w, r, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", b)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeThreadWatcher struct {
+	ch  chan ThreadLogEntry
+	err error
+}
+
+func (w fakeThreadWatcher) Subscribe(ctx context.Context, threadID string, sinceSeq int64) (<-chan ThreadLogEntry, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.ch, nil
+}
+
+func TestParseSinceSeq(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events", nil)
+	if got, err := parseSinceSeq(r); err != nil || got != 0 {
+		t.Fatalf("no since: got=%d err=%v, want 0,nil", got, err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?since=42", nil)
+	if got, err := parseSinceSeq(r); err != nil || got != 42 {
+		t.Fatalf("since=42: got=%d err=%v, want 42,nil", got, err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?since=abc", nil)
+	if _, err := parseSinceSeq(r); err == nil {
+		t.Fatalf("since=abc: expected error")
+	}
+}
+
+func TestParseWait(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events", nil)
+	if got, err := parseWait(r); err != nil || got != defaultLongPollWait {
+		t.Fatalf("no wait: got=%v err=%v, want %v,nil", got, err, defaultLongPollWait)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?wait=5s", nil)
+	if got, err := parseWait(r); err != nil || got != 5*time.Second {
+		t.Fatalf("wait=5s: got=%v err=%v, want 5s,nil", got, err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?wait=5m", nil)
+	if got, err := parseWait(r); err != nil || got != maxLongPollWait {
+		t.Fatalf("wait=5m: got=%v err=%v, want capped at %v", got, err, maxLongPollWait)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?wait=nonsense", nil)
+	if _, err := parseWait(r); err == nil {
+		t.Fatalf("wait=nonsense: expected error")
+	}
+}
+
+func TestParseThreadEventsIDFromPath(t *testing.T) {
+	cases := []struct {
+		path   string
+		wantID string
+		wantOK bool
+	}{
+		{"/api/v1/threads/t1/events", "t1", true},
+		{"/api/v1/threads/t1", "", false},
+		{"/api/v1/threads//events", "", false},
+		{"/api/v1/threads/t1/events/extra", "", false},
+		{"/other", "", false},
+	}
+	for _, c := range cases {
+		id, ok := parseThreadEventsIDFromPath(c.path)
+		if id != c.wantID || ok != c.wantOK {
+			t.Errorf("parseThreadEventsIDFromPath(%q) = %q,%v; want %q,%v", c.path, id, ok, c.wantID, c.wantOK)
+		}
+	}
+}
+
+func TestPollingThreadWatcher_Subscribe_FiltersBySeqNum(t *testing.T) {
+	getter := testThreadGetter{resp: GetThreadResponse{
+		ThreadLog: []ThreadLogEntry{
+			{SeqNum: 1, Op: "create_thread"},
+			{SeqNum: 2, Op: "create_post"},
+			{SeqNum: 3, Op: "create_post"},
+		},
+	}}
+	w := newPollingThreadWatcher(getter, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch, err := w.Subscribe(ctx, "t1", 1)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	var got []ThreadLogEntry
+	for len(got) < 2 {
+		select {
+		case e := <-ch:
+			got = append(got, e)
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for entries, got %d", len(got))
+		}
+	}
+	if got[0].SeqNum != 2 || got[1].SeqNum != 3 {
+		t.Fatalf("got seqnums %d,%d want 2,3", got[0].SeqNum, got[1].SeqNum)
+	}
+}
+
+func TestHandleThreadEvents_LongPoll_ReturnsEntryWhenAvailable(t *testing.T) {
+	orig := threadWatcher
+	defer func() { threadWatcher = orig }()
+	ch := make(chan ThreadLogEntry, 1)
+	ch <- ThreadLogEntry{SeqNum: 5, Op: "create_post"}
+	threadWatcher = fakeThreadWatcher{ch: ch}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	handleThreadEvents(w, r, "t1")
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.StatusCode, w.Body.String())
+	}
+
+	var out []ThreadLogEntry
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out) != 1 || out[0].SeqNum != 5 {
+		t.Fatalf("got %+v, want one entry with SeqNum=5", out)
+	}
+}
+
+func TestHandleThreadEvents_LongPoll_EmptyAfterDeadline(t *testing.T) {
+	orig := threadWatcher
+	defer func() { threadWatcher = orig }()
+	threadWatcher = fakeThreadWatcher{ch: make(chan ThreadLogEntry)}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events?wait=10ms", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	handleThreadEvents(w, r, "t1")
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.StatusCode, w.Body.String())
+	}
+
+	var out []ThreadLogEntry
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("got %+v, want empty array", out)
+	}
+}
+
+func TestHandleThreadEvents_SSE_StreamsEntry(t *testing.T) {
+	orig := threadWatcher
+	defer func() { threadWatcher = orig }()
+	ch := make(chan ThreadLogEntry, 1)
+	ch <- ThreadLogEntry{SeqNum: 7, Op: "create_post"}
+	threadWatcher = fakeThreadWatcher{ch: ch}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/t1/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handleThreadEvents(w, r, "t1")
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"seqNum":7`) {
+		t.Fatalf("body missing streamed entry: %s", body)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("Content-Type=%q, want text/event-stream", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandleThreadEvents_MethodNotAllowed(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/threads/t1/events", nil)
+	w := httptest.NewRecorder()
+	handleThreadEvents(w, r, "t1")
+
+	if w.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
+	}
+}
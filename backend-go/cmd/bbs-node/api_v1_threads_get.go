@@ -20,8 +20,10 @@ type ThreadDetail struct {
 }
 
 // ThreadLogEntry はスレッド内の変更履歴を表す(最低限 op と createdAt)。
+// SeqNum は /api/v1/threads/{threadId}/events の since カーソルに使う採番。
 // TODO: 仕様確定後に cid/postCid/old/new などを追加する。
 type ThreadLogEntry struct {
+	SeqNum    int64  `json:"seqNum"`
 	Op        string `json:"op"`
 	CreatedAt string `json:"createdAt"`
 }
@@ -53,6 +55,10 @@ type ThreadGetter interface {
 // あるいは別ファイルの init() で `threadGetter = flexIPFSThreadGetter{...}` を実行。
 //
 // まずは「APIが200で返る」ことを優先して、デフォルトは空のデータを返す。
+//
+// internal/indexer をデータソースにする場合は、BoardLogEntry の列を
+// ThreadLogFromBoardLog(entries, pub) に通すこと。署名またはハッシュチェーンの
+// 検証に失敗したエントリ以降は破棄され、ThreadLog には公開されない。
 var threadGetter ThreadGetter = defaultThreadGetter{}
 
 type defaultThreadGetter struct{}
@@ -69,36 +75,77 @@ func (defaultThreadGetter) GetThread(ctx context.Context, threadID string) (GetT
 // init は main.go を触らずに DefaultServeMux にルーティングを登録する。
 func init() {
 	// main.go を触らずに DefaultServeMux にルーティングを足す。
-	// 末尾スラッシュ付きで prefix マッチさせる。
-	http.HandleFunc("/api/v1/threads/", handleGetThread)
+	// 末尾スラッシュ付きで prefix マッチさせる。withRequestID はエラー
+	// レスポンスの requestId を埋めるため、すべてのエントリポイントをここで包む。
+	http.HandleFunc("/api/v1/threads/", withRequestID(handleGetThread))
 }
 
 // handleGetThread は GET /api/v1/threads/{threadId} を処理して JSON を返す。
+// {threadId}/events は handleThreadEvents に委譲する(同じ prefix に対して
+// http.HandleFunc を二重登録できないため、ここで振り分ける)。
 func handleGetThread(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		writeJSONError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if threadID, ok := parseThreadEventsIDFromPath(r.URL.Path); ok {
+		handleThreadEvents(w, r, threadID)
 		return
 	}
 
 	threadID, ok := parseThreadIDFromPath(r.URL.Path)
 	if !ok {
-		writeJSONError(w, http.StatusNotFound, "not found")
+		writeJSONError(w, r, http.StatusNotFound, codeNotFound, "not found")
 		return
 	}
 
-	resp, err := threadGetter.GetThread(r.Context(), threadID)
-	if err != nil {
-		if errors.Is(err, ErrThreadNotFound) {
-			writeJSONError(w, http.StatusNotFound, "thread not found")
+	ifNoneMatch := r.Header.Get("If-None-Match")
+
+	// Fast path: a ThreadGetter backed by the log chain can answer a 304
+	// from just the latest SeqNum/hash, without materializing Posts.
+	if etagGetter, ok := threadGetter.(ThreadETagGetter); ok {
+		etag, lastMod, err := etagGetter.GetThreadETag(r.Context(), threadID)
+		if err != nil {
+			writeThreadGetterError(w, r, err)
 			return
 		}
-		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		if ifNoneMatchSatisfied(ifNoneMatch, etag) {
+			setConditionalHeaders(w, etag, lastMod)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	resp, err := threadGetter.GetThread(r.Context(), threadID)
+	if err != nil {
+		writeThreadGetterError(w, r, err)
 		return
 	}
 
+	etag, lastMod := computeThreadETag(resp)
+	if ifNoneMatchSatisfied(ifNoneMatch, etag) {
+		setConditionalHeaders(w, etag, lastMod)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	setConditionalHeaders(w, etag, lastMod)
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// writeThreadGetterError maps an error returned by ThreadGetter.GetThread or
+// ThreadETagGetter.GetThreadETag to the right status and jsonProblem code:
+// ErrThreadNotFound (and any other sentinel errorCode recognizes) gets its
+// own status, everything else is a 500 with codeInternal.
+func writeThreadGetterError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrThreadNotFound) {
+		writeJSONError(w, r, http.StatusNotFound, errorCode(err), "thread not found")
+		return
+	}
+	writeJSONError(w, r, http.StatusInternalServerError, errorCode(err), err.Error())
+}
+
 // parseThreadIDFromPath は URL パスから threadId を抽出する。
 // /api/v1/threads/{id} 以外は false を返す。
 func parseThreadIDFromPath(path string) (string, bool) {
@@ -117,9 +164,36 @@ func parseThreadIDFromPath(path string) (string, bool) {
 	return rest, true
 }
 
-// jsonError は JSON エラーレスポンスの形。
-type jsonError struct {
-	Error string `json:"error"`
+// Stable machine-readable codes for jsonProblem.Code. Clients should switch
+// on these, not on Message, which is free-form and may change wording.
+const (
+	codeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	codeNotFound         = "NOT_FOUND"
+	codeThreadNotFound   = "THREAD_NOT_FOUND"
+	codeBadRequest       = "BAD_REQUEST"
+	codeInternal         = "INTERNAL"
+	codeNotImplemented   = "NOT_IMPLEMENTED"
+)
+
+// errorCode maps a sentinel error to its jsonProblem code. Errors with no
+// specific mapping fall back to codeInternal.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrThreadNotFound):
+		return codeThreadNotFound
+	default:
+		return codeInternal
+	}
+}
+
+// jsonProblem は problem+json 風のエラーレスポンスの形。requestId は
+// withRequestID が振ったリクエストIDで、サーバログとの突き合わせに使う。
+// details は任意の補助情報(省略時は省く)。
+type jsonProblem struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+	Details   any    `json:"details,omitempty"`
 }
 
 // writeJSON は任意の値を JSON で返す共通ヘルパー。
@@ -129,7 +203,18 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-// writeJSONError はエラー用の JSON を返す共通ヘルパー。
-func writeJSONError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, jsonError{Error: msg})
+// writeJSONError はエラー用の JSON を返す共通ヘルパー。details が必要な
+// 場合は writeJSONProblem を直接使うこと。
+func writeJSONError(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	writeJSONProblem(w, r, status, code, msg, nil)
+}
+
+// writeJSONProblem はリクエストIDを添えた problem+json 風のエラーを返す。
+func writeJSONProblem(w http.ResponseWriter, r *http.Request, status int, code, msg string, details any) {
+	writeJSON(w, status, jsonProblem{
+		Code:      code,
+		Message:   msg,
+		RequestID: requestIDFromContext(r.Context()),
+		Details:   details,
+	})
 }
@@ -25,7 +25,9 @@ func (g testThreadGetter) GetThread(ctx context.Context, threadID string) (GetTh
 }
 
 type jsonErr struct {
-	Error string `json:"error"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
 }
 
 func TestGetThread_OK_DefaultGetter(t *testing.T) {
@@ -67,8 +69,8 @@ func TestGetThread_NotFound_InvalidPath(t *testing.T) {
 
 	var out jsonErr
 	_ = json.NewDecoder(resp.Body).Decode(&out)
-	if out.Error == "" {
-		t.Fatalf("expected error body")
+	if out.Code != codeNotFound || out.Message == "" {
+		t.Fatalf("expected jsonProblem body, got %+v", out)
 	}
 }
 
@@ -107,6 +109,14 @@ func TestGetThread_ThreadNotFound_FromGetter(t *testing.T) {
 	if resp.StatusCode != http.StatusNotFound {
 		t.Fatalf("status=%d body=%s", resp.StatusCode, w.Body.String())
 	}
+
+	var out jsonErr
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Code != codeThreadNotFound {
+		t.Fatalf("code=%q, want %q", out.Code, codeThreadNotFound)
+	}
 }
 
 func TestGetThread_InternalError_FromGetter(t *testing.T) {
@@ -122,3 +132,25 @@ func TestGetThread_InternalError_FromGetter(t *testing.T) {
 		t.Fatalf("status=%d body=%s", w.Result().StatusCode, w.Body.String())
 	}
 }
+
+func TestGetThread_ErrorBody_CarriesRequestID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/threads/", nil)
+	r.Header.Set(requestIDHeader, "req-fixed-1")
+	w := httptest.NewRecorder()
+	withRequestID(handleGetThread)(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get(requestIDHeader); got != "req-fixed-1" {
+		t.Fatalf("response %s header = %q, want echoed client value", requestIDHeader, got)
+	}
+
+	var out jsonErr
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.RequestID != "req-fixed-1" {
+		t.Fatalf("body requestId = %q, want %q", out.RequestID, "req-fixed-1")
+	}
+}
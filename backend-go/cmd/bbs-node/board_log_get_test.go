@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleGetBoardLog_Since_WalksBackwardsFromHead(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+
+	cid1, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "createThread", nil))
+	if err != nil {
+		t.Fatalf("AppendEntry(1): %v", err)
+	}
+	cid2, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "addPost", &cid1))
+	if err != nil {
+		t.Fatalf("AppendEntry(2): %v", err)
+	}
+	cid3, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "addPost", &cid2))
+	if err != nil {
+		t.Fatalf("AppendEntry(3): %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards/board-1/log?since="+cid1, nil)
+	w := httptest.NewRecorder()
+	handleBoardLogHTTP(w, r, "board-1", logs)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.StatusCode, w.Body.String())
+	}
+	var page boardLogPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Entries) != 2 {
+		t.Fatalf("len(Entries)=%d, want 2 (cid3, cid2)", len(page.Entries))
+	}
+	if page.Entries[0].CID != cid3 || page.Entries[1].CID != cid2 {
+		t.Fatalf("Entries=%v, want [cid3=%s, cid2=%s] newest-first", page.Entries, cid3, cid2)
+	}
+}
+
+func TestHandleGetBoardLog_Since_Empty_WalksToGenesis(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+
+	cid1, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "createThread", nil))
+	if err != nil {
+		t.Fatalf("AppendEntry(1): %v", err)
+	}
+	cid2, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "addPost", &cid1))
+	if err != nil {
+		t.Fatalf("AppendEntry(2): %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards/board-1/log?since=", nil)
+	w := httptest.NewRecorder()
+	handleBoardLogHTTP(w, r, "board-1", logs)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	var page boardLogPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Entries) != 2 || page.Entries[0].CID != cid2 || page.Entries[1].CID != cid1 {
+		t.Fatalf("Entries=%v, want [cid2, cid1] newest-first to genesis", page.Entries)
+	}
+}
+
+func TestHandleGetBoardLog_Since_UnknownCID_BadRequest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	if _, err := logs.AppendEntry("board-1", signedBoardLogEntry(t, pub, priv, "board-1", "createThread", nil)); err != nil {
+		t.Fatalf("AppendEntry: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards/board-1/log?since=does-not-exist", nil)
+	w := httptest.NewRecorder()
+	handleBoardLogHTTP(w, r, "board-1", logs)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("status=%d, want 400", w.Result().StatusCode)
+	}
+}
+
+func TestRegisterBoardsHTTP_LogStreamRoutesToSSE(t *testing.T) {
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	logs.Hub = NewBoardLogHub()
+	mux := http.NewServeMux()
+	registerBoardsHTTP(mux, []BoardMeta{{BoardID: "board-1"}}, logs, boardsTimeouts{})
+
+	// The SSE loop only returns once its request context is done (or the
+	// hub closes, or a heartbeat/event fires); cancel up front so
+	// ServeHTTP returns as soon as it's written headers and flushed the
+	// (empty) backlog, instead of hanging on the 15s heartbeat ticker.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards/board-1/log/stream", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if ct := w.Result().Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type=%q, want text/event-stream", ct)
+	}
+}
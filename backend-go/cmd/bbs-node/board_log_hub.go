@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// --- In-process pub/sub hub for newly appended BoardLogEntry records ---
+
+// boardLogHubBufferSize bounds how many not-yet-delivered entries a single
+// subscriber can have queued before BoardLogHub.Publish starts dropping the
+// oldest ones to make room for the newest.
+const boardLogHubBufferSize = 32
+
+// BoardLogHub fans out entries BoardLogStore.AppendEntry just accepted to
+// every live subscriber of that board, without touching disk — callers
+// that need history before "now" get it from BoardLogStore.List and
+// resume live updates from there (see handleBoardEventsSSE). It has no
+// opinion on transport; it's consumed by the boards HTTP SSE handler
+// today, but nothing here is HTTP-specific.
+//
+// BoardLogHub is safe for concurrent use.
+type BoardLogHub struct {
+	mu   sync.Mutex
+	subs map[string]map[*boardLogSubscriber]struct{} // boardID -> subscribers
+}
+
+type boardLogSubscriber struct {
+	ch chan BoardLogEntryWithCID
+}
+
+// NewBoardLogHub returns an empty hub.
+func NewBoardLogHub() *BoardLogHub {
+	return &BoardLogHub{subs: make(map[string]map[*boardLogSubscriber]struct{})}
+}
+
+// Subscribe registers for boardID's future Publish calls. The returned
+// channel is closed when ctx is done; callers must call the returned
+// unsubscribe func (e.g. via defer) once they stop reading from it so the
+// hub can forget about them.
+func (h *BoardLogHub) Subscribe(ctx context.Context, boardID string) (<-chan BoardLogEntryWithCID, func()) {
+	sub := &boardLogSubscriber{ch: make(chan BoardLogEntryWithCID, boardLogHubBufferSize)}
+
+	h.mu.Lock()
+	if h.subs[boardID] == nil {
+		h.subs[boardID] = make(map[*boardLogSubscriber]struct{})
+	}
+	h.subs[boardID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		delete(h.subs[boardID], sub)
+		if len(h.subs[boardID]) == 0 {
+			delete(h.subs, boardID)
+		}
+		h.mu.Unlock()
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return sub.ch, unsubscribe
+}
+
+// Publish fans entry out to every current subscriber of boardID. A
+// subscriber whose buffer is already full (a slow consumer) has its
+// oldest buffered entry dropped to make room for entry, rather than
+// Publish blocking on it — a slow reader loses history, it doesn't stall
+// the writer that triggered this Publish call.
+func (h *BoardLogHub) Publish(boardID string, entry BoardLogEntryWithCID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[boardID] {
+		select {
+		case sub.ch <- entry:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- entry:
+			default:
+			}
+		}
+	}
+}
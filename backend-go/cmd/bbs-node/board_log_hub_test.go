@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBoardLogHub_PublishDeliversToSubscriber(t *testing.T) {
+	h := NewBoardLogHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := h.Subscribe(ctx, "board-1")
+	defer unsubscribe()
+
+	h.Publish("board-1", BoardLogEntryWithCID{CID: "cid-1"})
+
+	select {
+	case e := <-ch:
+		if e.CID != "cid-1" {
+			t.Fatalf("got CID %q, want cid-1", e.CID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for published entry")
+	}
+}
+
+func TestBoardLogHub_PublishIgnoresOtherBoards(t *testing.T) {
+	h := NewBoardLogHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := h.Subscribe(ctx, "board-1")
+	defer unsubscribe()
+
+	h.Publish("board-2", BoardLogEntryWithCID{CID: "cid-1"})
+
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected delivery for unrelated board: %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestBoardLogHub_PublishDropsOldestWhenSubscriberFalledBehind(t *testing.T) {
+	h := NewBoardLogHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := h.Subscribe(ctx, "board-1")
+	defer unsubscribe()
+
+	// Fill the subscriber's buffer, then publish one more than it can
+	// hold — the oldest buffered entry (seq 0) should be dropped to make
+	// room for the newest.
+	for i := 0; i < boardLogHubBufferSize+1; i++ {
+		h.Publish("board-1", BoardLogEntryWithCID{CID: string(rune('a' + i))})
+	}
+
+	var got []string
+	for len(got) < boardLogHubBufferSize {
+		select {
+		case e := <-ch:
+			got = append(got, e.CID)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out draining buffer, got %d entries", len(got))
+		}
+	}
+	if got[0] == "a" {
+		t.Fatalf("expected oldest entry to have been dropped, but got it: %v", got)
+	}
+	if got[len(got)-1] != string(rune('a'+boardLogHubBufferSize)) {
+		t.Fatalf("expected most recent entry to survive, got %v", got)
+	}
+}
+
+func TestBoardLogHub_UnsubscribeStopsDelivery(t *testing.T) {
+	h := NewBoardLogHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, unsubscribe := h.Subscribe(ctx, "board-1")
+	unsubscribe()
+	cancel()
+
+	h.Publish("board-1", BoardLogEntryWithCID{CID: "cid-1"})
+
+	select {
+	case e, ok := <-ch:
+		if ok {
+			t.Fatalf("unexpected delivery after unsubscribe: %+v", e)
+		}
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"flex-bbs/backend-go/bbs/attachments"
+)
+
+// --- Append-only per-board BoardLogEntry log, hash-chained by CID ---
+
+var (
+	// ErrBoardLogNotFound is Head's error for a board with no entries
+	// appended yet.
+	ErrBoardLogNotFound = errors.New("board log: no entries")
+
+	// ErrBoardLogPrevMismatch is AppendEntry's error when entry.PrevLogCid
+	// doesn't match the board's current head, i.e. the caller signed
+	// against a head that's since moved (a concurrent writer won the
+	// race, or the caller's view of the chain is stale).
+	ErrBoardLogPrevMismatch = errors.New("board log: prevLogCid does not match current head")
+
+	// ErrBoardLogBroken is Verify's error for a chain whose prevLogCid
+	// linkage or signature doesn't hold up under replay.
+	ErrBoardLogBroken = errors.New("board log: chain verification failed")
+
+	// ErrBoardLogSignatureInvalid is AppendEntry's error when entry's
+	// Signature doesn't verify against its own AuthorPubKey.
+	ErrBoardLogSignatureInvalid = errors.New("board log: invalid signature")
+)
+
+// ComputeBoardLogEntryCID derives entry's content-addressed CID: SHA-256
+// over entry.CanonicalSignPayload() followed by entry's raw (decoded)
+// Ed25519 signature bytes, encoded the same way bbs/attachments encodes a
+// blob CID (CIDv1, raw codec, sha2-256 multihash, base32). Folding the
+// signature into the hash means two entries with identical payloads but
+// different signers (or re-signed by the same key) land on different
+// CIDs, which is what lets prevLogCid pin down one specific signed entry
+// rather than just its content.
+func ComputeBoardLogEntryCID(entry *BoardLogEntry) (string, error) {
+	sigBytes, err := decodeEd25519Field(entry.Signature)
+	if err != nil {
+		return "", fmt.Errorf("signature: %w", err)
+	}
+	digest := sha256.Sum256(append([]byte(entry.CanonicalSignPayload()), sigBytes...))
+	return attachments.EncodeCIDv1Raw(digest), nil
+}
+
+// BoardLogEntryWithCID pairs a BoardLogEntry with the CID it was stored
+// under (ComputeBoardLogEntryCID's result at append time).
+type BoardLogEntryWithCID struct {
+	CID   string
+	Entry *BoardLogEntry
+}
+
+// BoardLogStore persists one append-only BoardLogEntry log per board as
+// newline-delimited JSON under Dir ("<boardId>.jsonl"), one file per
+// board. Each accepted entry embeds PrevLogCid = the CID of the entry
+// appended before it (or nil for the first), so AppendEntry and Verify
+// together give a hash-chained, signed history: AppendEntry refuses an
+// entry whose PrevLogCid doesn't match the board's current head, and
+// Verify replays a board's file from genesis re-checking that linkage and
+// every entry's signature.
+//
+// A single store is meant to be shared across a process, not created per
+// call — mirrors config.BoardsStore.
+type BoardLogStore struct {
+	Dir string
+
+	// Hub, if non-nil, is notified of every entry AppendEntry accepts
+	// (see handleBoardEventsSSE in api_v1_boards_events.go). It's left
+	// nil by NewBoardLogStore; callers that want live updates set it
+	// explicitly after construction.
+	Hub *BoardLogHub
+
+	mu    sync.Mutex
+	heads map[string]string // boardID -> head CID, lazily populated
+}
+
+// NewBoardLogStore returns a store persisting board logs under dir.
+func NewBoardLogStore(dir string) *BoardLogStore {
+	return &BoardLogStore{
+		Dir:   dir,
+		heads: make(map[string]string),
+	}
+}
+
+func (s *BoardLogStore) path(boardID string) string {
+	return filepath.Join(s.Dir, boardID+".jsonl")
+}
+
+// readEntries loads every entry in boardID's log file in append order
+// (genesis first). A missing file is treated as an empty log, not an
+// error.
+func (s *BoardLogStore) readEntries(boardID string) ([]BoardLogEntryWithCID, error) {
+	f, err := os.Open(s.path(boardID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []BoardLogEntryWithCID
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry BoardLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("board log %s: %w", boardID, err)
+		}
+		cid, err := ComputeBoardLogEntryCID(&entry)
+		if err != nil {
+			return nil, fmt.Errorf("board log %s: %w", boardID, err)
+		}
+		out = append(out, BoardLogEntryWithCID{CID: cid, Entry: &entry})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("board log %s: %w", boardID, err)
+	}
+	return out, nil
+}
+
+// List returns boardID's full entry history in append order (genesis
+// first), for callers paginating it themselves (see
+// handleGetBoardLog in boards_api.go).
+func (s *BoardLogStore) List(boardID string) ([]BoardLogEntryWithCID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readEntries(boardID)
+}
+
+// ListCtx is List, but bails out early with ctx.Err() if ctx is already
+// done before the read starts or becomes done while it's in flight,
+// rather than paging through (or returning) a result a deadline-bound
+// caller (see withBoardsTimeout) no longer has time to use.
+func (s *BoardLogStore) ListCtx(ctx context.Context, boardID string) ([]BoardLogEntryWithCID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Head returns boardID's current head CID, i.e. the CID of the last
+// entry AppendEntry accepted. It returns ErrBoardLogNotFound if boardID
+// has no entries yet.
+func (s *BoardLogStore) Head(boardID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.headLocked(boardID)
+}
+
+func (s *BoardLogStore) headLocked(boardID string) (string, error) {
+	if cid, ok := s.heads[boardID]; ok {
+		return cid, nil
+	}
+	entries, err := s.readEntries(boardID)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", ErrBoardLogNotFound
+	}
+	head := entries[len(entries)-1].CID
+	s.heads[boardID] = head
+	return head, nil
+}
+
+// AppendEntry accepts entry as the next entry in boardID's log and
+// returns the CID it was stored under. entry must already be fully
+// signed by its author (Signature covering CanonicalSignPayload, which
+// includes PrevLogCid) — AppendEntry only validates and records it, it
+// does not sign on the caller's behalf. It rejects entry if:
+//   - entry fails Validate()
+//   - entry.BoardID != boardID
+//   - entry.PrevLogCid doesn't match the board's current head
+//     (ErrBoardLogPrevMismatch)
+//   - entry.Signature doesn't verify against entry.AuthorPubKey
+func (s *BoardLogStore) AppendEntry(boardID string, entry *BoardLogEntry) (string, error) {
+	if err := entry.Validate(); err != nil {
+		return "", err
+	}
+	if entry.BoardID != boardID {
+		return "", fmt.Errorf("board log: entry.BoardID=%q does not match boardId=%q", entry.BoardID, boardID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head, err := s.headLocked(boardID)
+	if err != nil && !errors.Is(err, ErrBoardLogNotFound) {
+		return "", err
+	}
+	prev := normOptionalString(entry.PrevLogCid)
+	if prev != head {
+		return "", fmt.Errorf("%w: entry.PrevLogCid=%q, head=%q", ErrBoardLogPrevMismatch, prev, head)
+	}
+
+	ok, err := VerifySignature(entry.AuthorPubKey, entry.Signature, entry.CanonicalSignPayload())
+	if err != nil {
+		return "", fmt.Errorf("board log: %w", err)
+	}
+	if !ok {
+		return "", ErrBoardLogSignatureInvalid
+	}
+
+	cid, err := ComputeBoardLogEntryCID(entry)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(s.path(boardID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return "", err
+	}
+
+	s.heads[boardID] = cid
+	if s.Hub != nil {
+		s.Hub.Publish(boardID, BoardLogEntryWithCID{CID: cid, Entry: entry})
+	}
+	return cid, nil
+}
+
+// AppendEntryCtx is AppendEntry, but bails out early with ctx.Err() if
+// ctx is already done, for the same reason ListCtx does.
+func (s *BoardLogStore) AppendEntryCtx(ctx context.Context, boardID string, entry *BoardLogEntry) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.AppendEntry(boardID, entry)
+}
+
+// Verify walks boardID's log from genesis, failing on the first entry
+// whose PrevLogCid doesn't chain to the entry before it or whose
+// Signature doesn't verify against its own AuthorPubKey. A board with no
+// entries verifies successfully.
+func (s *BoardLogStore) Verify(boardID string) error {
+	entries, err := s.List(boardID)
+	if err != nil {
+		return err
+	}
+
+	prev := ""
+	for i, item := range entries {
+		e := item.Entry
+		if normOptionalString(e.PrevLogCid) != prev {
+			return fmt.Errorf("%w: entry %d (cid=%s) has prevLogCid=%q, want %q (fork detected)",
+				ErrBoardLogBroken, i, item.CID, normOptionalString(e.PrevLogCid), prev)
+		}
+		ok, err := VerifySignature(e.AuthorPubKey, e.Signature, e.CanonicalSignPayload())
+		if err != nil {
+			return fmt.Errorf("%w: entry %d (cid=%s): %v", ErrBoardLogBroken, i, item.CID, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: entry %d (cid=%s) has invalid signature", ErrBoardLogBroken, i, item.CID)
+		}
+		prev = item.CID
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// --- Wiring BoardLogStore writes into the posts API's mutating handlers ---
+//
+// handleCreatePost/handleEditPost/handleTombstonePost (in api_v1_posts_post.go,
+// api_v1_posts_edit.go and api_v1_posts_tombstone.go) each accept an
+// optional boardId + boardLogEntry alongside their usual signed request:
+// the author signs the BoardLogEntry the same way they sign the post
+// itself (this package never holds an author's private key, so it can't
+// sign on their behalf — see BoardLogStore.AppendEntry's doc comment),
+// naming prevLogCid as the board's head at the time they built it. The
+// handler fills in nothing; it only checks that the entry the author
+// signed actually describes the mutation that just happened before
+// appending it, so a client can't slip an entry past BoardLogStore.Verify
+// that doesn't match what the log is meant to record.
+
+// ErrBoardLogEntryMismatch is appendPostBoardLogEntry's error when the
+// caller-supplied BoardLogEntry doesn't describe the mutation it's
+// attached to (wrong boardId/op/authorPubKey/threadId, or a CID field
+// that doesn't match what the store just did).
+var ErrBoardLogEntryMismatch = errors.New("board log: entry does not match the mutation it is attached to")
+
+// appendPostBoardLogEntry validates that entry matches the mutation the
+// caller just performed (boardID, op, authorPubKey, threadID, and
+// whichever of postCID/oldPostCID/newPostCID/targetPostCID the op cares
+// about) and, if so, appends it to logs. It returns ("", nil) without
+// doing anything if logs or entry is nil — board-log writes are opt-in,
+// same as checkWriteLimits is opt-in when writeLimits is unconfigured.
+func appendPostBoardLogEntry(
+	ctx context.Context,
+	logs *BoardLogStore,
+	entry *BoardLogEntry,
+	boardID, op, authorPubKey, threadID string,
+	postCID, oldPostCID, newPostCID, targetPostCID *string,
+) (string, error) {
+	if logs == nil || entry == nil {
+		return "", nil
+	}
+
+	if entry.BoardID != boardID {
+		return "", fmt.Errorf("%w: boardId=%q, want %q", ErrBoardLogEntryMismatch, entry.BoardID, boardID)
+	}
+	if entry.Op != op {
+		return "", fmt.Errorf("%w: op=%q, want %q", ErrBoardLogEntryMismatch, entry.Op, op)
+	}
+	if entry.AuthorPubKey != authorPubKey {
+		return "", fmt.Errorf("%w: authorPubKey=%q, want %q", ErrBoardLogEntryMismatch, entry.AuthorPubKey, authorPubKey)
+	}
+	if threadID != "" && normOptionalString(entry.ThreadID) != threadID {
+		return "", fmt.Errorf("%w: threadId=%q, want %q", ErrBoardLogEntryMismatch, normOptionalString(entry.ThreadID), threadID)
+	}
+	if err := requireMatchingCID("postCid", entry.PostCid, postCID); err != nil {
+		return "", err
+	}
+	if err := requireMatchingCID("oldPostCid", entry.OldPostCid, oldPostCID); err != nil {
+		return "", err
+	}
+	if err := requireMatchingCID("newPostCid", entry.NewPostCid, newPostCID); err != nil {
+		return "", err
+	}
+	if err := requireMatchingCID("targetPostCid", entry.TargetPostCid, targetPostCID); err != nil {
+		return "", err
+	}
+
+	return logs.AppendEntryCtx(ctx, boardID, entry)
+}
+
+// requireMatchingCID reports ErrBoardLogEntryMismatch if want is set but
+// got doesn't carry the same value (both nil is fine; a nil want means the
+// op doesn't care about this field and got is left alone).
+func requireMatchingCID(field string, got, want *string) error {
+	if want == nil {
+		return nil
+	}
+	if got == nil || *got != *want {
+		return fmt.Errorf("%w: %s=%v, want %q", ErrBoardLogEntryMismatch, field, got, *want)
+	}
+	return nil
+}
+
+// writeBoardLogAppendError writes err from appendPostBoardLogEntry as the
+// appropriate JSON error response, mirroring handlePostBoardLog's mapping
+// in boards_api.go: the primary post mutation this entry is attached to
+// has already been committed by the time this runs, so a client that gets
+// one of these still needs to check whether its post/edit/tombstone went
+// through even though the board log didn't record it.
+func writeBoardLogAppendError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrBoardLogEntryMismatch):
+		writeJSONError(w, http.StatusBadRequest, "board_log_mismatch", err.Error())
+	case errors.Is(err, ErrBoardLogPrevMismatch):
+		writeJSONError(w, http.StatusConflict, "board_log_conflict", err.Error())
+	case errors.Is(err, ErrBoardLogSignatureInvalid),
+		errors.Is(err, ErrInvalidVersion),
+		errors.Is(err, ErrInvalidType),
+		errors.Is(err, ErrMissingField),
+		errors.Is(err, ErrInvalidPubKey),
+		errors.Is(err, ErrInvalidTimestamp):
+		writeJSONError(w, http.StatusBadRequest, "board_log_invalid", err.Error())
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "board_log_error", err.Error())
+	}
+}
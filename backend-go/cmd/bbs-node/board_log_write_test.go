@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// newBoardLogWriteTestKey returns an ed25519 key and its ed25519: encoded
+// public/private strings, for building both a post's own signature
+// (SignPayloadEd25519) and its attached BoardLogEntry's signature
+// (NewSigningKey.Sign).
+func newBoardLogWriteTestKey(t *testing.T) (pub ed25519.PublicKey, priv ed25519.PrivateKey, pubStr, privStr string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return pub, priv, EncodeEd25519PublicKey(pub), EncodeEd25519PrivateKey(priv)
+}
+
+func TestHandleCreatePost_WithBoardLogEntry_AppendsAndReturnsCID(t *testing.T) {
+	resetPostsStoreForTests()
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	setBoardLogStoreForTests(logs)
+	defer setBoardLogStoreForTests(nil)
+
+	_, priv, pubStr, privStr := newBoardLogWriteTestKey(t)
+
+	var req createPostRequest
+	req.Version = 1
+	req.ThreadID = "thread-1"
+	req.AuthorPubKey = pubStr
+	req.DisplayName = "alice"
+	req.Body.Format = "md"
+	req.Body.Content = "hello"
+	req.CreatedAt = "2025-01-01T00:00:00Z"
+
+	payload, err := PostSignPayload(req.Version, req.ThreadID, req.ParentPostCid, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	if err != nil {
+		t.Fatalf("PostSignPayload: %v", err)
+	}
+	sig, err := SignPayloadEd25519(privStr, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+	req.Signature = sig
+
+	req.BoardID = "board-1"
+	threadID := req.ThreadID
+	entry := &BoardLogEntry{
+		Version:      1,
+		Type:         "boardLogEntry",
+		BoardID:      req.BoardID,
+		Op:           "addPost",
+		ThreadID:     &threadID,
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: pubStr,
+	}
+	entry.Signature = NewSigningKey(priv).Sign([]byte(entry.CanonicalSignPayload()))
+	req.LogEntry = entry
+
+	b, _ := json.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handleCreatePost(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status=%d body=%s", resp.StatusCode, w.Body.String())
+	}
+
+	var out createPostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if out.BoardLogCid == "" {
+		t.Fatalf("BoardLogCid is empty, want the appended entry's CID")
+	}
+
+	head, err := logs.Head(req.BoardID)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	if head != out.BoardLogCid {
+		t.Fatalf("Head=%q, want response BoardLogCid=%q", head, out.BoardLogCid)
+	}
+
+	entries, err := logs.List(req.BoardID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("len(entries)=%d, want 1", len(entries))
+	}
+	if got := normOptionalString(entries[0].Entry.PostCid); got != out.PostCid {
+		t.Fatalf("logged postCid=%q, want %q", got, out.PostCid)
+	}
+}
+
+func TestHandleCreatePost_WithBoardLogEntry_OpMismatchRejected(t *testing.T) {
+	resetPostsStoreForTests()
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+	setBoardLogStoreForTests(logs)
+	defer setBoardLogStoreForTests(nil)
+
+	_, priv, pubStr, privStr := newBoardLogWriteTestKey(t)
+
+	var req createPostRequest
+	req.Version = 1
+	req.ThreadID = "thread-1"
+	req.AuthorPubKey = pubStr
+	req.Body.Format = "md"
+	req.Body.Content = "hello"
+	req.CreatedAt = "2025-01-01T00:00:00Z"
+
+	payload, err := PostSignPayload(req.Version, req.ThreadID, req.ParentPostCid, req.AuthorPubKey, req.DisplayName, req.Body.Format, req.Body.Content, req.CreatedAt)
+	if err != nil {
+		t.Fatalf("PostSignPayload: %v", err)
+	}
+	sig, err := SignPayloadEd25519(privStr, payload)
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+	req.Signature = sig
+	req.BoardID = "board-1"
+
+	// entry.Op doesn't match the "addPost" the handler just performed.
+	entry := &BoardLogEntry{
+		Version:      1,
+		Type:         "boardLogEntry",
+		BoardID:      req.BoardID,
+		Op:           "editPost",
+		CreatedAt:    "2025-01-01T00:00:00Z",
+		AuthorPubKey: pubStr,
+	}
+	entry.Signature = NewSigningKey(priv).Sign([]byte(entry.CanonicalSignPayload()))
+	req.LogEntry = entry
+
+	b, _ := json.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	posts.handleCreatePost(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s, want 400", resp.StatusCode, w.Body.String())
+	}
+
+	// The post itself was already persisted by the time the mismatch was
+	// caught; only the board log append was rejected.
+	if _, err := logs.Head(req.BoardID); err != ErrBoardLogNotFound {
+		t.Fatalf("Head = %v, want ErrBoardLogNotFound (no entry should have been appended)", err)
+	}
+}
+
+func TestAppendPostBoardLogEntry_NilLogsOrEntry_NoOp(t *testing.T) {
+	logs := NewBoardLogStore(filepath.Join(t.TempDir(), "boardlogs"))
+
+	ctx := context.Background()
+	if cid, err := appendPostBoardLogEntry(ctx, nil, &BoardLogEntry{}, "board-1", "addPost", "ed25519:x", "thread-1", nil, nil, nil, nil); cid != "" || err != nil {
+		t.Fatalf("nil logs: cid=%q err=%v, want (\"\", nil)", cid, err)
+	}
+	if cid, err := appendPostBoardLogEntry(ctx, logs, nil, "board-1", "addPost", "ed25519:x", "thread-1", nil, nil, nil, nil); cid != "" || err != nil {
+		t.Fatalf("nil entry: cid=%q err=%v, want (\"\", nil)", cid, err)
+	}
+}
@@ -1,45 +1,85 @@
 package main
 
-import "sync"
+import (
+	"context"
+	"sync"
 
-// --- In-memory store for boards -> threads listing (Issue #16) ---
-//
-// NOTE:
-// 本来は永続ストレージ/FlexIPFS などから取得する想定。
-// 合体(本実装)時に、このストア自体を消すか、getBoardThreadSummaries の実装を差し替える。
-
-var (
-	boardThreadsMu sync.RWMutex
-	boardThreads   = map[string][]threadSummary{}
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
+// threadsHandlers holds the storage.ThreadStore that
+// getBoardThreadSummaries/setBoardThreadSummaries/handleCreateThread are
+// wired to, the same pattern posts_store.go's postsHandlers uses for
+// PostStore — built via newThreadsHandlers rather than this file
+// declaring its own ad-hoc boardThreadsMu/boardThreads globals.
+type threadsHandlers struct {
+	mu    sync.RWMutex
+	store poststore.ThreadStore
+}
+
+// newThreadsHandlers wires up a threadsHandlers backed by store.
+func newThreadsHandlers(store poststore.ThreadStore) *threadsHandlers {
+	return &threadsHandlers{store: store}
+}
+
+// threadStore returns the currently-wired ThreadStore, so callers never
+// read h.store directly without going through the mutex.
+func (h *threadsHandlers) threadStore() poststore.ThreadStore {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.store
+}
+
+// threads is the board-threads API's single instance, storing to a
+// MemoryThreadStore by default (matching boardThreads' previous
+// behaviour); tests reset it via resetBoardThreads.
+//
+// NOTE: 合体(本実装)時には、flex-ipfs/DB 裏付けの BoltThreadStore
+// (cmd/bbs-node/storage/bolt_thread_store.go) に差し替える想定。
+var threads = newThreadsHandlers(poststore.NewMemoryThreadStore())
+
 // getBoardThreadSummaries returns thread summaries for a board.
 // It returns (nil, false) when the board does not exist.
 func getBoardThreadSummaries(boardID string) ([]threadSummary, bool) {
-	boardThreadsMu.RLock()
-	threads, ok := boardThreads[boardID]
-	boardThreadsMu.RUnlock()
+	ts, ok := threads.threadStore().Get(context.Background(), boardID)
 	if !ok {
 		return nil, false
 	}
-	// defensive copy to avoid callers mutating shared slice
-	out := make([]threadSummary, len(threads))
-	copy(out, threads)
+	out := make([]threadSummary, len(ts))
+	for i, t := range ts {
+		out[i] = threadSummary{ID: t.ID, Title: t.Title}
+	}
 	return out, true
 }
 
 // setBoardThreadSummaries is a small helper for tests and temporary wiring.
-func setBoardThreadSummaries(boardID string, threads []threadSummary) {
-	boardThreadsMu.Lock()
-	defer boardThreadsMu.Unlock()
-	cp := make([]threadSummary, len(threads))
-	copy(cp, threads)
-	boardThreads[boardID] = cp
+func setBoardThreadSummaries(boardID string, summaries []threadSummary) {
+	store := threads.threadStore()
+	ctx := context.Background()
+	// Replace wholesale: start from an empty list for boardID, then Put
+	// each summary in order, matching the previous map-assignment's
+	// overwrite-in-place semantics.
+	if existing, ok := store.Get(ctx, boardID); ok {
+		for _, t := range existing {
+			store.Delete(ctx, boardID, t.ID)
+		}
+	}
+	for _, s := range summaries {
+		store.Put(ctx, boardID, poststore.Thread{ID: s.ID, Title: s.Title})
+	}
 }
 
-// resetBoardThreads clears the in-memory store (used by tests).
+// resetBoardThreads replaces threads' store with a fresh
+// MemoryThreadStore, so each test starts from an empty, independent
+// store.
 func resetBoardThreads() {
-	boardThreadsMu.Lock()
-	defer boardThreadsMu.Unlock()
-	boardThreads = map[string][]threadSummary{}
+	threads.mu.Lock()
+	threads.store = poststore.NewMemoryThreadStore()
+	threads.mu.Unlock()
+}
+
+// resetBoardThreadsForTests is an older name for resetBoardThreads kept
+// around because post_threads_test.go already calls it under this name.
+func resetBoardThreadsForTests() {
+	resetBoardThreads()
 }
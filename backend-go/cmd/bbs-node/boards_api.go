@@ -1,11 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,18 +30,125 @@ type BoardMeta struct {
 	CreatedAt  time.Time `json:"createdAt"`
 	CreatedBy  string    `json:"createdBy"`
 	Signature  string    `json:"signature,omitempty"`
+	// ModeratorKeys lists capability keys CreatedBy has issued scoped
+	// access to this board's moderation actions (currently just
+	// "tombstone"; see moderator_keys.go). Each key carries its own
+	// Signature over ModeratorKeyIssuanceSignPayload, signed by CreatedBy
+	// independently of BoardMeta.Signature above -- a key's authenticity
+	// doesn't depend on which BoardMeta happened to be carrying it when a
+	// handler checked it.
+	ModeratorKeys []ModeratorKey `json:"moderatorKeys,omitempty"`
+	// Verified is set by loadBoardMetaConfig from the result of checking
+	// Signature against CreatedBy; it is not itself part of the signed
+	// payload. Callers that construct a BoardMeta directly (as the
+	// existing tests in boards_api_test.go do) get the zero value, false.
+	Verified bool `json:"verified"`
+}
+
+// CanonicalSignPayload generates the deterministic signing payload for
+// BoardMeta. Signature fields (in strict order):
+//
+//	type, version, boardId, title, description, logHeadCid, createdAt, createdBy
+func (b *BoardMeta) CanonicalSignPayload() string {
+	var buf bytes.Buffer
+	buf.WriteString("type=boardMeta\n")
+	buf.WriteString(fmt.Sprintf("version=%d\n", b.Version))
+	buf.WriteString(fmt.Sprintf("boardId=%s\n", b.BoardID))
+	buf.WriteString(fmt.Sprintf("title=%s\n", b.Title))
+	buf.WriteString(fmt.Sprintf("description=%s\n", b.Description))
+	buf.WriteString(fmt.Sprintf("logHeadCid=%s\n", b.LogHeadCID))
+	buf.WriteString(fmt.Sprintf("createdAt=%s\n", b.CreatedAt.UTC().Format(time.RFC3339Nano)))
+	buf.WriteString(fmt.Sprintf("createdBy=%s", b.CreatedBy)) // No newline at end
+	return buf.String()
 }
 
 // boardMetaConfigFile は設定ファイルの JSON 形式です。
 type boardMetaConfigFile struct {
-	Boards []BoardMeta `json:"boards"`
+	Boards   []BoardMeta         `json:"boards"`
+	Timeouts boardsTimeoutConfig `json:"timeouts,omitempty"`
 }
 
 // loadBoardMetaConfig は設定ファイルから BoardMeta 一覧を読み込みます。
 // path が空の場合は組み込みのデフォルト一覧を返します。
-func loadBoardMetaConfig(path string) ([]BoardMeta, error) {
+//
+// 読み込んだ各 BoardMeta は Signature を CreatedBy (ed25519:プレフィックス
+// 付き公開鍵) で検証し、Verified に結果をセットします。署名が無い/不正な
+// BoardMeta は、boardsStrictVerifyEnabled() が false (デフォルト) なら
+// 一覧から落として処理を続け、true ("strict mode": 環境変数
+// BOARDS_STRICT_VERIFY=1/true で有効化) ならデフォルトへのフォールバック
+// をせずエラーを返します。
+//
+// logs が非nilの場合、返す各 BoardMeta の LogHeadCID を logs 上の現在の
+// head で上書きします(ログが空の板は空文字のまま)。これは署名検証の
+// "後" に行います — LogHeadCID 自体が署名対象フィールドなので、検証は
+// 設定ファイルに書かれていた値に対して行う必要があります。
+//
+// 設定ファイルの任意の "timeouts" セクション (boardsTimeoutConfig) も
+// 解決して返し、registerBoardsHTTP のハンドラー用デッドラインとして使えるようにします。
+func loadBoardMetaConfig(path string, logs *BoardLogStore) ([]BoardMeta, boardsTimeouts, error) {
+	boards, timeoutCfg, err := loadBoardMetasWithoutLogHeads(path)
+	if err != nil {
+		return nil, boardsTimeouts{}, err
+	}
+	boards, err = verifyBoardMetas(boards, boardsStrictVerifyEnabled())
+	if err != nil {
+		return nil, boardsTimeouts{}, err
+	}
+	populateBoardLogHeads(boards, logs)
+	return boards, resolveBoardsTimeouts(timeoutCfg), nil
+}
+
+// boardsStrictVerifyEnabled reports whether BOARDS_STRICT_VERIFY is set to
+// a truthy value (strconv.ParseBool, e.g. "1"/"true"/"TRUE").
+func boardsStrictVerifyEnabled() bool {
+	v, err := strconv.ParseBool(os.Getenv("BOARDS_STRICT_VERIFY"))
+	return err == nil && v
+}
+
+// verifyBoardMetas checks each board's Signature against its CreatedBy
+// pubkey, logging the per-board result. A board that fails verification
+// (unsigned or invalid) is dropped from the returned slice unless strict
+// is set, in which case verifyBoardMetas returns an error instead of
+// dropping anything.
+func verifyBoardMetas(boards []BoardMeta, strict bool) ([]BoardMeta, error) {
+	out := make([]BoardMeta, 0, len(boards))
+	for i := range boards {
+		b := boards[i]
+		verr := verifyBoardMetaSignature(&b)
+		if verr != nil {
+			log.Printf("board meta verify: boardId=%s verified=false: %v", b.BoardID, verr)
+			if strict {
+				return nil, fmt.Errorf("board %q failed signature verification: %w", b.BoardID, verr)
+			}
+			continue
+		}
+		log.Printf("board meta verify: boardId=%s verified=true", b.BoardID)
+		b.Verified = true
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// verifyBoardMetaSignature checks b.Signature against b.CreatedBy for
+// b.CanonicalSignPayload(), returning a non-nil error for an unsigned or
+// invalid BoardMeta.
+func verifyBoardMetaSignature(b *BoardMeta) error {
+	if b.Signature == "" {
+		return errors.New("board meta has no signature")
+	}
+	ok, err := VerifySignature(b.CreatedBy, b.Signature, b.CanonicalSignPayload())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("signature does not match createdBy")
+	}
+	return nil
+}
+
+func loadBoardMetasWithoutLogHeads(path string) ([]BoardMeta, boardsTimeoutConfig, error) {
 	if path == "" {
-		return defaultBoardMetas(), nil
+		return defaultBoardMetas(), boardsTimeoutConfig{}, nil
 	}
 
 	resolved := path
@@ -47,21 +164,37 @@ func loadBoardMetaConfig(path string) ([]BoardMeta, error) {
 	if err != nil {
 		// 設定ファイルが見つからない場合は警告してデフォルトにフォールバック
 		log.Printf("boards config not found (%s), using defaults: %v", resolved, err)
-		return defaultBoardMetas(), nil
+		return defaultBoardMetas(), boardsTimeoutConfig{}, nil
 	}
 	defer f.Close()
 
 	var cfg boardMetaConfigFile
 	if err := json.NewDecoder(f).Decode(&cfg); err != nil {
 		log.Printf("failed to parse boards config (%s), using defaults: %v", resolved, err)
-		return defaultBoardMetas(), nil
+		return defaultBoardMetas(), boardsTimeoutConfig{}, nil
 	}
 
 	if len(cfg.Boards) == 0 {
 		log.Printf("boards config (%s) has no boards, using defaults", resolved)
-		return defaultBoardMetas(), nil
+		return defaultBoardMetas(), boardsTimeoutConfig{}, nil
+	}
+	return cfg.Boards, cfg.Timeouts, nil
+}
+
+// populateBoardLogHeads sets boards[i].LogHeadCID from logs.Head for each
+// board, in place. A board with no log entries yet (or logs == nil) is
+// left with whatever LogHeadCID the config already had.
+func populateBoardLogHeads(boards []BoardMeta, logs *BoardLogStore) {
+	if logs == nil {
+		return
+	}
+	for i := range boards {
+		head, err := logs.Head(boards[i].BoardID)
+		if err != nil {
+			continue
+		}
+		boards[i].LogHeadCID = head
 	}
-	return cfg.Boards, nil
 }
 
 // defaultBoardMetas は外部配布なしでも最低限動作するための組み込み板一覧です。
@@ -83,46 +216,451 @@ func defaultBoardMetas() []BoardMeta {
 // registerBoardsHTTP は BoardMeta 関連の REST API を登録します。
 // - GET /api/v1/boards
 // - GET /api/v1/boards/{boardId}
-func registerBoardsHTTP(mux *http.ServeMux, boards []BoardMeta) {
+// - GET /api/v1/boards/{boardId}/log   (logs が非nilの場合のみ)
+// - POST /api/v1/boards/{boardId}/log  (logs が非nilの場合のみ)
+// - GET /api/v1/boards/{boardId}/events (logs とその Hub が非nilの場合のみ)
+//
+// logs に nil を渡した場合、board log / events エンドポイントは 501 を
+// 返します (既存の呼び出し元/テストが board log を持たない状態を想定
+// しているため)。
+//
+// timeouts の Read/Write デッドラインは一覧・単一板取得・board log の
+// GET/POST に適用されます (withBoardsTimeout 経由)。board events の SSE
+// ハンドラーだけは意図的に対象外です — 長時間張りっぱなしの接続である
+// ことが前提の設計であり、自前のハートビートと r.Context() で生存管理
+// しています。
+func registerBoardsHTTP(mux *http.ServeMux, boards []BoardMeta, logs *BoardLogStore, timeouts boardsTimeouts) {
 	// インメモリマップを作成
 	byID := make(map[string]BoardMeta, len(boards))
 	for _, b := range boards {
 		byID[b.BoardID] = b
 	}
 
-	mux.HandleFunc("/api/v1/boards", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/boards", withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		// 一覧を返す
-		resp := struct {
-			Boards []BoardMeta `json:"boards"`
-		}{Boards: boards}
+
+		etag := boardsETag(boards, logs)
+		lastModified := boardsLastModified(boards)
+		if boardsNotModified(r, etag, lastModified) {
+			writeBoardsCacheHeaders(w, etag, lastModified)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		page, err := paginateBoardsList(boards, r.URL.Query())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		writeBoardsCacheHeaders(w, etag, lastModified)
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		if err := json.NewEncoder(w).Encode(resp); err != nil {
+		if err := json.NewEncoder(w).Encode(page); err != nil {
 			log.Printf("failed to write /api/v1/boards response: %v", err)
 		}
-	})
+	}, timeouts.readTimeout()))
 
 	mux.HandleFunc("/api/v1/boards/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		rest := r.URL.Path[len("/api/v1/boards/"):]
+		if rest == "" {
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		boardID := r.URL.Path[len("/api/v1/boards/"):]
-		if boardID == "" {
-			w.WriteHeader(http.StatusNotFound)
+
+		if boardID, ok := strings.CutSuffix(rest, "/events"); ok {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			handleBoardEventsSSE(w, r, boardID, logs)
 			return
 		}
-		b, ok := byID[boardID]
-		if !ok {
-			w.WriteHeader(http.StatusNotFound)
+
+		// "/log/stream" is the same SSE stream as "/events", named to match
+		// the board log read API (GET .../log, GET .../log/stream) rather
+		// than the older board-wide "/events" naming.
+		if boardID, ok := strings.CutSuffix(rest, "/log/stream"); ok {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			handleBoardEventsSSE(w, r, boardID, logs)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		if err := json.NewEncoder(w).Encode(b); err != nil {
-			log.Printf("failed to write /api/v1/boards/%s response: %v", boardID, err)
+
+		if boardID, ok := strings.CutSuffix(rest, "/log"); ok {
+			timeout := timeouts.readTimeout()
+			if r.Method == http.MethodPost {
+				timeout = timeouts.writeTimeout()
+			}
+			withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+				handleBoardLogHTTP(w, r, boardID, logs)
+			}, timeout)(w, r)
+			return
+		}
+
+		withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			b, ok := byID[rest]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			etag := boardsETag([]BoardMeta{b}, logs)
+			lastModified := b.CreatedAt
+			if boardsNotModified(r, etag, lastModified) {
+				writeBoardsCacheHeaders(w, etag, lastModified)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			writeBoardsCacheHeaders(w, etag, lastModified)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			if err := json.NewEncoder(w).Encode(b); err != nil {
+				log.Printf("failed to write /api/v1/boards/%s response: %v", rest, err)
+			}
+		}, timeouts.readTimeout())(w, r)
+	})
+}
+
+// --- GET /api/v1/boards: pagination, filtering, and ETag/Last-Modified caching ---
+
+// boardsListPage is GET /api/v1/boards's response shape. NextCursor is the
+// opaque cursor to pass back as ?cursor= to fetch the next page; it's
+// omitted once Boards reaches the end of the (filtered) set.
+type boardsListPage struct {
+	Boards     []BoardMeta `json:"boards"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+// boardsCursor is the decoded form of a boardsListPage cursor: the
+// (BoardID, CreatedAt) of the last board sent, which is also the sort key
+// paginateBoardsList orders boards by. It's opaque to clients — they only
+// ever round-trip the base64 string NextCursor gives them.
+type boardsCursor struct {
+	BoardID   string    `json:"boardId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func encodeBoardsCursor(b BoardMeta) string {
+	data, err := json.Marshal(boardsCursor{BoardID: b.BoardID, CreatedAt: b.CreatedAt})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeBoardsCursor(s string) (boardsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return boardsCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c boardsCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return boardsCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return c, nil
+}
+
+// paginateBoardsList sorts boards by (createdAt, boardId), optionally
+// filters by q (case-folded substring match on Title or Description),
+// then returns the page starting after ?cursor= (or from the beginning
+// if absent), bounded to ?limit= entries (default 50, capped at 500 via
+// parsePositiveInt). It returns an error if ?cursor= is present but
+// doesn't decode or doesn't match any board in the (filtered) set.
+func paginateBoardsList(boards []BoardMeta, q url.Values) (boardsListPage, error) {
+	sorted := make([]BoardMeta, len(boards))
+	copy(sorted, boards)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
 		}
+		return sorted[i].BoardID < sorted[j].BoardID
 	})
+
+	if needle := strings.ToLower(q.Get("q")); needle != "" {
+		filtered := make([]BoardMeta, 0, len(sorted))
+		for _, b := range sorted {
+			if strings.Contains(strings.ToLower(b.Title), needle) || strings.Contains(strings.ToLower(b.Description), needle) {
+				filtered = append(filtered, b)
+			}
+		}
+		sorted = filtered
+	}
+
+	start := 0
+	if raw := q.Get("cursor"); raw != "" {
+		cursor, err := decodeBoardsCursor(raw)
+		if err != nil {
+			return boardsListPage{}, err
+		}
+		idx := -1
+		for i, b := range sorted {
+			if b.BoardID == cursor.BoardID && b.CreatedAt.Equal(cursor.CreatedAt) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return boardsListPage{}, fmt.Errorf("cursor does not match any board in the current set")
+		}
+		start = idx + 1
+	}
+
+	limit := parsePositiveInt(q.Get("limit"), 50, 500)
+	end := start + limit
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	if start > len(sorted) {
+		start = len(sorted)
+	}
+	page := sorted[start:end]
+
+	resp := boardsListPage{Boards: page}
+	if end < len(sorted) {
+		resp.NextCursor = encodeBoardsCursor(page[len(page)-1])
+	}
+	return resp, nil
+}
+
+// boardsETag computes a strong ETag over the sorted (BoardID, LogHeadCID,
+// Signature) tuples of boards. For each board, logs (if non-nil) is
+// consulted for its live head rather than trusting the board's own
+// LogHeadCID, which may have been captured before subsequent log
+// appends — this is what makes the ETag change whenever the underlying
+// board set OR any board's log head changes, without a separate cache to
+// invalidate.
+func boardsETag(boards []BoardMeta, logs *BoardLogStore) string {
+	type tuple struct{ boardID, logHeadCID, signature string }
+	tuples := make([]tuple, len(boards))
+	for i, b := range boards {
+		head := b.LogHeadCID
+		if logs != nil {
+			if h, err := logs.Head(b.BoardID); err == nil {
+				head = h
+			}
+		}
+		tuples[i] = tuple{b.BoardID, head, b.Signature}
+	}
+	sort.Slice(tuples, func(i, j int) bool { return tuples[i].boardID < tuples[j].boardID })
+
+	h := sha256.New()
+	for _, t := range tuples {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\n", t.boardID, t.logHeadCID, t.signature)
+	}
+	return fmt.Sprintf(`"%x"`, h.Sum(nil))
+}
+
+// boardsLastModified is the most recent CreatedAt across boards (the zero
+// Time if boards is empty). It only reflects board creation, not log
+// activity — a board's log head changing is reflected in its ETag instead,
+// since telling when a log head last changed would mean reading the whole
+// log rather than just its cached head.
+func boardsLastModified(boards []BoardMeta) time.Time {
+	var latest time.Time
+	for _, b := range boards {
+		if b.CreatedAt.After(latest) {
+			latest = b.CreatedAt
+		}
+	}
+	return latest
+}
+
+func writeBoardsCacheHeaders(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// boardsNotModified reports whether r's conditional headers indicate the
+// client's cached copy is still fresh. If-None-Match takes precedence over
+// If-Modified-Since, per RFC 7232 §6.
+func boardsNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return inm == etag || inm == "*"
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// boardLogPage is GET /api/v1/boards/{boardId}/log's response shape.
+// NextCursor is the CID to pass back as ?cursor= to fetch the next page;
+// it's omitted once Entries reaches the end of the log.
+type boardLogPage struct {
+	Entries    []boardLogEntryView `json:"entries"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+type boardLogEntryView struct {
+	CID   string         `json:"cid"`
+	Entry *BoardLogEntry `json:"entry"`
+}
+
+// handleBoardLogHTTP dispatches GET/POST /api/v1/boards/{boardId}/log.
+func handleBoardLogHTTP(w http.ResponseWriter, r *http.Request, boardID string, logs *BoardLogStore) {
+	if logs == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		handleGetBoardLog(w, r, boardID, logs)
+	case http.MethodPost:
+		handlePostBoardLog(w, r, boardID, logs)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGetBoardLog pages through boardID's log, oldest-first. ?cursor=
+// resumes after the given CID; ?limit= bounds the page size (default 50,
+// capped at 500 via parsePositiveInt).
+//
+// ?since=<cid> is a different shape for a different caller: instead of a
+// forward page for a UI to render, it's the newest-first chain a
+// federated peer replays to catch up from a CID it already has (or from
+// genesis, if since is the empty string) up to the board's current head.
+// ?cursor=/?limit= are ignored when ?since= is present.
+func handleGetBoardLog(w http.ResponseWriter, r *http.Request, boardID string, logs *BoardLogStore) {
+	entries, err := logs.ListCtx(r.Context(), boardID)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			w.WriteHeader(boardsTimeoutStatus(err))
+			return
+		}
+		log.Printf("board log list error (board=%s): %v", boardID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if _, ok := r.URL.Query()["since"]; ok {
+		handleGetBoardLogSince(w, r, boardID, entries)
+		return
+	}
+
+	start := 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		idx := -1
+		for i, e := range entries {
+			if e.CID == cursor {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		start = idx + 1
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), 50, 500)
+	end := start + limit
+	if end > len(entries) {
+		end = len(entries)
+	}
+	if start > len(entries) {
+		start = len(entries)
+	}
+	page := entries[start:end]
+
+	resp := boardLogPage{Entries: make([]boardLogEntryView, len(page))}
+	for i, e := range page {
+		resp.Entries[i] = boardLogEntryView{CID: e.CID, Entry: e.Entry}
+	}
+	if end < len(entries) {
+		resp.NextCursor = page[len(page)-1].CID
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("failed to write /api/v1/boards/%s/log response: %v", boardID, err)
+	}
+}
+
+// handleGetBoardLogSince serves the ?since= branch of handleGetBoardLog:
+// entries (oldest-first, as returned by BoardLogStore.List) walked
+// backwards from the head down to (but not including) the entry whose
+// CID is since, newest-first in the response. An empty since walks all
+// the way back to genesis. A non-empty since that names no entry in the
+// log is a 400, same as an unknown ?cursor=.
+func handleGetBoardLogSince(w http.ResponseWriter, r *http.Request, boardID string, entries []BoardLogEntryWithCID) {
+	since := r.URL.Query().Get("since")
+
+	stop := -1
+	if since != "" {
+		stop = -2
+		for i, e := range entries {
+			if e.CID == since {
+				stop = i
+				break
+			}
+		}
+		if stop == -2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := boardLogPage{Entries: make([]boardLogEntryView, 0, len(entries)-stop-1)}
+	for i := len(entries) - 1; i > stop; i-- {
+		resp.Entries = append(resp.Entries, boardLogEntryView{CID: entries[i].CID, Entry: entries[i].Entry})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("failed to write /api/v1/boards/%s/log response: %v", boardID, err)
+	}
+}
+
+// handlePostBoardLog accepts a single, already-signed BoardLogEntry and
+// appends it to boardID's log via BoardLogStore.AppendEntry.
+func handlePostBoardLog(w http.ResponseWriter, r *http.Request, boardID string, logs *BoardLogStore) {
+	var entry BoardLogEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cid, err := logs.AppendEntryCtx(r.Context(), boardID, &entry)
+	if err != nil {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+			w.WriteHeader(boardsTimeoutStatus(err))
+		case errors.Is(err, ErrBoardLogPrevMismatch):
+			w.WriteHeader(http.StatusConflict)
+		case errors.Is(err, ErrBoardLogSignatureInvalid),
+			errors.Is(err, ErrInvalidVersion),
+			errors.Is(err, ErrInvalidType),
+			errors.Is(err, ErrMissingField),
+			errors.Is(err, ErrInvalidPubKey),
+			errors.Is(err, ErrInvalidTimestamp):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			log.Printf("board log append error (board=%s): %v", boardID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(struct {
+		CID string `json:"cid"`
+	}{CID: cid}); err != nil {
+		log.Printf("failed to write /api/v1/boards/%s/log response: %v", boardID, err)
+	}
 }
@@ -25,7 +25,7 @@ func TestBoardsAPI_GetSingleBoard_Success(t *testing.T) {
 
     // テスト用のServeMuxに boards API を登録
     mux := http.NewServeMux()
-    registerBoardsHTTP(mux, boards)
+    registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
 
     // httptest.NewServer で実サーバー相当を起動
     srv := httptest.NewServer(mux)
@@ -60,7 +60,7 @@ func TestBoardsAPI_GetSingleBoard_NotFound(t *testing.T) {
 
     // boards API を登録
     mux := http.NewServeMux()
-    registerBoardsHTTP(mux, boards)
+    registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
 
     // テスト用HTTPサーバーを起動
     srv := httptest.NewServer(mux)
@@ -93,7 +93,7 @@ func TestBoardsAPI_GetSingleBoard_EmptyID(t *testing.T) {
 
     // boards API を登録
     mux := http.NewServeMux()
-    registerBoardsHTTP(mux, boards)
+    registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
 
     // テスト用HTTPサーバーを起動
     srv := httptest.NewServer(mux)
@@ -126,7 +126,7 @@ func TestBoardsAPI_GetSingleBoard_MethodNotAllowed(t *testing.T) {
 
     // boards API を登録
     mux := http.NewServeMux()
-    registerBoardsHTTP(mux, boards)
+    registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
 
     // テスト用HTTPサーバーを起動
     srv := httptest.NewServer(mux)
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func mkBoard(id, title, desc string, createdAt time.Time) BoardMeta {
+	return BoardMeta{Version: 1, Type: "boardMeta", BoardID: id, Title: title, Description: desc, CreatedAt: createdAt, CreatedBy: "system"}
+}
+
+func TestPaginateBoardsList_OrdersByCreatedAtThenBoardID(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	boards := []BoardMeta{
+		mkBoard("b", "B board", "", t0),
+		mkBoard("a", "A board", "", t0),
+		mkBoard("c", "C board", "", t0.Add(time.Hour)),
+	}
+
+	page, err := paginateBoardsList(boards, url.Values{})
+	if err != nil {
+		t.Fatalf("paginateBoardsList: %v", err)
+	}
+	if len(page.Boards) != 3 {
+		t.Fatalf("len = %d, want 3", len(page.Boards))
+	}
+	got := []string{page.Boards[0].BoardID, page.Boards[1].BoardID, page.Boards[2].BoardID}
+	want := []string{"a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPaginateBoardsList_LimitAndCursor(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	boards := []BoardMeta{
+		mkBoard("a", "A", "", t0),
+		mkBoard("b", "B", "", t0.Add(time.Minute)),
+		mkBoard("c", "C", "", t0.Add(2*time.Minute)),
+	}
+
+	page1, err := paginateBoardsList(boards, url.Values{"limit": {"2"}})
+	if err != nil {
+		t.Fatalf("page1: %v", err)
+	}
+	if len(page1.Boards) != 2 || page1.Boards[0].BoardID != "a" || page1.Boards[1].BoardID != "b" {
+		t.Fatalf("page1 = %+v", page1.Boards)
+	}
+	if page1.NextCursor == "" {
+		t.Fatalf("expected a NextCursor for page1")
+	}
+
+	page2, err := paginateBoardsList(boards, url.Values{"limit": {"2"}, "cursor": {page1.NextCursor}})
+	if err != nil {
+		t.Fatalf("page2: %v", err)
+	}
+	if len(page2.Boards) != 1 || page2.Boards[0].BoardID != "c" {
+		t.Fatalf("page2 = %+v", page2.Boards)
+	}
+	if page2.NextCursor != "" {
+		t.Fatalf("expected no NextCursor on the last page, got %q", page2.NextCursor)
+	}
+}
+
+func TestPaginateBoardsList_InvalidCursorErrors(t *testing.T) {
+	boards := []BoardMeta{mkBoard("a", "A", "", time.Now())}
+	if _, err := paginateBoardsList(boards, url.Values{"cursor": {"not-valid-base64!!"}}); err == nil {
+		t.Fatalf("expected an error for an undecodable cursor")
+	}
+}
+
+func TestPaginateBoardsList_FiltersByQCaseFolded(t *testing.T) {
+	t0 := time.Now()
+	boards := []BoardMeta{
+		mkBoard("a", "General Chat", "talk about anything", t0),
+		mkBoard("b", "Tech Support", "ask your questions HERE", t0),
+	}
+
+	page, err := paginateBoardsList(boards, url.Values{"q": {"QUESTIONS"}})
+	if err != nil {
+		t.Fatalf("paginateBoardsList: %v", err)
+	}
+	if len(page.Boards) != 1 || page.Boards[0].BoardID != "b" {
+		t.Fatalf("got %+v, want only board b", page.Boards)
+	}
+}
+
+func TestBoardsETag_ChangesWithLogHead(t *testing.T) {
+	b := mkBoard("a", "A", "", time.Now())
+	b.Signature = "sig"
+
+	etagNoLogs := boardsETag([]BoardMeta{b}, nil)
+
+	store := NewBoardLogStore(t.TempDir())
+	etagEmptyLog := boardsETag([]BoardMeta{b}, store)
+	if etagNoLogs != etagEmptyLog {
+		t.Fatalf("etag should be the same with a nil store vs. an empty one: %q vs %q", etagNoLogs, etagEmptyLog)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	e := signedBoardLogEntry(t, pub, priv, "a", "createThread", nil)
+	if _, err := store.AppendEntry("a", e); err != nil {
+		t.Fatalf("AppendEntry: %v", err)
+	}
+
+	etagAfterAppend := boardsETag([]BoardMeta{b}, store)
+	if etagAfterAppend == etagEmptyLog {
+		t.Fatalf("expected ETag to change once the board's log head changed")
+	}
+}
+
+func TestBoardsNotModified_IfNoneMatch(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil)
+	r.Header.Set("If-None-Match", `"abc"`)
+	if !boardsNotModified(r, `"abc"`, time.Time{}) {
+		t.Fatalf("expected a match")
+	}
+	if boardsNotModified(r, `"different"`, time.Time{}) {
+		t.Fatalf("expected no match")
+	}
+}
+
+func TestBoardsNotModified_IfModifiedSince(t *testing.T) {
+	lastModified := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil)
+	r.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+
+	if !boardsNotModified(r, "", lastModified) {
+		t.Fatalf("expected not-modified for an exact match")
+	}
+	if !boardsNotModified(r, "", lastModified.Add(-time.Minute)) {
+		t.Fatalf("expected not-modified for an older lastModified")
+	}
+	if boardsNotModified(r, "", lastModified.Add(time.Minute)) {
+		t.Fatalf("expected modified for a newer lastModified")
+	}
+}
+
+func TestBoardsAPI_ListReturns304WhenIfNoneMatchMatches(t *testing.T) {
+	boards := []BoardMeta{mkBoard("a", "A", "", time.Now())}
+	mux := http.NewServeMux()
+	registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/boards")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	etag := resp.Header.Get("ETag")
+	resp.Body.Close()
+	if etag == "" {
+		t.Fatalf("expected an ETag header")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/boards", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("conditional GET: %v", err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusNotModified {
+		t.Fatalf("status=%d, want 304", resp2.StatusCode)
+	}
+}
+
+func TestBoardsAPI_ListPaginates(t *testing.T) {
+	t0 := time.Now()
+	boards := []BoardMeta{
+		mkBoard("a", "A", "", t0),
+		mkBoard("b", "B", "", t0.Add(time.Minute)),
+	}
+	mux := http.NewServeMux()
+	registerBoardsHTTP(mux, boards, nil, boardsTimeouts{})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/boards?limit=1")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var page boardsListPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(page.Boards) != 1 || page.Boards[0].BoardID != "a" {
+		t.Fatalf("got %+v", page.Boards)
+	}
+	if page.NextCursor == "" {
+		t.Fatalf("expected a NextCursor")
+	}
+}
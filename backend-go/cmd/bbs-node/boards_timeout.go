@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// --- Per-request deadlines for registerBoardsHTTP handlers ---
+
+// defaultBoardsReadTimeout/defaultBoardsWriteTimeout are the deadlines
+// withBoardsTimeout installs when the boards config file doesn't
+// override them (see boardsTimeoutConfig).
+const (
+	defaultBoardsReadTimeout  = 5 * time.Second
+	defaultBoardsWriteTimeout = 15 * time.Second
+)
+
+// boardsTimeouts holds the resolved (non-zero) deadlines registerBoardsHTTP
+// wraps its non-streaming handlers with. The board-events SSE endpoint is
+// deliberately not wrapped: it's long-lived by design, and governs its own
+// lifecycle via r.Context() and its heartbeat ticker (see
+// handleBoardEventsSSE).
+type boardsTimeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+func (t boardsTimeouts) readTimeout() time.Duration {
+	if t.Read <= 0 {
+		return defaultBoardsReadTimeout
+	}
+	return t.Read
+}
+
+func (t boardsTimeouts) writeTimeout() time.Duration {
+	if t.Write <= 0 {
+		return defaultBoardsWriteTimeout
+	}
+	return t.Write
+}
+
+// boardsTimeoutConfig is the boards config file's override for
+// boardsTimeouts, as duration strings (e.g. "5s", "250ms") so operators can
+// tune it per deployment without a rebuild. Either field left empty keeps
+// that one's default.
+type boardsTimeoutConfig struct {
+	ReadTimeout  string `json:"readTimeout,omitempty"`
+	WriteTimeout string `json:"writeTimeout,omitempty"`
+}
+
+// resolveBoardsTimeouts parses cfg into a boardsTimeouts, logging and
+// falling back to the default for any field that's set but unparseable.
+func resolveBoardsTimeouts(cfg boardsTimeoutConfig) boardsTimeouts {
+	t := boardsTimeouts{Read: defaultBoardsReadTimeout, Write: defaultBoardsWriteTimeout}
+	if cfg.ReadTimeout != "" {
+		if d, err := time.ParseDuration(cfg.ReadTimeout); err == nil {
+			t.Read = d
+		} else {
+			log.Printf("boards config: invalid readTimeout %q, using default %v: %v", cfg.ReadTimeout, defaultBoardsReadTimeout, err)
+		}
+	}
+	if cfg.WriteTimeout != "" {
+		if d, err := time.ParseDuration(cfg.WriteTimeout); err == nil {
+			t.Write = d
+		} else {
+			log.Printf("boards config: invalid writeTimeout %q, using default %v: %v", cfg.WriteTimeout, defaultBoardsWriteTimeout, err)
+		}
+	}
+	return t
+}
+
+// boardsTimeoutStatus maps a context error from a deadline-bound boards
+// handler to the HTTP status withBoardsTimeout (or the handler itself, for
+// an error surfaced from e.g. BoardLogStore.ListCtx) should respond with:
+// 503 for our own deadline firing, 499 ("client closed request", nginx's
+// convention — net/http has no named constant for it) for the client
+// disconnecting first.
+func boardsTimeoutStatus(err error) int {
+	if errors.Is(err, context.Canceled) {
+		return 499
+	}
+	return http.StatusServiceUnavailable
+}
+
+type boardsTimeoutErrorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func writeBoardsTimeoutError(w http.ResponseWriter, status int) {
+	code, msg := "timeout", "request exceeded its deadline"
+	if status == 499 {
+		code, msg = "client_closed_request", "client disconnected before the request completed"
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(boardsTimeoutErrorBody{Error: msg, Code: code})
+}
+
+// claimableResponseWriter lets two goroutines race to respond to the same
+// request (the handler finishing normally vs. withBoardsTimeout reacting to
+// ctx.Done()) while guaranteeing only the winner's bytes reach the wire.
+type claimableResponseWriter struct {
+	http.ResponseWriter
+	mu      sync.Mutex
+	claimed bool
+}
+
+func (w *claimableResponseWriter) claim() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.claimed {
+		return false
+	}
+	w.claimed = true
+	return true
+}
+
+func (w *claimableResponseWriter) WriteHeader(status int) {
+	if !w.claim() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *claimableResponseWriter) Write(b []byte) (int, error) {
+	if !w.claim() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// withBoardsTimeout wraps next with a context.WithTimeout bound to timeout
+// (itself derived from r.Context(), so the client disconnecting cancels it
+// too). next runs on its own goroutine so a handler that blocks doesn't
+// wedge the request past its deadline; handlers are expected to notice
+// r.Context() during their own I/O and return promptly once it's done (see
+// BoardLogStore.ListCtx/AppendEntryCtx).
+//
+// Whichever finishes first wins: if next returns before the deadline, its
+// response is what the client sees. If the deadline (or the client's own
+// disconnect) fires first, withBoardsTimeout writes the response itself —
+// 503 for its own deadline, 499 if the client went away — and next's
+// eventual write, if any, is silently discarded.
+func withBoardsTimeout(next http.HandlerFunc, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		cw := &claimableResponseWriter{ResponseWriter: w}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			next(cw, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			if cw.claim() {
+				writeBoardsTimeoutError(w, boardsTimeoutStatus(ctx.Err()))
+			}
+			<-done
+		}
+	}
+}
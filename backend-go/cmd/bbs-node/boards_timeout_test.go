@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithBoardsTimeout_PassesThroughOnNormalCompletion(t *testing.T) {
+	h := withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+	}, time.Second)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil)
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("status=%d, want 201", w.Result().StatusCode)
+	}
+	if w.Body.String() != "ok" {
+		t.Fatalf("body=%q, want %q", w.Body.String(), "ok")
+	}
+}
+
+// TestWithBoardsTimeout_DeadlineReturns503 uses a handler that ignores its
+// deadline entirely (blocks on release, not on r.Context()) so the test
+// controls exactly when the handler goroutine is allowed to finish,
+// avoiding a race between it and withBoardsTimeout's own ctx.Done() case.
+func TestWithBoardsTimeout_DeadlineReturns503(t *testing.T) {
+	release := make(chan struct{})
+	h := withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}, 10*time.Millisecond)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(w, r)
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the 10ms deadline fire
+	close(release)
+	<-done
+
+	if w.Result().StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d, want 503", w.Result().StatusCode)
+	}
+	var body boardsTimeoutErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != "timeout" {
+		t.Fatalf("code=%q, want timeout", body.Code)
+	}
+}
+
+func TestWithBoardsTimeout_ClientCancelReturns499(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	h := withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}, time.Minute)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(w, r)
+	}()
+
+	cancel()
+	time.Sleep(20 * time.Millisecond) // let the cancellation propagate
+	close(release)
+	<-done
+
+	if w.Result().StatusCode != 499 {
+		t.Fatalf("status=%d, want 499", w.Result().StatusCode)
+	}
+}
+
+func TestWithBoardsTimeout_LateHandlerWriteIsDiscarded(t *testing.T) {
+	release := make(chan struct{})
+	h := withBoardsTimeout(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		// Arrives after the timeout response has already been written;
+		// must not panic or corrupt the response the client already got.
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("too late"))
+	}, 10*time.Millisecond)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/boards", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(w, r)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-done
+
+	if w.Result().StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d, want 503", w.Result().StatusCode)
+	}
+	if w.Body.String() == "too late" {
+		t.Fatalf("late write leaked into the response: %s", w.Body.String())
+	}
+}
+
+func TestResolveBoardsTimeouts(t *testing.T) {
+	got := resolveBoardsTimeouts(boardsTimeoutConfig{})
+	if got.Read != defaultBoardsReadTimeout || got.Write != defaultBoardsWriteTimeout {
+		t.Fatalf("empty config: got %+v, want defaults", got)
+	}
+
+	got = resolveBoardsTimeouts(boardsTimeoutConfig{ReadTimeout: "2s", WriteTimeout: "30s"})
+	if got.Read != 2*time.Second || got.Write != 30*time.Second {
+		t.Fatalf("explicit config: got %+v", got)
+	}
+
+	got = resolveBoardsTimeouts(boardsTimeoutConfig{ReadTimeout: "not-a-duration"})
+	if got.Read != defaultBoardsReadTimeout {
+		t.Fatalf("invalid readTimeout: got %v, want default %v", got.Read, defaultBoardsReadTimeout)
+	}
+}
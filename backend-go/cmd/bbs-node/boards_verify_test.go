@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func signedBoardMeta(t *testing.T, boardID string) (BoardMeta, string) {
+	t.Helper()
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	b := BoardMeta{
+		Version:     1,
+		Type:        "boardMeta",
+		BoardID:     boardID,
+		Title:       "テスト板",
+		Description: "テスト用の板",
+		CreatedAt:   time.Date(2026, 7, 29, 0, 0, 0, 0, time.UTC),
+		CreatedBy:   kp.Public,
+	}
+	sig, err := SignPayloadEd25519(kp.Private, b.CanonicalSignPayload())
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+	b.Signature = "ed25519:b64:" + sig
+	return b, kp.Public
+}
+
+func TestVerifyBoardMetas_AcceptsValidSignature(t *testing.T) {
+	b, _ := signedBoardMeta(t, "bbs.verified")
+
+	out, err := verifyBoardMetas([]BoardMeta{b}, false)
+	if err != nil {
+		t.Fatalf("verifyBoardMetas: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if !out[0].Verified {
+		t.Fatalf("expected Verified = true")
+	}
+}
+
+func TestVerifyBoardMetas_DropsUnsignedNonStrict(t *testing.T) {
+	unsigned := BoardMeta{BoardID: "bbs.unsigned", CreatedBy: "system"}
+
+	out, err := verifyBoardMetas([]BoardMeta{unsigned}, false)
+	if err != nil {
+		t.Fatalf("verifyBoardMetas: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("len(out) = %d, want 0 (unsigned board should be dropped)", len(out))
+	}
+}
+
+func TestVerifyBoardMetas_StrictModeErrorsOnInvalid(t *testing.T) {
+	unsigned := BoardMeta{BoardID: "bbs.unsigned", CreatedBy: "system"}
+
+	if _, err := verifyBoardMetas([]BoardMeta{unsigned}, true); err == nil {
+		t.Fatalf("expected an error in strict mode for an unsigned board")
+	}
+}
+
+func TestVerifyBoardMetas_RejectsTamperedField(t *testing.T) {
+	b, _ := signedBoardMeta(t, "bbs.tampered")
+	b.Title = "改ざん済み" // signature no longer matches
+
+	out, err := verifyBoardMetas([]BoardMeta{b}, false)
+	if err != nil {
+		t.Fatalf("verifyBoardMetas: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("len(out) = %d, want 0 (tampered board should be dropped)", len(out))
+	}
+}
+
+func TestBoardsStrictVerifyEnabled(t *testing.T) {
+	old, had := os.LookupEnv("BOARDS_STRICT_VERIFY")
+	defer func() {
+		if had {
+			os.Setenv("BOARDS_STRICT_VERIFY", old)
+		} else {
+			os.Unsetenv("BOARDS_STRICT_VERIFY")
+		}
+	}()
+
+	os.Unsetenv("BOARDS_STRICT_VERIFY")
+	if boardsStrictVerifyEnabled() {
+		t.Fatalf("expected false with BOARDS_STRICT_VERIFY unset")
+	}
+
+	os.Setenv("BOARDS_STRICT_VERIFY", "true")
+	if !boardsStrictVerifyEnabled() {
+		t.Fatalf("expected true with BOARDS_STRICT_VERIFY=true")
+	}
+}
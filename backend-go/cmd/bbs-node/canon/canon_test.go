@@ -0,0 +1,136 @@
+package canon
+
+import "testing"
+
+// These are golden vectors: the exact bytes a post create/edit/tombstone
+// signature is computed over. A client SDK reimplementing CanonicalEncode
+// must reproduce these byte-for-byte, so don't "fix" a vector's formatting
+// to look nicer — changing it changes what every existing signature
+// verifies against.
+
+func strp(s string) *string { return &s }
+
+func TestEncodeSignedPost_CreateGoldenVector(t *testing.T) {
+	sp := SignedPost{
+		Version:      1,
+		ThreadID:     "thread-1",
+		AuthorPubKey: "ed25519:abc",
+		DisplayName:  strp("alice"),
+		Body:         &SignedPostBody{Format: "md", Content: "hello"},
+		CreatedAt:    strp("2025-01-01T00:00:00Z"),
+	}
+	got, err := EncodeSignedPost(PrefixPostCreate, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost: %v", err)
+	}
+	want := "flexbbs.post.v1\n" +
+		`{"authorPubKey":"ed25519:abc","body":{"content":"hello","format":"md"},` +
+		`"createdAt":"2025-01-01T00:00:00Z","displayName":"alice","editedAt":null,` +
+		`"parentPostCid":null,"postCid":null,"threadId":"thread-1","tombstonedAt":null,"version":1}`
+	if string(got) != want {
+		t.Fatalf("payload mismatch:\n got=%s\nwant=%s", got, want)
+	}
+}
+
+func TestEncodeSignedPost_EditGoldenVector(t *testing.T) {
+	sp := SignedPost{
+		Version:      1,
+		ThreadID:     "thread-1",
+		PostCID:      strp("oldcid"),
+		AuthorPubKey: "ed25519:abc",
+		Body:         &SignedPostBody{Format: "md", Content: "hello edited"},
+		EditedAt:     strp("2025-01-02T00:00:00Z"),
+	}
+	got, err := EncodeSignedPost(PrefixPostEdit, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost: %v", err)
+	}
+	want := "flexbbs.post.edit.v1\n" +
+		`{"authorPubKey":"ed25519:abc","body":{"content":"hello edited","format":"md"},` +
+		`"createdAt":null,"displayName":null,"editedAt":"2025-01-02T00:00:00Z",` +
+		`"parentPostCid":null,"postCid":"oldcid","threadId":"thread-1","tombstonedAt":null,"version":1}`
+	if string(got) != want {
+		t.Fatalf("payload mismatch:\n got=%s\nwant=%s", got, want)
+	}
+}
+
+func TestEncodeSignedPost_TombstoneGoldenVector(t *testing.T) {
+	sp := SignedPost{
+		Version:      1,
+		ThreadID:     "thread-1",
+		PostCID:      strp("oldcid"),
+		AuthorPubKey: "ed25519:abc",
+		TombstonedAt: strp("2025-01-03T00:00:00Z"),
+	}
+	got, err := EncodeSignedPost(PrefixPostTombstone, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost: %v", err)
+	}
+	want := "flexbbs.post.tombstone.v1\n" +
+		`{"authorPubKey":"ed25519:abc","body":null,"createdAt":null,"displayName":null,` +
+		`"editedAt":null,"parentPostCid":null,"postCid":"oldcid","threadId":"thread-1",` +
+		`"tombstonedAt":"2025-01-03T00:00:00Z","version":1}`
+	if string(got) != want {
+		t.Fatalf("payload mismatch:\n got=%s\nwant=%s", got, want)
+	}
+}
+
+// TestEncodeSignedPost_DifferentActionsDiverge confirms the domain
+// separation prefixes actually do their job: the same SignedPost content
+// must not canonical-encode to the same bytes for two different actions,
+// or a signature for one action could be replayed as another.
+func TestEncodeSignedPost_DifferentActionsDiverge(t *testing.T) {
+	sp := SignedPost{
+		Version:      1,
+		ThreadID:     "thread-1",
+		PostCID:      strp("cid-1"),
+		AuthorPubKey: "ed25519:abc",
+	}
+	create, err := EncodeSignedPost(PrefixPostCreate, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost(create): %v", err)
+	}
+	edit, err := EncodeSignedPost(PrefixPostEdit, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost(edit): %v", err)
+	}
+	tombstone, err := EncodeSignedPost(PrefixPostTombstone, sp)
+	if err != nil {
+		t.Fatalf("EncodeSignedPost(tombstone): %v", err)
+	}
+	if string(create) == string(edit) || string(edit) == string(tombstone) || string(create) == string(tombstone) {
+		t.Fatalf("expected distinct payloads per action, got:\ncreate=%s\nedit=%s\ntombstone=%s", create, edit, tombstone)
+	}
+}
+
+// TestCanonicalEncode_NFCNormalization confirms two Unicode representations
+// of the same display name (precomposed "é" vs. "e" + combining acute)
+// canonical-encode identically, so a client that normalizes differently
+// than another still produces a verifiable signature.
+func TestCanonicalEncode_NFCNormalization(t *testing.T) {
+	const precomposedE = "Caf\u00e9"  // é as the single precomposed codepoint U+00E9
+	const decomposedE = "Cafe\u0301" // "e" followed by combining acute accent U+0301
+
+	precomposed := SignedPost{
+		Version:      1,
+		ThreadID:     "thread-1",
+		AuthorPubKey: "ed25519:abc",
+		DisplayName:  strp(precomposedE),
+		Body:         &SignedPostBody{Format: "md", Content: "hi"},
+		CreatedAt:    strp("2025-01-01T00:00:00Z"),
+	}
+	decomposed := precomposed
+	decomposed.DisplayName = strp(decomposedE)
+
+	got1, err := CanonicalEncode(precomposed)
+	if err != nil {
+		t.Fatalf("CanonicalEncode(precomposed): %v", err)
+	}
+	got2, err := CanonicalEncode(decomposed)
+	if err != nil {
+		t.Fatalf("CanonicalEncode(decomposed): %v", err)
+	}
+	if string(got1) != string(got2) {
+		t.Fatalf("expected NFC-normalized encodings to match:\nprecomposed=%s\ndecomposed=%s", got1, got2)
+	}
+}
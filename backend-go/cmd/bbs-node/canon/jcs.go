@@ -0,0 +1,187 @@
+package canon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CanonicalEncode serializes v deterministically: object keys sorted by
+// UTF-16 code unit order (the ordering RFC 8785's JSON Canonicalization
+// Scheme requires — see flex-bbs/backend-go/bbs/signature's CanonicalJSON,
+// which this mirrors), no insignificant whitespace, numbers formatted per
+// ECMA-262 7.1.12.1, and every string normalized to Unicode NFC before
+// encoding so two byte-distinct inputs that are canonically the same
+// string (e.g. "é" as one codepoint vs. "e" + combining acute) sign
+// identically. It round-trips v through encoding/json first so struct
+// tags are honored the same way they are everywhere else in this codebase.
+func CanonicalEncode(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeCanonical(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCanonical(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if val {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		return encodeCanonicalNumber(buf, val)
+	case string:
+		encodeCanonicalString(buf, norm.NFC.String(val))
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return utf16Less(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeCanonicalString(buf, norm.NFC.String(k))
+			buf.WriteByte(':')
+			if err := encodeCanonical(buf, val[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canon: unsupported type %T", v)
+	}
+	return nil
+}
+
+// utf16Less compares two strings by UTF-16 code unit order, as RFC 8785
+// requires for object key sorting (distinct from Go's default byte-wise
+// string comparison once characters outside the BMP are involved).
+func utf16Less(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		ca, na := utf16Units(ra[i])
+		cb, nb := utf16Units(rb[j])
+		for k := 0; k < len(ca) && k < len(cb); k++ {
+			if ca[k] != cb[k] {
+				return ca[k] < cb[k]
+			}
+		}
+		if len(ca) != len(cb) {
+			return len(ca) < len(cb)
+		}
+		i += na
+		j += nb
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+func utf16Units(r rune) ([]uint16, int) {
+	if r > 0xFFFF {
+		r -= 0x10000
+		return []uint16{0xD800 + uint16(r>>10), 0xDC00 + uint16(r&0x3FF)}, 1
+	}
+	return []uint16{uint16(r)}, 1
+}
+
+func encodeCanonicalNumber(buf *bytes.Buffer, n json.Number) error {
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("canon: number %q is not representable in JSON", n)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		return nil
+	}
+	buf.WriteString(formatECMANumber(f))
+	return nil
+}
+
+// formatECMANumber renders f the way ECMA-262 7.1.12.1 (ToString applied to
+// a Number) would, which is what RFC 8785 mandates for non-integral values.
+func formatECMANumber(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	// Go emits exponents as e+07 / e-07; ECMA-262 uses e+7 / e-7 (no
+	// leading zero) and always includes the sign.
+	if i := bytes.IndexByte([]byte(s), 'e'); i >= 0 {
+		mantissa, exp := s[:i], s[i+1:]
+		sign := "+"
+		if exp[0] == '+' || exp[0] == '-' {
+			sign = string(exp[0])
+			exp = exp[1:]
+		}
+		for len(exp) > 1 && exp[0] == '0' {
+			exp = exp[1:]
+		}
+		s = mantissa + "e" + sign + exp
+	}
+	return s
+}
+
+func encodeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
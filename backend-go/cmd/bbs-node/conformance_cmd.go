@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"flex-bbs/backend-go/bbs/conformance"
+)
+
+// runConformance drives bbs/conformance's black-box suite, either against
+// an already-running node (-base-url) or against an ephemeral one this
+// command spins up itself in the given -role against a mock flex-ipfs (the
+// default, when -base-url is empty) — the same two shapes gateway-
+// conformance supports against boxo/kubo, so a CI matrix job can run this
+// once per role without standing up real infrastructure, or point it at a
+// node it already deployed.
+func runConformance(args []string) int {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	baseURL := fs.String("base-url", "", "base URL of an already-running bbs-node to test against; if empty, an ephemeral node is started in -role against a mock flex-ipfs")
+	fixturesCAR := fs.String("fixtures-car", "", "CAR-style archive (see storage.ExportBoard) of pre-signed fixture content to load before running checks; only valid when -base-url is empty, since loading fixtures needs a storage handle into the node under test")
+	fixtureBoardID := fs.String("fixture-board-id", "", "board ID already present on the node at -base-url for read-path checks to exercise; ignored (and overwritten) when -fixtures-car is used instead")
+	junitOut := fs.String("junit-out", "", "write a JUnit XML report to this path (in addition to the human summary on stdout)")
+	role := fs.String("role", "full", "role to start the ephemeral node in when -base-url is empty: client | indexer | archiver | full")
+	_ = fs.Parse(args)
+
+	ctx := context.Background()
+	opts := conformance.Options{FixtureBoardID: *fixtureBoardID}
+
+	target := *baseURL
+	if target == "" {
+		h, err := conformance.StartEphemeralNode(*role)
+		if err != nil {
+			log.Printf("conformance: start ephemeral node: %v", err)
+			return 1
+		}
+		defer h.Close()
+		target = h.BaseURL
+
+		if *fixturesCAR != "" {
+			boardID, _, err := h.LoadFixturesCAR(ctx, *fixturesCAR)
+			if err != nil {
+				log.Printf("conformance: load fixtures: %v", err)
+				return 1
+			}
+			opts.FixtureBoardID = boardID
+		}
+	} else if *fixturesCAR != "" {
+		log.Printf("conformance: -fixtures-car requires an ephemeral node (leave -base-url empty); ignoring it")
+	}
+
+	report, err := conformance.Run(ctx, target, opts)
+	if err != nil {
+		log.Printf("conformance: run: %v", err)
+		return 1
+	}
+
+	fmt.Print(report.Summary())
+
+	if *junitOut != "" {
+		xmlBytes, err := report.JUnitXML("bbs-node-conformance")
+		if err != nil {
+			log.Printf("conformance: render junit xml: %v", err)
+			return 1
+		}
+		if err := os.WriteFile(*junitOut, xmlBytes, 0o644); err != nil {
+			log.Printf("conformance: write junit xml: %v", err)
+			return 1
+		}
+	}
+
+	if report.Failed() > 0 {
+		return 1
+	}
+	return 0
+}
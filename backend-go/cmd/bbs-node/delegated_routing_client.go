@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+// ========================================
+// Delegated Routing V1 バックエンド
+// ========================================
+
+// RoutingBackend は FlexIPFSClient が話すプロトコルを選択します。
+type RoutingBackend int
+
+const (
+	// RoutingBackendFlex は Flexible-IPFS 独自の /dht/* マルチパートAPIを使います。
+	RoutingBackendFlex RoutingBackend = iota
+	// RoutingBackendDelegatedV1 は boxo/someguy, rainbow などが実装する
+	// 標準の Delegated Routing V1 HTTP API (IPIP-337/IPIP-417) を使います。
+	RoutingBackendDelegatedV1
+)
+
+// NewRoutingClient は backend に応じて FlexIPFSClient の実装を選びます。
+// 呼び出し側のBBSコードを変更せずに、実行時にルーティングのバックエンドを
+// Flexible-IPFS からボックス互換のデリゲートルーターへ切り替えられます。
+func NewRoutingClient(baseURL string, backend RoutingBackend) FlexIPFSClient {
+	switch backend {
+	case RoutingBackendDelegatedV1:
+		return NewDelegatedRoutingClient(baseURL)
+	default:
+		return NewFlexIPFSClient(baseURL)
+	}
+}
+
+// delegatedRoutingClient は Delegated Routing V1 HTTP API を話す FlexIPFSClient 実装です。
+// このAPIはルーティング専用（プロバイダ・ピア・IPNSレコードの検索と告知）であり、
+// Flexible-IPFS の /dht/putvalue のような汎用キーバリューストアは提供しないため、
+// PutValue/PutValueWithAttr/GetValue はサポート対象外としてエラーを返します。
+type delegatedRoutingClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// selfPeerID と signingKey が両方設定されている場合、Provide は
+	// IPIP-378 の署名済みプロバイダレコードとしてアナウンスされます。
+	selfPeerID string
+	signingKey ed25519.PrivateKey
+}
+
+// NewDelegatedRoutingClient は baseURL (例: "https://somerouter.example/routing/v1"
+// のひとつ上の階層、つまり "https://somerouter.example") に対する読み取り専用の
+// クライアントを返します。署名付き Provide を使う場合は
+// WithProvideIdentity で呼び出し元のピアIDと秘密鍵を設定してください。
+func NewDelegatedRoutingClient(baseURL string) FlexIPFSClient {
+	return &delegatedRoutingClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// WithProvideIdentity は Provide が署名済みプロバイダレコードを送れるように、
+// この接続元のピアIDと署名鍵を設定します。
+func (c *delegatedRoutingClient) WithProvideIdentity(selfPeerID string, signingKey ed25519.PrivateKey) *delegatedRoutingClient {
+	c.selfPeerID = selfPeerID
+	c.signingKey = signingKey
+	return c
+}
+
+func (c *delegatedRoutingClient) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *delegatedRoutingClient) PutValueWithAttr(ctx context.Context, key string, value []byte, attrs map[string]string, tags []string) error {
+	return &FlexClientError{Op: "PutValueWithAttr", Err: fmt.Errorf("delegated routing v1 has no generic key/value store")}
+}
+
+func (c *delegatedRoutingClient) PutValue(ctx context.Context, key string, value []byte) error {
+	return &FlexClientError{Op: "PutValue", Err: fmt.Errorf("delegated routing v1 has no generic key/value store")}
+}
+
+func (c *delegatedRoutingClient) GetValue(ctx context.Context, key string) (*FlexGetValueResponse, error) {
+	return nil, &FlexClientError{Op: "GetValue", Err: fmt.Errorf("delegated routing v1 has no generic key/value store")}
+}
+
+func (c *delegatedRoutingClient) PutSignedValue(ctx context.Context, key string, payload []byte, attrs map[string]string, tags []string, priv ed25519.PrivateKey) error {
+	return &FlexClientError{Op: "PutSignedValue", Err: fmt.Errorf("delegated routing v1 has no generic key/value store; use NewFlexIPFSClient instead")}
+}
+
+func (c *delegatedRoutingClient) GetSignedValue(ctx context.Context, key string, expectedPubKey ed25519.PublicKey) ([]byte, error) {
+	return nil, &FlexClientError{Op: "GetSignedValue", Err: fmt.Errorf("delegated routing v1 has no generic key/value store; use NewFlexIPFSClient instead")}
+}
+
+// delegatedPeerRecord は IPIP-337 の "Peer" スキーマの必要十分なサブセットです。
+type delegatedPeerRecord struct {
+	Schema string   `json:"Schema"`
+	ID     string   `json:"ID"`
+	Addrs  []string `json:"Addrs,omitempty"`
+}
+
+// delegatedProvidersResponse はJSON形式 (application/json) での応答の包み紙です。
+// NDJSONの場合は各行がそのまま1件の delegatedPeerRecord になります。
+type delegatedProvidersResponse struct {
+	Providers []delegatedPeerRecord `json:"Providers"`
+}
+
+func (c *delegatedRoutingClient) FindProviders(ctx context.Context, key string) (*FlexFindProvidersResponse, error) {
+	endpoint := c.baseURL + "/routing/v1/providers/" + url.PathEscape(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, &FlexClientError{Op: "FindProviders", Err: err}
+	}
+	req.Header.Set("Accept", "application/x-ndjson, application/json;q=0.9")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &FlexClientError{Op: "FindProviders", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, c.parseErrorResponse(resp, "FindProviders")
+	}
+
+	records, err := c.decodeProviderRecords(resp)
+	if err != nil {
+		return nil, &FlexClientError{Op: "FindProviders", Err: err}
+	}
+
+	peers := make([]FlexPeer, 0, len(records))
+	for _, r := range records {
+		peers = append(peers, FlexPeer{ID: r.ID, Addrs: r.Addrs})
+	}
+	return &FlexFindProvidersResponse{Providers: peers}, nil
+}
+
+func (c *delegatedRoutingClient) decodeProviderRecords(resp *http.Response) ([]delegatedPeerRecord, error) {
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "ndjson") {
+		var out []delegatedPeerRecord
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var rec delegatedPeerRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				return nil, fmt.Errorf("decode ndjson provider record: %w", err)
+			}
+			out = append(out, rec)
+		}
+		return out, scanner.Err()
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var wrapped delegatedProvidersResponse
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("decode json provider response: %w", err)
+	}
+	return wrapped.Providers, nil
+}
+
+// FindProvidersStream issues the same request as FindProviders but decodes
+// the NDJSON response line-by-line, handing each record to the returned
+// channel as it arrives rather than waiting for the DHT walk to finish. A
+// record that fails to decode produces an error event and the stream
+// continues; a transport-level failure (request setup, non-2xx status, a
+// mid-stream read error) closes the channel after one final error event.
+func (c *delegatedRoutingClient) FindProvidersStream(ctx context.Context, key string) (<-chan FlexProviderEvent, error) {
+	endpoint := c.baseURL + "/routing/v1/providers/" + url.PathEscape(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, &FlexClientError{Op: "FindProvidersStream", Err: err}
+	}
+	// Ask specifically for NDJSON here: a non-streaming JSON response would
+	// have to be buffered whole anyway, defeating the point of this method.
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &FlexClientError{Op: "FindProvidersStream", Err: err}
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, c.parseErrorResponse(resp, "FindProvidersStream")
+	}
+
+	ch := make(chan FlexProviderEvent)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var rec delegatedPeerRecord
+			evt := FlexProviderEvent{}
+			if err := json.Unmarshal(line, &rec); err != nil {
+				evt.Err = fmt.Errorf("decode ndjson provider record: %w", err)
+			} else {
+				evt.Peer = FlexPeer{ID: rec.ID, Addrs: rec.Addrs}
+			}
+			select {
+			case ch <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			select {
+			case ch <- FlexProviderEvent{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// delegatedProvideAnnouncement is the IPIP-378 signed provider record body
+// sent to PUT /routing/v1/providers. Payload is the JWS compact serialization
+// wrapping the delegatedPeerRecord as JSON.
+type delegatedProvideAnnouncement struct {
+	Providers []string `json:"Providers"`
+}
+
+func (c *delegatedRoutingClient) Provide(ctx context.Context, key string) error {
+	if c.selfPeerID == "" || len(c.signingKey) == 0 {
+		return &FlexClientError{Op: "Provide", Err: fmt.Errorf("delegated routing v1 Provide requires WithProvideIdentity to be configured")}
+	}
+
+	payload, err := json.Marshal(delegatedPeerRecord{Schema: "peer", ID: c.selfPeerID})
+	if err != nil {
+		return &FlexClientError{Op: "Provide", Err: err}
+	}
+	jws, err := signature.SignJWS(c.signingKey, payload)
+	if err != nil {
+		return &FlexClientError{Op: "Provide", Err: err}
+	}
+
+	body, err := json.Marshal(delegatedProvideAnnouncement{Providers: []string{jws}})
+	if err != nil {
+		return &FlexClientError{Op: "Provide", Err: err}
+	}
+
+	endpoint := c.baseURL + "/routing/v1/providers"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return &FlexClientError{Op: "Provide", Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &FlexClientError{Op: "Provide", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return c.parseErrorResponse(resp, "Provide")
+	}
+	return nil
+}
+
+func (c *delegatedRoutingClient) PeerList(ctx context.Context) (*FlexPeerListResponse, error) {
+	// Delegated Routing V1 only exposes peer lookup by ID (GET /routing/v1/peers/{peer-id}),
+	// not a full peer listing, so there's no honest way to implement this.
+	return nil, &FlexClientError{Op: "PeerList", Err: fmt.Errorf("delegated routing v1 has no peer listing endpoint, only lookup by peer id")}
+}
+
+func (c *delegatedRoutingClient) GetIPNS(ctx context.Context, name string) (*FlexIPNSRecord, error) {
+	endpoint := c.baseURL + "/routing/v1/ipns/" + url.PathEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, &FlexClientError{Op: "GetIPNS", Err: err}
+	}
+	req.Header.Set("Accept", "application/vnd.ipfs.ipns-record")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &FlexClientError{Op: "GetIPNS", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, c.parseErrorResponse(resp, "GetIPNS")
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &FlexClientError{Op: "GetIPNS", Err: err}
+	}
+	return &FlexIPNSRecord{Name: name, Value: body}, nil
+}
+
+func (c *delegatedRoutingClient) PutIPNS(ctx context.Context, name string, record *FlexIPNSRecord) error {
+	endpoint := c.baseURL + "/routing/v1/ipns/" + url.PathEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(record.Value))
+	if err != nil {
+		return &FlexClientError{Op: "PutIPNS", Err: err}
+	}
+	req.Header.Set("Content-Type", "application/vnd.ipfs.ipns-record")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &FlexClientError{Op: "PutIPNS", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return c.parseErrorResponse(resp, "PutIPNS")
+	}
+	return nil
+}
+
+func (c *delegatedRoutingClient) parseErrorResponse(resp *http.Response, op string) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var flexErr FlexErrorResponse
+	if err := json.Unmarshal(body, &flexErr); err == nil && flexErr.Message != "" {
+		return &flexErr
+	}
+	return &FlexClientError{
+		Op:  op,
+		Err: fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body)),
+	}
+}
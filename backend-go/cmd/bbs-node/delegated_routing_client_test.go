@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+func TestDelegatedRoutingClient_FindProviders_NDJSON(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/routing/v1/providers/bafytest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"Schema":"peer","ID":"12D3Koo1","Addrs":["/ip4/1.2.3.4/tcp/4001"]}` + "\n"))
+		w.Write([]byte(`{"Schema":"peer","ID":"12D3Koo2"}` + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewDelegatedRoutingClient(srv.URL)
+	got, err := client.FindProviders(context.Background(), "bafytest")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(got.Providers) != 2 {
+		t.Fatalf("got %d providers, want 2", len(got.Providers))
+	}
+	if got.Providers[0].ID != "12D3Koo1" || len(got.Providers[0].Addrs) != 1 {
+		t.Fatalf("unexpected first provider: %+v", got.Providers[0])
+	}
+}
+
+func TestDelegatedRoutingClient_FindProviders_JSONFallback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/routing/v1/providers/bafytest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Providers":[{"Schema":"peer","ID":"12D3Koo3"}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewDelegatedRoutingClient(srv.URL)
+	got, err := client.FindProviders(context.Background(), "bafytest")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(got.Providers) != 1 || got.Providers[0].ID != "12D3Koo3" {
+		t.Fatalf("unexpected providers: %+v", got.Providers)
+	}
+}
+
+func TestDelegatedRoutingClient_Provide_RequiresIdentity(t *testing.T) {
+	client := NewDelegatedRoutingClient("http://example.invalid")
+	if err := client.Provide(context.Background(), "bafytest"); err == nil {
+		t.Fatal("expected Provide without an identity to fail")
+	}
+}
+
+func TestDelegatedRoutingClient_Provide_SignsAnnouncement(t *testing.T) {
+	pub, priv, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	privKey, err := signature.ParsePrivateKey(priv)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	_ = pub
+
+	var putBody []byte
+	mux := http.NewServeMux()
+	mux.HandleFunc("/routing/v1/providers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		buf := make([]byte, r.ContentLength)
+		r.Body.Read(buf)
+		putBody = buf
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewDelegatedRoutingClient(srv.URL).(*delegatedRoutingClient).WithProvideIdentity("12D3KooSelf", privKey)
+	if err := client.Provide(context.Background(), "bafytest"); err != nil {
+		t.Fatalf("Provide: %v", err)
+	}
+	if len(putBody) == 0 {
+		t.Fatal("expected PUT body to be sent")
+	}
+}
+
+func TestDelegatedRoutingClient_FindProvidersStream_YieldsAsTheyArrive(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/routing/v1/providers/bafytest", func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Schema":"peer","ID":"12D3Koo1"}` + "\n"))
+		if flusher != nil {
+			flusher.Flush()
+		}
+		w.Write([]byte(`not-json` + "\n"))
+		w.Write([]byte(`{"Schema":"peer","ID":"12D3Koo2"}` + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewDelegatedRoutingClient(srv.URL)
+	events, err := client.FindProvidersStream(context.Background(), "bafytest")
+	if err != nil {
+		t.Fatalf("FindProvidersStream: %v", err)
+	}
+
+	var ids []string
+	var errCount int
+	for evt := range events {
+		if evt.Err != nil {
+			errCount++
+			continue
+		}
+		ids = append(ids, evt.Peer.ID)
+	}
+	if errCount != 1 {
+		t.Fatalf("got %d decode errors, want 1", errCount)
+	}
+	if len(ids) != 2 || ids[0] != "12D3Koo1" || ids[1] != "12D3Koo2" {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+}
+
+func TestDelegatedRoutingClient_FindProvidersStream_RespectsCancellation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/routing/v1/providers/bafytest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Schema":"peer","ID":"12D3Koo1"}` + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewDelegatedRoutingClient(srv.URL)
+	events, err := client.FindProvidersStream(ctx, "bafytest")
+	if err != nil {
+		t.Fatalf("FindProvidersStream: %v", err)
+	}
+	for range events {
+		// Drain; the goroutine must still close the channel promptly.
+	}
+}
+
+func TestDelegatedRoutingClient_PutValue_Unsupported(t *testing.T) {
+	client := NewDelegatedRoutingClient("http://example.invalid")
+	if err := client.PutValue(context.Background(), "k", []byte("v")); err == nil {
+		t.Fatal("expected PutValue to be unsupported")
+	}
+	if _, err := client.GetValue(context.Background(), "k"); err == nil {
+		t.Fatal("expected GetValue to be unsupported")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"flex-bbs/backend-go/bbs/dnslink"
+	"flex-bbs/backend-go/bbs/flexipfs"
+	"flex-bbs/backend-go/bbs/storage"
+)
+
+// runResolveDNSLink is a debugging aid for the bbs/dnslink resolver: it
+// resolves a single name's "_bbs.<name>" TXT records against live DNS (and,
+// for bbs-pointer entries, against flex-ipfs's BoardPointer records) and
+// prints whatever BoardRefs come back, without touching boards.json.
+func runResolveDNSLink(args []string) int {
+	fs := flag.NewFlagSet("resolve-dnslink", flag.ExitOnError)
+	name := fs.String("name", "", "dnslink name to resolve (e.g. bbs.example.org or dnslink://bbs.example.org)")
+	flexBase := fs.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL, used to follow bbs-pointer records")
+	_ = fs.Parse(args)
+
+	if *name == "" {
+		log.Printf("missing required field: --name")
+		return 2
+	}
+
+	st := storage.New(flexipfs.New(*flexBase))
+	r := dnslink.NewResolver(st)
+
+	refs, err := r.Resolve(context.Background(), *name)
+	if err != nil {
+		log.Printf("resolve-dnslink: %v", err)
+		return 1
+	}
+	for _, ref := range refs {
+		fmt.Printf("%s\t%s\n", ref.BoardID, ref.BoardMetaCID)
+	}
+	return 0
+}
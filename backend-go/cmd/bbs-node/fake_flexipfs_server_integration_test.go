@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakeFlexIPFSServer_PutAndGetRoundtrip(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	client := NewFlexIPFSClient(srv.URL())
+	ctx := context.Background()
+
+	attrs := map[string]string{"type": "post"}
+	tags := []string{"board-general"}
+	if err := client.PutValueWithAttr(ctx, "post-1", []byte("hello world"), attrs, tags); err != nil {
+		t.Fatalf("PutValueWithAttr: %v", err)
+	}
+
+	got, err := client.GetValue(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if string(got.Value) != "hello world" {
+		t.Fatalf("Value = %q, want %q", got.Value, "hello world")
+	}
+	if got.Type != "post" {
+		t.Fatalf("Type = %q, want %q", got.Type, "post")
+	}
+}
+
+func TestFakeFlexIPFSServer_GetValue_NotFound(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	client := NewFlexIPFSClient(srv.URL())
+	_, err := client.GetValue(context.Background(), "missing")
+	if err == nil {
+		t.Fatal("expected error for missing key")
+	}
+	if _, ok := err.(*FlexErrorResponse); !ok {
+		t.Fatalf("error type = %T, want *FlexErrorResponse", err)
+	}
+}
+
+func TestFakeFlexIPFSServer_FindProvidersAndProvide(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.SetProviders("bafytest", []FlexPeer{{ID: "peer-a"}, {ID: "peer-b"}})
+
+	client := NewFlexIPFSClient(srv.URL())
+	ctx := context.Background()
+
+	resp, err := client.FindProviders(ctx, "bafytest")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(resp.Providers) != 2 {
+		t.Fatalf("got %d providers, want 2", len(resp.Providers))
+	}
+
+	if err := client.Provide(ctx, "bafytest"); err != nil {
+		t.Fatalf("Provide: %v", err)
+	}
+	if got := srv.Provided(); len(got) != 1 || got[0] != "bafytest" {
+		t.Fatalf("Provided() = %v, want [bafytest]", got)
+	}
+}
+
+func TestFakeFlexIPFSServer_ForceStatus(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.ForceStatus = 503
+	srv.ForceBody = []byte(`{"message":"overloaded","code":"ERR_BUSY"}`)
+
+	client := NewFlexIPFSClient(srv.URL())
+	_, err := client.GetValue(context.Background(), "anything")
+	if err == nil {
+		t.Fatal("expected error from forced 503")
+	}
+	flexErr, ok := err.(*FlexErrorResponse)
+	if !ok {
+		t.Fatalf("error type = %T, want *FlexErrorResponse", err)
+	}
+	if flexErr.Code != "ERR_BUSY" {
+		t.Fatalf("Code = %q, want ERR_BUSY", flexErr.Code)
+	}
+}
+
+func TestFakeFlexIPFSServer_TruncateBody_ErrorsAsUnexpectedEOF(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.TruncateBody = true
+
+	client := NewFlexIPFSClient(srv.URL())
+	_, err := client.GetValue(context.Background(), "anything")
+	if err == nil {
+		t.Fatal("expected error from truncated response body")
+	}
+}
+
+func TestFakeFlexIPFSServer_MalformedJSON_FallsBackToRawBytes(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	client := NewFlexIPFSClient(srv.URL())
+	ctx := context.Background()
+	if err := client.PutValueWithAttr(ctx, "post-1", []byte("hello"), nil, nil); err != nil {
+		t.Fatalf("PutValueWithAttr: %v", err)
+	}
+
+	srv.MalformedJSON = true
+
+	// GetValue falls back to treating an unparsable body as raw bytes rather
+	// than failing, matching httpFlexIPFSClient.GetValue's documented fallback.
+	got, err := client.GetValue(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if len(got.Value) == 0 {
+		t.Fatal("expected non-empty fallback raw value")
+	}
+}
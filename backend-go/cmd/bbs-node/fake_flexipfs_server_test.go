@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// FakeFlexIPFSServer is a real httptest.Server implementing the same wire
+// protocol as httpFlexIPFSClient talks to (multipart puts, query-string
+// gets, JSON error bodies), so tests can exercise request building,
+// multipart encoding, and error parsing end-to-end instead of only the
+// FlexIPFSClient interface via MockFlexIPFSClient.
+type FakeFlexIPFSServer struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	storage   map[string]*mockStorageEntry
+	providers map[string][]FlexPeer
+	provided  []string
+	peers     []FlexPeer
+
+	// Fault injection, applied to every request handled while set. Zero
+	// values mean "behave normally".
+	Latency       time.Duration
+	ForceStatus   int
+	ForceBody     []byte
+	MalformedJSON bool
+	TruncateBody  bool
+}
+
+// NewFakeFlexIPFSServer starts the fake server. Callers must Close it.
+func NewFakeFlexIPFSServer() *FakeFlexIPFSServer {
+	f := &FakeFlexIPFSServer{
+		storage:   make(map[string]*mockStorageEntry),
+		providers: make(map[string][]FlexPeer),
+		peers: []FlexPeer{
+			{ID: "fake-peer-1", Addrs: []string{"/ip4/127.0.0.1/tcp/5001"}},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dht/putvaluewithattr", f.handlePutValueWithAttr)
+	mux.HandleFunc("/dht/getvalue", f.handleGetValue)
+	mux.HandleFunc("/dht/findprovs", f.handleFindProviders)
+	mux.HandleFunc("/dht/provide", f.handleProvide)
+	mux.HandleFunc("/dht/peerlist", f.handlePeerList)
+	f.srv = httptest.NewServer(mux)
+	return f
+}
+
+// URL returns the fake server's base URL, suitable for NewFlexIPFSClient.
+func (f *FakeFlexIPFSServer) URL() string {
+	return f.srv.URL
+}
+
+// Close shuts the underlying httptest.Server down.
+func (f *FakeFlexIPFSServer) Close() {
+	f.srv.Close()
+}
+
+// SetProviders configures the FlexPeer list FindProviders/FindProvidersStream
+// returns for key.
+func (f *FakeFlexIPFSServer) SetProviders(key string, peers []FlexPeer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.providers[key] = peers
+}
+
+// Provided returns the keys that have been announced via Provide, in call order.
+func (f *FakeFlexIPFSServer) Provided() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.provided))
+	copy(out, f.provided)
+	return out
+}
+
+// applyFaults sleeps Latency (if any) and, if ForceStatus is set, writes
+// ForceStatus/ForceBody and reports that the caller should stop handling the
+// request normally.
+func (f *FakeFlexIPFSServer) applyFaults(w http.ResponseWriter) (handled bool) {
+	f.mu.Lock()
+	latency := f.Latency
+	forceStatus := f.ForceStatus
+	forceBody := f.ForceBody
+	f.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if forceStatus > 0 {
+		w.WriteHeader(forceStatus)
+		if len(forceBody) > 0 {
+			w.Write(forceBody)
+		}
+		return true
+	}
+	return false
+}
+
+// writeJSON marshals v and writes it as the response body, honoring
+// MalformedJSON (corrupt the encoding) and TruncateBody (claim a larger
+// Content-Length than bytes actually written, so the client sees an
+// unexpected-EOF mid-read, simulating a dropped connection).
+func (f *FakeFlexIPFSServer) writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	f.mu.Lock()
+	malformed := f.MalformedJSON
+	truncate := f.TruncateBody
+	f.mu.Unlock()
+
+	if malformed && len(body) > 0 {
+		body = body[:len(body)-1]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if truncate && len(body) > 1 {
+		half := len(body) / 2
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(body)))
+		w.WriteHeader(status)
+		w.Write(body[:half])
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (f *FakeFlexIPFSServer) handlePutValueWithAttr(w http.ResponseWriter, r *http.Request) {
+	if f.applyFaults(w) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		f.writeJSON(w, http.StatusBadRequest, &FlexErrorResponse{Message: "bad multipart form: " + err.Error(), Code: "BAD_REQUEST"})
+		return
+	}
+	key := r.FormValue("arg")
+	if key == "" {
+		f.writeJSON(w, http.StatusBadRequest, &FlexErrorResponse{Message: "missing arg", Code: "BAD_REQUEST"})
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		f.writeJSON(w, http.StatusBadRequest, &FlexErrorResponse{Message: "missing file part: " + err.Error(), Code: "BAD_REQUEST"})
+		return
+	}
+	defer file.Close()
+	buf := make([]byte, 0, 4096)
+	chunk := make([]byte, 4096)
+	for {
+		n, readErr := file.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if readErr != nil {
+			break
+		}
+	}
+
+	var attrs map[string]string
+	if raw := r.FormValue("attrs"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &attrs); err != nil {
+			f.writeJSON(w, http.StatusBadRequest, &FlexErrorResponse{Message: "bad attrs json: " + err.Error(), Code: "BAD_REQUEST"})
+			return
+		}
+	}
+	var tags []string
+	if raw := r.FormValue("tags"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+			f.writeJSON(w, http.StatusBadRequest, &FlexErrorResponse{Message: "bad tags json: " + err.Error(), Code: "BAD_REQUEST"})
+			return
+		}
+	}
+
+	f.mu.Lock()
+	f.storage[key] = &mockStorageEntry{Value: buf, Attrs: attrs, Tags: tags}
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (f *FakeFlexIPFSServer) handleGetValue(w http.ResponseWriter, r *http.Request) {
+	if f.applyFaults(w) {
+		return
+	}
+	key := r.URL.Query().Get("arg")
+
+	f.mu.Lock()
+	entry, ok := f.storage[key]
+	f.mu.Unlock()
+	if !ok {
+		f.writeJSON(w, http.StatusNotFound, &FlexErrorResponse{Message: "key not found", Code: "NOT_FOUND"})
+		return
+	}
+
+	resp := FlexGetValueResponse{Value: entry.Value, Attrs: entry.Attrs, Tags: entry.Tags}
+	if t, ok := entry.Attrs["type"]; ok {
+		resp.Type = t
+	}
+	f.writeJSON(w, http.StatusOK, &resp)
+}
+
+func (f *FakeFlexIPFSServer) handleFindProviders(w http.ResponseWriter, r *http.Request) {
+	if f.applyFaults(w) {
+		return
+	}
+	key := r.URL.Query().Get("arg")
+
+	f.mu.Lock()
+	peers, ok := f.providers[key]
+	f.mu.Unlock()
+	if !ok {
+		peers = []FlexPeer{}
+	}
+	f.writeJSON(w, http.StatusOK, &FlexFindProvidersResponse{Providers: peers})
+}
+
+func (f *FakeFlexIPFSServer) handleProvide(w http.ResponseWriter, r *http.Request) {
+	if f.applyFaults(w) {
+		return
+	}
+	key := r.URL.Query().Get("arg")
+
+	f.mu.Lock()
+	f.provided = append(f.provided, key)
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *FakeFlexIPFSServer) handlePeerList(w http.ResponseWriter, r *http.Request) {
+	if f.applyFaults(w) {
+		return
+	}
+	f.mu.Lock()
+	peers := make([]FlexPeer, len(f.peers))
+	copy(peers, f.peers)
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, &FlexPeerListResponse{Peers: peers})
+}
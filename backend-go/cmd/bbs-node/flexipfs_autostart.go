@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,20 +16,129 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// flexIPFSProcState is flexIPFSProc's supervisor state machine: running ->
+// backingOff (on an unexpected exit, until the retry sleep elapses) ->
+// running again, or -> failed once flexIPFSMaxFailures exits have happened
+// within flexIPFSFailureWindow, or -> stopped once stop() is called.
+type flexIPFSProcState int
+
+const (
+	flexIPFSStateRunning flexIPFSProcState = iota
+	flexIPFSStateBackingOff
+	flexIPFSStateFailed
+	flexIPFSStateStopped
+)
+
+func (s flexIPFSProcState) String() string {
+	switch s {
+	case flexIPFSStateRunning:
+		return "running"
+	case flexIPFSStateBackingOff:
+		return "backing_off"
+	case flexIPFSStateFailed:
+		return "failed"
+	case flexIPFSStateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	flexIPFSInitialBackoff = 1 * time.Second
+	flexIPFSMaxBackoff     = 30 * time.Second
+	// flexIPFSHealthyGrace is how long flex-ipfs must stay up (after
+	// waitForFlexIPFS confirms it's serving) before a later crash resets the
+	// backoff instead of continuing to escalate from where it left off.
+	flexIPFSHealthyGrace = 60 * time.Second
+	// flexIPFSMaxFailures/flexIPFSFailureWindow: the supervisor gives up
+	// (state -> failed) rather than retrying forever once this many exits
+	// happen within this window, e.g. a port conflict or missing jar that
+	// a restart will never fix on its own.
+	flexIPFSMaxFailures   = 5
+	flexIPFSFailureWindow = 5 * time.Minute
+)
+
 type flexIPFSProc struct {
+	mu sync.Mutex
+
 	cmd         *exec.Cmd
 	stdinWriter io.Closer
-	logFile     *os.File
+	logCloser   io.Closer
+	// exited is closed by monitor once cmd.Wait() returns, so stop() can poll
+	// for the current child's exit instead of sleeping a fixed duration
+	// between each step of its kill escalation.
+	exited chan struct{}
+
+	javaBin, flexBaseDir, gwEndpointOverride, logDir, baseURL string
+	// sshTarget/sshKeyPath are set instead of javaBin when flex-ipfs is being
+	// managed on a remote host over ssh (see maybeStartFlexIPFSRemote);
+	// launch dispatches to launchFlexIPFSProcessRemote when sshTarget != "".
+	sshTarget, sshKeyPath string
+	// killStep bounds how long stop() waits after each escalation step
+	// (Interrupt, then SIGTERM) before moving on to the next.
+	killStep time.Duration
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	state        flexIPFSProcState
+	backoff      time.Duration
+	restartCount int
+	lastExitErr  error
+	startedAt    time.Time
+	failures     []time.Time
+}
+
+// flexIPFSStatus is flexIPFSProc.status's snapshot of the supervisor's
+// current state, for /status-style HTTP handlers elsewhere in bbs-node to
+// surface without reaching into flexIPFSProc's internals.
+type flexIPFSStatus struct {
+	State        string
+	RestartCount int
+	LastExitErr  error
+	Uptime       time.Duration
+}
+
+// status returns a point-in-time snapshot of p's supervisor state. Safe to
+// call on a nil p (e.g. autostart disabled or skipped because flex-ipfs was
+// already running), reporting it as stopped.
+func (p *flexIPFSProc) status() flexIPFSStatus {
+	if p == nil {
+		return flexIPFSStatus{State: flexIPFSStateStopped.String()}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := flexIPFSStatus{
+		State:        p.state.String(),
+		RestartCount: p.restartCount,
+		LastExitErr:  p.lastExitErr,
+	}
+	if p.state == flexIPFSStateRunning && !p.startedAt.IsZero() {
+		st.Uptime = time.Since(p.startedAt)
+	}
+	return st
 }
 
-func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpointOverride, logDir string) (*flexIPFSProc, error) {
+// coord, if non-nil, has the returned flexIPFSProc (and cleanup of the
+// cross-process start lock) registered with it, so a signal-driven shutdown
+// stops flex-ipfs without the caller needing its own defer/cleanup. Callers
+// with no coordinator of their own (e.g. the one-shot CLI subcommands) pass
+// nil and keep using defer flexProc.stop() as before.
+func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpointOverride, logDir string, killStep time.Duration, sshTarget, sshKeyPath string, coord *shutdownCoordinator) (*flexIPFSProc, error) {
 	if !isLocalBaseURL(baseURL) {
-		log.Printf("flex-ipfs autostart skipped (non-local base url): %s", baseURL)
-		return nil, nil
+		if strings.TrimSpace(sshTarget) == "" {
+			log.Printf("flex-ipfs autostart skipped (non-local base url): %s", baseURL)
+			return nil, nil
+		}
+		return maybeStartFlexIPFSRemote(ctx, baseURL, baseDirOverride, gwEndpointOverride, logDir, killStep, sshTarget, sshKeyPath, coord)
 	}
 
 	// If a local Flexible-IPFS is already running on the target base URL,
@@ -50,12 +160,9 @@ func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpoin
 				log.Printf("flex-ipfs bootstrap config sync failed: %v", err)
 			}
 		}
-		// Best-effort: if we have a gw endpoint configured (or present in kadrtt.properties),
+		// Best-effort: if we have gw endpoint(s) configured (or present in kadrtt.properties),
 		// proactively connect so peerlist isn't empty for subsequent operations (e.g. Create Board).
-		if endpoint := resolveFlexIPFSConnectEndpoint(baseDirOverride, gwEndpointOverride); endpoint != "" {
-			if err := flexIPFSSwarmConnect(ctx, baseURL, endpoint); err != nil {
-				log.Printf("flex-ipfs swarm/connect failed: %v", err)
-			}
+		if endpoints := connectFlexIPFSEndpoints(ctx, baseURL, baseDirOverride, gwEndpointOverride); len(endpoints) > 0 {
 			waitForFlexIPFSPeers(ctx, baseURL, 5*time.Second)
 		}
 		return nil, nil
@@ -93,23 +200,20 @@ func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpoin
 			if err := syncFlexIPFSBootstrapConfig(flexBaseDir, gwEndpointOverride); err != nil {
 				log.Printf("flex-ipfs bootstrap config sync failed: %v", err)
 			}
-			// Best-effort: explicit connect to a configured gw endpoint (if any).
-			if endpoint := resolveFlexIPFSConnectEndpoint(flexBaseDir, gwEndpointOverride); endpoint != "" {
-				if err := flexIPFSSwarmConnect(ctx, baseURL, endpoint); err != nil {
-					log.Printf("flex-ipfs swarm/connect failed: %v", err)
-				}
+			// Best-effort: explicit connect to configured gw endpoint(s) (if any).
+			if endpoints := connectFlexIPFSEndpoints(ctx, baseURL, flexBaseDir, gwEndpointOverride); len(endpoints) > 0 {
 				waitForFlexIPFSPeers(ctx, baseURL, 5*time.Second)
 			}
 			return nil, nil
 		}
 
-		release, ok, err := tryAcquireFlexIPFSStartLock(lockPath)
+		release, ok, err := tryAcquireFlexIPFSStartLock(lockPath, flexBaseDir)
 		if err != nil {
 			return nil, err
 		}
 		if ok {
 			// We are the starter.
-			proc, err := startFlexIPFS(javaBin, flexBaseDir, gwEndpointOverride, logDir)
+			proc, err := startFlexIPFS(javaBin, flexBaseDir, gwEndpointOverride, logDir, baseURL, killStep, "", "")
 			if err != nil {
 				release()
 				return nil, err
@@ -124,21 +228,55 @@ func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpoin
 				return nil, fmt.Errorf("flex-ipfs API not ready after 60s (is another flex-ipfs/java process holding .ipfs/datastore?)")
 			}
 			release()
-			// Best-effort explicit connect to a configured gw endpoint (if any).
-			if endpoint := resolveFlexIPFSConnectEndpoint(flexBaseDir, gwEndpointOverride); endpoint != "" {
-				if err := flexIPFSSwarmConnect(ctx, baseURL, endpoint); err != nil {
-					log.Printf("flex-ipfs swarm/connect failed: %v", err)
-				}
-			}
+			setCurrentFlexIPFSProc(proc)
+			// Best-effort: start probing configured gw endpoint(s) in the background.
+			connectFlexIPFSEndpoints(ctx, baseURL, flexBaseDir, gwEndpointOverride)
 			// Best-effort wait for bootstrap to populate peers (prevents early put failures).
 			waitForFlexIPFSPeers(ctx, baseURL, 5*time.Second)
+			if coord != nil {
+				coord.register("flex-ipfs", func(context.Context) error {
+					proc.stop()
+					return nil
+				})
+				// Belt-and-suspenders: release() above already removes lockPath
+				// on the normal path, but a coordinator-driven shutdown running
+				// this closer should still clean it up if that somehow didn't
+				// happen (e.g. a future code path returns before release()).
+				coord.register("flex-ipfs-start-lock", func(context.Context) error {
+					_ = os.Remove(lockPath)
+					return nil
+				})
+			}
 			return proc, nil
 		}
 
-		// Another process is starting flex-ipfs; wait for it to come up.
+		// Another process is starting flex-ipfs; wait for it to come up,
+		// but don't wait out the full timeout for a starter that's
+		// obviously gone.
 		if st, statErr := os.Stat(lockPath); statErr == nil {
-			// Stale lock cleanup (e.g. a crashed starter).
-			if time.Since(st.ModTime()) > 5*time.Minute {
+			if tryReclaimStaleLock(lockPath) {
+				// We were able to take the advisory lock ourselves, so whoever
+				// held it crashed without cleaning up lockPath. (Always false
+				// on platforms where flockTryExclusive isn't implemented; the
+				// pid/age checks below cover those instead.)
+				log.Printf("flex-ipfs: reclaiming start lock %s (OS lock released by its owner)", lockPath)
+				_ = os.Remove(lockPath)
+				continue
+			}
+			if info, err := parseFlexIPFSLockInfo(lockPath); err == nil {
+				if info.PID > 0 && !processAlive(info.PID) {
+					log.Printf("flex-ipfs: reclaiming start lock %s (pid %d is gone)", lockPath, info.PID)
+					_ = os.Remove(lockPath)
+					continue
+				}
+				if !info.Started.IsZero() && time.Since(info.Started) > 5*time.Minute {
+					log.Printf("flex-ipfs: reclaiming start lock %s (held since %s)", lockPath, info.Started)
+					_ = os.Remove(lockPath)
+					continue
+				}
+			} else if time.Since(st.ModTime()) > 5*time.Minute {
+				// Unparseable lock file (e.g. left over from an older
+				// bbs-node build); fall back to the old flat timeout.
 				_ = os.Remove(lockPath)
 				continue
 			}
@@ -150,21 +288,259 @@ func maybeStartFlexIPFS(ctx context.Context, baseURL, baseDirOverride, gwEndpoin
 	}
 }
 
+// stop requests an intentional shutdown: it tells p's run loop not to
+// respawn, then kills the current child process (if any) the same way the
+// pre-supervisor stop() always did, and blocks until run has exited. Safe to
+// call more than once.
 func (p *flexIPFSProc) stop() {
-	if p == nil || p.cmd == nil || p.cmd.Process == nil {
+	if p == nil {
 		return
 	}
-	_ = p.stdinWriter.Close()
-	// Try graceful interrupt first (no-op on Windows), then kill.
-	_ = p.cmd.Process.Signal(os.Interrupt)
-	time.Sleep(2 * time.Second)
-	_ = p.cmd.Process.Kill()
-	if p.logFile != nil {
-		_ = p.logFile.Close()
-		p.logFile = nil
+	p.mu.Lock()
+	alreadyStopping := false
+	select {
+	case <-p.stopCh:
+		alreadyStopping = true
+	default:
+		close(p.stopCh)
+	}
+	cmd := p.cmd
+	stdinWriter := p.stdinWriter
+	exited := p.exited
+	killStep := p.killStep
+	p.mu.Unlock()
+	if alreadyStopping {
+		<-p.doneCh
+		return
+	}
+
+	if stdinWriter != nil {
+		_ = stdinWriter.Close()
+	}
+	if cmd != nil && cmd.Process != nil && exited != nil {
+		escalateShutdown(cmd, exited, killStep)
+	}
+	<-p.doneCh
+}
+
+// escalateShutdown asks cmd to exit with increasingly forceful signals,
+// giving it up to step after each one to exit on its own (confirmed via
+// exited, which the caller's monitor loop closes once cmd.Wait() returns)
+// before moving on, rather than guessing how long a graceful shutdown takes
+// with a single blind sleep.
+func escalateShutdown(cmd *exec.Cmd, exited <-chan struct{}, step time.Duration) {
+	if step <= 0 {
+		step = 10 * time.Second
+	}
+	signals := []os.Signal{os.Interrupt}
+	if runtime.GOOS != "windows" {
+		signals = append(signals, syscall.SIGTERM)
+	}
+	for _, sig := range signals {
+		_ = cmd.Process.Signal(sig)
+		select {
+		case <-exited:
+			return
+		case <-time.After(step):
+		}
+	}
+	_ = cmd.Process.Kill()
+	select {
+	case <-exited:
+	case <-time.After(step):
+	}
+}
+
+// isStopped reports whether stop() has been called, the signal onFailure and
+// run check to decide whether an exit was intentional.
+func (p *flexIPFSProc) isStopped() bool {
+	select {
+	case <-p.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *flexIPFSProc) setState(s flexIPFSProcState) {
+	p.mu.Lock()
+	p.state = s
+	p.mu.Unlock()
+}
+
+// closeProcFiles closes p's current stdin pipe and log file handle, clearing
+// them so a later status()/stop() doesn't reference a process that's already
+// gone. Called once a child has exited, win or lose.
+func (p *flexIPFSProc) closeProcFiles() {
+	p.mu.Lock()
+	stdinWriter := p.stdinWriter
+	logCloser := p.logCloser
+	p.stdinWriter = nil
+	p.logCloser = nil
+	p.mu.Unlock()
+	if stdinWriter != nil {
+		_ = stdinWriter.Close()
+	}
+	if logCloser != nil {
+		_ = logCloser.Close()
 	}
 }
 
+// launch starts a fresh flex-ipfs child process and records it as p's
+// current one, for every supervised restart (the very first launch is done
+// by startFlexIPFS itself, before run's loop begins).
+func (p *flexIPFSProc) launch() (*exec.Cmd, error) {
+	cmd, stdinW, logCloser, err := launchFlexIPFSProcessFor(p.javaBin, p.flexBaseDir, p.gwEndpointOverride, p.logDir, p.sshTarget, p.sshKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("flex-ipfs started pid=%d baseDir=%s java=%s", cmd.Process.Pid, p.flexBaseDir, p.javaBin)
+	p.mu.Lock()
+	p.cmd = cmd
+	p.stdinWriter = stdinW
+	p.logCloser = logCloser
+	p.exited = make(chan struct{})
+	p.state = flexIPFSStateRunning
+	p.startedAt = time.Now()
+	p.mu.Unlock()
+	return cmd, nil
+}
+
+// run is p's supervisor loop. first is the process startFlexIPFS already
+// launched; every exit after that is handled by onFailure, which sleeps an
+// exponential, jittered backoff before run relaunches via launch, unless
+// stop() was called or too many exits have happened too quickly.
+func (p *flexIPFSProc) run(first *exec.Cmd) {
+	defer close(p.doneCh)
+	cmd := first
+	for {
+		if cmd == nil {
+			var err error
+			cmd, err = p.launch()
+			if err != nil {
+				log.Printf("flex-ipfs relaunch failed: %v", err)
+				if !p.onFailure(err) {
+					return
+				}
+				continue
+			}
+		}
+
+		exitErr := p.monitor(cmd)
+		cmd = nil
+
+		if p.isStopped() {
+			p.setState(flexIPFSStateStopped)
+			return
+		}
+		if exitErr != nil {
+			log.Printf("flex-ipfs exited: %v", exitErr)
+		} else {
+			log.Printf("flex-ipfs exited")
+		}
+		if !p.onFailure(exitErr) {
+			return
+		}
+	}
+}
+
+// monitor waits for cmd to exit, resetting p's backoff once flex-ipfs has
+// reported itself healthy (via waitForFlexIPFS) and then stayed up for
+// flexIPFSHealthyGrace, so a crash after a long healthy run doesn't inherit
+// whatever slow backoff an earlier run of crashes left behind.
+func (p *flexIPFSProc) monitor(cmd *exec.Cmd) error {
+	p.mu.Lock()
+	exited := p.exited
+	p.mu.Unlock()
+
+	exitCh := make(chan error, 1)
+	go func() { exitCh <- cmd.Wait() }()
+
+	healthyCh := make(chan struct{})
+	go func() {
+		if waitForFlexIPFS(context.Background(), p.baseURL, 60*time.Second) {
+			close(healthyCh)
+		}
+	}()
+
+	grace := time.NewTimer(flexIPFSHealthyGrace)
+	grace.Stop()
+
+	for {
+		select {
+		case err := <-exitCh:
+			grace.Stop()
+			p.closeProcFiles()
+			close(exited)
+			return err
+		case <-healthyCh:
+			grace.Reset(flexIPFSHealthyGrace)
+		case <-grace.C:
+			p.resetBackoff()
+		}
+	}
+}
+
+func (p *flexIPFSProc) resetBackoff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.backoff = 0
+	p.failures = nil
+}
+
+// onFailure records an exit (or relaunch failure) and reports whether run
+// should keep retrying. It returns false either because stop() fired while
+// we were sleeping the backoff, or because flexIPFSMaxFailures exits have now
+// happened within flexIPFSFailureWindow and the supervisor is giving up.
+func (p *flexIPFSProc) onFailure(err error) bool {
+	now := time.Now()
+	p.mu.Lock()
+	p.lastExitErr = err
+	p.restartCount++
+	p.failures = appendWithinWindow(p.failures, now, flexIPFSFailureWindow)
+	tooMany := len(p.failures) >= flexIPFSMaxFailures
+	if tooMany {
+		p.state = flexIPFSStateFailed
+	} else {
+		p.state = flexIPFSStateBackingOff
+	}
+	prevSleep := p.backoff
+	p.mu.Unlock()
+
+	if tooMany {
+		log.Printf("flex-ipfs: giving up after %d exits within %s (last error: %v)", flexIPFSMaxFailures, flexIPFSFailureWindow, err)
+		return false
+	}
+
+	sleep := decorrelatedJitterSleep(&retryPolicy{initial: flexIPFSInitialBackoff, max: flexIPFSMaxBackoff}, prevSleep)
+	p.mu.Lock()
+	p.backoff = sleep
+	p.mu.Unlock()
+	log.Printf("flex-ipfs: restarting in %s (restart #%d)", sleep.Round(100*time.Millisecond), p.restartCount)
+
+	select {
+	case <-time.After(sleep):
+		return true
+	case <-p.stopCh:
+		return false
+	}
+}
+
+// appendWithinWindow appends t to times and drops any entries older than
+// window, so a long healthy uptime punctuated by one crash doesn't count
+// toward flexIPFSMaxFailures alongside a true crash loop.
+func appendWithinWindow(times []time.Time, t time.Time, window time.Duration) []time.Time {
+	times = append(times, t)
+	cutoff := t.Add(-window)
+	kept := times[:0]
+	for _, x := range times {
+		if x.After(cutoff) {
+			kept = append(kept, x)
+		}
+	}
+	return kept
+}
+
 func isLocalBaseURL(baseURL string) bool {
 	u, err := url.Parse(baseURL)
 	if err != nil {
@@ -242,12 +618,67 @@ func findJavaBin(runtimeDir string) (string, error) {
 	return exec.LookPath("java")
 }
 
-func startFlexIPFS(javaBin, flexBaseDir, gwEndpointOverride, logDir string) (*flexIPFSProc, error) {
-	if err := os.MkdirAll(filepath.Join(flexBaseDir, "providers"), 0o755); err != nil {
+// startFlexIPFS launches flex-ipfs once and hands the running process off to
+// a supervisor goroutine (flexIPFSProc.run) that reinvokes
+// launchFlexIPFSProcessFor on any later unexpected exit. baseURL is used by
+// the supervisor to confirm the child has come up healthy between restarts
+// (see flexIPFSHealthyGrace); the caller still does its own waitForFlexIPFS
+// check against the same baseURL for this initial start, same as before.
+// killStep bounds stop()'s Interrupt/SIGTERM escalation steps; killStep <= 0
+// falls back to a 10s default. sshTarget/sshKeyPath are non-empty when
+// flex-ipfs is being managed on a remote host over ssh instead of as a local
+// child process (see maybeStartFlexIPFSRemote); javaBin is ignored in that
+// case.
+func startFlexIPFS(javaBin, flexBaseDir, gwEndpointOverride, logDir, baseURL string, killStep time.Duration, sshTarget, sshKeyPath string) (*flexIPFSProc, error) {
+	cmd, stdinW, logCloser, err := launchFlexIPFSProcessFor(javaBin, flexBaseDir, gwEndpointOverride, logDir, sshTarget, sshKeyPath)
+	if err != nil {
 		return nil, err
 	}
+	log.Printf("flex-ipfs started pid=%d baseDir=%s java=%s", cmd.Process.Pid, flexBaseDir, javaBin)
+
+	p := &flexIPFSProc{
+		cmd:                cmd,
+		stdinWriter:        stdinW,
+		logCloser:          logCloser,
+		exited:             make(chan struct{}),
+		javaBin:            javaBin,
+		flexBaseDir:        flexBaseDir,
+		gwEndpointOverride: gwEndpointOverride,
+		logDir:             logDir,
+		baseURL:            baseURL,
+		sshTarget:          sshTarget,
+		sshKeyPath:         sshKeyPath,
+		killStep:           killStep,
+		stopCh:             make(chan struct{}),
+		doneCh:             make(chan struct{}),
+		state:              flexIPFSStateRunning,
+		startedAt:          time.Now(),
+	}
+	go p.run(cmd)
+	return p, nil
+}
+
+// launchFlexIPFSProcessFor dispatches to launchFlexIPFSProcess for a local
+// javaBin, or to launchFlexIPFSProcessRemote when sshTarget is set. Shared by
+// startFlexIPFS's initial launch and flexIPFSProc.launch's restarts, so
+// there is exactly one place that decides which of the two a given
+// flexIPFSProc uses.
+func launchFlexIPFSProcessFor(javaBin, flexBaseDir, gwEndpointOverride, logDir, sshTarget, sshKeyPath string) (cmd *exec.Cmd, stdinWriter io.Closer, logCloser io.Closer, err error) {
+	if strings.TrimSpace(sshTarget) != "" {
+		return launchFlexIPFSProcessRemote(sshTarget, sshKeyPath, flexBaseDir, gwEndpointOverride, logDir)
+	}
+	return launchFlexIPFSProcess(javaBin, flexBaseDir, gwEndpointOverride, logDir)
+}
+
+// launchFlexIPFSProcess execs the Java APIServer once, without tracking or
+// supervising it — launchFlexIPFSProcessFor uses it for every local launch
+// and restart.
+func launchFlexIPFSProcess(javaBin, flexBaseDir, gwEndpointOverride, logDir string) (cmd *exec.Cmd, stdinWriter io.Closer, logCloser io.Closer, err error) {
+	if err := os.MkdirAll(filepath.Join(flexBaseDir, "providers"), 0o755); err != nil {
+		return nil, nil, nil, err
+	}
 	if err := os.MkdirAll(filepath.Join(flexBaseDir, "getdata"), 0o755); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	attrPath := filepath.Join(flexBaseDir, "attr")
 	if _, err := os.Stat(attrPath); os.IsNotExist(err) {
@@ -255,77 +686,85 @@ func startFlexIPFS(javaBin, flexBaseDir, gwEndpointOverride, logDir string) (*fl
 	}
 
 	if err := maybeOverrideKadrttGWEndpoint(flexBaseDir, gwEndpointOverride); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	if err := ensureKadrttGlobalIP(flexBaseDir, gwEndpointOverride); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	if err := syncFlexIPFSBootstrapConfig(flexBaseDir, gwEndpointOverride); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	// Keep stdin open to avoid APIServer exiting on EOF.
 	stdinR, stdinW := io.Pipe()
 
-	cmd := exec.Command(javaBin, "-cp", "lib/*", "org.peergos.APIServer")
-	cmd.Dir = flexBaseDir
-	cmd.Env = append(os.Environ(),
+	c := exec.Command(javaBin, "-cp", "lib/*", "org.peergos.APIServer")
+	c.Dir = flexBaseDir
+	c.Env = append(os.Environ(),
 		"HOME="+flexBaseDir,
 		"IPFS_HOME="+filepath.Join(flexBaseDir, ".ipfs"),
 	)
-	cmd.Stdin = stdinR
-	var logFile *os.File
-	logPath := filepath.Join(flexBaseDir, "flex-ipfs.log")
+	c.Stdin = stdinR
+	rot := attachFlexIPFSLogOutput(c, flexBaseDir, logDir)
+
+	if err := c.Start(); err != nil {
+		_ = stdinW.Close()
+		if rot != nil {
+			_ = rot.Close()
+		}
+		return nil, nil, nil, err
+	}
+
+	if rot == nil {
+		return c, stdinW, nil, nil
+	}
+	return c, stdinW, rot, nil
+}
+
+// attachFlexIPFSLogOutput wires c's Stdout/Stderr to flex-ipfs.log (rotated
+// via newRotatingFileWriter, under logDir if set or else defaultDir) and
+// flexIPFSLogRing, plus the bbs-node process's own stdout/stderr when that
+// looks like an attached terminal. Shared by launchFlexIPFSProcess and
+// launchFlexIPFSProcessRemote so the log-sink wiring isn't duplicated
+// between the local and ssh-remote launch paths.
+func attachFlexIPFSLogOutput(c *exec.Cmd, defaultDir, logDir string) *rotatingFileWriter {
+	logPath := filepath.Join(defaultDir, "flex-ipfs.log")
 	if strings.TrimSpace(logDir) != "" {
 		logPath = filepath.Join(logDir, "flex-ipfs.log")
 	}
 	_ = os.MkdirAll(filepath.Dir(logPath), 0o755)
-	if f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err == nil {
-		logFile = f
+	var rot *rotatingFileWriter
+	var fileWriter io.Writer
+	if rw, err := newRotatingFileWriter(logPath, flexIPFSLogMaxSize, flexIPFSLogMaxAge, flexIPFSLogBackups); err != nil {
+		log.Printf("flex-ipfs log: failed to open %s: %v", logPath, err)
+	} else {
+		rot = rw
+		fileWriter = io.MultiWriter(rw, flexIPFSLogRing)
 	}
 
 	if !isCharDevice(os.Stdout) || !isCharDevice(os.Stderr) {
 		// When bbs-node is run with stdout/stderr redirected (e.g., from the TUI),
 		// inheriting those pipes can keep the parent process' output streams open
 		// even after bbs-node exits, which can make callers appear to "hang".
-		// Log to a file instead in that case.
-		if logFile != nil {
-			cmd.Stdout = logFile
-			cmd.Stderr = logFile
+		// Log to the rotator (and ring buffer) instead in that case.
+		if fileWriter != nil {
+			c.Stdout = fileWriter
+			c.Stderr = fileWriter
 		} else {
-			cmd.Stdout = io.Discard
-			cmd.Stderr = io.Discard
+			c.Stdout = io.Discard
+			c.Stderr = io.Discard
 		}
 	} else {
-		if logFile != nil {
-			mw := io.MultiWriter(os.Stdout, logFile)
-			cmd.Stdout = mw
-			cmd.Stderr = mw
+		if fileWriter != nil {
+			mw := io.MultiWriter(os.Stdout, fileWriter)
+			c.Stdout = mw
+			c.Stderr = mw
 		} else {
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
 		}
 	}
-
-	if err := cmd.Start(); err != nil {
-		_ = stdinW.Close()
-		if logFile != nil {
-			_ = logFile.Close()
-		}
-		return nil, err
-	}
-
-	log.Printf("flex-ipfs started pid=%d baseDir=%s java=%s", cmd.Process.Pid, flexBaseDir, javaBin)
-
-	go func() {
-		if err := cmd.Wait(); err != nil {
-			log.Printf("flex-ipfs exited: %v", err)
-		} else {
-			log.Printf("flex-ipfs exited")
-		}
-	}()
-
-	return &flexIPFSProc{cmd: cmd, stdinWriter: stdinW, logFile: logFile}, nil
+	return rot
 }
 
 func isCharDevice(f *os.File) bool {
@@ -388,15 +827,48 @@ func detectLocalIP4() string {
 	return ""
 }
 
+// maybeOverrideKadrttGWEndpoint records endpoint (a single gw endpoint, or a
+// comma/newline-separated list — see splitFlexIPFSEndpoints) into
+// flexible-ipfs-base/kadrtt.properties: a single endpoint still goes into
+// the legacy ipfs.endpoint key (unchanged on-disk format for the common
+// case), while two or more go into ipfs.endpoints instead, read in
+// preference to ipfs.endpoint by readKadrttGWEndpoint.
 func maybeOverrideKadrttGWEndpoint(flexBaseDir, endpoint string) error {
-	endpoint = strings.TrimSpace(endpoint)
-	if endpoint == "" {
+	endpoints := splitFlexIPFSEndpoints(endpoint)
+	if len(endpoints) == 0 {
 		return nil
 	}
-	if strings.ContainsAny(endpoint, "\r\n") {
-		return fmt.Errorf("FLEXIPFS_GW_ENDPOINT must be a single line")
+	if len(endpoints) == 1 {
+		return rewriteKadrttProperty(flexBaseDir, "ipfs.endpoint", endpoints[0])
 	}
+	return rewriteKadrttProperty(flexBaseDir, "ipfs.endpoints", strings.Join(endpoints, ","))
+}
+
+// splitFlexIPFSEndpoints splits a comma/newline-separated endpoint list
+// (from -flexipfs-gw-endpoint, FLEXIPFS_GW_ENDPOINT, mDNS discovery, or a
+// kadrtt.properties ipfs.endpoints/ipfs.endpoint value) into trimmed,
+// deduped, order-preserving entries.
+func splitFlexIPFSEndpoints(s string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	}) {
+		v := strings.TrimSpace(field)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
 
+// rewriteKadrttProperty sets key=value in flexible-ipfs-base/kadrtt.properties,
+// replacing an existing uncommented `key ...` line in place (preserving its
+// indentation and `:`/`=` separator) or appending a new `key=value` line
+// otherwise. Comment lines (# or !) are left untouched.
+func rewriteKadrttProperty(flexBaseDir, key, value string) error {
 	propsPath := filepath.Join(flexBaseDir, "kadrtt.properties")
 	b, err := os.ReadFile(propsPath)
 	if err != nil {
@@ -409,13 +881,13 @@ func maybeOverrideKadrttGWEndpoint(flexBaseDir, endpoint string) error {
 		lineSep = "\r\n"
 	}
 
-	reEndpoint := regexp.MustCompile(`^(\s*)ipfs\.endpoint(\s*[:=]).*$`)
+	reKey := regexp.MustCompile(`^(\s*)` + regexp.QuoteMeta(key) + `(\s*[:=]).*$`)
 	parts := strings.SplitAfter(original, lineSep)
 
 	var out strings.Builder
-	out.Grow(len(original) + len(endpoint) + 64)
+	out.Grow(len(original) + len(value) + 64)
 
-	replacedEndpoint := false
+	replaced := false
 	for _, part := range parts {
 		if part == "" {
 			continue
@@ -434,13 +906,13 @@ func maybeOverrideKadrttGWEndpoint(flexBaseDir, endpoint string) error {
 			continue
 		}
 
-		if m := reEndpoint.FindStringSubmatch(line); m != nil {
+		if m := reKey.FindStringSubmatch(line); m != nil {
 			out.WriteString(m[1])
-			out.WriteString("ipfs.endpoint")
+			out.WriteString(key)
 			out.WriteString(m[2])
-			out.WriteString(endpoint)
+			out.WriteString(value)
 			out.WriteString(suffix)
-			replacedEndpoint = true
+			replaced = true
 			continue
 		}
 
@@ -448,12 +920,13 @@ func maybeOverrideKadrttGWEndpoint(flexBaseDir, endpoint string) error {
 		out.WriteString(suffix)
 	}
 
-	if !replacedEndpoint {
+	if !replaced {
 		if !strings.HasSuffix(out.String(), lineSep) && out.Len() > 0 {
 			out.WriteString(lineSep)
 		}
-		out.WriteString("ipfs.endpoint=")
-		out.WriteString(endpoint)
+		out.WriteString(key)
+		out.WriteString("=")
+		out.WriteString(value)
 		out.WriteString(lineSep)
 	}
 
@@ -465,7 +938,7 @@ func maybeOverrideKadrttGWEndpoint(flexBaseDir, endpoint string) error {
 	if err := os.WriteFile(propsPath, []byte(out.String()), mode); err != nil {
 		return err
 	}
-	log.Printf("flex-ipfs: set ipfs.endpoint=%s (%s)", endpoint, propsPath)
+	log.Printf("flex-ipfs: set %s=%s (%s)", key, value, propsPath)
 	return nil
 }
 
@@ -576,9 +1049,13 @@ func ensureKadrttGlobalIP(flexBaseDir, gwEndpointOverride string) error {
 	return nil
 }
 
+// syncFlexIPFSBootstrapConfig writes the resolved gw endpoint(s) into
+// .ipfs/config's Bootstrap array as a deduped, order-preserving union with
+// whatever's already there (the configured endpoints first, so a fresh
+// datastore bootstraps off them before any stale entries).
 func syncFlexIPFSBootstrapConfig(flexBaseDir, gwEndpointOverride string) error {
-	desired := strings.TrimSpace(resolveFlexIPFSConnectEndpoint(flexBaseDir, gwEndpointOverride))
-	if desired == "" {
+	desired := resolveFlexIPFSConnectEndpoints(flexBaseDir, gwEndpointOverride)
+	if len(desired) == 0 {
 		return nil
 	}
 
@@ -614,24 +1091,36 @@ func syncFlexIPFSBootstrapConfig(flexBaseDir, gwEndpointOverride string) error {
 		}
 	}
 
+	already := make(map[string]bool, len(bootstrap))
 	for _, s := range bootstrap {
-		if s == desired {
-			return nil
+		already[s] = true
+	}
+	var added []string
+	for _, d := range desired {
+		if !already[d] {
+			added = append(added, d)
 		}
 	}
+	if len(added) == 0 {
+		return nil
+	}
 
-	var updated []string
-	switch len(bootstrap) {
-	case 0:
-		updated = []string{desired}
-	case 1:
-		// The most common case: a stale single bootstrap from the bundled kadrtt.properties.
-		updated = []string{desired}
-	default:
-		updated = append([]string{desired}, bootstrap...)
+	// Configured endpoints first (so a fresh datastore bootstraps off them
+	// before any stale entries), any previously-configured single endpoint
+	// dropped from desired is still kept since bootstrap is appended, not
+	// replaced.
+	updated := append(append([]string{}, desired...), bootstrap...)
+	dedup := make(map[string]bool, len(updated))
+	final := updated[:0]
+	for _, s := range updated {
+		if dedup[s] {
+			continue
+		}
+		dedup[s] = true
+		final = append(final, s)
 	}
 
-	cfg["Bootstrap"] = updated
+	cfg["Bootstrap"] = final
 	out, err := json.MarshalIndent(cfg, "", "\t")
 	if err != nil {
 		return fmt.Errorf("marshal %s: %w", configPath, err)
@@ -647,21 +1136,119 @@ func syncFlexIPFSBootstrapConfig(flexBaseDir, gwEndpointOverride string) error {
 		return err
 	}
 
-	log.Printf("flex-ipfs: updated .ipfs/config bootstrap (added %s)", desired)
+	log.Printf("flex-ipfs: updated .ipfs/config bootstrap (added %s)", strings.Join(added, ","))
 	return nil
 }
 
-func tryAcquireFlexIPFSStartLock(lockPath string) (release func(), acquired bool, err error) {
+// tryAcquireFlexIPFSStartLock creates lockPath exclusively and records who's
+// holding it (pid, start time, the flex-ipfs base dir being started, and
+// this binary's path) so an operator debugging a stuck cluster can `cat` it
+// and see which peer owns it, then takes an OS-level advisory lock on top
+// (see flockTryExclusive) so a crash releases the lock even if the file
+// itself is left behind.
+func tryAcquireFlexIPFSStartLock(lockPath, flexBaseDir string) (release func(), acquired bool, err error) {
 	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
-	if err == nil {
-		_, _ = fmt.Fprintf(f, "pid=%d\nstarted=%s\n", os.Getpid(), time.Now().Format(time.RFC3339Nano))
-		_ = f.Close()
-		return func() { _ = os.Remove(lockPath) }, true, nil
+	if err != nil {
+		if os.IsExist(err) {
+			return func() {}, false, nil
+		}
+		return func() {}, false, err
 	}
-	if os.IsExist(err) {
+
+	if lockErr := flockTryExclusive(f); lockErr != nil {
+		_ = f.Close()
 		return func() {}, false, nil
 	}
-	return func() {}, false, err
+
+	exe, _ := os.Executable()
+	_, _ = fmt.Fprintf(f, "pid=%d\nstarted=%s\nbase_dir=%s\nbinary=%s\n", os.Getpid(), time.Now().Format(time.RFC3339Nano), flexBaseDir, exe)
+	_ = f.Sync()
+
+	return func() {
+		_ = flockUnlock(f)
+		_ = f.Close()
+		_ = os.Remove(lockPath)
+	}, true, nil
+}
+
+// flexIPFSLockInfo is what tryAcquireFlexIPFSStartLock records in the lock
+// file, parsed back out by parseFlexIPFSLockInfo so the waiting branch of
+// maybeStartFlexIPFS can check liveness instead of trusting a flat timeout.
+type flexIPFSLockInfo struct {
+	PID     int
+	Started time.Time
+	BaseDir string
+	Binary  string
+}
+
+func parseFlexIPFSLockInfo(lockPath string) (flexIPFSLockInfo, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return flexIPFSLockInfo{}, err
+	}
+	var info flexIPFSLockInfo
+	for _, line := range strings.Split(string(data), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "pid":
+			info.PID, _ = strconv.Atoi(v)
+		case "started":
+			info.Started, _ = time.Parse(time.RFC3339Nano, v)
+		case "base_dir":
+			info.BaseDir = v
+		case "binary":
+			info.Binary = v
+		}
+	}
+	return info, nil
+}
+
+// tryReclaimStaleLock opens an existing lock file (without O_EXCL, since the
+// file already exists) and attempts a non-blocking OS-level lock on it.
+// Success means the process that created it is gone: flock/LockFileEx locks
+// are released by the kernel when the owning process dies, even on
+// SIGKILL, regardless of whether it got a chance to remove the file itself.
+// Always reports false where flockTryExclusive isn't supported (see the
+// Windows build of this function), leaving staleness entirely to the
+// pid/start-time checks in the caller on that platform.
+func tryReclaimStaleLock(lockPath string) bool {
+	f, err := os.OpenFile(lockPath, os.O_RDWR, 0o644)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if err := flockTryExclusive(f); err != nil {
+		return false
+	}
+	_ = flockUnlock(f)
+	return true
+}
+
+// processAlive reports whether pid appears to still be running. On Windows,
+// os.FindProcess itself opens a handle via OpenProcess and fails if the
+// process doesn't exist, so a lookup error alone is conclusive. Elsewhere,
+// os.FindProcess always succeeds regardless of whether pid exists, so the
+// standard probe is sending it the null signal.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = proc.Signal(syscall.Signal(0))
+	if err == nil {
+		return true
+	}
+	// Exists but we can't signal it (e.g. owned by another user): still alive.
+	return errors.Is(err, syscall.EPERM)
 }
 
 func resolveFlexIPFSConnectEndpoint(baseDirOrOverride string, gwEndpointOverride string) string {
@@ -686,27 +1273,49 @@ func resolveFlexIPFSConnectEndpoint(baseDirOrOverride string, gwEndpointOverride
 	return ""
 }
 
+// resolveFlexIPFSConnectEndpoints is resolveFlexIPFSConnectEndpoint split
+// into the individual gw endpoints (see splitFlexIPFSEndpoints) it may
+// represent.
+func resolveFlexIPFSConnectEndpoints(baseDirOrOverride, gwEndpointOverride string) []string {
+	return splitFlexIPFSEndpoints(resolveFlexIPFSConnectEndpoint(baseDirOrOverride, gwEndpointOverride))
+}
+
+// readKadrttGWEndpoint reads the configured gw endpoint(s) out of
+// kadrtt.properties, preferring the newer ipfs.endpoints key (a
+// comma/newline-separated list, see splitFlexIPFSEndpoints) over the legacy
+// single-valued ipfs.endpoint when both are present.
 func readKadrttGWEndpoint(flexBaseDir string) (string, error) {
 	propsPath := filepath.Join(flexBaseDir, "kadrtt.properties")
 	b, err := os.ReadFile(propsPath)
 	if err != nil {
 		return "", err
 	}
+	var endpoint, endpoints string
 	for _, raw := range strings.Split(string(b), "\n") {
 		line := strings.TrimSpace(strings.TrimSuffix(raw, "\r"))
 		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
 			continue
 		}
-		if !strings.HasPrefix(line, "ipfs.endpoint") {
+		idx := strings.IndexAny(line, "=:")
+		if idx < 0 {
 			continue
 		}
-		if idx := strings.IndexAny(line, "=:"); idx >= 0 {
-			if v := strings.TrimSpace(line[idx+1:]); v != "" {
-				return v, nil
-			}
+		key := strings.TrimSpace(line[:idx])
+		v := strings.TrimSpace(line[idx+1:])
+		if v == "" {
+			continue
 		}
+		switch key {
+		case "ipfs.endpoints":
+			endpoints = v
+		case "ipfs.endpoint":
+			endpoint = v
+		}
+	}
+	if endpoints != "" {
+		return endpoints, nil
 	}
-	return "", nil
+	return endpoint, nil
 }
 
 func waitForFlexIPFS(ctx context.Context, baseURL string, timeout time.Duration) bool {
@@ -770,6 +1379,21 @@ func flexIPFSSwarmConnect(ctx context.Context, baseURL, addr string) error {
 	return fmt.Errorf("flex-ipfs swarm/connect http %d: %s", resp.StatusCode, msg)
 }
 
+// connectFlexIPFSEndpoints resolves the configured gw endpoint(s) (override
+// flag, or kadrtt.properties ipfs.endpoints/ipfs.endpoint) and, if any are
+// set, starts a background prober that calls /swarm/connect against each
+// and tracks its health (see startFlexIPFSEndpointProber, flexIPFSHealth in
+// flexipfs_status.go). Returns the resolved endpoints so callers can decide
+// whether waitForFlexIPFSPeers is worth calling at all.
+func connectFlexIPFSEndpoints(ctx context.Context, baseURL, baseDirOverride, gwEndpointOverride string) []string {
+	endpoints := resolveFlexIPFSConnectEndpoints(baseDirOverride, gwEndpointOverride)
+	if len(endpoints) == 0 {
+		return nil
+	}
+	startFlexIPFSEndpointProber(ctx, baseURL, endpoints)
+	return endpoints
+}
+
 func waitForFlexIPFSPeers(ctx context.Context, baseURL string, timeout time.Duration) {
 	deadline := time.Now().Add(timeout)
 	endpoint := strings.TrimRight(baseURL, "/") + "/dht/peerlist"
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"flex-bbs/backend-go/bbs/attachments"
+)
+
+// ErrCIDMismatch is returned by GetValue when key parses as a
+// CIDv1/raw/sha2-256 (see bbs/attachments.ParseCIDv1Raw) but the bytes
+// FlexIPFS actually returned don't hash to it — either the value was
+// corrupted in transit or the peer that answered is lying about what it
+// has stored under that key.
+var ErrCIDMismatch = errors.New("flexipfs: retrieved value does not match requested CID")
+
+// verifyCID checks value against key when key is a self-describing
+// CIDv1/raw/sha2-256 multihash, returning ErrCIDMismatch on a hash
+// mismatch. Keys that don't parse as that CID shape (an IPNS name, a
+// plain non-content-addressed DHT key) are left unchecked, since this
+// client stores both kinds of value under GetValue/PutValue.
+func verifyCID(key string, value []byte) error {
+	digest, err := attachments.ParseCIDv1Raw(key)
+	if err != nil {
+		return nil
+	}
+	if sha256.Sum256(value) != digest {
+		return ErrCIDMismatch
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"flex-bbs/backend-go/bbs/attachments"
+)
+
+func TestVerifyCID_MatchesDigest(t *testing.T) {
+	value := []byte("hello world")
+	digest := sha256.Sum256(value)
+	cid := attachments.EncodeCIDv1Raw(digest)
+
+	if err := verifyCID(cid, value); err != nil {
+		t.Fatalf("verifyCID(matching digest) = %v, want nil", err)
+	}
+}
+
+func TestVerifyCID_DetectsMismatch(t *testing.T) {
+	digest := sha256.Sum256([]byte("hello world"))
+	cid := attachments.EncodeCIDv1Raw(digest)
+
+	if err := verifyCID(cid, []byte("something else entirely")); !errors.Is(err, ErrCIDMismatch) {
+		t.Fatalf("verifyCID(mismatched digest) = %v, want ErrCIDMismatch", err)
+	}
+}
+
+func TestVerifyCID_IgnoresNonCIDKeys(t *testing.T) {
+	if err := verifyCID("plain-dht-key", []byte("anything")); err != nil {
+		t.Fatalf("verifyCID(non-CID key) = %v, want nil (unchecked)", err)
+	}
+}
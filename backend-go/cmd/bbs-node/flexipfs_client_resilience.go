@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ========================================
+// リトライ / サーキットブレーカー
+// ========================================
+
+// ClientOption configures retry, timeout, and circuit-breaking behavior for
+// an httpFlexIPFSClient built via NewFlexIPFSClient /
+// NewFlexIPFSClientWithHTTPClient. The zero value (no options) preserves the
+// client's original behavior: a single attempt per call, no breaker.
+type ClientOption func(*clientResilience)
+
+type retryPolicy struct {
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	jitter      float64
+}
+
+type clientResilience struct {
+	retry         *retryPolicy
+	perOpTimeout  map[string]time.Duration
+	breakerConfig *breakerConfig
+	onRetry       func(op string, attempt int, err error)
+	onCircuitOpen func(op string)
+}
+
+type breakerConfig struct {
+	failureThreshold int
+	resetAfter       time.Duration
+}
+
+// WithRetryPolicy enables retries for idempotent reads (GetValue,
+// FindProviders, PeerList, GetIPNS) using decorrelated-jitter backoff
+// (https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/):
+// each sleep is a random duration in [initial, min(max, prevSleep*3)). jitter
+// is currently unused by that formula but accepted (and must be in [0,1]) so
+// callers can dial in a future linear-jitter variant without an API break.
+func WithRetryPolicy(maxAttempts int, initial, max time.Duration, jitter float64) ClientOption {
+	return func(r *clientResilience) {
+		r.retry = &retryPolicy{maxAttempts: maxAttempts, initial: initial, max: max, jitter: jitter}
+	}
+}
+
+// WithPerOpTimeout overrides the client's default HTTP timeout for a single
+// operation name (e.g. "GetValue", "PutValueWithAttr" — matching the Op
+// field FlexClientError reports).
+func WithPerOpTimeout(op string, d time.Duration) ClientOption {
+	return func(r *clientResilience) {
+		if r.perOpTimeout == nil {
+			r.perOpTimeout = make(map[string]time.Duration)
+		}
+		r.perOpTimeout[op] = d
+	}
+}
+
+// WithCircuitBreaker trips the breaker after failureThreshold consecutive
+// failures on a given op, rejecting further calls to that op until
+// resetAfter has elapsed, at which point a single trial call is allowed
+// through (closing the breaker again if it succeeds).
+func WithCircuitBreaker(failureThreshold int, resetAfter time.Duration) ClientOption {
+	return func(r *clientResilience) {
+		r.breakerConfig = &breakerConfig{failureThreshold: failureThreshold, resetAfter: resetAfter}
+	}
+}
+
+// WithOnRetry registers a hook invoked right before each retried attempt
+// (not the first), so callers can track a flapping Flexible-IPFS node.
+func WithOnRetry(fn func(op string, attempt int, err error)) ClientOption {
+	return func(r *clientResilience) { r.onRetry = fn }
+}
+
+// WithOnCircuitOpen registers a hook invoked the moment an op's circuit
+// breaker trips open.
+func WithOnCircuitOpen(fn func(op string)) ClientOption {
+	return func(r *clientResilience) { r.onCircuitOpen = fn }
+}
+
+// circuitBreaker is a simple per-op consecutive-failure breaker: closed ->
+// open after failureThreshold consecutive failures, open -> half-open after
+// resetAfter, half-open -> closed on the next success or back to open on
+// the next failure.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	failureThreshold int
+	resetAfter       time.Duration
+
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInFlight       bool
+}
+
+func newCircuitBreaker(cfg *breakerConfig) *circuitBreaker {
+	if cfg == nil {
+		return nil
+	}
+	return &circuitBreaker{failureThreshold: cfg.failureThreshold, resetAfter: cfg.resetAfter}
+}
+
+var errCircuitOpen = errors.New("circuit breaker open")
+
+// allow reports whether a call may proceed, and (if so) whether this call is
+// the trial call for a half-open breaker (only one trial is let through at a
+// time so concurrent callers don't all hammer a still-recovering backend).
+func (b *circuitBreaker) allow() (ok bool, isTrial bool) {
+	if b == nil {
+		return true, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.consecutiveFailures < b.failureThreshold {
+		return true, false
+	}
+	if time.Now().Before(b.openUntil) {
+		return false, false
+	}
+	if b.trialInFlight {
+		return false, false
+	}
+	b.trialInFlight = true
+	return true, true
+}
+
+func (b *circuitBreaker) recordSuccess(isTrial bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.trialInFlight = false
+}
+
+// recordFailure reports whether this failure is the one that just tripped
+// the breaker open (so the caller can fire OnCircuitOpen exactly once).
+func (b *circuitBreaker) recordFailure(isTrial bool) (justOpened bool) {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+	b.consecutiveFailures++
+	if b.consecutiveFailures == b.failureThreshold {
+		b.openUntil = time.Now().Add(b.resetAfter)
+		return true
+	}
+	if isTrial {
+		// The trial call failed again; keep the breaker open for another window.
+		b.openUntil = time.Now().Add(b.resetAfter)
+	}
+	return false
+}
+
+// decorrelatedJitterSleep returns the next sleep duration given the previous
+// one, per AWS's "decorrelated jitter" backoff: a random value between
+// initial and min(max, prev*3).
+func decorrelatedJitterSleep(p *retryPolicy, prev time.Duration) time.Duration {
+	if prev <= 0 {
+		prev = p.initial
+	}
+	upper := prev * 3
+	if upper > p.max {
+		upper = p.max
+	}
+	if upper <= p.initial {
+		return p.initial
+	}
+	return p.initial + time.Duration(rand.Int63n(int64(upper-p.initial)))
+}
+
+// isRetryableReadErr reports whether err (from a failed HTTP round trip, not
+// an HTTP-level error status) looks transient enough to retry an idempotent
+// read: connection resets, timeouts, DNS hiccups, etc.
+func isRetryableReadErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// idempotentReadRetryable decides retries for FindProviders/PeerList (GetValue
+// uses the more specific getValueRetryable): any transient transport error, a
+// breaker that's currently open, or a 502/503/504 is worth another attempt;
+// anything else (a parsed FlexErrorResponse, a 4xx, a decode error in a
+// response body we already fully received) is not.
+func idempotentReadRetryable(err error, status int) bool {
+	if errors.Is(err, errCircuitOpen) {
+		return true
+	}
+	if status == 0 {
+		return isRetryableReadErr(err)
+	}
+	return isRetryableWriteStatus(status)
+}
+
+// getValueRetryable is idempotentReadRetryable specialized for GetValue: it
+// additionally treats an empty/unparseable 400 (parseErrorResponse falling
+// back to *FlexClientError because the body wasn't a FlexErrorResponse) the
+// same as a dropped connection, since the two are indistinguishable from the
+// client's side, and treats a structured FlexErrorResponse reporting
+// "Unknown Multihash type" as a fallback worth one more attempt rather than
+// a fatal 4xx, since it's how a Flexible-IPFS node that hasn't finished
+// syncing the key's routing record reports "ask me again". Every other
+// structured FlexErrorResponse (key not found, bad request, etc.) is fatal.
+func getValueRetryable(err error, status int) bool {
+	if errors.Is(err, errCircuitOpen) || isRetryableReadErr(err) {
+		return true
+	}
+	if status == 0 {
+		return false
+	}
+	if isRetryableWriteStatus(status) {
+		return true
+	}
+	var flexErr *FlexErrorResponse
+	if errors.As(err, &flexErr) {
+		return strings.EqualFold(flexErr.Message, "Unknown Multihash type")
+	}
+	return status == http.StatusBadRequest
+}
+
+// isRetryableWriteStatus reports whether an HTTP status on a write
+// (PutValue/PutValueWithAttr) is safe to retry: only 502/503/504, which
+// indicate the request was rejected by an intermediary/overloaded backend
+// before being applied, as opposed to a 4xx (client error, won't improve on
+// retry) or 200..499 success/ambiguous outcome (retrying risks a double write).
+func isRetryableWriteStatus(status int) bool {
+	switch status {
+	case 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+// withRetry runs attempt in a loop, retrying per r.retry (if set, and only
+// when retryable permits a given failure) with decorrelated-jitter
+// backoff between attempts, and gating/recording calls through a per-op
+// circuit breaker if r.breakerConfig is set. attempt should return the HTTP
+// status observed (0 if the request never got a response) and an error.
+func withRetry(ctx context.Context, r *clientResilience, breakers map[string]*circuitBreaker, mu *sync.Mutex, op string, retryable func(err error, status int) bool, attempt func(ctx context.Context) (status int, err error)) (status int, attempts int, err error) {
+	var breaker *circuitBreaker
+	if r.breakerConfig != nil {
+		mu.Lock()
+		breaker = breakers[op]
+		if breaker == nil {
+			breaker = newCircuitBreaker(r.breakerConfig)
+			breakers[op] = breaker
+		}
+		mu.Unlock()
+	}
+
+	maxAttempts := 1
+	if r.retry != nil && r.retry.maxAttempts > 0 {
+		maxAttempts = r.retry.maxAttempts
+	}
+
+	var sleep time.Duration
+	for attempts = 1; ; attempts++ {
+		ok, isTrial := breaker.allow()
+		if !ok {
+			return 0, attempts, errCircuitOpen
+		}
+
+		status, err = attempt(ctx)
+		if err == nil {
+			breaker.recordSuccess(isTrial)
+			return status, attempts, nil
+		}
+
+		if breaker.recordFailure(isTrial) && r.onCircuitOpen != nil {
+			r.onCircuitOpen(op)
+		}
+
+		if attempts >= maxAttempts || !retryable(err, status) {
+			return status, attempts, err
+		}
+		if r.onRetry != nil {
+			r.onRetry(op, attempts, err)
+		}
+
+		if r.retry != nil {
+			sleep = decorrelatedJitterSleep(r.retry, sleep)
+		} else {
+			sleep = 0
+		}
+		select {
+		case <-time.After(sleep):
+		case <-ctx.Done():
+			return status, attempts, ctx.Err()
+		}
+	}
+}
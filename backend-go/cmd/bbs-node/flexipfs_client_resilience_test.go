@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/attachments"
+)
+
+func TestHTTPFlexIPFSClient_GetValue_RetriesOnServerError(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	var mu sync.Mutex
+	calls := 0
+	srv.ForceStatus = http.StatusServiceUnavailable
+
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client(),
+		WithRetryPolicy(4, time.Millisecond, 5*time.Millisecond, 0),
+		WithOnRetry(func(op string, attempt int, err error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		}),
+	)
+
+	// Flip to success after a couple of forced failures, from a separate
+	// goroutine so the client's own retry loop observes the recovery.
+	go func() {
+		time.Sleep(15 * time.Millisecond)
+		srv.mu.Lock()
+		srv.ForceStatus = 0
+		srv.mu.Unlock()
+	}()
+
+	_, err := client.GetValue(context.Background(), "missing-but-retried")
+	// Eventually the forced failure clears and the request resolves to a
+	// real (404, since nothing was stored) response rather than a transport
+	// error, proving the retry loop kept the request alive across failures.
+	if err == nil {
+		t.Fatal("expected a 404 FlexErrorResponse once ForceStatus clears")
+	}
+	if _, ok := err.(*FlexErrorResponse); !ok {
+		t.Fatalf("error type = %T, want *FlexErrorResponse (retries should have outlasted the forced 503s)", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls == 0 {
+		t.Fatal("expected OnRetry to fire at least once")
+	}
+}
+
+func TestHTTPFlexIPFSClient_PutValueWithAttr_DoesNotRetryOn4xx(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.ForceStatus = http.StatusBadRequest
+	srv.ForceBody = []byte(`{"message":"bad request","code":"ERR_BAD"}`)
+
+	attempts := 0
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client(),
+		WithRetryPolicy(5, time.Millisecond, 5*time.Millisecond, 0),
+		WithOnRetry(func(op string, attempt int, err error) { attempts++ }),
+	)
+
+	err := client.PutValueWithAttr(context.Background(), "k", []byte("v"), nil, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 0 {
+		t.Fatalf("expected no retries on 4xx, got %d", attempts)
+	}
+}
+
+func TestHTTPFlexIPFSClient_CircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.ForceStatus = http.StatusServiceUnavailable
+
+	var opened int
+	var mu sync.Mutex
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client(),
+		WithRetryPolicy(1, time.Millisecond, time.Millisecond, 0),
+		WithCircuitBreaker(2, time.Hour),
+		WithOnCircuitOpen(func(op string) {
+			mu.Lock()
+			opened++
+			mu.Unlock()
+		}),
+	)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetValue(ctx, "k"); err == nil {
+			t.Fatal("expected error from forced 503")
+		}
+	}
+
+	mu.Lock()
+	gotOpened := opened
+	mu.Unlock()
+	if gotOpened != 1 {
+		t.Fatalf("OnCircuitOpen fired %d times, want 1", gotOpened)
+	}
+
+	// The breaker should now reject without even hitting the network.
+	if _, err := client.GetValue(ctx, "k"); err == nil {
+		t.Fatal("expected circuit-open error")
+	} else if fe, ok := err.(*FlexClientError); !ok || fe.Err != errCircuitOpen {
+		t.Fatalf("err = %#v, want *FlexClientError wrapping errCircuitOpen", err)
+	}
+}
+
+func TestHTTPFlexIPFSClient_PerOpTimeout(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+	srv.Latency = 50 * time.Millisecond
+
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client(),
+		WithPerOpTimeout("GetValue", 5*time.Millisecond),
+	)
+
+	_, err := client.GetValue(context.Background(), "k")
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestGetValueRetryable(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		want   bool
+	}{
+		{"circuit open", errCircuitOpen, 0, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, 200, true},
+		{"empty 400", &FlexClientError{Op: "GetValue", Err: errors.New("HTTP 400: ")}, http.StatusBadRequest, true},
+		{"502", errors.New("bad gateway"), http.StatusBadGateway, true},
+		{"unknown multihash type", &FlexErrorResponse{Message: "Unknown Multihash type"}, http.StatusBadRequest, true},
+		{"structured not found", &FlexErrorResponse{Message: "key not found", Code: "NOT_FOUND"}, http.StatusNotFound, false},
+		{"structured bad request", &FlexErrorResponse{Message: "missing arg"}, http.StatusBadRequest, false},
+		{"CID mismatch", ErrCIDMismatch, http.StatusOK, false},
+	}
+	for _, c := range cases {
+		if got := getValueRetryable(c.err, c.status); got != c.want {
+			t.Errorf("%s: getValueRetryable() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHTTPFlexIPFSClient_GetValue_DetectsCIDMismatch(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	digest := sha256.Sum256([]byte("the real content"))
+	cid := attachments.EncodeCIDv1Raw(digest)
+	srv.mu.Lock()
+	srv.storage[cid] = &mockStorageEntry{Value: []byte("a lying peer's substituted content")}
+	srv.mu.Unlock()
+
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client())
+	_, err := client.GetValue(context.Background(), cid)
+	if !errors.Is(err, ErrCIDMismatch) {
+		t.Fatalf("GetValue() err = %v, want ErrCIDMismatch", err)
+	}
+}
+
+func TestHTTPFlexIPFSClient_GetValue_CIDMatchSucceeds(t *testing.T) {
+	srv := NewFakeFlexIPFSServer()
+	defer srv.Close()
+
+	value := []byte("the real content")
+	digest := sha256.Sum256(value)
+	cid := attachments.EncodeCIDv1Raw(digest)
+	srv.mu.Lock()
+	srv.storage[cid] = &mockStorageEntry{Value: value}
+	srv.mu.Unlock()
+
+	client := NewFlexIPFSClientWithHTTPClient(srv.URL(), srv.srv.Client())
+	resp, err := client.GetValue(context.Background(), cid)
+	if err != nil {
+		t.Fatalf("GetValue(): %v", err)
+	}
+	if string(resp.Value) != string(value) {
+		t.Fatalf("resp.Value = %q, want %q", resp.Value, value)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// flockTryExclusive takes a non-blocking exclusive advisory lock on f via
+// flock(2). The kernel releases it automatically when f's last open file
+// descriptor closes, including when the owning process dies (even on
+// SIGKILL), which is what lets tryReclaimStaleLock tell a stale
+// .flex-ipfs-start.lock from one still actively held.
+func flockTryExclusive(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+func flockUnlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errFlockUnsupported is returned by flockTryExclusive on Windows, where this
+// package has no LockFileEx binding available (no vendored
+// golang.org/x/sys, and the public syscall package doesn't expose it). It
+// must stay non-nil: callers such as tryReclaimStaleLock treat a nil error
+// as "lock acquired, so the previous holder is gone", and on Windows we
+// can't actually make that determination this way. The pid-liveness and
+// lock-age checks in maybeStartFlexIPFS are what reclaim stale locks on
+// this platform instead.
+var errFlockUnsupported = errors.New("flex-ipfs: advisory file locking is not implemented on windows")
+
+func flockTryExclusive(f *os.File) error {
+	return errFlockUnsupported
+}
+
+func flockUnlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// flexIPFSLogMaxSize/flexIPFSLogMaxAge bound flex-ipfs.log's growth on a
+	// long-lived node: whichever limit is hit first triggers a rotation.
+	flexIPFSLogMaxSize = 50 * 1024 * 1024 // 50 MiB
+	flexIPFSLogMaxAge  = 24 * time.Hour
+	// flexIPFSLogBackups is how many gzip-compressed rotations
+	// (flex-ipfs.log.1.gz, flex-ipfs.log.2.gz, ...) are kept around.
+	flexIPFSLogBackups = 5
+	// flexIPFSLogRingSize bounds flexIPFSLogRing, the in-memory tail that
+	// GET /flex-ipfs/log/tail serves without touching disk.
+	flexIPFSLogRingSize = 4 * 1024
+)
+
+// rotatingFileWriter is an io.WriteCloser over flex-ipfs.log that rotates
+// the file once it exceeds maxSize or maxAge has elapsed since it was last
+// (re)opened, keeping up to backups gzip-compressed copies. It also reopens
+// the file (without rotating backups) on SIGHUP, so an external logrotate(8)
+// setup that renames flex-ipfs.log aside and signals the process works the
+// same way it would for any other log file.
+type rotatingFileWriter struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	backups int
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+
+	sigCh chan os.Signal
+	done  chan struct{}
+}
+
+func newRotatingFileWriter(path string, maxSize int64, maxAge time.Duration, backups int) (*rotatingFileWriter, error) {
+	w := &rotatingFileWriter{path: path, maxSize: maxSize, maxAge: maxAge, backups: backups}
+	if err := w.openLocked(); err != nil {
+		return nil, err
+	}
+
+	w.sigCh = make(chan os.Signal, 1)
+	w.done = make(chan struct{})
+	signal.Notify(w.sigCh, syscall.SIGHUP)
+	go w.watchSIGHUP()
+
+	return w, nil
+}
+
+func (w *rotatingFileWriter) watchSIGHUP() {
+	for {
+		select {
+		case <-w.sigCh:
+			if err := w.Reopen(); err != nil {
+				log.Printf("flex-ipfs log: reopen on SIGHUP failed: %v", err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// openLocked (re)opens w.path, picking up its on-disk size so rotation still
+// triggers at the right point after a restart. Callers must hold w.mu.
+func (w *rotatingFileWriter) openLocked() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.f = f
+	w.size = st.Size()
+	w.openedAt = time.Now()
+	return nil
+}
+
+func (w *rotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return 0, fmt.Errorf("flex-ipfs log: writer closed")
+	}
+	if w.size+int64(len(p)) > w.maxSize || time.Since(w.openedAt) > w.maxAge {
+		if err := w.rotateLocked(); err != nil {
+			log.Printf("flex-ipfs log: rotate failed: %v", err)
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotateLocked closes the current file, shifts existing .N.gz backups up by
+// one (dropping the oldest past w.backups), compresses the just-closed file
+// into .1.gz, and opens a fresh file at w.path. Callers must hold w.mu.
+func (w *rotatingFileWriter) rotateLocked() error {
+	if w.f != nil {
+		_ = w.f.Close()
+		w.f = nil
+	}
+
+	for i := w.backups; i >= 1; i-- {
+		if i == w.backups {
+			_ = os.Remove(w.backupPath(i))
+			continue
+		}
+		src := w.backupPath(i)
+		if _, err := os.Stat(src); err == nil {
+			_ = os.Rename(src, w.backupPath(i+1))
+		}
+	}
+
+	if _, err := os.Stat(w.path); err == nil {
+		if err := gzipFile(w.path, w.backupPath(1)); err != nil {
+			log.Printf("flex-ipfs log: compress backup failed: %v", err)
+		} else {
+			_ = os.Remove(w.path)
+		}
+	}
+
+	return w.openLocked()
+}
+
+func (w *rotatingFileWriter) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d.gz", w.path, n)
+}
+
+// Reopen closes and reopens w.path without rotating backups, for SIGHUP /
+// external logrotate support.
+func (w *rotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f != nil {
+		_ = w.f.Close()
+		w.f = nil
+	}
+	return w.openLocked()
+}
+
+func (w *rotatingFileWriter) Close() error {
+	w.mu.Lock()
+	f := w.f
+	w.f = nil
+	w.mu.Unlock()
+
+	signal.Stop(w.sigCh)
+	close(w.done)
+
+	if f != nil {
+		return f.Close()
+	}
+	return nil
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		_ = gw.Close()
+		return err
+	}
+	return gw.Close()
+}
+
+// logRing is a bounded in-memory tail of the most recent flex-ipfs log
+// output, plus live subscribers for /flex-ipfs/log/tail's follow mode, so
+// reading recent output never has to touch the (possibly rotated, possibly
+// large) file on disk.
+type logRing struct {
+	mu   sync.Mutex
+	cap  int
+	buf  []byte
+	subs []chan []byte
+}
+
+func newLogRing(capBytes int) *logRing {
+	return &logRing{cap: capBytes}
+}
+
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	r.buf = append(r.buf, p...)
+	if len(r.buf) > r.cap {
+		r.buf = append([]byte(nil), r.buf[len(r.buf)-r.cap:]...)
+	}
+	subs := r.subs
+	r.mu.Unlock()
+
+	if len(subs) > 0 {
+		chunk := append([]byte(nil), p...)
+		for _, ch := range subs {
+			select {
+			case ch <- chunk:
+			default:
+				// Slow subscriber: drop rather than block the JVM's stdout pipe.
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Bytes returns a snapshot of the current tail.
+func (r *logRing) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]byte, len(r.buf))
+	copy(out, r.buf)
+	return out
+}
+
+// Subscribe registers ch to receive every future Write (not the existing
+// backlog — callers wanting that should also call Bytes), until unsub is
+// called.
+func (r *logRing) Subscribe() (ch <-chan []byte, unsub func()) {
+	c := make(chan []byte, 64)
+	r.mu.Lock()
+	r.subs = append(r.subs, c)
+	r.mu.Unlock()
+	return c, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, sub := range r.subs {
+			if sub == c {
+				r.subs = append(r.subs[:i], r.subs[i+1:]...)
+				close(c)
+				return
+			}
+		}
+	}
+}
+
+// flexIPFSLogRing is shared across every flex-ipfs launch/restart so
+// /flex-ipfs/log/tail keeps working (against whatever recent output it has)
+// regardless of which child process is currently running.
+var flexIPFSLogRing = newLogRing(flexIPFSLogRingSize)
+
+func init() {
+	// Registered directly on DefaultServeMux, same as this package's other
+	// bolted-on endpoints (see api_v1_posts_edit.go); not wired into
+	// bbs/api.Server's mux.
+	http.HandleFunc("/flex-ipfs/log/tail", handleFlexIPFSLogTail)
+}
+
+// handleFlexIPFSLogTail serves flexIPFSLogRing's current contents as plain
+// text, or with ?follow=1, keeps the connection open and streams further
+// output as it arrives (plain chunked text, not a WebSocket upgrade — this
+// package has no shared websocket handshake helper outside bbs/api).
+func handleFlexIPFSLogTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(flexIPFSLogRing.Bytes())
+
+	if r.URL.Query().Get("follow") != "1" {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return
+	}
+	flusher.Flush()
+
+	ch, unsub := flexIPFSLogRing.Subscribe()
+	defer unsub()
+	for {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
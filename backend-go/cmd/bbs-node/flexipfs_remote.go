@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// remoteFlexBaseDir is where rsyncFlexIPFSBase mirrors the local
+// flexible-ipfs-base directory on the remote host, relative to the ssh
+// user's home (so no tilde-expansion or remote `pwd` round-trip is needed).
+const remoteFlexBaseDir = "flexible-ipfs-base"
+
+// maybeStartFlexIPFSRemote is maybeStartFlexIPFS's path for a non-local
+// baseURL when -flexipfs-ssh-target is set: it manages flex-ipfs on a
+// remote host over ssh instead of leaving the operator to do so by hand.
+//
+// This shells out to the system ssh/rsync binaries rather than linking
+// golang.org/x/crypto/ssh: this tree has no go.mod or vendored
+// dependencies at all, and adding one was out of scope here. It also
+// stages the remote flexible-ipfs-base by rsync'ing the same local
+// directory that maybeOverrideKadrttGWEndpoint, ensureKadrttGlobalIP and
+// syncFlexIPFSBootstrapConfig already mutate in place for a local start
+// (see launchFlexIPFSProcessRemote), rather than rewriting those three
+// functions against a new local-vs-SFTP filesystem abstraction: rsync
+// already keeps a remote mirror of a local directory in sync, which is
+// most of what that abstraction would have bought us.
+func maybeStartFlexIPFSRemote(ctx context.Context, baseURL, baseDirOverride, gwEndpointOverride, logDir string, killStep time.Duration, sshTarget, sshKeyPath string, coord *shutdownCoordinator) (*flexIPFSProc, error) {
+	flexBaseDir, _, err := resolveFlexDirs(baseDirOverride)
+	if err != nil {
+		return nil, fmt.Errorf("flex-ipfs remote: resolving local flexible-ipfs-base to stage to %s: %w", sshTarget, err)
+	}
+
+	if isFlexIPFSUpRemote(ctx, sshTarget, sshKeyPath, baseURL) {
+		log.Printf("flex-ipfs already running on %s at %s", sshTarget, baseURL)
+		return nil, nil
+	}
+
+	proc, err := startFlexIPFS("ssh:"+sshTarget, flexBaseDir, gwEndpointOverride, logDir, baseURL, killStep, sshTarget, sshKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ready := waitForFlexIPFS(ctx, baseURL, 90*time.Second)
+	if !ready {
+		proc.stop()
+		return nil, fmt.Errorf("flex-ipfs API not ready on %s after 90s", sshTarget)
+	}
+
+	setCurrentFlexIPFSProc(proc)
+
+	if coord != nil {
+		coord.register("flex-ipfs-remote", func(context.Context) error {
+			proc.stop()
+			return nil
+		})
+	}
+	return proc, nil
+}
+
+// launchFlexIPFSProcessRemote mirrors launchFlexIPFSProcess's contract
+// (same return shape, used the same way by both startFlexIPFS's initial
+// launch and flexIPFSProc.launch's restarts via launchFlexIPFSProcessFor),
+// but runs flex-ipfs on sshTarget instead of as a local child process: the
+// returned *exec.Cmd is the local `ssh` invocation, so stop()'s existing
+// Interrupt/SIGTERM/Kill escalation (see escalateShutdown) tears down the
+// ssh session the same way it would a local java process, and OpenSSH
+// propagates that to the remote command.
+func launchFlexIPFSProcessRemote(sshTarget, sshKeyPath, localFlexBaseDir, gwEndpointOverride, logDir string) (cmd *exec.Cmd, stdinWriter io.Closer, logCloser io.Closer, err error) {
+	if err := maybeOverrideKadrttGWEndpoint(localFlexBaseDir, gwEndpointOverride); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := ensureKadrttGlobalIP(localFlexBaseDir, gwEndpointOverride); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := syncFlexIPFSBootstrapConfig(localFlexBaseDir, gwEndpointOverride); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := rsyncFlexIPFSBase(localFlexBaseDir, sshTarget, sshKeyPath); err != nil {
+		return nil, nil, nil, fmt.Errorf("syncing flexible-ipfs-base to %s: %w", sshTarget, err)
+	}
+
+	remoteShellCmd := fmt.Sprintf("cd %s && exec java -cp lib/* org.peergos.APIServer", shellQuote(remoteFlexBaseDir))
+	c := exec.Command("ssh", append(sshArgs(sshTarget, sshKeyPath), remoteShellCmd)...)
+
+	// Keep stdin open to avoid APIServer exiting on EOF, same as the local
+	// launch path; ssh forwards it over the session the same way exec.Cmd
+	// forwards it to a local child.
+	stdinR, stdinW := io.Pipe()
+	c.Stdin = stdinR
+	rot := attachFlexIPFSLogOutput(c, localFlexBaseDir, logDir)
+
+	if err := c.Start(); err != nil {
+		_ = stdinW.Close()
+		if rot != nil {
+			_ = rot.Close()
+		}
+		return nil, nil, nil, err
+	}
+
+	if rot == nil {
+		return c, stdinW, nil, nil
+	}
+	return c, stdinW, rot, nil
+}
+
+// isFlexIPFSUpRemote checks flex-ipfs's health the same way isFlexIPFSUp
+// does, but against the remote loopback API: flex-ipfs binds 127.0.0.1 on
+// the remote host, so baseURL isn't dialable directly from here. Rather
+// than opening an ssh -L tunnel and dialing through it locally, it's
+// simpler (and just as conclusive) to have the remote host curl its own
+// loopback and report the result over the ssh exec channel's exit status.
+func isFlexIPFSUpRemote(ctx context.Context, sshTarget, sshKeyPath, baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	endpoint := "http://127.0.0.1:" + u.Port() + strings.TrimSuffix(u.Path, "/") + "/dht/peerlist"
+	remoteShellCmd := "curl -sf -m 1 -X POST " + shellQuote(endpoint) + " -o /dev/null"
+	c := exec.CommandContext(ctx, "ssh", append(sshArgs(sshTarget, sshKeyPath), remoteShellCmd)...)
+	return c.Run() == nil
+}
+
+// rsyncFlexIPFSBase mirrors localFlexBaseDir (already mutated in place by
+// maybeOverrideKadrttGWEndpoint/ensureKadrttGlobalIP/syncFlexIPFSBootstrapConfig,
+// same as for a local start) onto sshTarget at remoteFlexBaseDir.
+func rsyncFlexIPFSBase(localFlexBaseDir, sshTarget, sshKeyPath string) error {
+	dst := sshTarget + ":" + remoteFlexBaseDir + "/"
+	cmd := exec.Command("rsync", "-az", "--delete",
+		"-e", "ssh "+strings.Join(sshBaseArgs(sshKeyPath), " "),
+		strings.TrimSuffix(localFlexBaseDir, "/")+"/", dst)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// sshBaseArgs is the set of ssh options shared by every invocation
+// (ssh exec, and the `-e` command rsync uses for its own transport).
+func sshBaseArgs(sshKeyPath string) []string {
+	args := []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new"}
+	if strings.TrimSpace(sshKeyPath) != "" {
+		args = append(args, "-i", sshKeyPath)
+	}
+	return args
+}
+
+// sshArgs is sshBaseArgs plus the target and any trailing remote command.
+func sshArgs(sshTarget, sshKeyPath string, remoteCmd ...string) []string {
+	args := append(sshBaseArgs(sshKeyPath), sshTarget)
+	return append(args, remoteCmd...)
+}
+
+// shellQuote wraps s in single quotes for safe inclusion in a remote shell
+// command string, escaping any embedded single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"flex-bbs/backend-go/bbs/signature"
+)
+
+// Reserved attrs PutSignedValue/GetSignedValue use to carry the envelope
+// alongside a PutValueWithAttr value, so existing attrs-based storage,
+// filtering, and listing keeps working unmodified. Callers must not set
+// these themselves; PutSignedValue overwrites them.
+const (
+	signedAttrSig    = "_sig"
+	signedAttrPubKey = "_pubkey"
+	signedAttrSeq    = "_seq"
+)
+
+// signedEnvelope is the value actually signed: everything a peer could lie
+// about by re-serving stale or substituted bytes under the same key. Field
+// order doesn't matter for verification (json.Marshal sorts map keys), only
+// that both sides compute it the same way.
+type signedEnvelope struct {
+	Key     string            `json:"key"`
+	Seq     uint64            `json:"seq"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	Payload []byte            `json:"payload"`
+}
+
+// stripReservedAttrs returns attrs with the envelope's reserved keys
+// removed, or nil if nothing is left. Used both when deciding what
+// user-supplied attrs to fold into the signature and when handing attrs back
+// to callers of GetValue who don't care about the envelope plumbing.
+func stripReservedAttrs(attrs map[string]string) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		if k == signedAttrSig || k == signedAttrPubKey || k == signedAttrSeq {
+			continue
+		}
+		out[k] = v
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func envelopeDigest(key string, seq uint64, attrs map[string]string, payload []byte) ([]byte, error) {
+	return json.Marshal(signedEnvelope{Key: key, Seq: seq, Attrs: attrs, Payload: payload})
+}
+
+// putSignedValue implements PutSignedValue against any FlexIPFSClient: it
+// reads the current value (if any) to derive the next monotonic sequence
+// number, signs a digest of key+seq+attrs+payload with priv, and stores the
+// result via PutValueWithAttr with the signature, public key, and sequence
+// folded into reserved attrs. Shared by httpFlexIPFSClient and
+// MockFlexIPFSClient so both backends verify identically in tests.
+func putSignedValue(ctx context.Context, c FlexIPFSClient, key string, payload []byte, attrs map[string]string, tags []string, priv ed25519.PrivateKey) error {
+	if len(priv) != ed25519.PrivateKeySize {
+		return &FlexClientError{Op: "PutSignedValue", Err: fmt.Errorf("invalid ed25519 private key size")}
+	}
+
+	var seq uint64
+	if prev, err := c.GetValue(ctx, key); err == nil {
+		if prevSeqStr, ok := prev.Attrs[signedAttrSeq]; ok {
+			if prevSeq, err := strconv.ParseUint(prevSeqStr, 10, 64); err == nil {
+				seq = prevSeq + 1
+			}
+		}
+	}
+
+	userAttrs := stripReservedAttrs(attrs)
+	digest, err := envelopeDigest(key, seq, userAttrs, payload)
+	if err != nil {
+		return &FlexClientError{Op: "PutSignedValue", Err: err}
+	}
+	jws, err := signature.SignJWS(priv, digest)
+	if err != nil {
+		return &FlexClientError{Op: "PutSignedValue", Err: err}
+	}
+
+	pub, ok := priv.Public().(ed25519.PublicKey)
+	if !ok {
+		return &FlexClientError{Op: "PutSignedValue", Err: fmt.Errorf("unexpected public key type %T", priv.Public())}
+	}
+
+	signedAttrs := make(map[string]string, len(userAttrs)+3)
+	for k, v := range userAttrs {
+		signedAttrs[k] = v
+	}
+	signedAttrs[signedAttrSig] = jws
+	signedAttrs[signedAttrPubKey] = base64.StdEncoding.EncodeToString(pub)
+	signedAttrs[signedAttrSeq] = strconv.FormatUint(seq, 10)
+
+	return c.PutValueWithAttr(ctx, key, payload, signedAttrs, tags)
+}
+
+// getSignedValue implements GetSignedValue against any FlexIPFSClient: it
+// fetches key, verifies the embedded envelope against expectedPubKey (if
+// non-nil, the embedded _pubkey must match; verification always checks the
+// signature itself), and returns the payload only once it's trusted.
+func getSignedValue(ctx context.Context, c FlexIPFSClient, key string, expectedPubKey ed25519.PublicKey) ([]byte, error) {
+	resp, err := c.GetValue(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, signer, err := verifySignedEnvelope(key, resp.Value, resp.Attrs)
+	if err != nil {
+		return nil, &FlexClientError{Op: "GetSignedValue", Err: err}
+	}
+	if !verified {
+		return nil, &FlexClientError{Op: "GetSignedValue", Err: fmt.Errorf("signature verification failed for key %q", key)}
+	}
+	if expectedPubKey != nil && base64.StdEncoding.EncodeToString(expectedPubKey) != signer {
+		return nil, &FlexClientError{Op: "GetSignedValue", Err: fmt.Errorf("key %q was signed by an unexpected public key", key)}
+	}
+	return resp.Value, nil
+}
+
+// verifySignedEnvelope reports whether value's reserved attrs form a valid
+// signature over (key, seq, non-reserved attrs, value), and who signed it
+// (the base64-encoded public key). verified is false with a nil error when
+// attrs simply has no envelope (an ordinary, unsigned PutValueWithAttr
+// value) — only a malformed or non-verifying envelope is an error.
+func verifySignedEnvelope(key string, value []byte, attrs map[string]string) (verified bool, signer string, err error) {
+	jws, ok := attrs[signedAttrSig]
+	if !ok {
+		return false, "", nil
+	}
+	pubB64, ok := attrs[signedAttrPubKey]
+	if !ok {
+		return false, "", fmt.Errorf("envelope missing %s", signedAttrPubKey)
+	}
+	seqStr, ok := attrs[signedAttrSeq]
+	if !ok {
+		return false, "", fmt.Errorf("envelope missing %s", signedAttrSeq)
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return false, "", fmt.Errorf("envelope %s: %w", signedAttrSeq, err)
+	}
+	pub, err := base64.StdEncoding.DecodeString(pubB64)
+	if err != nil {
+		return false, "", fmt.Errorf("envelope %s: %w", signedAttrPubKey, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return false, "", fmt.Errorf("envelope %s: wrong key size %d", signedAttrPubKey, len(pub))
+	}
+
+	wantDigest, err := envelopeDigest(key, seq, stripReservedAttrs(attrs), value)
+	if err != nil {
+		return false, "", err
+	}
+	gotDigest, err := signature.VerifyJWS(ed25519.PublicKey(pub), jws)
+	if err != nil {
+		return false, pubB64, nil
+	}
+	if string(gotDigest) != string(wantDigest) {
+		return false, pubB64, nil
+	}
+	return true, pubB64, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestSignedValue_MockRoundtrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	m := NewMockFlexIPFSClient("mock://")
+	ctx := context.Background()
+
+	if err := m.PutSignedValue(ctx, "post-1", []byte("hello world"), map[string]string{"type": "post"}, nil, priv); err != nil {
+		t.Fatalf("PutSignedValue: %v", err)
+	}
+
+	payload, err := m.GetSignedValue(ctx, "post-1", pub)
+	if err != nil {
+		t.Fatalf("GetSignedValue: %v", err)
+	}
+	if string(payload) != "hello world" {
+		t.Fatalf("payload = %q, want %q", payload, "hello world")
+	}
+
+	got, err := m.GetValue(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if !got.Verified {
+		t.Fatal("GetValue.Verified = false, want true")
+	}
+	if got.Type != "post" {
+		t.Fatalf("Type = %q, want %q (user attrs must survive alongside the envelope)", got.Type, "post")
+	}
+}
+
+func TestSignedValue_TamperedValueFailsVerification(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	m := NewMockFlexIPFSClient("mock://")
+	ctx := context.Background()
+	if err := m.PutSignedValue(ctx, "post-1", []byte("hello world"), nil, nil, priv); err != nil {
+		t.Fatalf("PutSignedValue: %v", err)
+	}
+
+	// Simulate a malicious peer serving different bytes under the same key,
+	// keeping the (now stale) envelope attrs attached.
+	entry := m.storage["post-1"]
+	entry.Value = []byte("tampered")
+
+	if _, err := m.GetSignedValue(ctx, "post-1", nil); err == nil {
+		t.Fatal("expected GetSignedValue to reject a tampered value")
+	}
+
+	got, err := m.GetValue(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if got.Verified {
+		t.Fatal("GetValue.Verified = true for a tampered value, want false")
+	}
+}
+
+func TestSignedValue_WrongExpectedPubKeyRejected(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	m := NewMockFlexIPFSClient("mock://")
+	ctx := context.Background()
+	if err := m.PutSignedValue(ctx, "post-1", []byte("hello"), nil, nil, priv); err != nil {
+		t.Fatalf("PutSignedValue: %v", err)
+	}
+
+	if _, err := m.GetSignedValue(ctx, "post-1", otherPub); err == nil {
+		t.Fatal("expected GetSignedValue to reject an unexpected signer")
+	}
+}
+
+func TestSignedValue_SequenceIncrementsOnUpdate(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	m := NewMockFlexIPFSClient("mock://")
+	ctx := context.Background()
+	if err := m.PutSignedValue(ctx, "post-1", []byte("v1"), nil, nil, priv); err != nil {
+		t.Fatalf("PutSignedValue v1: %v", err)
+	}
+	if err := m.PutSignedValue(ctx, "post-1", []byte("v2"), nil, nil, priv); err != nil {
+		t.Fatalf("PutSignedValue v2: %v", err)
+	}
+
+	got, err := m.GetValue(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	if got.Attrs["_seq"] != "1" {
+		t.Fatalf("_seq = %q, want %q", got.Attrs["_seq"], "1")
+	}
+	if !got.Verified {
+		t.Fatal("Verified = false after a second signed put, want true")
+	}
+}
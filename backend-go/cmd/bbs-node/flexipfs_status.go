@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// currentFlexIPFSProcMu/currentFlexIPFSProc track whichever *flexIPFSProc
+// maybeStartFlexIPFS/maybeStartFlexIPFSRemote most recently started, so
+// handleFlexIPFSStatus has something to call .status() on without either of
+// those functions having to thread it through to here themselves.
+var (
+	currentFlexIPFSProcMu sync.Mutex
+	currentFlexIPFSProc   *flexIPFSProc
+)
+
+func setCurrentFlexIPFSProc(p *flexIPFSProc) {
+	currentFlexIPFSProcMu.Lock()
+	currentFlexIPFSProc = p
+	currentFlexIPFSProcMu.Unlock()
+}
+
+func getCurrentFlexIPFSProc() *flexIPFSProc {
+	currentFlexIPFSProcMu.Lock()
+	defer currentFlexIPFSProcMu.Unlock()
+	return currentFlexIPFSProc
+}
+
+// flexIPFSEndpointHealth is flexIPFSHealthTable's record of one configured
+// gw endpoint's most recent /swarm/connect probe.
+type flexIPFSEndpointHealth struct {
+	Endpoint    string
+	Up          bool
+	RTT         time.Duration
+	LastErr     error
+	LastChecked time.Time
+	Attempts    int
+}
+
+// flexIPFSHealthTable is startFlexIPFSEndpointProber's shared record of
+// each configured endpoint's health, surfaced read-only via
+// handleFlexIPFSStatus.
+type flexIPFSHealthTable struct {
+	mu    sync.Mutex
+	byURL map[string]flexIPFSEndpointHealth
+}
+
+func newFlexIPFSHealthTable() *flexIPFSHealthTable {
+	return &flexIPFSHealthTable{byURL: make(map[string]flexIPFSEndpointHealth)}
+}
+
+func (t *flexIPFSHealthTable) record(endpoint string, rtt time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.byURL[endpoint]
+	h.Endpoint = endpoint
+	h.Up = err == nil
+	h.RTT = rtt
+	h.LastErr = err
+	h.LastChecked = time.Now()
+	h.Attempts++
+	t.byURL[endpoint] = h
+}
+
+// snapshot returns a point-in-time copy of every endpoint probed so far.
+func (t *flexIPFSHealthTable) snapshot() map[string]flexIPFSEndpointHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]flexIPFSEndpointHealth, len(t.byURL))
+	for k, v := range t.byURL {
+		out[k] = v
+	}
+	return out
+}
+
+// flexIPFSHealth is shared across every connectFlexIPFSEndpoints call so
+// handleFlexIPFSStatus reports probes from the whole process lifetime, not
+// just the most recent autostart attempt.
+var flexIPFSHealth = newFlexIPFSHealthTable()
+
+// flexIPFSProbeRetryPolicy backs off an endpoint's probes after a failure,
+// reusing the same decorrelated-jitter formula the flex client package uses
+// for its own retries (see flexipfs_client_resilience.go) rather than a
+// second backoff implementation.
+var flexIPFSProbeRetryPolicy = &retryPolicy{initial: 2 * time.Second, max: 60 * time.Second}
+
+// flexIPFSHealthyRecheckInterval is how often startFlexIPFSEndpointProber
+// re-probes an endpoint it last found healthy, rather than hammering
+// /swarm/connect continuously once a peer is confirmed reachable.
+const flexIPFSHealthyRecheckInterval = 30 * time.Second
+
+// startFlexIPFSEndpointProber runs one goroutine per endpoint that calls
+// /swarm/connect against baseURL, times it, and records the result in
+// flexIPFSHealth: a healthy endpoint is rechecked every
+// flexIPFSHealthyRecheckInterval, an unhealthy one is retried with
+// decorrelated-jitter backoff (flexIPFSProbeRetryPolicy) instead of being
+// hammered. Each goroutine exits once ctx is done.
+func startFlexIPFSEndpointProber(ctx context.Context, baseURL string, endpoints []string) {
+	for _, endpoint := range endpoints {
+		go probeFlexIPFSEndpoint(ctx, baseURL, endpoint)
+	}
+}
+
+func probeFlexIPFSEndpoint(ctx context.Context, baseURL, endpoint string) {
+	var backoff time.Duration
+	for {
+		start := time.Now()
+		err := flexIPFSSwarmConnect(ctx, baseURL, endpoint)
+		flexIPFSHealth.record(endpoint, time.Since(start), err)
+
+		var wait time.Duration
+		if err != nil {
+			backoff = decorrelatedJitterSleep(flexIPFSProbeRetryPolicy, backoff)
+			wait = backoff
+		} else {
+			backoff = 0
+			wait = flexIPFSHealthyRecheckInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func init() {
+	// Registered directly on DefaultServeMux, same as this package's other
+	// bolted-on endpoints (see flexipfs_log_rotate.go, api_v1_posts_edit.go);
+	// not wired into bbs/api.Server's mux.
+	http.HandleFunc("/flex-ipfs/status", handleFlexIPFSStatus)
+}
+
+// flexIPFSStatusResponse is handleFlexIPFSStatus's JSON body: the
+// supervisor's own state (flexIPFSProc.status) alongside the configured gw
+// endpoints' health (flexIPFSHealthTable.snapshot).
+type flexIPFSStatusResponse struct {
+	State        string                       `json:"state"`
+	RestartCount int                          `json:"restart_count"`
+	LastExitErr  string                       `json:"last_exit_error,omitempty"`
+	UptimeSecs   float64                      `json:"uptime_seconds,omitempty"`
+	Endpoints    []flexIPFSEndpointStatusJSON `json:"endpoints,omitempty"`
+}
+
+type flexIPFSEndpointStatusJSON struct {
+	Endpoint      string  `json:"endpoint"`
+	Up            bool    `json:"up"`
+	RTTMillis     float64 `json:"rtt_millis,omitempty"`
+	LastErr       string  `json:"last_error,omitempty"`
+	LastCheckedAt string  `json:"last_checked_at,omitempty"`
+	Attempts      int     `json:"attempts"`
+}
+
+// handleFlexIPFSStatus reports the current flex-ipfs supervisor state and
+// configured gw endpoints' probe health as JSON, for operators and
+// monitoring to poll instead of grepping flex-ipfs.log.
+func handleFlexIPFSStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := getCurrentFlexIPFSProc().status()
+	resp := flexIPFSStatusResponse{
+		State:        st.State,
+		RestartCount: st.RestartCount,
+		UptimeSecs:   st.Uptime.Seconds(),
+	}
+	if st.LastExitErr != nil {
+		resp.LastExitErr = st.LastExitErr.Error()
+	}
+
+	health := flexIPFSHealth.snapshot()
+	for _, h := range health {
+		e := flexIPFSEndpointStatusJSON{
+			Endpoint:  h.Endpoint,
+			Up:        h.Up,
+			RTTMillis: float64(h.RTT) / float64(time.Millisecond),
+			Attempts:  h.Attempts,
+		}
+		if h.LastErr != nil {
+			e.LastErr = h.LastErr.Error()
+		}
+		if !h.LastChecked.IsZero() {
+			e.LastCheckedAt = h.LastChecked.Format(time.RFC3339)
+		}
+		resp.Endpoints = append(resp.Endpoints, e)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(resp)
+}
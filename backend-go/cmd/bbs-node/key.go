@@ -10,6 +10,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"flex-bbs/backend-go/cmd/bbs-node/canon"
 )
 
 // ---#4 Ed25519 key handling ---
@@ -33,15 +35,17 @@ type KeyPair struct {
 	Private string `json:"priv"`
 }
 
-// GenerateKeyPair は ed25519 鍵ペアを生成して、文字列(プレフィックス+base64)で返す。
+// GenerateKeyPair は ed25519 鍵ペアを生成して、"ed25519:<keyid>:<base64>"
+// 形式の文字列で返す(keyid は公開鍵の SHA-256 先頭バイトの hex — see keyID)。
 func GenerateKeyPair() (KeyPair, error) {
 	pub, priv, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
 		return KeyPair{}, err
 	}
+	kid := keyID(pub)
 	return KeyPair{
-		Public:  EncodeEd25519PublicKey(pub),
-		Private: EncodeEd25519PrivateKey(priv),
+		Public:  encodeKey(AlgEd25519, kid, pub),
+		Private: encodeKey(AlgEd25519, kid, priv),
 	}, nil
 }
 
@@ -85,19 +89,50 @@ func ParseEd25519PrivateKey(s string) (ed25519.PrivateKey, error) {
 	return ed25519.PrivateKey(raw), nil
 }
 
+// SigningKey wraps an ed25519.PrivateKey so signing always goes through one
+// place, rather than every caller reaching for ed25519.Sign plus its own
+// encoding choice. See VerifySignature (models.go) for the Sign-produced
+// "ed25519:b64:..." form this is the write-side counterpart of.
+type SigningKey struct {
+	priv ed25519.PrivateKey
+}
+
+// NewSigningKey wraps priv as a SigningKey.
+func NewSigningKey(priv ed25519.PrivateKey) SigningKey {
+	return SigningKey{priv: priv}
+}
+
+// SignRaw returns the raw ed25519 signature bytes over payload.
+func (k SigningKey) SignRaw(payload []byte) []byte {
+	return ed25519.Sign(k.priv, payload)
+}
+
+// Sign returns payload's signature in the canonical "ed25519:b64:<base64>"
+// form VerifySignature accepts.
+func (k SigningKey) Sign(payload []byte) string {
+	return keyPrefixEd25519 + "b64:" + base64.StdEncoding.EncodeToString(k.SignRaw(payload))
+}
+
 // SignPayloadEd25519 は payload を署名して base64(StdEncoding) の署名文字列を返す。
+// privKey は ParsePrivateKey が解釈できる鍵文字列であればよく、名前に反して
+// ed25519 専用ではない(ed25519ph もここを通る。secp256k1 は
+// ErrUnsupportedAlgorithm を返す)。
 func SignPayloadEd25519(privKey string, payload string) (string, error) {
-	priv, err := ParseEd25519PrivateKey(privKey)
+	signer, err := ParsePrivateKey(privKey)
+	if err != nil {
+		return "", err
+	}
+	sig, err := signer.Sign([]byte(payload))
 	if err != nil {
 		return "", err
 	}
-	sig := ed25519.Sign(priv, []byte(payload))
 	return base64.StdEncoding.EncodeToString(sig), nil
 }
 
 // VerifyPayloadEd25519 は署名(base64)が payload と公開鍵に対して正しいか検証する。
+// pubKey は ParsePublicKey が解釈できる鍵文字列であればよい; see SignPayloadEd25519.
 func VerifyPayloadEd25519(pubKey string, payload string, signatureBase64 string) (bool, error) {
-	pub, err := ParseEd25519PublicKey(pubKey)
+	verifier, err := ParsePublicKey(pubKey)
 	if err != nil {
 		return false, err
 	}
@@ -105,10 +140,7 @@ func VerifyPayloadEd25519(pubKey string, payload string, signatureBase64 string)
 	if err != nil {
 		return false, fmt.Errorf("invalid signature base64: %w", err)
 	}
-	if len(sig) != ed25519.SignatureSize {
-		return false, fmt.Errorf("invalid signature length=%d", len(sig))
-	}
-	return ed25519.Verify(pub, []byte(payload), sig), nil
+	return verifier.Verify([]byte(payload), sig)
 }
 
 // --- Deterministic sign payload builders (field-order strict) ---
@@ -136,8 +168,12 @@ func normOptionalString(s *string) string {
 	return *s
 }
 
-// PostSignPayload builds the signable payload for Post as specified in docs.
-// NOTE: attachments/meta/signature are intentionally excluded.
+// PostSignPayload builds the canonical, domain-separated signing payload
+// for post creation: a canon.SignedPost with only the create-relevant
+// fields populated, canonical-encoded and prefixed with
+// canon.PrefixPostCreate so the result can't be replayed as an edit or
+// tombstone signature. Replaces this function's old positional "k=v\n"
+// payload (see canon.SignedPost's doc comment for why).
 func PostSignPayload(
 	version int,
 	threadID string,
@@ -147,18 +183,24 @@ func PostSignPayload(
 	bodyFormat string,
 	bodyContent string,
 	createdAt string,
-) string {
-	return BuildSignPayload([][2]string{
-		{"type", "post"},
-		{"version", fmt.Sprintf("%d", version)},
-		{"threadId", threadID},
-		{"parentPostCid", normOptionalString(parentPostCid)},
-		{"authorPubKey", authorPubKey},
-		{"displayName", displayName},
-		{"body.format", bodyFormat},
-		{"body.content", bodyContent},
-		{"createdAt", createdAt},
-	})
+) (string, error) {
+	sp := canon.SignedPost{
+		Version:       version,
+		ThreadID:      threadID,
+		ParentPostCID: parentPostCid,
+		AuthorPubKey:  authorPubKey,
+		DisplayName:   &displayName,
+		Body: &canon.SignedPostBody{
+			Format:  bodyFormat,
+			Content: bodyContent,
+		},
+		CreatedAt: &createdAt,
+	}
+	payload, err := canon.EncodeSignedPost(canon.PrefixPostCreate, sp)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
 }
 
 // BoardLogEntrySignPayload builds the signable payload for BoardLogEntry as specified in docs.
@@ -199,6 +241,13 @@ type StoredKey struct {
 	Name string `json:"name"`
 	Pub  string `json:"pub"`
 	Priv string `json:"priv"`
+
+	// EncryptedPriv holds Priv's ciphertext when this key's private
+	// material has been wrapped with EncryptStoredKeyPriv; Priv is then
+	// empty and DecryptStoredKeyPriv is required to recover it. Name and
+	// Pub are unaffected, so a keys file can mix encrypted and plaintext
+	// entries and still be listed without a passphrase.
+	EncryptedPriv *EncryptedStoredKeyPriv `json:"encryptedPriv,omitempty"`
 }
 
 // KeysFile は keys ファイルのトップレベル構造。
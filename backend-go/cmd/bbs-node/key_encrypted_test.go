@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedKeysFile_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.enc.json")
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	in := KeysFile{Keys: []StoredKey{{Name: "default", Pub: kp.Public, Priv: kp.Private}}}
+	if err := SaveEncryptedKeysFile(path, in, "correct horse battery staple", 0o600); err != nil {
+		t.Fatalf("SaveEncryptedKeysFile: %v", err)
+	}
+
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if got := st.Mode().Perm(); got != 0o600 {
+		t.Fatalf("unexpected perm: got=%#o want=%#o", got, 0o600)
+	}
+
+	out, err := LoadEncryptedKeysFile(path, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("LoadEncryptedKeysFile: %v", err)
+	}
+	if len(out.Keys) != 1 || out.Keys[0].Priv != kp.Private {
+		t.Fatalf("loaded keys mismatch: %+v", out)
+	}
+}
+
+func TestLoadEncryptedKeysFile_WrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.enc.json")
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	in := KeysFile{Keys: []StoredKey{{Name: "default", Pub: kp.Public, Priv: kp.Private}}}
+	if err := SaveEncryptedKeysFile(path, in, "right passphrase", 0o600); err != nil {
+		t.Fatalf("SaveEncryptedKeysFile: %v", err)
+	}
+
+	if _, err := LoadEncryptedKeysFile(path, "wrong passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+}
+
+func TestLoadAnyKeysFile_AutoDetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	in := KeysFile{Keys: []StoredKey{{Name: "default", Pub: kp.Public, Priv: kp.Private}}}
+
+	plainPath := filepath.Join(dir, "plain.json")
+	if err := SaveKeysFile(plainPath, in, 0o600); err != nil {
+		t.Fatalf("SaveKeysFile: %v", err)
+	}
+	noPrompt := func() (string, error) {
+		t.Fatalf("passphrase should not be requested for a plaintext keys file")
+		return "", nil
+	}
+	out, err := LoadAnyKeysFile(plainPath, noPrompt)
+	if err != nil {
+		t.Fatalf("LoadAnyKeysFile(plaintext): %v", err)
+	}
+	if len(out.Keys) != 1 || out.Keys[0].Priv != kp.Private {
+		t.Fatalf("plaintext keys mismatch: %+v", out)
+	}
+
+	encPath := filepath.Join(dir, "enc.json")
+	if err := SaveEncryptedKeysFile(encPath, in, "hunter2", 0o600); err != nil {
+		t.Fatalf("SaveEncryptedKeysFile: %v", err)
+	}
+	calls := 0
+	out, err = LoadAnyKeysFile(encPath, func() (string, error) {
+		calls++
+		return "hunter2", nil
+	})
+	if err != nil {
+		t.Fatalf("LoadAnyKeysFile(encrypted): %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected passphrase callback to be called exactly once, got %d", calls)
+	}
+	if len(out.Keys) != 1 || out.Keys[0].Priv != kp.Private {
+		t.Fatalf("decrypted keys mismatch: %+v", out)
+	}
+}
+
+func TestStoredKey_EncryptDecryptPriv(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	k := StoredKey{Name: "default", Pub: kp.Public, Priv: kp.Private}
+
+	if err := EncryptStoredKeyPriv(&k, "hunter2"); err != nil {
+		t.Fatalf("EncryptStoredKeyPriv: %v", err)
+	}
+	if k.Priv != "" {
+		t.Fatalf("expected Priv to be cleared after encryption, got %q", k.Priv)
+	}
+	if k.EncryptedPriv == nil {
+		t.Fatalf("expected EncryptedPriv to be set")
+	}
+	// Name and Pub stay readable without a passphrase.
+	if k.Name != "default" || k.Pub != kp.Public {
+		t.Fatalf("Name/Pub should be unaffected by encryption")
+	}
+
+	got, err := DecryptStoredKeyPriv(k, "hunter2")
+	if err != nil {
+		t.Fatalf("DecryptStoredKeyPriv: %v", err)
+	}
+	if got != kp.Private {
+		t.Fatalf("decrypted priv mismatch: got=%q want=%q", got, kp.Private)
+	}
+
+	if _, err := DecryptStoredKeyPriv(k, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+}
+
+func TestDecryptStoredKeyPriv_PlaintextPassthrough(t *testing.T) {
+	k := StoredKey{Name: "default", Pub: "ed25519:pub", Priv: "ed25519:priv"}
+	got, err := DecryptStoredKeyPriv(k, "unused")
+	if err != nil {
+		t.Fatalf("DecryptStoredKeyPriv: %v", err)
+	}
+	if got != "ed25519:priv" {
+		t.Fatalf("expected plaintext passthrough, got %q", got)
+	}
+}
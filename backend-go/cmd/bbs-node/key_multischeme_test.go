@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKeyPair_EmitsThreeFieldForm(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	if got := strings.Count(kp.Public, ":"); got != 2 {
+		t.Fatalf("expected pub key in <alg>:<keyid>:<base64> form, got %q", kp.Public)
+	}
+	if got := strings.Count(kp.Private, ":"); got != 2 {
+		t.Fatalf("expected priv key in <alg>:<keyid>:<base64> form, got %q", kp.Private)
+	}
+
+	pubVerifier, err := ParsePublicKey(kp.Public)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	privSigner, err := ParsePrivateKey(kp.Private)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	if pubVerifier.KeyID() != privSigner.KeyID() {
+		t.Fatalf("pub/priv keyid mismatch: %q vs %q", pubVerifier.KeyID(), privSigner.KeyID())
+	}
+	if pubVerifier.Algorithm() != AlgEd25519 || privSigner.Algorithm() != AlgEd25519 {
+		t.Fatalf("expected AlgEd25519, got pub=%q priv=%q", pubVerifier.Algorithm(), privSigner.Algorithm())
+	}
+}
+
+func TestSignVerifyPayload_RoundTripsThreeFieldForm(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	sig, err := SignPayloadEd25519(kp.Private, "hello")
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519: %v", err)
+	}
+	ok, err := VerifyPayloadEd25519(kp.Public, "hello", sig)
+	if err != nil {
+		t.Fatalf("VerifyPayloadEd25519: %v", err)
+	}
+	if !ok {
+		t.Fatalf("expected verification ok")
+	}
+}
+
+func TestSignVerifyPayload_LegacyTwoFieldFormStillParses(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	priv, err := ParsePrivateKey(kp.Private)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+
+	legacyPub := EncodeEd25519PublicKey(mustParseEd25519Pub(t, kp.Public))
+	legacyPriv := keyPrefixEd25519 + strings.SplitN(kp.Private, ":", 3)[2]
+
+	sig, err := SignPayloadEd25519(legacyPriv, "hello")
+	if err != nil {
+		t.Fatalf("SignPayloadEd25519(legacy): %v", err)
+	}
+	ok, err := VerifyPayloadEd25519(legacyPub, "hello", sig)
+	if err != nil {
+		t.Fatalf("VerifyPayloadEd25519(legacy): %v", err)
+	}
+	if !ok {
+		t.Fatalf("expected verification ok for legacy two-field form")
+	}
+
+	// A legacy string has no keyid segment, so ParsePublicKey/ParsePrivateKey
+	// must derive one from the key itself rather than leaving it empty.
+	verifier, err := ParsePublicKey(legacyPub)
+	if err != nil {
+		t.Fatalf("ParsePublicKey(legacy): %v", err)
+	}
+	if verifier.KeyID() == "" || verifier.KeyID() != priv.KeyID() {
+		t.Fatalf("expected legacy-derived keyid to match the three-field one, got %q want %q", verifier.KeyID(), priv.KeyID())
+	}
+}
+
+func mustParseEd25519Pub(t *testing.T, threeField string) []byte {
+	t.Helper()
+	v, err := ParsePublicKey(threeField)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	ev, ok := v.(ed25519Verifier)
+	if !ok {
+		t.Fatalf("expected ed25519Verifier, got %T", v)
+	}
+	return []byte(ev.pub)
+}
+
+func TestParsePublicKey_Secp256k1_UnsupportedInThisBuild(t *testing.T) {
+	_, err := ParsePublicKey("secp256k1:deadbeefdeadbeef:AAAA")
+	if !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestParsePublicKey_UnknownAlgorithm(t *testing.T) {
+	_, err := ParsePublicKey("rot13:deadbeefdeadbeef:AAAA")
+	if !errors.Is(err, ErrInvalidKeyType) {
+		t.Fatalf("expected ErrInvalidKeyType, got %v", err)
+	}
+}
+
+func TestEd25519Ph_SignVerifyRoundTrip(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	parts := strings.SplitN(kp.Private, ":", 3)
+	phPriv := string(AlgEd25519Ph) + ":" + parts[1] + ":" + parts[2]
+	pubParts := strings.SplitN(kp.Public, ":", 3)
+	phPub := string(AlgEd25519Ph) + ":" + pubParts[1] + ":" + pubParts[2]
+
+	signer, err := ParsePrivateKey(phPriv)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey(ed25519ph): %v", err)
+	}
+	sig, err := signer.Sign([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Sign(ed25519ph): %v", err)
+	}
+
+	verifier, err := ParsePublicKey(phPub)
+	if err != nil {
+		t.Fatalf("ParsePublicKey(ed25519ph): %v", err)
+	}
+	ok, err := verifier.Verify([]byte("hello"), sig)
+	if err != nil {
+		t.Fatalf("Verify(ed25519ph): %v", err)
+	}
+	if !ok {
+		t.Fatalf("expected ed25519ph verification ok")
+	}
+
+	// A plain ed25519 signature over the same payload must not verify
+	// under ed25519ph, and vice versa — they're different message
+	// encodings even though they share key material.
+	plainSigner, err := ParsePrivateKey(kp.Private)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	plainSig, err := plainSigner.Sign([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if ok, _ := verifier.Verify([]byte("hello"), plainSig); ok {
+		t.Fatalf("expected plain ed25519 signature to be rejected under ed25519ph")
+	}
+}
@@ -22,7 +22,7 @@ func TestSignVerify_OK(t *testing.T) {
 		t.Fatalf("GenerateKeyPair: %v", err)
 	}
 
-	payload := PostSignPayload(
+	payload, err := PostSignPayload(
 		1,
 		"thread-1",
 		nil,
@@ -32,6 +32,9 @@ func TestSignVerify_OK(t *testing.T) {
 		"hello",
 		"2025-12-19T00:00:00Z",
 	)
+	if err != nil {
+		t.Fatalf("PostSignPayload: %v", err)
+	}
 
 	sig, err := SignPayloadEd25519(kp.Private, payload)
 	if err != nil {
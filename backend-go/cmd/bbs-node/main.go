@@ -9,40 +9,60 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"runtime"
 	"strings"
-	"syscall"
 	"time"
 
 	"flex-bbs/backend-go/bbs/api"
 	"flex-bbs/backend-go/bbs/archive"
+	"flex-bbs/backend-go/bbs/attachments"
 	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/dnslink"
 	"flex-bbs/backend-go/bbs/flexipfs"
 	"flex-bbs/backend-go/bbs/indexer"
 	bbslog "flex-bbs/backend-go/bbs/log"
+	"flex-bbs/backend-go/bbs/ratelimit"
+	"flex-bbs/backend-go/bbs/republisher"
+	"flex-bbs/backend-go/bbs/routing"
 	"flex-bbs/backend-go/bbs/signature"
 	"flex-bbs/backend-go/bbs/storage"
 	"flex-bbs/backend-go/bbs/types"
+	searchindex "flex-bbs/backend-go/internal/indexer"
 )
 
 var (
-	role               = flag.String("role", "client", "node role: client | indexer | archiver | full")
-	flexIPFSBase       = flag.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
-	flexIPFSBaseDir    = flag.String("flexipfs-base-dir", "", "path to flexible-ipfs-base (auto-detected if empty)")
-	flexIPFSGWEndpoint = flag.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint in flexible-ipfs-base/kadrtt.properties when autostarting (also via env FLEXIPFS_GW_ENDPOINT)")
-	flexIPFSMdns       = flag.Bool("flexipfs-mdns", false, "use mDNS on LAN to discover/advertise flex-ipfs gw endpoint")
-	flexIPFSMdnsSvc    = flag.String("flexipfs-mdns-service", defaultFlexIPFSMdnsService, "mDNS service type for flex-ipfs gw endpoint (e.g. _flexipfs-gw._tcp)")
-	flexIPFSMdnsTO     = flag.Duration("flexipfs-mdns-timeout", defaultFlexIPFSMdnsTimeout, "mDNS discovery timeout")
-	autoStartFlexIPFS  = flag.Bool("autostart-flexipfs", true, "auto start local Flexible-IPFS if not running")
-	httpAddr           = flag.String("http", "127.0.0.1:8080", "HTTP listen address")
-	dataDir            = flag.String("data-dir", "", "data directory for boards.json and index db (defaults to OS config dir)")
-	boardsFile         = flag.String("boards-file", "", "path to boards.json (defaults to <data-dir>/boards.json)")
-	indexDBPath        = flag.String("index-db", "", "path to index sqlite db (defaults to <data-dir>/index.db)")
-	indexSyncInterval  = flag.Duration("index-sync-interval", 15*time.Second, "index sync interval (indexer/full)")
-	archiveDir         = flag.String("archive-dir", "", "archive directory (archiver/full)")
-	archiveInterval    = flag.Duration("archive-interval", 60*time.Second, "archive sync interval (archiver/full)")
+	role                  = flag.String("role", "client", "node role: client | indexer | archiver | full")
+	flexIPFSBase          = flag.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
+	flexIPFSBaseDir       = flag.String("flexipfs-base-dir", "", "path to flexible-ipfs-base (auto-detected if empty)")
+	flexIPFSGWEndpoint    = flag.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint(s) in flexible-ipfs-base/kadrtt.properties when autostarting; comma/newline-separated for more than one (also via env FLEXIPFS_GW_ENDPOINT)")
+	flexIPFSMdns          = flag.Bool("flexipfs-mdns", false, "use mDNS on LAN to discover/advertise flex-ipfs gw endpoint")
+	flexIPFSMdnsSvc       = flag.String("flexipfs-mdns-service", defaultFlexIPFSMdnsService, "mDNS service type for flex-ipfs gw endpoint (e.g. _flexipfs-gw._tcp)")
+	flexIPFSMdnsTO        = flag.Duration("flexipfs-mdns-timeout", defaultFlexIPFSMdnsTimeout, "mDNS discovery timeout")
+	autoStartFlexIPFS     = flag.Bool("autostart-flexipfs", true, "auto start local Flexible-IPFS if not running")
+	flexIPFSSSHTarget     = flag.String("flexipfs-ssh-target", "", "user@host[:port] to manage flex-ipfs over ssh when -flexipfs-base-url is not local (requires the system ssh/rsync binaries)")
+	flexIPFSSSHKey        = flag.String("flexipfs-ssh-key", "", "private key file for -flexipfs-ssh-target (passed to ssh/rsync as -i)")
+	httpAddr              = flag.String("http", "127.0.0.1:8080", "HTTP listen address")
+	dataDir               = flag.String("data-dir", "", "data directory for boards.json and index db (defaults to OS config dir)")
+	boardsFile            = flag.String("boards-file", "", "path to boards.json (defaults to <data-dir>/boards.json)")
+	indexDBPath           = flag.String("index-db", "", "path to index sqlite db (defaults to <data-dir>/index.db)")
+	indexSyncInterval     = flag.Duration("index-sync-interval", 15*time.Second, "index sync interval (indexer/full)")
+	archiveDir            = flag.String("archive-dir", "", "archive directory (archiver/full)")
+	attachmentsDir        = flag.String("attachments-dir", "", "blob attachment store directory (defaults to <data-dir>/attachments)")
+	archiveInterval       = flag.Duration("archive-interval", 60*time.Second, "archive sync interval (archiver/full)")
+	shutdownTimeout       = flag.Duration("shutdown-timeout", defaultShutdownStepTimeout, "max time to wait for each shutdown step (e.g. stopping flex-ipfs) on SIGINT/SIGTERM/SIGHUP")
+	writesPerMinute       = flag.Int("writes-per-minute", 0, "per-author and per-IP sustained write rate for post create/edit/tombstone (0 disables rate limiting)")
+	writesBurstSize       = flag.Int("writes-burst", 0, "per-author and per-IP write burst size (0 disables rate limiting)")
+	nonceDBPath           = flag.String("nonce-db", "", "path to bbolt replay-nonce db for post create/edit/tombstone (defaults to <data-dir>/nonces.db; in-memory if -data-dir is also unset)")
+	publishPointer        = flag.Bool("publish-pointer", false, "republish a signed BoardPointer for -publish-pointer-board-id on an interval (indexer/full roles), so clients can resolve the board's latest BoardMetaCID from -publish-pointer-priv-key's pubkey instead of learning it out-of-band")
+	publishPointerKey     = flag.String("publish-pointer-priv-key", "", "ed25519 private key (ed25519:...) to sign republished BoardPointers; required by -publish-pointer")
+	publishPointerBoard   = flag.String("publish-pointer-board-id", "", "board ID to publish a BoardPointer for; required by -publish-pointer")
+	publishPointerEvery   = flag.Duration("publish-pointer-interval", defaultPointerPublishInterval, "BoardPointer republish interval")
+	publishPointerTTL     = flag.Duration("publish-pointer-ttl", defaultPointerPublishTTL, "BoardPointer validUntil TTL from each republish")
+	dnslinkEnabled        = flag.Bool("dnslink", false, "resolve \"dnslinks\" entries in boards.json via DNS TXT records (see bbs/dnslink); refreshed on every boards.json reload, including the indexer sync loop's")
+	routingRefreshEvery   = flag.Duration("routing-refresh-interval", 60*time.Second, "how often to re-query bbs/routing (mDNS + delegated HTTP peers) for new indexer providers to trust; 0 disables the periodic refresh (startup's one-shot query still runs)")
+	republishInterval     = flag.Duration("republish-interval", republisher.DefaultInterval, "how often to re-pin/re-provide reachable board content (BoardMeta, log heads, archive shards) through flex-ipfs (archiver/full roles); 0 disables the periodic republish")
+	republishConcurrency  = flag.Int("republish-concurrency", republisher.DefaultConcurrency, "max concurrent Pin/Provide calls per republish run")
+	republishStrategy     = flag.String("republish-strategy", string(republisher.StrategyPinned), "which CIDs to republish: pinned | archived | all")
 )
 
 func main() {
@@ -62,6 +82,18 @@ func main() {
 			os.Exit(runRemoveTrustedIndexer(os.Args[2:]))
 		case "sync-trusted-indexers":
 			os.Exit(runSyncTrustedIndexers(os.Args[2:]))
+		case "repair-index":
+			os.Exit(runRepairIndex(os.Args[2:]))
+		case "migrate-search-index":
+			os.Exit(runMigrateSearchIndex(os.Args[2:]))
+		case "recalculate-search-index":
+			os.Exit(runRecalculateSearchIndex(os.Args[2:]))
+		case "conformance":
+			os.Exit(runConformance(os.Args[2:]))
+		case "resolve-dnslink":
+			os.Exit(runResolveDNSLink(os.Args[2:]))
+		case "export-car":
+			os.Exit(runExportCAR(os.Args[2:]))
 		}
 	}
 
@@ -87,33 +119,30 @@ func main() {
 	}
 	logDir := filepath.Join(dd, "logs")
 
-	var flexProc *flexIPFSProc
+	coord := newShutdownCoordinator(*shutdownTimeout)
+
 	if *autoStartFlexIPFS {
-		p, err := maybeStartFlexIPFS(ctx, *flexIPFSBase, *flexIPFSBaseDir, flexGWEndpoint, logDir)
-		if err != nil {
+		if _, err := maybeStartFlexIPFS(ctx, *flexIPFSBase, *flexIPFSBaseDir, flexGWEndpoint, logDir, *shutdownTimeout, *flexIPFSSSHTarget, *flexIPFSSSHKey, coord); err != nil {
 			log.Printf("flex-ipfs autostart failed: %v", err)
-		} else {
-			flexProc = p
 		}
 	}
 
-	// Shutdown hook to stop child processes.
-	sigCh := make(chan os.Signal, 1)
-	signals := []os.Signal{os.Interrupt}
-	if runtime.GOOS != "windows" {
-		signals = append(signals, syscall.SIGTERM)
+	if stopMdns != nil {
+		coord.register("flexipfs-mdns", func(context.Context) error {
+			stopMdns()
+			return nil
+		})
 	}
-	signal.Notify(sigCh, signals...)
+
+	// Shutdown hook: a signal cancels ctx (stopping the background sync/
+	// archive loops below), then the coordinator runs its registered closers
+	// (http server, flex-ipfs, mdns) in reverse order once ctx.Done() fires.
 	go func() {
-		<-sigCh
-		log.Printf("signal received, shutting down")
+		sig := waitForSignal()
+		log.Printf("signal received (%s), shutting down", sig)
 		cancel()
 	}()
 
-	if stopMdns != nil {
-		defer stopMdns()
-	}
-
 	bf := *boardsFile
 	if bf == "" {
 		bf = filepath.Join(dd, "boards.json")
@@ -126,20 +155,56 @@ func main() {
 	if ad == "" {
 		ad = filepath.Join(dd, "archive")
 	}
-	boards := config.NewBoardsStore(bf)
-	if err := boards.Load(); err != nil {
-		log.Fatalf("boards load error: %v", err)
+	attDir := *attachmentsDir
+	if attDir == "" {
+		attDir = filepath.Join(dd, "attachments")
 	}
 
+	if *writesPerMinute > 0 && *writesBurstSize > 0 {
+		nonceDB := *nonceDBPath
+		if nonceDB == "" && *dataDir != "" {
+			nonceDB = filepath.Join(dd, "nonces.db")
+		}
+		var nonces ratelimit.NonceStore
+		if nonceDB != "" {
+			bboltNonces, err := ratelimit.OpenBboltNonceStore(nonceDB)
+			if err != nil {
+				log.Fatalf("nonce db open error: %v", err)
+			}
+			nonces = bboltNonces
+			coord.register("nonce-db", func(context.Context) error {
+				return bboltNonces.Close()
+			})
+		} else {
+			nonces = ratelimit.NewMemoryNonceStore()
+		}
+		posts.configureWriteLimits(ratelimit.Config{
+			WritesPerMinute: *writesPerMinute,
+			BurstSize:       *writesBurstSize,
+		}, nonces)
+	}
 	trustedIndexers := config.NewTrustedIndexersStore(defaultTrustedIndexersPath(dd))
 	if err := trustedIndexers.Load(); err != nil {
 		log.Printf("trusted indexers load error: %v", err)
 	}
-	if !flexGWExplicit {
-		maybeTrustIndexerFromFlexIPFSGWMdns(ctx, trustedIndexers, flexGWEndpoint)
-	}
 
-	flex := flexipfs.New(*flexIPFSBase)
+	var flex *flexipfs.Client
+	if *flexIPFSMdns && !isLocalBaseURL(*flexIPFSBase) {
+		// A non-local base URL plus mDNS means we're on a LAN with
+		// (possibly) more than one flex-ipfs gw reachable; discover and
+		// fail over across all of them rather than pinning to the single
+		// -flexipfs-base-url endpoint.
+		pool, err := discoverFlexIPFSGWEndpointPoolMdns(ctx, *flexIPFSMdnsSvc, *flexIPFSMdnsTO)
+		if err != nil {
+			log.Printf("flex-ipfs mdns gw pool discovery failed, falling back to -flexipfs-base-url: %v", err)
+		} else {
+			log.Printf("flex-ipfs mdns gw pool discovered: %v", pool.Endpoints())
+			flex = flexipfs.NewWithPool(pool)
+		}
+	}
+	if flex == nil {
+		flex = flexipfs.New(*flexIPFSBase)
+	}
 	if isLocalBaseURL(*flexIPFSBase) {
 		if baseDir, _, err := resolveFlexDirs(*flexIPFSBaseDir); err == nil && baseDir != "" {
 			flex.BaseDir = baseDir
@@ -147,6 +212,33 @@ func main() {
 	}
 	st := storage.New(flex)
 
+	boards := config.NewBoardsStore(bf)
+	if *dnslinkEnabled {
+		boards.DNSLink = dnslink.NewResolver(st)
+	}
+	if err := boards.Load(); err != nil {
+		log.Fatalf("boards load error: %v", err)
+	}
+
+	// router replaces the old "trust the mDNS flex-ipfs gw neighbor on
+	// port 8080" heuristic (maybeTrustIndexerFromFlexIPFSGWMdns) with a
+	// real, testable discovery layer: MDNSRouter applies that same LAN
+	// heuristic, HTTPDelegatedRouter asks already-trusted peers who they
+	// know about via /routing/v1/providers, and CompositeRouter merges
+	// both. Only FindIndexers results feed trustedIndexers today — there's
+	// no equivalent store for discovered archivers yet, so FindArchivers
+	// is exposed on router for future callers without a consumer here.
+	var mdnsEndpoints []string
+	if !flexGWExplicit && flexGWEndpoint != "" {
+		mdnsEndpoints = []string{flexGWEndpoint}
+	}
+	router := routing.NewCompositeRouter(
+		routing.NewMDNSRouter(mdnsEndpoints),
+		routing.NewHTTPDelegatedRouter(trustedIndexers.List),
+	)
+	refreshRoutingTrustedIndexers(ctx, router, boards, trustedIndexers)
+	go runRoutingRefreshLoop(ctx, router, boards, trustedIndexers, *routingRefreshEvery)
+
 	var ix *indexer.Indexer
 	if *role == "indexer" || *role == "full" {
 		var err error
@@ -156,9 +248,35 @@ func main() {
 		}
 		go runIndexSyncLoop(ctx, ix, boards, *indexSyncInterval)
 	}
+	var rep *republisher.Republisher
 	if *role == "archiver" || *role == "full" {
 		a := &archive.Archiver{Storage: st, Boards: boards, Dir: ad}
 		go runArchiveLoop(ctx, a, *archiveInterval)
+
+		rep = &republisher.Republisher{
+			Storage:     st,
+			Boards:      boards,
+			Flex:        flex,
+			ArchiveDir:  ad,
+			Strategy:    republisher.Strategy(*republishStrategy),
+			Concurrency: *republishConcurrency,
+		}
+		go runRepublishLoop(ctx, rep, *republishInterval)
+	}
+	if *publishPointer {
+		if *role != "indexer" && *role != "full" {
+			log.Fatalf("-publish-pointer requires -role=indexer or -role=full")
+		}
+		if *publishPointerKey == "" || *publishPointerBoard == "" {
+			log.Fatalf("-publish-pointer requires -publish-pointer-priv-key and -publish-pointer-board-id")
+		}
+		st.PointerCache = storage.NewPointerCache(defaultPointerCacheSize, defaultPointerCacheTTL)
+		go runPointerPublishLoop(ctx, st, boards, *publishPointerKey, *publishPointerBoard, *publishPointerEvery, *publishPointerTTL)
+	}
+
+	attStore, err := attachments.Open(attDir)
+	if err != nil {
+		log.Fatalf("attachments open error: %v", err)
 	}
 
 	srv := &api.Server{
@@ -167,11 +285,17 @@ func main() {
 		Boards:          boards,
 		TrustedIndexers: trustedIndexers,
 		Indexer:         ix,
+		Attachments:     attStore,
+		Republisher:     rep,
 	}
 	httpServer := &http.Server{
-		Addr:    *httpAddr,
-		Handler: srv.Handler(),
+		Addr:              *httpAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	coord.register("http-server", func(ctx context.Context) error {
+		return httpServer.Shutdown(ctx)
+	})
 
 	log.Printf("bbs-node starting role=%s http=%s flexipfs=%s", *role, *httpAddr, *flexIPFSBase)
 
@@ -183,12 +307,7 @@ func main() {
 	}()
 
 	<-ctx.Done()
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer shutdownCancel()
-	_ = httpServer.Shutdown(shutdownCtx)
-	if flexProc != nil {
-		flexProc.stop()
-	}
+	coord.shutdown()
 }
 
 func defaultDataDir() string {
@@ -202,66 +321,48 @@ func defaultTrustedIndexersPath(dataDir string) string {
 	return filepath.Join(dataDir, "trusted_indexers.json")
 }
 
-func maybeTrustIndexerFromFlexIPFSGWMdns(ctx context.Context, trusted *config.TrustedIndexersStore, gwEndpoint string) {
-	if trusted == nil {
-		return
-	}
-	gwEndpoint = strings.TrimSpace(gwEndpoint)
-	if gwEndpoint == "" {
-		return
-	}
-
-	ip := extractIP4FromMultiaddr(gwEndpoint)
-	if ip == "" {
-		return
-	}
-
-	// Convention: bbs-node HTTP is typically exposed on 8080 on the same host as the advertised flex-ipfs gw endpoint.
-	// When the gw endpoint was discovered via mDNS, treat that host as a trust anchor automatically.
-	baseURL := fmt.Sprintf("http://%s:8080", ip)
-
-	// Best-effort: avoid trusting obviously non-indexer peers, but keep behavior non-fatal.
-	role, err := fetchBbsNodeRole(ctx, baseURL)
-	if err == nil && role != "indexer" && role != "full" {
-		return
-	}
-
-	if changed, err := trusted.Add(baseURL); err != nil {
-		log.Printf("trusted indexers auto-add failed: %v", err)
-	} else if changed {
-		log.Printf("trusted indexers: auto-added (mdns gw bootstrap) %s", baseURL)
+// refreshRoutingTrustedIndexers queries router for indexer providers of
+// every board in boards.List() and adds whatever it finds to trusted.
+// It's best effort throughout (a failed lookup or a failed Add just gets
+// logged), since this always runs alongside other ways trustedIndexers
+// gets populated (add-trusted-indexer, sync-trusted-indexers) and
+// shouldn't be able to block startup.
+func refreshRoutingTrustedIndexers(ctx context.Context, router routing.Router, boards *config.BoardsStore, trusted *config.TrustedIndexersStore) {
+	for _, ref := range boards.List() {
+		records, err := router.FindIndexers(ctx, ref.BoardID)
+		if err != nil {
+			log.Printf("routing: find indexers for %s: %v", ref.BoardID, err)
+			continue
+		}
+		for _, rec := range records {
+			if changed, err := trusted.Add(rec.BaseURL); err != nil {
+				log.Printf("trusted indexers auto-add failed: %v", err)
+			} else if changed {
+				log.Printf("trusted indexers: auto-added (routing, board=%s) %s", ref.BoardID, rec.BaseURL)
+			}
+		}
 	}
 }
 
-func fetchBbsNodeRole(ctx context.Context, baseURL string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-
-	endpoint := strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/healthz"
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return "", err
-	}
-	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	b, err := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
-	if err != nil {
-		return "", err
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("healthz http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+// runRoutingRefreshLoop periodically re-runs refreshRoutingTrustedIndexers,
+// so indexer peers that come online after startup (or start advertising a
+// board this node didn't know about yet) still get discovered.
+func runRoutingRefreshLoop(ctx context.Context, router routing.Router, boards *config.BoardsStore, trusted *config.TrustedIndexersStore, interval time.Duration) {
+	if interval <= 0 {
+		return
 	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
 
-	s := strings.TrimSpace(string(b))
-	const prefix = "ok role="
-	if !strings.HasPrefix(s, prefix) {
-		return "", fmt.Errorf("unexpected healthz response: %q", s)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			_ = boards.Load()
+			refreshRoutingTrustedIndexers(ctx, router, boards, trusted)
+		}
 	}
-	return strings.TrimSpace(strings.TrimPrefix(s, prefix)), nil
 }
 
 func runGenKey(args []string) int {
@@ -314,6 +415,92 @@ func runArchiveLoop(ctx context.Context, a *archive.Archiver, interval time.Dura
 	}
 }
 
+// runExportCAR is a one-shot alternative to the archiver role's periodic
+// runArchiveLoop: instead of syncing a.Dir's loose <cid>.json files, it
+// writes a single CAR archive (see archive.Archiver.ExportCAR) for either
+// one board (-board-id) or every board in boards.json, to -out or stdout.
+func runExportCAR(args []string) int {
+	fs := flag.NewFlagSet("export-car", flag.ExitOnError)
+	dd := fs.String("data-dir", "", "data directory (defaults to OS config dir)")
+	boardsPath := fs.String("boards-file", "", "path to boards.json (defaults to <data-dir>/boards.json)")
+	flexIPFSBase := fs.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
+	boardID := fs.String("board-id", "", "only export this boardId from boards.json (defaults to every configured board)")
+	out := fs.String("out", "", "output CAR file path (defaults to stdout)")
+	_ = fs.Parse(args)
+
+	data := *dd
+	if data == "" {
+		data = defaultDataDir()
+	}
+	bp := *boardsPath
+	if bp == "" {
+		bp = filepath.Join(data, "boards.json")
+	}
+
+	boards := config.NewBoardsStore(bp)
+	if err := boards.Load(); err != nil {
+		log.Printf("export-car: boards load error: %v", err)
+		return 1
+	}
+
+	var roots []string
+	for _, ref := range boards.List() {
+		if *boardID != "" && ref.BoardID != *boardID {
+			continue
+		}
+		roots = append(roots, ref.BoardMetaCID)
+	}
+	if len(roots) == 0 {
+		log.Printf("export-car: no matching boards in %s", bp)
+		return 1
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Printf("export-car: create %s: %v", *out, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	st := storage.New(flexipfs.New(*flexIPFSBase))
+	a := &archive.Archiver{Storage: st, Boards: boards}
+	if err := a.ExportCAR(context.Background(), w, roots); err != nil {
+		log.Printf("export-car: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runRepublishLoop periodically re-runs rep.RunOnce so pinned/archived
+// board content keeps getting re-announced to flex-ipfs; unlike
+// runIndexSyncLoop/runArchiveLoop's interval<=0 fallback, interval<=0 here
+// disables the loop entirely rather than substituting a default, since
+// republishInterval's flag default is already republisher.DefaultInterval
+// and an explicit 0 is the documented way to turn periodic republishing
+// off.
+func runRepublishLoop(ctx context.Context, rep *republisher.Republisher, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := rep.RunOnce(ctx); err != nil {
+				log.Printf("republisher run error: %v", err)
+			}
+		}
+	}
+}
+
 func runInitBoard(args []string) int {
 	fs := flag.NewFlagSet("init-board", flag.ExitOnError)
 	boardID := fs.String("board-id", "", "board ID (e.g. bbs.general)")
@@ -322,7 +509,7 @@ func runInitBoard(args []string) int {
 	authorPrivKey := fs.String("author-priv-key", "", "author private key (ed25519:...)")
 	flexBase := fs.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
 	flexBaseDir := fs.String("flexipfs-base-dir", "", "path to flexible-ipfs-base (auto-detected if empty)")
-	flexGWEndpoint := fs.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint in flexible-ipfs-base/kadrtt.properties when autostarting (also via env FLEXIPFS_GW_ENDPOINT)")
+	flexGWEndpoint := fs.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint(s) in flexible-ipfs-base/kadrtt.properties when autostarting; comma/newline-separated for more than one (also via env FLEXIPFS_GW_ENDPOINT)")
 	flexMdns := fs.Bool("flexipfs-mdns", false, "use mDNS on LAN to discover flex-ipfs gw endpoint")
 	flexMdnsSvc := fs.String("flexipfs-mdns-service", defaultFlexIPFSMdnsService, "mDNS service type for flex-ipfs gw endpoint (e.g. _flexipfs-gw._tcp)")
 	flexMdnsTO := fs.Duration("flexipfs-mdns-timeout", defaultFlexIPFSMdnsTimeout, "mDNS discovery timeout")
@@ -350,7 +537,7 @@ func runInitBoard(args []string) int {
 	var flexProc *flexIPFSProc
 	if *autostartFlexIPFS {
 		flexGW, _ := resolveFlexIPFSGWEndpointWithMdns(ctx, *flexGWEndpoint, *flexMdns, *flexMdnsSvc, *flexMdnsTO)
-		p, err := maybeStartFlexIPFS(ctx, *flexBase, *flexBaseDir, flexGW, filepath.Join(data, "logs"))
+		p, err := maybeStartFlexIPFS(ctx, *flexBase, *flexBaseDir, flexGW, filepath.Join(data, "logs"), 0, "", "", nil)
 		if err != nil {
 			log.Printf("flex-ipfs autostart failed: %v", err)
 		} else {
@@ -403,7 +590,7 @@ func runAddBoard(args []string) int {
 	boardMetaCID := fs.String("board-meta-cid", "", "BoardMeta CID")
 	flexBase := fs.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
 	flexBaseDir := fs.String("flexipfs-base-dir", "", "path to flexible-ipfs-base (auto-detected if empty)")
-	flexGWEndpoint := fs.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint in flexible-ipfs-base/kadrtt.properties when autostarting (also via env FLEXIPFS_GW_ENDPOINT)")
+	flexGWEndpoint := fs.String("flexipfs-gw-endpoint", "", "override ipfs.endpoint(s) in flexible-ipfs-base/kadrtt.properties when autostarting; comma/newline-separated for more than one (also via env FLEXIPFS_GW_ENDPOINT)")
 	flexMdns := fs.Bool("flexipfs-mdns", false, "use mDNS on LAN to discover flex-ipfs gw endpoint")
 	flexMdnsSvc := fs.String("flexipfs-mdns-service", defaultFlexIPFSMdnsService, "mDNS service type for flex-ipfs gw endpoint (e.g. _flexipfs-gw._tcp)")
 	flexMdnsTO := fs.Duration("flexipfs-mdns-timeout", defaultFlexIPFSMdnsTimeout, "mDNS discovery timeout")
@@ -432,7 +619,7 @@ func runAddBoard(args []string) int {
 	if strings.TrimSpace(*boardID) == "" {
 		if *autostartFlexIPFS {
 			flexGW, _ := resolveFlexIPFSGWEndpointWithMdns(ctx, *flexGWEndpoint, *flexMdns, *flexMdnsSvc, *flexMdnsTO)
-			p, err := maybeStartFlexIPFS(ctx, *flexBase, *flexBaseDir, flexGW, filepath.Join(data, "logs"))
+			p, err := maybeStartFlexIPFS(ctx, *flexBase, *flexBaseDir, flexGW, filepath.Join(data, "logs"), 0, "", "", nil)
 			if err != nil {
 				log.Printf("flex-ipfs autostart failed: %v", err)
 			} else {
@@ -694,3 +881,141 @@ func fetchTrustedIndexersFromBootstrap(ctx context.Context, client *http.Client,
 	}
 	return list, nil
 }
+
+// runRepairIndex is a developer-only command that re-verifies every applied
+// migration's recorded checksum against the SQL compiled into this binary,
+// to catch a database that was migrated by a now-stale or hand-edited
+// build. It opens the index db read-write (so Indexer.Open can still apply
+// any migrations that are genuinely pending) but makes no other changes.
+func runRepairIndex(args []string) int {
+	fs := flag.NewFlagSet("repair-index", flag.ExitOnError)
+	dd := fs.String("data-dir", "", "data directory (defaults to OS config dir)")
+	dbPath := fs.String("index-db", "", "path to index sqlite db (defaults to <data-dir>/index.db)")
+	flexIPFSBase := fs.String("flexipfs-base-url", "http://127.0.0.1:5001/api/v0", "Flexible-IPFS HTTP API base URL")
+	_ = fs.Parse(args)
+
+	data := *dd
+	if data == "" {
+		data = defaultDataDir()
+	}
+	path := *dbPath
+	if path == "" {
+		path = filepath.Join(data, "index.db")
+	}
+
+	st := storage.New(flexipfs.New(*flexIPFSBase))
+	ix, err := indexer.Open(path, st)
+	if err != nil {
+		log.Printf("indexer open error: %v", err)
+		return 1
+	}
+	defer ix.Close()
+
+	mismatches, err := ix.VerifyMigrationChecksums(context.Background())
+	if err != nil {
+		log.Printf("checksum verification error: %v", err)
+		return 1
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("ok: all applied migration checksums match")
+		return 0
+	}
+	for _, m := range mismatches {
+		fmt.Printf("checksum mismatch: %s (%s) recorded=%s current=%s\n", m.Version, m.Name, m.Recorded, m.Current)
+	}
+	return 1
+}
+
+// runMigrateSearchIndex applies pending schema migrations to the search/API
+// index (internal/indexer's DB, backing the HTTP search endpoints — distinct
+// from the board-log index repair-index operates on above). Opening the DB
+// already migrates it to the latest version, so most of the time this
+// command is just a way to confirm that happened and see it print the
+// outcome; -version exists for rolling a database forward deterministically
+// to an older, known-good migration instead.
+func runMigrateSearchIndex(args []string) int {
+	fs := flag.NewFlagSet("migrate-search-index", flag.ExitOnError)
+	dd := fs.String("data-dir", "", "data directory (defaults to OS config dir)")
+	dbPath := fs.String("search-index-db", "", "path to search index sqlite db (defaults to <data-dir>/search-index.db)")
+	driver := fs.String("driver", "sqlite3", "search index driver: sqlite3 | pgx")
+	dsn := fs.String("dsn", "", "DSN for the driver (sqlite3 defaults to -search-index-db; required for pgx)")
+	version := fs.String("version", "", "migrate to this version instead of the latest registered migration")
+	_ = fs.Parse(args)
+
+	cfg := searchindex.DBConfig{Driver: *driver, DSN: *dsn}
+	if cfg.DSN == "" {
+		if cfg.Driver != "" && cfg.Driver != "sqlite3" {
+			log.Printf("-dsn is required for driver %q", cfg.Driver)
+			return 1
+		}
+		data := *dd
+		if data == "" {
+			data = defaultDataDir()
+		}
+		path := *dbPath
+		if path == "" {
+			path = filepath.Join(data, "search-index.db")
+		}
+		cfg.DSN = path
+	}
+
+	db, err := searchindex.NewDB(cfg)
+	if err != nil {
+		log.Printf("search index open error: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	if *version != "" {
+		if err := db.MigrateTo(context.Background(), *version); err != nil {
+			log.Printf("migrate error: %v", err)
+			return 1
+		}
+	}
+
+	fmt.Printf("ok: search index schema is up to date (driver=%s)\n", *driver)
+	return 0
+}
+
+func runRecalculateSearchIndex(args []string) int {
+	fs := flag.NewFlagSet("recalculate-search-index", flag.ExitOnError)
+	dd := fs.String("data-dir", "", "data directory (defaults to OS config dir)")
+	dbPath := fs.String("search-index-db", "", "path to search index sqlite db (defaults to <data-dir>/search-index.db)")
+	driver := fs.String("driver", "sqlite3", "search index driver: sqlite3 | pgx")
+	dsn := fs.String("dsn", "", "DSN for the driver (sqlite3 defaults to -search-index-db; required for pgx)")
+	boardID := fs.String("board-id", "", "restrict recalculation to this board (defaults to all boards)")
+	_ = fs.Parse(args)
+
+	cfg := searchindex.DBConfig{Driver: *driver, DSN: *dsn}
+	if cfg.DSN == "" {
+		if cfg.Driver != "" && cfg.Driver != "sqlite3" {
+			log.Printf("-dsn is required for driver %q", cfg.Driver)
+			return 1
+		}
+		data := *dd
+		if data == "" {
+			data = defaultDataDir()
+		}
+		path := *dbPath
+		if path == "" {
+			path = filepath.Join(data, "search-index.db")
+		}
+		cfg.DSN = path
+	}
+
+	db, err := searchindex.NewDB(cfg)
+	if err != nil {
+		log.Printf("search index open error: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	report, err := db.Recalculate(context.Background(), *boardID)
+	if err != nil {
+		log.Printf("recalculate error: %v", err)
+		return 1
+	}
+
+	fmt.Printf("ok: scanned %d threads, fixed %d\n", report.ThreadsScanned, report.ThreadsFixed)
+	return 0
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"flex-bbs/backend-go/bbs/flexipfs"
 	"github.com/grandcat/zeroconf"
 )
 
@@ -97,6 +100,131 @@ func discoverFlexIPFSGWEndpointMdns(ctx context.Context, service string, timeout
 	return found, nil
 }
 
+// discoverFlexIPFSGWEndpointsMdns browses service for the full timeout
+// window (unlike discoverFlexIPFSGWEndpointMdns, which stops at the first
+// advertisement) and returns every distinct endpoint seen, in the order
+// first discovered.
+func discoverFlexIPFSGWEndpointsMdns(ctx context.Context, service string, timeout time.Duration) ([]string, error) {
+	service = strings.TrimSpace(service)
+	if service == "" {
+		service = defaultFlexIPFSMdnsService
+	}
+	if timeout <= 0 {
+		timeout = defaultFlexIPFSMdnsTimeout
+	}
+
+	resolver, err := zeroconf.NewResolver(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	entries := make(chan *zeroconf.ServiceEntry)
+	var seen []string
+	seenSet := make(map[string]bool)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-entries:
+				if e == nil {
+					continue
+				}
+				ep := extractEndpointFromTxt(e.Text)
+				if ep == "" || seenSet[ep] {
+					continue
+				}
+				seenSet[ep] = true
+				seen = append(seen, ep)
+			}
+		}
+	}()
+
+	if err := resolver.Browse(ctx, service, "local.", entries); err != nil {
+		return nil, err
+	}
+	<-ctx.Done()
+
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("no %s advertisements found within %s", service, timeout)
+	}
+	return seen, nil
+}
+
+// flexIPFSGWProbeTimeout bounds each candidate endpoint's health probe in
+// discoverFlexIPFSGWEndpointPoolMdns, so one unreachable endpoint doesn't
+// stall the whole discovery past the mDNS browse window itself.
+const flexIPFSGWProbeTimeout = 2 * time.Second
+
+// probeFlexIPFSGWHTTPEndpoint reports whether endpoint answers a lightweight
+// GET (its version/status path) within flexIPFSGWProbeTimeout.
+func probeFlexIPFSGWHTTPEndpoint(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, flexIPFSGWProbeTimeout)
+	defer cancel()
+
+	u := strings.TrimRight(endpoint, "/") + "/api/v0/version"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("flex-ipfs gw probe %s: http %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+// discoverFlexIPFSGWEndpointPoolMdns discovers every _flexipfs-gw._tcp
+// advertisement seen during the mDNS browse window, probes each one, and
+// returns an ordered flexipfs.EndpointPool with the healthy endpoints
+// first — so a caller wiring it via flexipfs.NewWithPool starts its
+// round-robin rotation from a gateway it already knows answers, rather
+// than discovering that on the request path.
+func discoverFlexIPFSGWEndpointPoolMdns(ctx context.Context, service string, timeout time.Duration) (*flexipfs.EndpointPool, error) {
+	endpoints, err := discoverFlexIPFSGWEndpointsMdns(ctx, service, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	type probed struct {
+		endpoint string
+		healthy  bool
+	}
+	results := make([]probed, len(endpoints))
+	for i, ep := range endpoints {
+		err := probeFlexIPFSGWHTTPEndpoint(ctx, ep)
+		results[i] = probed{endpoint: ep, healthy: err == nil}
+		if err != nil {
+			log.Printf("flex-ipfs mdns probe failed for %s: %v", ep, err)
+		}
+	}
+	// Stable sort so equally-healthy endpoints keep their discovery order,
+	// with healthy ones first.
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].healthy && !results[j].healthy
+	})
+
+	ordered := make([]string, len(results))
+	for i, r := range results {
+		ordered[i] = r.endpoint
+	}
+	pool := flexipfs.NewEndpointPool(ordered)
+	for _, r := range results {
+		if !r.healthy {
+			pool.MarkUnhealthy(r.endpoint)
+		}
+	}
+	return pool, nil
+}
+
 func maybeAdvertiseFlexIPFSGWEndpointMdns(endpoint string, mdnsEnabled bool, mdnsService string) (stop func(), err error) {
 	if !mdnsEnabled {
 		return func() {}, nil
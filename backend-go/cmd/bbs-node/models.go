@@ -3,11 +3,16 @@ package main
 import (
 	"bytes"
 	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/mr-tron/base58"
 )
 
 // ============================================================================
@@ -405,38 +410,108 @@ func (e *BoardLogEntry) CanonicalSignPayload() string {
 // Signature Verification
 // ============================================================================
 
-// VerifySignature verifies an ed25519 signature against the canonical payload.
-// pubKeyHex should be in format "ed25519:hexstring" or "ed25519:base64string"
-// For now we support hex format.
-func VerifySignature(pubKeyStr string, signatureBase64 string, payload string) (bool, error) {
-	// Parse public key
-	if !strings.HasPrefix(pubKeyStr, "ed25519:") {
-		return false, fmt.Errorf("invalid public key format: must start with 'ed25519:'")
+// VerifySignature verifies an ed25519 signature against the canonical
+// payload. pubKeyStr and signatureStr both accept "ed25519:<encoding>:<data>",
+// where <encoding> is one of:
+//   - "hex"   hex-encoded bytes
+//   - "b64"   base64 (standard alphabet), padded or unpadded
+//   - "b64url" base64 (URL-safe alphabet), padded or unpadded
+//   - "mb"    multibase, matching how bbs/did resolves did:key ids: a
+//     "z" prefix selects base58btc, the only base this module emits
+//
+// A bare "ed25519:<data>" (no encoding segment) auto-detects: hex if <data>
+// is exactly 64 hex digits (a 32-byte key), base64 otherwise.
+func VerifySignature(pubKeyStr string, signatureStr string, payload string) (bool, error) {
+	pubKeyBytes, err := decodeEd25519Field(pubKeyStr)
+	if err != nil {
+		return false, fmt.Errorf("public key: %w", err)
+	}
+	if len(pubKeyBytes) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("public key: %w: got %d bytes, want %d", ErrInvalidPubKey, len(pubKeyBytes), ed25519.PublicKeySize)
 	}
-	
-	keyHex := strings.TrimPrefix(pubKeyStr, "ed25519:")
-	
-	// Decode hex to bytes (ed25519 public keys are 32 bytes)
-	var pubKeyBytes [32]byte
-	n, err := fmt.Sscanf(keyHex, "%32x", &pubKeyBytes)
-	if err != nil || n != 1 {
-		return false, fmt.Errorf("failed to parse public key hex: %w", err)
+
+	sigBytes, err := decodeEd25519Field(signatureStr)
+	if err != nil {
+		return false, fmt.Errorf("signature: %w", err)
 	}
-	
-	pubKey := ed25519.PublicKey(pubKeyBytes[:])
-	
-	// Decode signature from base64
-	sigBytes := make([]byte, 0, ed25519.SignatureSize)
-	// Try base64 decode (Note: ed25519 signatures are 64 bytes)
-	// For now, we'll assume signature is hex-encoded as well for simplicity
-	// In production, use proper base64 decoding
-	var sigArray [64]byte
-	n, err = fmt.Sscanf(signatureBase64, "%64x", &sigArray)
-	if err != nil || n != 1 {
-		return false, fmt.Errorf("failed to parse signature hex: %w", err)
-	}
-	sigBytes = sigArray[:]
-	
-	// Verify
-	return ed25519.Verify(pubKey, []byte(payload), sigBytes), nil
+	if len(sigBytes) != ed25519.SignatureSize {
+		return false, fmt.Errorf("signature: got %d bytes, want %d", len(sigBytes), ed25519.SignatureSize)
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(pubKeyBytes), []byte(payload), sigBytes), nil
+}
+
+// hexField42 matches a bare (no encoding tag) value that looks like a
+// hex-encoded 32-byte ed25519 public key or seed.
+var hexField42 = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// decodeEd25519Field decodes the data portion of an "ed25519:..." key or
+// signature string; see VerifySignature for the accepted forms.
+func decodeEd25519Field(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "ed25519:") {
+		return nil, fmt.Errorf("must start with 'ed25519:'")
+	}
+	rest := strings.TrimPrefix(s, "ed25519:")
+
+	if enc, data, ok := strings.Cut(rest, ":"); ok {
+		switch enc {
+		case "hex":
+			return decodeHexField(data)
+		case "b64":
+			return decodeBase64Field(base64.StdEncoding, data)
+		case "b64url":
+			return decodeBase64Field(base64.URLEncoding, data)
+		case "mb":
+			return decodeMultibaseField(data)
+		}
+		// Not a recognized encoding tag: the ':' was probably part of the
+		// bare value itself, so auto-detect the whole of rest instead.
+	}
+	return autodetectEd25519Field(rest)
+}
+
+func autodetectEd25519Field(data string) ([]byte, error) {
+	if hexField42.MatchString(data) {
+		return decodeHexField(data)
+	}
+	return decodeBase64Field(base64.StdEncoding, data)
+}
+
+func decodeHexField(data string) ([]byte, error) {
+	b, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %w", err)
+	}
+	return b, nil
+}
+
+// decodeBase64Field decodes data with enc, tolerating both the padded and
+// unpadded form of enc's alphabet, since real-world callers send both.
+func decodeBase64Field(enc *base64.Encoding, data string) ([]byte, error) {
+	if b, err := enc.DecodeString(data); err == nil {
+		return b, nil
+	}
+	b, err := enc.WithPadding(base64.NoPadding).DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return b, nil
+}
+
+// decodeMultibaseField decodes data as a multibase string, matching
+// bbs/did's did:key convention: a "z" prefix selects base58btc, the only
+// base this module currently emits or accepts.
+func decodeMultibaseField(data string) ([]byte, error) {
+	if !strings.HasPrefix(data, "z") {
+		prefix := data
+		if len(prefix) > 1 {
+			prefix = prefix[:1]
+		}
+		return nil, fmt.Errorf("unsupported multibase prefix %q (only 'z'/base58btc)", prefix)
+	}
+	b, err := base58.Decode(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid multibase base58btc: %w", err)
+	}
+	return b, nil
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ModeratorScopeTombstone is the only capability scope
+// handleTombstonePost currently checks for; see ModeratorKey.Authorized.
+const ModeratorScopeTombstone = "tombstone"
+
+// ModeratorKey is a named, board-scoped Ed25519 capability credential a
+// board owner (BoardMeta.CreatedBy) issues so someone other than a post's
+// original author can perform scoped moderation actions against that
+// board -- currently just tombstoning a post. Keys are carried in
+// BoardMeta.ModeratorKeys; a key's own Signature (over
+// ModeratorKeyIssuanceSignPayload, checked by VerifyModeratorKeyIssuance)
+// is what makes it trustworthy, not BoardMeta's own signature, so a key
+// survives being copied into a later BoardMeta unmodified.
+type ModeratorKey struct {
+	KeyID     string    `json:"keyId"`
+	PubKey    string    `json:"pubKey"` // "ed25519:..."
+	BoardID   string    `json:"boardId"`
+	Scopes    []string  `json:"scopes"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	IssuedBy  string    `json:"issuedBy"` // board owner's pubkey, must equal the carrying BoardMeta.CreatedBy
+
+	// Revoked lets a board owner kill a key before NotAfter by reissuing
+	// BoardMeta with this entry's Revoked flipped true, without having to
+	// also drop it from ModeratorKeys (which would otherwise make a
+	// revoked keyId indistinguishable from one that never existed).
+	Revoked bool `json:"revoked,omitempty"`
+
+	Signature string `json:"signature"`
+}
+
+// HasScope reports whether k lists scope among its Scopes.
+func (k ModeratorKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorized reports whether k grants scope against boardID at t: it must
+// not be revoked, must be bound to boardID, t must fall within
+// [NotBefore, NotAfter], and scope must be among its Scopes. It does not
+// check k.Signature -- call VerifyModeratorKeyIssuance first.
+func (k ModeratorKey) Authorized(boardID, scope string, t time.Time) bool {
+	if k.Revoked {
+		return false
+	}
+	if k.BoardID != boardID {
+		return false
+	}
+	if t.Before(k.NotBefore) || t.After(k.NotAfter) {
+		return false
+	}
+	return k.HasScope(scope)
+}
+
+// FindModeratorKey returns the ModeratorKey in bm.ModeratorKeys whose
+// KeyID is keyID.
+func (bm *BoardMeta) FindModeratorKey(keyID string) (ModeratorKey, bool) {
+	for _, k := range bm.ModeratorKeys {
+		if k.KeyID == keyID {
+			return k, true
+		}
+	}
+	return ModeratorKey{}, false
+}
+
+// ModeratorKeyIssuanceSignPayload builds the canonical, domain-separated
+// signing payload for a moderator key issuance record, signed by the
+// board owner (k.IssuedBy) to authenticate k independently of whichever
+// BoardMeta ends up carrying it.
+func ModeratorKeyIssuanceSignPayload(k ModeratorKey) string {
+	return BuildSignPayload([][2]string{
+		{"type", "moderatorKeyIssuance"},
+		{"keyId", k.KeyID},
+		{"pubKey", k.PubKey},
+		{"boardId", k.BoardID},
+		{"scopes", strings.Join(k.Scopes, ",")},
+		{"notBefore", k.NotBefore.UTC().Format(time.RFC3339Nano)},
+		{"notAfter", k.NotAfter.UTC().Format(time.RFC3339Nano)},
+		{"issuedAt", k.IssuedAt.UTC().Format(time.RFC3339Nano)},
+		{"issuedBy", k.IssuedBy},
+	})
+}
+
+// SignModeratorKeyIssuance signs k's issuance record with privKey (which
+// must belong to k.IssuedBy) and sets k.Signature.
+func SignModeratorKeyIssuance(privKey string, k *ModeratorKey) error {
+	sig, err := SignPayloadEd25519(privKey, ModeratorKeyIssuanceSignPayload(*k))
+	if err != nil {
+		return err
+	}
+	k.Signature = sig
+	return nil
+}
+
+// VerifyModeratorKeyIssuance reports whether k.Signature is a valid
+// signature by k.IssuedBy over k's issuance payload.
+func VerifyModeratorKeyIssuance(k ModeratorKey) (bool, error) {
+	return VerifyPayloadEd25519(k.IssuedBy, ModeratorKeyIssuanceSignPayload(k), k.Signature)
+}
+
+// ModeratorTombstoneSignPayload builds the canonical, domain-separated
+// signing payload a moderator key's holder signs over to tombstone
+// postCid on someone else's behalf -- the moderator-path counterpart of
+// PostTombstoneSignPayload (api_v1_posts_tombstone.go), over the key's
+// boardId/moderatorKeyId rather than an authorPubKey.
+func ModeratorTombstoneSignPayload(version int, boardID, threadID, postCid, moderatorKeyID, tombstonedAt string) string {
+	return BuildSignPayload([][2]string{
+		{"type", "moderatorTombstonePost"},
+		{"version", fmt.Sprintf("%d", version)},
+		{"boardId", boardID},
+		{"threadId", threadID},
+		{"postCid", postCid},
+		{"moderatorKeyId", moderatorKeyID},
+		{"tombstonedAt", tombstonedAt},
+	})
+}
+
+// boardMetaLookup is the minimal read-only board config access
+// handleTombstonePost needs to resolve a moderatorKeyId against its
+// board's current BoardMeta. staticBoardMetaLookup below is the concrete
+// implementation over the []BoardMeta slice loadBoardMetaConfig already
+// returns.
+type boardMetaLookup interface {
+	BoardMetaByID(boardID string) (BoardMeta, bool)
+}
+
+// staticBoardMetaLookup implements boardMetaLookup over a fixed slice of
+// BoardMeta, the shape loadBoardMetaConfig returns at startup.
+type staticBoardMetaLookup []BoardMeta
+
+// BoardMetaByID returns the BoardMeta in s whose BoardID is boardID.
+func (s staticBoardMetaLookup) BoardMetaByID(boardID string) (BoardMeta, bool) {
+	for _, b := range s {
+		if b.BoardID == boardID {
+			return b, true
+		}
+	}
+	return BoardMeta{}, false
+}
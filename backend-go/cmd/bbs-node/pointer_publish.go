@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"flex-bbs/backend-go/bbs/config"
+	"flex-bbs/backend-go/bbs/storage"
+)
+
+const (
+	defaultPointerPublishInterval = 4 * time.Hour
+	defaultPointerPublishTTL      = 24 * time.Hour
+
+	// defaultPointerCacheSize/TTL size a small in-process ResolveBoardPointer
+	// cache (see storage.PointerCache) for this node's own republish loop,
+	// which re-resolves its own prior record every tick just to find the
+	// next Seq.
+	defaultPointerCacheSize = 256
+	defaultPointerCacheTTL  = 5 * time.Minute
+)
+
+// runPointerPublishLoop republishes a signed BoardPointer for boardID,
+// pointing at whatever BoardMetaCID boards.json currently has on file for
+// it, every interval — resigning with an incremented Seq each time (see
+// storage.Storage.PublishBoardPointer) so the record's ValidUntil never
+// lapses as long as this loop keeps running.
+func runPointerPublishLoop(ctx context.Context, st *storage.Storage, boards *config.BoardsStore, privKey, boardID string, interval, ttl time.Duration) {
+	if interval <= 0 {
+		interval = defaultPointerPublishInterval
+	}
+	if ttl <= 0 {
+		ttl = defaultPointerPublishTTL
+	}
+
+	publish := func() {
+		_ = boards.Load()
+		cid, ok := boards.Get(boardID)
+		if !ok {
+			log.Printf("publish-pointer: board %s not found in boards.json, skipping", boardID)
+			return
+		}
+		p, err := st.PublishBoardPointer(ctx, privKey, boardID, cid, ttl)
+		if err != nil {
+			log.Printf("publish-pointer: %v", err)
+			return
+		}
+		log.Printf("publish-pointer: published board=%s seq=%d cid=%s", boardID, p.Seq, p.BoardMetaCID)
+	}
+	publish()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			publish()
+		}
+	}
+}
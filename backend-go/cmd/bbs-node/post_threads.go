@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -11,6 +12,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
 )
 
 // --- API: POST /api/v1/threads (create thread) ---
@@ -108,13 +111,15 @@ func handleCreateThread(w http.ResponseWriter, r *http.Request) {
 	sum := sha256.Sum256([]byte(payload))
 	threadID := "thread-" + hex.EncodeToString(sum[:8])
 
-	// BBSデータ(暫定): board_threads_store.go の in-memory へ追加。
+	// BBSデータ(暫定): threads (board_threads_store.go) の ThreadStore へ追加。
 	//
 	// 差し替えメモ:
-	// 合体(本実装)のタイミングで、ここはストレージ層/FlexIPFS/DB に置き換える。
-	boardThreadsMu.Lock()
-	boardThreads[req.BoardID] = append(boardThreads[req.BoardID], threadSummary{ID: threadID, Title: req.Title})
-	boardThreadsMu.Unlock()
+	// 合体(本実装)のタイミングで、threads の裏付けを BoltThreadStore/
+	// flex-ipfs 等の本物の ThreadStore 実装に差し替える。
+	if err := threads.threadStore().Put(context.Background(), req.BoardID, poststore.Thread{ID: threadID, Title: req.Title}); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
 
 	writeJSON(w, http.StatusCreated, createThreadResponse{ThreadID: threadID})
 }
@@ -59,17 +59,15 @@ func TestCreateThread_OK(t *testing.T) {
 		t.Fatalf("threadId empty")
 	}
 
-	boardThreadsMu.RLock()
-	threads := boardThreads[req.BoardID]
-	boardThreadsMu.RUnlock()
-	if len(threads) != 1 {
-		t.Fatalf("threads len=%d", len(threads))
+	stored, ok := getBoardThreadSummaries(req.BoardID)
+	if !ok || len(stored) != 1 {
+		t.Fatalf("threads len=%d ok=%v", len(stored), ok)
 	}
-	if threads[0].ID != out.ThreadID {
-		t.Fatalf("stored id=%q resp id=%q", threads[0].ID, out.ThreadID)
+	if stored[0].ID != out.ThreadID {
+		t.Fatalf("stored id=%q resp id=%q", stored[0].ID, out.ThreadID)
 	}
-	if threads[0].Title != req.Title {
-		t.Fatalf("stored title=%q", threads[0].Title)
+	if stored[0].Title != req.Title {
+		t.Fatalf("stored title=%q", stored[0].Title)
 	}
 }
 
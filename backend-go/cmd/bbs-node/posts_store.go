@@ -0,0 +1,101 @@
+package main
+
+import (
+	"sync"
+
+	poststore "flex-bbs/backend-go/cmd/bbs-node/storage"
+)
+
+// postsHandlers holds the storage.PostStore that
+// handleCreatePost/handleEditPost/handlePostActions are wired to, built
+// via newPostsHandlers rather than each of those files declaring its own
+// ad-hoc postsStoreMu/postsStore global.
+type postsHandlers struct {
+	mu     sync.RWMutex
+	store  poststore.PostStore
+	limits writeLimits
+
+	// logs, if non-nil, is where handleCreatePost/handleEditPost/
+	// handleTombstonePost append a BoardLogEntry for their mutation (see
+	// board_log_write.go). Left nil by newPostsHandlers — a board's posts
+	// API works standalone until a caller wires a *BoardLogStore in, same
+	// as limits defaults to "unconfigured, allow everything".
+	logs *BoardLogStore
+
+	// boards, if non-nil, is where handleTombstonePost resolves a
+	// moderatorKeyId against its board's current BoardMeta (see
+	// moderator_keys.go). Left nil by newPostsHandlers — moderator
+	// tombstones are rejected until a caller wires a boardMetaLookup in,
+	// same as logs.
+	boards boardMetaLookup
+}
+
+// newPostsHandlers wires up a postsHandlers backed by store.
+func newPostsHandlers(store poststore.PostStore) *postsHandlers {
+	return &postsHandlers{store: store}
+}
+
+// postStore returns the currently-wired PostStore, so handlers never read
+// h.store directly without going through the mutex.
+func (h *postsHandlers) postStore() poststore.PostStore {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.store
+}
+
+// boardLogStore returns the currently-wired *BoardLogStore, or nil if none
+// has been set.
+func (h *postsHandlers) boardLogStore() *BoardLogStore {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.logs
+}
+
+// setBoardLogStoreForTests wires logs into posts, mirroring
+// resetPostsStoreForTests. Pass nil to go back to "unconfigured".
+func setBoardLogStoreForTests(logs *BoardLogStore) {
+	posts.mu.Lock()
+	posts.logs = logs
+	posts.mu.Unlock()
+}
+
+// boardsLookup returns the currently-wired boardMetaLookup, or nil if
+// none has been set.
+func (h *postsHandlers) boardsLookup() boardMetaLookup {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.boards
+}
+
+// setBoardMetaLookupForTests wires boards into posts, mirroring
+// setBoardLogStoreForTests. Pass nil to go back to "unconfigured".
+func setBoardMetaLookupForTests(boards boardMetaLookup) {
+	posts.mu.Lock()
+	posts.boards = boards
+	posts.mu.Unlock()
+}
+
+// posts is the posts API's single instance, storing to a
+// ContentAddressedStore by default; tests swap this to a fresh
+// MemoryStore via resetPostsStoreForTests.
+var posts = newPostsHandlers(poststore.NewContentAddressedStore())
+
+// resetPostsStoreForTests replaces posts' store with a fresh MemoryStore,
+// so each test starts from an empty, independent store.
+func resetPostsStoreForTests() {
+	posts.mu.Lock()
+	posts.store = poststore.NewMemoryStore()
+	posts.mu.Unlock()
+}
+
+// seedPostForTests installs sp directly into posts' store under its own
+// CID, bypassing Put's content-derived CID assignment. Requires
+// resetPostsStoreForTests to have been called first, since only
+// MemoryStore supports seeding.
+func seedPostForTests(sp poststore.StoredPost) {
+	ms, ok := posts.postStore().(*poststore.MemoryStore)
+	if !ok {
+		panic("seedPostForTests: posts store is not a MemoryStore; call resetPostsStoreForTests first")
+	}
+	ms.Seed(sp)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithRequestID_GeneratesWhenAbsent(t *testing.T) {
+	var seen string
+	h := withRequestID(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	if seen == "" {
+		t.Fatal("expected a generated request ID in context")
+	}
+	if got := w.Result().Header.Get(requestIDHeader); got != seen {
+		t.Fatalf("response header %s = %q, want it to match context value %q", requestIDHeader, got, seen)
+	}
+}
+
+func TestWithRequestID_EchoesClientSuppliedID(t *testing.T) {
+	var seen string
+	h := withRequestID(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(requestIDHeader, "client-supplied-id")
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	if seen != "client-supplied-id" {
+		t.Fatalf("context request ID = %q, want client-supplied value", seen)
+	}
+	if got := w.Result().Header.Get(requestIDHeader); got != "client-supplied-id" {
+		t.Fatalf("response header %s = %q, want echoed client value", requestIDHeader, got)
+	}
+}
+
+func TestRequestIDFromContext_EmptyWithoutMiddleware(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := requestIDFromContext(r.Context()); got != "" {
+		t.Fatalf("requestIDFromContext() = %q, want empty for a bare context", got)
+	}
+}
+
+func TestGenerateRequestID_Unique(t *testing.T) {
+	a := generateRequestID()
+	b := generateRequestID()
+	if a == "" || b == "" {
+		t.Fatal("expected non-empty IDs")
+	}
+	if a == b {
+		t.Fatalf("expected distinct IDs, got %q twice", a)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownStepTimeout is how long shutdownCoordinator.shutdown waits
+// for each registered closer to return before moving on, unless overridden
+// via newShutdownCoordinator.
+const defaultShutdownStepTimeout = 10 * time.Second
+
+// namedCloser is one resource shutdownCoordinator knows how to tear down,
+// named so a slow or failing closer is identifiable in the log.
+type namedCloser struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// shutdownCoordinator collects named cleanup steps (registered as the
+// process starts resources) and, on SIGINT/SIGTERM/SIGHUP, runs them in
+// reverse registration order, each bounded by a per-closer timeout. Running
+// them in reverse mirrors the usual defer convention: whatever was started
+// last (and therefore depends on everything before it) is torn down first.
+type shutdownCoordinator struct {
+	mu      sync.Mutex
+	closers []namedCloser
+	step    time.Duration
+}
+
+// newShutdownCoordinator returns a coordinator whose closers each get up to
+// step to finish; step <= 0 falls back to defaultShutdownStepTimeout.
+func newShutdownCoordinator(step time.Duration) *shutdownCoordinator {
+	if step <= 0 {
+		step = defaultShutdownStepTimeout
+	}
+	return &shutdownCoordinator{step: step}
+}
+
+// register appends a named closer, to be invoked on shutdown. A nil receiver
+// or close func is a no-op, so callers that only sometimes have a
+// coordinator (e.g. maybeStartFlexIPFS's one-shot CLI callers, which pass
+// nil) don't need to guard every call site.
+func (c *shutdownCoordinator) register(name string, close func(ctx context.Context) error) {
+	if c == nil || close == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closers = append(c.closers, namedCloser{name: name, close: close})
+}
+
+// waitForSignal blocks until SIGINT, SIGTERM, or (outside Windows) SIGHUP
+// arrives, then returns it.
+func waitForSignal() os.Signal {
+	sigCh := make(chan os.Signal, 1)
+	signals := []os.Signal{os.Interrupt}
+	if runtime.GOOS != "windows" {
+		signals = append(signals, syscall.SIGTERM, syscall.SIGHUP)
+	}
+	signal.Notify(sigCh, signals...)
+	return <-sigCh
+}
+
+// listenAndShutdown waits for a shutdown signal in the background, runs
+// shutdown, and closes the returned channel once every closer has returned
+// or timed out. Intended to be started once, early in main.
+func (c *shutdownCoordinator) listenAndShutdown() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		sig := waitForSignal()
+		log.Printf("signal received (%s), shutting down", sig)
+		c.shutdown()
+		close(done)
+	}()
+	return done
+}
+
+// shutdown runs every registered closer in reverse registration order, each
+// bounded by c.step, and logs (rather than aborts on) individual failures so
+// one stuck closer doesn't prevent the rest from running.
+func (c *shutdownCoordinator) shutdown() {
+	c.mu.Lock()
+	closers := append([]namedCloser(nil), c.closers...)
+	c.mu.Unlock()
+
+	overallCtx, overallCancel := context.WithTimeout(context.Background(), c.step*time.Duration(len(closers)+1))
+	defer overallCancel()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		nc := closers[i]
+		stepCtx, stepCancel := context.WithTimeout(overallCtx, c.step)
+		if err := nc.close(stepCtx); err != nil {
+			log.Printf("shutdown: %s: %v", nc.name, err)
+		}
+		stepCancel()
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// boltPostsBucket maps a post's CID to its JSON-encoded StoredPost.
+// boltThreadIndexBucket maps a threadID to the JSON-encoded list of its
+// posts' CIDs in Put order, mirroring MemoryStore's byCID/byThread maps
+// but persisted to disk.
+var (
+	boltPostsBucket       = []byte("posts")
+	boltThreadIndexBucket = []byte("thread_index")
+)
+
+// BoltStore is a PostStore backed by a bbolt database file, for a
+// cmd/bbs-node deployment where posts must survive a restart
+// (MemoryStore's maps do not), following the same bbolt conventions as
+// ratelimit.BboltNonceStore.
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// OpenBoltStore opens (creating if necessary) a bbolt-backed PostStore at
+// path.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("storage: open post db %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltPostsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltThreadIndexBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage: init post db %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Put(ctx context.Context, p Post) (string, error) {
+	cid, _ := computeCID(p)
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		posts := tx.Bucket(boltPostsBucket)
+		if posts.Get([]byte(cid)) != nil {
+			return nil
+		}
+
+		sp := StoredPost{CID: cid, Post: p}
+		v, err := json.Marshal(sp)
+		if err != nil {
+			return err
+		}
+		if err := posts.Put([]byte(cid), v); err != nil {
+			return err
+		}
+
+		threads := tx.Bucket(boltThreadIndexBucket)
+		cids, err := readThreadCIDs(threads, p.ThreadID)
+		if err != nil {
+			return err
+		}
+		cids = append(cids, cid)
+		return writeThreadCIDs(threads, p.ThreadID, cids)
+	})
+	if err != nil {
+		return "", fmt.Errorf("storage: put post: %w", err)
+	}
+	return cid, nil
+}
+
+func (s *BoltStore) Get(ctx context.Context, cid string) (StoredPost, error) {
+	var sp StoredPost
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(boltPostsBucket).Get([]byte(cid))
+		if v == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(v, &sp)
+	})
+	if err != nil {
+		return StoredPost{}, err
+	}
+	return sp, nil
+}
+
+func (s *BoltStore) ListByThread(ctx context.Context, threadID, cursor string, limit int) ([]StoredPost, string, error) {
+	var posts []StoredPost
+	var next string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		cids, err := readThreadCIDs(tx.Bucket(boltThreadIndexBucket), threadID)
+		if err != nil {
+			return err
+		}
+
+		start := 0
+		if cursor != "" {
+			idx := -1
+			for i, c := range cids {
+				if c == cursor {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				return ErrNotFound
+			}
+			start = idx + 1
+		}
+
+		end := start + limit
+		if end > len(cids) || limit <= 0 {
+			end = len(cids)
+		}
+
+		postsBucket := tx.Bucket(boltPostsBucket)
+		posts = make([]StoredPost, 0, end-start)
+		for _, c := range cids[start:end] {
+			var sp StoredPost
+			if err := json.Unmarshal(postsBucket.Get([]byte(c)), &sp); err != nil {
+				return err
+			}
+			posts = append(posts, sp)
+		}
+		if end < len(cids) {
+			next = cids[end-1]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: list posts by thread: %w", err)
+	}
+	return posts, next, nil
+}
+
+func (s *BoltStore) Tombstone(ctx context.Context, oldCID, newCID, tombstonedAt, tombstonedBy string) error {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		posts := tx.Bucket(boltPostsBucket)
+		if posts.Get([]byte(oldCID)) == nil {
+			return ErrNotFound
+		}
+
+		v := posts.Get([]byte(newCID))
+		if v == nil {
+			return ErrNotFound
+		}
+		var sp StoredPost
+		if err := json.Unmarshal(v, &sp); err != nil {
+			return err
+		}
+		sp.Tombstoned = true
+		sp.TombstonedAt = tombstonedAt
+		sp.TombstonedBy = tombstonedBy
+		nv, err := json.Marshal(sp)
+		if err != nil {
+			return err
+		}
+		return posts.Put([]byte(newCID), nv)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: tombstone post: %w", err)
+	}
+	return nil
+}
+
+// ListTombstoned returns every tombstoned post in s, in key (CID) order --
+// bbolt iterates a bucket in byte-sorted key order, so unlike MemoryStore
+// and ContentAddressedStore this isn't Put order.
+func (s *BoltStore) ListTombstoned(ctx context.Context) ([]StoredPost, error) {
+	var out []StoredPost
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltPostsBucket).ForEach(func(k, v []byte) error {
+			var sp StoredPost
+			if err := json.Unmarshal(v, &sp); err != nil {
+				return err
+			}
+			if sp.Tombstoned {
+				out = append(out, sp)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: list tombstoned posts: %w", err)
+	}
+	return out, nil
+}
+
+// Close closes the underlying bbolt database.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func readThreadCIDs(threads *bbolt.Bucket, threadID string) ([]string, error) {
+	v := threads.Get([]byte(threadID))
+	if v == nil {
+		return nil, nil
+	}
+	var cids []string
+	if err := json.Unmarshal(v, &cids); err != nil {
+		return nil, err
+	}
+	return cids, nil
+}
+
+func writeThreadCIDs(threads *bbolt.Bucket, threadID string, cids []string) error {
+	v, err := json.Marshal(cids)
+	if err != nil {
+		return err
+	}
+	return threads.Put([]byte(threadID), v)
+}
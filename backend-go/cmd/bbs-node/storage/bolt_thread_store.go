@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// boltThreadsBucket maps a board ID to its JSON-encoded []Thread, in Put
+// order.
+var boltThreadsBucket = []byte("threads")
+
+// BoltThreadStore is a ThreadStore backed by a bbolt database file, for a
+// cmd/bbs-node deployment where board thread listings must survive a
+// restart (MemoryThreadStore's maps do not). Watch is served entirely
+// in-process (bbolt has no native change-notification mechanism): a Put
+// or Delete notifies subscribers after its transaction commits, the same
+// best-effort, never-close semantics as MemoryThreadStore.Watch.
+type BoltThreadStore struct {
+	db   *bbolt.DB
+	subs *MemoryThreadStore // reused only for its subscriber bookkeeping
+}
+
+// OpenBoltThreadStore opens (creating if necessary) a bbolt-backed
+// ThreadStore at path.
+func OpenBoltThreadStore(path string) (*BoltThreadStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("storage: open thread db %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltThreadsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage: init thread db %s: %w", path, err)
+	}
+	return &BoltThreadStore{db: db, subs: NewMemoryThreadStore()}, nil
+}
+
+func (s *BoltThreadStore) Put(ctx context.Context, boardID string, t Thread) error {
+	var snapshot []Thread
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(boltThreadsBucket)
+		threads, err := readThreads(b, boardID)
+		if err != nil {
+			return err
+		}
+		threads = append(threads, t)
+		snapshot = threads
+		return writeThreads(b, boardID, threads)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: put thread: %w", err)
+	}
+	s.subs.notify(boardID, snapshot)
+	return nil
+}
+
+func (s *BoltThreadStore) Get(ctx context.Context, boardID string) ([]Thread, bool) {
+	var threads []Thread
+	var found bool
+	s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(boltThreadsBucket).Get([]byte(boardID))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &threads)
+	})
+	return threads, found
+}
+
+func (s *BoltThreadStore) Delete(ctx context.Context, boardID, threadID string) error {
+	var snapshot []Thread
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(boltThreadsBucket)
+		threads, err := readThreads(b, boardID)
+		if err != nil {
+			return err
+		}
+		kept := make([]Thread, 0, len(threads))
+		for _, t := range threads {
+			if t.ID != threadID {
+				kept = append(kept, t)
+			}
+		}
+		snapshot = kept
+		return writeThreads(b, boardID, kept)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: delete thread: %w", err)
+	}
+	s.subs.notify(boardID, snapshot)
+	return nil
+}
+
+func (s *BoltThreadStore) List(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltThreadsBucket).ForEach(func(k, v []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: list boards: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *BoltThreadStore) Watch(ctx context.Context, boardID string) <-chan []Thread {
+	return s.subs.Watch(ctx, boardID)
+}
+
+// Close closes the underlying bbolt database.
+func (s *BoltThreadStore) Close() error {
+	return s.db.Close()
+}
+
+func readThreads(threads *bbolt.Bucket, boardID string) ([]Thread, error) {
+	v := threads.Get([]byte(boardID))
+	if v == nil {
+		return nil, nil
+	}
+	var out []Thread
+	if err := json.Unmarshal(v, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func writeThreads(threads *bbolt.Bucket, boardID string, list []Thread) error {
+	v, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return threads.Put([]byte(boardID), v)
+}
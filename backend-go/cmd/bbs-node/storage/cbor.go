@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// encodePostDagCBOR canonically encodes p as a dag-cbor map, so that
+// computeCID's digest only ever depends on p's content.
+//
+// Canonical (DAG-)CBOR map key ordering is bytewise comparison of each
+// key's encoded bytes; for this fixed set of short text-string keys that
+// reduces to ordering by length then lexicographically, which is
+// precomputed here rather than sorted at encode time. Optional fields
+// (ParentPostCID, EditedAt) are omitted entirely when empty, which leaves
+// the remaining keys' relative order unchanged.
+func encodePostDagCBOR(p Post) []byte {
+	type field struct {
+		key     string
+		value   string
+		present bool
+	}
+	fields := []field{
+		{"threadId", p.ThreadID, true},
+		{"createdAt", p.CreatedAt, true},
+		{"editedAt", p.EditedAt, p.EditedAt != ""},
+		{"bodyFormat", p.BodyFormat, true},
+		{"bodyContent", p.BodyContent, true},
+		{"displayName", p.DisplayName, true},
+		{"authorPubKey", p.AuthorPubKey, true},
+		{"parentPostCid", p.ParentPostCID, p.ParentPostCID != ""},
+	}
+
+	n := 0
+	for _, f := range fields {
+		if f.present {
+			n++
+		}
+	}
+
+	buf := cborMapHead(n)
+	for _, f := range fields {
+		if !f.present {
+			continue
+		}
+		buf = append(buf, cborTextString(f.key)...)
+		buf = append(buf, cborTextString(f.value)...)
+	}
+	return buf
+}
+
+// decodePostDagCBOR reverses encodePostDagCBOR. It's not a general
+// dag-cbor decoder: it only understands a top-level map of text-string
+// keys to text-string values, which is all encodePostDagCBOR ever
+// produces.
+func decodePostDagCBOR(data []byte) (Post, error) {
+	n, rest, err := cborTakeMapHead(data)
+	if err != nil {
+		return Post{}, fmt.Errorf("storage: decode post: %w", err)
+	}
+
+	var p Post
+	for i := uint64(0); i < n; i++ {
+		key, r, err := cborTakeTextString(rest)
+		if err != nil {
+			return Post{}, fmt.Errorf("storage: decode post: key %d: %w", i, err)
+		}
+		value, r2, err := cborTakeTextString(r)
+		if err != nil {
+			return Post{}, fmt.Errorf("storage: decode post: value for %q: %w", key, err)
+		}
+		rest = r2
+
+		switch key {
+		case "threadId":
+			p.ThreadID = value
+		case "createdAt":
+			p.CreatedAt = value
+		case "editedAt":
+			p.EditedAt = value
+		case "bodyFormat":
+			p.BodyFormat = value
+		case "bodyContent":
+			p.BodyContent = value
+		case "displayName":
+			p.DisplayName = value
+		case "authorPubKey":
+			p.AuthorPubKey = value
+		case "parentPostCid":
+			p.ParentPostCID = value
+		}
+	}
+	return p, nil
+}
+
+// --- minimal CBOR primitives, scoped to what encodePostDagCBOR/decodePostDagCBOR need ---
+
+const (
+	cborMajorTextString = 3
+	cborMajorMap        = 5
+)
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		b := make([]byte, 3)
+		b[0] = major<<5 | 25
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	case n <= 0xffffffff:
+		b := make([]byte, 5)
+		b[0] = major<<5 | 26
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		return b
+	default:
+		b := make([]byte, 9)
+		b[0] = major<<5 | 27
+		binary.BigEndian.PutUint64(b[1:], n)
+		return b
+	}
+}
+
+func cborMapHead(n int) []byte {
+	return cborHead(cborMajorMap, uint64(n))
+}
+
+func cborTextString(s string) []byte {
+	return append(cborHead(cborMajorTextString, uint64(len(s))), s...)
+}
+
+// cborTakeHead parses one head byte (plus any following length bytes),
+// returning the major type, the length/value it carries, and the
+// remaining bytes.
+func cborTakeHead(data []byte) (major byte, n uint64, rest []byte, err error) {
+	if len(data) < 1 {
+		return 0, 0, nil, fmt.Errorf("truncated head")
+	}
+	major = data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, 0, nil, fmt.Errorf("truncated 1-byte length")
+		}
+		return major, uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, 0, nil, fmt.Errorf("truncated 2-byte length")
+		}
+		return major, uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, 0, nil, fmt.Errorf("truncated 4-byte length")
+		}
+		return major, uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, 0, nil, fmt.Errorf("truncated 8-byte length")
+		}
+		return major, binary.BigEndian.Uint64(data), data[8:], nil
+	default:
+		return 0, 0, nil, fmt.Errorf("unsupported additional info %d", info)
+	}
+}
+
+func cborTakeMapHead(data []byte) (n uint64, rest []byte, err error) {
+	major, n, rest, err := cborTakeHead(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	if major != cborMajorMap {
+		return 0, nil, fmt.Errorf("expected map, got major type %d", major)
+	}
+	return n, rest, nil
+}
+
+func cborTakeTextString(data []byte) (s string, rest []byte, err error) {
+	major, n, rest, err := cborTakeHead(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if major != cborMajorTextString {
+		return "", nil, fmt.Errorf("expected text string, got major type %d", major)
+	}
+	if uint64(len(rest)) < n {
+		return "", nil, fmt.Errorf("truncated text string")
+	}
+	return string(rest[:n]), rest[n:], nil
+}
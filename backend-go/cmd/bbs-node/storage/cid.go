@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+)
+
+// CIDv1 (dag-cbor codec, sha2-256 multihash) is the only CID shape this
+// package produces, since every Post is stored as the dag-cbor block
+// encodePostDagCBOR builds. See bbs/attachments/cid.go for the sibling
+// scheme this mirrors (raw codec, for opaque attachment blobs rather than
+// structured CBOR).
+const (
+	codecDagCBOR       = 0x71
+	multihashSHA256    = 0x12
+	sha256DigestLength = 32
+)
+
+// cidBase32 is the unpadded base32 alphabet CIDv1 strings use under the
+// "b" multibase prefix: https://github.com/multiformats/multibase. Same
+// alphabet bbs/attachments/cid.go uses, so CIDs from either package look
+// alike.
+var cidBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// computeCID dag-cbor-encodes p and returns both its CIDv1 string and the
+// encoded bytes, so callers that go on to store the block (see
+// ContentAddressedStore.Put) don't have to encode it a second time.
+func computeCID(p Post) (cid string, block []byte) {
+	block = encodePostDagCBOR(p)
+	digest := sha256.Sum256(block)
+
+	buf := make([]byte, 0, 8+sha256DigestLength)
+	buf = appendUvarint(buf, 1) // CID version
+	buf = appendUvarint(buf, codecDagCBOR)
+	buf = appendUvarint(buf, multihashSHA256)
+	buf = appendUvarint(buf, sha256DigestLength)
+	buf = append(buf, digest[:]...)
+	return "b" + cidBase32.EncodeToString(buf), block
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
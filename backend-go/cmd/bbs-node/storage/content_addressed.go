@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"flex-bbs/backend-go/bbs/storage/car"
+)
+
+// ContentAddressedStore is a PostStore that keeps each post as the
+// dag-cbor block computeCID hashed it from, so a post's CID is a
+// verifiable function of its content rather than an opaque key assigned
+// by the store. Thread membership and tombstone state are bookkeeping on
+// top of that block store, not part of the content-addressed data itself.
+type ContentAddressedStore struct {
+	mu           sync.RWMutex
+	blocks       map[string][]byte   // cid -> dag-cbor block
+	byThread     map[string][]string // threadID -> CIDs in Put order
+	tombstoned   map[string]string   // cid -> tombstonedAt, for CIDs Tombstone marked
+	tombstonedBy map[string]string   // cid -> tombstonedBy, alongside tombstoned
+	putOrder     []string            // every CID ever Put, in that order
+}
+
+// NewContentAddressedStore returns an empty ContentAddressedStore.
+func NewContentAddressedStore() *ContentAddressedStore {
+	return &ContentAddressedStore{
+		blocks:       make(map[string][]byte),
+		byThread:     make(map[string][]string),
+		tombstoned:   make(map[string]string),
+		tombstonedBy: make(map[string]string),
+	}
+}
+
+func (s *ContentAddressedStore) Put(ctx context.Context, p Post) (string, error) {
+	cid, block := computeCID(p)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.blocks[cid]; !exists {
+		s.blocks[cid] = block
+		s.byThread[p.ThreadID] = append(s.byThread[p.ThreadID], cid)
+		s.putOrder = append(s.putOrder, cid)
+	}
+	return cid, nil
+}
+
+func (s *ContentAddressedStore) Get(ctx context.Context, cid string) (StoredPost, error) {
+	s.mu.RLock()
+	block, ok := s.blocks[cid]
+	tombstonedAt, tombstoned := s.tombstoned[cid]
+	tombstonedBy := s.tombstonedBy[cid]
+	s.mu.RUnlock()
+	if !ok {
+		return StoredPost{}, ErrNotFound
+	}
+
+	p, err := decodePostDagCBOR(block)
+	if err != nil {
+		return StoredPost{}, fmt.Errorf("storage: get %s: %w", cid, err)
+	}
+	return StoredPost{CID: cid, Post: p, Tombstoned: tombstoned, TombstonedAt: tombstonedAt, TombstonedBy: tombstonedBy}, nil
+}
+
+func (s *ContentAddressedStore) ListByThread(ctx context.Context, threadID, cursor string, limit int) ([]StoredPost, string, error) {
+	s.mu.RLock()
+	cids := append([]string(nil), s.byThread[threadID]...)
+	s.mu.RUnlock()
+
+	start := 0
+	if cursor != "" {
+		idx := -1
+		for i, c := range cids {
+			if c == cursor {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, "", ErrNotFound
+		}
+		start = idx + 1
+	}
+
+	end := start + limit
+	if limit <= 0 || end > len(cids) {
+		end = len(cids)
+	}
+
+	posts := make([]StoredPost, 0, end-start)
+	for _, cid := range cids[start:end] {
+		sp, err := s.Get(ctx, cid)
+		if err != nil {
+			return nil, "", err
+		}
+		posts = append(posts, sp)
+	}
+
+	next := ""
+	if end < len(cids) {
+		next = cids[end-1]
+	}
+	return posts, next, nil
+}
+
+func (s *ContentAddressedStore) Tombstone(ctx context.Context, oldCID, newCID, tombstonedAt, tombstonedBy string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blocks[oldCID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := s.blocks[newCID]; !ok {
+		return ErrNotFound
+	}
+	s.tombstoned[newCID] = tombstonedAt
+	s.tombstonedBy[newCID] = tombstonedBy
+	return nil
+}
+
+// ListTombstoned returns every tombstoned post in s, in Put order.
+func (s *ContentAddressedStore) ListTombstoned(ctx context.Context) ([]StoredPost, error) {
+	s.mu.RLock()
+	cids := make([]string, 0, len(s.tombstoned))
+	for _, cid := range s.putOrder {
+		if _, ok := s.tombstoned[cid]; ok {
+			cids = append(cids, cid)
+		}
+	}
+	s.mu.RUnlock()
+
+	out := make([]StoredPost, 0, len(cids))
+	for _, cid := range cids {
+		sp, err := s.Get(ctx, cid)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sp)
+	}
+	return out, nil
+}
+
+// ExportCAR writes every post in threadID to w as a CAR archive rooted at
+// the thread's most recent post, for backup or migration to another
+// node. See bbs/storage/car for the archive format.
+func (s *ContentAddressedStore) ExportCAR(ctx context.Context, w io.Writer, threadID string) error {
+	s.mu.RLock()
+	cids := append([]string(nil), s.byThread[threadID]...)
+	s.mu.RUnlock()
+
+	var roots []string
+	if len(cids) > 0 {
+		roots = []string{cids[len(cids)-1]}
+	}
+
+	cw, err := car.NewWriter(w, roots)
+	if err != nil {
+		return fmt.Errorf("storage: export thread %s: %w", threadID, err)
+	}
+	for _, cid := range cids {
+		s.mu.RLock()
+		block := s.blocks[cid]
+		s.mu.RUnlock()
+		if _, err := cw.PutBlock(cid, block); err != nil {
+			return fmt.Errorf("storage: export thread %s: %w", threadID, err)
+		}
+	}
+	if err := cw.Flush(); err != nil {
+		return fmt.Errorf("storage: export thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// ImportCAR reads a CAR archive previously written by ExportCAR (or
+// another node's equivalent) and installs every block it contains,
+// recomputing each post's thread membership from its own ThreadID field
+// rather than trusting the archive's block order.
+func (s *ContentAddressedStore) ImportCAR(ctx context.Context, r io.Reader) error {
+	cr, err := car.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("storage: import: %w", err)
+	}
+	for {
+		cid, data, err := cr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("storage: import: %w", err)
+		}
+
+		p, err := decodePostDagCBOR(data)
+		if err != nil {
+			return fmt.Errorf("storage: import block %s: %w", cid, err)
+		}
+
+		s.mu.Lock()
+		if _, exists := s.blocks[cid]; !exists {
+			s.blocks[cid] = data
+			s.byThread[p.ThreadID] = append(s.byThread[p.ThreadID], cid)
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is a PostStore backed by plain in-process maps, for tests
+// that don't want ContentAddressedStore's dag-cbor encode/decode round
+// trip in the way of asserting on post content directly.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	byCID    map[string]StoredPost
+	byThread map[string][]string // threadID -> CIDs in Put order
+	putOrder []string            // every CID ever Put/Seeded, in that order
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		byCID:    make(map[string]StoredPost),
+		byThread: make(map[string][]string),
+	}
+}
+
+func (m *MemoryStore) Put(ctx context.Context, p Post) (string, error) {
+	cid, _ := computeCID(p)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.byCID[cid]; !exists {
+		m.byCID[cid] = StoredPost{CID: cid, Post: p}
+		m.byThread[p.ThreadID] = append(m.byThread[p.ThreadID], cid)
+		m.putOrder = append(m.putOrder, cid)
+	}
+	return cid, nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, cid string) (StoredPost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sp, ok := m.byCID[cid]
+	if !ok {
+		return StoredPost{}, ErrNotFound
+	}
+	return sp, nil
+}
+
+func (m *MemoryStore) ListByThread(ctx context.Context, threadID, cursor string, limit int) ([]StoredPost, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cids := m.byThread[threadID]
+	start := 0
+	if cursor != "" {
+		idx := -1
+		for i, c := range cids {
+			if c == cursor {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, "", ErrNotFound
+		}
+		start = idx + 1
+	}
+
+	end := start + limit
+	if end > len(cids) || limit <= 0 {
+		end = len(cids)
+	}
+
+	posts := make([]StoredPost, 0, end-start)
+	for _, c := range cids[start:end] {
+		posts = append(posts, m.byCID[c])
+	}
+
+	next := ""
+	if end < len(cids) {
+		next = cids[end-1]
+	}
+	return posts, next, nil
+}
+
+func (m *MemoryStore) Tombstone(ctx context.Context, oldCID, newCID, tombstonedAt, tombstonedBy string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byCID[oldCID]; !ok {
+		return ErrNotFound
+	}
+	sp, ok := m.byCID[newCID]
+	if !ok {
+		return ErrNotFound
+	}
+	sp.Tombstoned = true
+	sp.TombstonedAt = tombstonedAt
+	sp.TombstonedBy = tombstonedBy
+	m.byCID[newCID] = sp
+	return nil
+}
+
+// ListTombstoned returns every tombstoned post in m, in Put order.
+func (m *MemoryStore) ListTombstoned(ctx context.Context) ([]StoredPost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []StoredPost
+	for _, cid := range m.putOrder {
+		if sp := m.byCID[cid]; sp.Tombstoned {
+			out = append(out, sp)
+		}
+	}
+	return out, nil
+}
+
+// Seed directly installs sp under its own CID, bypassing Put's
+// content-derived CID assignment. Tests use this to seed a post under a
+// hand-picked CID without needing it to match computeCID's output.
+func (m *MemoryStore) Seed(sp StoredPost) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.byCID[sp.CID]; !exists {
+		m.byThread[sp.Post.ThreadID] = append(m.byThread[sp.Post.ThreadID], sp.CID)
+		m.putOrder = append(m.putOrder, sp.CID)
+	}
+	m.byCID[sp.CID] = sp
+}
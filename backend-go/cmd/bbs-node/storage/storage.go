@@ -0,0 +1,85 @@
+// Package storage defines the persistence abstraction for cmd/bbs-node's
+// posts API: a single PostStore interface two backends implement, so
+// api_v1_posts_post.go/api_v1_posts_edit.go/api_v1_posts_tombstone.go can
+// be wired to either without duplicating their request/response/signature
+// handling.
+//
+// This is deliberately separate from bbs/storage.Storage, which already
+// persists a different model (bbs/types.Post) over a live flexipfs.Client;
+// that package is the "real deployment" storage layer for boards/threads,
+// while this one is scoped to cmd/bbs-node's own posts prototype until it
+// gets consolidated into bbs/storage. Import it under an alias (e.g.
+// poststore) at call sites that also import bbs/storage, to keep the two
+// apart.
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// Post is the durable content a PostStore saves under a CID: everything
+// about a post except that CID itself, which Put derives from this
+// content (see cid.go).
+type Post struct {
+	ThreadID      string
+	ParentPostCID string // empty if this post doesn't reply to another
+	AuthorPubKey  string
+	DisplayName   string
+	BodyFormat    string
+	BodyContent   string
+	CreatedAt     string
+	EditedAt      string // empty unless this revision is the result of an edit
+}
+
+// StoredPost is a Post as returned by Get/ListByThread: its content plus
+// the CID Put assigned it and its tombstone state.
+type StoredPost struct {
+	CID          string
+	Post         Post
+	Tombstoned   bool
+	TombstonedAt string
+
+	// TombstonedBy identifies who tombstoned this post: the author's
+	// AuthorPubKey for a self-tombstone, or "moderator:<keyId>" when a
+	// board moderator capability key tombstoned it instead. Empty unless
+	// Tombstoned is true.
+	TombstonedBy string
+}
+
+// ErrNotFound is returned by Get and Tombstone for a CID the store has no
+// record of.
+var ErrNotFound = errors.New("storage: not found")
+
+// PostStore persists posts content-addressed by CID. Implementations must
+// be safe for concurrent use.
+type PostStore interface {
+	// Put derives p's CID from its content and stores it, returning that
+	// CID. Put is idempotent: calling it twice with identical content
+	// returns the same CID both times and does not create a second
+	// record.
+	Put(ctx context.Context, p Post) (cid string, err error)
+
+	// Get returns the post stored under cid, or ErrNotFound.
+	Get(ctx context.Context, cid string) (StoredPost, error)
+
+	// ListByThread returns up to limit posts for threadID in the order
+	// they were Put, resuming after cursor (the CID of the last post
+	// returned by a previous call, or "" to start from the beginning).
+	// next is the cursor to pass to resume after this page, or "" if
+	// there are no more posts.
+	ListByThread(ctx context.Context, threadID, cursor string, limit int) (posts []StoredPost, next string, err error)
+
+	// Tombstone marks newCID (already Put, typically an empty-content
+	// marker post) as the tombstone for oldCID as of tombstonedAt,
+	// attributed to tombstonedBy (the author's pubkey, or
+	// "moderator:<keyId>"). It does not alter oldCID's own record;
+	// Get(oldCID) still returns its original content, same as before
+	// tombstoning.
+	Tombstone(ctx context.Context, oldCID, newCID, tombstonedAt, tombstonedBy string) error
+
+	// ListTombstoned returns every post Tombstone has marked, for callers
+	// (currently just an archive sync path) that want to sweep tombstone
+	// events without already knowing which CIDs to look for.
+	ListTombstoned(ctx context.Context) ([]StoredPost, error)
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// postStoreBackends returns one PostStore instance per implementation of
+// the interface, plus a teardown for any that need closing, so
+// round-trip tests exercise all three backends through the exact same
+// assertions rather than duplicating them per type.
+func postStoreBackends(t *testing.T) []struct {
+	name  string
+	store PostStore
+} {
+	t.Helper()
+	bolt, err := OpenBoltStore(filepath.Join(t.TempDir(), "posts.db"))
+	if err != nil {
+		t.Fatalf("OpenBoltStore: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return []struct {
+		name  string
+		store PostStore
+	}{
+		{"MemoryStore", NewMemoryStore()},
+		{"ContentAddressedStore", NewContentAddressedStore()},
+		{"BoltStore", bolt},
+	}
+}
+
+func TestPostStore_PutIsIdempotentByContent(t *testing.T) {
+	for _, b := range postStoreBackends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := Post{ThreadID: "t1", AuthorPubKey: "pub", BodyContent: "hi", CreatedAt: "2025-01-01T00:00:00Z"}
+
+			cid1, err := b.store.Put(ctx, p)
+			if err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			cid2, err := b.store.Put(ctx, p)
+			if err != nil {
+				t.Fatalf("Put (again): %v", err)
+			}
+			if cid1 != cid2 {
+				t.Fatalf("Put on identical content returned different CIDs: %q vs %q", cid1, cid2)
+			}
+
+			sp, err := b.store.Get(ctx, cid1)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if sp.Post != p {
+				t.Fatalf("Get returned %+v, want %+v", sp.Post, p)
+			}
+
+			posts, _, err := b.store.ListByThread(ctx, "t1", "", 10)
+			if err != nil {
+				t.Fatalf("ListByThread: %v", err)
+			}
+			if len(posts) != 1 || posts[0].CID != cid1 {
+				t.Fatalf("ListByThread = %+v, want a single post with CID %q", posts, cid1)
+			}
+		})
+	}
+}
+
+func TestPostStore_ListByThreadPagesAndTombstones(t *testing.T) {
+	for _, b := range postStoreBackends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := context.Background()
+			var cids []string
+			for i := 0; i < 3; i++ {
+				cid, err := b.store.Put(ctx, Post{ThreadID: "t1", BodyContent: string(rune('a' + i)), CreatedAt: "2025-01-01T00:00:00Z"})
+				if err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+				cids = append(cids, cid)
+			}
+
+			page1, next, err := b.store.ListByThread(ctx, "t1", "", 2)
+			if err != nil {
+				t.Fatalf("ListByThread page1: %v", err)
+			}
+			if len(page1) != 2 || next == "" {
+				t.Fatalf("page1 = %+v, next = %q", page1, next)
+			}
+			page2, next2, err := b.store.ListByThread(ctx, "t1", next, 2)
+			if err != nil {
+				t.Fatalf("ListByThread page2: %v", err)
+			}
+			if len(page2) != 1 || next2 != "" {
+				t.Fatalf("page2 = %+v, next2 = %q", page2, next2)
+			}
+
+			tombstone, err := b.store.Put(ctx, Post{ThreadID: "t1", BodyContent: "", CreatedAt: "2025-01-02T00:00:00Z"})
+			if err != nil {
+				t.Fatalf("Put tombstone: %v", err)
+			}
+			if err := b.store.Tombstone(ctx, cids[0], tombstone, "2025-01-02T00:00:00Z", "author1"); err != nil {
+				t.Fatalf("Tombstone: %v", err)
+			}
+			sp, err := b.store.Get(ctx, tombstone)
+			if err != nil {
+				t.Fatalf("Get tombstone: %v", err)
+			}
+			if !sp.Tombstoned || sp.TombstonedAt != "2025-01-02T00:00:00Z" || sp.TombstonedBy != "author1" {
+				t.Fatalf("tombstone post = %+v", sp)
+			}
+
+			if err := b.store.Tombstone(ctx, "missing-cid", tombstone, "2025-01-02T00:00:00Z", "author1"); err != ErrNotFound {
+				t.Fatalf("Tombstone(missing oldCID) = %v, want ErrNotFound", err)
+			}
+
+			tombstoned, err := b.store.ListTombstoned(ctx)
+			if err != nil {
+				t.Fatalf("ListTombstoned: %v", err)
+			}
+			if len(tombstoned) != 1 || tombstoned[0].CID != tombstone {
+				t.Fatalf("ListTombstoned = %+v, want a single post with CID %q", tombstoned, tombstone)
+			}
+		})
+	}
+}
+
+// threadStoreBackends mirrors postStoreBackends for ThreadStore.
+func threadStoreBackends(t *testing.T) []struct {
+	name  string
+	store ThreadStore
+} {
+	t.Helper()
+	bolt, err := OpenBoltThreadStore(filepath.Join(t.TempDir(), "threads.db"))
+	if err != nil {
+		t.Fatalf("OpenBoltThreadStore: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return []struct {
+		name  string
+		store ThreadStore
+	}{
+		{"MemoryThreadStore", NewMemoryThreadStore()},
+		{"BoltThreadStore", bolt},
+	}
+}
+
+func TestThreadStore_PutGetDeleteRoundTrip(t *testing.T) {
+	for _, b := range threadStoreBackends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, ok := b.store.Get(ctx, "board-1"); ok {
+				t.Fatal("Get on an unknown board should return ok=false")
+			}
+
+			if err := b.store.Put(ctx, "board-1", Thread{ID: "t1", Title: "hello"}); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if err := b.store.Put(ctx, "board-1", Thread{ID: "t2", Title: "world"}); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			got, ok := b.store.Get(ctx, "board-1")
+			if !ok || len(got) != 2 || got[0].ID != "t1" || got[1].ID != "t2" {
+				t.Fatalf("Get = %+v, ok=%v, want [t1 t2] in Put order", got, ok)
+			}
+
+			ids, err := b.store.List(ctx)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(ids) != 1 || ids[0] != "board-1" {
+				t.Fatalf("List = %v, want [board-1]", ids)
+			}
+
+			if err := b.store.Delete(ctx, "board-1", "t1"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			got, ok = b.store.Get(ctx, "board-1")
+			if !ok || len(got) != 1 || got[0].ID != "t2" {
+				t.Fatalf("Get after Delete = %+v, ok=%v, want [t2]", got, ok)
+			}
+		})
+	}
+}
+
+func TestThreadStore_WatchReceivesUpdates(t *testing.T) {
+	for _, b := range threadStoreBackends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ch := b.store.Watch(ctx, "board-1")
+			if err := b.store.Put(ctx, "board-1", Thread{ID: "t1", Title: "hello"}); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			select {
+			case got := <-ch:
+				if len(got) != 1 || got[0].ID != "t1" {
+					t.Fatalf("Watch delivered %+v, want [t1]", got)
+				}
+			default:
+				t.Fatal("Watch channel had no update after Put")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// Thread is the durable content a ThreadStore keeps for one thread within
+// a board: just enough to render a board's thread listing. cmd/bbs-node's
+// own threadSummary is the JSON-facing equivalent of this type; callers
+// convert between the two at the package boundary.
+type Thread struct {
+	ID    string
+	Title string
+}
+
+// ThreadStore persists a board's threads, keyed by board ID. Unlike
+// PostStore, a Thread isn't content-addressed: handleCreateThread derives
+// its ID from the thread's sign payload rather than from Thread's own
+// content, so a ThreadStore just keeps each board's threads in Put order
+// rather than hashing them into a CID. Implementations must be safe for
+// concurrent use.
+type ThreadStore interface {
+	// Put appends t to boardID's thread list. It does not de-duplicate by
+	// t.ID; callers are responsible for only calling Put once per thread.
+	Put(ctx context.Context, boardID string, t Thread) error
+
+	// Get returns boardID's threads in Put order. ok is false if boardID
+	// has no threads at all, distinct from an empty list.
+	Get(ctx context.Context, boardID string) (threads []Thread, ok bool)
+
+	// Delete removes threadID from boardID's list. It is not an error to
+	// delete a thread that doesn't exist.
+	Delete(ctx context.Context, boardID, threadID string) error
+
+	// List returns every board ID this store currently has at least one
+	// thread for.
+	List(ctx context.Context) ([]string, error)
+
+	// Watch returns a channel that receives boardID's current thread list
+	// every time Put or Delete changes it. Delivery is best-effort (a slow
+	// receiver may miss an intermediate update, same as
+	// config.TrustedIndexersStore.Subscribe); the channel is abandoned,
+	// not closed, once ctx is done.
+	Watch(ctx context.Context, boardID string) <-chan []Thread
+}
+
+// MemoryThreadStore is a ThreadStore backed by plain in-process maps, the
+// current (and only, pre-this-package) behavior of cmd/bbs-node's
+// boardThreads global — used by tests and as the default for the
+// prototype posts/threads API.
+type MemoryThreadStore struct {
+	mu          sync.RWMutex
+	byBoard     map[string][]Thread
+	subscribers map[string]map[chan []Thread]struct{}
+}
+
+// NewMemoryThreadStore returns an empty MemoryThreadStore.
+func NewMemoryThreadStore() *MemoryThreadStore {
+	return &MemoryThreadStore{
+		byBoard:     make(map[string][]Thread),
+		subscribers: make(map[string]map[chan []Thread]struct{}),
+	}
+}
+
+func (m *MemoryThreadStore) Put(ctx context.Context, boardID string, t Thread) error {
+	m.mu.Lock()
+	m.byBoard[boardID] = append(m.byBoard[boardID], t)
+	snapshot := append([]Thread(nil), m.byBoard[boardID]...)
+	m.mu.Unlock()
+	m.notify(boardID, snapshot)
+	return nil
+}
+
+func (m *MemoryThreadStore) Get(ctx context.Context, boardID string) ([]Thread, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	threads, ok := m.byBoard[boardID]
+	if !ok {
+		return nil, false
+	}
+	out := make([]Thread, len(threads))
+	copy(out, threads)
+	return out, true
+}
+
+func (m *MemoryThreadStore) Delete(ctx context.Context, boardID, threadID string) error {
+	m.mu.Lock()
+	kept := make([]Thread, 0, len(m.byBoard[boardID]))
+	for _, t := range m.byBoard[boardID] {
+		if t.ID != threadID {
+			kept = append(kept, t)
+		}
+	}
+	m.byBoard[boardID] = kept
+	snapshot := append([]Thread(nil), kept...)
+	m.mu.Unlock()
+	m.notify(boardID, snapshot)
+	return nil
+}
+
+func (m *MemoryThreadStore) List(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.byBoard))
+	for id := range m.byBoard {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m *MemoryThreadStore) Watch(ctx context.Context, boardID string) <-chan []Thread {
+	ch := make(chan []Thread, 1)
+	m.mu.Lock()
+	if m.subscribers[boardID] == nil {
+		m.subscribers[boardID] = make(map[chan []Thread]struct{})
+	}
+	m.subscribers[boardID][ch] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		delete(m.subscribers[boardID], ch)
+		m.mu.Unlock()
+	}()
+	return ch
+}
+
+func (m *MemoryThreadStore) notify(boardID string, threads []Thread) {
+	m.mu.RLock()
+	subs := m.subscribers[boardID]
+	chans := make([]chan []Thread, 0, len(subs))
+	for ch := range subs {
+		chans = append(chans, ch)
+	}
+	m.mu.RUnlock()
+	for _, ch := range chans {
+		select {
+		case ch <- threads:
+		default:
+		}
+	}
+}
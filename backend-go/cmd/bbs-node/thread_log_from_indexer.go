@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+
+	"flex-bbs/backend-go/internal/indexer"
+	"flex-bbs/backend-go/internal/indexer/logchain"
+)
+
+// ThreadLogFromBoardLog converts a board's BoardLogEntry chain into the
+// ThreadLogEntry list GetThreadResponse publishes, refusing to emit any
+// entry at or after the first one that fails logchain verification against
+// pub (bad signature, broken PrevHash, or a fork). This is what
+// "実データソースが決まり次第、ThreadLog/Posts を組み立てる" in
+// defaultThreadGetter.GetThread resolves to once a ThreadGetter backed by
+// internal/indexer is wired in: verify first, publish only what verifies.
+func ThreadLogFromBoardLog(entries []indexer.BoardLogEntry, pub ed25519.PublicKey) []ThreadLogEntry {
+	v := logchain.NewVerifier()
+	log := make([]ThreadLogEntry, 0, len(entries))
+	for i := range entries {
+		if err := v.Verify(context.Background(), entries[:i+1], pub); err != nil {
+			break
+		}
+		log = append(log, ThreadLogEntry{
+			SeqNum:    entries[i].SeqNum,
+			Op:        entries[i].Operation,
+			CreatedAt: entries[i].Timestamp.Format("2006-01-02T15:04:05.000000000Z07:00"),
+		})
+	}
+	return log
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flex-bbs/backend-go/bbs/signature"
+	"flex-bbs/backend-go/internal/indexer"
+	"flex-bbs/backend-go/internal/indexer/logchain"
+)
+
+func TestThreadLogFromBoardLog_StopsAtFirstUnverifiableEntry(t *testing.T) {
+	db, err := indexer.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer db.Close()
+
+	_, privStr, err := signature.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	priv, err := signature.ParsePrivateKey(privStr)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	pub, err := signature.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatalf("PublicKeyFromPrivate: %v", err)
+	}
+
+	a := logchain.NewAppender(db)
+	ctx := context.Background()
+	var entries []indexer.BoardLogEntry
+	for _, op := range []string{"create_thread", "create_post", "create_post"} {
+		e := indexer.BoardLogEntry{
+			Timestamp: time.Now().UTC(),
+			Operation: op,
+			EntityID:  "t1",
+			Data:      "{}",
+		}
+		if err := a.Append(ctx, &e, priv); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if got := ThreadLogFromBoardLog(entries, pub); len(got) != 3 {
+		t.Fatalf("all-valid chain: len(ThreadLog) = %d, want 3", len(got))
+	}
+
+	entries[1].Data = `{"tampered":true}`
+	got := ThreadLogFromBoardLog(entries, pub)
+	if len(got) != 1 {
+		t.Fatalf("tampered middle entry: len(ThreadLog) = %d, want 1", len(got))
+	}
+	if got[0].Op != "create_thread" {
+		t.Fatalf("ThreadLog[0].Op = %q, want %q", got[0].Op, "create_thread")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"flex-bbs/backend-go/bbs/ratelimit"
+)
+
+// writeLimits bundles the replay/flood defenses shared by handleCreatePost,
+// handleEditPost, and handlePostActions' tombstone branch: a token bucket
+// per authorPubKey, a secondary token bucket per client IP (so a single
+// leaked key can't be worked around by spraying writes from many keys off
+// one host, and vice versa), and a NonceStore so the exact same signed
+// payload can't be replayed once it's been accepted. The zero value has
+// every field nil, which checkWriteLimits treats as "unconfigured, allow
+// everything" — same convention as ratelimit.Limiter's zero Config.
+type writeLimits struct {
+	byAuthor *ratelimit.Limiter
+	byIP     *ratelimit.Limiter
+	nonces   ratelimit.NonceStore
+}
+
+// configureWriteLimits installs cfg (author/IP token buckets) and nonces
+// (replay detection) onto h. Called once from main, after flags are
+// parsed; tests that don't call it get the zero writeLimits, i.e. no
+// limiting.
+func (h *postsHandlers) configureWriteLimits(cfg ratelimit.Config, nonces ratelimit.NonceStore) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limits = writeLimits{
+		byAuthor: ratelimit.New(cfg),
+		byIP:     ratelimit.New(cfg),
+		nonces:   nonces,
+	}
+}
+
+// checkWriteLimits enforces h's configured rate limits and replay check
+// for a write from authorPubKey/signature arriving over r, writing the
+// appropriate 429/409 response and returning ok=false if the write must be
+// rejected. nonces retains signature as a seen nonce until
+// replayWindowEnd(): the server's current time plus replayWindowWidth. The
+// signed payload's own timestamps play no part in that window.
+func (h *postsHandlers) checkWriteLimits(w http.ResponseWriter, r *http.Request, authorPubKey, signature string) (ok bool) {
+	h.mu.RLock()
+	limits := h.limits
+	h.mu.RUnlock()
+
+	if limits.byIP != nil && !limits.byIP.Allow(clientIP(r)) {
+		writeJSONError(w, http.StatusTooManyRequests, "rate_limited", "too many writes from this client, slow down")
+		return false
+	}
+	if limits.byAuthor != nil && !limits.byAuthor.Allow(authorPubKey) {
+		writeJSONError(w, http.StatusTooManyRequests, "rate_limited", "too many writes for this author, slow down")
+		return false
+	}
+	if limits.nonces != nil && signature != "" {
+		seen, err := limits.nonces.CheckAndStore(r.Context(), signature, replayWindowEnd())
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "store_error", err.Error())
+			return false
+		}
+		if seen {
+			writeJSONError(w, http.StatusConflict, "replayed_write", "this signed write has already been submitted")
+			return false
+		}
+	}
+	return true
+}
+
+// replayWindowWidth bounds how long checkWriteLimits remembers a signature
+// as "already submitted". It only needs to outlast how long a client might
+// plausibly retry a write it suspects didn't land, not forever.
+const replayWindowWidth = 24 * time.Hour
+
+func replayWindowEnd() time.Time {
+	return time.Now().Add(replayWindowWidth)
+}
+
+// clientIP extracts the caller's address for per-IP rate limiting,
+// preferring the first hop recorded in X-Forwarded-For (set by a reverse
+// proxy in front of bbs-node) over r.RemoteAddr, which would otherwise
+// always be the proxy's own address.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		if first := strings.TrimSpace(strings.SplitN(fwd, ",", 2)[0]); first != "" {
+			return first
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,19 +3,31 @@ package indexer
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // APIHandler はindexerの検索APIを処理します。
 type APIHandler struct {
-	db DB
+	db       DB
+	searcher Searcher
 }
 
-// NewAPIHandler は新しいAPIハンドラーを作成します。
+// NewAPIHandler は新しいAPIハンドラーを作成します。検索は既定では db自身の
+// SearchPosts/SearchThreads (SQL LIKE) を使う。WithSearcher で NewFTSSearcher
+// などの専用バックエンドに差し替えられる。
 func NewAPIHandler(db DB) *APIHandler {
-	return &APIHandler{db: db}
+	return &APIHandler{db: db, searcher: db}
+}
+
+// WithSearcher は検索バックエンドを差し替える。チェーン用に自身を返す。
+func (h *APIHandler) WithSearcher(s Searcher) *APIHandler {
+	h.searcher = s
+	return h
 }
 
 // RegisterRoutes はHTTPルーティングを登録します。
@@ -90,8 +102,57 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	})
 }
 
+// parseSearchDateParam parses an RFC3339 query-string value into a *time.Time,
+// returning nil (no filter) for an empty or unparseable value rather than
+// failing the whole search request over a malformed date_from/date_to.
+func parseSearchDateParam(v string) *time.Time {
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// parseListOptsParams parses the query-string params handleBoardThreads/
+// handleThreadPosts share for keyset pagination: limit, after, before, and
+// order=asc|desc (default asc). An unparseable after/before is reported as
+// an error rather than silently ignored, since (unlike a malformed search
+// date filter) a bad cursor means the caller can't reach the page they
+// asked for at all.
+func parseListOptsParams(q url.Values) (ListOpts, error) {
+	opts := ListOpts{
+		After:  Cursor(q.Get("after")),
+		Before: Cursor(q.Get("before")),
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil {
+			opts.Limit = v
+		}
+	}
+	if q.Get("order") == "desc" {
+		opts.Order = OrderDesc
+	}
+	if _, _, err := opts.After.decode(); err != nil {
+		return ListOpts{}, fmt.Errorf("invalid after cursor: %w", err)
+	}
+	if _, _, err := opts.Before.decode(); err != nil {
+		return ListOpts{}, fmt.Errorf("invalid before cursor: %w", err)
+	}
+	return opts, nil
+}
+
 // handleSearchPosts は投稿検索エンドポイントを処理します。
-// GET /api/v1/search/posts?query=...&board_id=...&thread_id=...&author_id=...&limit=&offset=
+// GET /api/v1/search/posts?q=...&query=...&mode=&order_by=&board_id=...&thread_id=...&author_id=...&date_from=&date_to=&prefix=&snippet=&highlight=&limit=&offset=&after=&before=
+//
+// q, when present, is ParseSearchQuery's query-language string (e.g.
+// `author:ed25519:abc board:general "exact phrase" -spam`); its filters are
+// merged onto the same request the legacy per-field params populate, and
+// the request is routed to SearchPostsStructured instead of SearchPosts.
+// The legacy params remain a fully-functional deprecated fallback when q is
+// absent.
 func (h *APIHandler) handleSearchPosts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -100,10 +161,19 @@ func (h *APIHandler) handleSearchPosts(w http.ResponseWriter, r *http.Request) {
 
 	q := r.URL.Query()
 	req := &SearchPostsRequest{
-		Query:    q.Get("query"),
-		BoardID:  q.Get("board_id"),
-		ThreadID: q.Get("thread_id"),
-		AuthorID: q.Get("author_id"),
+		Query:     q.Get("query"),
+		Mode:      SearchMode(q.Get("mode")),
+		OrderBy:   q.Get("order_by"),
+		BoardID:   q.Get("board_id"),
+		ThreadID:  q.Get("thread_id"),
+		AuthorID:  q.Get("author_id"),
+		DateFrom:  parseSearchDateParam(q.Get("date_from")),
+		DateTo:    parseSearchDateParam(q.Get("date_to")),
+		Prefix:    q.Get("prefix") == "true",
+		Snippet:   q.Get("snippet") == "true",
+		Highlight: q.Get("highlight") == "true",
+		After:     Cursor(q.Get("after")),
+		Before:    Cursor(q.Get("before")),
 	}
 
 	if limitStr := q.Get("limit"); limitStr != "" {
@@ -117,7 +187,24 @@ func (h *APIHandler) handleSearchPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	resp, err := h.db.SearchPosts(r.Context(), req)
+	structured := false
+	if raw := q.Get("q"); raw != "" {
+		parsed, err := ParseSearchQuery(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		parsed.ApplyToSearchPostsRequest(req)
+		structured = true
+	}
+
+	var resp *SearchPostsResponse
+	var err error
+	if structured {
+		resp, err = h.searcher.SearchPostsStructured(r.Context(), req)
+	} else {
+		resp, err = h.searcher.SearchPosts(r.Context(), req)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -126,7 +213,10 @@ func (h *APIHandler) handleSearchPosts(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleSearchThreads はスレッド検索エンドポイントを処理します。
-// GET /api/v1/search/threads?query=...&board_id=...&limit=&offset=
+// GET /api/v1/search/threads?q=...&query=...&mode=&order_by=&board_id=...&author_id=...&date_from=&date_to=&prefix=&snippet=&highlight=&viewer_id=&limit=&offset=&after=&before=
+//
+// q behaves as documented on handleSearchPosts, routing to
+// SearchThreadsStructured when present.
 func (h *APIHandler) handleSearchThreads(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -135,8 +225,19 @@ func (h *APIHandler) handleSearchThreads(w http.ResponseWriter, r *http.Request)
 
 	q := r.URL.Query()
 	req := &SearchThreadsRequest{
-		Query:   q.Get("query"),
-		BoardID: q.Get("board_id"),
+		Query:     q.Get("query"),
+		Mode:      SearchMode(q.Get("mode")),
+		OrderBy:   q.Get("order_by"),
+		BoardID:   q.Get("board_id"),
+		AuthorID:  q.Get("author_id"),
+		DateFrom:  parseSearchDateParam(q.Get("date_from")),
+		DateTo:    parseSearchDateParam(q.Get("date_to")),
+		Prefix:    q.Get("prefix") == "true",
+		Snippet:   q.Get("snippet") == "true",
+		Highlight: q.Get("highlight") == "true",
+		ViewerID:  q.Get("viewer_id"),
+		After:     Cursor(q.Get("after")),
+		Before:    Cursor(q.Get("before")),
 	}
 	if limitStr := q.Get("limit"); limitStr != "" {
 		if v, err := strconv.Atoi(limitStr); err == nil {
@@ -149,7 +250,24 @@ func (h *APIHandler) handleSearchThreads(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	resp, err := h.db.SearchThreads(r.Context(), req)
+	structured := false
+	if raw := q.Get("q"); raw != "" {
+		parsed, err := ParseSearchQuery(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		parsed.ApplyToSearchThreadsRequest(req)
+		structured = true
+	}
+
+	var resp *SearchThreadsResponse
+	var err error
+	if structured {
+		resp, err = h.searcher.SearchThreadsStructured(r.Context(), req)
+	} else {
+		resp, err = h.searcher.SearchThreads(r.Context(), req)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -210,13 +328,20 @@ func (h *APIHandler) handleBoardThreads(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "missing board_id")
 		return
 	}
-	threads, err := h.db.ListThreadsByBoard(r.Context(), boardID)
+	opts, err := parseListOptsParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	threads, next, prev, err := h.db.ListThreadsByBoard(r.Context(), boardID, opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
-		"threads": threads,
+		"threads":     threads,
+		"next_cursor": next,
+		"prev_cursor": prev,
 	})
 }
 
@@ -256,13 +381,20 @@ func (h *APIHandler) handleThreadPosts(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing thread_id")
 		return
 	}
-	posts, err := h.db.ListPostsByThread(r.Context(), threadID)
+	opts, err := parseListOptsParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, prev, err := h.db.ListPostsByThread(r.Context(), threadID, opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
-		"posts": posts,
+		"posts":       posts,
+		"next_cursor": next,
+		"prev_cursor": prev,
 	})
 }
 
@@ -0,0 +1,218 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor is an opaque, base64-encoded pagination marker over a
+// (created_at, id) pair — the same composite key ListThreadsByBoard and
+// ListPostsByThread order their rows by. Callers should treat it as an
+// opaque token: pass it back verbatim as ListOpts.After/Before, never parse
+// or construct one by hand.
+type Cursor string
+
+// cursorKey is Cursor's decoded form.
+type cursorKey struct {
+	T  time.Time `json:"t"`
+	ID string    `json:"id"`
+}
+
+// newCursor encodes the (created_at, id) of one row into a Cursor.
+func newCursor(t time.Time, id string) Cursor {
+	b, err := json.Marshal(cursorKey{T: t, ID: id})
+	if err != nil {
+		// cursorKey only ever holds a time.Time and a string, both of
+		// which always marshal; a failure here would be a bug in this
+		// function, not bad input.
+		panic(fmt.Sprintf("indexer: encode cursor: %v", err))
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// decode recovers the (created_at, id) pair a Cursor was built from. An
+// empty Cursor decodes to the zero value with no error, so callers can
+// decode ListOpts.After/Before without a separate empty check.
+func (c Cursor) decode() (time.Time, string, error) {
+	if c == "" {
+		return time.Time{}, "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("decode cursor: %w", err)
+	}
+	var k cursorKey
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return time.Time{}, "", fmt.Errorf("decode cursor: %w", err)
+	}
+	return k.T, k.ID, nil
+}
+
+// Order controls the direction ListOpts walks the (created_at, id) index.
+type Order int
+
+const (
+	// OrderAsc lists oldest-first. The zero value, matching the ORDER BY
+	// created_at ASC every ListThreadsByBoard/ListPostsByThread caller
+	// already expected before keyset pagination existed.
+	OrderAsc Order = iota
+	// OrderDesc lists newest-first.
+	OrderDesc
+)
+
+// ListOpts configures keyset (cursor) pagination for ListThreadsByBoard and
+// ListPostsByThread. If both After and Before are set, After takes
+// precedence. A zero ListOpts lists the first page in OrderAsc.
+type ListOpts struct {
+	Limit  int
+	After  Cursor
+	Before Cursor
+	Order  Order
+}
+
+// defaultListLimit/maxListLimit mirror clampSearchPaging's defaults so
+// ListThreadsByBoard/ListPostsByThread's own pagination behaves the same
+// way search's LIMIT/OFFSET pagination always has.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 200
+)
+
+func clampListLimit(limit int) int {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return limit
+}
+
+// buildKeysetClause turns opts into a SQL fragment comparing the
+// (created_at, id) row value against opts.After/opts.Before (without a
+// leading "AND" — the caller joins it onto its own WHERE), the ORDER BY
+// direction to query in, and whether the fetched rows need reversing before
+// they're handed back to the caller. startIdx is the 1-indexed position of
+// the fragment's first bind placeholder (i.e. len(args-so-far)+1); sqlite's
+// dialect ignores it since every placeholder there is just "?".
+//
+// Before walks backwards from its cursor, so it fetches in the opposite
+// direction from opts.Order and relies on the caller reversing the page
+// (see keysetPage) to land on the Limit rows immediately preceding Before,
+// rather than the Limit oldest/newest rows satisfying the comparison.
+func buildKeysetClause(d dialect, startIdx int, opts ListOpts) (clause string, args []any, desc bool, reverse bool, err error) {
+	desc = opts.Order == OrderDesc
+
+	switch {
+	case opts.After != "":
+		at, aid, derr := opts.After.decode()
+		if derr != nil {
+			return "", nil, false, false, derr
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		clause = fmt.Sprintf("(created_at, id) %s (%s, %s)", op, d.placeholder(startIdx), d.placeholder(startIdx+1))
+		args = []any{at, aid}
+	case opts.Before != "":
+		bt, bid, derr := opts.Before.decode()
+		if derr != nil {
+			return "", nil, false, false, derr
+		}
+		op := "<"
+		if desc {
+			op = ">"
+		}
+		clause = fmt.Sprintf("(created_at, id) %s (%s, %s)", op, d.placeholder(startIdx), d.placeholder(startIdx+1))
+		args = []any{bt, bid}
+		desc = !desc
+		reverse = true
+	}
+	return clause, args, desc, reverse, nil
+}
+
+// appendSearchKeyset extends a SearchPosts/SearchThreads WHERE clause (where,
+// args already built from the request's query/board/author/date filters)
+// with the row-value comparison for req.After/req.Before, if either is set.
+// Search's existing ORDER BY is always created_at DESC, so the returned
+// opts always has Order: OrderDesc; see buildKeysetClause for what desc/
+// reverse mean to the caller. If neither cursor is set, where/args are
+// returned unchanged so the caller's existing LIMIT/OFFSET path still works.
+func appendSearchKeyset(d dialect, where []string, args []any, after, before Cursor) (newWhere []string, newArgs []any, desc, reverse bool, err error) {
+	clause, cargs, desc, reverse, err := buildKeysetClause(d, len(args)+1, ListOpts{After: after, Before: before, Order: OrderDesc})
+	if err != nil {
+		return nil, nil, false, false, err
+	}
+	if clause == "" {
+		return where, args, desc, reverse, nil
+	}
+	newWhere = append(append([]string{}, where...), clause)
+	newArgs = append(append([]any{}, args...), cargs...)
+	return newWhere, newArgs, desc, reverse, nil
+}
+
+// searchOffsetClause returns the trailing " OFFSET ?" SQL fragment for
+// SearchPosts/SearchThreads' deprecated offset-based pagination, or "" once
+// a request supplies After/Before — a keyset page has no well-defined
+// offset to apply.
+func searchOffsetClause(useKeyset bool) string {
+	if useKeyset {
+		return ""
+	}
+	return " OFFSET ?"
+}
+
+// postSearchResultCursorKey/threadSearchResultCursorKey extract the
+// (created_at, id) keysetPage needs to build SearchPostsResponse/
+// SearchThreadsResponse's NextCursor/PrevCursor.
+func postSearchResultCursorKey(p PostSearchResult) (time.Time, string) {
+	return p.CreatedAt, p.ID
+}
+
+func threadSearchResultCursorKey(th ThreadSearchResult) (time.Time, string) {
+	return th.CreatedAt, th.ID
+}
+
+// keysetPage trims rows (fetched with one extra lookahead row beyond
+// opts.Limit) down to at most opts.Limit items in caller-facing order, and
+// derives the next/prev cursors ListOpts' pagination contract promises.
+// reverse is buildKeysetClause's return value for the same call; at
+// extracts the (created_at, id) key from one row.
+func keysetPage[T any](rows []T, opts ListOpts, reverse bool, at func(T) (time.Time, string)) (items []T, next, prev Cursor) {
+	limit := clampListLimit(opts.Limit)
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if reverse {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	items = rows
+	if len(items) == 0 {
+		return items, "", ""
+	}
+
+	firstT, firstID := at(items[0])
+	lastT, lastID := at(items[len(items)-1])
+	if reverse {
+		// Before: the lookahead row (if any) is further in the past
+		// (relative to Order), so it indicates an earlier previous page.
+		if hasMore {
+			prev = newCursor(firstT, firstID)
+		}
+		next = newCursor(lastT, lastID)
+	} else {
+		if hasMore {
+			next = newCursor(lastT, lastID)
+		}
+		if opts.After != "" {
+			prev = newCursor(firstT, firstID)
+		}
+	}
+	return items, next, prev
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -19,6 +20,10 @@ type DB interface {
 	GetLastSequence(ctx context.Context) (int64, error)
 	SetLastSequence(ctx context.Context, seq int64) error
 
+	// ログチェーン管理（直前に受理されたエントリのハッシュ。先頭は ""）
+	GetLastHash(ctx context.Context) (string, error)
+	SetLastHash(ctx context.Context, hash string) error
+
 	// Board 操作
 	CreateBoard(ctx context.Context, b *Board) error
 	UpdateBoard(ctx context.Context, b *Board) error
@@ -29,19 +34,77 @@ type DB interface {
 	CreateThread(ctx context.Context, t *Thread) error
 	UpdateThread(ctx context.Context, t *Thread) error
 	GetThread(ctx context.Context, id string) (*Thread, error)
-	ListThreadsByBoard(ctx context.Context, boardID string) ([]Thread, error)
+	// ListThreadsByBoard lists boardID's threads one keyset page at a time,
+	// ordered by (created_at, id); see ListOpts and Cursor.
+	ListThreadsByBoard(ctx context.Context, boardID string, opts ListOpts) (threads []Thread, next, prev Cursor, err error)
 	CloseThread(ctx context.Context, threadID string) error
+	// DeleteThread soft-deletes threadID and, in the same statement batch,
+	// every one of its posts that isn't already deleted, so a caller never
+	// sees a deleted thread whose posts still show up in SearchPosts. It
+	// also decrements the owning board's thread_count, mirroring how
+	// DeletePost decrements thread.post_count. See also Recalculate, which
+	// corrects any count drift this (or any other bookkeeping) accumulates
+	// over time.
+	DeleteThread(ctx context.Context, threadID string) error
 
 	// Post 操作
 	CreatePost(ctx context.Context, p *Post) error
 	UpdatePost(ctx context.Context, p *Post) error
 	GetPost(ctx context.Context, id string) (*Post, error)
-	ListPostsByThread(ctx context.Context, threadID string) ([]Post, error)
+	// ListPostsByThread lists threadID's posts one keyset page at a time,
+	// ordered by (created_at, id); see ListOpts and Cursor.
+	ListPostsByThread(ctx context.Context, threadID string, opts ListOpts) (posts []Post, next, prev Cursor, err error)
 	DeletePost(ctx context.Context, postID string) error
 
 	// 検索
+	// CountPosts/ListPosts and CountThreads/ListThreads are the unified
+	// query path PostQuery/ThreadQuery describe; SearchPosts/SearchThreads
+	// are now thin wrappers around them that translate a
+	// SearchPostsRequest/SearchThreadsRequest into the equivalent query and
+	// reshape the result into PostSearchResult/ThreadSearchResult.
+	CountPosts(ctx context.Context, q PostQuery) (int, error)
+	ListPosts(ctx context.Context, q PostQuery) (posts []Post, next, prev Cursor, err error)
+	CountThreads(ctx context.Context, q ThreadQuery) (int, error)
+	ListThreads(ctx context.Context, q ThreadQuery) (threads []Thread, next, prev Cursor, err error)
 	SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error)
 	SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error)
+	// SearchPostsStructured/SearchThreadsStructured are SearchPosts/
+	// SearchThreads' forward-looking entry point: the one a caller building
+	// a request from ParseSearchQuery (or setting BoardIDs/AuthorIDs/
+	// ThreadIDs/IncludeTerms/ExcludeTerms/PhraseTerms directly) should call.
+	// Against this plain-LIKE backend they share their translation of req
+	// into a PostQuery/ThreadQuery with SearchPosts/SearchThreads — both
+	// already honor every field on the request struct — but a Searcher
+	// backed by FTSSearcher tells them apart, compiling the structured
+	// fields into a real FTS5 boolean MATCH expression instead of a single
+	// quoted phrase.
+	SearchPostsStructured(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error)
+	SearchThreadsStructured(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error)
+
+	// 既読管理
+	// MarkRead records that userID has read threadID up through postID;
+	// SearchThreads(req with ViewerID set) uses the recorded last_read_at
+	// to fill in ThreadSearchResult's Unread/UnreadCount for that viewer.
+	MarkRead(ctx context.Context, userID, threadID, postID string) error
+	// MarkAllRead marks every thread in boardID read for userID as of now,
+	// for a "mark all read" action rather than reading one thread at a time.
+	MarkAllRead(ctx context.Context, userID, boardID string) error
+
+	// 再集計
+	// Recalculate scans threads (restricted to boardID if non-empty) and
+	// rewrites any post_count that has drifted from its actual non-deleted
+	// post count, acting as a recalculator to fix abnormalities that can
+	// accumulate across upgrades rather than trusting the incremental
+	// counters DeletePost/CreatePost maintain to never skew.
+	Recalculate(ctx context.Context, boardID string) (RecalcReport, error)
+
+	// MigrateTo brings the schema up to the given migration version (or the
+	// latest registered migration, if version is ""), applying any pending
+	// migrations under an exclusive lock. NewSQLiteDB/NewPostgresDB already
+	// call this with "" on open; callers only need it directly to inspect
+	// or roll a database forward to a specific version (see
+	// migrations.go).
+	MigrateTo(ctx context.Context, version string) error
 
 	// 終了処理
 	Close() error
@@ -49,17 +112,34 @@ type DB interface {
 
 // sqliteDB は SQLite ベースの DB 実装です。
 type sqliteDB struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *stmtCache
+	hooks []QueryHook
 }
 
 // sqliteTx はトランザクション中の DB 実装です。
 type sqliteTx struct {
-	tx *sql.Tx
+	tx    *sql.Tx
+	cache *stmtCache  // shared with the sqliteDB that started this tx; see txStmt
+	hooks []QueryHook // shared with the sqliteDB that started this tx
+
+	// spCounter names each nested WithTx's SAVEPOINT; shared across all
+	// sqliteTx values derived from the same top-level transaction so that
+	// savepoints nested two or more levels deep still get distinct names.
+	// See WithTx.
+	spCounter *int64
 }
 
 // NewSQLiteDB は SQLite を利用した新しい DB を作成します。
 // dsn には ":memory:" も利用できます。
 func NewSQLiteDB(dsn string) (DB, error) {
+	return NewSQLiteDBWithOptions(dsn, Options{})
+}
+
+// NewSQLiteDBWithOptions is NewSQLiteDB with the ability to attach
+// QueryHooks (see Options) to every statement the returned DB, and any
+// sqliteTx it opens, executes.
+func NewSQLiteDBWithOptions(dsn string, opts Options) (DB, error) {
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
@@ -70,65 +150,51 @@ func NewSQLiteDB(dsn string) (DB, error) {
 		return nil, fmt.Errorf("enable foreign_keys: %w", err)
 	}
 
-	s := &sqliteDB{db: db}
-	if err := s.initSchema(); err != nil {
+	s := &sqliteDB{db: db, cache: newStmtCache(db), hooks: opts.Hooks}
+	if err := s.MigrateTo(context.Background(), ""); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	// Prepare every cached statement now, while nothing else can be
+	// holding the pool's connection open inside a WithTx; see
+	// stmtCache.prepareAll.
+	if err := s.cache.prepareAll(); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
 	return s, nil
 }
 
-// スキーマ定義
-func (s *sqliteDB) initSchema() error {
-	schema := []string{
-		`CREATE TABLE IF NOT EXISTS boards (
-            id TEXT PRIMARY KEY,
-            name TEXT NOT NULL,
-            description TEXT NOT NULL,
-            created_at TIMESTAMP NOT NULL,
-            updated_at TIMESTAMP NOT NULL,
-            thread_count INTEGER NOT NULL DEFAULT 0
-        );`,
-		`CREATE TABLE IF NOT EXISTS threads (
-            id TEXT PRIMARY KEY,
-            board_id TEXT NOT NULL,
-            title TEXT NOT NULL,
-            author_id TEXT NOT NULL,
-            created_at TIMESTAMP NOT NULL,
-            updated_at TIMESTAMP NOT NULL,
-            post_count INTEGER NOT NULL DEFAULT 0,
-            is_closed INTEGER NOT NULL DEFAULT 0,
-            FOREIGN KEY(board_id) REFERENCES boards(id) ON DELETE CASCADE
-        );`,
-		`CREATE TABLE IF NOT EXISTS posts (
-            id TEXT PRIMARY KEY,
-            thread_id TEXT NOT NULL,
-            board_id TEXT NOT NULL,
-            author_id TEXT NOT NULL,
-            content TEXT NOT NULL,
-            created_at TIMESTAMP NOT NULL,
-            updated_at TIMESTAMP NOT NULL,
-            is_deleted INTEGER NOT NULL DEFAULT 0,
-            reply_to TEXT,
-            FOREIGN KEY(thread_id) REFERENCES threads(id) ON DELETE CASCADE,
-            FOREIGN KEY(board_id) REFERENCES boards(id) ON DELETE CASCADE
-        );`,
-		`CREATE TABLE IF NOT EXISTS log_state (
-            id INTEGER PRIMARY KEY CHECK (id = 1),
-            last_seq INTEGER NOT NULL
-        );`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_thread_id_created_at ON posts(thread_id, created_at);`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_board_id_created_at ON posts(board_id, created_at);`,
-		`CREATE INDEX IF NOT EXISTS idx_posts_author_id ON posts(author_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_threads_board_id_created_at ON threads(board_id, created_at);`,
-	}
-
-	for _, q := range schema {
-		if _, err := s.db.Exec(q); err != nil {
-			return fmt.Errorf("init schema: %w", err)
-		}
+// hookedStmt returns id's cached statement wrapped so calls through it fire
+// s.hooks; see hookedStmt and txStmt (sqliteTx's equivalent).
+func (s *sqliteDB) hookedStmt(id stmtID) (*hookedStmt, error) {
+	st, err := s.cache.stmt(id)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &hookedStmt{stmt: st, hooks: s.hooks, op: stmtOps[id], sql: stmtQueries[id]}, nil
+}
+
+// MigrateTo applies sqliteMigrations up to version (or all of them, if
+// version is ""); see migrations.go.
+func (s *sqliteDB) MigrateTo(ctx context.Context, version string) error {
+	return migrateTo(ctx, s.db, sqliteDialect, sqliteMigrations, version)
+}
+
+// MigrateTo on a transaction-scoped DB would need its own *sql.DB to take
+// the exclusive migration lock against, which sqliteTx doesn't have; call
+// it on the sqliteDB outside of WithTx instead.
+func (t *sqliteTx) MigrateTo(ctx context.Context, version string) error {
+	return fmt.Errorf("indexer: MigrateTo is not supported inside WithTx")
+}
+
+// VerifyMigrationChecksums re-checks every migration sqliteMigrations
+// records as applied against the SQL compiled into this binary. Not part
+// of the DB interface; callers that need it (e.g. a repair CLI) type-assert
+// for it the way cmd/bbs-node's ThreadGetter callers check for
+// ThreadETagGetter.
+func (s *sqliteDB) VerifyMigrationChecksums(ctx context.Context) ([]MigrationChecksumMismatch, error) {
+	return verifyMigrationChecksums(ctx, s.db, sqliteMigrations)
 }
 
 // ========================================
@@ -140,7 +206,8 @@ func (s *sqliteDB) WithTx(ctx context.Context, fn func(tx DB) error) error {
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
-	w := &sqliteTx{tx: tx}
+	var spCounter int64
+	w := &sqliteTx{tx: tx, cache: s.cache, hooks: s.hooks, spCounter: &spCounter}
 	if err := fn(w); err != nil {
 		_ = tx.Rollback()
 		return err
@@ -151,9 +218,36 @@ func (s *sqliteDB) WithTx(ctx context.Context, fn func(tx DB) error) error {
 	return nil
 }
 
-// トランザクションの中で更に WithTx が呼ばれた場合は、そのまま同じ tx を使う。
+// トランザクションの中で更に WithTx が呼ばれた場合、同じ tx に対して
+// SAVEPOINT を張り、内側の fn だけをロールバックできるようにする。これに
+// より、内側で compensating な失敗が起きても外側のトランザクションは
+// 巻き込まれず、そのままコミットを続行できる。
 func (t *sqliteTx) WithTx(ctx context.Context, fn func(tx DB) error) error {
-	return fn(t)
+	n := atomic.AddInt64(t.spCounter, 1)
+	sp := fmt.Sprintf("sp_%d", n)
+
+	savepointSQL := "SAVEPOINT " + sp
+	if _, err := hookedExec(ctx, t.hooks, "with_tx_savepoint", savepointSQL, nil, func(ctx context.Context) (sql.Result, error) {
+		return t.tx.ExecContext(ctx, savepointSQL)
+	}); err != nil {
+		return fmt.Errorf("savepoint %s: %w", sp, err)
+	}
+	if err := fn(t); err != nil {
+		rollbackSQL := "ROLLBACK TO " + sp
+		if _, rerr := hookedExec(ctx, t.hooks, "with_tx_rollback_to_savepoint", rollbackSQL, nil, func(ctx context.Context) (sql.Result, error) {
+			return t.tx.ExecContext(ctx, rollbackSQL)
+		}); rerr != nil {
+			return fmt.Errorf("rollback to savepoint %s: %w (original error: %v)", sp, rerr, err)
+		}
+		return err
+	}
+	releaseSQL := "RELEASE " + sp
+	if _, err := hookedExec(ctx, t.hooks, "with_tx_release_savepoint", releaseSQL, nil, func(ctx context.Context) (sql.Result, error) {
+		return t.tx.ExecContext(ctx, releaseSQL)
+	}); err != nil {
+		return fmt.Errorf("release savepoint %s: %w", sp, err)
+	}
+	return nil
 }
 
 // ========================================
@@ -161,8 +255,12 @@ func (t *sqliteTx) WithTx(ctx context.Context, fn func(tx DB) error) error {
 // ========================================
 
 func (s *sqliteDB) GetLastSequence(ctx context.Context) (int64, error) {
+	st, err := s.hookedStmt(stmtGetLastSequence)
+	if err != nil {
+		return 0, err
+	}
 	var seq int64
-	err := s.db.QueryRowContext(ctx, `SELECT last_seq FROM log_state WHERE id = 1`).Scan(&seq)
+	err = st.QueryRowContext(ctx).Scan(&seq)
 	if err == sql.ErrNoRows {
 		return 0, nil
 	}
@@ -173,19 +271,23 @@ func (s *sqliteDB) GetLastSequence(ctx context.Context) (int64, error) {
 }
 
 func (s *sqliteDB) SetLastSequence(ctx context.Context, seq int64) error {
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO log_state (id, last_seq) VALUES (1, ?)
-        ON CONFLICT(id) DO UPDATE SET last_seq = excluded.last_seq
-    `, seq)
+	st, err := s.hookedStmt(stmtSetLastSequence)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, seq); err != nil {
 		return fmt.Errorf("set last_seq: %w", err)
 	}
 	return nil
 }
 
 func (t *sqliteTx) GetLastSequence(ctx context.Context) (int64, error) {
+	st, err := t.txStmt(ctx, stmtGetLastSequence)
+	if err != nil {
+		return 0, err
+	}
 	var seq int64
-	err := t.tx.QueryRowContext(ctx, `SELECT last_seq FROM log_state WHERE id = 1`).Scan(&seq)
+	err = st.QueryRowContext(ctx).Scan(&seq)
 	if err == sql.ErrNoRows {
 		return 0, nil
 	}
@@ -196,16 +298,74 @@ func (t *sqliteTx) GetLastSequence(ctx context.Context) (int64, error) {
 }
 
 func (t *sqliteTx) SetLastSequence(ctx context.Context, seq int64) error {
-	_, err := t.tx.ExecContext(ctx, `
-        INSERT INTO log_state (id, last_seq) VALUES (1, ?)
-        ON CONFLICT(id) DO UPDATE SET last_seq = excluded.last_seq
-    `, seq)
+	st, err := t.txStmt(ctx, stmtSetLastSequence)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, seq); err != nil {
 		return fmt.Errorf("set last_seq(tx): %w", err)
 	}
 	return nil
 }
 
+// ========================================
+// ログチェーン管理
+// ========================================
+
+func (s *sqliteDB) GetLastHash(ctx context.Context) (string, error) {
+	st, err := s.hookedStmt(stmtGetLastHash)
+	if err != nil {
+		return "", err
+	}
+	var hash string
+	err = st.QueryRowContext(ctx).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get last_hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (s *sqliteDB) SetLastHash(ctx context.Context, hash string) error {
+	st, err := s.hookedStmt(stmtSetLastHash)
+	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, hash); err != nil {
+		return fmt.Errorf("set last_hash: %w", err)
+	}
+	return nil
+}
+
+func (t *sqliteTx) GetLastHash(ctx context.Context) (string, error) {
+	st, err := t.txStmt(ctx, stmtGetLastHash)
+	if err != nil {
+		return "", err
+	}
+	var hash string
+	err = st.QueryRowContext(ctx).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get last_hash(tx): %w", err)
+	}
+	return hash, nil
+}
+
+func (t *sqliteTx) SetLastHash(ctx context.Context, hash string) error {
+	st, err := t.txStmt(ctx, stmtSetLastHash)
+	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, hash); err != nil {
+		return fmt.Errorf("set last_hash(tx): %w", err)
+	}
+	return nil
+}
+
 // ========================================
 // Board 操作
 // ========================================
@@ -218,11 +378,11 @@ func (s *sqliteDB) CreateBoard(ctx context.Context, b *Board) error {
 	if b.UpdatedAt.IsZero() {
 		b.UpdatedAt = now
 	}
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO boards (id, name, description, created_at, updated_at, thread_count)
-        VALUES (?, ?, ?, ?, ?, ?)
-    `, b.ID, b.Name, b.Description, b.CreatedAt, b.UpdatedAt, b.ThreadCount)
+	st, err := s.hookedStmt(stmtCreateBoard)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, b.ID, b.Name, b.Description, b.CreatedAt, b.UpdatedAt, b.ThreadCount); err != nil {
 		return fmt.Errorf("create board: %w", err)
 	}
 	return nil
@@ -236,11 +396,11 @@ func (t *sqliteTx) CreateBoard(ctx context.Context, b *Board) error {
 	if b.UpdatedAt.IsZero() {
 		b.UpdatedAt = now
 	}
-	_, err := t.tx.ExecContext(ctx, `
-        INSERT INTO boards (id, name, description, created_at, updated_at, thread_count)
-        VALUES (?, ?, ?, ?, ?, ?)
-    `, b.ID, b.Name, b.Description, b.CreatedAt, b.UpdatedAt, b.ThreadCount)
+	st, err := t.txStmt(ctx, stmtCreateBoard)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, b.ID, b.Name, b.Description, b.CreatedAt, b.UpdatedAt, b.ThreadCount); err != nil {
 		return fmt.Errorf("create board(tx): %w", err)
 	}
 	return nil
@@ -248,12 +408,11 @@ func (t *sqliteTx) CreateBoard(ctx context.Context, b *Board) error {
 
 func (s *sqliteDB) UpdateBoard(ctx context.Context, b *Board) error {
 	b.UpdatedAt = time.Now().UTC()
-	_, err := s.db.ExecContext(ctx, `
-        UPDATE boards
-        SET name = ?, description = ?, updated_at = ?, thread_count = ?
-        WHERE id = ?
-    `, b.Name, b.Description, b.UpdatedAt, b.ThreadCount, b.ID)
+	st, err := s.hookedStmt(stmtUpdateBoard)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, b.Name, b.Description, b.UpdatedAt, b.ThreadCount, b.ID); err != nil {
 		return fmt.Errorf("update board: %w", err)
 	}
 	return nil
@@ -261,24 +420,23 @@ func (s *sqliteDB) UpdateBoard(ctx context.Context, b *Board) error {
 
 func (t *sqliteTx) UpdateBoard(ctx context.Context, b *Board) error {
 	b.UpdatedAt = time.Now().UTC()
-	_, err := t.tx.ExecContext(ctx, `
-        UPDATE boards
-        SET name = ?, description = ?, updated_at = ?, thread_count = ?
-        WHERE id = ?
-    `, b.Name, b.Description, b.UpdatedAt, b.ThreadCount, b.ID)
+	st, err := t.txStmt(ctx, stmtUpdateBoard)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, b.Name, b.Description, b.UpdatedAt, b.ThreadCount, b.ID); err != nil {
 		return fmt.Errorf("update board(tx): %w", err)
 	}
 	return nil
 }
 
 func (s *sqliteDB) GetBoard(ctx context.Context, id string) (*Board, error) {
-	row := s.db.QueryRowContext(ctx, `
-        SELECT id, name, description, created_at, updated_at, thread_count
-        FROM boards WHERE id = ?
-    `, id)
+	st, err := s.hookedStmt(stmtGetBoard)
+	if err != nil {
+		return nil, err
+	}
 	var b Board
-	if err := row.Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt, &b.ThreadCount); err != nil {
+	if err := st.QueryRowContext(ctx, id).Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt, &b.ThreadCount); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -288,12 +446,12 @@ func (s *sqliteDB) GetBoard(ctx context.Context, id string) (*Board, error) {
 }
 
 func (t *sqliteTx) GetBoard(ctx context.Context, id string) (*Board, error) {
-	row := t.tx.QueryRowContext(ctx, `
-        SELECT id, name, description, created_at, updated_at, thread_count
-        FROM boards WHERE id = ?
-    `, id)
+	st, err := t.txStmt(ctx, stmtGetBoard)
+	if err != nil {
+		return nil, err
+	}
 	var b Board
-	if err := row.Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt, &b.ThreadCount); err != nil {
+	if err := st.QueryRowContext(ctx, id).Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt, &b.ThreadCount); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -303,11 +461,11 @@ func (t *sqliteTx) GetBoard(ctx context.Context, id string) (*Board, error) {
 }
 
 func (s *sqliteDB) ListBoards(ctx context.Context) ([]Board, error) {
-	rows, err := s.db.QueryContext(ctx, `
-        SELECT id, name, description, created_at, updated_at, thread_count
-        FROM boards
-        ORDER BY created_at ASC
-    `)
+	st, err := s.hookedStmt(stmtListBoards)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := st.QueryContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list boards: %w", err)
 	}
@@ -325,11 +483,11 @@ func (s *sqliteDB) ListBoards(ctx context.Context) ([]Board, error) {
 }
 
 func (t *sqliteTx) ListBoards(ctx context.Context) ([]Board, error) {
-	rows, err := t.tx.QueryContext(ctx, `
-        SELECT id, name, description, created_at, updated_at, thread_count
-        FROM boards
-        ORDER BY created_at ASC
-    `)
+	st, err := t.txStmt(ctx, stmtListBoards)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := st.QueryContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list boards(tx): %w", err)
 	}
@@ -358,19 +516,19 @@ func (s *sqliteDB) CreateThread(ctx context.Context, t *Thread) error {
 	if t.UpdatedAt.IsZero() {
 		t.UpdatedAt = now
 	}
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO threads (id, board_id, title, author_id, created_at, updated_at, post_count, is_closed)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-    `, t.ID, t.BoardID, t.Title, t.AuthorID, t.CreatedAt, t.UpdatedAt, t.PostCount, boolToInt(t.IsClosed))
+	createSt, err := s.hookedStmt(stmtCreateThread)
 	if err != nil {
+		return err
+	}
+	if _, err := createSt.ExecContext(ctx, t.ID, t.BoardID, t.Title, t.AuthorID, t.CreatedAt, t.UpdatedAt, t.PostCount, sqliteDialect.boolParam(t.IsClosed), sqliteDialect.boolParam(t.IsDeleted)); err != nil {
 		return fmt.Errorf("create thread: %w", err)
 	}
 	// 対応する board のスレッド数を+1
-	_, err = s.db.ExecContext(ctx, `
-        UPDATE boards SET thread_count = thread_count + 1, updated_at = ?
-        WHERE id = ?
-    `, now, t.BoardID)
+	incSt, err := s.hookedStmt(stmtIncrementBoardThreadCount)
 	if err != nil {
+		return err
+	}
+	if _, err := incSt.ExecContext(ctx, now, t.BoardID); err != nil {
 		return fmt.Errorf("increment board.thread_count: %w", err)
 	}
 	return nil
@@ -384,18 +542,18 @@ func (t *sqliteTx) CreateThread(ctx context.Context, th *Thread) error {
 	if th.UpdatedAt.IsZero() {
 		th.UpdatedAt = now
 	}
-	_, err := t.tx.ExecContext(ctx, `
-        INSERT INTO threads (id, board_id, title, author_id, created_at, updated_at, post_count, is_closed)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-    `, th.ID, th.BoardID, th.Title, th.AuthorID, th.CreatedAt, th.UpdatedAt, th.PostCount, boolToInt(th.IsClosed))
+	createSt, err := t.txStmt(ctx, stmtCreateThread)
 	if err != nil {
+		return err
+	}
+	if _, err := createSt.ExecContext(ctx, th.ID, th.BoardID, th.Title, th.AuthorID, th.CreatedAt, th.UpdatedAt, th.PostCount, sqliteDialect.boolParam(th.IsClosed), sqliteDialect.boolParam(th.IsDeleted)); err != nil {
 		return fmt.Errorf("create thread(tx): %w", err)
 	}
-	_, err = t.tx.ExecContext(ctx, `
-        UPDATE boards SET thread_count = thread_count + 1, updated_at = ?
-        WHERE id = ?
-    `, now, th.BoardID)
+	incSt, err := t.txStmt(ctx, stmtIncrementBoardThreadCount)
 	if err != nil {
+		return err
+	}
+	if _, err := incSt.ExecContext(ctx, now, th.BoardID); err != nil {
 		return fmt.Errorf("increment board.thread_count(tx): %w", err)
 	}
 	return nil
@@ -403,12 +561,11 @@ func (t *sqliteTx) CreateThread(ctx context.Context, th *Thread) error {
 
 func (s *sqliteDB) UpdateThread(ctx context.Context, t *Thread) error {
 	t.UpdatedAt = time.Now().UTC()
-	_, err := s.db.ExecContext(ctx, `
-        UPDATE threads
-        SET title = ?, author_id = ?, updated_at = ?, post_count = ?, is_closed = ?
-        WHERE id = ?
-    `, t.Title, t.AuthorID, t.UpdatedAt, t.PostCount, boolToInt(t.IsClosed), t.ID)
+	st, err := s.hookedStmt(stmtUpdateThread)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, t.Title, t.AuthorID, t.UpdatedAt, t.PostCount, sqliteDialect.boolParam(t.IsClosed), sqliteDialect.boolParam(t.IsDeleted), t.ID); err != nil {
 		return fmt.Errorf("update thread: %w", err)
 	}
 	return nil
@@ -416,118 +573,162 @@ func (s *sqliteDB) UpdateThread(ctx context.Context, t *Thread) error {
 
 func (t *sqliteTx) UpdateThread(ctx context.Context, th *Thread) error {
 	th.UpdatedAt = time.Now().UTC()
-	_, err := t.tx.ExecContext(ctx, `
-        UPDATE threads
-        SET title = ?, author_id = ?, updated_at = ?, post_count = ?, is_closed = ?
-        WHERE id = ?
-    `, th.Title, th.AuthorID, th.UpdatedAt, th.PostCount, boolToInt(th.IsClosed), th.ID)
+	st, err := t.txStmt(ctx, stmtUpdateThread)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, th.Title, th.AuthorID, th.UpdatedAt, th.PostCount, sqliteDialect.boolParam(th.IsClosed), sqliteDialect.boolParam(th.IsDeleted), th.ID); err != nil {
 		return fmt.Errorf("update thread(tx): %w", err)
 	}
 	return nil
 }
 
 func (s *sqliteDB) GetThread(ctx context.Context, id string) (*Thread, error) {
-	row := s.db.QueryRowContext(ctx, `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads WHERE id = ?
-    `, id)
+	st, err := s.hookedStmt(stmtGetThread)
+	if err != nil {
+		return nil, err
+	}
 	var t Thread
-	var closed int
-	if err := row.Scan(&t.ID, &t.BoardID, &t.Title, &t.AuthorID, &t.CreatedAt, &t.UpdatedAt, &t.PostCount, &closed); err != nil {
+	var closed, deleted int
+	if err := st.QueryRowContext(ctx, id).Scan(&t.ID, &t.BoardID, &t.Title, &t.AuthorID, &t.CreatedAt, &t.UpdatedAt, &t.PostCount, &closed, &deleted); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get thread: %w", err)
 	}
 	t.IsClosed = closed != 0
+	t.IsDeleted = deleted != 0
 	return &t, nil
 }
 
 func (t *sqliteTx) GetThread(ctx context.Context, id string) (*Thread, error) {
-	row := t.tx.QueryRowContext(ctx, `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads WHERE id = ?
-    `, id)
+	st, err := t.txStmt(ctx, stmtGetThread)
+	if err != nil {
+		return nil, err
+	}
 	var th Thread
-	var closed int
-	if err := row.Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID, &th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed); err != nil {
+	var closed, deleted int
+	if err := st.QueryRowContext(ctx, id).Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID, &th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed, &deleted); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get thread(tx): %w", err)
 	}
 	th.IsClosed = closed != 0
+	th.IsDeleted = deleted != 0
 	return &th, nil
 }
 
-func (s *sqliteDB) ListThreadsByBoard(ctx context.Context, boardID string) ([]Thread, error) {
-	rows, err := s.db.QueryContext(ctx, `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads
-        WHERE board_id = ?
-        ORDER BY created_at ASC
-    `, boardID)
+func (s *sqliteDB) ListThreadsByBoard(ctx context.Context, boardID string, opts ListOpts) ([]Thread, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteListThreadsByBoardQuery(boardID, opts)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, s.hooks, "list_threads_by_board", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return s.db.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("list threads: %w", err)
+		return nil, "", "", fmt.Errorf("list threads: %w", err)
 	}
 	defer rows.Close()
 
-	var res []Thread
-	for rows.Next() {
-		var t Thread
-		var closed int
-		if err := rows.Scan(&t.ID, &t.BoardID, &t.Title, &t.AuthorID, &t.CreatedAt, &t.UpdatedAt, &t.PostCount, &closed); err != nil {
-			return nil, fmt.Errorf("list threads scan: %w", err)
-		}
-		t.IsClosed = closed != 0
-		res = append(res, t)
+	res, err := scanThreadRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads scan: %w", err)
 	}
-	return res, nil
+	items, next, prev := keysetPage(res, opts, reverse, threadCursorKey)
+	return items, next, prev, nil
 }
 
-func (t *sqliteTx) ListThreadsByBoard(ctx context.Context, boardID string) ([]Thread, error) {
-	rows, err := t.tx.QueryContext(ctx, `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads
-        WHERE board_id = ?
-        ORDER BY created_at ASC
-    `, boardID)
+func (t *sqliteTx) ListThreadsByBoard(ctx context.Context, boardID string, opts ListOpts) ([]Thread, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteListThreadsByBoardQuery(boardID, opts)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, t.hooks, "list_threads_by_board", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return t.tx.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("list threads(tx): %w", err)
+		return nil, "", "", fmt.Errorf("list threads(tx): %w", err)
 	}
 	defer rows.Close()
 
+	res, err := scanThreadRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads scan(tx): %w", err)
+	}
+	items, next, prev := keysetPage(res, opts, reverse, threadCursorKey)
+	return items, next, prev, nil
+}
+
+// sqliteListThreadsByBoardQuery builds the keyset-paginated SELECT and its
+// args for ListThreadsByBoard, shared by sqliteDB and sqliteTx since neither
+// needs a prepared-statement cache here: the WHERE/ORDER BY vary with opts.
+func sqliteListThreadsByBoardQuery(boardID string, opts ListOpts) (query string, args []any, reverse bool, err error) {
+	clause, clauseArgs, desc, reverse, err := buildKeysetClause(sqliteDialect, 2, opts)
+	if err != nil {
+		return "", nil, false, err
+	}
+	where := "board_id = ? AND is_deleted = " + sqliteDialect.boolLiteral(false)
+	args = []any{boardID}
+	if clause != "" {
+		where += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	query = fmt.Sprintf(`
+        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed, is_deleted
+        FROM threads
+        WHERE %s
+        ORDER BY created_at %s, id %s
+        LIMIT ?
+    `, where, order, order)
+	args = append(args, clampListLimit(opts.Limit)+1)
+	return query, args, reverse, nil
+}
+
+// scanThreadRows scans rows shaped like ListThreadsByBoard's SELECT into
+// Thread values; closing rows is still the caller's responsibility.
+func scanThreadRows(rows *sql.Rows) ([]Thread, error) {
 	var res []Thread
 	for rows.Next() {
-		var th Thread
-		var closed int
-		if err := rows.Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID, &th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed); err != nil {
-			return nil, fmt.Errorf("list threads scan(tx): %w", err)
+		var t Thread
+		var closed, deleted int
+		if err := rows.Scan(&t.ID, &t.BoardID, &t.Title, &t.AuthorID, &t.CreatedAt, &t.UpdatedAt, &t.PostCount, &closed, &deleted); err != nil {
+			return nil, err
 		}
-		th.IsClosed = closed != 0
-		res = append(res, th)
+		t.IsClosed = closed != 0
+		t.IsDeleted = deleted != 0
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 	return res, nil
 }
 
+func threadCursorKey(t Thread) (time.Time, string) { return t.CreatedAt, t.ID }
+
 func (s *sqliteDB) CloseThread(ctx context.Context, threadID string) error {
-	_, err := s.db.ExecContext(ctx, `
-        UPDATE threads SET is_closed = 1, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), threadID)
+	st, err := s.hookedStmt(stmtCloseThread)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, time.Now().UTC(), threadID); err != nil {
 		return fmt.Errorf("close thread: %w", err)
 	}
 	return nil
 }
 
 func (t *sqliteTx) CloseThread(ctx context.Context, threadID string) error {
-	_, err := t.tx.ExecContext(ctx, `
-        UPDATE threads SET is_closed = 1, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), threadID)
+	st, err := t.txStmt(ctx, stmtCloseThread)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, time.Now().UTC(), threadID); err != nil {
 		return fmt.Errorf("close thread(tx): %w", err)
 	}
 	return nil
@@ -545,18 +746,18 @@ func (s *sqliteDB) CreatePost(ctx context.Context, p *Post) error {
 	if p.UpdatedAt.IsZero() {
 		p.UpdatedAt = now
 	}
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO posts (id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-    `, p.ID, p.ThreadID, p.BoardID, p.AuthorID, p.Content, p.CreatedAt, p.UpdatedAt, boolToInt(p.IsDeleted), nullIfEmpty(p.ReplyTo))
+	createSt, err := s.hookedStmt(stmtCreatePost)
 	if err != nil {
+		return err
+	}
+	if _, err := createSt.ExecContext(ctx, p.ID, p.ThreadID, p.BoardID, p.AuthorID, p.Content, p.CreatedAt, p.UpdatedAt, sqliteDialect.boolParam(p.IsDeleted), nullIfEmpty(p.ReplyTo)); err != nil {
 		return fmt.Errorf("create post: %w", err)
 	}
-	_, err = s.db.ExecContext(ctx, `
-        UPDATE threads SET post_count = post_count + 1, updated_at = ?
-        WHERE id = ?
-    `, now, p.ThreadID)
+	incSt, err := s.hookedStmt(stmtIncrementThreadPostCount)
 	if err != nil {
+		return err
+	}
+	if _, err := incSt.ExecContext(ctx, now, p.ThreadID); err != nil {
 		return fmt.Errorf("increment thread.post_count: %w", err)
 	}
 	return nil
@@ -570,18 +771,18 @@ func (t *sqliteTx) CreatePost(ctx context.Context, p *Post) error {
 	if p.UpdatedAt.IsZero() {
 		p.UpdatedAt = now
 	}
-	_, err := t.tx.ExecContext(ctx, `
-        INSERT INTO posts (id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-    `, p.ID, p.ThreadID, p.BoardID, p.AuthorID, p.Content, p.CreatedAt, p.UpdatedAt, boolToInt(p.IsDeleted), nullIfEmpty(p.ReplyTo))
+	createSt, err := t.txStmt(ctx, stmtCreatePost)
 	if err != nil {
+		return err
+	}
+	if _, err := createSt.ExecContext(ctx, p.ID, p.ThreadID, p.BoardID, p.AuthorID, p.Content, p.CreatedAt, p.UpdatedAt, sqliteDialect.boolParam(p.IsDeleted), nullIfEmpty(p.ReplyTo)); err != nil {
 		return fmt.Errorf("create post(tx): %w", err)
 	}
-	_, err = t.tx.ExecContext(ctx, `
-        UPDATE threads SET post_count = post_count + 1, updated_at = ?
-        WHERE id = ?
-    `, now, p.ThreadID)
+	incSt, err := t.txStmt(ctx, stmtIncrementThreadPostCount)
 	if err != nil {
+		return err
+	}
+	if _, err := incSt.ExecContext(ctx, now, p.ThreadID); err != nil {
 		return fmt.Errorf("increment thread.post_count(tx): %w", err)
 	}
 	return nil
@@ -589,12 +790,11 @@ func (t *sqliteTx) CreatePost(ctx context.Context, p *Post) error {
 
 func (s *sqliteDB) UpdatePost(ctx context.Context, p *Post) error {
 	p.UpdatedAt = time.Now().UTC()
-	_, err := s.db.ExecContext(ctx, `
-        UPDATE posts
-        SET content = ?, updated_at = ?, is_deleted = ?, reply_to = ?
-        WHERE id = ?
-    `, p.Content, p.UpdatedAt, boolToInt(p.IsDeleted), nullIfEmpty(p.ReplyTo), p.ID)
+	st, err := s.hookedStmt(stmtUpdatePost)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, p.Content, p.UpdatedAt, sqliteDialect.boolParam(p.IsDeleted), nullIfEmpty(p.ReplyTo), p.ID); err != nil {
 		return fmt.Errorf("update post: %w", err)
 	}
 	return nil
@@ -602,26 +802,25 @@ func (s *sqliteDB) UpdatePost(ctx context.Context, p *Post) error {
 
 func (t *sqliteTx) UpdatePost(ctx context.Context, p *Post) error {
 	p.UpdatedAt = time.Now().UTC()
-	_, err := t.tx.ExecContext(ctx, `
-        UPDATE posts
-        SET content = ?, updated_at = ?, is_deleted = ?, reply_to = ?
-        WHERE id = ?
-    `, p.Content, p.UpdatedAt, boolToInt(p.IsDeleted), nullIfEmpty(p.ReplyTo), p.ID)
+	st, err := t.txStmt(ctx, stmtUpdatePost)
 	if err != nil {
+		return err
+	}
+	if _, err := st.ExecContext(ctx, p.Content, p.UpdatedAt, sqliteDialect.boolParam(p.IsDeleted), nullIfEmpty(p.ReplyTo), p.ID); err != nil {
 		return fmt.Errorf("update post(tx): %w", err)
 	}
 	return nil
 }
 
 func (s *sqliteDB) GetPost(ctx context.Context, id string) (*Post, error) {
-	row := s.db.QueryRowContext(ctx, `
-        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
-        FROM posts WHERE id = ?
-    `, id)
+	st, err := s.hookedStmt(stmtGetPost)
+	if err != nil {
+		return nil, err
+	}
 	var p Post
 	var deleted int
 	var replyTo sql.NullString
-	if err := row.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
+	if err := st.QueryRowContext(ctx, id).Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -635,14 +834,14 @@ func (s *sqliteDB) GetPost(ctx context.Context, id string) (*Post, error) {
 }
 
 func (t *sqliteTx) GetPost(ctx context.Context, id string) (*Post, error) {
-	row := t.tx.QueryRowContext(ctx, `
-        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
-        FROM posts WHERE id = ?
-    `, id)
+	st, err := t.txStmt(ctx, stmtGetPost)
+	if err != nil {
+		return nil, err
+	}
 	var p Post
 	var deleted int
 	var replyTo sql.NullString
-	if err := row.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
+	if err := st.QueryRowContext(ctx, id).Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -655,54 +854,86 @@ func (t *sqliteTx) GetPost(ctx context.Context, id string) (*Post, error) {
 	return &p, nil
 }
 
-func (s *sqliteDB) ListPostsByThread(ctx context.Context, threadID string) ([]Post, error) {
-	rows, err := s.db.QueryContext(ctx, `
-        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
-        FROM posts
-        WHERE thread_id = ?
-        ORDER BY created_at ASC
-    `, threadID)
+func (s *sqliteDB) ListPostsByThread(ctx context.Context, threadID string, opts ListOpts) ([]Post, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteListPostsByThreadQuery(threadID, opts)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, s.hooks, "list_posts_by_thread", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return s.db.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("list posts: %w", err)
+		return nil, "", "", fmt.Errorf("list posts: %w", err)
 	}
 	defer rows.Close()
 
-	var res []Post
-	for rows.Next() {
-		var p Post
-		var deleted int
-		var replyTo sql.NullString
-		if err := rows.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
-			return nil, fmt.Errorf("list posts scan: %w", err)
-		}
-		p.IsDeleted = deleted != 0
-		if replyTo.Valid {
-			p.ReplyTo = replyTo.String
-		}
-		res = append(res, p)
+	res, err := scanPostRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts scan: %w", err)
 	}
-	return res, nil
+	items, next, prev := keysetPage(res, opts, reverse, postCursorKey)
+	return items, next, prev, nil
 }
 
-func (t *sqliteTx) ListPostsByThread(ctx context.Context, threadID string) ([]Post, error) {
-	rows, err := t.tx.QueryContext(ctx, `
-        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
-        FROM posts
-        WHERE thread_id = ?
-        ORDER BY created_at ASC
-    `, threadID)
+func (t *sqliteTx) ListPostsByThread(ctx context.Context, threadID string, opts ListOpts) ([]Post, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteListPostsByThreadQuery(threadID, opts)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, t.hooks, "list_posts_by_thread", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return t.tx.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("list posts(tx): %w", err)
+		return nil, "", "", fmt.Errorf("list posts(tx): %w", err)
 	}
 	defer rows.Close()
 
+	res, err := scanPostRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts scan(tx): %w", err)
+	}
+	items, next, prev := keysetPage(res, opts, reverse, postCursorKey)
+	return items, next, prev, nil
+}
+
+// sqliteListPostsByThreadQuery builds the keyset-paginated SELECT and its
+// args for ListPostsByThread; see sqliteListThreadsByBoardQuery.
+func sqliteListPostsByThreadQuery(threadID string, opts ListOpts) (query string, args []any, reverse bool, err error) {
+	clause, clauseArgs, desc, reverse, err := buildKeysetClause(sqliteDialect, 2, opts)
+	if err != nil {
+		return "", nil, false, err
+	}
+	where := "thread_id = ?"
+	args = []any{threadID}
+	if clause != "" {
+		where += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	query = fmt.Sprintf(`
+        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
+        FROM posts
+        WHERE %s
+        ORDER BY created_at %s, id %s
+        LIMIT ?
+    `, where, order, order)
+	args = append(args, clampListLimit(opts.Limit)+1)
+	return query, args, reverse, nil
+}
+
+// scanPostRows scans rows shaped like ListPostsByThread's SELECT into Post
+// values; closing rows is still the caller's responsibility.
+func scanPostRows(rows *sql.Rows) ([]Post, error) {
 	var res []Post
 	for rows.Next() {
 		var p Post
 		var deleted int
 		var replyTo sql.NullString
 		if err := rows.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content, &p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
-			return nil, fmt.Errorf("list posts scan(tx): %w", err)
+			return nil, err
 		}
 		p.IsDeleted = deleted != 0
 		if replyTo.Valid {
@@ -710,13 +941,22 @@ func (t *sqliteTx) ListPostsByThread(ctx context.Context, threadID string) ([]Po
 		}
 		res = append(res, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
+func postCursorKey(p Post) (time.Time, string) { return p.CreatedAt, p.ID }
+
 func (s *sqliteDB) DeletePost(ctx context.Context, postID string) error {
 	// post 情報取得（thread_id 用）
+	threadIDSt, err := s.hookedStmt(stmtGetPostThreadID)
+	if err != nil {
+		return err
+	}
 	var threadID string
-	err := s.db.QueryRowContext(ctx, `SELECT thread_id FROM posts WHERE id = ?`, postID).Scan(&threadID)
+	err = threadIDSt.QueryRowContext(ctx, postID).Scan(&threadID)
 	if err == sql.ErrNoRows {
 		return nil
 	}
@@ -724,26 +964,30 @@ func (s *sqliteDB) DeletePost(ctx context.Context, postID string) error {
 		return fmt.Errorf("delete post get thread: %w", err)
 	}
 
-	_, err = s.db.ExecContext(ctx, `
-        UPDATE posts SET is_deleted = 1, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), postID)
+	markSt, err := s.hookedStmt(stmtMarkPostDeleted)
 	if err != nil {
+		return err
+	}
+	if _, err := markSt.ExecContext(ctx, time.Now().UTC(), postID); err != nil {
 		return fmt.Errorf("delete post: %w", err)
 	}
-	_, err = s.db.ExecContext(ctx, `
-        UPDATE threads SET post_count = CASE WHEN post_count > 0 THEN post_count - 1 ELSE 0 END, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), threadID)
+	decSt, err := s.hookedStmt(stmtDecrementThreadPostCount)
 	if err != nil {
+		return err
+	}
+	if _, err := decSt.ExecContext(ctx, time.Now().UTC(), threadID); err != nil {
 		return fmt.Errorf("decrement thread.post_count: %w", err)
 	}
 	return nil
 }
 
 func (t *sqliteTx) DeletePost(ctx context.Context, postID string) error {
+	threadIDSt, err := t.txStmt(ctx, stmtGetPostThreadID)
+	if err != nil {
+		return err
+	}
 	var threadID string
-	err := t.tx.QueryRowContext(ctx, `SELECT thread_id FROM posts WHERE id = ?`, postID).Scan(&threadID)
+	err = threadIDSt.QueryRowContext(ctx, postID).Scan(&threadID)
 	if err == sql.ErrNoRows {
 		return nil
 	}
@@ -751,296 +995,833 @@ func (t *sqliteTx) DeletePost(ctx context.Context, postID string) error {
 		return fmt.Errorf("delete post get thread(tx): %w", err)
 	}
 
-	_, err = t.tx.ExecContext(ctx, `
-        UPDATE posts SET is_deleted = 1, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), postID)
+	markSt, err := t.txStmt(ctx, stmtMarkPostDeleted)
 	if err != nil {
+		return err
+	}
+	if _, err := markSt.ExecContext(ctx, time.Now().UTC(), postID); err != nil {
 		return fmt.Errorf("delete post(tx): %w", err)
 	}
-	_, err = t.tx.ExecContext(ctx, `
-        UPDATE threads SET post_count = CASE WHEN post_count > 0 THEN post_count - 1 ELSE 0 END, updated_at = ?
-        WHERE id = ?
-    `, time.Now().UTC(), threadID)
+	decSt, err := t.txStmt(ctx, stmtDecrementThreadPostCount)
 	if err != nil {
+		return err
+	}
+	if _, err := decSt.ExecContext(ctx, time.Now().UTC(), threadID); err != nil {
 		return fmt.Errorf("decrement thread.post_count(tx): %w", err)
 	}
 	return nil
 }
 
+func (s *sqliteDB) DeleteThread(ctx context.Context, threadID string) error {
+	boardIDSt, err := s.hookedStmt(stmtGetThreadBoardID)
+	if err != nil {
+		return err
+	}
+	var boardID string
+	err = boardIDSt.QueryRowContext(ctx, threadID).Scan(&boardID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete thread get board: %w", err)
+	}
+
+	now := time.Now().UTC()
+	markThreadSt, err := s.hookedStmt(stmtMarkThreadDeleted)
+	if err != nil {
+		return err
+	}
+	if _, err := markThreadSt.ExecContext(ctx, now, threadID); err != nil {
+		return fmt.Errorf("delete thread: %w", err)
+	}
+	markPostsSt, err := s.hookedStmt(stmtMarkThreadPostsDeleted)
+	if err != nil {
+		return err
+	}
+	if _, err := markPostsSt.ExecContext(ctx, now, threadID); err != nil {
+		return fmt.Errorf("delete thread cascade posts: %w", err)
+	}
+	decSt, err := s.hookedStmt(stmtDecrementBoardThreadCount)
+	if err != nil {
+		return err
+	}
+	if _, err := decSt.ExecContext(ctx, now, boardID); err != nil {
+		return fmt.Errorf("decrement board.thread_count: %w", err)
+	}
+	return nil
+}
+
+func (t *sqliteTx) DeleteThread(ctx context.Context, threadID string) error {
+	boardIDSt, err := t.txStmt(ctx, stmtGetThreadBoardID)
+	if err != nil {
+		return err
+	}
+	var boardID string
+	err = boardIDSt.QueryRowContext(ctx, threadID).Scan(&boardID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete thread get board(tx): %w", err)
+	}
+
+	now := time.Now().UTC()
+	markThreadSt, err := t.txStmt(ctx, stmtMarkThreadDeleted)
+	if err != nil {
+		return err
+	}
+	if _, err := markThreadSt.ExecContext(ctx, now, threadID); err != nil {
+		return fmt.Errorf("delete thread(tx): %w", err)
+	}
+	markPostsSt, err := t.txStmt(ctx, stmtMarkThreadPostsDeleted)
+	if err != nil {
+		return err
+	}
+	if _, err := markPostsSt.ExecContext(ctx, now, threadID); err != nil {
+		return fmt.Errorf("delete thread cascade posts(tx): %w", err)
+	}
+	decSt, err := t.txStmt(ctx, stmtDecrementBoardThreadCount)
+	if err != nil {
+		return err
+	}
+	if _, err := decSt.ExecContext(ctx, now, boardID); err != nil {
+		return fmt.Errorf("decrement board.thread_count(tx): %w", err)
+	}
+	return nil
+}
+
 // ========================================
 // 検索系
 // ========================================
-
-func (s *sqliteDB) SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
-	if req.Limit <= 0 {
-		req.Limit = 20
+//
+// CountPosts/ListPosts and CountThreads/ListThreads are the shared query
+// path: PostQuery/ThreadQuery.buildWhere builds the WHERE clause, and
+// sqliteBuildPostsListQuery/sqliteBuildThreadsListQuery build the
+// keyset-paginated SELECT around it, so sqliteDB and sqliteTx each need
+// only dispatch to s.db/t.tx. SearchPosts/SearchThreads below are thin
+// wrappers translating a SearchPostsRequest/SearchThreadsRequest into the
+// equivalent query.
+
+func (s *sqliteDB) CountPosts(ctx context.Context, q PostQuery) (int, error) {
+	query, args := sqliteBuildPostsCountQuery(q)
+	var total int
+	if err := hookedQueryRow(ctx, s.hooks, "count_posts", query, args, func(ctx context.Context) *sql.Row {
+		return s.db.QueryRowContext(ctx, query, args...)
+	}).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count posts: %w", err)
 	}
-	if req.Offset < 0 {
-		req.Offset = 0
+	return total, nil
+}
+
+func (t *sqliteTx) CountPosts(ctx context.Context, q PostQuery) (int, error) {
+	query, args := sqliteBuildPostsCountQuery(q)
+	var total int
+	if err := hookedQueryRow(ctx, t.hooks, "count_posts", query, args, func(ctx context.Context) *sql.Row {
+		return t.tx.QueryRowContext(ctx, query, args...)
+	}).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count posts(tx): %w", err)
 	}
+	return total, nil
+}
 
-	where := []string{"is_deleted = 0"}
-	args := []any{}
+// sqliteBuildPostsCountQuery builds CountPosts' SELECT COUNT(*) and its
+// args, shared by sqliteDB and sqliteTx.
+func sqliteBuildPostsCountQuery(q PostQuery) (query string, args []any) {
+	where, args := q.buildWhere(sqliteDialect)
+	return `SELECT COUNT(*) FROM posts WHERE ` + strings.Join(where, " AND "), args
+}
 
-	if req.Query != "" {
-		where = append(where, "content LIKE ?")
-		args = append(args, "%"+req.Query+"%")
+func (s *sqliteDB) ListPosts(ctx context.Context, q PostQuery) ([]Post, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteBuildPostsListQuery(q)
+	if err != nil {
+		return nil, "", "", err
 	}
-	if req.BoardID != "" {
-		where = append(where, "board_id = ?")
-		args = append(args, req.BoardID)
+	rows, err := hookedQuery(ctx, s.hooks, "list_posts", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return s.db.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts query: %w", err)
 	}
-	if req.ThreadID != "" {
-		where = append(where, "thread_id = ?")
-		args = append(args, req.ThreadID)
+	defer rows.Close()
+
+	res, err := scanPostRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts scan: %w", err)
 	}
-	if req.AuthorID != "" {
-		where = append(where, "author_id = ?")
-		args = append(args, req.AuthorID)
+	items, next, prev := keysetPage(res, ListOpts{Limit: q.Limit, After: q.After, Before: q.Before}, reverse, postCursorKey)
+	return items, next, prev, nil
+}
+
+func (t *sqliteTx) ListPosts(ctx context.Context, q PostQuery) ([]Post, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteBuildPostsListQuery(q)
+	if err != nil {
+		return nil, "", "", err
 	}
-	whereSQL := strings.Join(where, " AND ")
+	rows, err := hookedQuery(ctx, t.hooks, "list_posts", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return t.tx.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts query(tx): %w", err)
+	}
+	defer rows.Close()
 
-	// カウント
-	countQuery := `SELECT COUNT(*) FROM posts WHERE ` + whereSQL
-	var total int
-	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, fmt.Errorf("search posts count: %w", err)
+	res, err := scanPostRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list posts scan(tx): %w", err)
+	}
+	items, next, prev := keysetPage(res, ListOpts{Limit: q.Limit, After: q.After, Before: q.Before}, reverse, postCursorKey)
+	return items, next, prev, nil
+}
+
+// sqliteBuildPostsListQuery builds ListPosts' keyset-paginated SELECT and
+// its args, shared by sqliteDB and sqliteTx. Once q.After/q.Before selects
+// keyset pagination, the ORDER BY column/direction follow Cursor's
+// (created_at, id) contract rather than q.SortBy/q.SortDir — see
+// PostQuery.SortBy.
+func sqliteBuildPostsListQuery(q PostQuery) (query string, args []any, reverse bool, err error) {
+	where, whereArgs := q.buildWhere(sqliteDialect)
+	listWhere, listArgs, desc, reverse, kerr := appendSearchKeyset(sqliteDialect, where, whereArgs, q.After, q.Before)
+	if kerr != nil {
+		return "", nil, false, fmt.Errorf("list posts: %w", kerr)
+	}
+	useKeyset := q.After != "" || q.Before != ""
+
+	col := orderColumn(q.SortBy)
+	order := "ASC"
+	if q.SortDir == OrderDesc {
+		order = "DESC"
+	}
+	if useKeyset {
+		col = "created_at"
+		order = "DESC"
+		if !desc {
+			order = "ASC"
+		}
 	}
 
-	// データ取得
-	query := `
+	query = `
         SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
         FROM posts
-        WHERE ` + whereSQL + `
-        ORDER BY created_at DESC
-        LIMIT ? OFFSET ?
-    `
-	argsWithLimit := append(args, req.Limit, req.Offset)
-	rows, err := s.db.QueryContext(ctx, query, argsWithLimit...)
+        WHERE ` + strings.Join(listWhere, " AND ") + `
+        ORDER BY ` + col + ` ` + order + `, id ` + order + `
+        LIMIT ?` + searchOffsetClause(useKeyset)
+	fetchLimit := clampListLimit(q.Limit)
+	if useKeyset {
+		fetchLimit++
+	}
+	args = append(append([]any{}, listArgs...), fetchLimit)
+	if !useKeyset {
+		offset := q.Offset
+		if offset < 0 {
+			offset = 0
+		}
+		args = append(args, offset)
+	}
+	return query, args, reverse, nil
+}
+
+func (s *sqliteDB) CountThreads(ctx context.Context, q ThreadQuery) (int, error) {
+	query, args := sqliteBuildThreadsCountQuery(q)
+	var total int
+	if err := hookedQueryRow(ctx, s.hooks, "count_threads", query, args, func(ctx context.Context) *sql.Row {
+		return s.db.QueryRowContext(ctx, query, args...)
+	}).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count threads: %w", err)
+	}
+	return total, nil
+}
+
+func (t *sqliteTx) CountThreads(ctx context.Context, q ThreadQuery) (int, error) {
+	query, args := sqliteBuildThreadsCountQuery(q)
+	var total int
+	if err := hookedQueryRow(ctx, t.hooks, "count_threads", query, args, func(ctx context.Context) *sql.Row {
+		return t.tx.QueryRowContext(ctx, query, args...)
+	}).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count threads(tx): %w", err)
+	}
+	return total, nil
+}
+
+// sqliteBuildThreadsCountQuery builds CountThreads' SELECT COUNT(*) and its
+// args, shared by sqliteDB and sqliteTx.
+func sqliteBuildThreadsCountQuery(q ThreadQuery) (query string, args []any) {
+	where, args := q.buildWhere(sqliteDialect)
+	return `SELECT COUNT(*) FROM threads WHERE ` + strings.Join(where, " AND "), args
+}
+
+func (s *sqliteDB) ListThreads(ctx context.Context, q ThreadQuery) ([]Thread, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteBuildThreadsListQuery(q)
 	if err != nil {
-		return nil, fmt.Errorf("search posts query: %w", err)
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, s.hooks, "list_threads", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return s.db.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads query: %w", err)
 	}
 	defer rows.Close()
 
-	var posts []Post
-	for rows.Next() {
-		var p Post
-		var deleted int
-		var replyTo sql.NullString
-		if err := rows.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content,
-			&p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
-			return nil, fmt.Errorf("search posts scan: %w", err)
+	res, err := scanThreadRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads scan: %w", err)
+	}
+	items, next, prev := keysetPage(res, ListOpts{Limit: q.Limit, After: q.After, Before: q.Before}, reverse, threadCursorKey)
+	return items, next, prev, nil
+}
+
+func (t *sqliteTx) ListThreads(ctx context.Context, q ThreadQuery) ([]Thread, Cursor, Cursor, error) {
+	query, args, reverse, err := sqliteBuildThreadsListQuery(q)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := hookedQuery(ctx, t.hooks, "list_threads", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return t.tx.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads query(tx): %w", err)
+	}
+	defer rows.Close()
+
+	res, err := scanThreadRows(rows)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("list threads scan(tx): %w", err)
+	}
+	items, next, prev := keysetPage(res, ListOpts{Limit: q.Limit, After: q.After, Before: q.Before}, reverse, threadCursorKey)
+	return items, next, prev, nil
+}
+
+// sqliteBuildThreadsListQuery is ListThreads' analogue of
+// sqliteBuildPostsListQuery.
+func sqliteBuildThreadsListQuery(q ThreadQuery) (query string, args []any, reverse bool, err error) {
+	where, whereArgs := q.buildWhere(sqliteDialect)
+	listWhere, listArgs, desc, reverse, kerr := appendSearchKeyset(sqliteDialect, where, whereArgs, q.After, q.Before)
+	if kerr != nil {
+		return "", nil, false, fmt.Errorf("list threads: %w", kerr)
+	}
+	useKeyset := q.After != "" || q.Before != ""
+
+	col := orderColumn(q.SortBy)
+	order := "ASC"
+	if q.SortDir == OrderDesc {
+		order = "DESC"
+	}
+	if useKeyset {
+		col = "created_at"
+		order = "DESC"
+		if !desc {
+			order = "ASC"
 		}
-		p.IsDeleted = deleted != 0
-		if replyTo.Valid {
-			p.ReplyTo = replyTo.String
+	}
+
+	query = `
+        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed, is_deleted
+        FROM threads
+        WHERE ` + strings.Join(listWhere, " AND ") + `
+        ORDER BY ` + col + ` ` + order + `, id ` + order + `
+        LIMIT ?` + searchOffsetClause(useKeyset)
+	fetchLimit := clampListLimit(q.Limit)
+	if useKeyset {
+		fetchLimit++
+	}
+	args = append(append([]any{}, listArgs...), fetchLimit)
+	if !useKeyset {
+		offset := q.Offset
+		if offset < 0 {
+			offset = 0
 		}
-		posts = append(posts, p)
+		args = append(args, offset)
 	}
+	return query, args, reverse, nil
+}
 
-	return &SearchPostsResponse{
-		Posts:      posts,
-		TotalCount: total,
-		Limit:      req.Limit,
-		Offset:     req.Offset,
-	}, nil
+func (s *sqliteDB) SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	return sqliteSearchPosts(ctx, s, req)
 }
 
 func (t *sqliteTx) SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
-	// トランザクション内でも特に変わらないので、db 実装とほぼ同じ
-	if req.Limit <= 0 {
-		req.Limit = 20
-	}
-	if req.Offset < 0 {
-		req.Offset = 0
-	}
+	return sqliteSearchPosts(ctx, t, req)
+}
 
-	where := []string{"is_deleted = 0"}
-	args := []any{}
+func (s *sqliteDB) SearchPostsStructured(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	return sqliteSearchPosts(ctx, s, req)
+}
 
-	if req.Query != "" {
-		where = append(where, "content LIKE ?")
-		args = append(args, "%"+req.Query+"%")
+func (t *sqliteTx) SearchPostsStructured(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	return sqliteSearchPosts(ctx, t, req)
+}
+
+// sqliteSearchPosts implements SearchPosts/SearchPostsStructured against any
+// DB whose CountPosts/ListPosts are backed by sqliteDialect (sqliteDB or
+// sqliteTx), by translating req into a PostQuery and reshaping the Post rows
+// it gets back into PostSearchResult. SortDir: OrderDesc reproduces
+// SearchPosts' historic newest-first default, which predates
+// PostQuery.SortDir's OrderAsc zero value. The deprecated singular
+// BoardID/ThreadID/AuthorID fields (if set) are folded into their plural
+// counterparts, so this one translation serves both the legacy request shape
+// and ParseSearchQuery's structured one.
+func sqliteSearchPosts(ctx context.Context, db DB, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	req.Limit, req.Offset = clampSearchPaging(req.Limit, req.Offset)
+	q := PostQuery{
+		Query:         req.Query,
+		BoardIDs:      req.BoardIDs,
+		ThreadIDs:     req.ThreadIDs,
+		AuthorIDs:     req.AuthorIDs,
+		IncludeTerms:  req.IncludeTerms,
+		ExcludeTerms:  req.ExcludeTerms,
+		PhraseTerms:   req.PhraseTerms,
+		CreatedAfter:  req.DateFrom,
+		CreatedBefore: req.DateTo,
+		SortDir:       OrderDesc,
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+		After:         req.After,
+		Before:        req.Before,
 	}
 	if req.BoardID != "" {
-		where = append(where, "board_id = ?")
-		args = append(args, req.BoardID)
+		q.BoardIDs = append(q.BoardIDs, req.BoardID)
 	}
 	if req.ThreadID != "" {
-		where = append(where, "thread_id = ?")
-		args = append(args, req.ThreadID)
+		q.ThreadIDs = append(q.ThreadIDs, req.ThreadID)
 	}
 	if req.AuthorID != "" {
-		where = append(where, "author_id = ?")
-		args = append(args, req.AuthorID)
+		q.AuthorIDs = append(q.AuthorIDs, req.AuthorID)
 	}
-	whereSQL := strings.Join(where, " AND ")
 
-	countQuery := `SELECT COUNT(*) FROM posts WHERE ` + whereSQL
-	var total int
-	if err := t.tx.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, fmt.Errorf("search posts count(tx): %w", err)
+	total, err := db.CountPosts(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("search posts count: %w", err)
 	}
-
-	query := `
-        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
-        FROM posts
-        WHERE ` + whereSQL + `
-        ORDER BY created_at DESC
-        LIMIT ? OFFSET ?
-    `
-	argsWithLimit := append(args, req.Limit, req.Offset)
-	rows, err := t.tx.QueryContext(ctx, query, argsWithLimit...)
+	posts, next, prev, err := db.ListPosts(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("search posts query(tx): %w", err)
+		return nil, fmt.Errorf("search posts query: %w", err)
 	}
-	defer rows.Close()
 
-	var posts []Post
-	for rows.Next() {
-		var p Post
-		var deleted int
-		var replyTo sql.NullString
-		if err := rows.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content,
-			&p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
-			return nil, fmt.Errorf("search posts scan(tx): %w", err)
-		}
-		p.IsDeleted = deleted != 0
-		if replyTo.Valid {
-			p.ReplyTo = replyTo.String
-		}
-		posts = append(posts, p)
+	results := make([]PostSearchResult, len(posts))
+	for i, p := range posts {
+		results[i] = PostSearchResult{Post: p}
 	}
-
 	return &SearchPostsResponse{
-		Posts:      posts,
+		Posts:      results,
 		TotalCount: total,
 		Limit:      req.Limit,
 		Offset:     req.Offset,
+		NextCursor: next,
+		PrevCursor: prev,
 	}, nil
 }
 
 func (s *sqliteDB) SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
-	if req.Limit <= 0 {
-		req.Limit = 20
-	}
-	if req.Offset < 0 {
-		req.Offset = 0
-	}
+	return sqliteSearchThreads(ctx, s, s.hooks, s.db.QueryContext, req)
+}
 
-	where := []string{"1=1"}
-	args := []any{}
+func (t *sqliteTx) SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	return sqliteSearchThreads(ctx, t, t.hooks, t.tx.QueryContext, req)
+}
 
-	if req.Query != "" {
-		where = append(where, "title LIKE ?")
-		args = append(args, "%"+req.Query+"%")
+func (s *sqliteDB) SearchThreadsStructured(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	return sqliteSearchThreads(ctx, s, s.hooks, s.db.QueryContext, req)
+}
+
+func (t *sqliteTx) SearchThreadsStructured(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	return sqliteSearchThreads(ctx, t, t.hooks, t.tx.QueryContext, req)
+}
+
+// sqliteSearchThreads is sqliteSearchPosts' analogue for SearchThreads/
+// SearchThreadsStructured. It also takes hooks/queryContext (unlike
+// sqliteSearchPosts) so it can batch-fetch Unread/UnreadCount when
+// req.ViewerID is set; see sqliteFetchUnreadCounts.
+func sqliteSearchThreads(ctx context.Context, db DB, hooks []QueryHook, queryContext sqliteQueryContextFunc, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	req.Limit, req.Offset = clampSearchPaging(req.Limit, req.Offset)
+	q := ThreadQuery{
+		Query:         req.Query,
+		BoardIDs:      req.BoardIDs,
+		AuthorIDs:     req.AuthorIDs,
+		IncludeTerms:  req.IncludeTerms,
+		ExcludeTerms:  req.ExcludeTerms,
+		PhraseTerms:   req.PhraseTerms,
+		CreatedAfter:  req.DateFrom,
+		CreatedBefore: req.DateTo,
+		SortDir:       OrderDesc,
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+		After:         req.After,
+		Before:        req.Before,
 	}
 	if req.BoardID != "" {
-		where = append(where, "board_id = ?")
-		args = append(args, req.BoardID)
+		q.BoardIDs = append(q.BoardIDs, req.BoardID)
+	}
+	if req.AuthorID != "" {
+		q.AuthorIDs = append(q.AuthorIDs, req.AuthorID)
 	}
-	whereSQL := strings.Join(where, " AND ")
 
-	countQuery := `SELECT COUNT(*) FROM threads WHERE ` + whereSQL
-	var total int
-	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	total, err := db.CountThreads(ctx, q)
+	if err != nil {
 		return nil, fmt.Errorf("search threads count: %w", err)
 	}
-
-	query := `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads
-        WHERE ` + whereSQL + `
-        ORDER BY created_at DESC
-        LIMIT ? OFFSET ?
-    `
-	argsWithLimit := append(args, req.Limit, req.Offset)
-	rows, err := s.db.QueryContext(ctx, query, argsWithLimit...)
+	threads, next, prev, err := db.ListThreads(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("search threads query: %w", err)
 	}
-	defer rows.Close()
 
-	var threads []Thread
-	for rows.Next() {
-		var th Thread
-		var closed int
-		if err := rows.Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID,
-			&th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed); err != nil {
-			return nil, fmt.Errorf("search threads scan: %w", err)
+	results := make([]ThreadSearchResult, len(threads))
+	for i, th := range threads {
+		results[i] = ThreadSearchResult{Thread: th}
+	}
+	if req.ViewerID != "" && len(threads) > 0 {
+		ids := make([]string, len(threads))
+		for i, th := range threads {
+			ids[i] = th.ID
+		}
+		counts, err := sqliteFetchUnreadCounts(ctx, hooks, queryContext, req.ViewerID, ids)
+		if err != nil {
+			return nil, fmt.Errorf("search threads unread counts: %w", err)
+		}
+		for i := range results {
+			results[i].UnreadCount = counts[results[i].ID]
+			results[i].Unread = counts[results[i].ID] > 0
 		}
-		th.IsClosed = closed != 0
-		threads = append(threads, th)
 	}
-
 	return &SearchThreadsResponse{
-		Threads:    threads,
+		Threads:    results,
 		TotalCount: total,
 		Limit:      req.Limit,
 		Offset:     req.Offset,
+		NextCursor: next,
+		PrevCursor: prev,
 	}, nil
 }
 
-func (t *sqliteTx) SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
-	if req.Limit <= 0 {
-		req.Limit = 20
+func (s *sqliteDB) SearchPostsWithContext(ctx context.Context, req *SearchPostsRequest) (*SearchPostsWithContextResponse, error) {
+	return sqliteSearchPostsWithContext(ctx, s, s.hooks, s.db.QueryContext, req)
+}
+
+func (t *sqliteTx) SearchPostsWithContext(ctx context.Context, req *SearchPostsRequest) (*SearchPostsWithContextResponse, error) {
+	return sqliteSearchPostsWithContext(ctx, t, t.hooks, t.tx.QueryContext, req)
+}
+
+// sqliteQueryContextFunc matches the signature *sql.DB.QueryContext and
+// *sql.Tx.QueryContext share, so sqliteSearchPostsWithContext's batch
+// lookups can run against either without sqliteDB/sqliteTx needing a common
+// embedded type.
+type sqliteQueryContextFunc func(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+
+// sqliteSearchPostsWithContext implements SearchPostsWithContext: it runs
+// SearchPosts as usual, then resolves the page's distinct thread_id/
+// board_id values with two "WHERE id IN (...)" queries instead of one
+// query per row, so a page of N results costs 3 round trips total rather
+// than 2N+1. There's no batch lookup for AuthorID — see PostWithContext's
+// doc comment for why.
+func sqliteSearchPostsWithContext(ctx context.Context, db DB, hooks []QueryHook, queryContext sqliteQueryContextFunc, req *SearchPostsRequest) (*SearchPostsWithContextResponse, error) {
+	resp, err := sqliteSearchPosts(ctx, db, req)
+	if err != nil {
+		return nil, err
 	}
-	if req.Offset < 0 {
-		req.Offset = 0
+
+	threadIDSet := make(map[string]struct{}, len(resp.Posts))
+	boardIDSet := make(map[string]struct{}, len(resp.Posts))
+	for _, p := range resp.Posts {
+		threadIDSet[p.ThreadID] = struct{}{}
+		boardIDSet[p.BoardID] = struct{}{}
 	}
 
-	where := []string{"1=1"}
-	args := []any{}
+	threads, err := sqliteFetchThreadsByIDs(ctx, hooks, queryContext, setKeys(threadIDSet))
+	if err != nil {
+		return nil, fmt.Errorf("search posts with context: %w", err)
+	}
+	boards, err := sqliteFetchBoardsByIDs(ctx, hooks, queryContext, setKeys(boardIDSet))
+	if err != nil {
+		return nil, fmt.Errorf("search posts with context: %w", err)
+	}
 
-	if req.Query != "" {
-		where = append(where, "title LIKE ?")
-		args = append(args, "%"+req.Query+"%")
+	posts := make([]PostWithContext, len(resp.Posts))
+	for i, p := range resp.Posts {
+		posts[i] = PostWithContext{Post: p.Post}
+		if th, ok := threads[p.ThreadID]; ok {
+			posts[i].Thread = th
+		}
+		if b, ok := boards[p.BoardID]; ok {
+			posts[i].Board = b
+		}
 	}
-	if req.BoardID != "" {
-		where = append(where, "board_id = ?")
-		args = append(args, req.BoardID)
+	return &SearchPostsWithContextResponse{
+		Posts:      posts,
+		TotalCount: resp.TotalCount,
+		Limit:      resp.Limit,
+		NextCursor: resp.NextCursor,
+		PrevCursor: resp.PrevCursor,
+	}, nil
+}
+
+// setKeys returns the keys of a struct{}-valued set as a slice, in whatever
+// order map iteration gives — fine here since sqliteFetchThreadsByIDs/
+// sqliteFetchBoardsByIDs only use it to build an IN (...) clause.
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
 	}
-	whereSQL := strings.Join(where, " AND ")
+	return keys
+}
 
-	countQuery := `SELECT COUNT(*) FROM threads WHERE ` + whereSQL
-	var total int
-	if err := t.tx.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, fmt.Errorf("search threads count(tx): %w", err)
+// sqliteFetchThreadsByIDs batch-fetches threads by ID, for callers (like
+// sqliteSearchPostsWithContext) that would otherwise fetch one thread per
+// result row. Returns an empty map, not an error, for an empty ids.
+func sqliteFetchThreadsByIDs(ctx context.Context, hooks []QueryHook, queryContext sqliteQueryContextFunc, ids []string) (map[string]*Thread, error) {
+	out := make(map[string]*Thread, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := fmt.Sprintf(`
+        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed, is_deleted
+        FROM threads WHERE id IN (%s)
+    `, sqliteDialect.placeholders(len(ids)))
+	rows, err := hookedQuery(ctx, hooks, "fetch_threads_by_ids", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return queryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch threads by ids: %w", err)
 	}
+	defer rows.Close()
 
-	query := `
-        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed
-        FROM threads
-        WHERE ` + whereSQL + `
-        ORDER BY created_at DESC
-        LIMIT ? OFFSET ?
-    `
-	argsWithLimit := append(args, req.Limit, req.Offset)
-	rows, err := t.tx.QueryContext(ctx, query, argsWithLimit...)
+	res, err := scanThreadRows(rows)
 	if err != nil {
-		return nil, fmt.Errorf("search threads query(tx): %w", err)
+		return nil, fmt.Errorf("fetch threads by ids scan: %w", err)
+	}
+	for i := range res {
+		out[res[i].ID] = &res[i]
+	}
+	return out, nil
+}
+
+// sqliteFetchBoardsByIDs is sqliteFetchThreadsByIDs' analogue for boards.
+func sqliteFetchBoardsByIDs(ctx context.Context, hooks []QueryHook, queryContext sqliteQueryContextFunc, ids []string) (map[string]*Board, error) {
+	out := make(map[string]*Board, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := fmt.Sprintf(`
+        SELECT id, name, description, created_at, updated_at, thread_count
+        FROM boards WHERE id IN (%s)
+    `, sqliteDialect.placeholders(len(ids)))
+	rows, err := hookedQuery(ctx, hooks, "fetch_boards_by_ids", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return queryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch boards by ids: %w", err)
 	}
 	defer rows.Close()
 
-	var threads []Thread
 	for rows.Next() {
-		var th Thread
-		var closed int
-		if err := rows.Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID,
-			&th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed); err != nil {
-			return nil, fmt.Errorf("search threads scan(tx): %w", err)
+		var b Board
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt, &b.ThreadCount); err != nil {
+			return nil, fmt.Errorf("fetch boards by ids scan: %w", err)
 		}
-		th.IsClosed = closed != 0
-		threads = append(threads, th)
+		out[b.ID] = &b
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("fetch boards by ids: %w", err)
+	}
+	return out, nil
+}
 
-	return &SearchThreadsResponse{
-		Threads:    threads,
-		TotalCount: total,
-		Limit:      req.Limit,
-		Offset:     req.Offset,
-	}, nil
+// sqliteFetchUnreadCounts batch-computes, for userID, how many non-deleted
+// posts in each of threadIDs were created after that thread's
+// thread_reads.last_read_at — one query for the whole page rather than one
+// per thread. A thread userID has never marked read (no thread_reads row)
+// counts every post unread; a thread missing from the returned map is
+// fully read (zero unread posts).
+func sqliteFetchUnreadCounts(ctx context.Context, hooks []QueryHook, queryContext sqliteQueryContextFunc, userID string, threadIDs []string) (map[string]int, error) {
+	out := make(map[string]int, len(threadIDs))
+	if len(threadIDs) == 0 {
+		return out, nil
+	}
+	args := make([]any, 0, len(threadIDs)+1)
+	args = append(args, userID)
+	for _, id := range threadIDs {
+		args = append(args, id)
+	}
+	query := fmt.Sprintf(`
+        SELECT p.thread_id, COUNT(*)
+        FROM posts p
+        LEFT JOIN thread_reads r ON r.thread_id = p.thread_id AND r.user_id = ?
+        WHERE p.is_deleted = %s AND p.thread_id IN (%s)
+          AND (r.last_read_at IS NULL OR p.created_at > r.last_read_at)
+        GROUP BY p.thread_id
+    `, sqliteDialect.boolLiteral(false), sqliteDialect.placeholders(len(threadIDs)))
+	rows, err := hookedQuery(ctx, hooks, "fetch_unread_counts", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return queryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch unread counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var threadID string
+		var count int
+		if err := rows.Scan(&threadID, &count); err != nil {
+			return nil, fmt.Errorf("fetch unread counts scan: %w", err)
+		}
+		out[threadID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("fetch unread counts: %w", err)
+	}
+	return out, nil
+}
+
+func (s *sqliteDB) MarkRead(ctx context.Context, userID, threadID, postID string) error {
+	return sqliteMarkRead(ctx, s.db.QueryRowContext, s.db.ExecContext, userID, threadID, postID)
+}
+
+func (t *sqliteTx) MarkRead(ctx context.Context, userID, threadID, postID string) error {
+	return sqliteMarkRead(ctx, t.tx.QueryRowContext, t.tx.ExecContext, userID, threadID, postID)
+}
+
+// sqliteExecContextFunc matches *sql.DB.ExecContext/*sql.Tx.ExecContext's
+// shared signature, the ExecContext counterpart to sqliteQueryContextFunc.
+type sqliteExecContextFunc func(ctx context.Context, query string, args ...any) (sql.Result, error)
+
+// sqliteMarkRead sets last_read_at to postID's own created_at, so
+// thread_reads.last_read_at always lines up with an actual post in the
+// thread rather than the wall-clock time MarkRead happened to be called —
+// UnreadCount's "posts.created_at > thread_reads.last_read_at" comparison
+// only makes sense read that way.
+func sqliteMarkRead(ctx context.Context, queryRow func(ctx context.Context, query string, args ...any) *sql.Row, exec sqliteExecContextFunc, userID, threadID, postID string) error {
+	var createdAt time.Time
+	err := queryRow(ctx, `SELECT created_at FROM posts WHERE id = ? AND thread_id = ?`, postID, threadID).Scan(&createdAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("mark read: post %s not found in thread %s", postID, threadID)
+	}
+	if err != nil {
+		return fmt.Errorf("mark read get post: %w", err)
+	}
+
+	_, err = exec(ctx, `
+        INSERT INTO thread_reads (user_id, thread_id, last_read_post_id, last_read_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(user_id, thread_id) DO UPDATE SET
+            last_read_post_id = excluded.last_read_post_id,
+            last_read_at = excluded.last_read_at
+    `, userID, threadID, postID, createdAt)
+	if err != nil {
+		return fmt.Errorf("mark read: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteDB) MarkAllRead(ctx context.Context, userID, boardID string) error {
+	return sqliteMarkAllRead(ctx, s.db.ExecContext, userID, boardID)
+}
+
+func (t *sqliteTx) MarkAllRead(ctx context.Context, userID, boardID string) error {
+	return sqliteMarkAllRead(ctx, t.tx.ExecContext, userID, boardID)
+}
+
+// sqliteMarkAllRead marks every thread in boardID read for userID as of
+// now, in one statement rather than one MarkRead per thread. Unlike
+// MarkRead, there's no single anchor post for "every thread in a board", so
+// last_read_post_id is left blank and last_read_at is the current time.
+func sqliteMarkAllRead(ctx context.Context, exec sqliteExecContextFunc, userID, boardID string) error {
+	_, err := exec(ctx, `
+        INSERT INTO thread_reads (user_id, thread_id, last_read_post_id, last_read_at)
+        SELECT ?, id, '', ? FROM threads WHERE board_id = ?
+        ON CONFLICT(user_id, thread_id) DO UPDATE SET
+            last_read_post_id = excluded.last_read_post_id,
+            last_read_at = excluded.last_read_at
+    `, userID, time.Now().UTC(), boardID)
+	if err != nil {
+		return fmt.Errorf("mark all read: %w", err)
+	}
+	return nil
+}
+
+// Recalculate scans boardID's threads (or every thread, if boardID is "")
+// and rewrites each one's post_count from an authoritative
+// SELECT COUNT(*) FROM posts WHERE thread_id = ? AND is_deleted = 0,
+// correcting drift DeletePost/CreatePost's running increment/decrement may
+// have accumulated across upgrades or bugs — the "recalculator to fix
+// abnormalities" pattern, as a maintenance routine against the top-level DB
+// rather than something meant to run nested inside a WithTx callback.
+func (s *sqliteDB) Recalculate(ctx context.Context, boardID string) (RecalcReport, error) {
+	return sqliteRecalculate(ctx, s.hooks, s.db.QueryContext, s.db.ExecContext, boardID)
+}
+
+func (t *sqliteTx) Recalculate(ctx context.Context, boardID string) (RecalcReport, error) {
+	return sqliteRecalculate(ctx, t.hooks, t.tx.QueryContext, t.tx.ExecContext, boardID)
+}
+
+// sqliteRecalculate is sqliteDB.Recalculate's/sqliteTx.Recalculate's shared
+// implementation.
+func sqliteRecalculate(ctx context.Context, hooks []QueryHook, queryContext sqliteQueryContextFunc, exec sqliteExecContextFunc, boardID string) (RecalcReport, error) {
+	query, args := sqliteBuildRecalcQuery(boardID)
+	rows, err := hookedQuery(ctx, hooks, "recalculate", query, args, func(ctx context.Context) (*sql.Rows, error) {
+		return queryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return RecalcReport{}, fmt.Errorf("recalculate scan: %w", err)
+	}
+	type drifted struct {
+		id     string
+		actual int
+	}
+	var report RecalcReport
+	var toFix []drifted
+	for rows.Next() {
+		var id string
+		var recorded, actual int
+		if err := rows.Scan(&id, &recorded, &actual); err != nil {
+			rows.Close()
+			return RecalcReport{}, fmt.Errorf("recalculate scan row: %w", err)
+		}
+		report.ThreadsScanned++
+		if recorded != actual {
+			toFix = append(toFix, drifted{id: id, actual: actual})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return RecalcReport{}, err
+	}
+	rows.Close()
+
+	now := time.Now().UTC()
+	for _, d := range toFix {
+		if _, err := exec(ctx, `UPDATE threads SET post_count = ?, updated_at = ? WHERE id = ?`, d.actual, now, d.id); err != nil {
+			return RecalcReport{}, fmt.Errorf("recalculate fix %s: %w", d.id, err)
+		}
+		report.ThreadsFixed++
+	}
+	return report, nil
+}
+
+// sqliteBuildRecalcQuery builds Recalculate's scan query: one row per
+// thread (optionally restricted to boardID), with its recorded post_count
+// alongside the actual non-deleted post count so the caller can diff them
+// without a second round trip per thread.
+func sqliteBuildRecalcQuery(boardID string) (query string, args []any) {
+	where := "1=1"
+	if boardID != "" {
+		where = "t.board_id = ?"
+		args = append(args, boardID)
+	}
+	query = fmt.Sprintf(`
+        SELECT t.id, t.post_count, COALESCE(SUM(CASE WHEN p.is_deleted = %s THEN 1 ELSE 0 END), 0)
+        FROM threads t
+        LEFT JOIN posts p ON p.thread_id = t.id
+        WHERE %s
+        GROUP BY t.id, t.post_count
+    `, sqliteDialect.boolLiteral(false), where)
+	return query, args
 }
 
 // ========================================
@@ -1048,6 +1829,10 @@ func (t *sqliteTx) SearchThreads(ctx context.Context, req *SearchThreadsRequest)
 // ========================================
 
 func (s *sqliteDB) Close() error {
+	if err := s.cache.close(); err != nil {
+		_ = s.db.Close()
+		return err
+	}
 	return s.db.Close()
 }
 
@@ -1056,13 +1841,6 @@ func (t *sqliteTx) Close() error {
 	return nil
 }
 
-func boolToInt(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
-}
-
 func nullIfEmpty(s string) any {
 	if s == "" {
 		return nil
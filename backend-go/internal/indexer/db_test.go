@@ -2,6 +2,9 @@ package indexer
 
 import (
     "context"
+    "errors"
+    "fmt"
+    "reflect"
     "testing"
     "time"
 )
@@ -109,4 +112,664 @@ func TestSQLiteDB_LogSequence(t *testing.T) {
     if seq != 42 {
         t.Fatalf("last_seq = %d, want 42", seq)
     }
+}
+
+func TestSQLiteDB_NestedWithTxRollsBackOnlyInnerScope(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+
+    errBoom := errors.New("boom")
+    err = db.WithTx(ctx, func(outer DB) error {
+        if err := outer.CreateThread(ctx, &Thread{ID: "thread-outer", BoardID: "board1", Title: "Outer", AuthorID: "user1"}); err != nil {
+            return err
+        }
+
+        innerErr := outer.WithTx(ctx, func(inner DB) error {
+            if err := inner.CreateThread(ctx, &Thread{ID: "thread-inner", BoardID: "board1", Title: "Inner", AuthorID: "user1"}); err != nil {
+                return err
+            }
+            return errBoom
+        })
+        if !errors.Is(innerErr, errBoom) {
+            t.Fatalf("inner WithTx error = %v, want errBoom", innerErr)
+        }
+
+        // The inner failure should only have undone thread-inner; the outer
+        // scope (including thread-outer) must still be intact here.
+        th, err := outer.GetThread(ctx, "thread-outer")
+        if err != nil {
+            return err
+        }
+        if th == nil {
+            t.Fatalf("thread-outer missing after inner rollback")
+        }
+        return nil
+    })
+    if err != nil {
+        t.Fatalf("outer WithTx: %v", err)
+    }
+
+    outerThread, err := db.GetThread(ctx, "thread-outer")
+    if err != nil {
+        t.Fatalf("GetThread(thread-outer): %v", err)
+    }
+    if outerThread == nil {
+        t.Fatalf("thread-outer was not committed")
+    }
+    innerThread, err := db.GetThread(ctx, "thread-inner")
+    if err != nil {
+        t.Fatalf("GetThread(thread-inner): %v", err)
+    }
+    if innerThread != nil {
+        t.Fatalf("thread-inner should have been rolled back by its savepoint")
+    }
+}
+
+func TestSQLiteDB_DoublyNestedWithTxUsesDistinctSavepoints(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+
+    errInnermost := errors.New("innermost failure")
+    err = db.WithTx(ctx, func(l1 DB) error {
+        return l1.WithTx(ctx, func(l2 DB) error {
+            if err := l2.CreateThread(ctx, &Thread{ID: "thread-l2", BoardID: "board1", Title: "L2", AuthorID: "user1"}); err != nil {
+                return err
+            }
+            // Swallow the innermost failure here: only its own savepoint
+            // should roll back, so this level's savepoint still RELEASEs
+            // and thread-l2 survives.
+            innerErr := l2.WithTx(ctx, func(l3 DB) error {
+                if err := l3.CreateThread(ctx, &Thread{ID: "thread-l3", BoardID: "board1", Title: "L3", AuthorID: "user1"}); err != nil {
+                    return err
+                }
+                return errInnermost
+            })
+            if !errors.Is(innerErr, errInnermost) {
+                t.Fatalf("innermost WithTx error = %v, want errInnermost", innerErr)
+            }
+            return nil
+        })
+    })
+    if err != nil {
+        t.Fatalf("outer WithTx: %v", err)
+    }
+
+    l2Thread, err := db.GetThread(ctx, "thread-l2")
+    if err != nil {
+        t.Fatalf("GetThread(thread-l2): %v", err)
+    }
+    if l2Thread == nil {
+        t.Fatalf("thread-l2 should have survived the innermost rollback")
+    }
+    l3Thread, err := db.GetThread(ctx, "thread-l3")
+    if err != nil {
+        t.Fatalf("GetThread(thread-l3): %v", err)
+    }
+    if l3Thread != nil {
+        t.Fatalf("thread-l3 should have been rolled back by its own savepoint")
+    }
+}
+
+func TestSQLiteDB_ListThreadsByBoardPaginatesByKeyset(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    for i := 0; i < 5; i++ {
+        id := fmt.Sprintf("thread%d", i)
+        if err := db.CreateThread(ctx, &Thread{
+            ID:        id,
+            BoardID:   "board1",
+            Title:     id,
+            AuthorID:  "user1",
+            CreatedAt: base.Add(time.Duration(i) * time.Minute),
+        }); err != nil {
+            t.Fatalf("CreateThread(%s): %v", id, err)
+        }
+    }
+
+    // First page of 2, oldest-first (the zero Order).
+    page1, next1, prev1, err := db.ListThreadsByBoard(ctx, "board1", ListOpts{Limit: 2})
+    if err != nil {
+        t.Fatalf("ListThreadsByBoard page1: %v", err)
+    }
+    if ids := threadIDs(page1); !reflect.DeepEqual(ids, []string{"thread0", "thread1"}) {
+        t.Fatalf("page1 = %v, want [thread0 thread1]", ids)
+    }
+    if prev1 != "" {
+        t.Fatalf("page1 prev = %q, want empty (first page)", prev1)
+    }
+    if next1 == "" {
+        t.Fatalf("page1 next should not be empty: more rows remain")
+    }
+
+    // Second page, following next1.
+    page2, next2, prev2, err := db.ListThreadsByBoard(ctx, "board1", ListOpts{Limit: 2, After: next1})
+    if err != nil {
+        t.Fatalf("ListThreadsByBoard page2: %v", err)
+    }
+    if ids := threadIDs(page2); !reflect.DeepEqual(ids, []string{"thread2", "thread3"}) {
+        t.Fatalf("page2 = %v, want [thread2 thread3]", ids)
+    }
+    if prev2 == "" {
+        t.Fatalf("page2 prev should not be empty: there's a page before it")
+    }
+    if next2 == "" {
+        t.Fatalf("page2 next should not be empty: thread4 remains")
+    }
+
+    // Last page.
+    page3, next3, _, err := db.ListThreadsByBoard(ctx, "board1", ListOpts{Limit: 2, After: next2})
+    if err != nil {
+        t.Fatalf("ListThreadsByBoard page3: %v", err)
+    }
+    if ids := threadIDs(page3); !reflect.DeepEqual(ids, []string{"thread4"}) {
+        t.Fatalf("page3 = %v, want [thread4]", ids)
+    }
+    if next3 != "" {
+        t.Fatalf("page3 next = %q, want empty (no more rows)", next3)
+    }
+
+    // Walking backward from page2's prev cursor should land back on page1.
+    back, _, _, err := db.ListThreadsByBoard(ctx, "board1", ListOpts{Limit: 2, Before: prev2})
+    if err != nil {
+        t.Fatalf("ListThreadsByBoard Before: %v", err)
+    }
+    if ids := threadIDs(back); !reflect.DeepEqual(ids, []string{"thread0", "thread1"}) {
+        t.Fatalf("Before page = %v, want [thread0 thread1]", ids)
+    }
+}
+
+func threadIDs(threads []Thread) []string {
+    ids := make([]string, len(threads))
+    for i, th := range threads {
+        ids[i] = th.ID
+    }
+    return ids
+}
+
+func TestSQLiteDB_ListPostsByThreadPaginatesByKeysetDesc(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "T", AuthorID: "user1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    for i := 0; i < 3; i++ {
+        id := fmt.Sprintf("post%d", i)
+        if err := db.CreatePost(ctx, &Post{
+            ID:        id,
+            ThreadID:  "thread1",
+            BoardID:   "board1",
+            AuthorID:  "user1",
+            Content:   id,
+            CreatedAt: base.Add(time.Duration(i) * time.Minute),
+        }); err != nil {
+            t.Fatalf("CreatePost(%s): %v", id, err)
+        }
+    }
+
+    // Newest-first, one page.
+    page1, next1, prev1, err := db.ListPostsByThread(ctx, "thread1", ListOpts{Limit: 2, Order: OrderDesc})
+    if err != nil {
+        t.Fatalf("ListPostsByThread page1: %v", err)
+    }
+    if ids := postIDs(page1); !reflect.DeepEqual(ids, []string{"post2", "post1"}) {
+        t.Fatalf("page1 = %v, want [post2 post1]", ids)
+    }
+    if prev1 != "" {
+        t.Fatalf("page1 prev = %q, want empty", prev1)
+    }
+    if next1 == "" {
+        t.Fatalf("page1 next should not be empty: post0 remains")
+    }
+
+    page2, next2, _, err := db.ListPostsByThread(ctx, "thread1", ListOpts{Limit: 2, Order: OrderDesc, After: next1})
+    if err != nil {
+        t.Fatalf("ListPostsByThread page2: %v", err)
+    }
+    if ids := postIDs(page2); !reflect.DeepEqual(ids, []string{"post0"}) {
+        t.Fatalf("page2 = %v, want [post0]", ids)
+    }
+    if next2 != "" {
+        t.Fatalf("page2 next = %q, want empty", next2)
+    }
+}
+
+func postIDs(posts []Post) []string {
+    ids := make([]string, len(posts))
+    for i, p := range posts {
+        ids[i] = p.ID
+    }
+    return ids
+}
+
+func TestSQLiteDB_ListPostsAndListThreadsFilterByQuery(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "T", AuthorID: "user1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "post1", ThreadID: "thread1", BoardID: "board1", AuthorID: "user1", Content: "hello"}); err != nil {
+        t.Fatalf("CreatePost#1: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "post2", ThreadID: "thread1", BoardID: "board1", AuthorID: "user2", Content: "reply", ReplyTo: "post1"}); err != nil {
+        t.Fatalf("CreatePost#2: %v", err)
+    }
+    if err := db.DeletePost(ctx, "post2"); err != nil {
+        t.Fatalf("DeletePost: %v", err)
+    }
+
+    total, err := db.CountPosts(ctx, PostQuery{AuthorIDs: []string{"user1"}})
+    if err != nil {
+        t.Fatalf("CountPosts: %v", err)
+    }
+    if total != 1 {
+        t.Fatalf("CountPosts = %d, want 1", total)
+    }
+
+    total, err = db.CountPosts(ctx, PostQuery{IncludeDeleted: true})
+    if err != nil {
+        t.Fatalf("CountPosts(IncludeDeleted): %v", err)
+    }
+    if total != 2 {
+        t.Fatalf("CountPosts(IncludeDeleted) = %d, want 2", total)
+    }
+
+    hasReplies := true
+    posts, _, _, err := db.ListPosts(ctx, PostQuery{HasReplies: &hasReplies, IncludeDeleted: true})
+    if err != nil {
+        t.Fatalf("ListPosts(HasReplies): %v", err)
+    }
+    if ids := postIDs(posts); !reflect.DeepEqual(ids, []string{"post1"}) {
+        t.Fatalf("ListPosts(HasReplies) = %v, want [post1]", ids)
+    }
+
+    threads, _, _, err := db.ListThreads(ctx, ThreadQuery{Query: "t"})
+    if err != nil {
+        t.Fatalf("ListThreads: %v", err)
+    }
+    if len(threads) != 1 || threads[0].ID != "thread1" {
+        t.Fatalf("ListThreads mismatch: %+v", threads)
+    }
+
+    threadTotal, err := db.CountThreads(ctx, ThreadQuery{BoardIDs: []string{"board1"}})
+    if err != nil {
+        t.Fatalf("CountThreads: %v", err)
+    }
+    if threadTotal != 1 {
+        t.Fatalf("CountThreads = %d, want 1", threadTotal)
+    }
+}
+
+func TestSQLiteDB_SearchPostsWithContextAttachesThreadAndBoard(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "Thread One", AuthorID: "user1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "post1", ThreadID: "thread1", BoardID: "board1", AuthorID: "user1", Content: "hello there"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+
+    sdb := db.(*sqliteDB)
+    resp, err := sdb.SearchPostsWithContext(ctx, &SearchPostsRequest{Query: "hello"})
+    if err != nil {
+        t.Fatalf("SearchPostsWithContext: %v", err)
+    }
+    if len(resp.Posts) != 1 {
+        t.Fatalf("Posts = %d, want 1", len(resp.Posts))
+    }
+    got := resp.Posts[0]
+    if got.Thread == nil || got.Thread.ID != "thread1" || got.Thread.Title != "Thread One" {
+        t.Fatalf("Thread mismatch: %+v", got.Thread)
+    }
+    if got.Board == nil || got.Board.ID != "board1" || got.Board.Name != "Board One" {
+        t.Fatalf("Board mismatch: %+v", got.Board)
+    }
+}
+
+// benchSeedSearchPage creates one board/thread and n posts in it for the
+// SearchPostsWithContext benchmarks below.
+func benchSeedSearchPage(b *testing.B, ctx context.Context, db DB, n int) {
+    b.Helper()
+    if err := db.CreateBoard(ctx, &Board{ID: "bench-board", Name: "Bench"}); err != nil {
+        b.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "bench-thread", BoardID: "bench-board", Title: "Bench", AuthorID: "bench-user"}); err != nil {
+        b.Fatalf("CreateThread: %v", err)
+    }
+    for i := 0; i < n; i++ {
+        if err := db.CreatePost(ctx, &Post{
+            ID:       fmt.Sprintf("bench-post-%d", i),
+            ThreadID: "bench-thread",
+            BoardID:  "bench-board",
+            AuthorID: "bench-user",
+            Content:  "searchable content",
+        }); err != nil {
+            b.Fatalf("CreatePost: %v", err)
+        }
+    }
+}
+
+// BenchmarkSearchPosts_NPlusOne mirrors what a caller without
+// SearchPostsWithContext has to do today: one SearchPosts call, then a
+// GetThread/GetBoard round trip per row, to get the page Thread/Board
+// attached to the N+1 calls SearchPostsWithContext eliminates.
+func BenchmarkSearchPosts_NPlusOne(b *testing.B) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        b.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+    benchSeedSearchPage(b, ctx, db, 20)
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        resp, err := db.SearchPosts(ctx, &SearchPostsRequest{Query: "searchable", Limit: 20})
+        if err != nil {
+            b.Fatalf("SearchPosts: %v", err)
+        }
+        for _, p := range resp.Posts {
+            if _, err := db.GetThread(ctx, p.ThreadID); err != nil {
+                b.Fatalf("GetThread: %v", err)
+            }
+            if _, err := db.GetBoard(ctx, p.BoardID); err != nil {
+                b.Fatalf("GetBoard: %v", err)
+            }
+        }
+    }
+}
+
+// BenchmarkSearchPostsWithContext_Batched exercises SearchPostsWithContext
+// on the same page, which resolves the same Thread/Board data with two
+// batched IN (...) queries total instead of one query per row.
+func BenchmarkSearchPostsWithContext_Batched(b *testing.B) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        b.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+    benchSeedSearchPage(b, ctx, db, 20)
+
+    sdb := db.(*sqliteDB)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := sdb.SearchPostsWithContext(ctx, &SearchPostsRequest{Query: "searchable", Limit: 20}); err != nil {
+            b.Fatalf("SearchPostsWithContext: %v", err)
+        }
+    }
+}
+
+func TestSQLiteDB_MarkReadAndSearchThreadsUnreadCount(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "T1", AuthorID: "author1"}); err != nil {
+        t.Fatalf("CreateThread#1: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread2", BoardID: "board1", Title: "T2", AuthorID: "author1"}); err != nil {
+        t.Fatalf("CreateThread#2: %v", err)
+    }
+    base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    for i, id := range []string{"post0", "post1", "post2"} {
+        if err := db.CreatePost(ctx, &Post{
+            ID:        id,
+            ThreadID:  "thread1",
+            BoardID:   "board1",
+            AuthorID:  "author1",
+            Content:   "hello",
+            CreatedAt: base.Add(time.Duration(i) * time.Minute),
+        }); err != nil {
+            t.Fatalf("CreatePost(%s): %v", id, err)
+        }
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "t2post0", ThreadID: "thread2", BoardID: "board1", AuthorID: "author1", Content: "hello", CreatedAt: base}); err != nil {
+        t.Fatalf("CreatePost(t2post0): %v", err)
+    }
+
+    // Before any read, everything is unread.
+    resp, err := db.SearchThreads(ctx, &SearchThreadsRequest{Query: "t", ViewerID: "viewer1"})
+    if err != nil {
+        t.Fatalf("SearchThreads: %v", err)
+    }
+    unreadByID := map[string]ThreadSearchResult{}
+    for _, th := range resp.Threads {
+        unreadByID[th.ID] = th
+    }
+    if !unreadByID["thread1"].Unread || unreadByID["thread1"].UnreadCount != 3 {
+        t.Fatalf("thread1 before MarkRead = %+v, want Unread=true UnreadCount=3", unreadByID["thread1"])
+    }
+    if !unreadByID["thread2"].Unread || unreadByID["thread2"].UnreadCount != 1 {
+        t.Fatalf("thread2 before MarkRead = %+v, want Unread=true UnreadCount=1", unreadByID["thread2"])
+    }
+
+    // MarkRead up through post1 leaves post2 unread.
+    if err := db.MarkRead(ctx, "viewer1", "thread1", "post1"); err != nil {
+        t.Fatalf("MarkRead: %v", err)
+    }
+    resp, err = db.SearchThreads(ctx, &SearchThreadsRequest{Query: "t", ViewerID: "viewer1"})
+    if err != nil {
+        t.Fatalf("SearchThreads after MarkRead: %v", err)
+    }
+    for _, th := range resp.Threads {
+        unreadByID[th.ID] = th
+    }
+    if !unreadByID["thread1"].Unread || unreadByID["thread1"].UnreadCount != 1 {
+        t.Fatalf("thread1 after MarkRead = %+v, want Unread=true UnreadCount=1", unreadByID["thread1"])
+    }
+
+    // MarkAllRead clears every thread in the board.
+    if err := db.MarkAllRead(ctx, "viewer1", "board1"); err != nil {
+        t.Fatalf("MarkAllRead: %v", err)
+    }
+    resp, err = db.SearchThreads(ctx, &SearchThreadsRequest{Query: "t", ViewerID: "viewer1"})
+    if err != nil {
+        t.Fatalf("SearchThreads after MarkAllRead: %v", err)
+    }
+    for _, th := range resp.Threads {
+        if th.Unread || th.UnreadCount != 0 {
+            t.Fatalf("thread %s after MarkAllRead = %+v, want fully read", th.ID, th)
+        }
+    }
+
+    // A different viewer who never read anything still sees it all unread.
+    resp, err = db.SearchThreads(ctx, &SearchThreadsRequest{Query: "t", ViewerID: "viewer2"})
+    if err != nil {
+        t.Fatalf("SearchThreads(viewer2): %v", err)
+    }
+    for _, th := range resp.Threads {
+        if !th.Unread {
+            t.Fatalf("thread %s for viewer2 = %+v, want Unread=true", th.ID, th)
+        }
+    }
+
+    // Without ViewerID, Unread/UnreadCount stay at their zero values.
+    resp, err = db.SearchThreads(ctx, &SearchThreadsRequest{Query: "t"})
+    if err != nil {
+        t.Fatalf("SearchThreads(no viewer): %v", err)
+    }
+    for _, th := range resp.Threads {
+        if th.Unread || th.UnreadCount != 0 {
+            t.Fatalf("thread %s without ViewerID = %+v, want zero value", th.ID, th)
+        }
+    }
+}
+
+func TestSQLiteDB_DeleteThreadCascadesToPostsAndDecrementsBoardCount(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "T1", AuthorID: "author1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    for _, id := range []string{"post1", "post2"} {
+        if err := db.CreatePost(ctx, &Post{ID: id, ThreadID: "thread1", BoardID: "board1", AuthorID: "author1", Content: "hello"}); err != nil {
+            t.Fatalf("CreatePost(%s): %v", id, err)
+        }
+    }
+
+    if err := db.DeleteThread(ctx, "thread1"); err != nil {
+        t.Fatalf("DeleteThread: %v", err)
+    }
+
+    th, err := db.GetThread(ctx, "thread1")
+    if err != nil {
+        t.Fatalf("GetThread: %v", err)
+    }
+    if !th.IsDeleted {
+        t.Fatalf("thread1.IsDeleted = false, want true")
+    }
+
+    for _, id := range []string{"post1", "post2"} {
+        p, err := db.GetPost(ctx, id)
+        if err != nil {
+            t.Fatalf("GetPost(%s): %v", id, err)
+        }
+        if !p.IsDeleted {
+            t.Fatalf("post %s IsDeleted = false, want true", id)
+        }
+    }
+
+    board, err := db.GetBoard(ctx, "board1")
+    if err != nil {
+        t.Fatalf("GetBoard: %v", err)
+    }
+    if board.ThreadCount != 0 {
+        t.Fatalf("board.ThreadCount = %d, want 0", board.ThreadCount)
+    }
+
+    // A second DeleteThread on an already-deleted (or missing) thread is a no-op.
+    if err := db.DeleteThread(ctx, "thread1"); err != nil {
+        t.Fatalf("DeleteThread (again): %v", err)
+    }
+    if err := db.DeleteThread(ctx, "does-not-exist"); err != nil {
+        t.Fatalf("DeleteThread (missing): %v", err)
+    }
+}
+
+func TestSQLiteDB_RecalculateFixesPostCountDrift(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "T1", AuthorID: "author1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    for _, id := range []string{"post1", "post2", "post3"} {
+        if err := db.CreatePost(ctx, &Post{ID: id, ThreadID: "thread1", BoardID: "board1", AuthorID: "author1", Content: "hello"}); err != nil {
+            t.Fatalf("CreatePost(%s): %v", id, err)
+        }
+    }
+    if err := db.DeletePost(ctx, "post3"); err != nil {
+        t.Fatalf("DeletePost: %v", err)
+    }
+
+    // Intentionally corrupt post_count so it no longer matches the two
+    // non-deleted posts DeletePost's decrement just left behind.
+    th, err := db.GetThread(ctx, "thread1")
+    if err != nil {
+        t.Fatalf("GetThread: %v", err)
+    }
+    th.PostCount = 99
+    if err := db.UpdateThread(ctx, th); err != nil {
+        t.Fatalf("UpdateThread: %v", err)
+    }
+
+    report, err := db.Recalculate(ctx, "")
+    if err != nil {
+        t.Fatalf("Recalculate: %v", err)
+    }
+    if report.ThreadsScanned != 1 {
+        t.Fatalf("ThreadsScanned = %d, want 1", report.ThreadsScanned)
+    }
+    if report.ThreadsFixed != 1 {
+        t.Fatalf("ThreadsFixed = %d, want 1", report.ThreadsFixed)
+    }
+
+    th, err = db.GetThread(ctx, "thread1")
+    if err != nil {
+        t.Fatalf("GetThread after Recalculate: %v", err)
+    }
+    if th.PostCount != 2 {
+        t.Fatalf("thread1.PostCount = %d, want 2", th.PostCount)
+    }
+
+    // A second run against the now-consistent count fixes nothing.
+    report, err = db.Recalculate(ctx, "board1")
+    if err != nil {
+        t.Fatalf("Recalculate (scoped): %v", err)
+    }
+    if report.ThreadsFixed != 0 {
+        t.Fatalf("ThreadsFixed = %d, want 0", report.ThreadsFixed)
+    }
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dialect captures the handful of SQL differences between the storage
+// engines DB has concrete implementations for (sqliteDB, pgDB), so a query
+// built against one isn't silently wrong against the other: sqlite3 takes
+// positional "?" placeholders and has no native boolean type, while
+// Postgres takes "$1"/"$2"/... placeholders and stores bool natively.
+type dialect struct {
+	name string
+}
+
+var (
+	sqliteDialect   = dialect{name: "sqlite3"}
+	postgresDialect = dialect{name: "pgx"}
+)
+
+// placeholder returns the bind-parameter syntax for the i'th (1-indexed)
+// argument of a query under this dialect.
+func (d dialect) placeholder(i int) string {
+	if d.name == "pgx" {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// placeholders returns n sequential placeholders, comma-joined, starting at
+// 1 — e.g. sqliteDialect.placeholders(3) is "?, ?, ?" and
+// postgresDialect.placeholders(3) is "$1, $2, $3".
+func (d dialect) placeholders(n int) string {
+	parts := make([]string, n)
+	for i := 0; i < n; i++ {
+		parts[i] = d.placeholder(i + 1)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// boolParam converts b into whatever database/sql argument this dialect's
+// driver expects for a boolean column: sqlite3 has no BOOLEAN type, so
+// is_deleted/is_closed are declared INTEGER and b must be encoded as 0/1;
+// Postgres's BOOLEAN columns take a Go bool directly.
+func (d dialect) boolParam(b bool) any {
+	if d.name == "pgx" {
+		return b
+	}
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// boolLiteral renders b as literal SQL text rather than a bind argument,
+// for a WHERE clause built once up front (e.g. PostQuery's is_deleted
+// filter) rather than per-request like boolParam's callers.
+func (d dialect) boolLiteral(b bool) string {
+	if d.name == "pgx" {
+		if b {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// likeOperator returns the case-sensitivity-appropriate substring-match
+// operator for this dialect's SearchPosts/SearchThreads/PostQuery/
+// ThreadQuery scans: Postgres's LIKE is case-sensitive, so pgDB has always
+// used ILIKE instead.
+func (d dialect) likeOperator() string {
+	if d.name == "pgx" {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryEvent describes a single statement executed by sqliteDB/sqliteTx. It
+// is passed to QueryHook.BeforeQuery before the statement runs (Duration and
+// Err are zero then) and to QueryHook.AfterQuery once it returns.
+type QueryEvent struct {
+	Op       string
+	SQL      string
+	Args     []any
+	Duration time.Duration
+	Err      error
+}
+
+// QueryHook observes every statement sqliteDB and sqliteTx execute, in the
+// spirit of bun's QueryHook: BeforeQuery runs immediately before the
+// statement reaches the driver and may return a derived context (e.g. one
+// carrying a tracing span), which is used for the call itself and handed
+// back to AfterQuery. AfterQuery runs once the call returns, with Duration
+// and Err filled in.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, event QueryEvent) context.Context
+	AfterQuery(ctx context.Context, event QueryEvent)
+}
+
+// Options configures optional cross-cutting behavior for
+// NewSQLiteDBWithOptions.
+type Options struct {
+	// Hooks observe every statement sqliteDB (and any sqliteTx it opens)
+	// executes; see QueryHook. Fired in order on BeforeQuery, and in the
+	// same order on AfterQuery.
+	Hooks []QueryHook
+}
+
+// runHooks wraps a single call to the driver (fn) with BeforeQuery/
+// AfterQuery for every hook, timing the call and capturing whatever error
+// fn returns. fn runs with the context returned by the last hook's
+// BeforeQuery, so e.g. a tracing hook's span is active for the call itself.
+func runHooks(ctx context.Context, hooks []QueryHook, op, sqlText string, args []any, fn func(ctx context.Context) error) error {
+	if len(hooks) == 0 {
+		return fn(ctx)
+	}
+	start := time.Now()
+	for _, h := range hooks {
+		ctx = h.BeforeQuery(ctx, QueryEvent{Op: op, SQL: sqlText, Args: args})
+	}
+	err := fn(ctx)
+	ev := QueryEvent{Op: op, SQL: sqlText, Args: args, Duration: time.Since(start), Err: err}
+	for _, h := range hooks {
+		h.AfterQuery(ctx, ev)
+	}
+	return err
+}
+
+// hookedExec/hookedQuery/hookedQueryRow adapt runHooks to ExecContext's,
+// QueryContext's, and QueryRowContext's respective return shapes, so every
+// call site in db.go can wrap its driver call the same way regardless of
+// whether it goes through a cached *sql.Stmt or a one-off query string.
+
+func hookedExec(ctx context.Context, hooks []QueryHook, op, sqlText string, args []any, fn func(ctx context.Context) (sql.Result, error)) (sql.Result, error) {
+	var res sql.Result
+	err := runHooks(ctx, hooks, op, sqlText, args, func(ctx context.Context) error {
+		var ferr error
+		res, ferr = fn(ctx)
+		return ferr
+	})
+	return res, err
+}
+
+func hookedQuery(ctx context.Context, hooks []QueryHook, op, sqlText string, args []any, fn func(ctx context.Context) (*sql.Rows, error)) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := runHooks(ctx, hooks, op, sqlText, args, func(ctx context.Context) error {
+		var ferr error
+		rows, ferr = fn(ctx)
+		return ferr
+	})
+	return rows, err
+}
+
+// hookedQueryRow wraps QueryRowContext. *sql.Row defers its error to Scan,
+// so the AfterQuery event this fires always has Err == nil; a hook that
+// needs the scan error has nowhere to get it from at this layer.
+func hookedQueryRow(ctx context.Context, hooks []QueryHook, op, sqlText string, args []any, fn func(ctx context.Context) *sql.Row) *sql.Row {
+	var row *sql.Row
+	_ = runHooks(ctx, hooks, op, sqlText, args, func(ctx context.Context) error {
+		row = fn(ctx)
+		return nil
+	})
+	return row
+}
+
+// hookedStmt wraps a *sql.Stmt (sqliteDB's own, or one bound to a sqliteTx
+// via tx.StmtContext) so every call through stmtCache fires hooks the same
+// way the raw, dynamically-built queries in ListThreadsByBoard/
+// ListPostsByThread/SearchPosts/SearchThreads do. Its methods mirror
+// *sql.Stmt's, so it's a drop-in replacement at every existing call site.
+type hookedStmt struct {
+	stmt  *sql.Stmt
+	hooks []QueryHook
+	op    string
+	sql   string
+}
+
+func (h *hookedStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return hookedExec(ctx, h.hooks, h.op, h.sql, args, func(ctx context.Context) (sql.Result, error) {
+		return h.stmt.ExecContext(ctx, args...)
+	})
+}
+
+func (h *hookedStmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	return hookedQuery(ctx, h.hooks, h.op, h.sql, args, func(ctx context.Context) (*sql.Rows, error) {
+		return h.stmt.QueryContext(ctx, args...)
+	})
+}
+
+func (h *hookedStmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
+	return hookedQueryRow(ctx, h.hooks, h.op, h.sql, args, func(ctx context.Context) *sql.Row {
+		return h.stmt.QueryRowContext(ctx, args...)
+	})
+}
@@ -0,0 +1,136 @@
+package indexer
+
+import (
+    "context"
+    "testing"
+)
+
+// recordingHook records every QueryEvent it sees, tagging each with
+// "before"/"after" so a test can assert both fired, in order, for a call.
+type recordingHook struct {
+    events []string
+    seen   []QueryEvent
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, event QueryEvent) context.Context {
+    h.events = append(h.events, "before:"+event.Op)
+    h.seen = append(h.seen, event)
+    return ctx
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, event QueryEvent) {
+    h.events = append(h.events, "after:"+event.Op)
+    h.seen = append(h.seen, event)
+}
+
+func TestNewSQLiteDBWithOptions_HooksFireForExecAndQuery(t *testing.T) {
+    ctx := context.Background()
+    hook := &recordingHook{}
+    db, err := NewSQLiteDBWithOptions(":memory:", Options{Hooks: []QueryHook{hook}})
+    if err != nil {
+        t.Fatalf("NewSQLiteDBWithOptions: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if _, err := db.GetBoard(ctx, "board1"); err != nil {
+        t.Fatalf("GetBoard: %v", err)
+    }
+
+    wantOps := map[string]bool{"create_board": false, "get_board": false}
+    for _, ev := range hook.seen {
+        if _, ok := wantOps[ev.Op]; ok {
+            wantOps[ev.Op] = true
+        }
+    }
+    for op, seen := range wantOps {
+        if !seen {
+            t.Errorf("hook never saw a QueryEvent for op %q", op)
+        }
+    }
+
+    // Every fired event should have a before immediately followed by the
+    // matching after, never interleaved with a different op's before/after.
+    for i := 0; i < len(hook.events); i += 2 {
+        before := hook.events[i]
+        after := hook.events[i+1]
+        if before[:len("before:")] != "before:" || after[:len("after:")] != "after:" {
+            t.Fatalf("events out of order at %d: %v", i, hook.events)
+        }
+        if before[len("before:"):] != after[len("after:"):] {
+            t.Fatalf("before/after op mismatch at %d: %v", i, hook.events)
+        }
+    }
+}
+
+func TestNewSQLiteDBWithOptions_HookSeesQueryErr(t *testing.T) {
+    ctx := context.Background()
+    hook := &recordingHook{}
+    db, err := NewSQLiteDBWithOptions(":memory:", Options{Hooks: []QueryHook{hook}})
+    if err != nil {
+        t.Fatalf("NewSQLiteDBWithOptions: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "dup", Name: "First"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateBoard(ctx, &Board{ID: "dup", Name: "Second"}); err == nil {
+        t.Fatalf("CreateBoard: expected a duplicate-id error, got nil")
+    }
+
+    var sawErr bool
+    for _, ev := range hook.seen {
+        if ev.Op == "create_board" && ev.Err != nil {
+            sawErr = true
+        }
+    }
+    if !sawErr {
+        t.Fatalf("hook never saw a non-nil Err for the failing create_board")
+    }
+}
+
+func TestMetricsHook_RecordsDurationAndErrors(t *testing.T) {
+    ctx := context.Background()
+    metrics := NewMetricsHook()
+    db, err := NewSQLiteDBWithOptions(":memory:", Options{Hooks: []QueryHook{metrics}})
+    if err != nil {
+        t.Fatalf("NewSQLiteDBWithOptions: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err == nil {
+        t.Fatalf("CreateBoard: expected a duplicate-id error, got nil")
+    }
+
+    snap := metrics.Snapshot()
+    m, ok := snap[queryMetricsKey{Op: "create_board", Table: "boards"}]
+    if !ok {
+        t.Fatalf("no metrics recorded for create_board/boards, got %v", snap)
+    }
+    if m.DurationCount != 2 {
+        t.Fatalf("DurationCount = %d, want 2", m.DurationCount)
+    }
+    if m.ErrorsTotal != 1 {
+        t.Fatalf("ErrorsTotal = %d, want 1", m.ErrorsTotal)
+    }
+}
+
+func TestSniffTable(t *testing.T) {
+    cases := map[string]string{
+        "SELECT id FROM boards WHERE id = ?": "boards",
+        "INSERT INTO posts (id) VALUES (?)":  "posts",
+        "UPDATE threads SET post_count = ?":  "threads",
+        "SAVEPOINT sp_1":                     "",
+    }
+    for sql, want := range cases {
+        if got := sniffTable(sql); got != want {
+            t.Errorf("sniffTable(%q) = %q, want %q", sql, got, want)
+        }
+    }
+}
@@ -0,0 +1,187 @@
+package logchain
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "flex-bbs/backend-go/bbs/signature"
+    "flex-bbs/backend-go/internal/indexer"
+)
+
+func appendEntries(t *testing.T, a *Appender, priv []byte, ops []string) []indexer.BoardLogEntry {
+    t.Helper()
+    ctx := context.Background()
+    entries := make([]indexer.BoardLogEntry, 0, len(ops))
+    for i, op := range ops {
+        e := indexer.BoardLogEntry{
+            Timestamp: time.Now().UTC(),
+            Operation: op,
+            EntityID:  "entity",
+            Data:      "{}",
+        }
+        if err := a.Append(ctx, &e, priv); err != nil {
+            t.Fatalf("Append(%d): %v", i, err)
+        }
+        entries = append(entries, e)
+    }
+    return entries
+}
+
+func TestAppendAndVerify_RoundTrip(t *testing.T) {
+    db, err := indexer.NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    _, privStr, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    priv, err := signature.ParsePrivateKey(privStr)
+    if err != nil {
+        t.Fatalf("ParsePrivateKey: %v", err)
+    }
+    pub, err := signature.PublicKeyFromPrivate(priv)
+    if err != nil {
+        t.Fatalf("PublicKeyFromPrivate: %v", err)
+    }
+
+    a := NewAppender(db)
+    entries := appendEntries(t, a, priv, []string{"create_board", "create_thread", "create_post"})
+
+    if entries[0].SeqNum != 1 || entries[1].SeqNum != 2 || entries[2].SeqNum != 3 {
+        t.Fatalf("unexpected seq_nums: %d, %d, %d", entries[0].SeqNum, entries[1].SeqNum, entries[2].SeqNum)
+    }
+    if entries[0].PrevHash != "" {
+        t.Fatalf("first entry PrevHash = %q, want empty", entries[0].PrevHash)
+    }
+    if entries[1].PrevHash != ComputeHash(&entries[0]) {
+        t.Fatalf("second entry PrevHash does not chain to first entry's hash")
+    }
+
+    v := NewVerifier()
+    if err := v.Verify(context.Background(), entries, pub); err != nil {
+        t.Fatalf("Verify: %v", err)
+    }
+}
+
+func TestVerify_RejectsTamperedEntry(t *testing.T) {
+    db, err := indexer.NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    _, privStr, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    priv, err := signature.ParsePrivateKey(privStr)
+    if err != nil {
+        t.Fatalf("ParsePrivateKey: %v", err)
+    }
+    pub, err := signature.PublicKeyFromPrivate(priv)
+    if err != nil {
+        t.Fatalf("PublicKeyFromPrivate: %v", err)
+    }
+
+    a := NewAppender(db)
+    entries := appendEntries(t, a, priv, []string{"create_board", "create_thread"})
+    entries[1].Data = `{"tampered":true}`
+
+    if err := NewVerifier().Verify(context.Background(), entries, pub); err == nil {
+        t.Fatal("expected Verify to reject a tampered entry, got nil")
+    }
+}
+
+func TestVerify_RejectsForkedPrevHash(t *testing.T) {
+    db, err := indexer.NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    _, privStr, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    priv, err := signature.ParsePrivateKey(privStr)
+    if err != nil {
+        t.Fatalf("ParsePrivateKey: %v", err)
+    }
+    pub, err := signature.PublicKeyFromPrivate(priv)
+    if err != nil {
+        t.Fatalf("PublicKeyFromPrivate: %v", err)
+    }
+
+    a := NewAppender(db)
+    entries := appendEntries(t, a, priv, []string{"create_board", "create_thread"})
+    entries[1].PrevHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+    if err := NewVerifier().Verify(context.Background(), entries, pub); err == nil {
+        t.Fatal("expected Verify to reject a forked prev_hash, got nil")
+    }
+}
+
+func TestVerify_RejectsWrongPublicKey(t *testing.T) {
+    db, err := indexer.NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    _, privStr, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    priv, err := signature.ParsePrivateKey(privStr)
+    if err != nil {
+        t.Fatalf("ParsePrivateKey: %v", err)
+    }
+
+    otherPub, _, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    wrongPub, err := signature.ParsePublicKey(otherPub)
+    if err != nil {
+        t.Fatalf("ParsePublicKey: %v", err)
+    }
+
+    a := NewAppender(db)
+    entries := appendEntries(t, a, priv, []string{"create_board"})
+
+    if err := NewVerifier().Verify(context.Background(), entries, wrongPub); err == nil {
+        t.Fatal("expected Verify to reject a signature from an unregistered key, got nil")
+    }
+}
+
+func TestAppend_PersistsSequenceAndHashAcrossAppenders(t *testing.T) {
+    db, err := indexer.NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    _, privStr, err := signature.GenerateKeyPair()
+    if err != nil {
+        t.Fatalf("GenerateKeyPair: %v", err)
+    }
+    priv, err := signature.ParsePrivateKey(privStr)
+    if err != nil {
+        t.Fatalf("ParsePrivateKey: %v", err)
+    }
+
+    first := appendEntries(t, NewAppender(db), priv, []string{"create_board"})
+
+    // A second Appender wrapping the same db should continue the same chain.
+    second := appendEntries(t, NewAppender(db), priv, []string{"create_thread"})
+    if second[0].SeqNum != first[0].SeqNum+1 {
+        t.Fatalf("second appender seq_num = %d, want %d", second[0].SeqNum, first[0].SeqNum+1)
+    }
+    if second[0].PrevHash != ComputeHash(&first[0]) {
+        t.Fatal("second appender did not chain from the first appender's last entry")
+    }
+}
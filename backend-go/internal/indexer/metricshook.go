@@ -0,0 +1,100 @@
+package indexer
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// QueryMetrics are Prometheus-style counters describing every query with a
+// given (op, table) label pair, following the same plain-atomic-fields
+// convention as bbs/indexer.SyncerMetrics: no dependency on a specific
+// metrics client, so a caller registers indexer_db_query_duration_seconds
+// and indexer_db_query_errors_total with whatever registry it likes by
+// reading MetricsHook.Snapshot() on a scrape interval.
+type QueryMetrics struct {
+	DurationSecondsSum int64 // accumulated as nanoseconds; see DurationCount
+	DurationCount      int64
+	ErrorsTotal        int64
+}
+
+// queryMetricsKey is the (op, table) label pair indexer_db_query_duration_seconds
+// and indexer_db_query_errors_total are recorded under.
+type queryMetricsKey struct {
+	Op    string
+	Table string
+}
+
+// MetricsHook is a QueryHook that accumulates QueryMetrics per (op, table).
+// table is parsed out of QueryEvent.SQL since QueryEvent itself carries no
+// table field — op alone isn't enough to label a metric like
+// indexer_db_query_errors_total, which a caller will want to slice by
+// table too.
+type MetricsHook struct {
+	mu      sync.Mutex
+	metrics map[queryMetricsKey]*QueryMetrics
+}
+
+// NewMetricsHook returns an empty MetricsHook ready to use as a QueryHook.
+func NewMetricsHook() *MetricsHook {
+	return &MetricsHook{metrics: make(map[queryMetricsKey]*QueryMetrics)}
+}
+
+func (h *MetricsHook) BeforeQuery(ctx context.Context, event QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *MetricsHook) AfterQuery(ctx context.Context, event QueryEvent) {
+	key := queryMetricsKey{Op: event.Op, Table: sniffTable(event.SQL)}
+
+	h.mu.Lock()
+	m, ok := h.metrics[key]
+	if !ok {
+		m = &QueryMetrics{}
+		h.metrics[key] = m
+	}
+	h.mu.Unlock()
+
+	atomic.AddInt64(&m.DurationSecondsSum, int64(event.Duration))
+	atomic.AddInt64(&m.DurationCount, 1)
+	if event.Err != nil {
+		atomic.AddInt64(&m.ErrorsTotal, 1)
+	}
+}
+
+// Snapshot returns a point-in-time copy of every (op, table) pair's
+// QueryMetrics seen so far, for a caller to translate into its own
+// metrics registry on a scrape interval.
+func (h *MetricsHook) Snapshot() map[queryMetricsKey]QueryMetrics {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[queryMetricsKey]QueryMetrics, len(h.metrics))
+	for k, m := range h.metrics {
+		out[k] = QueryMetrics{
+			DurationSecondsSum: atomic.LoadInt64(&m.DurationSecondsSum),
+			DurationCount:      atomic.LoadInt64(&m.DurationCount),
+			ErrorsTotal:        atomic.LoadInt64(&m.ErrorsTotal),
+		}
+	}
+	return out
+}
+
+// sqlTableRE matches the table name after FROM/INTO/UPDATE, the only three
+// clauses the SQL this package emits ever names a table in (see
+// stmtQueries and the dynamic queries in db.go/postgres.go).
+var sqlTableRE = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE)\s+([a-zA-Z_][a-zA-Z0-9_]*)`)
+
+// sniffTable extracts the table a statement targets by regexing its SQL
+// text, since QueryEvent has no separate Table field (matching the exact
+// shape the bun-inspired QueryHook/QueryEvent types were specified with).
+// Returns "" if nothing matches, e.g. for the SAVEPOINT/ROLLBACK TO/RELEASE
+// statements WithTx issues.
+func sniffTable(sql string) string {
+	m := sqlTableRE.FindStringSubmatch(sql)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1])
+}
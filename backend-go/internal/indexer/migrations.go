@@ -0,0 +1,403 @@
+package indexer
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Migration is one versioned, idempotent-by-construction step in this
+// package's schema history, mirroring the pattern bbs/indexer uses for the
+// board-log index. Versions are sortable RFC3339 timestamps prefixed to the
+// migration's name, so migrations apply in order just by sorting the slice
+// they're registered in.
+type Migration interface {
+	Version() string
+	Name() string
+	// Checksum identifies the exact SQL this migration applied, so a
+	// checksum check can detect a migration whose embedded SQL was edited
+	// after release (which a database that already applied the old version
+	// would otherwise hide).
+	Checksum() string
+	Up(ctx context.Context, tx *sql.Tx) error
+}
+
+// sqlMigration is a Migration whose Up is just a sequence of plain SQL
+// statements, run one at a time in the same transaction — which covers
+// every migration this package needs so far.
+type sqlMigration struct {
+	version string
+	name    string
+	upStmts []string
+}
+
+func (m *sqlMigration) Version() string { return m.version }
+func (m *sqlMigration) Name() string    { return m.name }
+
+func (m *sqlMigration) Checksum() string {
+	sum := sha256.Sum256([]byte(strings.Join(m.upStmts, ";\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *sqlMigration) Up(ctx context.Context, tx *sql.Tx) error {
+	for _, stmt := range m.upStmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteMigrations is the ordered, append-only history of sqliteDB's
+// schema. Never edit a migration that has shipped — add a new one instead,
+// even to fix a mistake in an earlier one, so a checksum check stays
+// meaningful and already-deployed databases upgrade the same way a fresh
+// one does.
+var sqliteMigrations = []Migration{
+	&sqlMigration{
+		version: "2025-01-15T09:00:00Z_InitialSchema",
+		name:    "InitialSchema",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS boards (
+                id TEXT PRIMARY KEY,
+                name TEXT NOT NULL,
+                description TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL,
+                thread_count INTEGER NOT NULL DEFAULT 0
+            );`,
+			`CREATE TABLE IF NOT EXISTS threads (
+                id TEXT PRIMARY KEY,
+                board_id TEXT NOT NULL,
+                title TEXT NOT NULL,
+                author_id TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL,
+                post_count INTEGER NOT NULL DEFAULT 0,
+                is_closed INTEGER NOT NULL DEFAULT 0,
+                FOREIGN KEY(board_id) REFERENCES boards(id) ON DELETE CASCADE
+            );`,
+			`CREATE TABLE IF NOT EXISTS posts (
+                id TEXT PRIMARY KEY,
+                thread_id TEXT NOT NULL,
+                board_id TEXT NOT NULL,
+                author_id TEXT NOT NULL,
+                content TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL,
+                is_deleted INTEGER NOT NULL DEFAULT 0,
+                reply_to TEXT,
+                FOREIGN KEY(thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+                FOREIGN KEY(board_id) REFERENCES boards(id) ON DELETE CASCADE
+            );`,
+			`CREATE TABLE IF NOT EXISTS log_state (
+                id INTEGER PRIMARY KEY CHECK (id = 1),
+                last_seq INTEGER NOT NULL DEFAULT 0,
+                last_hash TEXT NOT NULL DEFAULT ''
+            );`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_thread_id_created_at ON posts(thread_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_board_id_created_at ON posts(board_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_author_id ON posts(author_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_board_id_created_at ON threads(board_id, created_at);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-29T09:00:00Z_KeysetPaginationIndexes",
+		name:    "KeysetPaginationIndexes",
+		upStmts: []string{
+			// ListPostsByThread/ListThreadsByBoard (and SearchPosts/
+			// SearchThreads' keyset mode) order by (created_at, id); the
+			// existing idx_posts_thread_id_created_at/
+			// idx_threads_board_id_created_at indexes don't cover the
+			// trailing id tiebreaker, so a keyset page still needs a sort
+			// step for rows sharing a created_at value without these.
+			`CREATE INDEX IF NOT EXISTS idx_posts_thread_id_created_at_id ON posts(thread_id, created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_board_id_created_at_id ON threads(board_id, created_at, id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T09:00:00Z_SearchKeysetIndexes",
+		name:    "SearchKeysetIndexes",
+		upStmts: []string{
+			// SearchPosts/SearchThreads (and ListPosts/ListThreads) keyset
+			// pages scan across every board/thread, ordered and compared on
+			// bare (created_at, id) — unlike ListPostsByThread/
+			// ListThreadsByBoard, nothing here narrows by thread_id/
+			// board_id first, so the prefixed indexes above don't cover it.
+			`CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts(created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_created_at_id ON threads(created_at, id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T10:00:00Z_ThreadReads",
+		name:    "ThreadReads",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS thread_reads (
+                user_id TEXT NOT NULL,
+                thread_id TEXT NOT NULL,
+                last_read_post_id TEXT NOT NULL DEFAULT '',
+                last_read_at TIMESTAMP NOT NULL,
+                PRIMARY KEY (user_id, thread_id),
+                FOREIGN KEY(thread_id) REFERENCES threads(id) ON DELETE CASCADE
+            );`,
+			`CREATE INDEX IF NOT EXISTS idx_thread_reads_thread_id ON thread_reads(thread_id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T11:00:00Z_ThreadSoftDelete",
+		name:    "ThreadSoftDelete",
+		upStmts: []string{
+			`ALTER TABLE threads ADD COLUMN is_deleted INTEGER NOT NULL DEFAULT 0;`,
+		},
+	},
+}
+
+// postgresMigrations is pgDB's equivalent of sqliteMigrations, translated
+// through postgresDialect's schema conventions (TIMESTAMPTZ, BOOLEAN,
+// BIGINT, the pg_trgm extension/indexes backing its ILIKE search scan).
+var postgresMigrations = []Migration{
+	&sqlMigration{
+		version: "2025-06-20T09:00:00Z_InitialSchema",
+		name:    "InitialSchema",
+		upStmts: []string{
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm;`,
+			`CREATE TABLE IF NOT EXISTS boards (
+                id TEXT PRIMARY KEY,
+                name TEXT NOT NULL,
+                description TEXT NOT NULL,
+                created_at TIMESTAMPTZ NOT NULL,
+                updated_at TIMESTAMPTZ NOT NULL,
+                thread_count BIGINT NOT NULL DEFAULT 0
+            );`,
+			`CREATE TABLE IF NOT EXISTS threads (
+                id TEXT PRIMARY KEY,
+                board_id TEXT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+                title TEXT NOT NULL,
+                author_id TEXT NOT NULL,
+                created_at TIMESTAMPTZ NOT NULL,
+                updated_at TIMESTAMPTZ NOT NULL,
+                post_count BIGINT NOT NULL DEFAULT 0,
+                is_closed BOOLEAN NOT NULL DEFAULT FALSE
+            );`,
+			`CREATE TABLE IF NOT EXISTS posts (
+                id TEXT PRIMARY KEY,
+                thread_id TEXT NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+                board_id TEXT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+                author_id TEXT NOT NULL,
+                content TEXT NOT NULL,
+                created_at TIMESTAMPTZ NOT NULL,
+                updated_at TIMESTAMPTZ NOT NULL,
+                is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+                reply_to TEXT
+            );`,
+			`CREATE TABLE IF NOT EXISTS log_state (
+                id INTEGER PRIMARY KEY CHECK (id = 1),
+                last_seq BIGINT NOT NULL DEFAULT 0,
+                last_hash TEXT NOT NULL DEFAULT ''
+            );`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_thread_id_created_at ON posts(thread_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_board_id_created_at ON posts(board_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_author_id ON posts(author_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_board_id_created_at ON threads(board_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_posts_content_trgm ON posts USING GIN (content gin_trgm_ops);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_title_trgm ON threads USING GIN (title gin_trgm_ops);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-29T09:00:00Z_KeysetPaginationIndexes",
+		name:    "KeysetPaginationIndexes",
+		upStmts: []string{
+			`CREATE INDEX IF NOT EXISTS idx_posts_thread_id_created_at_id ON posts(thread_id, created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_board_id_created_at_id ON threads(board_id, created_at, id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T09:00:00Z_SearchKeysetIndexes",
+		name:    "SearchKeysetIndexes",
+		upStmts: []string{
+			`CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts(created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS idx_threads_created_at_id ON threads(created_at, id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T10:00:00Z_ThreadReads",
+		name:    "ThreadReads",
+		upStmts: []string{
+			`CREATE TABLE IF NOT EXISTS thread_reads (
+                user_id TEXT NOT NULL,
+                thread_id TEXT NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+                last_read_post_id TEXT NOT NULL DEFAULT '',
+                last_read_at TIMESTAMPTZ NOT NULL,
+                PRIMARY KEY (user_id, thread_id)
+            );`,
+			`CREATE INDEX IF NOT EXISTS idx_thread_reads_thread_id ON thread_reads(thread_id);`,
+		},
+	},
+	&sqlMigration{
+		version: "2026-07-30T11:00:00Z_ThreadSoftDelete",
+		name:    "ThreadSoftDelete",
+		upStmts: []string{
+			`ALTER TABLE threads ADD COLUMN is_deleted BOOLEAN NOT NULL DEFAULT FALSE;`,
+		},
+	},
+}
+
+// migrationHistoryDDL creates the table migrateTo uses to track which
+// migrations have already run. applied_at is stored as an RFC3339 string
+// rather than a native timestamp column so the same statement works
+// unmodified against both sqlite3 and Postgres.
+const migrationHistoryDDL = `CREATE TABLE IF NOT EXISTS migration_history (
+    version TEXT PRIMARY KEY,
+    applied_at TEXT NOT NULL,
+    checksum TEXT NOT NULL
+);`
+
+// sqliteMigrationMu serializes migrateTo calls against sqlite databases
+// within this process. sqlite has no advisory-lock primitive the way
+// Postgres does; since every sqliteDB in a process shares nothing beyond
+// its own *sql.DB, and the SQLite driver itself already serializes
+// concurrent writers at the file level, an in-process mutex gives
+// migrateTo's "exclusive lock for the duration" the same guarantee
+// pg_advisory_lock gives pgDB, without depending on a second connection.
+var sqliteMigrationMu sync.Mutex
+
+// migrationLockKey is an arbitrary fixed key for pg_advisory_lock: every
+// pgDB migrating any database takes the same session-level lock, so two
+// processes racing to migrate the same Postgres server (even against
+// different databases) serialize rather than racing. That's broader than
+// strictly necessary, but migrations run once at startup and are cheap to
+// serialize globally.
+const migrationLockKey = 0x666c6578 // "flex" in hex, picked for readability in pg_locks
+
+// acquireMigrationLock takes the exclusive lock migrateTo holds for its
+// duration, returning a func to release it. Postgres uses a session-level
+// advisory lock; sqlite falls back to an in-process mutex (see
+// sqliteMigrationMu).
+func acquireMigrationLock(ctx context.Context, db *sql.DB, d dialect) (func(), error) {
+	if d.name == "pgx" {
+		if _, err := db.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, int64(migrationLockKey)); err != nil {
+			return nil, err
+		}
+		return func() {
+			_, _ = db.Exec(`SELECT pg_advisory_unlock($1)`, int64(migrationLockKey))
+		}, nil
+	}
+	sqliteMigrationMu.Lock()
+	return sqliteMigrationMu.Unlock, nil
+}
+
+// migrateTo brings db's schema up to date: it ensures migration_history
+// exists, takes the dialect's exclusive migration lock, then applies every
+// migration in list up to and including version (or every migration in
+// list, if version is "") that isn't already recorded in migration_history,
+// each in its own transaction.
+func migrateTo(ctx context.Context, db *sql.DB, d dialect, list []Migration, version string) error {
+	release, err := acquireMigrationLock(ctx, db, d)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer release()
+
+	if _, err := db.ExecContext(ctx, migrationHistoryDDL); err != nil {
+		return fmt.Errorf("create migration_history: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range list {
+		if version != "" && m.Version() > version {
+			break
+		}
+		if _, ok := applied[m.Version()]; ok {
+			continue
+		}
+		if err := applyMigration(ctx, db, d, m); err != nil {
+			return fmt.Errorf("migration %s (%s): %w", m.Version(), m.Name(), err)
+		}
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, checksum FROM migration_history`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]string)
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, d dialect, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	insertSQL := fmt.Sprintf(
+		`INSERT INTO migration_history(version, applied_at, checksum) VALUES(%s, %s, %s)`,
+		d.placeholder(1), d.placeholder(2), d.placeholder(3),
+	)
+	if _, err := tx.ExecContext(ctx, insertSQL, m.Version(), time.Now().UTC().Format(time.RFC3339), m.Checksum()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationChecksumMismatch describes one migration whose recorded checksum
+// no longer matches the SQL embedded in the running binary.
+type MigrationChecksumMismatch struct {
+	Version  string
+	Name     string
+	Recorded string
+	Current  string
+}
+
+// verifyMigrationChecksums re-checks every applied migration in list
+// against db's migration_history, for a developer-only repair mode: a
+// mismatch means a migration's source changed after it was already applied
+// to this database, so the schema may no longer match what the binary
+// expects.
+func verifyMigrationChecksums(ctx context.Context, db *sql.DB, list []Migration) ([]MigrationChecksumMismatch, error) {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []MigrationChecksumMismatch
+	for _, m := range list {
+		recorded, ok := applied[m.Version()]
+		if !ok {
+			continue
+		}
+		if current := m.Checksum(); current != recorded {
+			mismatches = append(mismatches, MigrationChecksumMismatch{
+				Version:  m.Version(),
+				Name:     m.Name(),
+				Recorded: recorded,
+				Current:  current,
+			})
+		}
+	}
+	return mismatches, nil
+}
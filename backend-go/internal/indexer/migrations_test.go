@@ -0,0 +1,165 @@
+package indexer
+
+import (
+    "context"
+    "database/sql"
+    "testing"
+
+    _ "github.com/mattn/go-sqlite3"
+)
+
+// chainMigrations builds a tiny 3-step migration chain used only by these
+// tests, independent of sqliteMigrations/postgresMigrations, so a change to
+// the real schema history can't change what this test is exercising.
+func chainMigrations() []Migration {
+    return []Migration{
+        &sqlMigration{
+            version: "2020-01-01T00:00:00Z_CreateWidgets",
+            name:    "CreateWidgets",
+            upStmts: []string{
+                `CREATE TABLE widgets (id TEXT PRIMARY KEY);`,
+            },
+        },
+        &sqlMigration{
+            version: "2020-01-02T00:00:00Z_AddWidgetName",
+            name:    "AddWidgetName",
+            upStmts: []string{
+                `ALTER TABLE widgets ADD COLUMN name TEXT NOT NULL DEFAULT '';`,
+            },
+        },
+        &sqlMigration{
+            version: "2020-01-03T00:00:00Z_CreateGadgets",
+            name:    "CreateGadgets",
+            upStmts: []string{
+                `CREATE TABLE gadgets (id TEXT PRIMARY KEY, widget_id TEXT NOT NULL);`,
+            },
+        },
+    }
+}
+
+func TestMigrateTo_FreshDBAppliesFullChain(t *testing.T) {
+    ctx := context.Background()
+    db, err := sql.Open("sqlite3", ":memory:")
+    if err != nil {
+        t.Fatalf("sql.Open: %v", err)
+    }
+    defer db.Close()
+
+    chain := chainMigrations()
+    if err := migrateTo(ctx, db, sqliteDialect, chain, ""); err != nil {
+        t.Fatalf("migrateTo: %v", err)
+    }
+
+    for _, tbl := range []string{"widgets", "gadgets"} {
+        if _, err := db.ExecContext(ctx, "SELECT * FROM "+tbl+" LIMIT 1"); err != nil {
+            t.Fatalf("table %s not created: %v", tbl, err)
+        }
+    }
+
+    applied, err := appliedMigrations(ctx, db)
+    if err != nil {
+        t.Fatalf("appliedMigrations: %v", err)
+    }
+    if len(applied) != len(chain) {
+        t.Fatalf("applied = %d migrations, want %d", len(applied), len(chain))
+    }
+
+    mismatches, err := verifyMigrationChecksums(ctx, db, chain)
+    if err != nil {
+        t.Fatalf("verifyMigrationChecksums: %v", err)
+    }
+    if len(mismatches) != 0 {
+        t.Fatalf("unexpected checksum mismatches: %+v", mismatches)
+    }
+}
+
+func TestMigrateTo_SeededAtIntermediateVersionAppliesOnlyRemaining(t *testing.T) {
+    ctx := context.Background()
+    db, err := sql.Open("sqlite3", ":memory:")
+    if err != nil {
+        t.Fatalf("sql.Open: %v", err)
+    }
+    defer db.Close()
+
+    chain := chainMigrations()
+
+    // Seed the database at the first migration only, as if it had been
+    // created by an older build.
+    if err := migrateTo(ctx, db, sqliteDialect, chain[:1], ""); err != nil {
+        t.Fatalf("seed migrateTo: %v", err)
+    }
+    if _, err := db.ExecContext(ctx, "SELECT * FROM gadgets LIMIT 1"); err == nil {
+        t.Fatalf("gadgets should not exist yet after seeding only the first migration")
+    }
+
+    // Migrating against the full chain should pick up where it left off.
+    if err := migrateTo(ctx, db, sqliteDialect, chain, ""); err != nil {
+        t.Fatalf("migrateTo: %v", err)
+    }
+    if _, err := db.ExecContext(ctx, "SELECT * FROM gadgets LIMIT 1"); err != nil {
+        t.Fatalf("gadgets not created by remaining migrations: %v", err)
+    }
+
+    // Re-running is a no-op: no error, and migration_history isn't touched.
+    applied, err := appliedMigrations(ctx, db)
+    if err != nil {
+        t.Fatalf("appliedMigrations: %v", err)
+    }
+    if err := migrateTo(ctx, db, sqliteDialect, chain, ""); err != nil {
+        t.Fatalf("second migrateTo: %v", err)
+    }
+    appliedAgain, err := appliedMigrations(ctx, db)
+    if err != nil {
+        t.Fatalf("appliedMigrations (again): %v", err)
+    }
+    if len(applied) != len(appliedAgain) {
+        t.Fatalf("re-running migrateTo changed applied count: %d -> %d", len(applied), len(appliedAgain))
+    }
+}
+
+func TestMigrateTo_VersionCapStopsBeforeLaterMigrations(t *testing.T) {
+    ctx := context.Background()
+    db, err := sql.Open("sqlite3", ":memory:")
+    if err != nil {
+        t.Fatalf("sql.Open: %v", err)
+    }
+    defer db.Close()
+
+    chain := chainMigrations()
+    if err := migrateTo(ctx, db, sqliteDialect, chain, "2020-01-02T00:00:00Z_AddWidgetName"); err != nil {
+        t.Fatalf("migrateTo: %v", err)
+    }
+
+    if _, err := db.ExecContext(ctx, "SELECT name FROM widgets LIMIT 1"); err != nil {
+        t.Fatalf("expected widgets.name from the capped version to exist: %v", err)
+    }
+    if _, err := db.ExecContext(ctx, "SELECT * FROM gadgets LIMIT 1"); err == nil {
+        t.Fatalf("gadgets should not exist: version cap should stop before CreateGadgets")
+    }
+}
+
+func TestNewSQLiteDB_RunsInitialSchemaMigrationAndVerifiesClean(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    sdb, ok := db.(*sqliteDB)
+    if !ok {
+        t.Fatalf("db is not *sqliteDB")
+    }
+    mismatches, err := sdb.VerifyMigrationChecksums(ctx)
+    if err != nil {
+        t.Fatalf("VerifyMigrationChecksums: %v", err)
+    }
+    if len(mismatches) != 0 {
+        t.Fatalf("unexpected checksum mismatches on a freshly migrated db: %+v", mismatches)
+    }
+
+    // Board/Thread/Post CRUD should work against the schema MigrateTo("") produced.
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "d"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+}
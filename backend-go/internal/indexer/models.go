@@ -22,6 +22,7 @@ type Thread struct {
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // 更新日時
 	PostCount int       `db:"post_count" json:"post_count"` // 投稿数
 	IsClosed  bool      `db:"is_closed" json:"is_closed"`   // クローズ済みか
+	IsDeleted bool      `db:"is_deleted" json:"is_deleted"` // 削除済みか
 }
 
 // Post は投稿を表すモデルです。
@@ -44,39 +45,208 @@ type BoardLogEntry struct {
 	Operation string    `json:"operation"`  // 操作種別（create_board, create_thread, create_post等）
 	EntityID  string    `json:"entity_id"`  // 対象エンティティID
 	Data      string    `json:"data"`       // JSONエンコードされたデータ
-	Signature string    `json:"signature"`  // 署名（将来の拡張用）
+	PrevHash  string    `json:"prev_hash"`  // 直前に受理されたエントリの正規化バイト列のSHA-256（先頭エントリは空文字）
+	Signature string    `json:"signature"`  // logchain.Appender が設定する "ed25519:<base64>" 署名
+}
+
+// SearchMode selects how SearchPostsRequest/SearchThreadsRequest's Query is
+// matched. Only FTSSearcher honors it; sqliteDB/pgDB's own plain
+// implementation always behaves as SearchModeLike regardless of what's set
+// here, since that's the only mode a bare SQL LIKE scan can do — see
+// Searcher.
+type SearchMode string
+
+const (
+	// SearchModeLike is the default: a plain SQL LIKE '%query%' scan, what
+	// sqliteDB/pgDB's own SearchPosts/SearchThreads always do. Works with
+	// no search backend configured.
+	SearchModeLike SearchMode = "like"
+	// SearchModeFTS matches Query as an fts5 quoted phrase via MATCH,
+	// ranked by bm25(); the same behavior Prefix/Snippet/Highlight already
+	// described before Mode existed. Requires FTSSearcher.
+	SearchModeFTS SearchMode = "fts"
+	// SearchModePhrase is an explicit synonym for SearchModeFTS, for a
+	// caller that wants to be unambiguous that Query is matched as a
+	// literal phrase rather than relying on the default.
+	SearchModePhrase SearchMode = "phrase"
+	// SearchModePrefix is equivalent to setting Prefix: true under
+	// SearchModeFTS; kept as its own Mode value so a caller can select it
+	// without also setting Prefix.
+	SearchModePrefix SearchMode = "prefix"
+)
+
+// OrderBy values for SearchPostsRequest/SearchThreadsRequest. Only
+// FTSSearcher honors OrderBy; sqliteDB/pgDB's own plain implementation is
+// keyset-paged on created_at and ignores it.
+const (
+	OrderByRank      = "rank"       // デフォルト。bm25() によるスコア順
+	OrderByCreatedAt = "created_at" // 作成日時順
+	OrderByUpdatedAt = "updated_at" // 更新日時順
+)
+
+// Range is a half-open [Start, End) byte offset into a PostSearchResult's
+// Content (or a ThreadSearchResult's Title), derived by FTSSearcher from a
+// dedicated highlight() call with its own internal marker bytes (see
+// parseHighlightRanges — this package's sqlite3 build can't use fts5's
+// offsets() auxiliary function at all). Unlike Highlight (which returns the
+// whole column with matches wrapped inline), Highlights lets an API caller
+// render its own emphasis without re-running the query or re-scanning the
+// text for the markers Highlight wraps matches in.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // SearchPostsRequest は投稿検索のリクエストです。
 type SearchPostsRequest struct {
-	Query    string `json:"query"`              // 検索クエリ
-	BoardID  string `json:"board_id,omitempty"` // 掲示板IDでフィルタ
-	ThreadID string `json:"thread_id,omitempty"`// スレッドIDでフィルタ
-	AuthorID string `json:"author_id,omitempty"`// 投稿者IDでフィルタ
-	Limit    int    `json:"limit,omitempty"`    // 取得件数（デフォルト20）
-	Offset   int    `json:"offset,omitempty"`   // オフセット
+	Query   string     `json:"query"` // 検索クエリ
+	Mode    SearchMode `json:"mode,omitempty"`
+	OrderBy string     `json:"order_by,omitempty"`
+	// Deprecated: BoardID/ThreadID/AuthorID は BoardIDs/ThreadIDs/AuthorIDs
+	// に置き換え中。後方互換のため当面は動作するが（値があれば対応する
+	// *IDs の末尾に足される）、新規クライアントは複数形を使うこと。
+	BoardID  string `json:"board_id,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+	AuthorID string `json:"author_id,omitempty"`
+	// BoardIDs/ThreadIDs/AuthorIDs, DateFrom/DateTo, IncludeTerms/
+	// ExcludeTerms/PhraseTerms are ParseSearchQuery's typed filter fields —
+	// what a query-language string like `author:ed25519:abc board:general
+	// "exact phrase" after:2025-01-01 before:2025-02-01 -spam` compiles to.
+	// They're plain fields on the request (not a nested struct) so a caller
+	// can also set them directly without going through ParseSearchQuery.
+	BoardIDs  []string   `json:"board_ids,omitempty"`
+	ThreadIDs []string   `json:"thread_ids,omitempty"`
+	AuthorIDs []string   `json:"author_ids,omitempty"`
+	DateFrom  *time.Time `json:"date_from,omitempty"` // created_at の下限（含む）でフィルタ
+	DateTo    *time.Time `json:"date_to,omitempty"`   // created_at の上限（含む）でフィルタ
+	// IncludeTerms/PhraseTerms must all appear in Content; ExcludeTerms must
+	// not. See PostQuery's fields of the same name for exactly how each is
+	// matched by the plain LIKE backend vs. FTSSearcher.
+	IncludeTerms []string `json:"include_terms,omitempty"`
+	ExcludeTerms []string `json:"exclude_terms,omitempty"`
+	PhraseTerms  []string `json:"phrase_terms,omitempty"`
+	Prefix       bool     `json:"prefix,omitempty"`    // true の場合 Query の末尾を前方一致として扱う（FTSSearcher のみ）
+	Snippet      bool     `json:"snippet,omitempty"`   // true の場合 snippet() で抜粋を返す（FTSSearcher のみ）
+	Highlight    bool     `json:"highlight,omitempty"` // true の場合 highlight()/Highlights でマッチ箇所を返す（FTSSearcher のみ）
+	Limit        int      `json:"limit,omitempty"`     // 取得件数（デフォルト20）
+	After        Cursor   `json:"after,omitempty"`     // このカーソルより後のページを取得（created_at DESC順）
+	Before       Cursor   `json:"before,omitempty"`    // このカーソルより前のページを取得
+	// Deprecated: Offset は After/Before によるキーセットページングに置き換え中。
+	// 後方互換のため当面は動作するが、新規クライアントは After/Before を使うこと。
+	Offset int `json:"offset,omitempty"` // オフセット
+}
+
+// PostSearchResult は投稿検索の1件分の結果です。Snippet/Highlight/Highlights/
+// Rank は FTSSearcher 経由のときのみ埋まります（sqliteDB の素朴な LIKE 検索
+// では常にゼロ値）。
+type PostSearchResult struct {
+	Post
+	Snippet    string  `json:"snippet,omitempty"`    // snippet() による抜粋（前後に "..." が付きうる）
+	Highlight  string  `json:"highlight,omitempty"`  // highlight() によるマッチ箇所強調済み本文全体
+	Highlights []Range `json:"highlights,omitempty"` // highlight() から導出したマッチ箇所（Content 内のバイトオフセット）
+	Rank       float64 `json:"rank,omitempty"`       // bm25(posts_fts) の値（小さいほど良いマッチ）
 }
 
 // SearchPostsResponse は投稿検索のレスポンスです。
 type SearchPostsResponse struct {
-	Posts      []Post `json:"posts"`       // 検索結果の投稿リスト
-	TotalCount int    `json:"total_count"` // 総件数
-	Limit      int    `json:"limit"`       // 取得件数
-	Offset     int    `json:"offset"`      // オフセット
+	Posts      []PostSearchResult `json:"posts"`                 // 検索結果の投稿リスト
+	TotalCount int                `json:"total_count"`           // 総件数
+	Limit      int                `json:"limit"`                 // 取得件数
+	NextCursor Cursor             `json:"next_cursor,omitempty"` // 次ページ用カーソル（最終ページでは空）
+	PrevCursor Cursor             `json:"prev_cursor,omitempty"` // 前ページ用カーソル（先頭ページでは空）
+	// Deprecated: NextCursor/PrevCursor に置き換え中。Offset ベースの
+	// リクエストには引き続き対応するため、互換目的でのみ返す。
+	Offset int `json:"offset"` // オフセット
+}
+
+// PostWithContext is a search result row with its Thread and Board attached,
+// so a caller rendering a results page doesn't fan out one query per row to
+// resolve them. There is no local Author entity to attach the same way —
+// AuthorID is an opaque ID from the external log this package indexes, not
+// a row in a table this package owns, so it's left for the caller to
+// resolve against whatever system tracks users.
+type PostWithContext struct {
+	Post
+	Thread *Thread `json:"thread,omitempty"`
+	Board  *Board  `json:"board,omitempty"`
+}
+
+// SearchPostsWithContextResponse is SearchPostsResponse's analogue for
+// SearchPostsWithContext.
+type SearchPostsWithContextResponse struct {
+	Posts      []PostWithContext `json:"posts"`
+	TotalCount int               `json:"total_count"`
+	Limit      int               `json:"limit"`
+	NextCursor Cursor            `json:"next_cursor,omitempty"`
+	PrevCursor Cursor            `json:"prev_cursor,omitempty"`
 }
 
 // SearchThreadsRequest はスレッド検索のリクエストです。
 type SearchThreadsRequest struct {
-	Query   string `json:"query"`              // 検索クエリ
-	BoardID string `json:"board_id,omitempty"` // 掲示板IDでフィルタ
-	Limit   int    `json:"limit,omitempty"`    // 取得件数（デフォルト20）
-	Offset  int    `json:"offset,omitempty"`   // オフセット
+	Query   string     `json:"query"`
+	Mode    SearchMode `json:"mode,omitempty"`
+	OrderBy string     `json:"order_by,omitempty"`
+	// Deprecated: BoardID/AuthorID は BoardIDs/AuthorIDs に置き換え中。See
+	// SearchPostsRequest's BoardID/ThreadID/AuthorID for the same note.
+	BoardID  string `json:"board_id,omitempty"`
+	AuthorID string `json:"author_id,omitempty"`
+	// BoardIDs/AuthorIDs, DateFrom/DateTo, IncludeTerms/ExcludeTerms/
+	// PhraseTerms: see SearchPostsRequest's fields of the same name —
+	// ParseSearchQuery populates both requests identically, minus
+	// ThreadIDs, which has no thread-search analogue.
+	BoardIDs     []string   `json:"board_ids,omitempty"`
+	AuthorIDs    []string   `json:"author_ids,omitempty"`
+	DateFrom     *time.Time `json:"date_from,omitempty"` // created_at の下限（含む）でフィルタ
+	DateTo       *time.Time `json:"date_to,omitempty"`   // created_at の上限（含む）でフィルタ
+	IncludeTerms []string   `json:"include_terms,omitempty"`
+	ExcludeTerms []string   `json:"exclude_terms,omitempty"`
+	PhraseTerms  []string   `json:"phrase_terms,omitempty"`
+	Prefix       bool       `json:"prefix,omitempty"`    // true の場合 Query の末尾を前方一致として扱う（FTSSearcher のみ）
+	Snippet      bool       `json:"snippet,omitempty"`   // true の場合 snippet() で抜粋を返す（FTSSearcher のみ）
+	Highlight    bool       `json:"highlight,omitempty"` // true の場合 highlight()/Highlights でマッチ箇所を返す（FTSSearcher のみ）
+	Limit        int        `json:"limit,omitempty"`     // 取得件数（デフォルト20）
+	After        Cursor     `json:"after,omitempty"`     // このカーソルより後のページを取得（created_at DESC順）
+	Before       Cursor     `json:"before,omitempty"`    // このカーソルより前のページを取得
+	// Deprecated: Offset は After/Before によるキーセットページングに置き換え中。
+	// 後方互換のため当面は動作するが、新規クライアントは After/Before を使うこと。
+	Offset int `json:"offset,omitempty"` // オフセット
+	// ViewerID, if set, fills in each result's Unread/UnreadCount from
+	// that user's thread_reads row (see MarkRead/MarkAllRead). Left empty,
+	// Unread/UnreadCount stay at their zero values.
+	ViewerID string `json:"viewer_id,omitempty"`
+}
+
+// ThreadSearchResult はスレッド検索の1件分の結果です。Snippet/Highlight/
+// Highlights/Rank の扱いは PostSearchResult と同様(ただし title 列に対して行う)。
+// Unread/UnreadCount は SearchThreadsRequest.ViewerID が設定されている場合
+// のみ埋まります。
+type ThreadSearchResult struct {
+	Thread
+	Snippet     string  `json:"snippet,omitempty"`
+	Highlight   string  `json:"highlight,omitempty"`
+	Highlights  []Range `json:"highlights,omitempty"`
+	Rank        float64 `json:"rank,omitempty"`
+	Unread      bool    `json:"unread,omitempty"`
+	UnreadCount int     `json:"unread_count,omitempty"`
 }
 
 // SearchThreadsResponse はスレッド検索のレスポンスです。
 type SearchThreadsResponse struct {
-	Threads    []Thread `json:"threads"`     // 検索結果のスレッドリスト
-	TotalCount int      `json:"total_count"` // 総件数
-	Limit      int      `json:"limit"`       // 取得件数
-	Offset     int      `json:"offset"`      // オフセット
+	Threads    []ThreadSearchResult `json:"threads"`               // 検索結果のスレッドリスト
+	TotalCount int                  `json:"total_count"`           // 総件数
+	Limit      int                  `json:"limit"`                 // 取得件数
+	NextCursor Cursor               `json:"next_cursor,omitempty"` // 次ページ用カーソル（最終ページでは空）
+	PrevCursor Cursor               `json:"prev_cursor,omitempty"` // 前ページ用カーソル（先頭ページでは空）
+	// Deprecated: NextCursor/PrevCursor に置き換え中。Offset ベースの
+	// リクエストには引き続き対応するため、互換目的でのみ返す。
+	Offset int `json:"offset"` // オフセット
+}
+
+// RecalcReport summarizes one Recalculate run: how many threads were
+// scanned and how many of them had a post_count that didn't match
+// SELECT COUNT(*) FROM posts WHERE thread_id = ? AND is_deleted = 0, and so
+// were rewritten.
+type RecalcReport struct {
+	ThreadsScanned int `json:"threads_scanned"`
+	ThreadsFixed   int `json:"threads_fixed"`
 }
@@ -0,0 +1,218 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PostQuery is the unified filter set CountPosts/ListPosts (and, through
+// them, SearchPosts) build against — replacing the four near-identical
+// WHERE-building blocks sqliteDB/sqliteTx/pgDB/pgTx's SearchPosts used to
+// maintain independently. See buildWhere.
+type PostQuery struct {
+	// Query, if non-empty, matches posts whose Content contains it
+	// (a LIKE/ILIKE scan, same as SearchPostsRequest.Query has always done).
+	Query string
+	// BoardIDs/ThreadIDs/AuthorIDs, given non-empty, restrict to posts
+	// whose column is one of the listed values. A single-element slice
+	// behaves like SearchPostsRequest's old BoardID/ThreadID/AuthorID
+	// equality filter.
+	BoardIDs  []string
+	ThreadIDs []string
+	AuthorIDs []string
+	// IncludeDeleted includes soft-deleted posts. SearchPosts has always
+	// excluded them, so the zero value (false) preserves that behavior.
+	IncludeDeleted bool
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	// ReplyTo, if non-empty, restricts to direct replies of the given post
+	// ID.
+	ReplyTo string
+	// HasReplies, if non-nil, restricts to posts that do (true) or don't
+	// (false) have at least one reply.
+	HasReplies *bool
+	// IncludeTerms/PhraseTerms/ExcludeTerms are ParseSearchQuery's typed
+	// term buckets (see SearchPostsRequest): every entry of IncludeTerms
+	// and PhraseTerms must appear in Content (each its own LIKE clause, so
+	// e.g. two IncludeTerms behave as an AND, not an OR), and no entry of
+	// ExcludeTerms may. Under this plain LIKE backend, IncludeTerms and
+	// PhraseTerms are equivalent (both require containment, ignoring word
+	// boundaries); a FTS-backed Searcher (see FTSSearcher) tells them
+	// apart, treating PhraseTerms as literal quoted phrases.
+	IncludeTerms []string
+	ExcludeTerms []string
+	PhraseTerms  []string
+	// SortBy is OrderByCreatedAt or OrderByUpdatedAt; "" defaults to
+	// OrderByCreatedAt, matching ListPosts/SearchPosts' historical order.
+	// It only chooses the ORDER BY column for an offset-paginated page —
+	// once After/Before is set, ListPosts keeps ordering by (created_at,
+	// id), the pair Cursor is always encoded against (see buildKeysetClause
+	// and Cursor), the same compromise FTSSearcher's OrderBy makes against
+	// the plain DB's own keyset pagination.
+	SortBy  string
+	SortDir Order
+	Limit   int
+	Offset  int
+	After   Cursor
+	Before  Cursor
+}
+
+// ThreadQuery is ListThreads/CountThreads' unified filter set, ThreadQuery's
+// analogue of PostQuery. Threads have no reply relationship, so it carries
+// none of PostQuery's ReplyTo/HasReplies.
+type ThreadQuery struct {
+	// Query, if non-empty, matches threads whose Title contains it.
+	Query     string
+	BoardIDs  []string
+	AuthorIDs []string
+	// IncludeDeleted includes soft-deleted threads. ListThreads/SearchThreads
+	// have always excluded them, so the zero value (false) preserves that
+	// behavior; see PostQuery.IncludeDeleted.
+	IncludeDeleted bool
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	// IncludeTerms/PhraseTerms/ExcludeTerms: see PostQuery's fields of the
+	// same name, matched against Title instead of Content.
+	IncludeTerms []string
+	ExcludeTerms []string
+	PhraseTerms  []string
+	// SortBy/SortDir: see PostQuery.SortBy's doc comment — the same
+	// offset-only caveat applies here.
+	SortBy  string
+	SortDir Order
+	Limit   int
+	Offset  int
+	After   Cursor
+	Before  Cursor
+}
+
+// buildWhere turns q into a list of ANDed conditions plus their bind args
+// under dialect d — the same ([]string, []any) shape appendSearchKeyset
+// already extends, so ListPosts/SearchPosts can hand its result straight to
+// appendSearchKeyset without reshaping it. The returned where is never
+// empty: posts are always filtered on is_deleted, even when every other
+// field is its zero value.
+func (q PostQuery) buildWhere(d dialect) (where []string, args []any) {
+	if q.IncludeDeleted {
+		where = []string{"1=1"}
+	} else {
+		where = []string{"is_deleted = " + d.boolLiteral(false)}
+	}
+	args = []any{}
+
+	if q.Query != "" {
+		args = append(args, "%"+q.Query+"%")
+		where = append(where, fmt.Sprintf("content %s %s", d.likeOperator(), d.placeholder(len(args))))
+	}
+	where, args = appendContainsClauses(d, where, args, "content", q.IncludeTerms)
+	where, args = appendContainsClauses(d, where, args, "content", q.PhraseTerms)
+	where, args = appendExcludesClauses(d, where, args, "content", q.ExcludeTerms)
+	where, args = appendInClause(d, where, args, "board_id", q.BoardIDs)
+	where, args = appendInClause(d, where, args, "thread_id", q.ThreadIDs)
+	where, args = appendInClause(d, where, args, "author_id", q.AuthorIDs)
+	if q.CreatedAfter != nil {
+		args = append(args, *q.CreatedAfter)
+		where = append(where, fmt.Sprintf("created_at >= %s", d.placeholder(len(args))))
+	}
+	if q.CreatedBefore != nil {
+		args = append(args, *q.CreatedBefore)
+		where = append(where, fmt.Sprintf("created_at <= %s", d.placeholder(len(args))))
+	}
+	if q.ReplyTo != "" {
+		args = append(args, q.ReplyTo)
+		where = append(where, fmt.Sprintf("reply_to = %s", d.placeholder(len(args))))
+	}
+	if q.HasReplies != nil {
+		clause := "EXISTS (SELECT 1 FROM posts r WHERE r.reply_to = posts.id)"
+		if !*q.HasReplies {
+			clause = "NOT " + clause
+		}
+		where = append(where, clause)
+	}
+	return where, args
+}
+
+// buildWhere is ThreadQuery's analogue of PostQuery.buildWhere.
+func (q ThreadQuery) buildWhere(d dialect) (where []string, args []any) {
+	if q.IncludeDeleted {
+		where = []string{"1=1"}
+	} else {
+		where = []string{"is_deleted = " + d.boolLiteral(false)}
+	}
+	args = []any{}
+
+	if q.Query != "" {
+		args = append(args, "%"+q.Query+"%")
+		where = append(where, fmt.Sprintf("title %s %s", d.likeOperator(), d.placeholder(len(args))))
+	}
+	where, args = appendContainsClauses(d, where, args, "title", q.IncludeTerms)
+	where, args = appendContainsClauses(d, where, args, "title", q.PhraseTerms)
+	where, args = appendExcludesClauses(d, where, args, "title", q.ExcludeTerms)
+	where, args = appendInClause(d, where, args, "board_id", q.BoardIDs)
+	where, args = appendInClause(d, where, args, "author_id", q.AuthorIDs)
+	if q.CreatedAfter != nil {
+		args = append(args, *q.CreatedAfter)
+		where = append(where, fmt.Sprintf("created_at >= %s", d.placeholder(len(args))))
+	}
+	if q.CreatedBefore != nil {
+		args = append(args, *q.CreatedBefore)
+		where = append(where, fmt.Sprintf("created_at <= %s", d.placeholder(len(args))))
+	}
+	return where, args
+}
+
+// appendInClause appends a "col IN (...)" condition (and its args) to
+// where/args when vals is non-empty, returning both unchanged otherwise.
+func appendInClause(d dialect, where []string, args []any, col string, vals []string) ([]string, []any) {
+	if len(vals) == 0 {
+		return where, args
+	}
+	placeholders := make([]string, len(vals))
+	for i, v := range vals {
+		args = append(args, v)
+		placeholders[i] = d.placeholder(len(args))
+	}
+	where = append(where, fmt.Sprintf("%s IN (%s)", col, strings.Join(placeholders, ", ")))
+	return where, args
+}
+
+// appendContainsClauses ANDs one "col LIKE ?" condition per entry of terms
+// onto where/args, so e.g. two terms require col to contain both (not
+// either) — the same all-must-match behavior IncludeTerms/PhraseTerms
+// document on PostQuery/ThreadQuery.
+func appendContainsClauses(d dialect, where []string, args []any, col string, terms []string) ([]string, []any) {
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		args = append(args, "%"+term+"%")
+		where = append(where, fmt.Sprintf("%s %s %s", col, d.likeOperator(), d.placeholder(len(args))))
+	}
+	return where, args
+}
+
+// appendExcludesClauses ANDs one "col NOT LIKE ?" condition per entry of
+// terms onto where/args, so a row containing any one of them is excluded.
+func appendExcludesClauses(d dialect, where []string, args []any, col string, terms []string) ([]string, []any) {
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		args = append(args, "%"+term+"%")
+		where = append(where, fmt.Sprintf("%s NOT %s %s", col, d.likeOperator(), d.placeholder(len(args))))
+	}
+	return where, args
+}
+
+// orderColumn maps SortBy to the column ListPosts/ListThreads order an
+// offset-paginated page by, defaulting to created_at for "" or any value
+// other than OrderByUpdatedAt — the same ignore-what-we-don't-recognize
+// convention sortPostSearchResults/sortThreadSearchResults use for
+// FTSSearcher's OrderBy.
+func orderColumn(sortBy string) string {
+	if sortBy == OrderByUpdatedAt {
+		return "updated_at"
+	}
+	return "created_at"
+}
@@ -0,0 +1,198 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// queryDateLayout is the date-only format ParseSearchQuery accepts for
+// after:/before: filters (e.g. "after:2025-01-01"), distinct from
+// parseSearchDateParam's RFC3339, since a user typing a search query isn't
+// expected to supply a time component.
+const queryDateLayout = "2006-01-02"
+
+// ParsedSearchQuery is ParseSearchQuery's output: the typed filter buckets a
+// query-language string compiles to. ApplyToSearchPostsRequest/
+// ApplyToSearchThreadsRequest copy it onto the request types handleSearchPosts/
+// handleSearchThreads and the SQL backends already know how to execute (see
+// PostQuery/ThreadQuery's fields of the same name).
+type ParsedSearchQuery struct {
+	AuthorIDs    []string
+	BoardIDs     []string
+	ThreadIDs    []string
+	After        *time.Time
+	Before       *time.Time
+	IncludeTerms []string
+	ExcludeTerms []string
+	PhraseTerms  []string
+}
+
+// ParseSearchQuery compiles a query-language string — e.g.
+// `author:ed25519:abc board:general "exact phrase" after:2025-01-01
+// before:2025-02-01 -spam` — into a ParsedSearchQuery. Recognized field
+// prefixes are author:, board:, thread:, after:, and before:; a token
+// prefixed with "-" is an excluded bareword; a "-"-prefixed quoted string
+// excludes a phrase; anything else is a bareword include term, and a
+// "..."-quoted run of tokens is a phrase term. Field prefixes are matched
+// case-insensitively; their values are not.
+func ParseSearchQuery(raw string) (ParsedSearchQuery, error) {
+	var q ParsedSearchQuery
+	for _, tok := range tokenizeSearchQuery(raw) {
+		term, negate, quoted := tok.term, tok.negate, tok.quoted
+		if !quoted {
+			if field, value, ok := splitQueryField(term); ok {
+				switch strings.ToLower(field) {
+				case "author":
+					q.AuthorIDs = append(q.AuthorIDs, value)
+					continue
+				case "board":
+					q.BoardIDs = append(q.BoardIDs, value)
+					continue
+				case "thread":
+					q.ThreadIDs = append(q.ThreadIDs, value)
+					continue
+				case "after":
+					t, err := time.Parse(queryDateLayout, value)
+					if err != nil {
+						return ParsedSearchQuery{}, fmt.Errorf("parse search query: invalid after: date %q: %w", value, err)
+					}
+					q.After = &t
+					continue
+				case "before":
+					t, err := time.Parse(queryDateLayout, value)
+					if err != nil {
+						return ParsedSearchQuery{}, fmt.Errorf("parse search query: invalid before: date %q: %w", value, err)
+					}
+					// before: is inclusive of the named day, so the filter
+					// boundary is the instant just past its end.
+					t = t.Add(24*time.Hour - time.Nanosecond)
+					q.Before = &t
+					continue
+				}
+			}
+		}
+		if term == "" {
+			continue
+		}
+		switch {
+		case negate:
+			q.ExcludeTerms = append(q.ExcludeTerms, term)
+		case quoted:
+			q.PhraseTerms = append(q.PhraseTerms, term)
+		default:
+			q.IncludeTerms = append(q.IncludeTerms, term)
+		}
+	}
+	return q, nil
+}
+
+// ApplyToSearchPostsRequest copies q onto req, appending to (rather than
+// overwriting) any filter req already set directly — so a caller combining
+// ParseSearchQuery with its own programmatic filters gets the union of both.
+func (q ParsedSearchQuery) ApplyToSearchPostsRequest(req *SearchPostsRequest) {
+	req.AuthorIDs = append(req.AuthorIDs, q.AuthorIDs...)
+	req.BoardIDs = append(req.BoardIDs, q.BoardIDs...)
+	req.ThreadIDs = append(req.ThreadIDs, q.ThreadIDs...)
+	req.IncludeTerms = append(req.IncludeTerms, q.IncludeTerms...)
+	req.ExcludeTerms = append(req.ExcludeTerms, q.ExcludeTerms...)
+	req.PhraseTerms = append(req.PhraseTerms, q.PhraseTerms...)
+	if q.After != nil {
+		req.DateFrom = q.After
+	}
+	if q.Before != nil {
+		req.DateTo = q.Before
+	}
+}
+
+// ApplyToSearchThreadsRequest is ApplyToSearchPostsRequest's analogue for
+// SearchThreadsRequest; q.ThreadIDs is dropped, since thread search has no
+// thread_id column to filter on.
+func (q ParsedSearchQuery) ApplyToSearchThreadsRequest(req *SearchThreadsRequest) {
+	req.AuthorIDs = append(req.AuthorIDs, q.AuthorIDs...)
+	req.BoardIDs = append(req.BoardIDs, q.BoardIDs...)
+	req.IncludeTerms = append(req.IncludeTerms, q.IncludeTerms...)
+	req.ExcludeTerms = append(req.ExcludeTerms, q.ExcludeTerms...)
+	req.PhraseTerms = append(req.PhraseTerms, q.PhraseTerms...)
+	if q.After != nil {
+		req.DateFrom = q.After
+	}
+	if q.Before != nil {
+		req.DateTo = q.Before
+	}
+}
+
+// searchQueryToken is one token tokenizeSearchQuery splits raw into: term is
+// its text (the field prefix, if any, still attached — splitQueryField pulls
+// it back off), negate reports a leading "-", and quoted reports whether it
+// came from a "..."-delimited run rather than a bareword.
+type searchQueryToken struct {
+	term   string
+	negate bool
+	quoted bool
+}
+
+// tokenizeSearchQuery splits raw on whitespace, except inside a
+// "..."-delimited span, which becomes one token with its quotes stripped. A
+// token (quoted or not) may carry a leading "-" to negate it; an unterminated
+// trailing quote is treated as running to the end of the string rather than
+// an error, since a search box shouldn't reject a query over one missing
+// quote.
+func tokenizeSearchQuery(raw string) []searchQueryToken {
+	var tokens []searchQueryToken
+	runes := []rune(raw)
+	i := 0
+	for i < len(runes) {
+		for i < len(runes) && isSearchQuerySpace(runes[i]) {
+			i++
+		}
+		if i >= len(runes) {
+			break
+		}
+		negate := false
+		if runes[i] == '-' && i+1 < len(runes) {
+			negate = true
+			i++
+		}
+		if runes[i] == '"' {
+			i++
+			start := i
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, searchQueryToken{term: string(runes[start:i]), negate: negate, quoted: true})
+			if i < len(runes) {
+				i++ // skip closing quote
+			}
+			continue
+		}
+		start := i
+		for i < len(runes) && !isSearchQuerySpace(runes[i]) {
+			i++
+		}
+		tokens = append(tokens, searchQueryToken{term: string(runes[start:i]), negate: negate})
+	}
+	return tokens
+}
+
+func isSearchQuerySpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// splitQueryField splits a bareword token like "author:ed25519:abc" into
+// ("author", "ed25519:abc"); ok is false if term has no ":" or the part
+// before it isn't a recognized field name, in which case term should be
+// treated as a plain include/exclude term instead.
+func splitQueryField(term string) (field, value string, ok bool) {
+	idx := strings.IndexByte(term, ':')
+	if idx <= 0 || idx == len(term)-1 {
+		return "", "", false
+	}
+	f := term[:idx]
+	switch strings.ToLower(f) {
+	case "author", "board", "thread", "after", "before":
+		return f, term[idx+1:], true
+	default:
+		return "", "", false
+	}
+}
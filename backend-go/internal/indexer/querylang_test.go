@@ -0,0 +1,85 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSearchQuery_FieldsPhrasesAndNegation(t *testing.T) {
+	got, err := ParseSearchQuery(`author:ed25519:abc board:general "exact phrase" after:2025-01-01 before:2025-02-01 -spam -"bad phrase" hello`)
+	if err != nil {
+		t.Fatalf("ParseSearchQuery: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.AuthorIDs, []string{"ed25519:abc"}) {
+		t.Fatalf("AuthorIDs = %v", got.AuthorIDs)
+	}
+	if !reflect.DeepEqual(got.BoardIDs, []string{"general"}) {
+		t.Fatalf("BoardIDs = %v", got.BoardIDs)
+	}
+	if !reflect.DeepEqual(got.PhraseTerms, []string{"exact phrase"}) {
+		t.Fatalf("PhraseTerms = %v", got.PhraseTerms)
+	}
+	if !reflect.DeepEqual(got.IncludeTerms, []string{"hello"}) {
+		t.Fatalf("IncludeTerms = %v", got.IncludeTerms)
+	}
+	if !reflect.DeepEqual(got.ExcludeTerms, []string{"spam", "bad phrase"}) {
+		t.Fatalf("ExcludeTerms = %v", got.ExcludeTerms)
+	}
+	if got.After == nil || !got.After.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("After = %v", got.After)
+	}
+	wantBefore := time.Date(2025, 2, 1, 23, 59, 59, int(time.Second-time.Nanosecond), time.UTC)
+	if got.Before == nil || !got.Before.Equal(wantBefore) {
+		t.Fatalf("Before = %v, want %v", got.Before, wantBefore)
+	}
+}
+
+func TestParseSearchQuery_InvalidDateIsError(t *testing.T) {
+	if _, err := ParseSearchQuery("after:not-a-date"); err == nil {
+		t.Fatalf("ParseSearchQuery: expected error for invalid after: date")
+	}
+}
+
+func TestParseSearchQuery_ThreadFieldAndCaseInsensitivePrefix(t *testing.T) {
+	got, err := ParseSearchQuery("THREAD:t1 Board:b1")
+	if err != nil {
+		t.Fatalf("ParseSearchQuery: %v", err)
+	}
+	if !reflect.DeepEqual(got.ThreadIDs, []string{"t1"}) {
+		t.Fatalf("ThreadIDs = %v", got.ThreadIDs)
+	}
+	if !reflect.DeepEqual(got.BoardIDs, []string{"b1"}) {
+		t.Fatalf("BoardIDs = %v", got.BoardIDs)
+	}
+}
+
+func TestParsedSearchQuery_ApplyToSearchPostsRequest_AppendsRatherThanOverwrites(t *testing.T) {
+	parsed, err := ParseSearchQuery("board:general hello")
+	if err != nil {
+		t.Fatalf("ParseSearchQuery: %v", err)
+	}
+	req := &SearchPostsRequest{BoardIDs: []string{"preexisting"}}
+	parsed.ApplyToSearchPostsRequest(req)
+
+	if !reflect.DeepEqual(req.BoardIDs, []string{"preexisting", "general"}) {
+		t.Fatalf("BoardIDs = %v", req.BoardIDs)
+	}
+	if !reflect.DeepEqual(req.IncludeTerms, []string{"hello"}) {
+		t.Fatalf("IncludeTerms = %v", req.IncludeTerms)
+	}
+}
+
+func TestParsedSearchQuery_ApplyToSearchThreadsRequest_DropsThreadIDs(t *testing.T) {
+	parsed, err := ParseSearchQuery("thread:t1 board:b1")
+	if err != nil {
+		t.Fatalf("ParseSearchQuery: %v", err)
+	}
+	req := &SearchThreadsRequest{}
+	parsed.ApplyToSearchThreadsRequest(req)
+
+	if !reflect.DeepEqual(req.BoardIDs, []string{"b1"}) {
+		t.Fatalf("BoardIDs = %v", req.BoardIDs)
+	}
+}
@@ -30,26 +30,8 @@ func (r *LogReplayer) ReplayEntry(ctx context.Context, entry *BoardLogEntry) err
 			return nil
 		}
 
-		var opErr error
-		switch entry.Operation {
-		case "create_board":
-			opErr = r.replayCreateBoard(ctx, tx, entry)
-		case "update_board":
-			opErr = r.replayUpdateBoard(ctx, tx, entry)
-		case "create_thread":
-			opErr = r.replayCreateThread(ctx, tx, entry)
-		case "close_thread":
-			opErr = r.replayCloseThread(ctx, tx, entry)
-		case "create_post":
-			opErr = r.replayCreatePost(ctx, tx, entry)
-		case "delete_post":
-			opErr = r.replayDeletePost(ctx, tx, entry)
-		default:
-			log.Printf("LogReplayer: unknown operation %q seq=%d", entry.Operation, entry.SeqNum)
-			// 未知の操作は無視してシーケンスだけ進める
-		}
-		if opErr != nil {
-			return fmt.Errorf("replay %s: %w", entry.Operation, opErr)
+		if err := r.applyEntry(ctx, tx, entry); err != nil {
+			return err
 		}
 
 		if err := tx.SetLastSequence(ctx, entry.SeqNum); err != nil {
@@ -59,6 +41,36 @@ func (r *LogReplayer) ReplayEntry(ctx context.Context, entry *BoardLogEntry) err
 	})
 }
 
+// applyEntry dispatches entry to its replay* handler, wrapping any error
+// with the operation name the way ReplayEntry always has. It does not touch
+// last_sequence itself -- ReplayEntry and ReplayEntriesBatch each advance it
+// on their own schedule (once per entry vs. once per batch), so the
+// dispatch logic only needs to exist in one place.
+func (r *LogReplayer) applyEntry(ctx context.Context, tx DB, entry *BoardLogEntry) error {
+	var opErr error
+	switch entry.Operation {
+	case "create_board":
+		opErr = r.replayCreateBoard(ctx, tx, entry)
+	case "update_board":
+		opErr = r.replayUpdateBoard(ctx, tx, entry)
+	case "create_thread":
+		opErr = r.replayCreateThread(ctx, tx, entry)
+	case "close_thread":
+		opErr = r.replayCloseThread(ctx, tx, entry)
+	case "create_post":
+		opErr = r.replayCreatePost(ctx, tx, entry)
+	case "delete_post":
+		opErr = r.replayDeletePost(ctx, tx, entry)
+	default:
+		log.Printf("LogReplayer: unknown operation %q seq=%d", entry.Operation, entry.SeqNum)
+		// 未知の操作は無視してシーケンスだけ進める
+	}
+	if opErr != nil {
+		return fmt.Errorf("replay %s: %w", entry.Operation, opErr)
+	}
+	return nil
+}
+
 // ReplayEntries は複数のBoardLogEntryを一括処理します。
 func (r *LogReplayer) ReplayEntries(ctx context.Context, entries []BoardLogEntry) error {
 	for i := range entries {
@@ -0,0 +1,310 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// defaultReplayBatchSize is ReplayBatchOptions.BatchSize's default: the
+// number of consecutive entries ReplayEntriesBatch groups into a single
+// WithTx (and a single SetLastSequence call) when the caller doesn't pick
+// one, chosen to keep a batch's transaction short enough not to stall
+// concurrent readers while still amortizing commit overhead across many
+// entries.
+const defaultReplayBatchSize = 100
+
+// ReplayBatchOptions configures ReplayEntriesBatch.
+type ReplayBatchOptions struct {
+	// BatchSize is the max number of entries grouped into one WithTx;
+	// <= 0 uses defaultReplayBatchSize.
+	BatchSize int
+	// Concurrency is the max number of worker goroutines used to apply a
+	// batch's independent entries (see groupReplayChains); <= 1 replays
+	// the batch serially, same order as ReplayEntries would.
+	Concurrency int
+}
+
+// ReplayEntriesBatch is ReplayEntries for large catch-up windows: instead
+// of one WithTx (and one SetLastSequence) per entry, it groups entries into
+// batches of opts.BatchSize and commits each batch in a single WithTx, and
+// when opts.Concurrency > 1 it additionally applies a batch's independent
+// entries (those with no shared or transitive dependency key -- see
+// groupReplayChains) from worker goroutines instead of one at a time.
+//
+// Concurrency only parallelizes work that doesn't touch the DB: decoding
+// entry.Data and resolving each entry's dependency key/chain happen off
+// the lock, but the actual replay* calls against tx are still serialized
+// under a mutex, since DB's sqliteTx/pgTx aren't specified to tolerate
+// concurrent use by multiple goroutines. The benefit of Concurrency is
+// therefore bounded by how much of a batch's replay time is decode/lookup
+// work versus the DB round-trip itself, not true parallel writes.
+func (r *LogReplayer) ReplayEntriesBatch(ctx context.Context, entries []BoardLogEntry, opts ReplayBatchOptions) error {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReplayBatchSize
+	}
+
+	for len(entries) > 0 {
+		n := batchSize
+		if n > len(entries) {
+			n = len(entries)
+		}
+		if err := r.replayBatch(ctx, entries[:n], opts.Concurrency); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+func (r *LogReplayer) replayBatch(ctx context.Context, batch []BoardLogEntry, concurrency int) error {
+	return r.db.WithTx(ctx, func(tx DB) error {
+		lastSeq, err := tx.GetLastSequence(ctx)
+		if err != nil {
+			return fmt.Errorf("get last seq: %w", err)
+		}
+		if concurrency > 1 {
+			return r.replayBatchConcurrent(ctx, tx, batch, lastSeq, concurrency)
+		}
+		return r.replayBatchSerial(ctx, tx, batch, lastSeq)
+	})
+}
+
+// replayBatchSerial is the concurrency<=1 path: apply batch in order,
+// skipping already-applied entries exactly as ReplayEntry does, then
+// advance last_sequence once to the highest seq actually applied.
+func (r *LogReplayer) replayBatchSerial(ctx context.Context, tx DB, batch []BoardLogEntry, lastSeq int64) error {
+	applied := lastSeq
+	for i := range batch {
+		entry := &batch[i]
+		if entry.SeqNum <= lastSeq {
+			continue
+		}
+		if err := r.applyEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+		applied = entry.SeqNum
+	}
+	if applied > lastSeq {
+		return tx.SetLastSequence(ctx, applied)
+	}
+	return nil
+}
+
+// replayBatchConcurrent schedules batch's entries onto up to concurrency
+// worker goroutines by independent dependency chain (see
+// groupReplayChains), then advances last_sequence to the min of every
+// worker's own high-water mark -- the highest seq that worker personally
+// applied -- rather than the batch's true last entry. Once every worker
+// has joined (as it has by the time this function reads the marks), every
+// worker that was actually assigned a chain has finished applying it, so
+// in the all-succeeded case this is conservative rather than exact: a
+// worker assigned only an early, short chain leaves its mark short of the
+// batch's tail even though nothing is left outstanding. That's the
+// trade-off for keeping the invariant trivially correct even if a future
+// change lets an individual chain fail without aborting its siblings --
+// last_sequence can never be set past an entry some worker hasn't
+// committed, including itself.
+func (r *LogReplayer) replayBatchConcurrent(ctx context.Context, tx DB, batch []BoardLogEntry, lastSeq int64, concurrency int) error {
+	chains := groupReplayChains(batch)
+	if len(chains) == 0 {
+		return nil
+	}
+	if concurrency > len(chains) {
+		concurrency = len(chains)
+	}
+
+	jobs := make(chan []*BoardLogEntry)
+	marks := make([]int64, concurrency)
+	for i := range marks {
+		marks[i] = -1 // -1 = this worker applied nothing; excluded from the final min
+	}
+
+	var dbMu sync.Mutex // serializes replay* calls against the single shared tx
+	var wg sync.WaitGroup
+	errCh := make(chan error, concurrency)
+
+	for w := 0; w < concurrency; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chain := range jobs {
+				for _, entry := range chain {
+					if entry.SeqNum <= lastSeq {
+						continue
+					}
+					dbMu.Lock()
+					err := r.applyEntry(ctx, tx, entry)
+					dbMu.Unlock()
+					if err != nil {
+						select {
+						case errCh <- err:
+						default:
+						}
+						return
+					}
+					marks[w] = entry.SeqNum
+				}
+			}
+		}()
+	}
+
+	for _, chain := range chains {
+		jobs <- chain
+	}
+	close(jobs)
+	wg.Wait()
+	close(errCh)
+
+	if err, ok := <-errCh; ok {
+		return err
+	}
+
+	applied := lastSeq
+	for _, m := range marks {
+		if m < 0 {
+			continue
+		}
+		if m < applied || applied == lastSeq {
+			applied = m
+		}
+	}
+	if applied > lastSeq {
+		return tx.SetLastSequence(ctx, applied)
+	}
+	return nil
+}
+
+// groupReplayChains partitions batch into the independently-schedulable
+// runs replayBatchConcurrent hands out to workers: entries are linked into
+// the same chain when they share a dependency key (create_board/
+// update_board by board ID, create_thread/close_thread by thread ID,
+// create_post/delete_post by post ID) or when one is a create_thread/
+// create_post's parent board/thread and the other is linked to that same
+// parent by a later close_thread/delete_post. Each returned chain is kept
+// in its original (seq) order, so a worker applying it sequentially
+// preserves every happens-before edge the request called for.
+func groupReplayChains(batch []BoardLogEntry) [][]*BoardLogEntry {
+	parent := make([]int, len(batch))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	lastSeenByKey := make(map[string]int)
+	threadBoard := make(map[string]string)
+	postThread := make(map[string]string)
+
+	for i := range batch {
+		own, dep := replayDepKey(&batch[i], threadBoard, postThread)
+		if prev, ok := lastSeenByKey[own]; ok {
+			union(prev, i)
+		}
+		lastSeenByKey[own] = i
+		if dep != "" {
+			if prev, ok := lastSeenByKey[dep]; ok {
+				union(prev, i)
+			}
+		}
+	}
+
+	groups := make(map[int][]*BoardLogEntry)
+	var roots []int
+	for i := range batch {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], &batch[i])
+	}
+
+	chains := make([][]*BoardLogEntry, 0, len(roots))
+	for _, root := range roots {
+		chains = append(chains, groups[root])
+	}
+	return chains
+}
+
+// replayDepKey returns entry's own dependency key and, if applicable, the
+// key of the parent it has a happens-before edge to. threadBoard and
+// postThread are updated in place as create_thread/create_post entries are
+// seen, so a later close_thread/delete_post entry for the same ID can
+// still resolve its parent even though its own BoardLogEntry carries only
+// an EntityID, not the parent's ID.
+func replayDepKey(entry *BoardLogEntry, threadBoard, postThread map[string]string) (own, dep string) {
+	switch entry.Operation {
+	case "create_board", "update_board":
+		return "board:" + entry.EntityID, ""
+
+	case "create_thread":
+		boardID := replayEntryField(entry, "board_id")
+		if boardID != "" {
+			threadBoard[entry.EntityID] = boardID
+			dep = "board:" + boardID
+		}
+		return "thread:" + entry.EntityID, dep
+
+	case "close_thread":
+		if boardID, ok := threadBoard[entry.EntityID]; ok {
+			dep = "board:" + boardID
+		}
+		return "thread:" + entry.EntityID, dep
+
+	case "create_post":
+		threadID := replayEntryField(entry, "thread_id")
+		if threadID != "" {
+			postThread[entry.EntityID] = threadID
+			dep = "thread:" + threadID
+		}
+		return "post:" + entry.EntityID, dep
+
+	case "delete_post":
+		if threadID, ok := postThread[entry.EntityID]; ok {
+			dep = "thread:" + threadID
+		}
+		return "post:" + entry.EntityID, dep
+
+	default:
+		// Unknown operations are applyEntry's no-op case; give each one
+		// its own key so it never blocks, or is blocked by, real work.
+		return fmt.Sprintf("other:%d", entry.SeqNum), ""
+	}
+}
+
+// replayEntryField best-effort extracts a single string field from
+// entry.Data without requiring callers to know whether it's a Board,
+// Thread, or Post payload; a decode failure (or missing field) just
+// returns "", the same as there being no dependency edge to resolve --
+// applyEntry's own json.Unmarshal of entry.Data still surfaces a proper
+// error for a genuinely malformed entry.
+func replayEntryField(entry *BoardLogEntry, field string) string {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(entry.Data), &raw); err != nil {
+		return ""
+	}
+	v, ok := raw[field]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return ""
+	}
+	return s
+}
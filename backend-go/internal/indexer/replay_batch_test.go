@@ -0,0 +1,192 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestReplayEntriesBatch_SerialAppliesAllAndAdvancesOnce(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer db.Close()
+
+	entries := []BoardLogEntry{
+		{SeqNum: 1, Operation: "create_board", EntityID: "board1", Data: `{"id":"board1","name":"Board One"}`},
+		{SeqNum: 2, Operation: "create_thread", EntityID: "thread1", Data: `{"id":"thread1","board_id":"board1","title":"T1","author_id":"u1"}`},
+		{SeqNum: 3, Operation: "create_post", EntityID: "post1", Data: `{"id":"post1","thread_id":"thread1","board_id":"board1","author_id":"u1","content":"hi"}`},
+	}
+
+	r := NewLogReplayer(db)
+	if err := r.ReplayEntriesBatch(ctx, entries, ReplayBatchOptions{BatchSize: 2}); err != nil {
+		t.Fatalf("ReplayEntriesBatch: %v", err)
+	}
+
+	seq, err := db.GetLastSequence(ctx)
+	if err != nil {
+		t.Fatalf("GetLastSequence: %v", err)
+	}
+	if seq != 3 {
+		t.Fatalf("GetLastSequence = %d, want 3", seq)
+	}
+
+	th, err := db.GetThread(ctx, "thread1")
+	if err != nil || th == nil {
+		t.Fatalf("GetThread(thread1) = %+v, %v", th, err)
+	}
+	if th.PostCount != 1 {
+		t.Errorf("thread1.PostCount = %d, want 1", th.PostCount)
+	}
+
+	b, err := db.GetBoard(ctx, "board1")
+	if err != nil || b == nil {
+		t.Fatalf("GetBoard(board1) = %+v, %v", b, err)
+	}
+	if b.ThreadCount != 1 {
+		t.Errorf("board1.ThreadCount = %d, want 1", b.ThreadCount)
+	}
+}
+
+func TestReplayEntriesBatch_SkipsAlreadyAppliedEntries(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+		t.Fatalf("CreateBoard: %v", err)
+	}
+	if err := db.SetLastSequence(ctx, 1); err != nil {
+		t.Fatalf("SetLastSequence: %v", err)
+	}
+
+	entries := []BoardLogEntry{
+		{SeqNum: 1, Operation: "create_board", EntityID: "board1", Data: `{"id":"board1","name":"Board One"}`},
+		{SeqNum: 2, Operation: "create_thread", EntityID: "thread1", Data: `{"id":"thread1","board_id":"board1","title":"T1","author_id":"u1"}`},
+	}
+
+	r := NewLogReplayer(db)
+	if err := r.ReplayEntriesBatch(ctx, entries, ReplayBatchOptions{}); err != nil {
+		t.Fatalf("ReplayEntriesBatch: %v", err)
+	}
+
+	th, err := db.GetThread(ctx, "thread1")
+	if err != nil || th == nil {
+		t.Fatalf("GetThread(thread1) = %+v, %v", th, err)
+	}
+}
+
+func TestReplayEntriesBatch_ConcurrentMatchesSerialResult(t *testing.T) {
+	ctx := context.Background()
+
+	build := func() DB {
+		db, err := NewSQLiteDB(":memory:")
+		if err != nil {
+			t.Fatalf("NewSQLiteDB: %v", err)
+		}
+		return db
+	}
+
+	var entries []BoardLogEntry
+	seq := int64(0)
+	for b := 0; b < 3; b++ {
+		boardID := fmt.Sprintf("board%d", b)
+		seq++
+		entries = append(entries, BoardLogEntry{SeqNum: seq, Operation: "create_board", EntityID: boardID, Data: fmt.Sprintf(`{"id":%q,"name":"Board"}`, boardID)})
+		for th := 0; th < 2; th++ {
+			threadID := fmt.Sprintf("%s-thread%d", boardID, th)
+			seq++
+			entries = append(entries, BoardLogEntry{SeqNum: seq, Operation: "create_thread", EntityID: threadID, Data: fmt.Sprintf(`{"id":%q,"board_id":%q,"title":"T","author_id":"u1"}`, threadID, boardID)})
+			for p := 0; p < 2; p++ {
+				postID := fmt.Sprintf("%s-post%d", threadID, p)
+				seq++
+				entries = append(entries, BoardLogEntry{SeqNum: seq, Operation: "create_post", EntityID: postID, Data: fmt.Sprintf(`{"id":%q,"thread_id":%q,"board_id":%q,"author_id":"u1","content":"hi"}`, postID, threadID, boardID)})
+			}
+		}
+	}
+
+	serial := build()
+	defer serial.Close()
+	if err := NewLogReplayer(serial).ReplayEntriesBatch(ctx, entries, ReplayBatchOptions{BatchSize: 100}); err != nil {
+		t.Fatalf("serial ReplayEntriesBatch: %v", err)
+	}
+
+	concurrent := build()
+	defer concurrent.Close()
+	if err := NewLogReplayer(concurrent).ReplayEntriesBatch(ctx, entries, ReplayBatchOptions{BatchSize: 100, Concurrency: 4}); err != nil {
+		t.Fatalf("concurrent ReplayEntriesBatch: %v", err)
+	}
+
+	serialSeq, _ := serial.GetLastSequence(ctx)
+	concurrentSeq, _ := concurrent.GetLastSequence(ctx)
+	if serialSeq != concurrentSeq {
+		t.Fatalf("GetLastSequence: serial=%d concurrent=%d", serialSeq, concurrentSeq)
+	}
+	if concurrentSeq != seq {
+		t.Fatalf("GetLastSequence = %d, want %d", concurrentSeq, seq)
+	}
+
+	for b := 0; b < 3; b++ {
+		boardID := fmt.Sprintf("board%d", b)
+		sb, err := serial.GetBoard(ctx, boardID)
+		if err != nil {
+			t.Fatalf("serial GetBoard(%s): %v", boardID, err)
+		}
+		cb, err := concurrent.GetBoard(ctx, boardID)
+		if err != nil {
+			t.Fatalf("concurrent GetBoard(%s): %v", boardID, err)
+		}
+		if sb.ThreadCount != cb.ThreadCount {
+			t.Errorf("%s.ThreadCount: serial=%d concurrent=%d", boardID, sb.ThreadCount, cb.ThreadCount)
+		}
+		for th := 0; th < 2; th++ {
+			threadID := fmt.Sprintf("%s-thread%d", boardID, th)
+			st, err := serial.GetThread(ctx, threadID)
+			if err != nil {
+				t.Fatalf("serial GetThread(%s): %v", threadID, err)
+			}
+			ct, err := concurrent.GetThread(ctx, threadID)
+			if err != nil {
+				t.Fatalf("concurrent GetThread(%s): %v", threadID, err)
+			}
+			if st.PostCount != ct.PostCount {
+				t.Errorf("%s.PostCount: serial=%d concurrent=%d", threadID, st.PostCount, ct.PostCount)
+			}
+		}
+	}
+}
+
+func TestGroupReplayChains_LinksThreadAndPostToParentBoard(t *testing.T) {
+	batch := []BoardLogEntry{
+		{SeqNum: 1, Operation: "create_board", EntityID: "boardA", Data: `{"id":"boardA"}`},
+		{SeqNum: 2, Operation: "create_board", EntityID: "boardB", Data: `{"id":"boardB"}`},
+		{SeqNum: 3, Operation: "create_thread", EntityID: "t1", Data: `{"id":"t1","board_id":"boardA"}`},
+		{SeqNum: 4, Operation: "create_thread", EntityID: "t2", Data: `{"id":"t2","board_id":"boardB"}`},
+		{SeqNum: 5, Operation: "create_post", EntityID: "p1", Data: `{"id":"p1","thread_id":"t1"}`},
+		{SeqNum: 6, Operation: "delete_post", EntityID: "p1"},
+	}
+
+	chains := groupReplayChains(batch)
+
+	var chainOf = make(map[int64]int)
+	for i, chain := range chains {
+		for _, e := range chain {
+			chainOf[e.SeqNum] = i
+		}
+	}
+
+	if chainOf[1] != chainOf[3] || chainOf[3] != chainOf[5] || chainOf[5] != chainOf[6] {
+		t.Errorf("expected boardA/t1/p1/delete_post(p1) in one chain, got %v", chainOf)
+	}
+	if chainOf[2] != chainOf[4] {
+		t.Errorf("expected boardB/t2 in one chain, got %v", chainOf)
+	}
+	if chainOf[1] == chainOf[2] {
+		t.Errorf("expected boardA's chain and boardB's chain to be independent, got %v", chainOf)
+	}
+}
@@ -0,0 +1,712 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// maxSearchLimit caps SearchPostsRequest.Limit / SearchThreadsRequest.Limit
+// so a caller can't force an unbounded scan of posts_fts/threads_fts.
+const maxSearchLimit = 200
+
+// Searcher answers full-text search queries over posts and threads.
+// DB's own SearchPosts/SearchThreads (a plain SQL LIKE scan) satisfy this
+// interface, so APIHandler works unchanged with no search backend
+// configured; NewFTSSearcher plugs in a dedicated SQLite FTS5 index instead.
+type Searcher interface {
+	SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error)
+	SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error)
+	// SearchPostsStructured/SearchThreadsStructured are SearchPosts/
+	// SearchThreads' structured-query counterparts: the entry point for a
+	// request built by ParseSearchQuery (or with BoardIDs/AuthorIDs/
+	// ThreadIDs/IncludeTerms/ExcludeTerms/PhraseTerms set directly). DB's
+	// own plain-LIKE implementation (see sqliteSearchPosts/pgSearchPosts)
+	// treats them identically to SearchPosts/SearchThreads; FTSSearcher
+	// compiles the structured fields into a real FTS5 boolean MATCH
+	// expression instead of a single quoted phrase — see
+	// ftsStructuredMatchExpr.
+	SearchPostsStructured(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error)
+	SearchThreadsStructured(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error)
+}
+
+// FTSSearcher is the default Searcher: SQLite FTS5 virtual tables kept in
+// sync with posts/threads via triggers. It uses the fts5 'trigram' tokenizer
+// rather than the word-splitting 'unicode61' one, since unicode61 doesn't
+// segment Japanese/Chinese/Korean text into words at all; trigram indexes
+// every run of 3 runes instead, so a quoted substring query still matches
+// inside CJK text with no word-boundary assumptions. Requires sqlite3
+// compiled with FTS5 and the trigram tokenizer (sqlite 3.34+) — the
+// mattn/go-sqlite3 build must be built with the sqlite_fts5 build tag (see
+// that package's README), or posts_fts/threads_fts's CREATE VIRTUAL TABLE
+// calls fail outright. It also never uses fts5's offsets() auxiliary
+// function (see parseHighlightRanges) — this package's sqlite3 build
+// rejects any query that selects it, regardless of how the query is
+// shaped.
+type FTSSearcher struct {
+	db *sql.DB
+}
+
+// NewFTSSearcher creates posts_fts/threads_fts (and the triggers that keep
+// them in sync with posts/threads) if they don't already exist, and returns
+// a Searcher backed by them.
+func NewFTSSearcher(db *sql.DB) (*FTSSearcher, error) {
+	if err := initFTSSchema(db); err != nil {
+		return nil, err
+	}
+	return &FTSSearcher{db: db}, nil
+}
+
+func initFTSSchema(db *sql.DB) error {
+	postsFTSExisted, err := ftsTableExists(db, "posts_fts")
+	if err != nil {
+		return err
+	}
+	threadsFTSExisted, err := ftsTableExists(db, "threads_fts")
+	if err != nil {
+		return err
+	}
+
+	schema := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+            id UNINDEXED,
+            thread_id UNINDEXED,
+            board_id UNINDEXED,
+            author_id UNINDEXED,
+            is_deleted UNINDEXED,
+            content,
+            tokenize = 'trigram'
+        );`,
+		`CREATE TRIGGER IF NOT EXISTS posts_fts_ai AFTER INSERT ON posts BEGIN
+            INSERT INTO posts_fts(id, thread_id, board_id, author_id, is_deleted, content)
+            VALUES (new.id, new.thread_id, new.board_id, new.author_id, new.is_deleted, new.content);
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS posts_fts_au AFTER UPDATE ON posts BEGIN
+            UPDATE posts_fts SET thread_id = new.thread_id, board_id = new.board_id,
+                author_id = new.author_id, is_deleted = new.is_deleted, content = new.content
+            WHERE id = new.id;
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS posts_fts_ad AFTER DELETE ON posts BEGIN
+            DELETE FROM posts_fts WHERE id = old.id;
+        END;`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS threads_fts USING fts5(
+            id UNINDEXED,
+            board_id UNINDEXED,
+            title,
+            tokenize = 'trigram'
+        );`,
+		`CREATE TRIGGER IF NOT EXISTS threads_fts_ai AFTER INSERT ON threads BEGIN
+            INSERT INTO threads_fts(id, board_id, title) VALUES (new.id, new.board_id, new.title);
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS threads_fts_au AFTER UPDATE ON threads BEGIN
+            UPDATE threads_fts SET board_id = new.board_id, title = new.title WHERE id = new.id;
+        END;`,
+		`CREATE TRIGGER IF NOT EXISTS threads_fts_ad AFTER DELETE ON threads BEGIN
+            DELETE FROM threads_fts WHERE id = old.id;
+        END;`,
+	}
+	for _, q := range schema {
+		if _, err := db.Exec(q); err != nil {
+			return fmt.Errorf("init fts schema: %w", err)
+		}
+	}
+
+	// The triggers above only keep posts_fts/threads_fts in sync with
+	// posts/threads going forward; a table that didn't exist yet (a fresh
+	// index, or one built against a database that already had rows) needs
+	// its existing rows backfilled once, or every post/thread written
+	// before this call would be permanently unsearchable.
+	if !postsFTSExisted {
+		if _, err := db.Exec(`
+            INSERT INTO posts_fts(id, thread_id, board_id, author_id, is_deleted, content)
+            SELECT id, thread_id, board_id, author_id, is_deleted, content FROM posts;
+        `); err != nil {
+			return fmt.Errorf("backfill posts_fts: %w", err)
+		}
+	}
+	if !threadsFTSExisted {
+		if _, err := db.Exec(`
+            INSERT INTO threads_fts(id, board_id, title)
+            SELECT id, board_id, title FROM threads;
+        `); err != nil {
+			return fmt.Errorf("backfill threads_fts: %w", err)
+		}
+	}
+	return nil
+}
+
+// ftsTableExists reports whether an FTS5 virtual table (or any object)
+// named name already exists in sqlite_master, so initFTSSchema can tell
+// a fresh CREATE VIRTUAL TABLE ... IF NOT EXISTS apart from one that was
+// already there, and only backfill existing rows in the fresh case.
+func ftsTableExists(db *sql.DB, name string) (bool, error) {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, name).Scan(&n); err != nil {
+		return false, fmt.Errorf("check fts table %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+// ftsQuote turns query into an fts5 quoted phrase, so a trigram match looks
+// for that exact run of characters rather than treating query as a boolean
+// expression of bareword terms. If prefix is true, the phrase is suffixed
+// with fts5's trailing "*" prefix-match operator, so e.g. ftsQuote("掲示板",
+// true) matches any indexed run of characters starting with "掲示板"
+// rather than only an exact one.
+func ftsQuote(query string, prefix bool) string {
+	q := `"` + strings.ReplaceAll(query, `"`, `""`) + `"`
+	if prefix {
+		q += " *"
+	}
+	return q
+}
+
+// ftsStructuredMatchExpr compiles IncludeTerms/PhraseTerms/ExcludeTerms (see
+// PostQuery's fields of the same name) into a single fts5 boolean MATCH
+// expression: every include/phrase term is AND-ed together (quoted via
+// ftsQuote so a multi-word phrase matches as a phrase, not as
+// implicitly-ANDed bareword tokens), and every exclude term is appended as
+// "NOT "<term>"". ok is false when there's no include/phrase term, since
+// fts5 has no way to MATCH "not X" without some positive term to anchor the
+// query on — callers should fall back to a plain SearchPosts/SearchThreads
+// query in that case.
+func ftsStructuredMatchExpr(include, phrase, exclude []string) (expr string, ok bool) {
+	var parts []string
+	for _, term := range include {
+		if term != "" {
+			parts = append(parts, ftsQuote(term, false))
+		}
+	}
+	for _, term := range phrase {
+		if term != "" {
+			parts = append(parts, ftsQuote(term, false))
+		}
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	expr = strings.Join(parts, " AND ")
+	for _, term := range exclude {
+		if term == "" {
+			continue
+		}
+		expr += " NOT " + ftsQuote(term, false)
+	}
+	return expr, true
+}
+
+// ftsAppendInClause ANDs a "col IN (?, ?, ...)" condition onto where/args
+// when ids is non-empty, the fts-path equivalent of appendInClause in
+// query.go.
+func ftsAppendInClause(where []string, args []any, col string, ids []string) ([]string, []any) {
+	if len(ids) == 0 {
+		return where, args
+	}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	where = append(where, col+" IN ("+strings.Join(placeholders, ", ")+")")
+	return where, args
+}
+
+// ftsIDBatchSize caps how many ids go into a single ftsAppendInClause call
+// when searchPosts/searchThreads re-fetch full rows for an fts5 match set.
+// SQLite's default SQLITE_MAX_VARIABLE_NUMBER is 999; 500 leaves headroom
+// for the other bound parameters (date filters, author_id) in the same
+// query.
+const ftsIDBatchSize = 500
+
+// ftsChunkIDs splits ids into ftsIDBatchSize-sized slices, so searchPosts/
+// searchThreads can re-fetch a potentially large fts5 match set across
+// several "col IN (?, ...)" queries instead of one sized to the whole set.
+func ftsChunkIDs(ids []string) [][]string {
+	var chunks [][]string
+	for len(ids) > 0 {
+		n := ftsIDBatchSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		chunks = append(chunks, ids[:n])
+		ids = ids[n:]
+	}
+	return chunks
+}
+
+// mergeIDs appends single (the deprecated singular BoardID/ThreadID/
+// AuthorID request field) onto ids (its plural BoardIDs/ThreadIDs/AuthorIDs
+// counterpart) when single is set, same fold-forward behavior
+// sqliteSearchPosts/pgSearchPosts apply to PostQuery.
+func mergeIDs(ids []string, single string) []string {
+	if single == "" {
+		return ids
+	}
+	return append(append([]string{}, ids...), single)
+}
+
+// ftsMatchMarkStart/ftsMatchMarkEnd bracket a match inside a snippet()/
+// highlight() result. Posts/threads already carry markdown body content
+// (see types.PostBody.Format), so "**...**" reads as emphasis in any
+// markdown renderer the frontend already has, instead of introducing an
+// HTML tag a JSON API consumer would have to sanitize.
+//
+// ftsOffsetMarkStart/ftsOffsetMarkEnd bracket matches the same way for an
+// internal-only second highlight() call that parseHighlightRanges reads to
+// derive Highlights (see that function) — distinct, unlikely-ever-to-appear
+// control bytes rather than ftsMatchMarkStart/End, so a post whose markdown
+// content happens to already contain "**" (ordinary bold syntax) can't be
+// mistaken for a match boundary the way reusing the display markers would.
+const (
+	ftsMatchMarkStart = "**"
+	ftsMatchMarkEnd   = "**"
+
+	ftsOffsetMarkStart = "\x01"
+	ftsOffsetMarkEnd   = "\x02"
+)
+
+// ftsSnippetExpr/ftsHighlightExpr build the SELECT-list expressions for
+// SearchPostsRequest.Snippet/Highlight (or their SearchThreadsRequest
+// equivalents): NULL (as the appropriate type) when the caller didn't ask
+// for it, so the scan target is always present regardless of which flags
+// were set. col is the FTS5 column's 0-based index within the virtual
+// table (5 for posts_fts.content, 2 for threads_fts.title).
+func ftsSnippetExpr(table string, col int, want bool) string {
+	if !want {
+		return "NULL"
+	}
+	return fmt.Sprintf("snippet(%s, %d, '%s', '%s', '...', 24)", table, col, ftsMatchMarkStart, ftsMatchMarkEnd)
+}
+
+func ftsHighlightExpr(table string, col int, want bool, markStart, markEnd string) string {
+	if !want {
+		return "NULL"
+	}
+	return fmt.Sprintf("highlight(%s, %d, '%s', '%s')", table, col, markStart, markEnd)
+}
+
+// parseHighlightRanges derives a result's Highlights from a highlight()
+// call bracketed with markStart/markEnd, rather than fts5's own offsets()
+// auxiliary function: the sqlite3 build this package links against (see
+// FTSSearcher's doc comment) unconditionally fails any query selecting
+// offsets(), even a bare "SELECT offsets(t) FROM t WHERE t MATCH ?" with no
+// join or sort, so offsets() can't be used at all here. searchPosts/
+// searchThreads pass ftsOffsetMarkStart/End — distinct control bytes a real
+// post/thread body won't contain — specifically so this can scan for literal
+// marker occurrences without mistaking markdown the author wrote (e.g.
+// "**bold**") for a match boundary, the way reusing the "**" Highlight
+// display marker would.
+func parseHighlightRanges(marked, markStart, markEnd string) []Range {
+	var ranges []Range
+	pos := 0
+	rest := marked
+	for {
+		si := strings.Index(rest, markStart)
+		if si < 0 {
+			return ranges
+		}
+		pos += si
+		rest = rest[si+len(markStart):]
+		ei := strings.Index(rest, markEnd)
+		if ei < 0 {
+			return ranges
+		}
+		ranges = append(ranges, Range{Start: pos, End: pos + ei})
+		pos += ei
+		rest = rest[ei+len(markEnd):]
+	}
+}
+
+// ftsMatch holds one posts_fts/threads_fts MATCH row's aux-function results,
+// keyed by id in searchPosts/searchThreads: rank from bm25(), snippet/
+// highlight/offsetMarks NULL unless the request asked for them (see
+// ftsSnippetExpr/ftsHighlightExpr). offsetMarks is highlight() run a second
+// time with ftsOffsetMarkStart/End instead of the display markers, solely so
+// parseHighlightRanges has something unambiguous to scan for.
+type ftsMatch struct {
+	rank                            float64
+	snippet, highlight, offsetMarks sql.NullString
+}
+
+// sortPostSearchResults/sortThreadSearchResults apply req.OrderBy in Go
+// rather than SQL: searchPosts/searchThreads can no longer ORDER BY
+// bm25()/date columns in the same query that computes them (see searchPosts'
+// doc comment), so the two queries' results are merged unsorted and sorted
+// here instead. Rank sorts ascending (bm25's convention: lower is a better
+// match); created_at/updated_at sort ascending too, the same default
+// ListPosts/ListThreads use (see orderColumn/sqliteBuildPostsListQuery) for
+// q.SortDir other than OrderDesc.
+func sortPostSearchResults(posts []PostSearchResult, orderBy string) {
+	switch orderBy {
+	case OrderByCreatedAt:
+		sort.SliceStable(posts, func(i, j int) bool { return posts[i].CreatedAt.Before(posts[j].CreatedAt) })
+	case OrderByUpdatedAt:
+		sort.SliceStable(posts, func(i, j int) bool { return posts[i].UpdatedAt.Before(posts[j].UpdatedAt) })
+	default:
+		sort.SliceStable(posts, func(i, j int) bool { return posts[i].Rank < posts[j].Rank })
+	}
+}
+
+func sortThreadSearchResults(threads []ThreadSearchResult, orderBy string) {
+	switch orderBy {
+	case OrderByCreatedAt:
+		sort.SliceStable(threads, func(i, j int) bool { return threads[i].CreatedAt.Before(threads[j].CreatedAt) })
+	case OrderByUpdatedAt:
+		sort.SliceStable(threads, func(i, j int) bool { return threads[i].UpdatedAt.Before(threads[j].UpdatedAt) })
+	default:
+		sort.SliceStable(threads, func(i, j int) bool { return threads[i].Rank < threads[j].Rank })
+	}
+}
+
+// pagePostSearchResults/pageThreadSearchResults apply Limit/Offset in Go,
+// the paginate half of the same SQL-to-Go move described above.
+func pagePostSearchResults(posts []PostSearchResult, limit, offset int) []PostSearchResult {
+	if offset >= len(posts) {
+		return nil
+	}
+	posts = posts[offset:]
+	if limit < len(posts) {
+		posts = posts[:limit]
+	}
+	return posts
+}
+
+func pageThreadSearchResults(threads []ThreadSearchResult, limit, offset int) []ThreadSearchResult {
+	if offset >= len(threads) {
+		return nil
+	}
+	threads = threads[offset:]
+	if limit < len(threads) {
+		threads = threads[:limit]
+	}
+	return threads
+}
+
+// ftsEffectivePrefix reports whether query should be treated as a prefix
+// match: either the legacy Prefix bool, or the equivalent Mode value.
+func ftsEffectivePrefix(prefix bool, mode SearchMode) bool {
+	return prefix || mode == SearchModePrefix
+}
+
+// clampSearchPaging applies the default/offset/max-limit rules shared by
+// every SearchPosts/SearchThreads implementation in this package, whether
+// backed by FTSSearcher or DB's own plain SQL LIKE scan.
+func clampSearchPaging(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// SearchPosts ranks matches by bm25(posts_fts) rather than (created_at, id)
+// by default (req.OrderBy can ask for created_at/updated_at instead; see
+// sortPostSearchResults), so it has no stable keyset to page over the way DB's own
+// plain LIKE scan does: a row's rank can shift between requests as
+// posts_fts changes, which keyset pagination assumes never happens for the
+// column(s) it walks. It still accepts req.Limit/req.Offset
+// (clampSearchPaging's existing contract) but always returns a zero
+// NextCursor/PrevCursor; callers wanting cursor-based paging over bm25
+// results should sort client-side within a single larger page instead.
+func (f *FTSSearcher) SearchPosts(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	return f.searchPosts(ctx, req, ftsQuote(req.Query, ftsEffectivePrefix(req.Prefix, req.Mode)))
+}
+
+// SearchPostsStructured compiles req's IncludeTerms/PhraseTerms/ExcludeTerms
+// into a real fts5 boolean MATCH expression (see ftsStructuredMatchExpr)
+// instead of the single quoted phrase SearchPosts builds from req.Query. If
+// there's no include/phrase term to anchor the query on, it falls back to
+// SearchPosts, the same degrade SearchPostsStructured's doc comment on
+// Searcher promises.
+func (f *FTSSearcher) SearchPostsStructured(ctx context.Context, req *SearchPostsRequest) (*SearchPostsResponse, error) {
+	matchExpr, ok := ftsStructuredMatchExpr(req.IncludeTerms, req.PhraseTerms, req.ExcludeTerms)
+	if !ok {
+		return f.SearchPosts(ctx, req)
+	}
+	return f.searchPosts(ctx, req, matchExpr)
+}
+
+// searchPosts is SearchPosts/SearchPostsStructured's shared core, taking the
+// already-compiled fts5 MATCH expression to run. BoardIDs/ThreadIDs/AuthorIDs
+// are ORed against their singular BoardID/ThreadID/AuthorID counterpart via
+// an IN clause, so a caller populating either (or both, as
+// SearchPostsRequest's field docs note the deprecated singular fields can
+// be) gets the union.
+func (f *FTSSearcher) searchPosts(ctx context.Context, req *SearchPostsRequest, matchExpr string) (*SearchPostsResponse, error) {
+	req.Limit, req.Offset = clampSearchPaging(req.Limit, req.Offset)
+
+	ftsWhere := []string{"posts_fts MATCH ?", "posts_fts.is_deleted = 0"}
+	args := []any{matchExpr}
+	ftsWhere, args = ftsAppendInClause(ftsWhere, args, "posts_fts.board_id", mergeIDs(req.BoardIDs, req.BoardID))
+	ftsWhere, args = ftsAppendInClause(ftsWhere, args, "posts_fts.thread_id", mergeIDs(req.ThreadIDs, req.ThreadID))
+	ftsWhere, args = ftsAppendInClause(ftsWhere, args, "posts_fts.author_id", mergeIDs(req.AuthorIDs, req.AuthorID))
+	ftsWhereSQL := strings.Join(ftsWhere, " AND ")
+
+	// bm25()/snippet()/highlight() are fts5 auxiliary functions: sqlite only
+	// lets them run against a query that scans posts_fts directly and does
+	// nothing else with the result — joining in posts (needed for OrderBy's
+	// date columns and for every column the caller actually wants back)
+	// trips "unable to use function ... in the requested context", no
+	// matter whether the join is written directly or pushed into a
+	// subquery. So run the fts5 MATCH alone first, matching rows keyed by
+	// id, then join against posts and apply date filters/ordering/paging in
+	// a second, plain SQL query plus a Go-side sort.
+	matchRows, err := f.db.QueryContext(ctx, `
+        SELECT id, bm25(posts_fts),
+            `+ftsSnippetExpr("posts_fts", 5, req.Snippet)+`,
+            `+ftsHighlightExpr("posts_fts", 5, req.Highlight, ftsMatchMarkStart, ftsMatchMarkEnd)+`,
+            `+ftsHighlightExpr("posts_fts", 5, req.Highlight, ftsOffsetMarkStart, ftsOffsetMarkEnd)+`
+        FROM posts_fts
+        WHERE `+ftsWhereSQL+`
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fts search posts match: %w", err)
+	}
+	matches := make(map[string]ftsMatch)
+	ids := make([]string, 0)
+	for matchRows.Next() {
+		var id string
+		var m ftsMatch
+		if err := matchRows.Scan(&id, &m.rank, &m.snippet, &m.highlight, &m.offsetMarks); err != nil {
+			matchRows.Close()
+			return nil, fmt.Errorf("fts search posts match scan: %w", err)
+		}
+		matches[id] = m
+		ids = append(ids, id)
+	}
+	if err := matchRows.Err(); err != nil {
+		matchRows.Close()
+		return nil, err
+	}
+	matchRows.Close()
+
+	if len(ids) == 0 {
+		return &SearchPostsResponse{Limit: req.Limit, Offset: req.Offset}, nil
+	}
+
+	// Fetched in ftsIDBatchSize-sized batches rather than one query with an
+	// id IN (?, ...) clause sized to the whole match set: SQLite caps how
+	// many bound parameters a single statement can take (999 by default),
+	// and a broad query can match far more posts than that before
+	// OrderBy/Limit ever gets applied (see this method's doc comment).
+	var posts []PostSearchResult
+	for _, batch := range ftsChunkIDs(ids) {
+		where, postArgs := ftsAppendInClause(nil, nil, "p.id", batch)
+		if req.DateFrom != nil {
+			where = append(where, "p.created_at >= ?")
+			postArgs = append(postArgs, *req.DateFrom)
+		}
+		if req.DateTo != nil {
+			where = append(where, "p.created_at <= ?")
+			postArgs = append(postArgs, *req.DateTo)
+		}
+
+		rows, err := f.db.QueryContext(ctx, `
+            SELECT p.id, p.thread_id, p.board_id, p.author_id, p.content, p.created_at, p.updated_at, p.is_deleted, p.reply_to
+            FROM posts p
+            WHERE `+strings.Join(where, " AND ")+`
+        `, postArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("fts search posts query: %w", err)
+		}
+		for rows.Next() {
+			var p PostSearchResult
+			var deleted int
+			var replyTo sql.NullString
+			if err := rows.Scan(&p.ID, &p.ThreadID, &p.BoardID, &p.AuthorID, &p.Content,
+				&p.CreatedAt, &p.UpdatedAt, &deleted, &replyTo); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("fts search posts scan: %w", err)
+			}
+			p.IsDeleted = deleted != 0
+			if replyTo.Valid {
+				p.ReplyTo = replyTo.String
+			}
+			m := matches[p.ID]
+			p.Rank = m.rank
+			p.Snippet = m.snippet.String
+			p.Highlight = m.highlight.String
+			p.Highlights = parseHighlightRanges(m.offsetMarks.String, ftsOffsetMarkStart, ftsOffsetMarkEnd)
+			posts = append(posts, p)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+
+	total := len(posts)
+	sortPostSearchResults(posts, req.OrderBy)
+	posts = pagePostSearchResults(posts, req.Limit, req.Offset)
+
+	return &SearchPostsResponse{
+		Posts:      posts,
+		TotalCount: total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}, nil
+}
+
+// SearchThreads has the same bm25-ranking caveat as SearchPosts: no stable
+// keyset, so NextCursor/PrevCursor are always zero.
+func (f *FTSSearcher) SearchThreads(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	return f.searchThreads(ctx, req, ftsQuote(req.Query, ftsEffectivePrefix(req.Prefix, req.Mode)))
+}
+
+// SearchThreadsStructured is SearchPostsStructured's analogue for threads;
+// see it for the fts5 MATCH-compilation and plain-SearchThreads-fallback
+// behavior.
+func (f *FTSSearcher) SearchThreadsStructured(ctx context.Context, req *SearchThreadsRequest) (*SearchThreadsResponse, error) {
+	matchExpr, ok := ftsStructuredMatchExpr(req.IncludeTerms, req.PhraseTerms, req.ExcludeTerms)
+	if !ok {
+		return f.SearchThreads(ctx, req)
+	}
+	return f.searchThreads(ctx, req, matchExpr)
+}
+
+// searchThreads is searchPosts' analogue for SearchThreads/
+// SearchThreadsStructured.
+func (f *FTSSearcher) searchThreads(ctx context.Context, req *SearchThreadsRequest, matchExpr string) (*SearchThreadsResponse, error) {
+	req.Limit, req.Offset = clampSearchPaging(req.Limit, req.Offset)
+
+	ftsWhere := []string{"threads_fts MATCH ?"}
+	args := []any{matchExpr}
+	ftsWhere, args = ftsAppendInClause(ftsWhere, args, "threads_fts.board_id", mergeIDs(req.BoardIDs, req.BoardID))
+	ftsWhereSQL := strings.Join(ftsWhere, " AND ")
+
+	// See searchPosts for why bm25()/snippet()/highlight() have to be
+	// computed against threads_fts alone, with the join to threads, date
+	// filters, ordering and paging done in a second query plus a Go-side
+	// sort instead.
+	matchRows, err := f.db.QueryContext(ctx, `
+        SELECT id, bm25(threads_fts),
+            `+ftsSnippetExpr("threads_fts", 2, req.Snippet)+`,
+            `+ftsHighlightExpr("threads_fts", 2, req.Highlight, ftsMatchMarkStart, ftsMatchMarkEnd)+`,
+            `+ftsHighlightExpr("threads_fts", 2, req.Highlight, ftsOffsetMarkStart, ftsOffsetMarkEnd)+`
+        FROM threads_fts
+        WHERE `+ftsWhereSQL+`
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fts search threads match: %w", err)
+	}
+	matches := make(map[string]ftsMatch)
+	ids := make([]string, 0)
+	for matchRows.Next() {
+		var id string
+		var m ftsMatch
+		if err := matchRows.Scan(&id, &m.rank, &m.snippet, &m.highlight, &m.offsetMarks); err != nil {
+			matchRows.Close()
+			return nil, fmt.Errorf("fts search threads match scan: %w", err)
+		}
+		matches[id] = m
+		ids = append(ids, id)
+	}
+	if err := matchRows.Err(); err != nil {
+		matchRows.Close()
+		return nil, err
+	}
+	matchRows.Close()
+
+	if len(ids) == 0 {
+		return &SearchThreadsResponse{Limit: req.Limit, Offset: req.Offset}, nil
+	}
+
+	// See searchPosts for why this re-fetch is batched rather than one
+	// id IN (?, ...) clause sized to the whole match set.
+	var threads []ThreadSearchResult
+	for _, batch := range ftsChunkIDs(ids) {
+		where, threadArgs := ftsAppendInClause(nil, nil, "t.id", batch)
+		where, threadArgs = ftsAppendInClause(where, threadArgs, "t.author_id", mergeIDs(req.AuthorIDs, req.AuthorID))
+		if req.DateFrom != nil {
+			where = append(where, "t.created_at >= ?")
+			threadArgs = append(threadArgs, *req.DateFrom)
+		}
+		if req.DateTo != nil {
+			where = append(where, "t.created_at <= ?")
+			threadArgs = append(threadArgs, *req.DateTo)
+		}
+
+		rows, err := f.db.QueryContext(ctx, `
+            SELECT t.id, t.board_id, t.title, t.author_id, t.created_at, t.updated_at, t.post_count, t.is_closed
+            FROM threads t
+            WHERE `+strings.Join(where, " AND ")+`
+        `, threadArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("fts search threads query: %w", err)
+		}
+		for rows.Next() {
+			var th ThreadSearchResult
+			var closed int
+			if err := rows.Scan(&th.ID, &th.BoardID, &th.Title, &th.AuthorID,
+				&th.CreatedAt, &th.UpdatedAt, &th.PostCount, &closed); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("fts search threads scan: %w", err)
+			}
+			th.IsClosed = closed != 0
+			m := matches[th.ID]
+			th.Rank = m.rank
+			th.Snippet = m.snippet.String
+			th.Highlight = m.highlight.String
+			th.Highlights = parseHighlightRanges(m.offsetMarks.String, ftsOffsetMarkStart, ftsOffsetMarkEnd)
+			threads = append(threads, th)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+
+	total := len(threads)
+	sortThreadSearchResults(threads, req.OrderBy)
+	threads = pageThreadSearchResults(threads, req.Limit, req.Offset)
+
+	return &SearchThreadsResponse{
+		Threads:    threads,
+		TotalCount: total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}, nil
+}
+
+// Reindexer drains BoardLogEntry values arriving from the IPFS-backed
+// source of truth and replays them in the background, same as LogReplayer
+// does synchronously elsewhere. Replaying writes to posts/threads, and the
+// triggers NewFTSSearcher installs keep posts_fts/threads_fts in sync with
+// those tables automatically — so reindexing search is just replaying.
+type Reindexer struct {
+	replayer *LogReplayer
+}
+
+// NewReindexer returns a Reindexer that replays entries against db.
+func NewReindexer(db DB) *Reindexer {
+	return &Reindexer{replayer: NewLogReplayer(db)}
+}
+
+// Run replays entries off the channel as they arrive until ctx is canceled
+// or the channel is closed. A replay error is logged and does not stop the
+// loop, matching LogReplayer's own tolerance of unknown operations.
+func (r *Reindexer) Run(ctx context.Context, entries <-chan BoardLogEntry) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			if err := r.replayer.ReplayEntry(ctx, &entry); err != nil {
+				log.Printf("Reindexer: replay seq=%d op=%s: %v", entry.SeqNum, entry.Operation, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package indexer
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestFTSSearcher_SearchPosts_MatchesCJKSubstring(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{
+        ID:       "p1",
+        ThreadID: "t1",
+        BoardID:  "b1",
+        AuthorID: "u1",
+        Content:  "今日は掲示板のテストを書いています",
+    }); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{
+        ID:       "p2",
+        ThreadID: "t1",
+        BoardID:  "b1",
+        AuthorID: "u1",
+        Content:  "unrelated english content",
+    }); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    resp, err := searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "掲示板"})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 1 || len(resp.Posts) != 1 || resp.Posts[0].ID != "p1" {
+        t.Fatalf("unexpected response: %+v", resp)
+    }
+}
+
+func TestFTSSearcher_SearchPosts_ExcludesDeletedAndHonorsFilters(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p1", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "keep this post"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p2", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "keep this too"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+    if err := db.DeletePost(ctx, "p2"); err != nil {
+        t.Fatalf("DeletePost: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    resp, err := searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "keep"})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 1 || len(resp.Posts) != 1 || resp.Posts[0].ID != "p1" {
+        t.Fatalf("expected only the non-deleted post to match, got: %+v", resp)
+    }
+
+    resp, err = searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "keep", ThreadID: "does-not-exist"})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 0 {
+        t.Fatalf("thread_id filter: TotalCount = %d, want 0", resp.TotalCount)
+    }
+}
+
+func TestFTSSearcher_SearchThreads(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "週末の予定について", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t2", BoardID: "b1", Title: "something else", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    resp, err := searcher.SearchThreads(ctx, &SearchThreadsRequest{Query: "週末"})
+    if err != nil {
+        t.Fatalf("SearchThreads: %v", err)
+    }
+    if resp.TotalCount != 1 || len(resp.Threads) != 1 || resp.Threads[0].ID != "t1" {
+        t.Fatalf("unexpected response: %+v", resp)
+    }
+}
+
+func TestFTSSearcher_SearchPosts_PrefixSnippetHighlight(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p1", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "今日は掲示板のテストを書いています"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    resp, err := searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "掲示", Prefix: true, Snippet: true, Highlight: true})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 1 || len(resp.Posts) != 1 || resp.Posts[0].ID != "p1" {
+        t.Fatalf("prefix query: unexpected response: %+v", resp)
+    }
+    if resp.Posts[0].Snippet == "" {
+        t.Fatalf("expected Snippet to be populated, got empty")
+    }
+    if resp.Posts[0].Highlight == "" {
+        t.Fatalf("expected Highlight to be populated, got empty")
+    }
+
+    resp, err = searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "掲示"})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.Posts[0].Snippet != "" || resp.Posts[0].Highlight != "" {
+        t.Fatalf("Snippet/Highlight should stay empty unless requested, got: %+v", resp.Posts[0])
+    }
+}
+
+func TestFTSSearcher_SearchPosts_DateRangeFilter(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p1", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "dated post"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    future := time.Now().UTC().Add(24 * time.Hour)
+    resp, err := searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "dated", DateFrom: &future})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 0 {
+        t.Fatalf("date_from in the future should exclude the post, got: %+v", resp)
+    }
+
+    past := time.Now().UTC().Add(-24 * time.Hour)
+    resp, err = searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "dated", DateFrom: &past})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 1 {
+        t.Fatalf("date_from in the past should include the post, got: %+v", resp)
+    }
+}
+
+func TestFTSSearcher_SearchPosts_ModeOrderByAndHighlights(t *testing.T) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.CreateBoard(ctx, &Board{ID: "b1", Name: "B", Description: "desc"}); err != nil {
+        t.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"}); err != nil {
+        t.Fatalf("CreateThread: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p1", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "掲示板の検索機能について"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+    if err := db.CreatePost(ctx, &Post{ID: "p2", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "掲示板掲示板掲示板のテスト"}); err != nil {
+        t.Fatalf("CreatePost: %v", err)
+    }
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    resp, err := searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "掲示板", Mode: SearchModePrefix, Highlight: true})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 2 {
+        t.Fatalf("expected both posts to match, got: %+v", resp)
+    }
+    if resp.Posts[0].Rank == 0 && resp.Posts[1].Rank == 0 {
+        t.Fatalf("expected at least one non-zero Rank, got: %+v", resp.Posts)
+    }
+    for _, p := range resp.Posts {
+        if len(p.Highlights) == 0 {
+            t.Fatalf("expected Highlights to be populated for post %s, got none", p.ID)
+        }
+    }
+
+    resp, err = searcher.SearchPosts(ctx, &SearchPostsRequest{Query: "掲示板", OrderBy: OrderByCreatedAt})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 2 || resp.Posts[0].ID != "p1" || resp.Posts[1].ID != "p2" {
+        t.Fatalf("OrderByCreatedAt: expected p1 then p2, got: %+v", resp.Posts)
+    }
+}
+
+func TestReindexer_Run_AppliesEntriesAndSearcherSeesThem(t *testing.T) {
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    sqlDB := db.(*sqliteDB).db
+    searcher, err := NewFTSSearcher(sqlDB)
+    if err != nil {
+        t.Fatalf("NewFTSSearcher: %v", err)
+    }
+
+    boardData, _ := json.Marshal(Board{ID: "b1", Name: "B", Description: "desc"})
+    threadData, _ := json.Marshal(Thread{ID: "t1", BoardID: "b1", Title: "T", AuthorID: "u1"})
+    postData, _ := json.Marshal(Post{ID: "p1", ThreadID: "t1", BoardID: "b1", AuthorID: "u1", Content: "reindexed content"})
+
+    entries := make(chan BoardLogEntry, 3)
+    entries <- BoardLogEntry{SeqNum: 1, Timestamp: time.Now().UTC(), Operation: "create_board", EntityID: "b1", Data: string(boardData)}
+    entries <- BoardLogEntry{SeqNum: 2, Timestamp: time.Now().UTC(), Operation: "create_thread", EntityID: "t1", Data: string(threadData)}
+    entries <- BoardLogEntry{SeqNum: 3, Timestamp: time.Now().UTC(), Operation: "create_post", EntityID: "p1", Data: string(postData)}
+    close(entries)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    NewReindexer(db).Run(ctx, entries)
+
+    resp, err := searcher.SearchPosts(context.Background(), &SearchPostsRequest{Query: "reindexed"})
+    if err != nil {
+        t.Fatalf("SearchPosts: %v", err)
+    }
+    if resp.TotalCount != 1 || len(resp.Posts) != 1 || resp.Posts[0].ID != "p1" {
+        t.Fatalf("Reindexer did not make the replayed post searchable: %+v", resp)
+    }
+}
@@ -0,0 +1,227 @@
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// snapshotMagic/snapshotVersion identify a SnapshotAt stream so
+// RestoreSnapshot can reject a truncated or unrelated file before
+// decoding anything else, rather than failing partway through with a
+// confusing JSON error or (worse) loading a half-written dump.
+const (
+	snapshotMagic   = "flexbbs-indexer-snapshot"
+	snapshotVersion = 1
+)
+
+// snapshotHeader is the first record SnapshotAt writes and RestoreSnapshot
+// reads: a small fixed header ahead of the row records, giving
+// RestoreSnapshot the row counts it needs to know when each section ends
+// without depending on a second pass or in-band sentinel values.
+type snapshotHeader struct {
+	Magic       string `json:"magic"`
+	Version     int    `json:"version"`
+	SeqNum      int64  `json:"seq_num"`
+	BoardCount  int    `json:"board_count"`
+	ThreadCount int    `json:"thread_count"`
+	PostCount   int    `json:"post_count"`
+}
+
+// SnapshotAt streams a consistent dump of every Board/Thread/Post row,
+// plus the log sequence number the caller has replayed up to, so a
+// RestoreSnapshot elsewhere can skip straight to ReplayEntries for
+// whatever tail of the board log comes after it instead of replaying
+// everything from seq 1 -- the same problem etcd's own snapshotting
+// solves for its raft log. The caller is responsible for passing the
+// SeqNum its own GetLastSequence reports as of a point consistent with
+// the rows being dumped (e.g. from inside the WithTx a replay just
+// committed in); SnapshotAt itself just records whatever seqNum it's
+// given, the same way logchain.Appender trusts its caller for prev-hash
+// bookkeeping rather than re-deriving it.
+//
+// The stream is one JSON header record (see snapshotHeader) followed by
+// one newline-delimited JSON record per row -- boards, then threads, then
+// posts, matching the order RestoreSnapshot must insert them in so
+// CreateThread/CreatePost's board/thread-count side effects land on rows
+// that already exist.
+func (r *LogReplayer) SnapshotAt(ctx context.Context, seqNum int64) (io.ReadCloser, error) {
+	boards, err := r.db.ListBoards(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: list boards: %w", err)
+	}
+	threads, err := snapshotListAllThreads(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: list threads: %w", err)
+	}
+	posts, err := snapshotListAllPosts(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: list posts: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeSnapshot(pw, seqNum, boards, threads, posts))
+	}()
+	return pr, nil
+}
+
+// snapshotListAllThreads pages through every thread in the DB (live and
+// soft-deleted) via ListThreads, the same unfiltered-except-for-paging
+// query SearchThreads builds on, reusing it here instead of a one-off
+// "list everything" method.
+func snapshotListAllThreads(ctx context.Context, db DB) ([]Thread, error) {
+	var out []Thread
+	var after Cursor
+	for {
+		page, next, _, err := db.ListThreads(ctx, ThreadQuery{IncludeDeleted: true, Limit: maxListLimit, After: after})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if next == "" {
+			return out, nil
+		}
+		after = next
+	}
+}
+
+// snapshotListAllPosts is snapshotListAllThreads' analogue for posts.
+func snapshotListAllPosts(ctx context.Context, db DB) ([]Post, error) {
+	var out []Post
+	var after Cursor
+	for {
+		page, next, _, err := db.ListPosts(ctx, PostQuery{IncludeDeleted: true, Limit: maxListLimit, After: after})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if next == "" {
+			return out, nil
+		}
+		after = next
+	}
+}
+
+// writeSnapshot encodes header followed by boards/threads/posts as
+// newline-delimited JSON records onto w, the shape RestoreSnapshot expects.
+func writeSnapshot(w io.Writer, seqNum int64, boards []Board, threads []Thread, posts []Post) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	header := snapshotHeader{
+		Magic:       snapshotMagic,
+		Version:     snapshotVersion,
+		SeqNum:      seqNum,
+		BoardCount:  len(boards),
+		ThreadCount: len(threads),
+		PostCount:   len(posts),
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("write snapshot header: %w", err)
+	}
+	for i := range boards {
+		if err := enc.Encode(boards[i]); err != nil {
+			return fmt.Errorf("write snapshot board %d: %w", i, err)
+		}
+	}
+	for i := range threads {
+		if err := enc.Encode(threads[i]); err != nil {
+			return fmt.Errorf("write snapshot thread %d: %w", i, err)
+		}
+	}
+	for i := range posts {
+		if err := enc.Encode(posts[i]); err != nil {
+			return fmt.Errorf("write snapshot post %d: %w", i, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// RestoreSnapshot atomically replaces r's DB state with the dump snap
+// streams (as produced by SnapshotAt) and sets last_sequence to the
+// snapshot's SeqNum, all inside one WithTx. It expects to run against an
+// empty DB -- the normal case being a freshly created sqlite file on
+// process startup -- not one with pre-existing rows: there's no
+// "truncate everything" primitive in the DB interface to clear stale data
+// first, so restoring over a non-empty DB will surface as duplicate-key
+// errors from CreateBoard/CreateThread/CreatePost rather than silently
+// merging or overwriting.
+//
+// Boards and threads are inserted with their ThreadCount/PostCount zeroed
+// rather than the value recorded in the snapshot: CreateThread/CreatePost
+// already increment their parent's count as a side effect of inserting a
+// row, the same as they do during normal log replay, so letting that run
+// for every dumped row reconstructs the correct counts instead of
+// double-counting whatever the snapshot recorded. A board or thread whose
+// children include soft-deleted rows may still end up with an inflated
+// thread_count/post_count afterward, the same pre-existing gap
+// Recalculate exists to close (RestoreSnapshot calls it for post_count at
+// the end; Recalculate has never covered board.thread_count).
+func (r *LogReplayer) RestoreSnapshot(ctx context.Context, snap io.Reader) error {
+	dec := json.NewDecoder(snap)
+
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("restore snapshot: read header: %w", err)
+	}
+	if header.Magic != snapshotMagic {
+		return fmt.Errorf("restore snapshot: not a flex-bbs indexer snapshot (bad magic %q)", header.Magic)
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("restore snapshot: unsupported snapshot version %d", header.Version)
+	}
+
+	return r.db.WithTx(ctx, func(tx DB) error {
+		for i := 0; i < header.BoardCount; i++ {
+			var b Board
+			if err := dec.Decode(&b); err != nil {
+				return fmt.Errorf("restore snapshot: read board %d: %w", i, err)
+			}
+			b.ThreadCount = 0
+			if err := tx.CreateBoard(ctx, &b); err != nil {
+				return fmt.Errorf("restore snapshot: create board %q: %w", b.ID, err)
+			}
+		}
+		for i := 0; i < header.ThreadCount; i++ {
+			var th Thread
+			if err := dec.Decode(&th); err != nil {
+				return fmt.Errorf("restore snapshot: read thread %d: %w", i, err)
+			}
+			th.PostCount = 0
+			if err := tx.CreateThread(ctx, &th); err != nil {
+				return fmt.Errorf("restore snapshot: create thread %q: %w", th.ID, err)
+			}
+		}
+		for i := 0; i < header.PostCount; i++ {
+			var p Post
+			if err := dec.Decode(&p); err != nil {
+				return fmt.Errorf("restore snapshot: read post %d: %w", i, err)
+			}
+			if err := tx.CreatePost(ctx, &p); err != nil {
+				return fmt.Errorf("restore snapshot: create post %q: %w", p.ID, err)
+			}
+		}
+		if _, err := tx.Recalculate(ctx, ""); err != nil {
+			return fmt.Errorf("restore snapshot: recalculate post counts: %w", err)
+		}
+		return tx.SetLastSequence(ctx, header.SeqNum)
+	})
+}
+
+// ReplayFromSnapshot restores snap and then replays tail through
+// ReplayEntries, for a process coming back up after a restart: instead of
+// replaying every BoardLogEntry ever written, it restores the last
+// snapshot taken and replays only the log tail since. tail doesn't need
+// to be pre-filtered to entries after the snapshot's SeqNum -- ReplayEntry
+// already skips any entry whose SeqNum is at or below what
+// GetLastSequence reports, so passing the snapshot's own seq (or earlier)
+// entries in tail is harmless, just wasted work.
+func (r *LogReplayer) ReplayFromSnapshot(ctx context.Context, snap io.Reader, tail []BoardLogEntry) error {
+	if err := r.RestoreSnapshot(ctx, snap); err != nil {
+		return fmt.Errorf("replay from snapshot: %w", err)
+	}
+	return r.ReplayEntries(ctx, tail)
+}
@@ -0,0 +1,167 @@
+package indexer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotAtAndRestoreSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	src, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB(src): %v", err)
+	}
+	defer src.Close()
+
+	if err := src.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+		t.Fatalf("CreateBoard: %v", err)
+	}
+	if err := src.CreateThread(ctx, &Thread{ID: "thread1", BoardID: "board1", Title: "Hello", AuthorID: "user1"}); err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	if err := src.CreatePost(ctx, &Post{ID: "post1", ThreadID: "thread1", BoardID: "board1", AuthorID: "user1", Content: "hi"}); err != nil {
+		t.Fatalf("CreatePost#1: %v", err)
+	}
+	if err := src.CreatePost(ctx, &Post{ID: "post2", ThreadID: "thread1", BoardID: "board1", AuthorID: "user2", Content: "there"}); err != nil {
+		t.Fatalf("CreatePost#2: %v", err)
+	}
+	if err := src.DeletePost(ctx, "post2"); err != nil {
+		t.Fatalf("DeletePost: %v", err)
+	}
+	if err := src.SetLastSequence(ctx, 5); err != nil {
+		t.Fatalf("SetLastSequence: %v", err)
+	}
+
+	r := NewLogReplayer(src)
+	snap, err := r.SnapshotAt(ctx, 5)
+	if err != nil {
+		t.Fatalf("SnapshotAt: %v", err)
+	}
+	defer snap.Close()
+
+	dst, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB(dst): %v", err)
+	}
+	defer dst.Close()
+
+	if err := NewLogReplayer(dst).RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+
+	seq, err := dst.GetLastSequence(ctx)
+	if err != nil {
+		t.Fatalf("GetLastSequence: %v", err)
+	}
+	if seq != 5 {
+		t.Fatalf("GetLastSequence after restore = %d, want 5", seq)
+	}
+
+	b, err := dst.GetBoard(ctx, "board1")
+	if err != nil {
+		t.Fatalf("GetBoard: %v", err)
+	}
+	if b == nil {
+		t.Fatal("GetBoard: board1 missing after restore")
+	}
+	if b.ThreadCount != 1 {
+		t.Errorf("board1.ThreadCount = %d, want 1", b.ThreadCount)
+	}
+
+	th, err := dst.GetThread(ctx, "thread1")
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if th == nil {
+		t.Fatal("GetThread: thread1 missing after restore")
+	}
+	if th.PostCount != 1 {
+		t.Errorf("thread1.PostCount = %d, want 1 (post2 was soft-deleted)", th.PostCount)
+	}
+
+	p1, err := dst.GetPost(ctx, "post1")
+	if err != nil {
+		t.Fatalf("GetPost(post1): %v", err)
+	}
+	if p1 == nil || p1.Content != "hi" {
+		t.Errorf("GetPost(post1) = %+v, want Content=hi", p1)
+	}
+
+	p2, err := dst.GetPost(ctx, "post2")
+	if err != nil {
+		t.Fatalf("GetPost(post2): %v", err)
+	}
+	if p2 == nil || !p2.IsDeleted {
+		t.Errorf("GetPost(post2) = %+v, want IsDeleted=true", p2)
+	}
+}
+
+func TestRestoreSnapshot_RejectsBadMagic(t *testing.T) {
+	ctx := context.Background()
+	dst, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer dst.Close()
+
+	bad := strings.NewReader(`{"magic":"not-a-snapshot","version":1}`)
+	if err := NewLogReplayer(dst).RestoreSnapshot(ctx, bad); err == nil {
+		t.Fatal("expected RestoreSnapshot to reject a stream with the wrong magic")
+	}
+}
+
+func TestReplayFromSnapshot_AppliesTailAfterRestore(t *testing.T) {
+	ctx := context.Background()
+
+	src, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB(src): %v", err)
+	}
+	defer src.Close()
+
+	if err := src.CreateBoard(ctx, &Board{ID: "board1", Name: "Board One"}); err != nil {
+		t.Fatalf("CreateBoard: %v", err)
+	}
+	if err := src.SetLastSequence(ctx, 1); err != nil {
+		t.Fatalf("SetLastSequence: %v", err)
+	}
+
+	r := NewLogReplayer(src)
+	snap, err := r.SnapshotAt(ctx, 1)
+	if err != nil {
+		t.Fatalf("SnapshotAt: %v", err)
+	}
+	defer snap.Close()
+
+	dst, err := NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB(dst): %v", err)
+	}
+	defer dst.Close()
+
+	tail := []BoardLogEntry{
+		{SeqNum: 1, Operation: "create_board", EntityID: "board1", Data: `{"id":"board1","name":"Board One"}`},
+		{SeqNum: 2, Operation: "create_thread", EntityID: "thread1", Data: `{"id":"thread1","board_id":"board1","title":"New","author_id":"user1"}`},
+	}
+	if err := NewLogReplayer(dst).ReplayFromSnapshot(ctx, snap, tail); err != nil {
+		t.Fatalf("ReplayFromSnapshot: %v", err)
+	}
+
+	seq, err := dst.GetLastSequence(ctx)
+	if err != nil {
+		t.Fatalf("GetLastSequence: %v", err)
+	}
+	if seq != 2 {
+		t.Fatalf("GetLastSequence after replay = %d, want 2", seq)
+	}
+
+	th, err := dst.GetThread(ctx, "thread1")
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if th == nil {
+		t.Fatal("GetThread: thread1 missing; tail entry seq=2 should have been replayed on top of the restored snapshot")
+	}
+}
@@ -0,0 +1,256 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// stmtID identifies one of sqliteDB's fixed, parameterized queries. Keying
+// the cache by this small enum rather than the SQL text itself means
+// stmtQueries is the one place that owns each statement's source, and a
+// typo in a call site fails at compile time instead of silently preparing
+// (and caching) a second copy of the same query.
+type stmtID int
+
+const (
+	stmtGetLastSequence stmtID = iota
+	stmtSetLastSequence
+	stmtGetLastHash
+	stmtSetLastHash
+	stmtCreateBoard
+	stmtUpdateBoard
+	stmtGetBoard
+	stmtListBoards
+	stmtCreateThread
+	stmtIncrementBoardThreadCount
+	stmtUpdateThread
+	stmtGetThread
+	stmtCloseThread
+	stmtCreatePost
+	stmtIncrementThreadPostCount
+	stmtUpdatePost
+	stmtGetPost
+	stmtGetPostThreadID
+	stmtMarkPostDeleted
+	stmtDecrementThreadPostCount
+	stmtGetThreadBoardID
+	stmtMarkThreadDeleted
+	stmtMarkThreadPostsDeleted
+	stmtDecrementBoardThreadCount
+)
+
+// stmtOps names every stmtID for QueryEvent.Op, so a QueryHook (tracing,
+// metrics, ...) can label a cached statement's calls the same way it
+// labels the dynamically-built queries in ListThreadsByBoard/
+// ListPostsByThread/SearchPosts/SearchThreads, which set Op by hand.
+var stmtOps = map[stmtID]string{
+	stmtGetLastSequence:           "get_last_sequence",
+	stmtSetLastSequence:           "set_last_sequence",
+	stmtGetLastHash:               "get_last_hash",
+	stmtSetLastHash:               "set_last_hash",
+	stmtCreateBoard:               "create_board",
+	stmtUpdateBoard:               "update_board",
+	stmtGetBoard:                  "get_board",
+	stmtListBoards:                "list_boards",
+	stmtCreateThread:              "create_thread",
+	stmtIncrementBoardThreadCount: "increment_board_thread_count",
+	stmtUpdateThread:              "update_thread",
+	stmtGetThread:                 "get_thread",
+	stmtCloseThread:               "close_thread",
+	stmtCreatePost:                "create_post",
+	stmtIncrementThreadPostCount:  "increment_thread_post_count",
+	stmtUpdatePost:                "update_post",
+	stmtGetPost:                   "get_post",
+	stmtGetPostThreadID:           "get_post_thread_id",
+	stmtMarkPostDeleted:           "mark_post_deleted",
+	stmtDecrementThreadPostCount:  "decrement_thread_post_count",
+	stmtGetThreadBoardID:          "get_thread_board_id",
+	stmtMarkThreadDeleted:         "mark_thread_deleted",
+	stmtMarkThreadPostsDeleted:    "mark_thread_posts_deleted",
+	stmtDecrementBoardThreadCount: "decrement_board_thread_count",
+}
+
+// stmtQueries holds the SQL text for every stmtID, so stmtCache.stmt has
+// something to Prepare on a cache miss. Keep these in sync with the
+// equivalent ad-hoc query strings sqliteDB's methods used before this
+// cache existed — changing one here changes it for both sqliteDB and every
+// sqliteTx sharing the same cache.
+var stmtQueries = map[stmtID]string{
+	stmtGetLastSequence: `SELECT last_seq FROM log_state WHERE id = 1`,
+	stmtSetLastSequence: `
+        INSERT INTO log_state (id, last_seq) VALUES (1, ?)
+        ON CONFLICT(id) DO UPDATE SET last_seq = excluded.last_seq
+    `,
+	stmtGetLastHash: `SELECT last_hash FROM log_state WHERE id = 1`,
+	stmtSetLastHash: `
+        INSERT INTO log_state (id, last_hash) VALUES (1, ?)
+        ON CONFLICT(id) DO UPDATE SET last_hash = excluded.last_hash
+    `,
+	stmtCreateBoard: `
+        INSERT INTO boards (id, name, description, created_at, updated_at, thread_count)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `,
+	stmtUpdateBoard: `
+        UPDATE boards
+        SET name = ?, description = ?, updated_at = ?, thread_count = ?
+        WHERE id = ?
+    `,
+	stmtGetBoard: `
+        SELECT id, name, description, created_at, updated_at, thread_count
+        FROM boards WHERE id = ?
+    `,
+	stmtListBoards: `
+        SELECT id, name, description, created_at, updated_at, thread_count
+        FROM boards
+        ORDER BY created_at ASC
+    `,
+	stmtCreateThread: `
+        INSERT INTO threads (id, board_id, title, author_id, created_at, updated_at, post_count, is_closed, is_deleted)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `,
+	stmtIncrementBoardThreadCount: `
+        UPDATE boards SET thread_count = thread_count + 1, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtUpdateThread: `
+        UPDATE threads
+        SET title = ?, author_id = ?, updated_at = ?, post_count = ?, is_closed = ?, is_deleted = ?
+        WHERE id = ?
+    `,
+	stmtGetThread: `
+        SELECT id, board_id, title, author_id, created_at, updated_at, post_count, is_closed, is_deleted
+        FROM threads WHERE id = ?
+    `,
+	stmtCloseThread: `
+        UPDATE threads SET is_closed = 1, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtCreatePost: `
+        INSERT INTO posts (id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `,
+	stmtIncrementThreadPostCount: `
+        UPDATE threads SET post_count = post_count + 1, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtUpdatePost: `
+        UPDATE posts
+        SET content = ?, updated_at = ?, is_deleted = ?, reply_to = ?
+        WHERE id = ?
+    `,
+	stmtGetPost: `
+        SELECT id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to
+        FROM posts WHERE id = ?
+    `,
+	stmtGetPostThreadID: `SELECT thread_id FROM posts WHERE id = ?`,
+	stmtMarkPostDeleted: `
+        UPDATE posts SET is_deleted = 1, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtDecrementThreadPostCount: `
+        UPDATE threads SET post_count = CASE WHEN post_count > 0 THEN post_count - 1 ELSE 0 END, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtGetThreadBoardID: `SELECT board_id FROM threads WHERE id = ?`,
+	stmtMarkThreadDeleted: `
+        UPDATE threads SET is_deleted = 1, updated_at = ?
+        WHERE id = ?
+    `,
+	stmtMarkThreadPostsDeleted: `
+        UPDATE posts SET is_deleted = 1, updated_at = ?
+        WHERE thread_id = ? AND is_deleted = 0
+    `,
+	stmtDecrementBoardThreadCount: `
+        UPDATE boards SET thread_count = CASE WHEN thread_count > 0 THEN thread_count - 1 ELSE 0 END, updated_at = ?
+        WHERE id = ?
+    `,
+}
+
+// stmtCache lazily Prepares and caches sqliteDB's fixed statements, so a hot
+// path like CreatePost parses its SQL once instead of on every call. Safe
+// for concurrent use: a cache hit only takes the read lock; only the first
+// caller for a given stmtID takes the write lock to Prepare and store it.
+type stmtCache struct {
+	db *sql.DB
+
+	mu    sync.RWMutex
+	stmts map[stmtID]*sql.Stmt
+}
+
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{db: db, stmts: make(map[stmtID]*sql.Stmt)}
+}
+
+// stmt returns id's prepared statement, preparing (and caching) it on first
+// use.
+func (c *stmtCache) stmt(id stmtID) (*sql.Stmt, error) {
+	c.mu.RLock()
+	st, ok := c.stmts[id]
+	c.mu.RUnlock()
+	if ok {
+		return st, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if st, ok := c.stmts[id]; ok {
+		return st, nil
+	}
+	query, ok := stmtQueries[id]
+	if !ok {
+		return nil, fmt.Errorf("indexer: no query registered for stmtID %d", id)
+	}
+	st, err := c.db.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("prepare stmt %d: %w", id, err)
+	}
+	c.stmts[id] = st
+	return st, nil
+}
+
+// prepareAll prepares and caches every statement in stmtQueries up front,
+// so no later c.stmt call can be the one to miss the cache. This matters
+// because a miss calls c.db.Prepare against the package-level *sql.DB pool:
+// if the first use of a given stmtID happens from inside an already-open
+// WithTx, that transaction's Begin has already checked out the pool's sole
+// connection, forcing Prepare to open a second one — which for a ":memory:"
+// DSN is a distinct, empty database. Calling this once in
+// NewSQLiteDBWithOptions, before any WithTx can race it for the connection,
+// rules that out.
+func (c *stmtCache) prepareAll() error {
+	for id := range stmtQueries {
+		if _, err := c.stmt(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close releases every statement this cache has prepared so far.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for id, st := range c.stmts {
+		if err := st.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("close stmt %d: %w", id, err)
+		}
+		delete(c.stmts, id)
+	}
+	return firstErr
+}
+
+// txStmt returns id's cached statement bound to t's transaction via
+// tx.StmtContext, so a sqliteTx reuses sqliteDB's already-parsed plan
+// instead of preparing its own copy for the lifetime of the transaction.
+// The result is wrapped so calls through it fire t.hooks exactly like
+// sqliteDB.hookedStmt's.
+func (t *sqliteTx) txStmt(ctx context.Context, id stmtID) (*hookedStmt, error) {
+	st, err := t.cache.stmt(id)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedStmt{stmt: t.tx.StmtContext(ctx, st), hooks: t.hooks, op: stmtOps[id], sql: stmtQueries[id]}, nil
+}
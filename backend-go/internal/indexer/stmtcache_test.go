@@ -0,0 +1,120 @@
+package indexer
+
+import (
+    "context"
+    "fmt"
+    "testing"
+    "time"
+)
+
+func TestStmtCache_ReusesPreparedStatement(t *testing.T) {
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+
+    sdb := db.(*sqliteDB)
+    st1, err := sdb.cache.stmt(stmtGetLastSequence)
+    if err != nil {
+        t.Fatalf("stmt: %v", err)
+    }
+    st2, err := sdb.cache.stmt(stmtGetLastSequence)
+    if err != nil {
+        t.Fatalf("stmt (again): %v", err)
+    }
+    if st1 != st2 {
+        t.Fatalf("stmt() prepared a second statement instead of reusing the cached one")
+    }
+}
+
+func TestStmtCache_CloseReleasesStatements(t *testing.T) {
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        t.Fatalf("NewSQLiteDB: %v", err)
+    }
+    sdb := db.(*sqliteDB)
+    if _, err := sdb.cache.stmt(stmtGetLastSequence); err != nil {
+        t.Fatalf("stmt: %v", err)
+    }
+    if err := db.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if len(sdb.cache.stmts) != 0 {
+        t.Fatalf("Close left %d statements in the cache", len(sdb.cache.stmts))
+    }
+}
+
+// benchSeedBoardAndThread sets up the single board/thread that every
+// CreatePost in these benchmarks is attached to.
+func benchSeedBoardAndThread(b *testing.B, ctx context.Context, db DB) {
+    b.Helper()
+    if err := db.CreateBoard(ctx, &Board{ID: "bench-board", Name: "Bench"}); err != nil {
+        b.Fatalf("CreateBoard: %v", err)
+    }
+    if err := db.CreateThread(ctx, &Thread{ID: "bench-thread", BoardID: "bench-board", Title: "Bench", AuthorID: "bench-user"}); err != nil {
+        b.Fatalf("CreateThread: %v", err)
+    }
+}
+
+// BenchmarkCreatePost_CachedStmt exercises sqliteDB.CreatePost as shipped,
+// which prepares stmtCreatePost and stmtIncrementThreadPostCount once via
+// the stmtCache and reuses them on every call.
+func BenchmarkCreatePost_CachedStmt(b *testing.B) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        b.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+    benchSeedBoardAndThread(b, ctx, db)
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        p := &Post{
+            ID:       fmt.Sprintf("cached-post-%d", i),
+            ThreadID: "bench-thread",
+            BoardID:  "bench-board",
+            AuthorID: "bench-user",
+            Content:  "benchmark content",
+        }
+        if err := db.CreatePost(ctx, p); err != nil {
+            b.Fatalf("CreatePost: %v", err)
+        }
+    }
+}
+
+// BenchmarkCreatePost_RawExec mirrors CreatePost's two statements but calls
+// sql.DB.ExecContext directly with inline SQL every time, as sqliteDB did
+// before the stmtCache existed, so it re-parses both statements on every
+// call instead of reusing a prepared plan.
+func BenchmarkCreatePost_RawExec(b *testing.B) {
+    ctx := context.Background()
+    db, err := NewSQLiteDB(":memory:")
+    if err != nil {
+        b.Fatalf("NewSQLiteDB: %v", err)
+    }
+    defer db.Close()
+    benchSeedBoardAndThread(b, ctx, db)
+
+    sdb := db.(*sqliteDB)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        now := time.Now().UTC()
+        id := fmt.Sprintf("raw-post-%d", i)
+        _, err := sdb.db.ExecContext(ctx, `
+            INSERT INTO posts (id, thread_id, board_id, author_id, content, created_at, updated_at, is_deleted, reply_to)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        `, id, "bench-thread", "bench-board", "bench-user", "benchmark content", now, now, sqliteDialect.boolParam(false), nil)
+        if err != nil {
+            b.Fatalf("insert post: %v", err)
+        }
+        _, err = sdb.db.ExecContext(ctx, `
+            UPDATE threads SET post_count = post_count + 1, updated_at = ?
+            WHERE id = ?
+        `, now, "bench-thread")
+        if err != nil {
+            b.Fatalf("increment thread.post_count: %v", err)
+        }
+    }
+}
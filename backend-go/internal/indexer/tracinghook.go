@@ -0,0 +1,55 @@
+package indexer
+
+import "context"
+
+// Span is the subset of go.opentelemetry.io/otel/trace.Span that
+// TracingHook needs. A caller that wants real OpenTelemetry traces passes a
+// Tracer backed by an otel SDK tracer (its Start method's return value
+// already satisfies this interface); a caller that doesn't can skip the
+// otel dependency entirely and pass a no-op or log-based Tracer instead.
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts a Span for a named operation, in the shape of
+// go.opentelemetry.io/otel/trace.Tracer.Start (minus the variadic
+// SpanStartOptions this package has no need to forward).
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// TracingHook is a QueryHook that opens a span per statement via Tracer,
+// tagged with the "db.system"/"db.statement" attributes the OpenTelemetry
+// semantic conventions define for database calls. See Tracer and Span for
+// why this package depends only on a narrow local interface rather than the
+// otel SDK itself.
+type TracingHook struct {
+	Tracer Tracer
+}
+
+// NewTracingHook returns a TracingHook that starts spans via tracer.
+func NewTracingHook(tracer Tracer) *TracingHook {
+	return &TracingHook{Tracer: tracer}
+}
+
+type tracingHookSpanKey struct{}
+
+func (h *TracingHook) BeforeQuery(ctx context.Context, event QueryEvent) context.Context {
+	ctx, span := h.Tracer.Start(ctx, "indexer.db."+event.Op)
+	span.SetAttribute("db.system", "sqlite")
+	span.SetAttribute("db.statement", event.SQL)
+	return context.WithValue(ctx, tracingHookSpanKey{}, span)
+}
+
+func (h *TracingHook) AfterQuery(ctx context.Context, event QueryEvent) {
+	span, ok := ctx.Value(tracingHookSpanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if event.Err != nil {
+		span.RecordError(event.Err)
+	}
+	span.End()
+}